@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runAudioCheck(args []string) error {
+	cfg := serviceConfig()
+	fs := newFlagSet("audio-check", &cfg.DatasetDir)
+	fs.IntVar(&cfg.SampleRate, "rate", cfg.SampleRate, "Sample rate the audio should have; 0 expects the dataset's most common one")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval audio-check [flags] [id...]")
+		fmt.Fprintln(fs.Output(), "Lists the cases, only the given ones if any, whose audio has another sample rate than the dataset's, is clipped or is nearly silent. Provider comparisons leave them out.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	resp, err := svc.AudioIssues(context.Background(), workspace.AudioIssuesRequest{IDs: fs.Args()})
+	if err != nil {
+		return err
+	}
+	printAudioIssues(resp)
+	if len(resp.Cases) == 0 {
+		fmt.Println("No audio issues found.")
+	}
+	return nil
+}
+
+// printAudioIssues lists the flagged cases of resp with what is wrong with
+// each.
+func printAudioIssues(resp *workspace.AudioIssuesResponse) {
+	for _, c := range resp.Cases {
+		var details []string
+		for _, issue := range c.Issues {
+			switch issue {
+			case workspace.AudioIssueSampleRate:
+				details = append(details, fmt.Sprintf("%d Hz, not %d", c.SampleRate, resp.SampleRate))
+			case workspace.AudioIssueClipped:
+				details = append(details, fmt.Sprintf("%.1f%% clipped", 100*c.Levels.ClippedRatio))
+			case workspace.AudioIssueSilent:
+				details = append(details, fmt.Sprintf("silent but for %d ms", c.Levels.LoudMS))
+			}
+		}
+		fmt.Printf("  %s\t%s\n", c.ID, strings.Join(details, ", "))
+	}
+}
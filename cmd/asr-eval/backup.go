@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/workspace"
+)
+
+func runBackup(args []string) error {
+	var (
+		cfg = serviceConfig()
+		out string
+	)
+	fs := newFlagSet("backup", &cfg.DatasetDir)
+	fs.StringVar(&out, "o", "", "Output file (default <dataset>-backup-<time>.zip)")
+	fs.Parse(args)
+	if out == "" {
+		out = fmt.Sprintf("%s-backup-%s.zip", filepath.Base(cfg.DatasetDir), time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	// atomicfile never leaves a truncated archive under the final name.
+	pr, pw := io.Pipe()
+	var m *workspace.BackupManifest
+	go func() {
+		var err error
+		m, err = svc.Backup(context.Background(), pw)
+		pw.CloseWithError(err)
+	}()
+	if err := atomicfile.Copy(out, pr); err != nil {
+		pr.CloseWithError(err)
+		return err
+	}
+	fmt.Printf("Backed up %d files to %s.\n", len(m.Files), out)
+	return nil
+}
+
+func runRestore(args []string) error {
+	var (
+		cfg       = serviceConfig()
+		overwrite bool
+	)
+	fs := newFlagSet("restore", &cfg.DatasetDir)
+	fs.BoolVar(&overwrite, "overwrite", false, "Replace files that exist in the dataset, archiving current contexts and reports")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval restore [flags] backup.zip")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	resp, err := svc.RestoreBackup(context.Background(), f, fi.Size(), workspace.RestoreBackupRequest{Overwrite: overwrite})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d files.\n", resp.Restored)
+	for _, name := range resp.Skipped {
+		fmt.Printf("Skipped existing %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runExportBundle(args []string) error {
+	var (
+		cfg   = serviceConfig()
+		out   string
+		audio bool
+		tags  []string
+	)
+	fs := newFlagSet("export-bundle", &cfg.DatasetDir)
+	fs.StringVar(&out, "o", "bundle.zip", "Output file; - writes to stdout")
+	fs.BoolVar(&audio, "audio", false, "Include audio files")
+	fs.Func("tag", "Export the cases with this tag; repeatable, ignored when case IDs are given", func(v string) error {
+		tags = append(tags, v)
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval export-bundle [flags] [case-id...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	var w io.Writer = os.Stdout
+	if out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	req := workspace.ExportBundleRequest{
+		CaseFilter: workspace.CaseFilter{Tags: tags},
+		IDs:        fs.Args(),
+		Audio:      audio,
+	}
+	return svc.ExportBundle(context.Background(), w, req)
+}
+
+func runImportBundle(args []string) error {
+	var (
+		cfg       = serviceConfig()
+		overwrite bool
+	)
+	fs := newFlagSet("import-bundle", &cfg.DatasetDir)
+	fs.BoolVar(&overwrite, "overwrite", false, "Replace files that already exist in the dataset")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval import-bundle [flags] bundle.zip")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	resp, err := svc.ImportBundle(context.Background(), f, fi.Size(), workspace.ImportBundleRequest{Overwrite: overwrite})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d files for %d cases.\n", resp.Imported, len(resp.Cases))
+	for _, name := range resp.Skipped {
+		fmt.Printf("Skipped existing %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runCompareRuns(args []string) error {
+	cfg := serviceConfig()
+	fs := newFlagSet("compare-runs", &cfg.DatasetDir)
+	threshold := fs.Int("threshold", workspace.DefaultCompareThreshold, "List cases whose Q moved by more than this")
+	strict := fs.Bool("fail", false, "Exit with an error when a Tier-1 checkpoint newly fails or a case's Q drops past the threshold")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval compare-runs [flags] <run-a> <run-b>")
+		fmt.Fprintln(fs.Output(), "       asr-eval compare-runs [flags] <dataset-dir-a> <dataset-dir-b>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("want two runs or two dataset directories")
+	}
+	a, b := fs.Arg(0), fs.Arg(1)
+	ctx := context.Background()
+
+	var resp *workspace.CompareRunsResponse
+	var err error
+	if isDir(a) && isDir(b) {
+		cfgA, cfgB := cfg, cfg
+		cfgA.DatasetDir, cfgB.DatasetDir = a, b
+		svcA := workspace.NewService(cfgA, nil)
+		defer svcA.Close()
+		svcB := workspace.NewService(cfgB, nil)
+		defer svcB.Close()
+		resp, err = svcA.CompareDatasets(ctx, svcB, *threshold)
+	} else {
+		svc := workspace.NewService(cfg, nil)
+		defer svc.Close()
+		resp, err = svc.CompareRuns(ctx, workspace.CompareRunsRequest{A: a, B: b, Threshold: *threshold})
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s vs %s over %d cases\n\n", resp.A, resp.B, resp.Cases)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Provider\tQ A\tQ B\tDelta\tS A\tS B\tP A\tP B")
+	stat := func(s *workspace.ProviderStats, v func(*workspace.ProviderStats) float64) string {
+		if s == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", v(s))
+	}
+	q := func(s *workspace.ProviderStats) float64 { return s.WeightedQ }
+	sScore := func(s *workspace.ProviderStats) float64 { return s.WeightedS }
+	pScore := func(s *workspace.ProviderStats) float64 { return s.WeightedP }
+	for _, p := range resp.Providers {
+		delta := "-"
+		if p.A != nil && p.B != nil {
+			delta = fmt.Sprintf("%+.2f", p.DeltaQ)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", p.Provider,
+			stat(p.A, q), stat(p.B, q), delta, stat(p.A, sScore), stat(p.B, sScore), stat(p.A, pScore), stat(p.B, pScore))
+	}
+	w.Flush()
+
+	fmt.Printf("\nNewly failing Tier-1 checkpoints: %d\n", len(resp.NewFailures))
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range resp.NewFailures {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s -> Fail\t%s\n", f.CaseID, f.Provider, f.CheckpointID, f.Was, f.Text)
+	}
+	w.Flush()
+
+	drops := 0
+	fmt.Printf("\nCases whose Q moved more than %d: %d\n", resp.Threshold, len(resp.Moved))
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, m := range resp.Moved {
+		if m.Delta < 0 {
+			drops++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d -> %d\t%+d\n", m.CaseID, m.Provider, m.A, m.B, m.Delta)
+	}
+	w.Flush()
+
+	if *strict && (len(resp.NewFailures) > 0 || drops > 0) {
+		return fmt.Errorf("%d newly failing Tier-1 checkpoints, %d cases dropped", len(resp.NewFailures), drops)
+	}
+	return nil
+}
+
+// isDir reports whether path is a directory.
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"asr-eval/pkg/workspace"
+)
+
+// costGroups are the -by groupings of the usage ledger.
+var costGroups = map[string]func(*workspace.UsageEntry) string{
+	"model":   func(e *workspace.UsageEntry) string { return e.Kind + " " + e.Model },
+	"command": func(e *workspace.UsageEntry) string { return e.Command },
+	"day":     func(e *workspace.UsageEntry) string { return e.Time.Format(time.DateOnly) },
+	"case":    func(e *workspace.UsageEntry) string { return e.CaseID },
+}
+
+func runCost(args []string) error {
+	var (
+		cfg          = serviceConfig()
+		since, until string
+		month        string
+		by           string
+		top          int
+	)
+	fs := newFlagSet("cost", &cfg.DatasetDir)
+	fs.StringVar(&since, "since", "", "Count usage from this day, YYYY-MM-DD (UTC)")
+	fs.StringVar(&until, "until", "", "Count usage before this day, YYYY-MM-DD (UTC)")
+	fs.StringVar(&month, "month", "", "Count usage in this month, YYYY-MM; overrides -since and -until")
+	fs.StringVar(&by, "by", "model,command,day,case", "Comma-separated groupings: model, command, day, case")
+	fs.IntVar(&top, "top", 20, "Rows per grouping, most expensive first (0 = all)")
+	fs.Parse(args)
+
+	var from, to time.Time
+	var err error
+	if month != "" {
+		if from, err = time.Parse("2006-01", month); err != nil {
+			return fmt.Errorf("bad -month %q", month)
+		}
+		to = from.AddDate(0, 1, 0)
+	} else {
+		if since != "" {
+			if from, err = time.Parse(time.DateOnly, since); err != nil {
+				return fmt.Errorf("bad -since %q", since)
+			}
+		}
+		if until != "" {
+			if to, err = time.Parse(time.DateOnly, until); err != nil {
+				return fmt.Errorf("bad -until %q", until)
+			}
+		}
+	}
+	var groups []string
+	for _, g := range strings.Split(by, ",") {
+		if _, ok := costGroups[g]; !ok {
+			return fmt.Errorf("unknown -by %q", g)
+		}
+		groups = append(groups, g)
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	entries, err := svc.ReadUsage(from, to)
+	if err != nil {
+		return err
+	}
+	total := svc.CostReport(entries, func(*workspace.UsageEntry) string { return "total" })
+	if len(total) == 0 {
+		fmt.Println("No usage recorded.")
+		return nil
+	}
+	fmt.Printf("Total: %.2f over %d calls", total[0].Cost, total[0].Calls)
+	if n := total[0].Unpriced; n > 0 {
+		fmt.Printf(" (%d calls without pricing not counted)", n)
+	}
+	fmt.Println()
+
+	for _, g := range groups {
+		lines := svc.CostReport(entries, costGroups[g])
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "%s\tCost\tCalls\tPrompt tokens\tOutput tokens\tAudio\tUnpriced\n", strings.ToUpper(g[:1])+g[1:])
+		for i, l := range lines {
+			if top > 0 && i == top {
+				fmt.Fprintf(w, "(%d more)\n", len(lines)-top)
+				break
+			}
+			key := l.Key
+			if key == "" {
+				key = "-"
+			}
+			audio := "-"
+			if l.AudioMS > 0 {
+				audio = (time.Duration(l.AudioMS) * time.Millisecond).Round(time.Second).String()
+			}
+			fmt.Fprintf(w, "%s\t%.2f\t%d\t%d\t%d\t%s\t%d\n", key, l.Cost, l.Calls, l.PromptTokens, l.OutputTokens, audio, l.Unpriced)
+		}
+		w.Flush()
+	}
+	return nil
+}
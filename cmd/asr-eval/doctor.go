@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/genai"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/llmclient"
+	"asr-eval/pkg/workspace"
+)
+
+// check is one row of the doctor table.
+type check struct {
+	kind, target string
+	took         time.Duration
+	detail       string
+	err          error
+}
+
+func runDoctor(args []string) error {
+	var (
+		cfg       = serviceConfig()
+		providers []string
+		timeout   time.Duration
+		skipASR   bool
+		skipLLM   bool
+	)
+	fs := newFlagSet("doctor", &cfg.DatasetDir)
+	fs.Func("provider", "Provider to check; repeatable (default every enabled provider with an ASR client)", func(v string) error {
+		providers = append(providers, v)
+		return nil
+	})
+	fs.DurationVar(&timeout, "timeout", time.Minute, "Time allowed for each check")
+	fs.BoolVar(&skipASR, "skip-asr", false, "Do not check the ASR providers")
+	fs.BoolVar(&skipLLM, "skip-llm", false, "Do not check the LLM models")
+	fs.Parse(args)
+
+	_ = godotenv.Load()
+	shared.ExportAPIKeys()
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	cfg = svc.Config
+	ctx := context.Background()
+
+	var (
+		mu     sync.Mutex
+		checks []check
+		wg     sync.WaitGroup
+	)
+	run := func(kind, target string, f func(ctx context.Context) (string, error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			detail, err := f(ctx)
+			mu.Lock()
+			checks = append(checks, check{kind: kind, target: target, took: time.Since(start), detail: detail, err: err})
+			mu.Unlock()
+		}()
+	}
+
+	if !skipASR {
+		if len(providers) == 0 {
+			registered := asr.Names()
+			for _, p := range svc.EnabledProviderIDs() {
+				if slices.Contains(registered, p) {
+					providers = append(providers, p)
+				}
+			}
+		}
+		dir, err := os.MkdirTemp("", "asr-eval-doctor")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		clip := filepath.Join(dir, "smoke.wav")
+		if err := os.WriteFile(clip, smokeClip(), 0644); err != nil {
+			return err
+		}
+		for _, p := range providers {
+			run("asr", p, func(ctx context.Context) (string, error) {
+				t, err := asr.New(p)
+				if err != nil {
+					return "", err
+				}
+				res, err := t.Transcribe(ctx, clip, asr.Options{})
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%q, %d stream entries", res.Text, len(res.Stream)), nil
+			})
+		}
+	}
+
+	if !skipLLM {
+		var models []string
+		for _, m := range slices.Concat([]string{cfg.GenModel, cfg.EvalModel}, cfg.FallbackModels, cfg.JudgeModels) {
+			if m != "" && !slices.Contains(models, m) {
+				models = append(models, m)
+			}
+		}
+		var gemini llmclient.Client
+		err := errors.New("GEMINI_API_KEY must be set")
+		if key := os.Getenv("GEMINI_API_KEY"); key != "" {
+			if client, cerr := genai.NewClient(ctx, &genai.ClientConfig{APIKey: key}); cerr != nil {
+				err = fmt.Errorf("init LLM client: %w", cerr)
+			} else {
+				gemini, err = llmclient.NewGenAI(client), nil
+			}
+		}
+		router := llmclient.NewDefaultRouter(gemini)
+		for _, m := range models {
+			run("llm", m, func(ctx context.Context) (string, error) {
+				if err != nil && router.Backend(m) == "gemini" {
+					return "", err
+				}
+				return pingModel(ctx, router, m)
+			})
+		}
+	}
+	wg.Wait()
+
+	if len(checks) == 0 {
+		return errors.New("nothing to check")
+	}
+	slices.SortFunc(checks, func(a, b check) int {
+		return strings.Compare(a.kind+"/"+a.target, b.kind+"/"+b.target)
+	})
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tTARGET\tRESULT\tTIME\tDETAIL")
+	failed := 0
+	for _, c := range checks {
+		result, detail := "ok", c.detail
+		if c.err != nil {
+			result = "FAIL"
+			detail, _, _ = strings.Cut(c.err.Error(), "\n")
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.kind, c.target, result, c.took.Round(10*time.Millisecond), detail)
+	}
+	tw.Flush()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// pingModel asks model for a fixed JSON answer, checking credentials,
+// connectivity and response decoding in one call.
+func pingModel(ctx context.Context, client llmclient.Client, model string) (string, error) {
+	var resp struct {
+		Answer int `json:"answer"`
+	}
+	req := &llmclient.Request{
+		Text:     `Reply with {"answer": 42}.`,
+		Thinking: llmclient.ThinkingLow,
+	}
+	usage, err := client.GenerateJSON(ctx, model, req, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.Answer != 42 {
+		return "", fmt.Errorf("answer = %d, want 42", resp.Answer)
+	}
+	if usage == nil {
+		return "no usage reported", nil
+	}
+	return fmt.Sprintf("%d tokens", usage.TotalTokens), nil
+}
+
+// smokeClip returns a second of a quiet 440 Hz tone as a 16 kHz mono
+// 16-bit WAV file, enough for a provider to accept and answer.
+func smokeClip() []byte {
+	const rate = 16000
+	samples := make([]int16, rate)
+	for i := range samples {
+		samples[i] = int16(2000 * math.Sin(2*math.Pi*440*float64(i)/rate))
+	}
+	var b bytes.Buffer
+	size := uint32(2 * len(samples))
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, 36+size)
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, struct {
+		Size                 uint32
+		Format, Channels     uint16
+		Rate, ByteRate       uint32
+		BlockAlign, BitDepth uint16
+	}{16, 1, 1, rate, 2 * rate, 2, 16})
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, size)
+	binary.Write(&b, binary.LittleEndian, samples)
+	return b.Bytes()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runDump(args []string) error {
+	var (
+		cfg    = serviceConfig()
+		out    string
+		filter workspace.CaseFilter
+	)
+	fs := newFlagSet("dump", &cfg.DatasetDir)
+	fs.StringVar(&out, "o", "-", "Output file; - writes to stdout")
+	fs.StringVar(&filter.Run, "run", "", "Dump the reports of this run instead of the current ones")
+	fs.Func("tag", "Dump the cases with this tag; repeatable", func(v string) error {
+		filter.Tags = append(filter.Tags, v)
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval dump [flags]")
+		fmt.Fprintln(fs.Output(), "Writes a JSON line per case and provider.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	var w io.Writer = os.Stdout
+	if out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	n, err := svc.Dump(context.Background(), bw, filter)
+	if err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d rows.\n", n)
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runDuplicates(args []string) error {
+	var (
+		cfg = serviceConfig()
+		req workspace.DuplicatesRequest
+	)
+	fs := newFlagSet("duplicates", &cfg.DatasetDir)
+	fs.Float64Var(&req.MinSimilarity, "min-similarity", 0.8, "Share of fingerprint bits two recordings must agree on to be duplicates; unrelated ones agree on about half")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval duplicates [flags] [id...]")
+		fmt.Fprintln(fs.Output(), "Fingerprints the audio of every case and lists the clusters of duplicate recordings, only those with the given cases if any.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	req.IDs = fs.Args()
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	resp, err := svc.FindDuplicates(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	printDuplicates(resp)
+	if len(resp.Clusters) == 0 {
+		fmt.Println("No duplicates found.")
+	}
+	if len(resp.Failed) > 0 {
+		return fmt.Errorf("%d cases could not be fingerprinted", len(resp.Failed))
+	}
+	return nil
+}
+
+// printDuplicates lists the clusters of resp with their pairs, and the
+// cases that could not be fingerprinted.
+func printDuplicates(resp *workspace.DuplicatesResponse) {
+	for _, f := range resp.Failed {
+		fmt.Printf("Failed: %s\n", f)
+	}
+	for i, c := range resp.Clusters {
+		fmt.Printf("Cluster %d: %d cases\n", i+1, len(c.IDs))
+		for _, p := range c.Pairs {
+			fmt.Printf("  %s = %s\t%.0f%% similar", p.A, p.B, 100*p.Similarity)
+			if p.OffsetMS != 0 {
+				fmt.Printf(", at %s", time.Duration(p.OffsetMS)*time.Millisecond)
+			}
+			fmt.Println()
+		}
+	}
+}
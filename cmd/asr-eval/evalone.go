@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/genai"
+
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/llmclient"
+)
+
+// evalOneResult is what eval-one prints.
+type evalOneResult struct {
+	Context *evalv2.EvalContext `json:"context"`
+	Report  *evalv2.EvalReport  `json:"report"`
+}
+
+func runEvalOne(args []string) error {
+	var (
+		cfg         = serviceConfig()
+		gt          string
+		audio       string
+		contextPath string
+		transcripts = make(map[string]string)
+		stdin       textSource
+	)
+	fs := flag.NewFlagSet("asr-eval eval-one", flag.ExitOnError)
+	fs.StringVar(&gt, "gt", "", "Ground truth text; @file reads a file, - reads stdin")
+	fs.StringVar(&audio, "audio", "", "Audio file to generate the context from")
+	fs.StringVar(&contextPath, "context", "", "Context JSON (.gt.v2.json) to evaluate against instead of generating one")
+	fs.Func("t", "Transcript as provider=text; @file reads a file, - reads stdin; repeatable", func(v string) error {
+		p, text, ok := strings.Cut(v, "=")
+		if !ok || p == "" {
+			return fmt.Errorf("want provider=text, got %q", v)
+		}
+		transcripts[p] = text
+		return nil
+	})
+	fs.StringVar(&cfg.GenModel, "gen-model", cfg.GenModel, "LLM model for context generation")
+	fs.StringVar(&cfg.EvalModel, "eval-model", cfg.EvalModel, "LLM model for evaluation")
+	fs.Func("judge-models", "Comma-separated judge models for a consensus evaluation", func(v string) error {
+		cfg.JudgeModels = strings.Split(v, ",")
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval eval-one [flags] (-audio file -gt text | -context file) -t provider=text...")
+		fmt.Fprintln(fs.Output(), "Generates a context and evaluates the transcripts against it without a dataset, printing both as JSON.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var err error
+	for p, v := range transcripts {
+		if transcripts[p], err = stdin.read(v); err != nil {
+			return fmt.Errorf("transcript %s: %w", p, err)
+		}
+	}
+	if len(transcripts) == 0 {
+		fs.Usage()
+		return errors.New("no transcripts")
+	}
+	if (audio == "") == (contextPath == "") {
+		fs.Usage()
+		return errors.New("want either -audio or -context")
+	}
+
+	_ = godotenv.Load()
+	shared.ExportAPIKeys()
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: os.Getenv("GEMINI_API_KEY")})
+	if err != nil {
+		return fmt.Errorf("init LLM client: %w", err)
+	}
+	e := evalv2.NewEvaluator(llmclient.NewDefaultRouter(llmclient.NewGenAI(client)), cfg.GenModel, cfg.EvalModel)
+	e.SetFallbackModels(cfg.FallbackModels)
+	e.SetMaxPromptTokens(cfg.MaxPromptTokens)
+
+	var res evalOneResult
+	if contextPath != "" {
+		content, err := os.ReadFile(contextPath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(content, &res.Context); err != nil {
+			return fmt.Errorf("%s: %w", contextPath, err)
+		}
+	} else {
+		if gt, err = stdin.read(gt); err != nil {
+			return fmt.Errorf("ground truth: %w", err)
+		}
+		if res.Context, _, err = e.GenerateContext(ctx, audio, gt, transcripts); err != nil {
+			return fmt.Errorf("generate context: %w", err)
+		}
+	}
+	if len(cfg.JudgeModels) > 1 {
+		res.Report, _, err = e.EvaluateConsensus(ctx, res.Context, transcripts, cfg.JudgeModels)
+	} else {
+		res.Report, _, err = e.Evaluate(ctx, res.Context, transcripts)
+	}
+	if err != nil {
+		return fmt.Errorf("evaluate: %w", err)
+	}
+	res.Report.ContextSnapshot = *res.Context
+	sWeight := cfg.SScoreWeight
+	if sWeight == 0 {
+		sWeight = evalv2.SScoreWeight
+	}
+	for p, r := range res.Report.Results {
+		r.Metrics.QScore = r.Metrics.CompositeScoreWith(sWeight)
+		res.Report.Results[p] = r
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(res)
+}
+
+// textSource resolves flag values to text: "@file" is the file's content
+// and "-" is standard input, which can be read once.
+type textSource struct {
+	used bool
+}
+
+func (s *textSource) read(v string) (string, error) {
+	switch {
+	case v == "-":
+		if s.used {
+			return "", errors.New("only one value can be read from stdin")
+		}
+		s.used = true
+		b, err := io.ReadAll(os.Stdin)
+		return strings.TrimSpace(string(b)), err
+	case strings.HasPrefix(v, "@"):
+		b, err := os.ReadFile(v[1:])
+		return strings.TrimSpace(string(b)), err
+	}
+	return v, nil
+}
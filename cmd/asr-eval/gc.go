@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runGC(args []string) error {
+	var (
+		cfg = serviceConfig()
+		yes bool
+	)
+	fs := newFlagSet("gc", &cfg.DatasetDir)
+	fs.BoolVar(&yes, "y", false, "Remove without asking")
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	ctx := context.Background()
+	files, err := svc.FindGarbage(ctx)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println("Nothing to remove.")
+		return nil
+	}
+	for _, f := range files {
+		fmt.Printf("%s\t%s\n", f.Name, f.Reason)
+	}
+	if !yes {
+		fmt.Printf("Remove these %d files? [y/N] ", len(files))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Nothing removed.")
+			return nil
+		}
+	}
+	n, err := svc.RemoveGarbage(ctx, files)
+	fmt.Printf("Removed %d files.\n", n)
+	return err
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"asr-eval/pkg/workspace"
+)
+
+// gtCommands are the subcommands of asr-eval gt.
+var gtCommands = map[string]command{
+	"get":         {"Print a case's ground truth", runGTGet},
+	"set":         {"Replace a case's ground truth, invalidating its context and report", runGTSet},
+	"append-note": {"Add a note on a case's ground truth as a comment", runGTAppendNote},
+	"bulk-edit":   {"Set ground truths from JSON lines, or replace text across cases", runGTBulkEdit},
+}
+
+func runGT(args []string) error {
+	if len(args) < 1 {
+		gtUsage()
+		return errors.New("need a subcommand")
+	}
+	c, ok := gtCommands[args[0]]
+	if !ok {
+		gtUsage()
+		return fmt.Errorf("unknown subcommand %q", args[0])
+	}
+	return c.run(args[1:])
+}
+
+func gtUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: asr-eval gt <subcommand> [flags]")
+	fmt.Fprintln(os.Stderr, "\nSubcommands:")
+	names := make([]string, 0, len(gtCommands))
+	for name := range gtCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, gtCommands[name].summary)
+	}
+}
+
+func runGTGet(args []string) error {
+	cfg := serviceConfig()
+	fs := newFlagSet("gt get", &cfg.DatasetDir)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval gt get [flags] case-id")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	gt, err := svc.GroundTruth(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Println(gt)
+	return nil
+}
+
+func runGTSet(args []string) error {
+	cfg := serviceConfig()
+	fs := newFlagSet("gt set", &cfg.DatasetDir)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval gt set [flags] case-id text|@file|-")
+		fmt.Fprintln(fs.Output(), "The context, generated from the old ground truth, is reduced to the new one and the report archived.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var stdin textSource
+	gt, err := stdin.read(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	id := fs.Arg(0)
+	changed, err := svc.SetGroundTruth(context.Background(), workspace.SetGroundTruthRequest{ID: id, GroundTruth: gt})
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Printf("%s: unchanged\n", id)
+		return nil
+	}
+	fmt.Printf("%s: ground truth set; generate the context again to evaluate\n", id)
+	return nil
+}
+
+func runGTAppendNote(args []string) error {
+	cfg := serviceConfig()
+	fs := newFlagSet("gt append-note", &cfg.DatasetDir)
+	author := fs.String("author", os.Getenv("USER"), "Author of the note")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval gt append-note [flags] case-id text|@file|-")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var stdin textSource
+	body, err := stdin.read(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	_, err = svc.CreateComment(context.Background(), workspace.CreateCommentRequest{ID: fs.Arg(0), Author: *author, Body: body})
+	return err
+}
+
+// gtEdit is a line of bulk-edit input.
+type gtEdit struct {
+	ID          string `json:"id"`
+	GroundTruth string `json:"ground_truth"`
+}
+
+func runGTBulkEdit(args []string) error {
+	var (
+		cfg      = serviceConfig()
+		replacer []string
+		tags     []string
+		dryRun   bool
+	)
+	fs := newFlagSet("gt bulk-edit", &cfg.DatasetDir)
+	fs.Func("replace", "Replace old with new in every selected ground truth, as old=new; repeatable", func(v string) error {
+		from, to, ok := strings.Cut(v, "=")
+		if !ok || from == "" {
+			return fmt.Errorf("want old=new, got %q", v)
+		}
+		replacer = append(replacer, from, to)
+		return nil
+	})
+	fs.Func("tag", "With -replace, edit the cases with this tag; repeatable", func(v string) error {
+		tags = append(tags, v)
+		return nil
+	})
+	fs.BoolVar(&dryRun, "n", false, "Only list the cases that would change")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval gt bulk-edit [flags] [edits.jsonl|-]")
+		fmt.Fprintln(fs.Output(), `Sets the ground truths given as {"id": ..., "ground_truth": ...} lines, or with -replace, edits those of the dataset.`)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	ctx := context.Background()
+
+	var edits []gtEdit
+	var err error
+	if len(replacer) > 0 {
+		if fs.NArg() > 0 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		edits, err = replaceEdits(ctx, svc, strings.NewReplacer(replacer...), tags)
+	} else {
+		edits, err = readEdits(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, e := range edits {
+		if dryRun {
+			gt, err := svc.GroundTruth(ctx, e.ID)
+			if err != nil {
+				return err
+			}
+			if gt != e.GroundTruth {
+				fmt.Printf("%s: %s\n  -> %s\n", e.ID, gt, e.GroundTruth)
+				changed++
+			}
+			continue
+		}
+		ok, err := svc.SetGroundTruth(ctx, workspace.SetGroundTruthRequest{ID: e.ID, GroundTruth: e.GroundTruth})
+		if err != nil {
+			return err
+		}
+		if ok {
+			fmt.Println(e.ID)
+			changed++
+		}
+	}
+	done := "Changed"
+	if dryRun {
+		done = "Would change"
+	}
+	fmt.Printf("%s %d of %d ground truths\n", done, changed, len(edits))
+	return nil
+}
+
+// replaceEdits applies r to the ground truth of the cases with all of
+// tags.
+func replaceEdits(ctx context.Context, svc *workspace.Service, r *strings.Replacer, tags []string) ([]gtEdit, error) {
+	ids, err := svc.Storage.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var edits []gtEdit
+	for _, id := range ids {
+		c, err := svc.GetCase(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if c.EvalContext == nil || slices.ContainsFunc(tags, func(t string) bool { return !slices.Contains(c.Tags, t) }) {
+			continue
+		}
+		if gt := c.EvalContext.Meta.GroundTruth; r.Replace(gt) != gt {
+			edits = append(edits, gtEdit{ID: id, GroundTruth: r.Replace(gt)})
+		}
+	}
+	return edits, nil
+}
+
+// readEdits reads JSON lines of edits from path, or stdin when path is
+// empty or "-".
+func readEdits(path string) ([]gtEdit, error) {
+	var r io.Reader = os.Stdin
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var edits []gtEdit
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var e gtEdit
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if e.ID == "" {
+			return nil, fmt.Errorf("line %d: no id", n)
+		}
+		edits = append(edits, e)
+	}
+	return edits, sc.Err()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runExportHF(args []string) error {
+	var (
+		cfg = serviceConfig()
+		out string
+		req workspace.ExportHFRequest
+	)
+	fs := newFlagSet("export-hf", &cfg.DatasetDir)
+	fs.StringVar(&out, "o", "hf.zip", "Output file; - writes to stdout")
+	fs.BoolVar(&req.Audio, "audio", false, "Include the audio files")
+	fs.StringVar(&req.Filter.Run, "run", "", "Export the reports of this run instead of the current ones")
+	fs.Func("tag", "Export the cases with this tag; repeatable", func(v string) error {
+		req.Filter.Tags = append(req.Filter.Tags, v)
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval export-hf [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	var w io.Writer = os.Stdout
+	if out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return svc.ExportHF(context.Background(), w, req)
+}
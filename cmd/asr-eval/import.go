@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"asr-eval/pkg/corpus"
+	"asr-eval/pkg/workspace"
+)
+
+func runImport(args []string) error {
+	var (
+		cfg    = serviceConfig()
+		format string
+		src    string
+		req    workspace.ImportCorpusRequest
+		dedupe bool
+	)
+	fs := newFlagSet("import", &cfg.DatasetDir)
+	fs.StringVar(&format, "format", "", "Corpus format: kaldi, librispeech, commonvoice or audio")
+	fs.StringVar(&src, "src", "", "Kaldi data directory, LibriSpeech directory, Common Voice TSV file or directory of audio files")
+	fs.StringVar(&req.Prefix, "prefix", "", "Prefix for case IDs, e.g. librispeech-")
+	fs.IntVar(&req.SampleRate, "rate", 0, "Resample audio to this rate in Hz; 0 keeps the original")
+	fs.IntVar(&req.Channels, "channels", 0, "Mix audio to this many channels; 0 keeps the original")
+	fs.BoolVar(&req.DryRun, "n", false, "Only count the cases to import")
+	fs.BoolVar(&dedupe, "check-duplicates", true, "List the imported cases that duplicate a recording of the dataset")
+	fs.Parse(args)
+	if format == "" || src == "" {
+		fs.Usage()
+		return errors.New("need -format and -src")
+	}
+
+	utts, err := corpus.Read(format, src)
+	if err != nil {
+		return err
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	ctx := context.Background()
+	resp, err := svc.ImportCorpus(ctx, utts, req)
+	if err != nil {
+		return err
+	}
+	for _, f := range resp.Failed {
+		fmt.Printf("Failed: %s\n", f)
+	}
+	verb := "Imported"
+	if req.DryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d of %d utterances; %d cases already exist.\n", verb, resp.Imported, len(utts), len(resp.Skipped))
+	if dedupe && len(resp.IDs) > 0 {
+		dups, err := svc.FindDuplicates(ctx, workspace.DuplicatesRequest{IDs: resp.IDs})
+		if err != nil {
+			return err
+		}
+		if len(dups.Clusters) > 0 {
+			fmt.Println("Imported recordings with duplicates:")
+			printDuplicates(dups)
+		}
+	}
+	if !req.DryRun && len(resp.IDs) > 0 {
+		issues, err := svc.AudioIssues(ctx, workspace.AudioIssuesRequest{IDs: resp.IDs})
+		switch {
+		case err != nil:
+			fmt.Printf("Audio check skipped: %v\n", err)
+		case len(issues.Cases) > 0:
+			fmt.Println("Imported recordings left out of provider comparisons:")
+			printAudioIssues(issues)
+		}
+	}
+	if len(resp.Failed) > 0 {
+		return fmt.Errorf("%d utterances failed", len(resp.Failed))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runImportTranscripts(args []string) error {
+	var (
+		cfg = serviceConfig()
+		req workspace.ImportTranscriptsRequest
+	)
+	fs := newFlagSet("import-transcripts", &cfg.DatasetDir)
+	fs.BoolVar(&req.Overwrite, "overwrite", false, "Replace existing transcripts instead of skipping them")
+	fs.BoolVar(&req.DryRun, "n", false, "Only validate and count the rows")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval import-transcripts [flags] file.csv|-")
+		fmt.Fprintln(fs.Output(), "Each row is id,provider,text; a first row of id,provider,text is a header.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("need a CSV file")
+	}
+
+	var r io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+		req.Source = filepath.Base(path)
+	}
+	rows, err := workspace.ReadTranscriptCSV(r)
+	if err != nil {
+		return err
+	}
+	req.Rows = rows
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	resp, err := svc.ImportTranscripts(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	for _, f := range resp.Failed {
+		fmt.Printf("Failed: %s\n", f)
+	}
+	verb := "Imported"
+	if req.DryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d of %d transcripts; %d already exist.\n", verb, resp.Imported, len(rows), len(resp.Skipped))
+	if len(resp.Failed) > 0 {
+		return fmt.Errorf("%d rows failed", len(resp.Failed))
+	}
+	return nil
+}
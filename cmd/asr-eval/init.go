@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"asr-eval/pkg/corpus"
+	"asr-eval/pkg/workspace"
+)
+
+func runInit(args []string) error {
+	var (
+		cfg   = serviceConfig()
+		audio string
+		req   workspace.ImportCorpusRequest
+	)
+	fs := newFlagSet("init", &cfg.DatasetDir)
+	fs.StringVar(&audio, "audio", "", "Directory of audio files to add as cases, each with a new UUID; a .txt file next to one is its ground truth")
+	fs.IntVar(&req.SampleRate, "rate", 16000, "Resample the audio to this rate in Hz; 0 keeps the original")
+	fs.IntVar(&req.Channels, "channels", 1, "Mix the audio to this many channels; 0 keeps the original")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval init [flags] [dir]")
+		fmt.Fprintln(fs.Output(), "Creates the dataset directory dir, -dataset-dir by default, with a starter dataset.yaml.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		cfg.DatasetDir = fs.Arg(0)
+	default:
+		fs.Usage()
+		return fmt.Errorf("too many arguments")
+	}
+
+	created, err := workspace.InitDataset(cfg.DatasetDir)
+	if err != nil {
+		return err
+	}
+	for _, p := range created {
+		fmt.Printf("Created %s\n", p)
+	}
+	if audio == "" {
+		return nil
+	}
+
+	utts, err := corpus.Read(corpus.FormatAudio, audio)
+	if err != nil {
+		return err
+	}
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	ctx := context.Background()
+	var imported, failed int
+	for _, u := range utts {
+		u.ID = uuid.NewString()
+		resp, err := svc.ImportCorpus(ctx, []corpus.Utterance{u}, req)
+		if err != nil {
+			return err
+		}
+		for _, f := range resp.Failed {
+			fmt.Printf("Failed: %s: %s\n", u.Audio, f)
+			failed++
+		}
+		if resp.Imported > 0 {
+			fmt.Printf("%s\t%s\n", u.ID, u.Audio)
+			imported++
+		}
+	}
+	fmt.Printf("Added %d of %d audio files.\n", imported, len(utts))
+	if failed > 0 {
+		return fmt.Errorf("%d audio files failed", failed)
+	}
+	return nil
+}
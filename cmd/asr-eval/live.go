@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/rtp"
+	"asr-eval/pkg/workspace"
+)
+
+// liveSource is the audio of a live session, 16-bit mono PCM.
+type liveSource interface {
+	io.Reader
+	Stop() error
+}
+
+// liveTranscription records input, a microphone or an rtp:// URL to
+// receive a call on, until interrupted, or for duration if set, streaming
+// it to providers side by side and printing their final segments as they
+// arrive, and saves the recording as a new case.
+func liveTranscription(svc *workspace.Service, providers []string, input, biasing string, duration time.Duration) error {
+	if len(providers) == 0 {
+		return errors.New("-live needs -provider")
+	}
+	if input == "" {
+		return errors.New("no default microphone input on this system; set -input")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var (
+		capture liveSource
+		rate    = asr.StreamSampleRate
+	)
+	if strings.HasPrefix(input, "rtp://") {
+		r, err := rtp.ListenURL(input)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Waiting for RTP on %s\n", r.Addr())
+		capture, rate = r, r.SampleRate()
+	} else {
+		c, err := audio.StartCapture(ctx, input, asr.StreamSampleRate)
+		if err != nil {
+			return err
+		}
+		capture = c
+	}
+	// A first interrupt stops recording and lets the providers finish what
+	// they heard; a second abandons them.
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+	go func() {
+		<-sigs
+		fmt.Println("Stopping; interrupt again to abandon the providers")
+		capture.Stop()
+		<-sigs
+		cancel()
+	}()
+	if duration > 0 {
+		time.AfterFunc(duration, func() { capture.Stop() })
+	}
+
+	width := 0
+	for _, p := range providers {
+		width = max(width, len(p))
+	}
+	fmt.Printf("Recording %s for %s; interrupt to stop\n", input, strings.Join(providers, ", "))
+	var mu sync.Mutex
+	resp, err := svc.Live(ctx, workspace.LiveRequest{
+		Audio:      capture,
+		SampleRate: rate,
+		Providers:  providers,
+		Context:    biasing,
+		OnStream: func(provider string, e asr.StreamEntry) {
+			if !e.Final {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Printf("%8s  %-*s  %s\n", (time.Duration(e.Timestamp) * time.Millisecond).Round(100*time.Millisecond), width, provider, e.Text)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved as case %s\n", resp.Case.ID)
+	for _, p := range providers {
+		if t, ok := resp.Case.Transcripts[p]; ok {
+			fmt.Printf("  %-*s  %s\n", width, p, t)
+		}
+	}
+	for _, f := range resp.Failed {
+		fmt.Printf("Failed: %s\n", f)
+	}
+	if len(resp.Failed) > 0 {
+		return fmt.Errorf("%d providers failed", len(resp.Failed))
+	}
+	return nil
+}
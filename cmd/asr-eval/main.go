@@ -0,0 +1,109 @@
+// Command asr-eval runs dataset maintenance tasks against a dataset
+// directory without the server.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"asr-eval/pkg/workspace"
+)
+
+// command is an asr-eval subcommand. run receives the arguments after the
+// command name.
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"backup":             {"Write every file of a dataset but audio to a zip", runBackup},
+	"restore":            {"Restore a backup into a dataset", runRestore},
+	"export-bundle":      {"Write selected cases to a zip bundle", runExportBundle},
+	"import-bundle":      {"Extract a zip bundle into a dataset", runImportBundle},
+	"init":               {"Create a dataset directory, optionally adding a directory of audio", runInit},
+	"import":             {"Add cases from a Kaldi, LibriSpeech or Common Voice corpus", runImport},
+	"duplicates":         {"List clusters of duplicate recordings by audio fingerprint", runDuplicates},
+	"audio-check":        {"List cases whose audio has the wrong sample rate, is clipped or is silent", runAudioCheck},
+	"import-transcripts": {"Write provider transcripts from a CSV of id,provider,text", runImportTranscripts},
+	"export-html":        {"Write a static HTML report to a zip", runExportHTML},
+	"export-leaderboard": {"Write the provider ranking with intervals and tier results as Markdown", runExportLeaderboard},
+	"export-hf":          {"Write cases as a Hugging Face dataset zip", runExportHF},
+	"redact":             {"Write a copy of cases with personal information replaced", runRedact},
+	"dump":               {"Write a JSON line per case and provider", runDump},
+	"eval-one":           {"Evaluate transcripts of one recording without a dataset", runEvalOne},
+	"gt":                 {"Get, set, annotate or bulk-edit ground truths", runGT},
+	"sample":             {"Pick a stratified sample of case IDs for the batch tools", runSample},
+	"show":               {"Print a case with diffed transcripts and checkpoints", runShow},
+	"cost":               {"Report LLM and ASR spend from the usage ledger", runCost},
+	"compare-runs":       {"Report score changes and regressions between two runs or datasets", runCompareRuns},
+	"rename":             {"Rename cases across all their files, or apply a mapping file", runRename},
+	"recompute":          {"Score stored reports again without LLM calls", runRecompute},
+	"reset-results":      {"Remove providers' results from case reports", runResetResults},
+	"gc":                 {"Remove stale files from a dataset", runGC},
+	"sync":               {"Copy a dataset's changes to another directory", runSync},
+	"doctor":             {"Check ASR providers and LLM models with a tiny request each", runDoctor},
+	"smoke":              {"Same as doctor", runDoctor},
+	"transcribe-all":     {"Run every enabled ASR provider over a dataset", runTranscribeAll},
+	"retry-failed":       {"Retry the failures of a batch run manifest", runRetryFailed},
+	"migrate":            {"Migrate a dataset: schema, compress, sqlite", runMigrate},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "asr-eval: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	var err error
+	if defaults, shared, err = workspace.LoadServiceConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "asr-eval: %v\n", err)
+		os.Exit(1)
+	}
+	defaults.Command = "asr-eval " + os.Args[1]
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "asr-eval %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: asr-eval <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'asr-eval <command> -h' for the command's flags.")
+}
+
+// defaults is the service configuration with asr-eval.yaml, shared,
+// applied.
+var (
+	defaults workspace.ServiceConfig
+	shared   *workspace.SharedConfig
+)
+
+// serviceConfig returns a copy of defaults for a command to adjust.
+func serviceConfig() workspace.ServiceConfig {
+	return defaults
+}
+
+// newFlagSet returns a flag set for the named command with the shared
+// -dataset-dir flag bound to dir, which holds its default.
+func newFlagSet(name string, dir *string) *flag.FlagSet {
+	fs := flag.NewFlagSet("asr-eval "+name, flag.ExitOnError)
+	fs.StringVar(dir, "dataset-dir", *dir, "Directory containing transcripts and audio files")
+	return fs
+}
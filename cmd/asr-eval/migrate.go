@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/workspace"
+)
+
+// migrations are the subcommands of asr-eval migrate. Those rewriting files
+// in place record them in the dataset's migration journal, so rerunning one
+// after an interruption picks up where it stopped.
+var migrations = map[string]command{
+	"schema":   {"Upgrade context and report files to the current schema", runMigrateSchema},
+	"compress": {"Gzip or gunzip report files in place", runMigrateCompress},
+	"bundles":  {"Fold per-case record files into case bundles, or back", runMigrateBundles},
+	"sqlite":   {"Copy a dataset directory into a SQLite store", runMigrateSQLite},
+}
+
+func runMigrate(args []string) error {
+	if len(args) < 1 {
+		migrateUsage()
+		return errors.New("need a migration")
+	}
+	m, ok := migrations[args[0]]
+	if !ok {
+		migrateUsage()
+		return fmt.Errorf("unknown migration %q", args[0])
+	}
+	return m.run(args[1:])
+}
+
+func migrateUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: asr-eval migrate <migration> [flags]")
+	fmt.Fprintln(os.Stderr, "\nMigrations:")
+	names := make([]string, 0, len(migrations))
+	for name := range migrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, migrations[name].summary)
+	}
+}
+
+// printMigrateResponse reports the outcome of a file migration, failing
+// when any file failed.
+func printMigrateResponse(resp *workspace.MigrateResponse, done, what string, dryRun bool) error {
+	for _, f := range resp.Failed {
+		fmt.Printf("Failed: %s\n", f)
+	}
+	if dryRun {
+		done = "Would have " + done
+	}
+	fmt.Printf("%s %d of %d %s", done, resp.Migrated, resp.Scanned, what)
+	if resp.Skipped > 0 {
+		fmt.Printf(" (%d unchanged since the last run)", resp.Skipped)
+	}
+	fmt.Println(".")
+	if len(resp.Failed) > 0 {
+		return fmt.Errorf("%d files failed", len(resp.Failed))
+	}
+	return nil
+}
+
+func runMigrateSchema(args []string) error {
+	var (
+		cfg    = serviceConfig()
+		dryRun bool
+	)
+	fs := newFlagSet("migrate schema", &cfg.DatasetDir)
+	fs.BoolVar(&dryRun, "n", false, "Only count the files to upgrade")
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	resp, err := svc.MigrateSchema(context.Background(), dryRun)
+	if err != nil {
+		return err
+	}
+	return printMigrateResponse(resp, "upgraded", fmt.Sprintf("context and report files to schema version %d", evalv2.SchemaVersion), dryRun)
+}
+
+func runMigrateCompress(args []string) error {
+	var (
+		cfg        = serviceConfig()
+		decompress bool
+		dryRun     bool
+	)
+	fs := newFlagSet("migrate compress", &cfg.DatasetDir)
+	fs.BoolVar(&decompress, "d", false, "Decompress instead")
+	fs.BoolVar(&dryRun, "n", false, "Only count the files to rewrite")
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	resp, err := svc.CompressReports(context.Background(), decompress, dryRun)
+	if err != nil {
+		return err
+	}
+	done := "compressed"
+	if decompress {
+		done = "decompressed"
+	}
+	return printMigrateResponse(resp, done, "report files", dryRun)
+}
+
+func runMigrateBundles(args []string) error {
+	var (
+		cfg    = serviceConfig()
+		unfold bool
+		dryRun bool
+	)
+	fs := newFlagSet("migrate bundles", &cfg.DatasetDir)
+	fs.BoolVar(&unfold, "d", false, "Split bundles back into a file per record instead")
+	fs.BoolVar(&dryRun, "n", false, "Only count the cases to change")
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	resp, err := svc.FoldCaseBundles(context.Background(), unfold, dryRun)
+	if err != nil {
+		return err
+	}
+	done := "bundled"
+	if unfold {
+		done = "unbundled"
+	}
+	return printMigrateResponse(resp, done, "cases", dryRun)
+}
+
+func runMigrateSQLite(args []string) error {
+	var (
+		cfg    = serviceConfig()
+		out    string
+		dryRun bool
+	)
+	fs := newFlagSet("migrate sqlite", &cfg.DatasetDir)
+	fs.StringVar(&out, "o", "", "SQLite database to create or update")
+	fs.BoolVar(&dryRun, "n", false, "Only count the cases to copy")
+	fs.Parse(args)
+	if out == "" && !dryRun {
+		fs.Usage()
+		return errors.New("need -o")
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	if dryRun {
+		ids, err := svc.Storage.ListIDs(context.Background())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Would have copied %d cases.\n", len(ids))
+		return nil
+	}
+
+	st, err := workspace.OpenSQLiteStorage(out)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	resp, err := svc.CopyToStorage(context.Background(), st)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Copied %d cases (%d transcripts, %d contexts, %d reports) and %d audit entries to %s.\n",
+		resp.Cases, resp.Transcripts, resp.Contexts, resp.Reports, resp.Audit, out)
+	return nil
+}
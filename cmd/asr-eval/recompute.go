@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runRecompute(args []string) error {
+	var (
+		cfg = serviceConfig()
+		req workspace.RecomputeRequest
+	)
+	fs := newFlagSet("recompute", &cfg.DatasetDir)
+	fs.BoolVar(&req.DryRun, "n", false, "Only print the changes")
+	fs.Func("tag", "Recompute the cases with this tag; repeatable, ignored when case IDs are given", func(v string) error {
+		req.Tags = append(req.Tags, v)
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval recompute [flags] [case-id...]")
+		fmt.Fprintln(fs.Output(), "Scores stored reports again from their checkpoint results and phonetic errors, without LLM calls.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	req.IDs = fs.Args()
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	resp, err := svc.Recompute(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	for _, c := range resp.Changes {
+		fmt.Printf("%s\t%s\tQ %d -> %d\tS %.3f -> %.3f\tP %.3f -> %.3f\n", c.CaseID, c.Provider,
+			c.Before.QScore, c.After.QScore, c.Before.SScore, c.After.SScore, c.Before.PScore, c.After.PScore)
+	}
+	verb := "Updated"
+	if req.DryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("%s %d of %d reports, %d results changed.\n", verb, resp.Updated, resp.Cases, len(resp.Changes))
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"google.golang.org/genai"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/llmclient"
+	"asr-eval/pkg/workspace"
+)
+
+func runRedact(args []string) error {
+	var (
+		cfg     = serviceConfig()
+		req     workspace.RedactRequest
+		mapPath string
+		noLLM   bool
+	)
+	fs := newFlagSet("redact", &cfg.DatasetDir)
+	fs.StringVar(&req.OutDir, "o", "", "Directory to write the redacted copy to")
+	fs.StringVar(&mapPath, "map", "", "Also write the placeholders and the values they replace to this JSON file; keep it private")
+	fs.BoolVar(&req.Audio, "audio", false, "Also copy the audio files, which are not redacted")
+	fs.BoolVar(&noLLM, "no-llm", false, "Only redact what the patterns match, leaving names in place")
+	fs.Func("tag", "Redact the cases with this tag; repeatable, ignored when case IDs are given", func(v string) error {
+		req.Tags = append(req.Tags, v)
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval redact [flags] -o dir [case-id...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if req.OutDir == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	req.IDs = fs.Args()
+	req.LLM = !noLLM
+
+	ctx := context.Background()
+	var client llmclient.Client
+	if req.LLM {
+		_ = godotenv.Load()
+		shared.ExportAPIKeys()
+		key := os.Getenv("GEMINI_API_KEY")
+		if key == "" {
+			return errors.New("GEMINI_API_KEY must be set to find names; pass -no-llm to skip")
+		}
+		c, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: key})
+		if err != nil {
+			return fmt.Errorf("init LLM client: %w", err)
+		}
+		client = llmclient.NewGenAI(c)
+	}
+	svc := workspace.NewService(cfg, client)
+	defer svc.Close()
+
+	resp, err := svc.Redact(ctx, req)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for _, r := range resp.Cases {
+		n += len(r)
+	}
+	fmt.Printf("Redacted %d cases into %s, %d placeholders\n", len(resp.Cases), req.OutDir, n)
+	if mapPath == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(mapPath, b)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runRename(args []string) error {
+	var (
+		cfg     = serviceConfig()
+		mapPath string
+		dryRun  bool
+	)
+	fs := newFlagSet("rename", &cfg.DatasetDir)
+	fs.StringVar(&mapPath, "map", "", "File of renames, a current and a new case ID per line; # starts a comment")
+	fs.BoolVar(&dryRun, "dry-run", false, "List the files to rename without renaming them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval rename [flags] case-id new-id")
+		fmt.Fprintln(fs.Output(), "       asr-eval rename [flags] -map file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	req := workspace.RenameCasesRequest{IDs: make(map[string]string), ValidateOnly: dryRun}
+	switch {
+	case mapPath == "" && fs.NArg() == 2:
+		req.IDs[fs.Arg(0)] = fs.Arg(1)
+	case mapPath != "" && fs.NArg() == 0:
+		if err := readRenameMap(mapPath, req.IDs); err != nil {
+			return err
+		}
+	default:
+		fs.Usage()
+		return errors.New("need a case ID and its new ID, or -map")
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	resp, err := svc.RenameCases(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	verb := "Renamed"
+	if dryRun {
+		verb = "Would rename"
+	}
+	for _, rc := range resp.Cases {
+		fmt.Printf("%s %s to %s: %d files", verb, rc.From, rc.To, len(rc.Files))
+		if len(rc.Runs) > 0 {
+			fmt.Printf(", runs %s", strings.Join(rc.Runs, ", "))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// readRenameMap adds the renames in the file at path to ids.
+func readRenameMap(path string, ids map[string]string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("%s:%d: want a case ID and its new ID", path, n)
+		}
+		if _, ok := ids[fields[0]]; ok {
+			return fmt.Errorf("%s:%d: %s is renamed twice", path, n, fields[0])
+		}
+		ids[fields[0]] = fields[1]
+	}
+	return sc.Err()
+}
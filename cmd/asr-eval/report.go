@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runExportHTML(args []string) error {
+	var (
+		cfg  = serviceConfig()
+		out  string
+		tags []string
+	)
+	fs := newFlagSet("export-html", &cfg.DatasetDir)
+	fs.StringVar(&out, "o", "report.zip", "Output file; - writes to stdout")
+	fs.Func("tag", "Report on the cases with this tag; repeatable", func(v string) error {
+		tags = append(tags, v)
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval export-html [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	var w io.Writer = os.Stdout
+	if out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return svc.ExportHTML(context.Background(), w, workspace.CaseFilter{Tags: tags})
+}
+
+func runExportLeaderboard(args []string) error {
+	var (
+		cfg    = serviceConfig()
+		out    string
+		format string
+		filter workspace.CaseFilter
+	)
+	fs := newFlagSet("export-leaderboard", &cfg.DatasetDir)
+	fs.StringVar(&out, "o", "-", "Output file; - writes to stdout")
+	fs.StringVar(&format, "format", "markdown", "Output format: markdown or json")
+	fs.Func("tag", "Rank on the cases with this tag; repeatable", func(v string) error {
+		filter.Tags = append(filter.Tags, v)
+		return nil
+	})
+	fs.StringVar(&filter.Run, "run", "", "Rank this run's reports instead of the current ones")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval export-leaderboard [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("unknown format %q, want markdown or json", format)
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	r, err := svc.LeaderboardReport(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+	var sel []string
+	if len(filter.Tags) > 0 {
+		sel = append(sel, "tags "+strings.Join(filter.Tags, ", "))
+	}
+	if filter.Run != "" {
+		sel = append(sel, "run "+filter.Run)
+	}
+	r.Filter = strings.Join(sel, "; ")
+
+	var w io.Writer = os.Stdout
+	if out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return r.WriteMarkdown(w)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runResetResults(args []string) error {
+	var (
+		cfg       = serviceConfig()
+		providers []string
+	)
+	fs := newFlagSet("reset-results", &cfg.DatasetDir)
+	fs.Func("provider", "Provider whose result to remove; repeatable", func(v string) error {
+		providers = append(providers, v)
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval reset-results -provider=<id> [flags] case-id...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if len(providers) == 0 || fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("need -provider and at least one case ID")
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	ctx := context.Background()
+	for _, id := range fs.Args() {
+		if _, err := svc.ResetResults(ctx, workspace.ResetResultsRequest{ID: id, ProviderIDs: providers}); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		fmt.Printf("Reset %s for %s.\n", strings.Join(providers, ", "), id)
+	}
+	return nil
+}
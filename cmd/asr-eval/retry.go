@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"asr-eval/pkg/batch"
+)
+
+func runRetryFailed(args []string) error {
+	fs := flag.NewFlagSet("asr-eval retry-failed", flag.ExitOnError)
+	list := fs.Bool("list", false, "Only list the failures with their error class and attempts")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval retry-failed [-list] <manifest> [transcribe-all flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return errors.New("missing manifest")
+	}
+	path := fs.Arg(0)
+
+	if *list {
+		m, err := batch.Open(path)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+		retryable := make(map[string]bool)
+		for _, item := range batch.Retryable(m, batch.DefaultPolicies) {
+			retryable[item] = true
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ITEM\tCLASS\tATTEMPTS\tRETRY\tERROR")
+		for _, f := range m.Failures() {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%t\t%s\n", f.Item, f.Class, f.Attempts, retryable[f.Item], f.Error)
+		}
+		return tw.Flush()
+	}
+
+	m, err := batch.Open(path)
+	if err != nil {
+		return err
+	}
+	tool := m.Tool
+	m.Close()
+	if tool != "transcribe-all" {
+		return fmt.Errorf("%s is a manifest of %s; retry it with %s -retry-failed %s", path, tool, tool, path)
+	}
+	return runTranscribeAll(append([]string{"-retry-failed", path}, fs.Args()[1:]...))
+}
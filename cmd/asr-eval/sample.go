@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runSample(args []string) error {
+	var (
+		cfg = serviceConfig()
+		req = workspace.SampleRequest{N: 50}
+		out string
+	)
+	fs := newFlagSet("sample", &cfg.DatasetDir)
+	fs.IntVar(&req.N, "n", req.N, "Cases to pick")
+	fs.Func("by", "Comma-separated dimensions to stratify by: "+strings.Join(workspace.SampleDimensions, ", ")+" (default all)", func(v string) error {
+		req.By = strings.Split(v, ",")
+		return nil
+	})
+	fs.Uint64Var(&req.Seed, "seed", 0, "Random seed; the same seed picks the same cases")
+	fs.Func("tag", "Sample the cases with this tag; repeatable", func(v string) error {
+		req.Tags = append(req.Tags, v)
+		return nil
+	})
+	fs.StringVar(&out, "o", "-", "File to write the IDs to, one a line; - writes to stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval sample [flags]")
+		fmt.Fprintln(fs.Output(), "Picks a stratified sample of cases and writes their IDs, for the -ids flag of batch_eval and transcribe-all.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	resp, err := svc.Sample(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "-" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	for _, id := range resp.IDs {
+		fmt.Fprintln(w, id)
+	}
+
+	// The strata go to stderr, keeping stdout a plain ID list.
+	tw := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "CASES\tPICKED\t STRATUM")
+	for _, st := range resp.Strata {
+		fmt.Fprintf(tw, "%d\t%d\t %s\n", st.Cases, st.Picked, st.Key)
+	}
+	tw.Flush()
+	fmt.Fprintf(os.Stderr, "Picked %d cases from %d strata\n", len(resp.IDs), len(resp.Strata))
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/workspace"
+)
+
+// Checkpoint status marks, each with an ANSI color of the same length so
+// colored columns still line up.
+var statusMarks = map[evalv2.CheckpointStatus]struct{ mark, color string }{
+	evalv2.StatusPass:    {"pass", "\x1b[32m"},
+	evalv2.StatusFail:    {"FAIL", "\x1b[31m"},
+	evalv2.StatusPartial: {"part", "\x1b[33m"},
+	"":                   {"-", "\x1b[90m"},
+}
+
+func runShow(args []string) error {
+	var (
+		cfg       = serviceConfig()
+		providers []string
+		against   string
+		colorMode string
+	)
+	fs := newFlagSet("show", &cfg.DatasetDir)
+	fs.Func("provider", "Show this provider only; repeatable", func(v string) error {
+		providers = append(providers, v)
+		return nil
+	})
+	fs.StringVar(&against, "against", "gt", "Diff transcripts against gt, audio_reality or revised")
+	fs.StringVar(&colorMode, "color", "auto", "Color the diffs: auto, always or never")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: asr-eval show [flags] <case-id>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("want one case ID")
+	}
+	var color bool
+	switch colorMode {
+	case "auto":
+		fi, err := os.Stdout.Stat()
+		color = err == nil && fi.Mode()&os.ModeCharDevice != 0 && os.Getenv("NO_COLOR") == ""
+	case "always":
+		color = true
+	case "never":
+	default:
+		return fmt.Errorf("unknown -color %q", colorMode)
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	ctx := context.Background()
+	c, err := svc.GetCase(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(providers) == 0 {
+		providers = slices.Sorted(maps.Keys(c.Transcripts))
+	}
+	bold := func(s string) string {
+		if color {
+			return "\x1b[1m" + s + "\x1b[0m"
+		}
+		return s
+	}
+
+	fmt.Println(bold("Case " + c.ID))
+	if len(c.Tags) > 0 {
+		fmt.Printf("Tags: %s\n", strings.Join(c.Tags, ", "))
+	}
+	if ec := c.EvalContext; ec != nil {
+		fmt.Printf("\n%s\n%s\n", bold("Ground truth"), ec.Meta.GroundTruth)
+		if ec.Meta.QuestionableGT {
+			fmt.Printf("(questionable: %s)\n", ec.Meta.QuestionableReason)
+		}
+		fmt.Printf("\n%s\n%s\n", bold("Audio reality"), ec.Meta.AudioRealityInference)
+	} else {
+		fmt.Println("\nNo eval context.")
+	}
+
+	var results map[string]evalv2.EvalResult
+	if c.ReportV2 != nil {
+		results = c.ReportV2.Results
+	}
+	for _, p := range providers {
+		header := p
+		if r, ok := results[p]; ok {
+			header += fmt.Sprintf("  Q %d  S %.2f  P %.2f", r.Metrics.QScore, r.Metrics.SScore, r.Metrics.PScore)
+		}
+		fmt.Printf("\n%s\n", bold(header))
+		d, err := svc.Diff(ctx, workspace.DiffRequest{ID: c.ID, Provider: p, Against: against})
+		if err != nil {
+			// Without the reference, show the transcript as is.
+			fmt.Printf("%s\n(no diff: %v)\n", c.Transcripts[p], err)
+			continue
+		}
+		fmt.Println(d.Alignment.Inline(color))
+		a := d.Alignment
+		fmt.Printf("Error rate %.1f%% (sub %d, del %d, ins %d of %d)\n", a.ErrorRate*100, a.Sub, a.Del, a.Ins, a.RefTokens)
+	}
+
+	if c.EvalContext == nil || len(c.EvalContext.Checkpoints) == 0 {
+		return nil
+	}
+	fmt.Printf("\n%s\n", bold("Checkpoints"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := slices.Clone(providers)
+	if color {
+		// Pad the names with an escape as long as the marks' colors.
+		for i, p := range header {
+			header[i] = "\x1b[39m" + p + "\x1b[0m"
+		}
+	}
+	fmt.Fprintf(w, "ID\tTier\tWeight\t%s\tText\n", strings.Join(header, "\t"))
+	for _, cp := range c.EvalContext.Checkpoints {
+		row := []string{cp.ID, fmt.Sprint(cp.Tier), fmt.Sprintf("%.2f", cp.Weight)}
+		for _, p := range providers {
+			m := statusMarks[results[p].CheckpointResults[cp.ID].Status]
+			if m.mark == "" {
+				m = statusMarks[""]
+			}
+			if color {
+				m.mark = m.color + m.mark + "\x1b[0m"
+			}
+			row = append(row, m.mark)
+		}
+		row = append(row, cp.TextSegment)
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
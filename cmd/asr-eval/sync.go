@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"asr-eval/pkg/workspace"
+)
+
+func runSync(args []string) error {
+	var (
+		cfg    = serviceConfig()
+		to     string
+		dryRun bool
+	)
+	fs := newFlagSet("sync", &cfg.DatasetDir)
+	fs.StringVar(&to, "to", "", "Dataset copy to bring up to date")
+	fs.BoolVar(&dryRun, "n", false, "Only count the files to copy and remove")
+	fs.Parse(args)
+	if to == "" {
+		fs.Usage()
+		return errors.New("need -to")
+	}
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	resp, err := svc.SyncDataset(context.Background(), to, dryRun)
+	if err != nil {
+		return err
+	}
+	verb := "Replayed"
+	if dryRun {
+		verb = "Would replay"
+	}
+	fmt.Printf("%s %d changes onto %s: %d files copied, %d removed, up to change %d.\n",
+		verb, resp.Changes, to, resp.Copied, resp.Deleted, resp.Seq)
+	return nil
+}
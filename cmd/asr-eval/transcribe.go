@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/batch"
+	"asr-eval/pkg/workspace"
+)
+
+func runTranscribeAll(args []string) error {
+	var (
+		cfg          = serviceConfig()
+		req          workspace.TranscriptionTasksRequest
+		def          = workspace.ProviderLimits{Concurrency: shared.ConcurrencyOr(4)}
+		limits       = make(map[string]workspace.ProviderLimits)
+		manifestPath string
+		resumePath   string
+		retryPath    string
+		dryRun       bool
+		tui          bool
+		watch        bool
+		poll         time.Duration
+		trimSilence  bool
+		trimGaps     time.Duration
+		live         bool
+		input        = audio.DefaultCaptureInput()
+		duration     time.Duration
+		liveContext  string
+	)
+	fs := newFlagSet("transcribe-all", &cfg.DatasetDir)
+	fs.Func("provider", "Provider to run; repeatable (default every enabled provider with an ASR client)", func(v string) error {
+		req.Providers = append(req.Providers, v)
+		return nil
+	})
+	fs.Func("ids", "File listing the cases to transcribe, one a line, as asr-eval sample writes; - reads stdin", func(v string) error {
+		ids, err := batch.ReadIDs(v)
+		req.IDs = ids
+		return err
+	})
+	fs.BoolVar(&req.Overwrite, "overwrite", false, "Also redo cases that have the provider's transcript")
+	fs.IntVar(&def.Concurrency, "concurrency", def.Concurrency, "Calls at once per provider")
+	fs.IntVar(&def.RatePerMinute, "rate", 0, "Calls started a minute per provider (0 = no limit)")
+	fs.Func("limit", "Provider's own limits as provider=concurrency[:rate]; repeatable", func(v string) error {
+		p, l, err := parseLimit(v)
+		if err != nil {
+			return err
+		}
+		limits[p] = l
+		return nil
+	})
+	fs.StringVar(&manifestPath, "manifest", "", "Run manifest to write (default transcribe-all-<time>.manifest.jsonl)")
+	fs.StringVar(&resumePath, "resume", "", "Continue the run recorded in this manifest, with its flags")
+	fs.StringVar(&retryPath, "retry-failed", "", "Retry the failed transcriptions of this manifest, with backoff by error class")
+	fs.BoolVar(&dryRun, "n", false, "Only list the transcriptions to run")
+	fs.BoolVar(&tui, "tui", false, "Show a live dashboard of the run instead of a line per transcription")
+	fs.BoolVar(&watch, "watch", false, "Keep running, transcribing audio files as they are added to the dataset")
+	fs.DurationVar(&poll, "poll", 10*time.Second, "With -watch, how often to look for new audio when the dataset cannot be watched")
+	fs.BoolVar(&trimSilence, "trim-silence", false, "Trim leading and trailing silence from the audio before sending it")
+	fs.DurationVar(&trimGaps, "trim-gaps", 0, "With -trim-silence, also shorten silences within the audio longer than this (0 = keep them)")
+	fs.BoolVar(&live, "live", false, "Record the microphone, or a call sent as RTP, and stream it to the -provider realtime providers side by side, saving it as a new case")
+	fs.StringVar(&input, "input", input, "With -live, the ffmpeg input to record as <format>:<device>, such as alsa:hw:0 or dshow:audio=<name>, or rtp://[host]:port[?codec=pcmu|pcma|l16&rate=<Hz>] to receive a call on")
+	fs.DurationVar(&duration, "duration", 0, "With -live, stop recording after this long (0 = when interrupted)")
+	fs.StringVar(&liveContext, "context", "", "With -live, biasing context for the providers that take one")
+	fs.Parse(args)
+
+	var manifest *batch.Manifest
+	if retryPath != "" {
+		resumePath = retryPath
+	}
+	if resumePath != "" {
+		var err error
+		if manifest, err = batch.Resume(fs, resumePath, "transcribe-all", args); err != nil {
+			return err
+		}
+		defer manifest.Close()
+		manifestPath = resumePath
+	}
+
+	if trimSilence {
+		cfg.TrimSilence = &audio.TrimOptions{MaxGapMS: trimGaps.Milliseconds()}
+	} else if trimGaps > 0 {
+		return errors.New("-trim-gaps needs -trim-silence")
+	}
+
+	_ = godotenv.Load()
+	shared.ExportAPIKeys()
+
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+	if live {
+		if manifest != nil || dryRun || watch {
+			return errors.New("-live does not combine with -resume, -retry-failed, -n or -watch")
+		}
+		return liveTranscription(svc, req.Providers, input, liveContext, duration)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if retryPath != "" {
+		return retryTranscriptions(ctx, svc, manifest, def.Concurrency, dryRun)
+	}
+	if watch {
+		if manifest != nil || dryRun {
+			return errors.New("-watch does not combine with -resume or -n")
+		}
+		return watchTranscriptions(ctx, svc, req, limits, def, poll, manifestPath, args, tui)
+	}
+
+	var tasks []workspace.TranscriptionTask
+	if manifest != nil {
+		for _, item := range manifest.Items(batch.StatusPending) {
+			t, err := workspace.ParseTranscriptionTask(item)
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, t)
+		}
+	} else {
+		var err error
+		if tasks, err = svc.TranscriptionTasks(ctx, req); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		counts := make(map[string]int)
+		for _, t := range tasks {
+			fmt.Println(t)
+			counts[t.Provider]++
+		}
+		fmt.Printf("Would run %d transcriptions: %v\n", len(tasks), counts)
+		return nil
+	}
+	if manifest == nil {
+		if manifestPath == "" {
+			manifestPath = batch.DefaultPath("transcribe-all")
+		}
+		items := make([]string, len(tasks))
+		for i, t := range tasks {
+			items[i] = t.String()
+		}
+		var err error
+		if manifest, err = batch.Create(manifestPath, "transcribe-all", args, items); err != nil {
+			return err
+		}
+		defer manifest.Close()
+		fmt.Printf("Recording progress in %s\n", manifestPath)
+	}
+
+	var (
+		mu       sync.Mutex
+		finished int
+		dash     *batch.Dashboard
+		started  func(workspace.TranscriptionTask)
+	)
+	if tui {
+		dash = batch.NewDashboard("transcribe-all", len(tasks))
+		started = func(t workspace.TranscriptionTask) { dash.Start(t.Provider, t.ID) }
+		defer dash.Show(os.Stderr, 500*time.Millisecond)()
+	}
+	svc.TranscribeAll(ctx, tasks, limits, def, started, func(t workspace.TranscriptionTask, err error) {
+		if dash != nil {
+			dash.Finish(t.Provider, t.ID, err)
+		}
+		mu.Lock()
+		finished++
+		n := finished
+		mu.Unlock()
+		if err != nil {
+			fmt.Printf("[%d/%d] %s: %v\n", n, len(tasks), t, err)
+		} else {
+			fmt.Printf("[%d/%d] %s\n", n, len(tasks), t)
+		}
+		if err := manifest.Finish(t.String(), err); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to record %s in manifest: %v\n", t, err)
+		}
+	})
+
+	failed := len(manifest.Items(batch.StatusFailed))
+	pending := len(manifest.Items(batch.StatusPending))
+	fmt.Printf("Finished %d of %d transcriptions; %d failed, %d pending.\n", finished, len(tasks), failed, pending)
+	if pending > 0 {
+		return fmt.Errorf("interrupted; continue with -resume %s", manifestPath)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d transcriptions failed", failed)
+	}
+	return ctx.Err()
+}
+
+// watchTranscriptions runs the transcriptions of req and those of audio
+// files added later, recording them in a new manifest, until ctx is done.
+func watchTranscriptions(ctx context.Context, svc *workspace.Service, req workspace.TranscriptionTasksRequest, limits map[string]workspace.ProviderLimits, def workspace.ProviderLimits, poll time.Duration, manifestPath string, args []string, tui bool) error {
+	if manifestPath == "" {
+		manifestPath = batch.DefaultPath("transcribe-all")
+	}
+	manifest, err := batch.Create(manifestPath, "transcribe-all", args, nil)
+	if err != nil {
+		return err
+	}
+	defer manifest.Close()
+	fmt.Printf("Watching %s for new audio, recording progress in %s; interrupt to stop\n", svc.Config.DatasetDir, manifestPath)
+	if !svc.Watched() {
+		fmt.Printf("The dataset cannot be watched; looking for new audio every %s\n", poll)
+	}
+
+	var dash *batch.Dashboard
+	var started func(workspace.TranscriptionTask)
+	if tui {
+		dash = batch.NewDashboard("transcribe-all -watch", 0)
+		started = func(t workspace.TranscriptionTask) { dash.Start(t.Provider, t.ID) }
+		defer dash.Show(os.Stderr, 500*time.Millisecond)()
+	}
+	err = svc.WatchTranscriptions(ctx, req, limits, def, poll, started, func(t workspace.TranscriptionTask, err error) {
+		if dash != nil {
+			dash.Finish(t.Provider, t.ID, err)
+		}
+		if err != nil {
+			fmt.Printf("%s: %v\n", t, err)
+		} else {
+			fmt.Println(t)
+		}
+		if err := manifest.Finish(t.String(), err); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to record %s in manifest: %v\n", t, err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if failed := manifest.Failures(); len(failed) > 0 {
+		return fmt.Errorf("%d transcriptions failed; retry them with asr-eval retry-failed %s", len(failed), manifestPath)
+	}
+	return nil
+}
+
+// retryTranscriptions retries the failed transcriptions of manifest until
+// the default policies give up on them.
+func retryTranscriptions(ctx context.Context, svc *workspace.Service, manifest *batch.Manifest, concurrency int, dryRun bool) error {
+	items := batch.Retryable(manifest, batch.DefaultPolicies)
+	if dryRun {
+		for _, item := range items {
+			fmt.Println(item)
+		}
+		fmt.Printf("Would retry %d transcriptions.\n", len(items))
+		return nil
+	}
+	fmt.Printf("Retrying %d transcriptions\n", len(items))
+	err := batch.Retry(ctx, manifest, batch.DefaultPolicies, concurrency, func(ctx context.Context, item string) error {
+		t, err := workspace.ParseTranscriptionTask(item)
+		if err != nil {
+			return err
+		}
+		if _, err = svc.Transcribe(ctx, workspace.TranscribeRequest{ID: t.ID, Provider: t.Provider}); err != nil {
+			fmt.Printf("%s: %v (%s)\n", t, err, batch.Classify(err))
+		} else {
+			fmt.Println(t)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if failed := manifest.Failures(); len(failed) > 0 {
+		return fmt.Errorf("%d transcriptions still failed; see asr-eval retry-failed -list %s", len(failed), manifest.Path())
+	}
+	return nil
+}
+
+// parseLimit parses a -limit value, provider=concurrency[:rate].
+func parseLimit(v string) (string, workspace.ProviderLimits, error) {
+	var l workspace.ProviderLimits
+	p, spec, ok := strings.Cut(v, "=")
+	if !ok || p == "" {
+		return "", l, fmt.Errorf("want provider=concurrency[:rate], got %q", v)
+	}
+	c, r, hasRate := strings.Cut(spec, ":")
+	var err error
+	if l.Concurrency, err = strconv.Atoi(c); err != nil {
+		return "", l, fmt.Errorf("bad concurrency in %q", v)
+	}
+	if hasRate {
+		if l.RatePerMinute, err = strconv.Atoi(r); err != nil {
+			return "", l, fmt.Errorf("bad rate in %q", v)
+		}
+	}
+	return p, l, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/workspace"
+)
+
+// dryRun prints the LLM calls the batch would make for cases and the
+// prompt tokens they would send, reading the dataset only.
+func dryRun(ctx context.Context, svc *workspace.Service, cases []*workspace.Case, pricePerMTok float64) {
+	judges := cfg.JudgeModels
+	if len(judges) < 2 {
+		judges = []string{cfg.EvalModel}
+	}
+	providers := svc.EnabledProviderIDs()
+
+	var genCalls, evalCalls, genTokens, evalTokens, skipped int
+	for _, c := range cases {
+		full, err := svc.GetCase(ctx, c.ID)
+		if err != nil {
+			fmt.Printf("[%s] Skip: %v\n", c.ID, err)
+			skipped++
+			continue
+		}
+		ec := full.EvalContext
+		if !onlyStale {
+			groundTruth, source, err := contextSource(ctx, svc, c)
+			if err != nil {
+				fmt.Printf("[%s] Skip: %v\n", c.ID, err)
+				skipped++
+				continue
+			}
+			if groundTruth != "" {
+				var durationMS int64
+				if info, err := audio.ReadInfo(filepath.Join(cfg.DatasetDir, c.ID+".flac")); err == nil {
+					durationMS = info.DurationMS
+				}
+				n, err := evalv2.EstimateGenerateContextTokens(groundTruth, full.Transcripts, durationMS)
+				if err != nil {
+					fmt.Printf("[%s] Skip: %v\n", c.ID, err)
+					skipped++
+					continue
+				}
+				fmt.Printf("[%s] Would generate context with %s (source: %s), ~%d tokens\n", c.ID, cfg.GenModel, source, n)
+				genCalls++
+				genTokens += n
+				// Estimate the evaluation with the ground truth standing in
+				// for the context yet to be generated.
+				ec = &evalv2.EvalContext{Meta: evalv2.ContextMeta{GroundTruth: groundTruth}}
+			}
+		}
+		if len(providers) == 0 {
+			continue
+		}
+		transcripts := make(map[string]string)
+		for _, p := range providers {
+			if t, ok := full.Transcripts[p]; ok {
+				transcripts[p] = t
+			}
+		}
+		n, err := evalv2.EstimateEvaluateTokens(ec, transcripts)
+		if err != nil {
+			fmt.Printf("[%s] Skip: %v\n", c.ID, err)
+			skipped++
+			continue
+		}
+		fmt.Printf("[%s] Would evaluate %d providers with %v, ~%d tokens each\n", c.ID, len(transcripts), judges, n)
+		evalCalls += len(judges)
+		evalTokens += n * len(judges)
+	}
+
+	fmt.Printf("Dry run: %d cases, %d skipped.\n", len(cases), skipped)
+	fmt.Printf("  Context generation: %d calls to %s, ~%d prompt tokens\n", genCalls, cfg.GenModel, genTokens)
+	fmt.Printf("  Evaluation: %d calls to %v, ~%d prompt tokens\n", evalCalls, judges, evalTokens)
+	if pricePerMTok > 0 {
+		fmt.Printf("  Estimated prompt cost: $%.2f\n", float64(genTokens+evalTokens)*pricePerMTok/1e6)
+	}
+	fmt.Println("Output and thinking tokens are not included.")
+}
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"asr-eval/pkg/batch"
+	"asr-eval/pkg/llmclient"
 	"asr-eval/pkg/workspace"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/genai"
@@ -17,32 +24,172 @@ var (
 	cfg               = workspace.DefaultServiceConfig()
 	concurrency       = 10
 	defaultGTProvider = "txt"
+	listStale         = false
+	onlyStale         = false
+	manifestPath      = ""
+	resumePath        = ""
+	retryPath         = ""
+	dryRunOnly        = false
+	pricePerMTok      = 0.0
+	tui               = false
+	tokensPerMinute   = 0
+	idsPath           = ""
+	recheck           = ""
+	recheckBelow      = workspace.DefaultRecheckBelow
+	manifest          *batch.Manifest
+	dash              *batch.Dashboard // Nil unless -tui
+
+	outcomesMu sync.Mutex
+	outcomes   []workspace.CaseOutcome // Evaluations run, for the webhook summary
 )
 
 func main() {
+	shared, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
 	flag.StringVar(&cfg.DatasetDir, "dataset-dir", cfg.DatasetDir, "Directory containing transcripts and audio files")
 	flag.StringVar(&cfg.GenModel, "gen-model", cfg.GenModel, "LLM model to use for context generation")
 	flag.StringVar(&cfg.EvalModel, "eval-model", cfg.EvalModel, "LLM model to use for evaluation")
-	flag.IntVar(&concurrency, "concurrency", concurrency, "Number of concurrent workers (applied to both pools)")
+	flag.Func("fallback-models", "Comma-separated LLM models to fall back to on quota/availability errors", func(v string) error {
+		cfg.FallbackModels = strings.Split(v, ",")
+		return nil
+	})
+	flag.IntVar(&cfg.MaxPromptTokens, "max-prompt-tokens", cfg.MaxPromptTokens, "Token budget for evaluation prompts before truncation (0 = no limit)")
+	flag.Func("judge-models", "Comma-separated judge models for multi-judge consensus evaluation", func(v string) error {
+		cfg.JudgeModels = strings.Split(v, ",")
+		return nil
+	})
+	flag.IntVar(&concurrency, "concurrency", concurrency, "Number of concurrent workers (applied to both pools); LLM calls back off below it when rate limited")
+	flag.IntVar(&tokensPerMinute, "tpm", tokensPerMinute, "Tokens a minute to stay under for each LLM model (0 = no limit)")
 	flag.StringVar(&defaultGTProvider, "default-gt-provider", defaultGTProvider, "Provider ID to use as initial Ground Truth")
+	flag.BoolVar(&listStale, "list-stale", listStale, "List cases whose report predates their current context and exit")
+	flag.StringVar(&idsPath, "ids", idsPath, "File listing the cases to run, one a line, as asr-eval sample writes")
+	flag.BoolVar(&onlyStale, "only-stale", onlyStale, "Only re-evaluate cases whose report predates their current context, skipping context generation")
+	flag.StringVar(&recheck, "recheck", recheck, "Only re-evaluate this provider, on the cases where it scored below -recheck-below or failed a Tier-1 checkpoint")
+	flag.IntVar(&recheckBelow, "recheck-below", recheckBelow, "Q score below which -recheck re-evaluates a result")
+	flag.StringVar(&cfg.WebhookURL, "webhook-url", cfg.WebhookURL, "Chat webhook to post a summary to when the batch finishes")
+	flag.StringVar(&cfg.WebhookFormat, "webhook-format", cfg.WebhookFormat, "Webhook payload format: slack or feishu")
+	flag.StringVar(&manifestPath, "manifest", manifestPath, "Run manifest to write (default batch_eval-<time>.manifest.jsonl)")
+	flag.StringVar(&resumePath, "resume", resumePath, "Continue the run recorded in this manifest, with its flags")
+	flag.StringVar(&retryPath, "retry-failed", retryPath, "Retry the failed cases of this manifest, with backoff by error class")
+	flag.BoolVar(&dryRunOnly, "dry-run", dryRunOnly, "Print the cases and LLM calls the run would make, with estimated tokens, without calling any API or writing files")
+	flag.Float64Var(&pricePerMTok, "price", pricePerMTok, "USD per million prompt tokens, to estimate the cost of a dry run")
+	flag.BoolVar(&tui, "tui", tui, "Show a live dashboard of the run instead of the log")
 	flag.Parse()
 
+	if retryPath != "" {
+		resumePath = retryPath
+	}
+	if resumePath != "" {
+		var err error
+		manifest, err = batch.Resume(flag.CommandLine, resumePath, "batch_eval", os.Args[1:])
+		if err != nil {
+			log.Fatalf("Failed to resume: %v", err)
+		}
+		defer manifest.Close()
+	}
+
 	_ = godotenv.Load()
+	shared.ExportAPIKeys()
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{APIKey: apiKey})
-	if err != nil {
-		log.Fatalf("Failed to init LLM client: %v", err)
+	var llm llmclient.Client
+	if !dryRunOnly {
+		apiKey := os.Getenv("GEMINI_API_KEY")
+		client, err := genai.NewClient(context.Background(), &genai.ClientConfig{APIKey: apiKey})
+		if err != nil {
+			log.Fatalf("Failed to init LLM client: %v", err)
+		}
+		// The workers of both pools share one adaptive client, which
+		// finds the concurrency each model's quota sustains.
+		llm = llmclient.NewAdaptive(llmclient.NewDefaultRouter(llmclient.NewGenAI(client)), llmclient.AdaptiveOptions{
+			MaxConcurrency:  2 * concurrency,
+			TokensPerMinute: tokensPerMinute,
+		})
 	}
 
-	svc := workspace.NewService(cfg, client)
+	cfg.Overrides = workspace.FlagOverrides(flag.CommandLine, &cfg)
+	svc := workspace.NewService(cfg, llm)
+	defer svc.Close()
 	ctx := context.Background()
 
-	cases, err := svc.ListCases(ctx)
+	if retryPath != "" {
+		retry(ctx, svc)
+		return
+	}
+
+	if listStale {
+		resp, err := svc.ListStale(ctx, workspace.CaseFilter{})
+		if err != nil {
+			log.Fatalf("Failed to list stale cases: %v", err)
+		}
+		for _, c := range resp.Cases {
+			fmt.Printf("%s\treport=%s\tcontext=%s\n", c.ID, c.ReportHash, c.ContextHash)
+		}
+		fmt.Printf("%d stale cases.\n", len(resp.Cases))
+		return
+	}
+
+	resp, err := svc.ListCases(ctx, workspace.ListCasesRequest{})
 	if err != nil {
 		log.Fatalf("Failed to list cases: %v", err)
 	}
+	cases := resp.Cases
+	if onlyStale && manifest == nil {
+		stale, err := svc.ListStale(ctx, workspace.CaseFilter{})
+		if err != nil {
+			log.Fatalf("Failed to list stale cases: %v", err)
+		}
+		ids := make(map[string]bool, len(stale.Cases))
+		for _, c := range stale.Cases {
+			ids[c.ID] = true
+		}
+		cases = slices.DeleteFunc(cases, func(c *workspace.Case) bool { return !ids[c.ID] })
+	}
+
+	if recheck != "" && manifest == nil {
+		cases = slices.DeleteFunc(cases, func(c *workspace.Case) bool { return !workspace.NeedsRecheck(c, recheck, recheckBelow) })
+	}
+	if idsPath != "" && manifest == nil {
+		ids, err := batch.ReadIDs(idsPath)
+		if err != nil {
+			log.Fatalf("Failed to read case IDs: %v", err)
+		}
+		cases = slices.DeleteFunc(cases, func(c *workspace.Case) bool { return !slices.Contains(ids, c.ID) })
+	}
 
+	if manifest != nil {
+		pending := make(map[string]bool)
+		for _, id := range manifest.Items(batch.StatusPending) {
+			pending[id] = true
+		}
+		cases = slices.DeleteFunc(cases, func(c *workspace.Case) bool { return !pending[c.ID] })
+	}
+	if dryRunOnly {
+		dryRun(ctx, svc, cases, pricePerMTok)
+		return
+	}
+	if manifest == nil {
+		if manifestPath == "" {
+			manifestPath = batch.DefaultPath("batch_eval")
+		}
+		ids := make([]string, len(cases))
+		for i, c := range cases {
+			ids[i] = c.ID
+		}
+		manifest, err = batch.Create(manifestPath, "batch_eval", os.Args[1:], ids)
+		if err != nil {
+			log.Fatalf("Failed to create manifest: %v", err)
+		}
+		defer manifest.Close()
+		fmt.Printf("Recording progress in %s\n", manifestPath)
+	}
+
+	if tui {
+		dash = batch.NewDashboard("batch_eval", len(cases))
+		stop := dash.Show(os.Stderr, 500*time.Millisecond)
+		defer stop()
+	}
 	fmt.Printf("Found %d cases. Starting pipeline with concurrency %d for both Gen and Eval...\n", len(cases), concurrency)
 
 	// Channels for the pipeline
@@ -59,7 +206,10 @@ func main() {
 		go func() {
 			defer wgEval.Done()
 			for c := range evalQueue {
-				processEvaluation(ctx, svc, c)
+				started("evaluate", c.ID)
+				err := processEvaluation(ctx, svc, c)
+				finished("evaluate", c.ID, err)
+				record(c.ID, err)
 			}
 		}()
 	}
@@ -70,11 +220,22 @@ func main() {
 		go func() {
 			defer wgGen.Done()
 			for c := range genQueue {
+				// Stale cases already have a context; only the report is out of
+				// date. Rechecks judge the same context again.
+				if onlyStale || recheck != "" {
+					evalQueue <- c
+					continue
+				}
 				// Process Generation checks/actions
 				// If successful (or no gen needed), pass to Eval Queue
-				if updatedC, ok := processGeneration(ctx, svc, c); ok {
-					evalQueue <- updatedC
+				started("generate", c.ID)
+				updatedC, err := processGeneration(ctx, svc, c)
+				finished("generate", c.ID, err)
+				if err != nil {
+					record(c.ID, err)
+					continue
 				}
+				evalQueue <- updatedC
 			}
 		}()
 	}
@@ -92,18 +253,79 @@ func main() {
 	// Wait for Evaluation to finish
 	wgEval.Wait()
 
-	fmt.Println("Batch execution complete.")
+	fmt.Printf("Batch execution complete; %d cases failed.\n", len(manifest.Items(batch.StatusFailed)))
+
+	if cfg.WebhookURL != "" && len(outcomes) > 0 {
+		slices.SortFunc(outcomes, func(a, b workspace.CaseOutcome) int { return strings.Compare(a.CaseID, b.CaseID) })
+		summary := &workspace.RunSummary{Dataset: filepath.Base(filepath.Clean(cfg.DatasetDir)), Kind: "batch_eval", Cases: outcomes}
+		if err := workspace.PostWebhook(ctx, cfg.WebhookURL, cfg.WebhookFormat, summary); err != nil {
+			log.Printf("Failed to post webhook: %v", err)
+		}
+	}
+}
+
+// loadConfig sets cfg and concurrency from asr-eval.yaml, before the flags
+// override them.
+func loadConfig() (*workspace.SharedConfig, error) {
+	c, shared, err := workspace.LoadServiceConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg = c
+	concurrency = shared.ConcurrencyOr(concurrency)
+	return shared, nil
+}
+
+// started and finished report a stage of case id to the dashboard, if
+// shown.
+func started(stage, id string) {
+	if dash != nil {
+		dash.Start(stage, id)
+	}
+}
+
+func finished(stage, id string, err error) {
+	if dash != nil {
+		dash.Finish(stage, id, err)
+	}
+}
+
+// record notes the outcome of case id in the run manifest.
+func record(id string, err error) {
+	if err := manifest.Finish(id, err); err != nil {
+		log.Printf("[%s] Failed to record in manifest: %v", id, err)
+	}
 }
 
-// processGeneration returns the (potentially updated) case and true if the case is ready for evaluation
-func processGeneration(ctx context.Context, svc *workspace.Service, c *workspace.Case) (*workspace.Case, bool) {
+// retry runs the failed cases of the manifest again, until the default
+// policies give up on them.
+func retry(ctx context.Context, svc *workspace.Service) {
+	fmt.Printf("Retrying %d cases\n", len(batch.Retryable(manifest, batch.DefaultPolicies)))
+	err := batch.Retry(ctx, manifest, batch.DefaultPolicies, concurrency, func(ctx context.Context, id string) error {
+		c, err := svc.GetCase(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !onlyStale && recheck == "" {
+			if c, err = processGeneration(ctx, svc, c); err != nil {
+				return err
+			}
+		}
+		return processEvaluation(ctx, svc, c)
+	})
+	if err != nil {
+		log.Fatalf("Retry failed: %v", err)
+	}
+	fmt.Printf("Retry complete; %d cases still failed.\n", len(manifest.Items(batch.StatusFailed)))
+}
+
+// contextSource returns the ground truth to generate c's context from and
+// where it came from, or an empty ground truth when c can be evaluated
+// with its current context.
+func contextSource(ctx context.Context, svc *workspace.Service, c *workspace.Case) (groundTruth, source string, err error) {
 	// Optimization: Only fetch full case if we actually need to generate context.
 	// We rely on ListCases providing a popualted EvalContext (if it exists).
 
-	needsContextGen := false
-	var groundTruth string
-	var source string
-
 	if c.EvalContext == nil {
 		// Case A: Missing Context
 		// We need to check if default provider exists.
@@ -112,32 +334,35 @@ func processGeneration(ctx context.Context, svc *workspace.Service, c *workspace
 		fullCase, err := svc.GetCase(ctx, c.ID)
 		if err != nil {
 			log.Printf("[%s] Failed to get full case details: %v", c.ID, err)
-			return nil, false
+			return "", "", err
 		}
 
 		if gt, ok := fullCase.Transcripts[defaultGTProvider]; ok {
-			groundTruth = gt
-			needsContextGen = true
-			source = "default_provider (" + defaultGTProvider + ")"
-		} else {
-			log.Printf("[%s] Skipping context gen: default GT provider '%s' not found", c.ID, defaultGTProvider)
-			// Cannot evaluate if no context
-			return nil, false
+			return gt, "default_provider (" + defaultGTProvider + ")", nil
 		}
+		log.Printf("[%s] Skipping context gen: default GT provider '%s' not found", c.ID, defaultGTProvider)
+		// Cannot evaluate if no context
+		return "", "", fmt.Errorf("no context and default GT provider %q not found", defaultGTProvider)
 	} else if c.EvalContext.Meta.QuestionableGT {
 		// Case B: Questionable GT
 		// We have EvalContext, so we can check fields directly.
 		if c.EvalContext.Meta.AudioRealityInference != "" {
-			groundTruth = c.EvalContext.Meta.AudioRealityInference
-			needsContextGen = true
-			source = "audio_reality_inference"
-		} else {
-			log.Printf("[%s] Skipping context regen: Questionable GT but no Audio Reality Inference", c.ID)
-			// We can still proceed to evaluate with the existing (questionable) context.
+			return c.EvalContext.Meta.AudioRealityInference, "audio_reality_inference", nil
 		}
+		log.Printf("[%s] Skipping context regen: Questionable GT but no Audio Reality Inference", c.ID)
+		// We can still proceed to evaluate with the existing (questionable) context.
 	}
+	return "", "", nil
+}
 
-	if needsContextGen {
+// processGeneration returns the (potentially updated) case if it is ready for evaluation
+func processGeneration(ctx context.Context, svc *workspace.Service, c *workspace.Case) (*workspace.Case, error) {
+	groundTruth, source, err := contextSource(ctx, svc, c)
+	if err != nil {
+		return nil, err
+	}
+
+	if groundTruth != "" {
 		fmt.Printf("[%s] Generating Context (Source: %s)...\n", c.ID, source)
 		req := workspace.GenerateContextRequest{
 			ID:          c.ID,
@@ -146,7 +371,7 @@ func processGeneration(ctx context.Context, svc *workspace.Service, c *workspace
 		newCtx, err := svc.GenerateContext(ctx, req)
 		if err != nil {
 			log.Printf("[%s] Failed to generate context: %v", c.ID, err)
-			return nil, false
+			return nil, err
 		}
 
 		// Save Context
@@ -157,16 +382,16 @@ func processGeneration(ctx context.Context, svc *workspace.Service, c *workspace
 		updatedCase, err := svc.UpdateContext(ctx, updateReq)
 		if err != nil {
 			log.Printf("[%s] Failed to save context: %v", c.ID, err)
-			return nil, false
+			return nil, err
 		}
 		fmt.Printf("[%s] Context saved.\n", c.ID)
-		return updatedCase, true
+		return updatedCase, nil
 	}
 
-	return c, true
+	return c, nil
 }
 
-func processEvaluation(ctx context.Context, svc *workspace.Service, c *workspace.Case) {
+func processEvaluation(ctx context.Context, svc *workspace.Service, c *workspace.Case) error {
 	// We use 'c' directly. It should have EvalContext.
 	// Note: svc.Evaluate will still fetch Transcripts internally (GetCase).
 	// But we avoid fetching here in main.
@@ -174,14 +399,12 @@ func processEvaluation(ctx context.Context, svc *workspace.Service, c *workspace
 	if c.EvalContext == nil {
 		// Should not happen given logic in main/processGen, but safe guard
 		log.Printf("[%s] Skipping evaluation: No EvalContext", c.ID)
-		return
+		return errors.New("no context")
 	}
 
-	enabledProviders := make([]string, 0)
-	for p, enabled := range svc.Config.EnabledProviders {
-		if enabled {
-			enabledProviders = append(enabledProviders, p)
-		}
+	enabledProviders := svc.EnabledProviderIDs()
+	if recheck != "" {
+		enabledProviders = []string{recheck}
 	}
 
 	if len(enabledProviders) > 0 {
@@ -191,11 +414,22 @@ func processEvaluation(ctx context.Context, svc *workspace.Service, c *workspace
 			EvalContext: c.EvalContext,
 			ProviderIDs: enabledProviders,
 		}
-		_, err := svc.Evaluate(ctx, evalReq)
+		report, err := svc.Evaluate(ctx, evalReq)
+		outcome := workspace.CaseOutcome{CaseID: c.ID}
 		if err != nil {
 			log.Printf("[%s] Failed to evaluate: %v", c.ID, err)
+			outcome.Error = err.Error()
 		} else {
 			fmt.Printf("[%s] Evaluation complete.\n", c.ID)
+			outcome.Scores = make(map[string]int, len(report.Results))
+			for p, r := range report.Results {
+				outcome.Scores[p] = r.Metrics.QScore
+			}
 		}
+		outcomesMu.Lock()
+		outcomes = append(outcomes, outcome)
+		outcomesMu.Unlock()
+		return err
 	}
+	return nil
 }
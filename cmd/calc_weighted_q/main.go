@@ -1,132 +1,141 @@
 package main
 
 import (
-	"asr-eval/pkg/evalv2"
-	"encoding/json"
+	"asr-eval/pkg/workspace"
+	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
-	"sort"
-	"strings"
 	"text/tabwriter"
 )
 
 func main() {
-	var datasetDir string
-	flag.StringVar(&datasetDir, "dataset-dir", "transcripts_and_audios", "Directory containing transcripts and audio files")
+	cfg, _, err := workspace.LoadServiceConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	var run, compare string
+	flag.StringVar(&cfg.DatasetDir, "dataset-dir", cfg.DatasetDir, "Directory containing transcripts and audio files")
+	flag.StringVar(&run, "run", "", "Score this run's reports instead of the current ones")
+	flag.StringVar(&compare, "compare", "", "Compare -run against this run over the cases both evaluated")
 	flag.Parse()
 
-	// Provider stats
-	type providerStats struct {
-		WeightedSum float64 // Q Score sum
-		WeightedS   float64 // S Score sum
-		WeightedP   float64 // P Score sum
-		TotalTokens int
-		Count       int
+	if compare != "" {
+		if run == "" {
+			log.Fatalf("-compare needs -run")
+		}
+		compareRuns(cfg, run, compare)
+		return
+	}
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
+
+	cases, err := listCases(context.Background(), svc, run)
+	if err != nil {
+		log.Fatalf("Error listing cases: %v", err)
 	}
-	stats := make(map[string]*providerStats)
 
-	// Walk through the dataset directory
-	err := filepath.Walk(datasetDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	// Every provider counts, enabled or not.
+	lb := workspace.Leaderboard{SScoreWeight: svc.Config.SScoreWeight}
+	for _, c := range cases {
+		if c.ReportV2 == nil {
+			continue
 		}
-		if info.IsDir() {
-			return nil
+		// Get token count estimate
+		tokenCount := c.ReportV2.ContextSnapshot.Meta.TotalTokenCountEstimate
+		if tokenCount <= 0 && c.EvalContext != nil {
+			// Fallback: the case's current context
+			tokenCount = c.EvalContext.Meta.TotalTokenCountEstimate
 		}
+		lb.Add(c.ReportV2, tokenCount)
+	}
 
-		// Look for .report.v2.json files
-		if strings.HasSuffix(info.Name(), ".report.v2.json") {
-			content, err := ioutil.ReadFile(path)
-			if err != nil {
-				log.Printf("Error reading file %s: %v", path, err)
-				return nil
-			}
+	// Output results
+	if run != "" {
+		fmt.Printf("Weighted Q Scores (Dataset: %s, Run: %s)\n", cfg.DatasetDir, run)
+	} else {
+		fmt.Printf("Weighted Q Scores (Dataset: %s)\n", cfg.DatasetDir)
+	}
+	fmt.Println("--------------------------------------------------")
 
-			var report evalv2.EvalReport
-			if err := json.Unmarshal(content, &report); err != nil {
-				log.Printf("Error parsing JSON in %s: %v", path, err)
-				return nil
-			}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Provider\tWeighted Q\tWeighted S\tWeighted P\tTotal Tokens\tCases")
 
-			// Get token count estimate
-			tokenCount := report.ContextSnapshot.Meta.TotalTokenCountEstimate
-			if tokenCount <= 0 {
-				// Fallback: Try to read from [id].gt.v2.json
-				gtFilename := strings.Replace(info.Name(), ".report.v2.json", ".gt.v2.json", 1)
-				gtPath := filepath.Join(filepath.Dir(path), gtFilename)
-				if gtContent, err := ioutil.ReadFile(gtPath); err == nil {
-					var ctx evalv2.EvalContext
-					if err := json.Unmarshal(gtContent, &ctx); err == nil {
-						tokenCount = ctx.Meta.TotalTokenCountEstimate
-					}
-				}
-			}
+	for _, s := range lb.Rankings() {
+		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%d\t%d\n", s.Provider, s.WeightedQ, s.WeightedS, s.WeightedP, s.TotalTokens, s.Cases)
+	}
+	w.Flush()
+}
 
-			if tokenCount <= 0 {
-				// fmt.Printf("DEBUG: Skipping %s (TokenCount=%d)\n", info.Name(), tokenCount)
-				return nil
+// listCases returns the cases to score, leaving out those the dataset
+// manifest flags for audio issues: from the manifest when scoring the
+// current reports, which spares reading every one of them.
+func listCases(ctx context.Context, svc *workspace.Service, run string) ([]*workspace.Case, error) {
+	m, err := svc.Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	flagged := make(map[string]bool)
+	if m != nil {
+		for _, e := range m.Cases {
+			if len(e.AudioIssues) > 0 {
+				flagged[e.ID] = true
 			}
-
-			for provider, result := range report.Results {
-				// Calculate QScore if not present (CompositeScore calculates it)
-				qScore := result.Metrics.CompositeScore()
-
-				if _, ok := stats[provider]; !ok {
-					stats[provider] = &providerStats{}
-				}
-				s := stats[provider]
-				// Accumulate Q (0-100)
-				s.WeightedSum += float64(qScore) * float64(tokenCount)
-				// Accumulate S and P (convert 0.0-1.0 to 0-100 for consistency)
-				s.WeightedS += result.Metrics.SScore * 100 * float64(tokenCount)
-				s.WeightedP += result.Metrics.PScore * 100 * float64(tokenCount)
-
-				s.TotalTokens += tokenCount
-				s.Count++
+		}
+	}
+	if run == "" && m != nil {
+		cases := make([]*workspace.Case, 0, len(m.Cases))
+		for _, e := range m.Cases {
+			if !flagged[e.ID] {
+				cases = append(cases, e.Case())
 			}
 		}
-		return nil
+		return cases, nil
+	}
+	resp, err := svc.ListCases(ctx, workspace.ListCasesRequest{
+		CaseFilter: workspace.CaseFilter{Run: run},
 	})
-
 	if err != nil {
-		log.Fatalf("Error walking directory: %v", err)
+		return nil, err
 	}
+	cases := resp.Cases[:0]
+	for _, c := range resp.Cases {
+		if !flagged[c.ID] {
+			cases = append(cases, c)
+		}
+	}
+	return cases, nil
+}
 
-	// Output results
-	fmt.Printf("Weighted Q Scores (Dataset: %s)\n", datasetDir)
-	fmt.Println("--------------------------------------------------")
+// compareRuns prints the weighted Q of each provider in runs a and b over
+// the cases both evaluated.
+func compareRuns(cfg workspace.ServiceConfig, a, b string) {
+	svc := workspace.NewService(cfg, nil)
+	defer svc.Close()
 
-	// Sort providers for consistent output
-	var providers []string
-	for p := range stats {
-		providers = append(providers, p)
+	resp, err := svc.CompareRuns(context.Background(), workspace.CompareRunsRequest{A: a, B: b})
+	if err != nil {
+		log.Fatalf("Error comparing runs: %v", err)
 	}
-	sort.Slice(providers, func(i, j int) bool {
-		// Sort by weighted average descending? or name?
-		// Let's sort by score descending for better utility
-		scoreI := stats[providers[i]].WeightedSum / float64(stats[providers[i]].TotalTokens)
-		scoreJ := stats[providers[j]].WeightedSum / float64(stats[providers[j]].TotalTokens)
-		return scoreI > scoreJ
-	})
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "Provider\tWeighted Q\tWeighted S\tWeighted P\tTotal Tokens\tCases")
+	fmt.Printf("Weighted Q Scores (Dataset: %s, %s vs %s over %d cases)\n", cfg.DatasetDir, a, b, resp.Cases)
+	fmt.Println("--------------------------------------------------")
 
-	for _, p := range providers {
-		s := stats[p]
-		weightedQ := 0.0
-		weightedS := 0.0
-		weightedP := 0.0
-		if s.TotalTokens > 0 {
-			weightedQ = s.WeightedSum / float64(s.TotalTokens)
-			weightedS = s.WeightedS / float64(s.TotalTokens)
-			weightedP = s.WeightedP / float64(s.TotalTokens)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Provider\t%s\t%s\tDelta\n", a, b)
+	q := func(s *workspace.ProviderStats) string {
+		if s == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", s.WeightedQ)
+	}
+	for _, p := range resp.Providers {
+		delta := "-"
+		if p.A != nil && p.B != nil {
+			delta = fmt.Sprintf("%+.2f", p.DeltaQ)
 		}
-		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%d\t%d\n", p, weightedQ, weightedS, weightedP, s.TotalTokens, s.Count)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Provider, q(p.A), q(p.B), delta)
 	}
 	w.Flush()
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/fs"
@@ -17,22 +18,50 @@ import (
 
 	"github.com/joho/godotenv"
 
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/batch"
 	"asr-eval/pkg/volc/client"
 	"asr-eval/pkg/volc/request"
 	"asr-eval/pkg/volc/response"
+	"asr-eval/pkg/workspace"
 )
 
 func main() {
+	shared, err := workspace.LoadSharedConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
 	// Define flags
 	ctxFlag := flag.String("context", "", "Path to context JSON file or raw JSON string")
 	extFlag := flag.String("ext", ".volc2", "Output file extension")
-	concurrencyFlag := flag.Int("concurrency", 10, "Number of concurrent workers (max 50)")
+	concurrencyFlag := flag.Int("concurrency", shared.ConcurrencyOr(10), "Number of concurrent workers (max 50)")
 	modelFlag := flag.String("model", "v2", "Model version: v1 (bigasr) or v2 (seedasr)")
 	limitFlag := flag.Int("limit", 0, "Limit number of files to process (0 = no limit)")
 	batchFlag := flag.String("batch", "", "Directory to scan for unprocessed files (batch mode)")
 	realtimeFlag := flag.Bool("realtime", false, "Use realtime streaming API instead of nostream")
+	manifestFlag := flag.String("manifest", "", "Run manifest to write (default processor-<time>.manifest.jsonl)")
+	resumeFlag := flag.String("resume", "", "Continue the run recorded in this manifest, with its flags")
+	retryFlag := flag.String("retry-failed", "", "Retry the failed files of this manifest, with backoff by error class")
+	dryRunFlag := flag.Bool("dry-run", false, "Print the files and audio duration the run would send, without calling the API or writing files")
+	priceFlag := flag.Float64("price", 0, "USD per audio hour, to estimate the cost of a dry run")
+	tuiFlag := flag.Bool("tui", false, "Show a live dashboard of the run instead of the log")
 	flag.Parse()
 
+	var manifest *batch.Manifest
+	if *retryFlag != "" {
+		*resumeFlag = *retryFlag
+	}
+	if *resumeFlag != "" {
+		var err error
+		manifest, err = batch.Resume(flag.CommandLine, *resumeFlag, "processor", os.Args[1:])
+		if err != nil {
+			log.Fatalf("Failed to resume: %v", err)
+		}
+		defer manifest.Close()
+	}
+
 	// Set model version
 	request.SetModelVersion(*modelFlag)
 	log.Printf("Using model version: %s", *modelFlag)
@@ -57,9 +86,10 @@ func main() {
 	}
 
 	_ = godotenv.Load() // Load .env file if it exists
+	shared.ExportAPIKeys()
 
 	// Validate Env vars are set
-	if os.Getenv("VOLC_APPID") == "" || os.Getenv("VOLC_TOKEN") == "" {
+	if !*dryRunFlag && (os.Getenv("VOLC_APPID") == "" || os.Getenv("VOLC_TOKEN") == "") {
 		log.Fatal("Please set VOLC_APPID and VOLC_TOKEN environment variables in .env file or export them.")
 	}
 
@@ -78,13 +108,35 @@ func main() {
 		log.Printf("Context payload: %s", ctxString)
 	}
 
+	if *retryFlag != "" {
+		log.Printf("Retrying %d files", len(batch.Retryable(manifest, batch.DefaultPolicies)))
+		err := batch.Retry(context.Background(), manifest, batch.DefaultPolicies, *concurrencyFlag, func(ctx context.Context, file string) error {
+			c := client.NewAsrWsClient(url, 200)
+			if ctxString != "" {
+				c.SetContext(ctxString)
+			}
+			return processFile(ctx, c, file, *extFlag, *realtimeFlag)
+		})
+		if err != nil {
+			log.Fatalf("Retry failed: %v", err)
+		}
+		log.Printf("Finished retrying, %d files still failed", len(manifest.Items(batch.StatusFailed)))
+		return
+	}
+
 	var files []string
 	args := flag.Args()
 
-	if *batchFlag != "" {
+	if manifest != nil {
+		files = manifest.Items(batch.StatusPending)
+		if len(files) == 0 {
+			log.Println("No pending files in manifest")
+			return
+		}
+	} else if *batchFlag != "" {
 		// Batch mode: scan directory for unprocessed files
 		var err error
-		files, err = getUnprocessedFlacFiles(*batchFlag, *extFlag, *limitFlag)
+		files, err = getUnprocessedAudioFiles(*batchFlag, *extFlag, *limitFlag)
 		if err != nil {
 			log.Fatalf("Failed to scan directory: %v", err)
 		}
@@ -100,6 +152,25 @@ func main() {
 		log.Fatal("Please specify files as arguments or use -batch <directory>")
 	}
 
+	if *dryRunFlag {
+		printDryRun(files, "volc "+*modelFlag, *priceFlag)
+		return
+	}
+
+	if manifest == nil {
+		path := *manifestFlag
+		if path == "" {
+			path = batch.DefaultPath("processor")
+		}
+		var err error
+		manifest, err = batch.Create(path, "processor", os.Args[1:], files)
+		if err != nil {
+			log.Fatalf("Failed to create manifest: %v", err)
+		}
+		defer manifest.Close()
+		log.Printf("Recording progress in %s", path)
+	}
+
 	// Limit concurrency to max 50
 	concurrency := *concurrencyFlag
 	if concurrency > 50 {
@@ -111,6 +182,14 @@ func main() {
 
 	log.Printf("Processing %d files with %d concurrent workers", len(files), concurrency)
 
+	var dash *batch.Dashboard
+	if *tuiFlag {
+		dash = batch.NewDashboard("processor", len(files))
+		stop := dash.Show(os.Stderr, 500*time.Millisecond)
+		defer stop()
+	}
+	group := "volc " + *modelFlag
+
 	// Worker pool
 	fileChan := make(chan string, len(files))
 	var wg sync.WaitGroup
@@ -127,7 +206,19 @@ func main() {
 			}
 
 			for file := range fileChan {
-				processFile(c, file, *extFlag, *realtimeFlag)
+				if dash != nil {
+					dash.Start(group, file)
+				}
+				err := processFile(context.Background(), c, file, *extFlag, *realtimeFlag)
+				if dash != nil {
+					dash.Finish(group, file, err)
+				}
+				if err != nil {
+					fmt.Printf("Failed to process %s: %v\n", file, err)
+				}
+				if err := manifest.Finish(file, err); err != nil {
+					log.Printf("Failed to record %s in manifest: %v", file, err)
+				}
 			}
 		}(i)
 	}
@@ -139,18 +230,38 @@ func main() {
 	close(fileChan)
 
 	wg.Wait()
-	log.Printf("Finished processing %d files", len(files))
+	log.Printf("Finished processing %d files, %d failed", len(files), len(manifest.Items(batch.StatusFailed)))
+}
+
+// printDryRun lists files with their duration and the total audio a run
+// would send to model.
+func printDryRun(files []string, model string, pricePerHour float64) {
+	var totalMS int64
+	for _, f := range files {
+		info, err := audio.ReadInfo(f)
+		if err != nil {
+			fmt.Printf("%s\t(unknown duration: %v)\n", f, err)
+			continue
+		}
+		totalMS += info.DurationMS
+		fmt.Printf("%s\t%s\n", f, time.Duration(info.DurationMS)*time.Millisecond)
+	}
+	hours := float64(totalMS) / float64(time.Hour/time.Millisecond)
+	fmt.Printf("Dry run: %d files, %s of audio to %s.\n", len(files), time.Duration(totalMS)*time.Millisecond, model)
+	if pricePerHour > 0 {
+		fmt.Printf("Estimated cost: $%.2f\n", hours*pricePerHour)
+	}
 }
 
-func getUnprocessedFlacFiles(root string, ext string, limit int) ([]string, error) {
+func getUnprocessedAudioFiles(root string, ext string, limit int) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if !d.IsDir() && filepath.Ext(path) == ".flac" {
+		if !d.IsDir() && audio.FormatOfExt(path) != "" {
 			// Check if output file with specified extension exists
-			outPath := strings.TrimSuffix(path, ".flac") + ext
+			outPath := strings.TrimSuffix(path, filepath.Ext(path)) + ext
 			if _, err := os.Stat(outPath); os.IsNotExist(err) {
 				files = append(files, path)
 			}
@@ -178,7 +289,7 @@ type StreamEntry struct {
 	Text      string `json:"s"`
 }
 
-func processFile(c *client.AsrWsClient, filePath string, ext string, realtime bool) {
+func processFile(ctx context.Context, c *client.AsrWsClient, filePath string, ext string, realtime bool) error {
 	fmt.Printf("Processing %s...\n", filePath)
 
 	resChan := make(chan *response.AsrResponse)
@@ -186,6 +297,7 @@ func processFile(c *client.AsrWsClient, filePath string, ext string, realtime bo
 	wg.Add(1)
 
 	var finalTranscript string
+	var respErr error
 	var mu sync.Mutex
 
 	startTime := time.Now()
@@ -208,6 +320,9 @@ func processFile(c *client.AsrWsClient, filePath string, ext string, realtime bo
 		for res := range resChan {
 			if res.Code != 0 {
 				fmt.Printf("Error response: Code=%d, Error=%s\n", res.Code, res.PayloadMsg.Error)
+				mu.Lock()
+				respErr = fmt.Errorf("error response %d: %s", res.Code, res.PayloadMsg.Error)
+				mu.Unlock()
 				return
 			}
 			if res.PayloadMsg != nil && res.PayloadMsg.Result.Text != "" {
@@ -270,25 +385,24 @@ func processFile(c *client.AsrWsClient, filePath string, ext string, realtime bo
 		}
 	}()
 
-	err := c.Excute(context.Background(), filePath, resChan)
+	err := c.Excute(ctx, filePath, resChan)
+	wg.Wait()
 	if err != nil {
-		fmt.Printf("Failed to process %s: %v\n", filePath, err)
-		return
+		return err
+	}
+	if respErr != nil {
+		return respErr
 	}
-
-	wg.Wait()
 
 	if finalTranscript != "" {
 		volcPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ext
-		err := ioutil.WriteFile(volcPath, []byte(finalTranscript), 0644)
-		if err != nil {
-			fmt.Printf("Failed to write result to %s: %v\n", volcPath, err)
-		} else {
-			fmt.Printf("Saved result to %s\n", volcPath)
+		if err := atomicfile.WriteFile(volcPath, []byte(finalTranscript)); err != nil {
+			return fmt.Errorf("write result: %w", err)
 		}
-	} else {
-		// If empty, maybe it was silence or failed silently?
-		// Don't overwrite if empty unless sure.
-		fmt.Printf("No transcript received for %s\n", filePath)
+		fmt.Printf("Saved result to %s\n", volcPath)
+		return nil
 	}
+	// If empty, maybe it was silence or failed silently?
+	// Don't overwrite if empty unless sure.
+	return errors.New("no transcript received")
 }
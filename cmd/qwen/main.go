@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io/fs"
@@ -12,26 +13,56 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
 
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/batch"
 	"asr-eval/pkg/qwen"
+	"asr-eval/pkg/workspace"
 )
 
 func main() {
+	shared, err := workspace.LoadSharedConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
 	// Define flags
 	ctxFlag := flag.String("context", "", "Path to context JSON file or raw JSON string (Context/Corpus)")
 	extFlag := flag.String("ext", ".qwen", "Output file extension")
-	concurrencyFlag := flag.Int("concurrency", 10, "Number of concurrent workers (max 50)")
+	concurrencyFlag := flag.Int("concurrency", shared.ConcurrencyOr(10), "Number of concurrent workers (max 50)")
 	modelFlag := flag.String("model", "qwen3-asr-flash-realtime", "Model name (e.g. qwen-realtime-v1)")
 	limitFlag := flag.Int("limit", 0, "Limit number of files to process (0 = no limit)")
 	batchFlag := flag.String("batch", "", "Directory to scan for unprocessed files (batch mode)")
+	manifestFlag := flag.String("manifest", "", "Run manifest to write (default qwen-<time>.manifest.jsonl)")
+	resumeFlag := flag.String("resume", "", "Continue the run recorded in this manifest, with its flags")
+	retryFlag := flag.String("retry-failed", "", "Retry the failed files of this manifest, with backoff by error class")
+	dryRunFlag := flag.Bool("dry-run", false, "Print the files and audio duration the run would send, without calling the API or writing files")
+	priceFlag := flag.Float64("price", 0, "USD per audio hour, to estimate the cost of a dry run")
+	tuiFlag := flag.Bool("tui", false, "Show a live dashboard of the run instead of the log")
 	flag.Parse()
 
+	var manifest *batch.Manifest
+	if *retryFlag != "" {
+		*resumeFlag = *retryFlag
+	}
+	if *resumeFlag != "" {
+		var err error
+		manifest, err = batch.Resume(flag.CommandLine, *resumeFlag, "qwen", os.Args[1:])
+		if err != nil {
+			log.Fatalf("Failed to resume: %v", err)
+		}
+		defer manifest.Close()
+	}
+
 	_ = godotenv.Load() // Load .env file if it exists
+	shared.ExportAPIKeys()
 
 	apiKey := os.Getenv("QWEN_API_KEY")
-	if apiKey == "" {
+	if apiKey == "" && !*dryRunFlag {
 		log.Fatal("Please set QWEN_API_KEY environment variables.")
 	}
 
@@ -52,13 +83,32 @@ func main() {
 		log.Printf("Context payload: %s", ctxString)
 	}
 
+	if *retryFlag != "" {
+		c := qwen.NewClient(*modelFlag, apiKey)
+		log.Printf("Retrying %d files", len(batch.Retryable(manifest, batch.DefaultPolicies)))
+		err := batch.Retry(context.Background(), manifest, batch.DefaultPolicies, *concurrencyFlag, func(ctx context.Context, file string) error {
+			return processFile(c, file, ctxString, *extFlag)
+		})
+		if err != nil {
+			log.Fatalf("Retry failed: %v", err)
+		}
+		log.Printf("Finished retrying, %d files still failed", len(manifest.Items(batch.StatusFailed)))
+		return
+	}
+
 	var files []string
 	args := flag.Args()
 
-	if *batchFlag != "" {
+	if manifest != nil {
+		files = manifest.Items(batch.StatusPending)
+		if len(files) == 0 {
+			log.Println("No pending files in manifest")
+			return
+		}
+	} else if *batchFlag != "" {
 		// Batch mode: scan directory for unprocessed files
 		var err error
-		files, err = getUnprocessedFlacFiles(*batchFlag, *extFlag, *limitFlag)
+		files, err = getUnprocessedAudioFiles(*batchFlag, *extFlag, *limitFlag)
 		if err != nil {
 			log.Fatalf("Failed to scan directory: %v", err)
 		}
@@ -74,6 +124,25 @@ func main() {
 		log.Fatal("Please specify files as arguments or use -batch <directory>")
 	}
 
+	if *dryRunFlag {
+		printDryRun(files, *modelFlag, *priceFlag)
+		return
+	}
+
+	if manifest == nil {
+		path := *manifestFlag
+		if path == "" {
+			path = batch.DefaultPath("qwen")
+		}
+		var err error
+		manifest, err = batch.Create(path, "qwen", os.Args[1:], files)
+		if err != nil {
+			log.Fatalf("Failed to create manifest: %v", err)
+		}
+		defer manifest.Close()
+		log.Printf("Recording progress in %s", path)
+	}
+
 	// Limit concurrency
 	concurrency := *concurrencyFlag
 	if concurrency > 50 {
@@ -85,6 +154,14 @@ func main() {
 
 	log.Printf("Processing %d files with %d concurrent workers", len(files), concurrency)
 
+	var dash *batch.Dashboard
+	if *tuiFlag {
+		dash = batch.NewDashboard("qwen", len(files))
+		stop := dash.Show(os.Stderr, 500*time.Millisecond)
+		defer stop()
+	}
+	group := *modelFlag
+
 	// Worker pool
 	fileChan := make(chan string, len(files))
 	var wg sync.WaitGroup
@@ -103,7 +180,19 @@ func main() {
 			c := qwen.NewClient(*modelFlag, apiKey)
 
 			for file := range fileChan {
-				processFile(c, file, ctxString, *extFlag)
+				if dash != nil {
+					dash.Start(group, file)
+				}
+				err := processFile(c, file, ctxString, *extFlag)
+				if dash != nil {
+					dash.Finish(group, file, err)
+				}
+				if err != nil {
+					fmt.Printf("Failed to process %s: %v\n", file, err)
+				}
+				if err := manifest.Finish(file, err); err != nil {
+					log.Printf("Failed to record %s in manifest: %v", file, err)
+				}
 			}
 		}(i)
 	}
@@ -114,17 +203,37 @@ func main() {
 	close(fileChan)
 
 	wg.Wait()
-	log.Printf("Finished processing %d files", len(files))
+	log.Printf("Finished processing %d files, %d failed", len(files), len(manifest.Items(batch.StatusFailed)))
+}
+
+// printDryRun lists files with their duration and the total audio a run
+// would send to model.
+func printDryRun(files []string, model string, pricePerHour float64) {
+	var totalMS int64
+	for _, f := range files {
+		info, err := audio.ReadInfo(f)
+		if err != nil {
+			fmt.Printf("%s\t(unknown duration: %v)\n", f, err)
+			continue
+		}
+		totalMS += info.DurationMS
+		fmt.Printf("%s\t%s\n", f, time.Duration(info.DurationMS)*time.Millisecond)
+	}
+	hours := float64(totalMS) / float64(time.Hour/time.Millisecond)
+	fmt.Printf("Dry run: %d files, %s of audio to %s.\n", len(files), time.Duration(totalMS)*time.Millisecond, model)
+	if pricePerHour > 0 {
+		fmt.Printf("Estimated cost: $%.2f\n", hours*pricePerHour)
+	}
 }
 
-func getUnprocessedFlacFiles(root string, ext string, limit int) ([]string, error) {
+func getUnprocessedAudioFiles(root string, ext string, limit int) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if !d.IsDir() && filepath.Ext(path) == ".flac" {
-			outPath := strings.TrimSuffix(path, ".flac") + ext
+		if !d.IsDir() && audio.FormatOfExt(path) != "" {
+			outPath := strings.TrimSuffix(path, filepath.Ext(path)) + ext
 			if _, err := os.Stat(outPath); os.IsNotExist(err) {
 				files = append(files, path)
 			}
@@ -142,7 +251,7 @@ func getUnprocessedFlacFiles(root string, ext string, limit int) ([]string, erro
 	return files, nil
 }
 
-func processFile(c *qwen.Client, filePath string, corpusText string, ext string) {
+func processFile(c *qwen.Client, filePath string, corpusText string, ext string) error {
 	fmt.Printf("Processing %s...\n", filePath)
 
 	resChan := make(chan qwen.Result)
@@ -150,6 +259,7 @@ func processFile(c *qwen.Client, filePath string, corpusText string, ext string)
 	wg.Add(1)
 
 	var fullTranscript strings.Builder
+	var resErr error
 	var mu sync.Mutex
 
 	go func() {
@@ -157,6 +267,9 @@ func processFile(c *qwen.Client, filePath string, corpusText string, ext string)
 		for res := range resChan {
 			if res.Error != nil {
 				fmt.Printf("Error processing %s: %v\n", filePath, res.Error)
+				mu.Lock()
+				resErr = res.Error
+				mu.Unlock()
 				return
 			}
 
@@ -176,22 +289,25 @@ func processFile(c *qwen.Client, filePath string, corpusText string, ext string)
 	}()
 
 	err := c.ProcessFile(context.Background(), filePath, corpusText, resChan)
-	if err != nil {
-		fmt.Printf("Failed to process %s: %v\n", filePath, err)
-	}
 
 	wg.Wait()
+	if err == nil {
+		err = resErr
+	}
 
 	finalStr := fullTranscript.String()
-	if finalStr != "" {
-		outPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ext
-		err := ioutil.WriteFile(outPath, []byte(finalStr), 0644)
+	if finalStr == "" {
 		if err != nil {
-			fmt.Printf("Failed to write result to %s: %v\n", outPath, err)
-		} else {
-			fmt.Printf("Saved result to %s\n", outPath)
+			return err
 		}
-	} else {
-		// fmt.Printf("No transcript received for %s\n", filePath)
+		return errors.New("no transcript received")
+	}
+	// A partial transcript is still saved, as before, but the file is
+	// recorded as failed so a retry can complete it.
+	outPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ext
+	if werr := atomicfile.WriteFile(outPath, []byte(finalStr)); werr != nil {
+		return fmt.Errorf("write result: %w", werr)
 	}
+	fmt.Printf("Saved result to %s\n", outPath)
+	return err
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+)
+
+// withCORS allows cross-origin requests from origins, answering preflight
+// requests itself. Listed origins may send credentials. An origin of "*"
+// allows any other origin without credentials, so a page elsewhere cannot
+// ride on the browser's API key cookie.
+func withCORS(next http.Handler, origins []string) http.Handler {
+	anyOrigin := slices.Contains(origins, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		listed := origin != "" && origin != "*" && slices.Contains(origins, origin)
+		if origin == "" || !(listed || anyOrigin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		if listed {
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+		} else {
+			h.Set("Access-Control-Allow-Origin", "*")
+		}
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Accept")
+			h.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.Set("Access-Control-Expose-Headers", "Location")
+		next.ServeHTTP(w, r)
+	})
+}
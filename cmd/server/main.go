@@ -1,39 +1,133 @@
 package main
 
 import (
+	"asr-eval/pkg/llmclient"
 	"asr-eval/pkg/workspace"
+	"asr-eval/ui"
 	"context"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"google.golang.org/genai"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
+// shutdownGrace is how long in-flight requests may run after SIGTERM.
+const shutdownGrace = 30 * time.Second
+
 func main() {
+	cfg, shared, err := workspace.LoadServiceConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
 	var (
-		cfg  = workspace.DefaultServiceConfig()
-		port = 8080
+		port     = 8080
+		grpcPort = 0
+		roots    []string
+		dbPath   string
+
+		host        = "127.0.0.1"
+		tlsCert     string
+		tlsKey      string
+		corsOrigins []string
+		apiKeysFile string
+		staticDir   string
+
+		maxEvals  = shared.ConcurrencyOr(8)
+		rateLimit = 20
+		rateBurst = 10
 	)
 
 	flag.StringVar(&cfg.DatasetDir, "dataset-dir", cfg.DatasetDir, "Directory containing transcripts and audio files")
+	flag.Func("dataset", "Dataset to mount as name=dir; repeatable, the first is the default (overrides -dataset-dir)", func(v string) error {
+		roots = append(roots, v)
+		return nil
+	})
+	flag.StringVar(&dbPath, "sqlite", "", "SQLite database to keep the dataset's case records in, as made by asr-eval migrate sqlite; needs -tags sqlite and one dataset")
 	flag.StringVar(&cfg.GenModel, "gen-model", cfg.GenModel, "LLM model to use for context generation")
 	flag.StringVar(&cfg.EvalModel, "eval-model", cfg.EvalModel, "LLM model to use for evaluation")
+	flag.Func("fallback-models", "Comma-separated LLM models to fall back to on quota/availability errors", func(v string) error {
+		cfg.FallbackModels = strings.Split(v, ",")
+		return nil
+	})
+	flag.IntVar(&cfg.MaxPromptTokens, "max-prompt-tokens", cfg.MaxPromptTokens, "Token budget for evaluation prompts before truncation (0 = no limit)")
+	flag.Func("judge-models", "Comma-separated judge models for multi-judge consensus evaluation", func(v string) error {
+		cfg.JudgeModels = strings.Split(v, ",")
+		return nil
+	})
+	flag.IntVar(&maxEvals, "max-concurrent-evals", maxEvals, "Evaluations and context generations to run at once across all datasets (0 = no limit)")
+	flag.IntVar(&rateLimit, "rate-limit", rateLimit, "Evaluate, generate, transcribe and batch calls allowed per client per minute (0 = no limit)")
+	flag.IntVar(&rateBurst, "rate-burst", rateBurst, "Calls a client may make at once before -rate-limit applies")
+	flag.StringVar(&host, "host", host, "Host to bind to; use 0.0.0.0 to listen on all interfaces")
 	flag.IntVar(&port, "port", 8080, "Port to listen on")
+	flag.IntVar(&grpcPort, "grpc-port", 0, "Port to serve the gRPC API on (0 disables it)")
+	flag.StringVar(&tlsCert, "tls-cert", "", "TLS certificate file; serves HTTPS together with -tls-key")
+	flag.StringVar(&tlsKey, "tls-key", "", "TLS private key file")
+	flag.Func("cors-origins", "Comma-separated origins allowed to call the API cross-origin (* for any, without credentials)", func(v string) error {
+		corsOrigins = strings.Split(v, ",")
+		return nil
+	})
+	flag.StringVar(&staticDir, "static-dir", "", "Serve the UI from this directory instead of the embedded build")
+	flag.StringVar(&apiKeysFile, "api-keys-file", "", "File of user:key lines; when set, API and audio requests require a key")
+	flag.StringVar(&cfg.WebhookURL, "webhook-url", cfg.WebhookURL, "Chat webhook to post finished evaluations and batch runs to")
+	flag.StringVar(&cfg.WebhookFormat, "webhook-format", cfg.WebhookFormat, "Webhook payload format: slack or feishu")
 	flag.Parse()
 
+	if (tlsCert == "") != (tlsKey == "") {
+		log.Fatalf("-tls-cert and -tls-key must be set together")
+	}
+	if cfg.WebhookFormat != workspace.WebhookSlack && cfg.WebhookFormat != workspace.WebhookFeishu {
+		log.Fatalf("-webhook-format must be %s or %s", workspace.WebhookSlack, workspace.WebhookFeishu)
+	}
+	cfg.Overrides = workspace.FlagOverrides(flag.CommandLine, &cfg)
+
 	_ = godotenv.Load()
+	shared.ExportAPIKeys()
 
 	apiKey := os.Getenv("GEMINI_API_KEY")
 	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{APIKey: apiKey})
 	if err != nil {
 		log.Fatalf("Failed to init LLM client: %v", err)
 	}
+	llm := llmclient.NewDefaultRouter(llmclient.NewGenAI(client))
 
-	svc := workspace.NewService(cfg, client)
+	if len(roots) == 0 {
+		roots = []string{cfg.DatasetDir}
+	}
+	if dbPath != "" && len(roots) > 1 {
+		log.Fatalf("-sqlite serves one dataset, got %d", len(roots))
+	}
+	if maxEvals > 0 {
+		cfg.EvalSlots = workspace.NewEvalSlots(maxEvals)
+	}
+	datasets := workspace.NewDatasets()
+	defer datasets.Close()
+	for _, root := range roots {
+		name, dir := workspace.ParseDatasetRoot(root)
+		dsCfg := cfg
+		dsCfg.DatasetDir = dir
+		if dbPath != "" {
+			st, err := workspace.OpenSQLiteStorage(dbPath)
+			if err != nil {
+				log.Fatalf("Failed to open %s: %v", dbPath, err)
+			}
+			dsCfg.Storage = st
+		}
+		if err := datasets.Mount(name, workspace.NewService(dsCfg, llm)); err != nil {
+			log.Fatalf("Failed to mount dataset %s: %v", root, err)
+		}
+	}
 
 	// Use Go 1.22+ ServeMux patterns if available, or just standard.
 	// RegisterRoutes uses pattern matching like "GET /api/cases/{id}" which requires Go 1.22.
@@ -41,33 +135,117 @@ func main() {
 	// Assuming Go 1.22 based on recent work.
 	mux := http.NewServeMux()
 
-	// Register API Routes
-	svc.RegisterRoutes(mux)
-
-	// Serve static files
-	fs := http.FileServer(http.Dir("./static"))
-	// Handle root specially to fallback to index.html for SPA routing
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		// If it's an API call not matched above, it returns 404 naturally?
-		// No, RegisterRoutes handles specific paths.
-		// We need to serve static files.
-
-		path := "./static" + r.URL.Path
-		if _, err := os.Stat(path); err == nil && r.URL.Path != "/" {
-			fs.ServeHTTP(w, r)
-			return
+	// Register API and audio routes
+	datasets.RegisterRoutes(mux)
+
+	// Everything else is the UI.
+	static := ui.FS()
+	if staticDir != "" {
+		static = os.DirFS(staticDir)
+	}
+	mux.Handle("/", workspace.StaticHandler(static))
+
+	var (
+		handler      http.Handler = mux
+		interceptors []grpc.UnaryServerInterceptor
+		grpcOpts     []grpc.ServerOption
+	)
+	if rateLimit > 0 {
+		limiter := workspace.NewRateLimiter(rateLimit, rateBurst)
+		handler = limiter.Wrap(handler)
+		interceptors = append(interceptors, limiter.UnaryInterceptor())
+	}
+	if apiKeysFile != "" {
+		auth, err := workspace.LoadAuthenticator(apiKeysFile)
+		if err != nil {
+			log.Fatalf("Failed to load API keys: %v", err)
 		}
-		// Fallback to index.html
-		http.ServeFile(w, r, "./static/index.html")
-	})
+		handler = auth.Wrap(handler)
+		interceptors = append([]grpc.UnaryServerInterceptor{auth.UnaryInterceptor()}, interceptors...)
+	}
+	grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(interceptors...))
+	if tlsCert != "" {
+		creds, err := credentials.NewServerTLSFromFile(tlsCert, tlsKey)
+		if err != nil {
+			log.Fatalf("Failed to load TLS credentials: %v", err)
+		}
+		grpcOpts = append(grpcOpts, grpc.Creds(creds))
+	}
+	if len(corsOrigins) > 0 {
+		// Outside auth so that preflight requests, which carry no
+		// credentials, get through.
+		handler = withCORS(handler, corsOrigins)
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	fmt.Printf("Attempting to listen on %s...\n", addr)
+	for _, root := range roots {
+		name, dir := workspace.ParseDatasetRoot(root)
+		fmt.Printf("Using dataset %s: %s\n", name, dir)
+	}
+
+	// Request contexts derive from base, which is canceled once the grace
+	// period runs out so in-flight LLM calls stop.
+	base, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return base },
+	}
+	srv.RegisterOnShutdown(datasets.CloseEvents)
+	errc := make(chan error, 2)
+	go func() {
+		if tlsCert != "" {
+			errc <- srv.ListenAndServeTLS(tlsCert, tlsKey)
+		} else {
+			errc <- srv.ListenAndServe()
+		}
+	}()
+
+	var grpcSrv *grpc.Server
+	if grpcPort != 0 {
+		grpcAddr := net.JoinHostPort(host, strconv.Itoa(grpcPort))
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", grpcAddr, err)
+		}
+		grpcSrv = grpc.NewServer(grpcOpts...)
+		datasets.RegisterGRPC(grpcSrv)
+		fmt.Printf("Serving gRPC on %s\n", grpcAddr)
+		go func() { errc <- grpcSrv.Serve(lis) }()
+	}
 
-	// Serve audio files
-	audioFs := http.FileServer(http.Dir(cfg.DatasetDir))
-	mux.Handle("/audio/", http.StripPrefix("/audio/", audioFs))
+	sig, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-errc:
+		log.Fatalf("Failed to serve: %v\n", err)
+	case <-sig.Done():
+	}
+	stop() // A second signal kills the process.
 
-	fmt.Printf("Attempting to listen on 127.0.0.1:%d...\n", port)
-	fmt.Printf("Using dataset directory: %s\n", cfg.DatasetDir)
-	if err := http.ListenAndServe(fmt.Sprintf("127.0.0.1:%d", port), mux); err != nil {
-		log.Fatalf("Failed to bind to 127.0.0.1:%d: %v\n", port, err)
+	log.Printf("Shutting down, waiting up to %v for requests to finish...", shutdownGrace)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+	grpcDone := make(chan struct{})
+	if grpcSrv != nil {
+		go func() {
+			grpcSrv.GracefulStop()
+			close(grpcDone)
+		}()
+	} else {
+		close(grpcDone)
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Grace period expired, canceling in-flight requests: %v", err)
+		cancelBase()
+		srv.Close()
+	}
+	select {
+	case <-grpcDone:
+	case <-ctx.Done():
+		grpcSrv.Stop()
 	}
+	// Deferred datasets.Close cancels running jobs and waits for them.
 }
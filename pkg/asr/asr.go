@@ -0,0 +1,92 @@
+// Package asr is a registry of ASR providers that transcribe an audio file
+// into the dataset's transcript and stream formats.
+package asr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Transcriber transcribes audio files with one provider configuration.
+type Transcriber interface {
+	Transcribe(ctx context.Context, path string, opts Options) (*Result, error)
+}
+
+// StreamSampleRate is the sample rate of the audio a StreamTranscriber
+// takes.
+const StreamSampleRate = 16000
+
+// StreamTranscriber is a Transcriber of a realtime provider, which can also
+// transcribe audio as it is captured.
+type StreamTranscriber interface {
+	Transcriber
+	// TranscribeStream transcribes 16-bit little-endian mono PCM at
+	// StreamSampleRate read from r until EOF, sending it as it arrives.
+	TranscribeStream(ctx context.Context, r io.Reader, opts Options) (*Result, error)
+}
+
+// Options tune a single transcription.
+type Options struct {
+	Context  string            // Biasing context or corpus text; ignored by providers without context support
+	Bias     *Bias             // Vocabulary to favor, put in the provider's form when Context is empty; ignored likewise
+	OnStream func(StreamEntry) // If not nil, called with each stream entry as it arrives
+}
+
+// Result is a finished transcription.
+type Result struct {
+	Text   string
+	Stream []StreamEntry // Empty for non-streaming providers
+}
+
+// StreamEntry is one line of a "[id].<provider>.stream.json" dump.
+type StreamEntry struct {
+	Timestamp int64  `json:"t"` // Milliseconds since the stream started
+	Final     bool   `json:"f,omitempty"`
+	Text      string `json:"s"`
+}
+
+// Factory creates a Transcriber, typically reading credentials from the
+// environment.
+type Factory func() (Transcriber, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register makes a provider available under name, the provider ID used as
+// the transcript file extension. It panics on duplicate names.
+func Register(name string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := factories[name]; ok {
+		panic("asr: duplicate provider " + name)
+	}
+	factories[name] = f
+}
+
+// New creates the Transcriber registered under name.
+func New(name string) (Transcriber, error) {
+	mu.RLock()
+	f, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("asr: unknown provider %q", name)
+	}
+	return f()
+}
+
+// Names returns the registered provider names in sorted order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,61 @@
+package asr
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Bias is vocabulary a recording is expected to contain, which providers
+// that take hotwords or a corpus are told to favor. Each renders it in its
+// own form.
+type Bias struct {
+	Phrases []string // Most important first
+	Domain  string   // What the recording is about, in a sentence or two
+}
+
+// context returns the biasing payload for a provider: opts.Context as
+// given, or else opts.Bias rendered by render.
+func (opts Options) context(render func(*Bias) string) string {
+	if opts.Context != "" || opts.Bias == nil {
+		return opts.Context
+	}
+	return render(opts.Bias)
+}
+
+// volcContext renders b as the corpus context of a volc request: its
+// phrases as hotwords, and its domain as dialog context.
+func volcContext(b *Bias) string {
+	type word struct {
+		Word string `json:"word"`
+	}
+	type text struct {
+		Text string `json:"text"`
+	}
+	var c struct {
+		Hotwords    []word `json:"hotwords,omitempty"`
+		ContextType string `json:"context_type,omitempty"`
+		ContextData []text `json:"context_data,omitempty"`
+	}
+	for _, p := range b.Phrases {
+		c.Hotwords = append(c.Hotwords, word{p})
+	}
+	if b.Domain != "" {
+		c.ContextType = "dialog_ctx"
+		c.ContextData = []text{{b.Domain}}
+	}
+	if len(c.Hotwords) == 0 && c.ContextData == nil {
+		return ""
+	}
+	out, _ := json.Marshal(c)
+	return string(out)
+}
+
+// qwenCorpus renders b as the corpus text of a qwen session: the domain,
+// then a phrase a line.
+func qwenCorpus(b *Bias) string {
+	lines := b.Phrases
+	if b.Domain != "" {
+		lines = append([]string{b.Domain}, lines...)
+	}
+	return strings.Join(lines, "\n")
+}
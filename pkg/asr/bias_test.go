@@ -0,0 +1,25 @@
+package asr
+
+import "testing"
+
+func TestBiasContext(t *testing.T) {
+	b := &Bias{Phrases: []string{"order 4417", "Visa"}, Domain: "Refunds"}
+	tests := []struct {
+		name   string
+		opts   Options
+		render func(*Bias) string
+		want   string
+	}{
+		{"volc", Options{Bias: b}, volcContext, `{"hotwords":[{"word":"order 4417"},{"word":"Visa"}],"context_type":"dialog_ctx","context_data":[{"text":"Refunds"}]}`},
+		{"volc phrases", Options{Bias: &Bias{Phrases: b.Phrases}}, volcContext, `{"hotwords":[{"word":"order 4417"},{"word":"Visa"}]}`},
+		{"volc empty", Options{Bias: &Bias{}}, volcContext, ""},
+		{"qwen", Options{Bias: b}, qwenCorpus, "Refunds\norder 4417\nVisa"},
+		{"given", Options{Context: "given", Bias: b}, qwenCorpus, "given"},
+		{"none", Options{}, qwenCorpus, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.context(tt.render); got != tt.want {
+			t.Errorf("%s: context() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
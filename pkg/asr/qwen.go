@@ -0,0 +1,84 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/qwen"
+)
+
+const qwenModel = "qwen3-asr-flash-realtime"
+
+func init() {
+	Register("qwen_ctx_rt", func() (Transcriber, error) {
+		apiKey := os.Getenv("QWEN_API_KEY")
+		if apiKey == "" {
+			return nil, errors.New("QWEN_API_KEY must be set")
+		}
+		return &qwenTranscriber{client: qwen.NewClient(qwenModel, apiKey)}, nil
+	})
+}
+
+type qwenTranscriber struct {
+	client *qwen.Client
+}
+
+func (t *qwenTranscriber) Transcribe(ctx context.Context, path string, opts Options) (*Result, error) {
+	return t.session(opts, func(resChan chan<- qwen.Result) error {
+		return t.client.ProcessFile(ctx, path, opts.context(qwenCorpus), resChan)
+	})
+}
+
+func (t *qwenTranscriber) TranscribeStream(ctx context.Context, r io.Reader, opts Options) (*Result, error) {
+	return t.session(opts, func(resChan chan<- qwen.Result) error {
+		return t.client.ProcessStream(ctx, r, opts.context(qwenCorpus), resChan)
+	})
+}
+
+// session collects the results process sends until it closes resChan.
+func (t *qwenTranscriber) session(opts Options, process func(resChan chan<- qwen.Result) error) (*Result, error) {
+	resChan := make(chan qwen.Result)
+	done := make(chan struct{})
+	var (
+		res    Result
+		resErr error
+		final  []string
+	)
+	start := time.Now()
+	go func() {
+		defer close(done)
+		for r := range resChan {
+			if r.Error != nil {
+				resErr = r.Error
+				continue
+			}
+			e := StreamEntry{
+				Timestamp: time.Since(start).Milliseconds(),
+				Final:     r.IsFinal,
+				Text:      r.Text,
+			}
+			res.Stream = append(res.Stream, e)
+			if opts.OnStream != nil {
+				opts.OnStream(e)
+			}
+			if r.IsFinal {
+				final = append(final, r.Text)
+			}
+		}
+	}()
+
+	err := process(resChan)
+	<-done
+	if err != nil {
+		return nil, err
+	}
+	if resErr != nil {
+		return nil, resErr
+	}
+	res.Text = strings.Join(final, " ")
+	return &res, nil
+}
@@ -0,0 +1,159 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"asr-eval/pkg/volc/client"
+	"asr-eval/pkg/volc/config"
+	"asr-eval/pkg/volc/request"
+	"asr-eval/pkg/volc/response"
+)
+
+const (
+	volcNostreamURL = "wss://openspeech.bytedance.com/api/v3/sauc/bigmodel_nostream"
+	volcRealtimeURL = "wss://openspeech.bytedance.com/api/v3/sauc/bigmodel_async"
+)
+
+func init() {
+	Register("volc", volcFactory(request.ModelV1, false, false))
+	Register("volc_ctx", volcFactory(request.ModelV1, false, true))
+	Register("volc_ctx_rt", volcFactory(request.ModelV1, true, true))
+	Register("volc2_ctx", volcFactory(request.ModelV2, false, true))
+	Register("volc2_ctx_rt", volcFactory(request.ModelV2, true, true))
+}
+
+// volcMu serializes volc sessions: the request package keeps the model
+// version and streaming mode in package globals.
+var volcMu sync.Mutex
+
+type volcTranscriber struct {
+	model      string
+	realtime   bool
+	useContext bool
+}
+
+// volcRealtimeTranscriber is a volcTranscriber of the realtime endpoint,
+// which also takes captured audio.
+type volcRealtimeTranscriber struct {
+	*volcTranscriber
+}
+
+func volcFactory(model string, realtime, useContext bool) Factory {
+	return func() (Transcriber, error) {
+		if config.AppKey() == "" || config.AccessKey() == "" {
+			return nil, errors.New("VOLC_APPID and VOLC_TOKEN must be set")
+		}
+		t := &volcTranscriber{model: model, realtime: realtime, useContext: useContext}
+		if realtime {
+			return volcRealtimeTranscriber{t}, nil
+		}
+		return t, nil
+	}
+}
+
+func (t *volcTranscriber) Transcribe(ctx context.Context, path string, opts Options) (*Result, error) {
+	volcMu.Lock()
+	defer volcMu.Unlock()
+	return t.session(opts, func(c *client.AsrWsClient, resChan chan<- *response.AsrResponse) error {
+		return c.Excute(ctx, path, resChan)
+	})
+}
+
+// TranscribeStream holds volcMu only while the session is set up, so
+// captured audio can go to several volc providers at once.
+func (t volcRealtimeTranscriber) TranscribeStream(ctx context.Context, r io.Reader, opts Options) (*Result, error) {
+	volcMu.Lock()
+	unlock := sync.OnceFunc(volcMu.Unlock)
+	defer unlock()
+	return t.session(opts, func(c *client.AsrWsClient, resChan chan<- *response.AsrResponse) error {
+		c.SetOnSessionStart(unlock)
+		return c.ExcuteStream(ctx, r, resChan)
+	})
+}
+
+// session configures the request package and a client, which excute runs,
+// and collects the responses. volcMu must be held.
+func (t *volcTranscriber) session(opts Options, excute func(c *client.AsrWsClient, resChan chan<- *response.AsrResponse) error) (*Result, error) {
+	url := volcNostreamURL
+	request.SetModelVersion(t.model)
+	if t.realtime {
+		url = volcRealtimeURL
+		request.SetEnableNonstream(true)
+		request.SetResultType("single")
+	} else {
+		request.SetEnableNonstream(false)
+		request.SetResultType("full")
+	}
+	if u := os.Getenv("VOLC_URL"); u != "" {
+		url = u
+	}
+
+	c := client.NewAsrWsClient(url, 200)
+	if ctx := opts.context(volcContext); t.useContext && ctx != "" {
+		c.SetContext(ctx)
+	}
+
+	resChan := make(chan *response.AsrResponse)
+	done := make(chan struct{})
+	var (
+		res    Result
+		resErr error
+	)
+	start := time.Now()
+	go func() {
+		defer close(done)
+		for r := range resChan {
+			if r.Code != 0 {
+				if resErr == nil {
+					resErr = fmt.Errorf("volc error %d: %s", r.Code, r.PayloadMsg.Error)
+				}
+				continue
+			}
+			if r.PayloadMsg == nil || r.PayloadMsg.Result.Text == "" {
+				continue
+			}
+			if !t.realtime {
+				res.Text = r.PayloadMsg.Result.Text
+				continue
+			}
+			ts := time.Since(start).Milliseconds()
+			emit := func(e StreamEntry) {
+				res.Stream = append(res.Stream, e)
+				if opts.OnStream != nil {
+					opts.OnStream(e)
+				}
+			}
+			var partial strings.Builder
+			for _, u := range r.PayloadMsg.Result.Utterances {
+				switch {
+				case !u.Definite:
+					partial.WriteString(u.Text)
+				case u.Text != "":
+					res.Text += u.Text
+					emit(StreamEntry{Timestamp: ts, Final: true, Text: u.Text})
+				}
+			}
+			if partial.Len() > 0 {
+				emit(StreamEntry{Timestamp: ts, Text: partial.String()})
+			}
+		}
+	}()
+
+	// Excute closes resChan, failing or not.
+	err := excute(c, resChan)
+	<-done
+	if err != nil {
+		return nil, err
+	}
+	if resErr != nil {
+		return nil, resErr
+	}
+	return &res, nil
+}
@@ -0,0 +1,64 @@
+// Package atomicfile replaces files so that readers, and the file after a
+// crash, hold either the old or the new content, never a truncated mix.
+package atomicfile
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile replaces path with data.
+func WriteFile(path string, data []byte) error {
+	return Copy(path, bytes.NewReader(data))
+}
+
+// Copy replaces path with the content read from r. The content goes to a
+// temp file next to path, is synced to disk, and is renamed over path; the
+// directory is synced too so the rename survives a crash. The temp file is
+// dot-prefixed so a leftover never reads as a data file.
+func Copy(path string, r io.Reader) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	fail := func(err error) error {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		return fail(err)
+	}
+	if err := f.Chmod(0644); err != nil {
+		return fail(err)
+	}
+	if err := f.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes dir's entries, making a rename in it durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !os.IsPermission(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultCaptureInput returns the ffmpeg input of the default microphone
+// on this system, or "" if it has none ffmpeg can name.
+func DefaultCaptureInput() string {
+	switch runtime.GOOS {
+	case "linux":
+		return "pulse:default"
+	case "darwin":
+		return "avfoundation::0"
+	}
+	return ""
+}
+
+// Capture is audio being recorded by ffmpeg, read as 16-bit little-endian
+// mono PCM.
+type Capture struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+	read   bool // Whether any audio was read
+	once   sync.Once
+	err    error // Of ffmpeg, once it has exited
+}
+
+// StartCapture starts recording input, "<format>:<device>" as given to
+// ffmpeg's -f and -i, such as "pulse:default", "alsa:hw:0" or
+// "avfoundation::0", at sampleRate. It records until Stop or ctx is done.
+func StartCapture(ctx context.Context, input string, sampleRate int) (*Capture, error) {
+	format, device, ok := strings.Cut(input, ":")
+	if !ok || format == "" {
+		return nil, fmt.Errorf("capture input %q is not <format>:<device>", input)
+	}
+	c := &Capture{}
+	c.cmd = exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-nostdin", "-f", format, "-i", device,
+		"-acodec", "pcm_s16le", "-ac", "1", "-ar", strconv.Itoa(sampleRate), "-f", "s16le", "-")
+	c.cmd.Stderr = &c.stderr
+	var err error
+	if c.stdout, err = c.cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	if err := c.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w", err)
+	}
+	return c, nil
+}
+
+// Read reads the recorded audio. It returns io.EOF once ffmpeg has stopped,
+// or ffmpeg's error if it failed before recording anything.
+func (c *Capture) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	c.read = c.read || n > 0
+	if err != io.EOF {
+		return n, err
+	}
+	c.once.Do(func() { c.err = c.cmd.Wait() })
+	// Stopping makes ffmpeg exit with an error too; what it recorded stands.
+	if c.err != nil && !c.read {
+		if msg := strings.TrimSpace(c.stderr.String()); msg != "" {
+			return n, fmt.Errorf("ffmpeg: %v: %s", c.err, msg)
+		}
+		return n, fmt.Errorf("ffmpeg: %w", c.err)
+	}
+	return n, io.EOF
+}
+
+// Stop ends the recording; Read returns what ffmpeg had recorded and then
+// io.EOF.
+func (c *Capture) Stop() error {
+	// ffmpeg flushes its output on an interrupt; Windows cannot send one.
+	err := c.cmd.Process.Signal(os.Interrupt)
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		err = c.cmd.Process.Kill()
+	}
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package audio
+
+import "fmt"
+
+// Channel selections: what of a multichannel recording, such as a call
+// with the agent and the customer on channels of their own, providers hear.
+const (
+	ChannelMix      = "mix"      // All channels averaged, as Mono does
+	ChannelAgent    = "agent"    // The agent's channel alone
+	ChannelCustomer = "customer" // The customer's channel alone
+)
+
+// ChannelOptions control SelectChannel.
+type ChannelOptions struct {
+	Select   string `json:"select,omitempty" yaml:"select,omitempty"`     // ChannelMix, ChannelAgent or ChannelCustomer; "" means ChannelMix
+	Agent    int    `json:"agent,omitempty" yaml:"agent,omitempty"`       // 1-based channel of the agent; 0 means 1, the left
+	Customer int    `json:"customer,omitempty" yaml:"customer,omitempty"` // 1-based channel of the customer; 0 means 2, the right
+}
+
+// Validate reports an unknown selection or a negative channel.
+func (o ChannelOptions) Validate() error {
+	switch o.Select {
+	case "", ChannelMix, ChannelAgent, ChannelCustomer:
+	default:
+		return fmt.Errorf("unknown channel selection %q; want %s, %s or %s", o.Select, ChannelMix, ChannelAgent, ChannelCustomer)
+	}
+	if o.Agent < 0 || o.Customer < 0 {
+		return fmt.Errorf("channels are numbered from 1")
+	}
+	return nil
+}
+
+// IsMix reports whether o averages the channels, as audio is reduced to
+// mono when no channel is selected.
+func (o ChannelOptions) IsMix() bool {
+	return o.Select == "" || o.Select == ChannelMix
+}
+
+// SelectChannel returns p reduced to mono as opts select: the channels
+// averaged, or the agent's or the customer's channel alone. Mono audio is
+// returned as is, since it holds both sides of the call.
+func (p *PCM) SelectChannel(opts ChannelOptions) (*PCM, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if p.Channels <= 1 || opts.IsMix() {
+		return p.Mono(), nil
+	}
+	ch := opts.Agent
+	if ch == 0 {
+		ch = 1
+	}
+	if opts.Select == ChannelCustomer {
+		if ch = opts.Customer; ch == 0 {
+			ch = 2
+		}
+	}
+	if ch > p.Channels {
+		return nil, fmt.Errorf("%s channel %d of audio with %d channels", opts.Select, ch, p.Channels)
+	}
+	return p.Channel(ch - 1), nil
+}
+
+// Channel returns channel i, 0-based, of p as mono audio.
+func (p *PCM) Channel(i int) *PCM {
+	mono := &PCM{SampleRate: p.SampleRate, Channels: 1, Samples: make([]int16, p.Frames())}
+	for j := range mono.Samples {
+		mono.Samples[j] = p.Samples[j*p.Channels+i]
+	}
+	return mono
+}
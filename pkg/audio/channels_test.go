@@ -0,0 +1,48 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelectChannel(t *testing.T) {
+	stereo := &PCM{SampleRate: 8000, Channels: 2, Samples: []int16{100, -100, 200, 0, 300, 1000}}
+	mono := &PCM{SampleRate: 8000, Channels: 1, Samples: []int16{1, 2, 3}}
+	tests := []struct {
+		name string
+		in   *PCM
+		opts ChannelOptions
+		want []int16
+	}{
+		{"default mixes", stereo, ChannelOptions{}, []int16{0, 100, 650}},
+		{"mix", stereo, ChannelOptions{Select: ChannelMix, Agent: 2}, []int16{0, 100, 650}},
+		{"agent on the left", stereo, ChannelOptions{Select: ChannelAgent}, []int16{100, 200, 300}},
+		{"customer on the right", stereo, ChannelOptions{Select: ChannelCustomer}, []int16{-100, 0, 1000}},
+		{"agent on the right", stereo, ChannelOptions{Select: ChannelAgent, Agent: 2, Customer: 1}, []int16{-100, 0, 1000}},
+		{"customer on the left", stereo, ChannelOptions{Select: ChannelCustomer, Agent: 2, Customer: 1}, []int16{100, 200, 300}},
+		{"mono kept", mono, ChannelOptions{Select: ChannelCustomer}, []int16{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.in.SelectChannel(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &PCM{SampleRate: 8000, Channels: 1, Samples: tt.want}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("SelectChannel() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, opts := range []ChannelOptions{
+		{Select: "left"},
+		{Select: ChannelAgent, Agent: -1},
+		{Select: ChannelCustomer, Customer: 3},
+	} {
+		if _, err := stereo.SelectChannel(opts); err == nil {
+			t.Errorf("SelectChannel(%+v) succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ffmpegTimeout bounds a single ffmpeg conversion.
+const ffmpegTimeout = 60 * time.Second
+
+// ToWAV returns the audio file at path as a 16-bit mono WAV file at
+// sampleRate. FLAC and WAV files are converted in Go; other formats, such as
+// Ogg/Opus, M4A, MP3 and AMR, are converted by ffmpeg, which must then be
+// installed. There is no Go MP3 decoder yet, so MP3 still needs ffmpeg.
+func ToWAV(ctx context.Context, path string, sampleRate int) ([]byte, error) {
+	pcm, err := DecodeFile(path)
+	if err == nil {
+		return pcm.Mono().Resample(sampleRate).WAV(), nil
+	}
+	wav, ffErr := ffmpegWAV(ctx, path, sampleRate)
+	if ffErr != nil {
+		return nil, fmt.Errorf("%w; ffmpeg: %v", err, ffErr)
+	}
+	return wav, nil
+}
+
+// ffmpegWAV converts the file at path with ffmpeg.
+func ffmpegWAV(ctx context.Context, path string, sampleRate int) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, ffmpegTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-y", "-i", path,
+		"-acodec", "pcm_s16le", "-ac", "1", "-ar", strconv.Itoa(sampleRate), "-f", "wav", "-")
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("ffmpeg: %w", ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("ffmpeg: %v: %s", err, msg)
+		}
+		return nil, fmt.Errorf("ffmpeg: %w", err)
+	}
+	// Rewrite the header, which ffmpeg leaves unsized when writing to a pipe.
+	pcm, err := Decode(&out)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg output: %w", err)
+	}
+	return pcm.WAV(), nil
+}
@@ -0,0 +1,220 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// PCM is decoded audio as 16-bit samples.
+type PCM struct {
+	SampleRate int
+	Channels   int
+	Samples    []int16 // Interleaved by channel
+}
+
+// Frames returns the number of samples per channel.
+func (p *PCM) Frames() int {
+	if p.Channels == 0 {
+		return 0
+	}
+	return len(p.Samples) / p.Channels
+}
+
+// Mono returns p with its channels averaged into one.
+func (p *PCM) Mono() *PCM {
+	if p.Channels <= 1 {
+		return p
+	}
+	mono := &PCM{SampleRate: p.SampleRate, Channels: 1, Samples: make([]int16, p.Frames())}
+	for i := range mono.Samples {
+		var sum int
+		for _, s := range p.Samples[i*p.Channels : (i+1)*p.Channels] {
+			sum += int(s)
+		}
+		mono.Samples[i] = int16(sum / p.Channels)
+	}
+	return mono
+}
+
+// Clip returns the audio of p from startMS to endMS, limited to its
+// length. The clip shares p's samples.
+func (p *PCM) Clip(startMS, endMS int64) *PCM {
+	frame := func(ms int64) int {
+		return int(min(max(ms*int64(p.SampleRate)/1000, 0), int64(p.Frames())))
+	}
+	from, to := frame(startMS), max(frame(startMS), frame(endMS))
+	return &PCM{SampleRate: p.SampleRate, Channels: p.Channels, Samples: p.Samples[from*p.Channels : to*p.Channels]}
+}
+
+// WAV encodes p as a 16-bit PCM WAV file with the canonical 44-byte header.
+func (p *PCM) WAV() []byte {
+	dataSize := 2 * len(p.Samples)
+	b := make([]byte, 44, 44+dataSize)
+	copy(b[0:], "RIFF")
+	binary.LittleEndian.PutUint32(b[4:], uint32(36+dataSize))
+	copy(b[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(b[16:], 16)
+	binary.LittleEndian.PutUint16(b[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(b[22:], uint16(p.Channels))
+	binary.LittleEndian.PutUint32(b[24:], uint32(p.SampleRate))
+	binary.LittleEndian.PutUint32(b[28:], uint32(p.SampleRate*p.Channels*2))
+	binary.LittleEndian.PutUint16(b[32:], uint16(p.Channels*2))
+	binary.LittleEndian.PutUint16(b[34:], 16)
+	copy(b[36:], "data")
+	binary.LittleEndian.PutUint32(b[40:], uint32(dataSize))
+	for _, s := range p.Samples {
+		b = binary.LittleEndian.AppendUint16(b, uint16(s))
+	}
+	return b
+}
+
+// DecodeFile decodes the FLAC or WAV file at path.
+func DecodeFile(path string) (*PCM, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	pcm, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pcm, nil
+}
+
+// Decode decodes a FLAC or WAV stream. Samples of other widths are scaled
+// to 16 bits. It returns ErrUnsupported for other formats.
+func Decode(r io.Reader) (*PCM, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	if err := skipID3(br); err != nil {
+		return nil, err
+	}
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	switch string(magic) {
+	case "fLaC":
+		return decodeFLAC(br)
+	case "RIFF":
+		return decodeWAV(br)
+	}
+	return nil, ErrUnsupported
+}
+
+// WAV format tags.
+const (
+	wavPCM        = 1
+	wavFloat      = 3
+	wavExtensible = 0xfffe
+)
+
+func decodeWAV(r io.Reader) (*PCM, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, err
+	}
+	if string(riff[8:12]) != "WAVE" {
+		return nil, ErrUnsupported
+	}
+
+	var (
+		pcm        = &PCM{}
+		format     int
+		blockAlign int
+		bits       int
+	)
+	for {
+		var ch [8]byte
+		if _, err := io.ReadFull(r, ch[:]); err != nil {
+			return nil, fmt.Errorf("wav: no data chunk: %w", err)
+		}
+		size := int64(binary.LittleEndian.Uint32(ch[4:]))
+		switch string(ch[:4]) {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("wav: fmt chunk too short")
+			}
+			fmtc := make([]byte, size)
+			if _, err := io.ReadFull(r, fmtc); err != nil {
+				return nil, err
+			}
+			format = int(binary.LittleEndian.Uint16(fmtc))
+			pcm.Channels = int(binary.LittleEndian.Uint16(fmtc[2:]))
+			pcm.SampleRate = int(binary.LittleEndian.Uint32(fmtc[4:]))
+			blockAlign = int(binary.LittleEndian.Uint16(fmtc[12:]))
+			bits = int(binary.LittleEndian.Uint16(fmtc[14:]))
+			// The sub-format GUID starts with the format tag.
+			if format == wavExtensible && size >= 26 {
+				format = int(binary.LittleEndian.Uint16(fmtc[24:]))
+			}
+			if size&1 != 0 {
+				io.CopyN(io.Discard, r, 1)
+			}
+			continue
+		case "data":
+			if blockAlign == 0 {
+				return nil, fmt.Errorf("wav: data chunk before fmt chunk")
+			}
+			if pcm.Channels == 0 || blockAlign != pcm.Channels*((bits+7)/8) {
+				return nil, fmt.Errorf("wav: block align %d does not fit %d channels of %d bits", blockAlign, pcm.Channels, bits)
+			}
+			conv, err := wavSampleFunc(format, bits)
+			if err != nil {
+				return nil, err
+			}
+			var data io.Reader = r
+			// Streaming writers leave the size at 0 or 0xffffffff; read to
+			// the end then.
+			if size != 0 && size != 0xffffffff {
+				data = io.LimitReader(r, size)
+			}
+			b, err := io.ReadAll(data)
+			if err != nil {
+				return nil, err
+			}
+			b = b[:len(b)-len(b)%blockAlign]
+			width := blockAlign / pcm.Channels
+			pcm.Samples = make([]int16, len(b)/width)
+			for i := range pcm.Samples {
+				pcm.Samples[i] = conv(b[i*width:])
+			}
+			return pcm, nil
+		}
+		if _, err := io.CopyN(io.Discard, r, size+size&1); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wavSampleFunc returns the function converting one little-endian sample
+// of the format to 16 bits.
+func wavSampleFunc(format, bits int) (func([]byte) int16, error) {
+	switch {
+	case format == wavPCM && bits == 8:
+		return func(b []byte) int16 { return (int16(b[0]) - 128) << 8 }, nil
+	case format == wavPCM && bits == 16:
+		return func(b []byte) int16 { return int16(binary.LittleEndian.Uint16(b)) }, nil
+	case format == wavPCM && bits == 24:
+		return func(b []byte) int16 { return int16(b[1]) | int16(b[2])<<8 }, nil
+	case format == wavPCM && bits == 32:
+		return func(b []byte) int16 { return int16(binary.LittleEndian.Uint32(b) >> 16) }, nil
+	case format == wavFloat && bits == 32:
+		return func(b []byte) int16 {
+			return floatTo16(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+		}, nil
+	case format == wavFloat && bits == 64:
+		return func(b []byte) int16 {
+			return floatTo16(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		}, nil
+	}
+	return nil, fmt.Errorf("wav: %w: format %d with %d bits", ErrUnsupported, format, bits)
+}
+
+func floatTo16(v float64) int16 {
+	return int16(math.Round(max(-1, min(1, v)) * math.MaxInt16))
+}
@@ -0,0 +1,315 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func (w *bitWriter) writeSigned(v int64, n int) { w.write(uint64(v)&(1<<n-1), n) }
+
+// subframe writes one subframe of samples with bps bits.
+type subframe func(w *bitWriter, samples []int64, bps int)
+
+func constant(w *bitWriter, s []int64, bps int) {
+	w.write(0, 8)
+	w.writeSigned(s[0], bps)
+}
+
+func verbatim(w *bitWriter, s []int64, bps int) {
+	w.write(1<<1, 8)
+	for _, v := range s {
+		w.writeSigned(v, bps)
+	}
+}
+
+// wasted writes a constant subframe declaring k wasted bits.
+func wasted(k int) subframe {
+	return func(w *bitWriter, s []int64, bps int) {
+		w.write(1, 8)
+		w.write(1, k) // Unary k-1
+		w.writeSigned(s[0]>>k, bps-k)
+	}
+}
+
+func fixed(order, param int) subframe {
+	return func(w *bitWriter, s []int64, bps int) {
+		w.write(uint64(8+order)<<1, 8)
+		for _, v := range s[:order] {
+			w.writeSigned(v, bps)
+		}
+		writeResidual(w, s, fixedCoefs[order], 0, param)
+	}
+}
+
+func lpc(coefs []int64, precision, shift, param int) subframe {
+	return func(w *bitWriter, s []int64, bps int) {
+		w.write(uint64(32+len(coefs)-1)<<1, 8)
+		for _, v := range s[:len(coefs)] {
+			w.writeSigned(v, bps)
+		}
+		w.write(uint64(precision-1), 4)
+		w.writeSigned(int64(shift), 5)
+		for _, c := range coefs {
+			w.writeSigned(c, precision)
+		}
+		writeResidual(w, s, coefs, shift, param)
+	}
+}
+
+// writeResidual writes the prediction residual as one Rice partition, or an
+// escaped partition of 16-bit values if param is 15.
+func writeResidual(w *bitWriter, s []int64, coefs []int64, shift, param int) {
+	w.write(0, 2) // 4-bit Rice parameters
+	w.write(0, 4) // One partition
+	w.write(uint64(param), 4)
+	if param == 15 {
+		w.write(16, 5)
+	}
+	for i := len(coefs); i < len(s); i++ {
+		var sum int64
+		for j, c := range coefs {
+			sum += c * s[i-1-j]
+		}
+		r := s[i] - sum>>shift
+		if param == 15 {
+			w.writeSigned(r, 16)
+			continue
+		}
+		u := uint64(r<<1 ^ r>>63)
+		for range u >> param {
+			w.write(0, 1)
+		}
+		w.write(1, 1)
+		w.write(u, param)
+	}
+}
+
+// flacFrame encodes channels as frame number n with the given channel
+// assignment, already decorrelated.
+func flacFrame(n int, assignment int, bps int, channels [][]int64, subframes ...subframe) []byte {
+	w := &bitWriter{}
+	w.write(0x7ffc, 15)
+	w.write(0, 1)
+	w.write(6, 4) // 8-bit block size after the frame number
+	w.write(0, 4) // Sample rate from STREAMINFO
+	w.write(uint64(assignment), 4)
+	w.write(0, 3) // Sample size from STREAMINFO
+	w.write(0, 1)
+	w.write(uint64(n), 8)
+	w.write(uint64(len(channels[0])-1), 8)
+	var crc8 uint8
+	for _, b := range w.buf {
+		crc8 = crc8Table[crc8^b]
+	}
+	w.write(uint64(crc8), 8)
+	for i, s := range channels {
+		sbps := bps
+		if (assignment == leftSide || assignment == midSide) && i == 1 || assignment == sideRight && i == 0 {
+			sbps++
+		}
+		subframes[i](w, s, sbps)
+	}
+	w.align()
+	var crc16 uint16
+	for _, b := range w.buf {
+		crc16 = crc16<<8 ^ crc16Table[byte(crc16>>8)^b]
+	}
+	w.write(uint64(crc16), 16)
+	return w.buf
+}
+
+func flacStream(rate, channels, bps int, samples int64, frames ...[]byte) []byte {
+	b := []byte("fLaC")
+	b = append(b, 0x80, 0, 0, 34)
+	si := make([]byte, 34)
+	binary.BigEndian.PutUint64(si[10:], uint64(rate)<<44|uint64(channels-1)<<41|uint64(bps-1)<<36|uint64(samples))
+	b = append(b, si...)
+	for _, f := range frames {
+		b = append(b, f...)
+	}
+	return b
+}
+
+func TestDecodeFLAC(t *testing.T) {
+	const n = 64
+	left, right := make([]int64, n), make([]int64, n)
+	for i := range n {
+		left[i] = int64(8000 * math.Sin(float64(i)/5))
+		right[i] = int64(6000*math.Cos(float64(i)/7)) - 100
+	}
+	side := make([]int64, n)
+	mid := make([]int64, n)
+	for i := range n {
+		side[i] = left[i] - right[i]
+		mid[i] = (left[i] + right[i]) >> 1
+	}
+	int16s := func(chs ...[]int64) []int16 {
+		var out []int16
+		for i := range chs[0] {
+			for _, c := range chs {
+				out = append(out, int16(c[i]))
+			}
+		}
+		return out
+	}
+	fill := func(v int64) []int64 {
+		s := make([]int64, n)
+		for i := range s {
+			s[i] = v
+		}
+		return s
+	}
+
+	tests := []struct {
+		name string
+		in   []byte
+		want *PCM
+	}{
+		{
+			name: "mono subframe types",
+			in: flacStream(16000, 1, 16, 6*n,
+				flacFrame(0, 0, 16, [][]int64{left}, verbatim),
+				flacFrame(1, 0, 16, [][]int64{left}, fixed(2, 9)),
+				flacFrame(2, 0, 16, [][]int64{left}, lpc([]int64{3, -2}, 12, 1, 10)),
+				flacFrame(3, 0, 16, [][]int64{left}, fixed(1, 15)),
+				flacFrame(4, 0, 16, [][]int64{fill(-1234)}, constant),
+				flacFrame(5, 0, 16, [][]int64{fill(96)}, wasted(5)),
+			),
+			want: &PCM{SampleRate: 16000, Channels: 1, Samples: int16s(append(append(append(append(append(
+				append([]int64{}, left...), left...), left...), left...), fill(-1234)...), fill(96)...))},
+		},
+		{
+			name: "stereo decorrelation",
+			in: flacStream(44100, 2, 16, 4*n,
+				flacFrame(0, 1, 16, [][]int64{left, right}, verbatim, fixed(2, 9)),
+				flacFrame(1, leftSide, 16, [][]int64{left, side}, verbatim, verbatim),
+				flacFrame(2, sideRight, 16, [][]int64{side, right}, fixed(1, 10), verbatim),
+				flacFrame(3, midSide, 16, [][]int64{mid, side}, verbatim, fixed(2, 10)),
+			),
+			want: &PCM{SampleRate: 44100, Channels: 2, Samples: int16s(
+				append(append(append(append([]int64{}, left...), left...), left...), left...),
+				append(append(append(append([]int64{}, right...), right...), right...), right...),
+			)},
+		},
+		{
+			name: "24-bit",
+			in:   flacStream(48000, 1, 24, 2, flacFrame(0, 0, 24, [][]int64{{0x123456, -0x123456}}, verbatim)),
+			want: &PCM{SampleRate: 48000, Channels: 1, Samples: []int16{0x1234, -0x1235}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(bytes.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Decode() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	corrupt := flacStream(16000, 1, 16, n, flacFrame(0, 0, 16, [][]int64{left}, verbatim))
+	corrupt[len(corrupt)-10] ^= 1
+	if _, err := Decode(bytes.NewReader(corrupt)); err == nil {
+		t.Error("Decode(corrupt frame) succeeded, want CRC error")
+	}
+}
+
+func TestDecodeWAV(t *testing.T) {
+	wav := func(format, channels, bits int, data []byte) []byte {
+		var b bytes.Buffer
+		b.WriteString("RIFF\x00\x00\x00\x00WAVE")
+		b.WriteString("fmt ")
+		binary.Write(&b, binary.LittleEndian, struct {
+			Size                      uint32
+			Format, Channels          uint16
+			SampleRate, ByteRate      uint32
+			BlockAlign, BitsPerSample uint16
+		}{16, uint16(format), uint16(channels), 8000, 0, uint16(channels * bits / 8), uint16(bits)})
+		b.WriteString("data")
+		binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+		b.Write(data)
+		return b.Bytes()
+	}
+	float32s := func(vs ...float32) []byte {
+		var b []byte
+		for _, v := range vs {
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+		}
+		return b
+	}
+
+	tests := []struct {
+		name string
+		in   []byte
+		want *PCM
+	}{
+		{"8-bit", wav(wavPCM, 1, 8, []byte{0, 128, 255}), &PCM{SampleRate: 8000, Channels: 1, Samples: []int16{-32768, 0, 32512}}},
+		{"24-bit stereo", wav(wavPCM, 2, 24, []byte{0, 0x34, 0x12, 0xff, 0xff, 0xff}), &PCM{SampleRate: 8000, Channels: 2, Samples: []int16{0x1234, -1}}},
+		{"float", wav(wavFloat, 1, 32, float32s(0.5, -2)), &PCM{SampleRate: 8000, Channels: 1, Samples: []int16{16384, -32767}}},
+		{"round trip", (&PCM{SampleRate: 16000, Channels: 2, Samples: []int16{1, -2, 300, -400}}).WAV(), &PCM{SampleRate: 16000, Channels: 2, Samples: []int16{1, -2, 300, -400}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(bytes.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Decode() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.flac")
+	in := flacStream(16000, 2, 16, 2, flacFrame(0, 1, 16, [][]int64{{100, -100}, {300, 100}}, verbatim, verbatim))
+	if err := os.WriteFile(path, in, 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ToWAV(context.Background(), path, 16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := (&PCM{SampleRate: 16000, Channels: 1, Samples: []int16{200, 0}}).WAV()
+	if !bytes.Equal(got, want) {
+		t.Errorf("ToWAV() = %x, want %x", got, want)
+	}
+}
+
+func TestClip(t *testing.T) {
+	// 10 frames at 1 kHz, stereo.
+	p := &PCM{SampleRate: 1000, Channels: 2}
+	for i := range 10 {
+		p.Samples = append(p.Samples, int16(i), int16(-i))
+	}
+	tests := []struct {
+		name       string
+		start, end int64
+		want       []int16
+	}{
+		{"within", 2, 4, []int16{2, -2, 3, -3}},
+		{"before the start", -5, 1, []int16{0, 0}},
+		{"past the end", 8, 20, []int16{8, -8, 9, -9}},
+		{"after the end", 12, 20, []int16{}},
+		{"reversed", 4, 2, []int16{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.Clip(tt.start, tt.end)
+			want := &PCM{SampleRate: 1000, Channels: 2, Samples: tt.want}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Clip(%d, %d) mismatch (-want +got):\n%s", tt.start, tt.end, diff)
+			}
+		})
+	}
+}
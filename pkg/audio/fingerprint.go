@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Fingerprints follow Haitsma and Kalker's robust audio hash, which
+// chromaprint builds on: the audio, mono at fingerprintRate, is cut into
+// long overlapping frames, and each frame's energy in fingerprintBands
+// bands between 300 Hz and 2 kHz gives a 32-bit sub-fingerprint. Bit m is
+// set when the energy difference of bands m and m+1 grew since the
+// previous frame, which survives re-encoding, resampling and changes of
+// level.
+const (
+	fingerprintRate  = 8000
+	fingerprintFrame = 4096 // 512 ms
+	fingerprintHop   = 256
+	fingerprintBands = 33
+
+	// fingerprintSilenceDB is the level below which a frame is silent.
+	fingerprintSilenceDB = -50
+)
+
+// FingerprintHopMS is the time between consecutive sub-fingerprints.
+const FingerprintHopMS = fingerprintHop * 1000 / fingerprintRate
+
+// Fingerprint is a perceptual hash of audio, a sub-fingerprint every
+// FingerprintHopMS. Silent frames are 0 and match nothing.
+type Fingerprint []uint32
+
+// Fingerprint returns the fingerprint of p, empty if p is shorter than a
+// frame.
+func (p *PCM) Fingerprint() Fingerprint {
+	mono := p.Mono().Resample(fingerprintRate)
+	n := (len(mono.Samples)-fingerprintFrame)/fingerprintHop + 1
+	if n < 2 {
+		return Fingerprint{}
+	}
+
+	window := make([]float64, fingerprintFrame)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/fingerprintFrame)
+	}
+	var edges [fingerprintBands + 1]int
+	for i := range edges {
+		f := 300 * math.Pow(2000.0/300, float64(i)/fingerprintBands)
+		edges[i] = int(math.Round(f * fingerprintFrame / fingerprintRate))
+	}
+	silence := math.Pow(math.MaxInt16*math.Pow(10, fingerprintSilenceDB/20.0), 2) * fingerprintFrame
+
+	re := make([]float64, fingerprintFrame)
+	im := make([]float64, fingerprintFrame)
+	var energy, prev [fingerprintBands]float64
+	fp := make(Fingerprint, 0, n-1)
+	for i := range n {
+		var power float64
+		for j, s := range mono.Samples[i*fingerprintHop : i*fingerprintHop+fingerprintFrame] {
+			power += float64(s) * float64(s)
+			re[j], im[j] = float64(s)*window[j], 0
+		}
+		fft(re, im)
+		for b := range energy {
+			energy[b] = 0
+			for k := edges[b]; k < edges[b+1]; k++ {
+				energy[b] += re[k]*re[k] + im[k]*im[k]
+			}
+		}
+		if i > 0 {
+			var v uint32
+			if power >= silence {
+				for m := range 32 {
+					if energy[m]-energy[m+1]-(prev[m]-prev[m+1]) > 0 {
+						v |= 1 << m
+					}
+				}
+			}
+			fp = append(fp, v)
+		}
+		prev = energy
+	}
+	return fp
+}
+
+// Compare returns the share of bits b agrees on with a when b's first
+// sub-fingerprint is aligned with a[offset], which may be negative, and
+// the number of frames compared: those both have and neither is silent.
+func Compare(a, b Fingerprint, offset int) (similarity float64, frames int) {
+	var errs int
+	for i := max(0, -offset); i < len(b) && i+offset < len(a); i++ {
+		x, y := a[i+offset], b[i]
+		if x == 0 || y == 0 {
+			continue
+		}
+		errs += bits.OnesCount32(x ^ y)
+		frames++
+	}
+	if frames == 0 {
+		return 0, 0
+	}
+	return 1 - float64(errs)/float64(32*frames), frames
+}
+
+// Voiced returns the number of sub-fingerprints of f that are not silent.
+func (f Fingerprint) Voiced() int {
+	var n int
+	for _, v := range f {
+		if v != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// fft transforms re and im, whose length must be a power of two, in place.
+func fft(re, im []float64) {
+	n := len(re)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			re[i], re[j] = re[j], re[i]
+			im[i], im[j] = im[j], im[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := -2 * math.Pi / float64(size)
+		for k := range size / 2 {
+			wr, wi := math.Cos(step*float64(k)), math.Sin(step*float64(k))
+			for start := 0; start < n; start += size {
+				i, j := start+k, start+k+size/2
+				tr := wr*re[j] - wi*im[j]
+				ti := wr*im[j] + wi*re[j]
+				re[j], im[j] = re[i]-tr, im[i]-ti
+				re[i], im[i] = re[i]+tr, im[i]+ti
+			}
+		}
+	}
+}
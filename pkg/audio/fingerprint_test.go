@@ -0,0 +1,87 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// melody is a seconds long sequence of random notes with harmonics, a
+// stand-in for speech whose spectrum keeps changing.
+func melody(seed int64, rate int, seconds float64) *PCM {
+	r := rand.New(rand.NewSource(seed))
+	p := &PCM{SampleRate: rate, Channels: 1, Samples: make([]int16, int(seconds*float64(rate)))}
+	var freq float64
+	for i := range p.Samples {
+		if i%(rate/8) == 0 {
+			freq = 150 + 600*r.Float64()
+		}
+		t := float64(i) / float64(rate)
+		v := math.Sin(2*math.Pi*freq*t) + 0.5*math.Sin(4*math.Pi*freq*t) + 0.3*math.Sin(6*math.Pi*freq*t)
+		p.Samples[i] = int16(4000 * v)
+	}
+	return p
+}
+
+func TestFingerprint(t *testing.T) {
+	a := melody(1, 16000, 10)
+	fa := a.Fingerprint()
+	if want := (10*8000-fingerprintFrame)/fingerprintHop + 1 - 1; len(fa) != want {
+		t.Fatalf("len(Fingerprint()) = %d, want %d", len(fa), want)
+	}
+
+	// The same recording at another rate, quieter and with noise.
+	r := rand.New(rand.NewSource(2))
+	b := a.Resample(44100)
+	for i, s := range b.Samples {
+		b.Samples[i] = int16(float64(s)*0.5 + 200*r.NormFloat64())
+	}
+	// Its second half, starting 64 sub-fingerprints in.
+	shift := 64 * FingerprintHopMS * 16
+	c := &PCM{SampleRate: 16000, Channels: 1, Samples: a.Samples[shift:]}
+
+	tests := []struct {
+		name   string
+		b      Fingerprint
+		offset int
+		min    float64
+		max    float64
+	}{
+		{"itself", fa, 0, 1, 1},
+		{"re-encoded", b.Fingerprint(), 0, 0.85, 1},
+		{"cut", c.Fingerprint(), 64, 0.95, 1},
+		{"misaligned", fa, 10, 0, 0.65},
+		{"other recording", melody(3, 16000, 10).Fingerprint(), 0, 0, 0.65},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, frames := Compare(fa, tt.b, tt.offset)
+			if got < tt.min || got > tt.max || frames == 0 {
+				t.Errorf("Compare() = %.3f over %d frames, want %.2f to %.2f", got, frames, tt.min, tt.max)
+			}
+		})
+	}
+
+	silent := &PCM{SampleRate: 16000, Channels: 1, Samples: make([]int16, 16000)}
+	if n := silent.Fingerprint().Voiced(); n != 0 {
+		t.Errorf("Voiced() of silence = %d, want 0", n)
+	}
+}
+
+func TestFFT(t *testing.T) {
+	re := make([]float64, 64)
+	im := make([]float64, 64)
+	for i := range re {
+		re[i] = math.Cos(2 * math.Pi * 5 * float64(i) / 64)
+	}
+	fft(re, im)
+	for k := range re {
+		want := 0.0
+		if k == 5 || k == 59 {
+			want = 32
+		}
+		if got := math.Hypot(re[k], im[k]); math.Abs(got-want) > 1e-9 {
+			t.Errorf("bin %d = %g, want %g", k, got, want)
+		}
+	}
+}
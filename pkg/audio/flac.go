@@ -0,0 +1,550 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// decodeFLAC decodes a FLAC stream positioned at its "fLaC" marker.
+func decodeFLAC(r *bufio.Reader) (*PCM, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	var si streamInfo
+	for last := false; !last; {
+		var bh [4]byte
+		if _, err := io.ReadFull(r, bh[:]); err != nil {
+			return nil, fmt.Errorf("flac: metadata: %w", err)
+		}
+		last = bh[0]&0x80 != 0
+		size := int(bh[1])<<16 | int(bh[2])<<8 | int(bh[3])
+		if bh[0]&0x7f == 0 {
+			if size < 34 {
+				return nil, fmt.Errorf("flac: STREAMINFO too short")
+			}
+			var b [34]byte
+			if _, err := io.ReadFull(r, b[:]); err != nil {
+				return nil, err
+			}
+			si = parseStreamInfo(b[:])
+			size -= 34
+		}
+		if _, err := r.Discard(size); err != nil {
+			return nil, fmt.Errorf("flac: metadata: %w", err)
+		}
+	}
+	if si.sampleRate == 0 {
+		return nil, fmt.Errorf("flac: no STREAMINFO")
+	}
+
+	pcm := &PCM{SampleRate: si.sampleRate, Channels: si.channels}
+	if si.samples > 0 {
+		pcm.Samples = make([]int16, 0, si.samples*int64(si.channels))
+	}
+	br := &bitReader{r: r}
+	for si.samples == 0 || int64(len(pcm.Samples)) < si.samples*int64(si.channels) {
+		if _, err := r.Peek(1); err == io.EOF {
+			break
+		}
+		if err := decodeFrame(br, &si, pcm); err != nil {
+			return nil, fmt.Errorf("flac: frame at sample %d: %w", len(pcm.Samples)/si.channels, err)
+		}
+	}
+	return pcm, nil
+}
+
+// streamInfo is what decoding needs of the STREAMINFO block.
+type streamInfo struct {
+	sampleRate    int
+	channels      int
+	bitsPerSample int
+	samples       int64 // Per channel; 0 when unknown
+}
+
+func parseStreamInfo(b []byte) streamInfo {
+	v := binary.BigEndian.Uint64(b[10:18])
+	return streamInfo{
+		sampleRate:    int(v >> 44),
+		channels:      int(v>>41&0x7) + 1,
+		bitsPerSample: int(v>>36&0x1f) + 1,
+		samples:       int64(v & (1<<36 - 1)),
+	}
+}
+
+// Channel assignments of a frame besides independent channels.
+const (
+	leftSide  = 8
+	sideRight = 9
+	midSide   = 10
+)
+
+var errCRC = errors.New("CRC mismatch")
+
+// decodeFrame decodes one frame and appends its samples to pcm.
+func decodeFrame(br *bitReader, si *streamInfo, pcm *PCM) error {
+	br.resetCRC()
+	sync, err := br.read(15)
+	if err != nil {
+		return err
+	}
+	if sync != 0x7ffc {
+		return fmt.Errorf("lost sync")
+	}
+	if _, err := br.read(1); err != nil { // Blocking strategy
+		return err
+	}
+	codes, err := br.read(16)
+	if err != nil {
+		return err
+	}
+	bsCode, srCode, chCode, ssCode := codes>>12, codes>>8&0xf, int(codes>>4&0xf), codes>>1&0x7
+	if err := br.skipUTF8(); err != nil {
+		return err
+	}
+
+	var blockSize int
+	switch {
+	case bsCode == 1:
+		blockSize = 192
+	case bsCode >= 2 && bsCode <= 5:
+		blockSize = 576 << (bsCode - 2)
+	case bsCode == 6:
+		n, err := br.read(8)
+		if err != nil {
+			return err
+		}
+		blockSize = int(n) + 1
+	case bsCode == 7:
+		n, err := br.read(16)
+		if err != nil {
+			return err
+		}
+		blockSize = int(n) + 1
+	case bsCode >= 8:
+		blockSize = 256 << (bsCode - 8)
+	default:
+		return fmt.Errorf("reserved block size")
+	}
+	switch srCode { // The rate is in STREAMINFO; skip any in the header.
+	case 12:
+		_, err = br.read(8)
+	case 13, 14:
+		_, err = br.read(16)
+	case 15:
+		err = fmt.Errorf("invalid sample rate")
+	}
+	if err != nil {
+		return err
+	}
+	bps := si.bitsPerSample
+	switch ssCode {
+	case 0:
+	case 1:
+		bps = 8
+	case 2:
+		bps = 12
+	case 4:
+		bps = 16
+	case 5:
+		bps = 20
+	case 6:
+		bps = 24
+	case 7:
+		bps = 32
+	default:
+		return fmt.Errorf("reserved sample size")
+	}
+	want := br.crc8
+	crc, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	if uint8(crc) != want {
+		return fmt.Errorf("header %w", errCRC)
+	}
+
+	channels := chCode + 1
+	if chCode >= leftSide {
+		if chCode > midSide {
+			return fmt.Errorf("reserved channel assignment")
+		}
+		channels = 2
+	}
+	if channels != si.channels {
+		return fmt.Errorf("%d channels, STREAMINFO says %d", channels, si.channels)
+	}
+	ch := make([][]int64, channels)
+	for i := range ch {
+		sbps := bps
+		// The side channel carries one more bit.
+		if (chCode == leftSide || chCode == midSide) && i == 1 || chCode == sideRight && i == 0 {
+			sbps++
+		}
+		ch[i] = make([]int64, blockSize)
+		if err := decodeSubframe(br, sbps, ch[i]); err != nil {
+			return fmt.Errorf("channel %d: %w", i, err)
+		}
+	}
+	br.align()
+	want16 := br.crc16
+	crc, err = br.read(16)
+	if err != nil {
+		return err
+	}
+	if uint16(crc) != want16 {
+		return fmt.Errorf("frame %w", errCRC)
+	}
+
+	switch chCode {
+	case leftSide:
+		for i, side := range ch[1] {
+			ch[1][i] = ch[0][i] - side
+		}
+	case sideRight:
+		for i, side := range ch[0] {
+			ch[0][i] = side + ch[1][i]
+		}
+	case midSide:
+		for i, side := range ch[1] {
+			mid := ch[0][i]<<1 | side&1
+			ch[0][i], ch[1][i] = (mid+side)>>1, (mid-side)>>1
+		}
+	}
+	for i := range blockSize {
+		for _, c := range ch {
+			pcm.Samples = append(pcm.Samples, to16(c[i], bps))
+		}
+	}
+	return nil
+}
+
+// to16 scales a bps-bit sample to 16 bits.
+func to16(v int64, bps int) int16 {
+	if bps > 16 {
+		return int16(v >> (bps - 16))
+	}
+	return int16(v << (16 - bps))
+}
+
+// fixedCoefs are the predictor coefficients of the fixed subframe orders.
+var fixedCoefs = [][]int64{
+	{},
+	{1},
+	{2, -1},
+	{3, -3, 1},
+	{4, -6, 4, -1},
+}
+
+func decodeSubframe(br *bitReader, bps int, out []int64) error {
+	hdr, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	if hdr&0x80 != 0 {
+		return fmt.Errorf("subframe padding bit set")
+	}
+	wasted := 0
+	if hdr&1 != 0 {
+		k, err := br.unary()
+		if err != nil {
+			return err
+		}
+		wasted = int(k) + 1
+		bps -= wasted
+	}
+
+	switch typ := hdr >> 1 & 0x3f; {
+	case typ == 0:
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		for i := range out {
+			out[i] = v
+		}
+	case typ == 1:
+		for i := range out {
+			if out[i], err = br.readSigned(bps); err != nil {
+				return err
+			}
+		}
+	case typ >= 8 && typ <= 12:
+		order := int(typ - 8)
+		if err := decodeFixed(br, bps, out, fixedCoefs[order]); err != nil {
+			return err
+		}
+	case typ >= 32:
+		order := int(typ-32) + 1
+		if order > len(out) {
+			return fmt.Errorf("LPC order %d exceeds block size", order)
+		}
+		// Warm-up samples come before the coefficients, so read them here.
+		for i := range order {
+			if out[i], err = br.readSigned(bps); err != nil {
+				return err
+			}
+		}
+		p, err := br.read(4)
+		if err != nil {
+			return err
+		}
+		if p == 0xf {
+			return fmt.Errorf("invalid LPC precision")
+		}
+		shift, err := br.readSigned(5)
+		if err != nil {
+			return err
+		}
+		if shift < 0 {
+			return fmt.Errorf("negative LPC shift")
+		}
+		coefs := make([]int64, order)
+		for i := range coefs {
+			if coefs[i], err = br.readSigned(int(p) + 1); err != nil {
+				return err
+			}
+		}
+		if err := decodeResidual(br, out, order); err != nil {
+			return err
+		}
+		predict(out, coefs, int(shift))
+	default:
+		return fmt.Errorf("reserved subframe type %d", typ)
+	}
+	if wasted > 0 {
+		for i := range out {
+			out[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+// decodeFixed reads the warm-up samples and residual of a fixed subframe
+// and restores the signal.
+func decodeFixed(br *bitReader, bps int, out []int64, coefs []int64) error {
+	order := len(coefs)
+	if order > len(out) {
+		return fmt.Errorf("predictor order %d exceeds block size", order)
+	}
+	var err error
+	for i := range order {
+		if out[i], err = br.readSigned(bps); err != nil {
+			return err
+		}
+	}
+	if err := decodeResidual(br, out, order); err != nil {
+		return err
+	}
+	predict(out, coefs, 0)
+	return nil
+}
+
+// predict adds the prediction from the preceding samples to the residuals
+// in out after the warm-up samples.
+func predict(out, coefs []int64, shift int) {
+	for i := len(coefs); i < len(out); i++ {
+		var sum int64
+		for j, c := range coefs {
+			sum += c * out[i-1-j]
+		}
+		out[i] += sum >> shift
+	}
+}
+
+// decodeResidual reads the Rice-coded residual into out[order:].
+func decodeResidual(br *bitReader, out []int64, order int) error {
+	method, err := br.read(2)
+	if err != nil {
+		return err
+	}
+	if method > 1 {
+		return fmt.Errorf("reserved residual coding method")
+	}
+	paramBits, escape := 4, uint64(0xf)
+	if method == 1 {
+		paramBits, escape = 5, 0x1f
+	}
+	po, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << po
+	if len(out)%partitions != 0 || len(out)/partitions < order {
+		return fmt.Errorf("invalid partition order %d", po)
+	}
+	i := order
+	for p := range partitions {
+		n := len(out) / partitions
+		if p == 0 {
+			n -= order
+		}
+		param, err := br.read(paramBits)
+		if err != nil {
+			return err
+		}
+		if param == escape {
+			bits, err := br.read(5)
+			if err != nil {
+				return err
+			}
+			for range n {
+				if out[i], err = br.readSigned(int(bits)); err != nil {
+					return err
+				}
+				i++
+			}
+			continue
+		}
+		for range n {
+			q, err := br.unary()
+			if err != nil {
+				return err
+			}
+			r, err := br.read(int(param))
+			if err != nil {
+				return err
+			}
+			v := q<<param | r
+			out[i] = int64(v>>1) ^ -int64(v&1)
+			i++
+		}
+	}
+	return nil
+}
+
+// bitReader reads big-endian bit fields, keeping the CRCs FLAC frames
+// end their header and body with.
+type bitReader struct {
+	r     io.ByteReader
+	cur   uint64 // Unread bits, right-aligned
+	n     int    // Number of bits in cur
+	crc8  uint8
+	crc16 uint16
+}
+
+func (br *bitReader) resetCRC() {
+	br.crc8, br.crc16 = 0, 0
+}
+
+func (br *bitReader) fill() error {
+	b, err := br.r.ReadByte()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	br.crc8 = crc8Table[br.crc8^b]
+	br.crc16 = br.crc16<<8 ^ crc16Table[byte(br.crc16>>8)^b]
+	br.cur = br.cur<<8 | uint64(b)
+	br.n += 8
+	return nil
+}
+
+// read returns the next n bits, n at most 32.
+func (br *bitReader) read(n int) (uint64, error) {
+	for br.n < n {
+		if err := br.fill(); err != nil {
+			return 0, err
+		}
+	}
+	br.n -= n
+	v := br.cur >> br.n & (1<<n - 1)
+	br.cur &= 1<<br.n - 1
+	return v, nil
+}
+
+// readSigned returns the next n bits as a two's complement number.
+func (br *bitReader) readSigned(n int) (int64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	if n > 32 {
+		hi, err := br.read(n - 32)
+		if err != nil {
+			return 0, err
+		}
+		lo, err := br.read(32)
+		if err != nil {
+			return 0, err
+		}
+		return int64((hi<<32|lo)<<(64-n)) >> (64 - n), nil
+	}
+	v, err := br.read(n)
+	if err != nil {
+		return 0, err
+	}
+	return int64(v<<(64-n)) >> (64 - n), nil
+}
+
+// unary counts the 0 bits before the next 1 bit.
+func (br *bitReader) unary() (uint64, error) {
+	var k uint64
+	for {
+		b, err := br.read(1)
+		if err != nil {
+			return 0, err
+		}
+		if b == 1 {
+			return k, nil
+		}
+		k++
+	}
+}
+
+// skipUTF8 skips the frame or sample number, coded like UTF-8.
+func (br *bitReader) skipUTF8() error {
+	b, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	extra := 0
+	for mask := uint64(0x80); b&mask != 0 && mask > 1; mask >>= 1 {
+		extra++
+	}
+	if extra == 1 || extra > 7 {
+		return fmt.Errorf("invalid coded number")
+	}
+	if extra > 0 {
+		extra--
+	}
+	for range extra {
+		if _, err := br.read(8); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// align drops the bits left of the current byte.
+func (br *bitReader) align() {
+	br.n -= br.n % 8
+	br.cur &= 1<<br.n - 1
+}
+
+// crc8Table and crc16Table are for the polynomials x^8+x^2+x+1 and
+// x^16+x^15+x^2+1, as FLAC uses.
+var crc8Table, crc16Table = func() (t8 [256]uint8, t16 [256]uint16) {
+	for i := range 256 {
+		c8 := uint8(i)
+		c16 := uint16(i) << 8
+		for range 8 {
+			if c8&0x80 != 0 {
+				c8 = c8<<1 ^ 0x07
+			} else {
+				c8 <<= 1
+			}
+			if c16&0x8000 != 0 {
+				c16 = c16<<1 ^ 0x8005
+			} else {
+				c16 <<= 1
+			}
+		}
+		t8[i], t16[i] = c8, c16
+	}
+	return
+}()
@@ -0,0 +1,198 @@
+package audio
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+)
+
+// flacBlockSize is the number of samples per channel in an encoded FLAC
+// frame, the size the reference encoder uses.
+const flacBlockSize = 4096
+
+// FLAC encodes p as a 16-bit FLAC file without ffmpeg. Each channel of a
+// frame is coded with the fixed predictor of order 0 to 4 that fits it
+// best and a single Rice partition: simpler than the LPC of the reference
+// encoder, at some cost in size.
+func (p *PCM) FLAC() []byte {
+	frames := p.Frames()
+	b := []byte("fLaC")
+	// STREAMINFO, the last metadata block.
+	b = append(b, 0x80, 0, 0, 34)
+	b = binary.BigEndian.AppendUint16(b, flacBlockSize)
+	b = binary.BigEndian.AppendUint16(b, flacBlockSize)
+	b = append(b, 0, 0, 0, 0, 0, 0) // Frame sizes unknown
+	b = binary.BigEndian.AppendUint64(b, uint64(p.SampleRate)<<44|uint64(p.Channels-1)<<41|15<<36|uint64(frames))
+	raw := make([]byte, 0, 2*len(p.Samples))
+	for _, s := range p.Samples {
+		raw = binary.LittleEndian.AppendUint16(raw, uint16(s))
+	}
+	sum := md5.Sum(raw)
+	b = append(b, sum[:]...)
+
+	channel := make([]int64, flacBlockSize)
+	for n, start := 0, 0; start < frames; n, start = n+1, start+flacBlockSize {
+		size := min(flacBlockSize, frames-start)
+		var bw bitWriter
+		bw.write(0xfff8, 16) // Sync, fixed block size
+		bw.write(7<<12|0<<8|uint64(p.Channels-1)<<4|4<<1, 16)
+		bw.writeUTF8(uint64(n))
+		bw.write(uint64(size-1), 16)
+		frame := bw.bytes()
+		frame = append(frame, flacCRC8(frame))
+
+		bw = bitWriter{buf: frame}
+		for ch := range p.Channels {
+			for i := range size {
+				channel[i] = int64(p.Samples[(start+i)*p.Channels+ch])
+			}
+			encodeSubframe(&bw, channel[:size])
+		}
+		frame = bw.bytes()
+		b = binary.BigEndian.AppendUint16(append(b, frame...), flacCRC16(frame))
+	}
+	return b
+}
+
+// encodeSubframe writes samples as a fixed-predictor subframe, which need
+// not end on a byte.
+func encodeSubframe(bw *bitWriter, samples []int64) {
+	order, residual := 0, samples
+	best := sumAbs(samples)
+	for o := 1; o <= 4 && o < len(samples); o++ {
+		r := fixedResidual(samples, o)
+		if s := sumAbs(r); s < best {
+			order, residual, best = o, r, s
+		}
+	}
+
+	bw.write(uint64(8+order)<<1, 8) // Fixed, no wasted bits
+	for _, s := range samples[:order] {
+		bw.write(uint64(s)&0xffff, 16)
+	}
+	u := make([]uint64, len(residual))
+	for i, r := range residual {
+		u[i] = uint64(r<<1 ^ r>>63)
+	}
+	param := riceParam(u)
+	bw.write(0, 2) // 4-bit Rice parameters
+	bw.write(0, 4) // A single partition
+	bw.write(uint64(param), 4)
+	for _, v := range u {
+		bw.writeUnary(v >> param)
+		bw.write(v&(1<<param-1), param)
+	}
+}
+
+// fixedResidual returns what the fixed predictor of the order leaves of
+// samples after the warm-up samples.
+func fixedResidual(samples []int64, order int) []int64 {
+	r := make([]int64, len(samples)-order)
+	for i := range r {
+		s := samples[i : i+order+1]
+		switch order {
+		case 1:
+			r[i] = s[1] - s[0]
+		case 2:
+			r[i] = s[2] - 2*s[1] + s[0]
+		case 3:
+			r[i] = s[3] - 3*s[2] + 3*s[1] - s[0]
+		case 4:
+			r[i] = s[4] - 4*s[3] + 6*s[2] - 4*s[1] + s[0]
+		}
+	}
+	return r
+}
+
+func sumAbs(v []int64) int64 {
+	var sum int64
+	for _, x := range v {
+		sum += max(x, -x)
+	}
+	return sum
+}
+
+// riceParam returns the Rice parameter coding u in the fewest bits.
+func riceParam(u []uint64) int {
+	best, bestBits := 0, uint64(1<<63)
+	for k := range 15 {
+		bits := uint64(len(u)) * uint64(k+1)
+		for _, v := range u {
+			bits += v >> k
+		}
+		if bits < bestBits {
+			best, bestBits = k, bits
+		}
+	}
+	return best
+}
+
+// bitWriter appends big-endian bit fields to buf.
+type bitWriter struct {
+	buf []byte
+	cur uint64 // Unwritten bits, right-aligned
+	n   int    // Number of bits in cur
+}
+
+// write appends the low n bits of v, n at most 32.
+func (bw *bitWriter) write(v uint64, n int) {
+	bw.cur = bw.cur<<n | v&(1<<n-1)
+	bw.n += n
+	for bw.n >= 8 {
+		bw.n -= 8
+		bw.buf = append(bw.buf, byte(bw.cur>>bw.n))
+	}
+	bw.cur &= 1<<bw.n - 1
+}
+
+// writeUnary appends q 0 bits and a 1 bit.
+func (bw *bitWriter) writeUnary(q uint64) {
+	for ; q >= 32; q -= 32 {
+		bw.write(0, 32)
+	}
+	bw.write(1, int(q)+1)
+}
+
+// writeUTF8 appends v coded like UTF-8, as frame numbers are.
+func (bw *bitWriter) writeUTF8(v uint64) {
+	if v < 0x80 {
+		bw.write(v, 8)
+		return
+	}
+	extra := 1
+	for v >= 1<<(6*extra+6-extra) {
+		extra++
+	}
+	bw.write(0xff00>>(extra+1)&0xff|v>>(6*extra), 8)
+	for i := extra - 1; i >= 0; i-- {
+		bw.write(0x80|v>>(6*i)&0x3f, 8)
+	}
+}
+
+// align pads to a byte with 0 bits.
+func (bw *bitWriter) align() {
+	if bw.n > 0 {
+		bw.write(0, 8-bw.n)
+	}
+}
+
+// bytes returns what has been written, padded to a byte.
+func (bw *bitWriter) bytes() []byte {
+	bw.align()
+	return bw.buf
+}
+
+func flacCRC8(b []byte) uint8 {
+	var crc uint8
+	for _, c := range b {
+		crc = crc8Table[crc^c]
+	}
+	return crc
+}
+
+func flacCRC16(b []byte) uint16 {
+	var crc uint16
+	for _, c := range b {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^c]
+	}
+	return crc
+}
@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFLAC(t *testing.T) {
+	noise := &PCM{SampleRate: 8000, Channels: 2, Samples: make([]int16, 2*5000)}
+	r := rand.New(rand.NewSource(1))
+	for i := range noise.Samples {
+		noise.Samples[i] = int16(r.Intn(1 << 16))
+	}
+	extremes := &PCM{SampleRate: 16000, Channels: 1}
+	for i := range 3 {
+		extremes.Samples = append(extremes.Samples, math.MaxInt16, math.MinInt16, 0, int16(i))
+	}
+
+	tests := []struct {
+		name string
+		in   *PCM
+	}{
+		{"empty", &PCM{SampleRate: 16000, Channels: 1}},
+		{"one sample", &PCM{SampleRate: 16000, Channels: 1, Samples: []int16{-7}}},
+		// Past frame 127, whose number takes two bytes.
+		{"melody", melody(1, 16000, 40)},
+		{"stereo noise", noise},
+		{"extremes", extremes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := tt.in.FLAC()
+			info, err := readInfo(bufio.NewReader(bytes.NewReader(enc)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := int64(tt.in.Frames()) * 1000 / int64(tt.in.SampleRate); info.DurationMS != want {
+				t.Errorf("DurationMS = %d, want %d", info.DurationMS, want)
+			}
+			got, err := Decode(bytes.NewReader(enc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Samples == nil {
+				got.Samples = []int16{}
+			}
+			want := *tt.in
+			if want.Samples == nil {
+				want.Samples = []int16{}
+			}
+			if diff := cmp.Diff(&want, got); diff != "" {
+				t.Errorf("Decode(FLAC()) mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	m := melody(2, 16000, 5)
+	if flac, wav := len(m.FLAC()), len(m.WAV()); 3*flac > 2*wav {
+		t.Errorf("FLAC() is %d bytes, want at most two thirds of WAV()'s %d", flac, wav)
+	}
+}
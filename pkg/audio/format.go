@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Input formats: FLAC and WAV, decoded in Go, and the compressed formats
+// recordings such as call-center exports arrive in, which ffmpeg converts.
+const (
+	FormatFLAC = "flac"
+	FormatWAV  = "wav"
+	FormatOgg  = "ogg"  // Vorbis in Ogg
+	FormatOpus = "opus" // Opus in Ogg
+	FormatM4A  = "m4a"  // AAC or ALAC in MP4
+	FormatMP3  = "mp3"
+	FormatAMR  = "amr" // AMR-NB or AMR-WB
+)
+
+// formatExts maps the file extensions of the input formats to them.
+var formatExts = map[string]string{
+	".flac": FormatFLAC,
+	".wav":  FormatWAV,
+	".ogg":  FormatOgg,
+	".oga":  FormatOgg,
+	".opus": FormatOpus,
+	".m4a":  FormatM4A,
+	".mp4":  FormatM4A,
+	".mp3":  FormatMP3,
+	".amr":  FormatAMR,
+	".awb":  FormatAMR,
+}
+
+// FormatOfExt returns the input format of a file named name by its
+// extension, or "" if it has none of theirs.
+func FormatOfExt(name string) string {
+	return formatExts[strings.ToLower(filepath.Ext(name))]
+}
+
+// DetectFile returns the input format of the file at path by its content,
+// or "" if it is none of them.
+func DetectFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	format, err := detect(bufio.NewReaderSize(f, 512))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return format, nil
+}
+
+// detect sniffs the start of r, past any ID3 tag.
+func detect(r *bufio.Reader) (string, error) {
+	id3, _ := r.Peek(3)
+	tagged := string(id3) == "ID3"
+	if err := skipID3(r); err != nil {
+		return "", err
+	}
+	// An Ogg page header is 27 bytes, its segment table at most 255, and
+	// the first packet then starts with the codec's magic.
+	head, _ := r.Peek(27 + 255 + 8)
+	switch {
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return FormatFLAC, nil
+	case len(head) >= 12 && (string(head[:4]) == "RIFF" || string(head[:4]) == "RF64") && string(head[8:12]) == "WAVE":
+		return FormatWAV, nil
+	case len(head) >= 27 && string(head[:4]) == "OggS":
+		packet := head[min(27+int(head[26]), len(head)):]
+		switch {
+		case bytes.HasPrefix(packet, []byte("OpusHead")):
+			return FormatOpus, nil
+		case bytes.HasPrefix(packet, []byte("\x01vorbis")):
+			return FormatOgg, nil
+		}
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return FormatM4A, nil
+	case bytes.HasPrefix(head, []byte("#!AMR\n")), bytes.HasPrefix(head, []byte("#!AMR-WB\n")):
+		return FormatAMR, nil
+	case len(head) >= 2 && head[0] == 0xff && head[1]&0xe0 == 0xe0 && head[1]&0x06 != 0:
+		// An MPEG audio frame sync with a layer set; ADTS AAC has none.
+		return FormatMP3, nil
+	case tagged:
+		// ID3 tags little but MP3, whose first frame may follow padding.
+		return FormatMP3, nil
+	}
+	return "", nil
+}
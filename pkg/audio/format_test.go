@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFile(t *testing.T) {
+	oggPage := func(packet string) string {
+		// Page header with a one-segment table.
+		return "OggS\x00\x02" + string(make([]byte, 20)) + "\x01\x13" + packet
+	}
+	tests := []struct {
+		name, content, want string
+	}{
+		{"flac", "fLaC\x00\x00\x00\x22", FormatFLAC},
+		{"flac with id3", "ID3\x04\x00\x00\x00\x00\x00\x02\x00\x00fLaC", FormatFLAC},
+		{"wav", "RIFF\x24\x00\x00\x00WAVEfmt ", FormatWAV},
+		{"opus", oggPage("OpusHead\x01\x01"), FormatOpus},
+		{"vorbis", oggPage("\x01vorbis\x00\x00"), FormatOgg},
+		{"ogg other", oggPage("\x7fFLAC\x01\x00"), ""},
+		{"m4a", "\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00", FormatM4A},
+		{"amr", "#!AMR\n\x3c", FormatAMR},
+		{"amr-wb", "#!AMR-WB\n\x04", FormatAMR},
+		{"mp3", "\xff\xfb\x90\x64", FormatMP3},
+		{"mp3 with id3", "ID3\x03\x00\x00\x00\x00\x00\x01\x00\x00\x00\xff\xfb", FormatMP3},
+		{"adts aac", "\xff\xf1\x50\x80", ""},
+		{"text", "hello", ""},
+		{"empty", "", ""},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "audio")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := DetectFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatOfExt(t *testing.T) {
+	for name, want := range map[string]string{
+		"a.flac": FormatFLAC, "call.OPUS": FormatOpus, "x/y.m4a": FormatM4A, "a.amr": FormatAMR, "a.txt": "", "flac": "",
+	} {
+		if got := FormatOfExt(name); got != want {
+			t.Errorf("FormatOfExt(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package audio reads and decodes audio files without external tools,
+// falling back to ffmpeg for formats it cannot decode.
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrUnsupported is returned for files that are neither FLAC nor WAV.
+var ErrUnsupported = errors.New("unsupported audio format")
+
+// Info describes an audio stream as declared by its file header.
+type Info struct {
+	Format        string `json:"format"` // "flac" or "wav"
+	DurationMS    int64  `json:"duration_ms"`
+	SampleRate    int    `json:"sample_rate"`
+	Channels      int    `json:"channels"`
+	BitsPerSample int    `json:"bits_per_sample"`
+	Samples       int64  `json:"samples"` // Per channel; 0 when the header does not declare it
+	Size          int64  `json:"size"`    // File size in bytes
+}
+
+// ReadInfo parses the header of the FLAC or WAV file at path.
+func ReadInfo(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := readInfo(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	info.Size = st.Size()
+	return info, nil
+}
+
+func readInfo(r *bufio.Reader) (*Info, error) {
+	if err := skipID3(r); err != nil {
+		return nil, err
+	}
+	magic, err := r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	var info *Info
+	switch string(magic) {
+	case "fLaC":
+		info, err = readFLACInfo(r)
+	case "RIFF":
+		info, err = readWAVInfo(r)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.SampleRate > 0 {
+		info.DurationMS = info.Samples * 1000 / int64(info.SampleRate)
+	}
+	return info, nil
+}
+
+// skipID3 skips an ID3v2 tag, which some encoders prepend to FLAC files.
+func skipID3(r *bufio.Reader) error {
+	hdr, err := r.Peek(10)
+	if err != nil || !bytes.HasPrefix(hdr, []byte("ID3")) {
+		return nil
+	}
+	// The size is a 28-bit syncsafe integer excluding the 10-byte header.
+	size := int(hdr[6])<<21 | int(hdr[7])<<14 | int(hdr[8])<<7 | int(hdr[9])
+	_, err = r.Discard(10 + size)
+	return err
+}
+
+// readFLACInfo decodes the STREAMINFO block, which must come first.
+func readFLACInfo(r io.Reader) (*Info, error) {
+	var hdr [8]byte // "fLaC" + metadata block header
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	if blockType := hdr[4] & 0x7f; blockType != 0 {
+		return nil, fmt.Errorf("flac: first metadata block has type %d, want STREAMINFO", blockType)
+	}
+	var si [34]byte
+	if _, err := io.ReadFull(r, si[:]); err != nil {
+		return nil, err
+	}
+	// Bytes 10-17 pack sample rate (20 bits), channels-1 (3), bits-1 (5)
+	// and total samples (36).
+	v := binary.BigEndian.Uint64(si[10:18])
+	return &Info{
+		Format:        "flac",
+		SampleRate:    int(v >> 44),
+		Channels:      int(v>>41&0x7) + 1,
+		BitsPerSample: int(v>>36&0x1f) + 1,
+		Samples:       int64(v & (1<<36 - 1)),
+	}, nil
+}
+
+// readWAVInfo walks the RIFF chunks up to "data".
+func readWAVInfo(r io.Reader) (*Info, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, err
+	}
+	if string(riff[8:12]) != "WAVE" {
+		return nil, ErrUnsupported
+	}
+
+	info := &Info{Format: "wav"}
+	blockAlign := 0
+	for {
+		var ch [8]byte
+		if _, err := io.ReadFull(r, ch[:]); err != nil {
+			return nil, fmt.Errorf("wav: no data chunk: %w", err)
+		}
+		size := int64(binary.LittleEndian.Uint32(ch[4:]))
+		switch string(ch[:4]) {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("wav: fmt chunk too short")
+			}
+			var fmtc [16]byte
+			if _, err := io.ReadFull(r, fmtc[:]); err != nil {
+				return nil, err
+			}
+			info.Channels = int(binary.LittleEndian.Uint16(fmtc[2:]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(fmtc[4:]))
+			blockAlign = int(binary.LittleEndian.Uint16(fmtc[12:]))
+			info.BitsPerSample = int(binary.LittleEndian.Uint16(fmtc[14:]))
+			size -= 16
+		case "data":
+			if blockAlign == 0 {
+				return nil, fmt.Errorf("wav: data chunk before fmt chunk")
+			}
+			// Streaming writers leave the size at 0 or 0xffffffff.
+			if size != 0 && size != 0xffffffff {
+				info.Samples = size / int64(blockAlign)
+			}
+			return info, nil
+		}
+		// Chunks are padded to an even size.
+		if _, err := io.CopyN(io.Discard, r, size+size&1); err != nil {
+			return nil, err
+		}
+	}
+}
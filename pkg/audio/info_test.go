@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadInfo(t *testing.T) {
+	// STREAMINFO for 16 kHz mono 16-bit, 48000 samples.
+	flac := []byte("fLaC")
+	flac = append(flac, 0x80, 0, 0, 34)
+	si := make([]byte, 34)
+	binary.BigEndian.PutUint64(si[10:], uint64(16000)<<44|uint64(0)<<41|uint64(15)<<36|48000)
+	flac = append(flac, si...)
+
+	id3 := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x05"), make([]byte, 5)...)
+
+	var wav bytes.Buffer
+	wav.WriteString("RIFF\x00\x00\x00\x00WAVE")
+	wav.WriteString("LIST")
+	binary.Write(&wav, binary.LittleEndian, uint32(3))
+	wav.Write([]byte{1, 2, 3, 0}) // odd chunk with padding
+	wav.WriteString("fmt ")
+	binary.Write(&wav, binary.LittleEndian, struct {
+		Size                      uint32
+		Format, Channels          uint16
+		SampleRate, ByteRate      uint32
+		BlockAlign, BitsPerSample uint16
+	}{16, 1, 2, 8000, 32000, 4, 16})
+	wav.WriteString("data")
+	binary.Write(&wav, binary.LittleEndian, uint32(16000))
+
+	tests := []struct {
+		name string
+		in   []byte
+		want *Info
+	}{
+		{"flac", flac, &Info{Format: "flac", DurationMS: 3000, SampleRate: 16000, Channels: 1, BitsPerSample: 16, Samples: 48000}},
+		{"flac with id3", append(id3, flac...), &Info{Format: "flac", DurationMS: 3000, SampleRate: 16000, Channels: 1, BitsPerSample: 16, Samples: 48000}},
+		{"wav", wav.Bytes(), &Info{Format: "wav", DurationMS: 500, SampleRate: 8000, Channels: 2, BitsPerSample: 16, Samples: 4000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readInfo(bufio.NewReader(bytes.NewReader(tt.in)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("readInfo() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := readInfo(bufio.NewReader(bytes.NewReader([]byte("OggS....")))); err != ErrUnsupported {
+		t.Errorf("readInfo(ogg) error = %v, want ErrUnsupported", err)
+	}
+}
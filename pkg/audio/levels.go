@@ -0,0 +1,68 @@
+package audio
+
+import "math"
+
+// Recordings fail Levels' checks when more than MaxClippedRatio of their
+// samples are clipped, or when less than MinLoudMS of them is louder than
+// the silence threshold of TrimSilence.
+const (
+	MaxClippedRatio = 0.001
+	MinLoudMS       = 500
+
+	clipLevel = 32600 // About -0.04 dBFS; limiters rarely leave exactly full scale
+	loudDB    = -40
+)
+
+// Levels measures what makes a recording unfit for comparing providers on.
+type Levels struct {
+	ClippedRatio float64 `json:"clipped_ratio"` // Share of samples at or near full scale
+	LoudMS       int64   `json:"loud_ms"`       // Audio louder than -40 dBFS, in whole frames
+}
+
+// Levels measures p, its channels mixed for loudness and taken apart for
+// clipping.
+func (p *PCM) Levels() Levels {
+	var l Levels
+	if len(p.Samples) == 0 {
+		return l
+	}
+	clipped := 0
+	for _, s := range p.Samples {
+		if s >= clipLevel || s <= -clipLevel {
+			clipped++
+		}
+	}
+	l.ClippedRatio = float64(clipped) / float64(len(p.Samples))
+
+	frameLen := p.SampleRate * silenceFrameMS / 1000
+	if frameLen == 0 || p.Channels == 0 {
+		return l
+	}
+	mono := p.Mono()
+	for i := 0; i+frameLen <= len(mono.Samples); i += frameLen {
+		if frameDB(mono.Samples[i:i+frameLen]) >= loudDB {
+			l.LoudMS += silenceFrameMS
+		}
+	}
+	return l
+}
+
+// Clipped reports whether too many samples are clipped to trust a
+// transcript of the recording.
+func (l Levels) Clipped() bool {
+	return l.ClippedRatio > MaxClippedRatio
+}
+
+// Silent reports whether the recording is silent or nearly so.
+func (l Levels) Silent() bool {
+	return l.LoudMS < MinLoudMS
+}
+
+// frameDB returns the RMS level of frame in dBFS.
+func frameDB(frame []int16) float64 {
+	var sum float64
+	for _, s := range frame {
+		sum += float64(s) * float64(s)
+	}
+	return 20 * math.Log10(math.Sqrt(sum/float64(len(frame)))/math.MaxInt16)
+}
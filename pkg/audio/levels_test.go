@@ -0,0 +1,40 @@
+package audio
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLevels(t *testing.T) {
+	const rate = 8000
+	quiet := tone(rate, rate, 440, 50)    // 1 s around -56 dBFS
+	loud := tone(rate, rate/2, 440, 8000) // 0.5 s around -15 dBFS
+	// A sine at twice full scale, clipped: flat two thirds of the time.
+	clipped := make([]int16, rate/10)
+	for i := range clipped {
+		v := 2 * math.MaxInt16 * math.Sin(2*math.Pi*440*float64(i)/rate)
+		clipped[i] = int16(max(min(v, math.MaxInt16), math.MinInt16))
+	}
+
+	tests := []struct {
+		name            string
+		p               *PCM
+		clipped, silent bool
+	}{
+		{"empty", &PCM{SampleRate: rate, Channels: 1}, false, true},
+		{"quiet", quiet, false, true},
+		{"speech", &PCM{SampleRate: rate, Channels: 1, Samples: slices.Concat(quiet.Samples, loud.Samples)}, false, false},
+		{"clipped", &PCM{SampleRate: rate, Channels: 1, Samples: slices.Concat(loud.Samples, clipped)}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := tt.p.Levels()
+			if diff := cmp.Diff([]bool{tt.clipped, tt.silent}, []bool{l.Clipped(), l.Silent()}); diff != "" {
+				t.Errorf("Levels() = %+v: clipped and silent mismatch (-want +got):\n%s", l, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package audio
+
+import "math"
+
+// resampleZeroCrossings is how many zero crossings of the sinc each side of
+// the filter spans; more gives a sharper cutoff at a higher cost.
+const resampleZeroCrossings = 16
+
+// Resample returns p converted to rate by band-limited interpolation with a
+// Blackman-windowed sinc filter, or p itself if it is at rate already. When
+// downsampling, the filter cuts off at the new Nyquist frequency so higher
+// frequencies do not alias.
+func (p *PCM) Resample(rate int) *PCM {
+	if rate == p.SampleRate || p.Channels == 0 || p.SampleRate == 0 {
+		return p
+	}
+	g := gcd(p.SampleRate, rate)
+	up, down := rate/g, p.SampleRate/g
+	bank := newFilterBank(up, down)
+
+	in := p.Frames()
+	out := int((int64(in)*int64(up) + int64(down) - 1) / int64(down))
+	res := &PCM{SampleRate: rate, Channels: p.Channels, Samples: make([]int16, out*p.Channels)}
+	for j := range out {
+		// Output sample j lies at input position pos + phase/up.
+		pos, phase := int(int64(j)*int64(down)/int64(up)), int(int64(j)*int64(down)%int64(up))
+		taps := bank.taps[phase]
+		first := pos - bank.half + 1
+		for c := range p.Channels {
+			var sum float64
+			for k, h := range taps {
+				if i := first + k; i >= 0 && i < in {
+					sum += h * float64(p.Samples[i*p.Channels+c])
+				}
+			}
+			res.Samples[j*p.Channels+c] = int16(math.Round(max(math.MinInt16, min(math.MaxInt16, sum))))
+		}
+	}
+	return res
+}
+
+// filterBank holds the filter taps for each of the up phases an output
+// sample can fall at between two input samples.
+type filterBank struct {
+	half int // Taps each side of the output position
+	taps [][]float64
+}
+
+func newFilterBank(up, down int) *filterBank {
+	// Cutoff relative to the input Nyquist frequency.
+	fc := min(1, float64(up)/float64(down))
+	half := int(math.Ceil(resampleZeroCrossings / fc))
+	b := &filterBank{half: half, taps: make([][]float64, up)}
+	for phase := range up {
+		frac := float64(phase) / float64(up)
+		taps := make([]float64, 2*half)
+		var sum float64
+		for k := range taps {
+			// Distance from the output position to input sample
+			// pos-half+1+k.
+			x := float64(k-half+1) - frac
+			taps[k] = fc * sinc(fc*x) * blackman(x/float64(half))
+			sum += taps[k]
+		}
+		// Normalize so a constant signal keeps its level.
+		for k := range taps {
+			taps[k] /= sum
+		}
+		b.taps[phase] = taps
+	}
+	return b
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is the Blackman window over u in [-1, 1].
+func blackman(u float64) float64 {
+	if u <= -1 || u >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*u) + 0.08*math.Cos(2*math.Pi*u)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Resampler resamples mono audio as it arrives, giving the same samples as
+// Resample does for the whole of it.
+type Resampler struct {
+	up, down int
+	bank     *filterBank // Nil when the rates are the same
+	buf      []int16     // Input still needed, from input sample offset on
+	offset   int64
+	total    int64 // Input samples written
+	next     int64 // Index of the next output sample
+}
+
+// NewResampler returns a Resampler from rate from to rate to.
+func NewResampler(from, to int) *Resampler {
+	if from == to {
+		return &Resampler{}
+	}
+	g := gcd(from, to)
+	r := &Resampler{up: to / g, down: from / g}
+	r.bank = newFilterBank(r.up, r.down)
+	return r
+}
+
+// Write adds in and returns the output samples it completes.
+func (r *Resampler) Write(in []int16) []int16 {
+	if r.bank == nil {
+		return in
+	}
+	r.buf = append(r.buf, in...)
+	r.total += int64(len(in))
+	return r.output(false)
+}
+
+// Flush returns the rest of the output, the input having ended.
+func (r *Resampler) Flush() []int16 {
+	if r.bank == nil {
+		return nil
+	}
+	return r.output(true)
+}
+
+func (r *Resampler) output(flush bool) []int16 {
+	up, down, half := int64(r.up), int64(r.down), int64(r.bank.half)
+	end := (r.total*up + down - 1) / down
+	var out []int16
+	for ; r.next < end; r.next++ {
+		pos, phase := r.next*down/up, r.next*down%up
+		if !flush && pos+half >= r.total {
+			break
+		}
+		first := pos - half + 1
+		var sum float64
+		for k, h := range r.bank.taps[phase] {
+			if i := first + int64(k); i >= 0 && i < r.total {
+				sum += h * float64(r.buf[i-r.offset])
+			}
+		}
+		out = append(out, int16(math.Round(max(math.MinInt16, min(math.MaxInt16, sum)))))
+	}
+	// Drop the input before the next output sample's first tap.
+	if drop := min(r.next*down/up-half+1, r.total) - r.offset; drop > 0 {
+		r.buf = r.buf[drop:]
+		r.offset += drop
+	}
+	return out
+}
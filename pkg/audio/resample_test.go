@@ -0,0 +1,89 @@
+package audio
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func tone(rate, frames int, freq, amp float64) *PCM {
+	p := &PCM{SampleRate: rate, Channels: 1, Samples: make([]int16, frames)}
+	for i := range p.Samples {
+		p.Samples[i] = int16(math.Round(amp * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))))
+	}
+	return p
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int
+		freq     float64
+		want     float64 // Amplitude expected after resampling
+	}{
+		{"48k to 16k", 48000, 16000, 1000, 10000},
+		{"44.1k to 16k", 44100, 16000, 1000, 10000},
+		{"44.1k to 16k near Nyquist", 44100, 16000, 6000, 10000},
+		{"8k to 16k", 8000, 16000, 440, 10000},
+		{"48k to 16k above Nyquist", 48000, 16000, 10000, 0},
+		{"44.1k to 16k above Nyquist", 44100, 16000, 12000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tone(tt.from, tt.from/2, tt.freq, 10000)
+			got := in.Resample(tt.to)
+			if got.SampleRate != tt.to || got.Channels != 1 {
+				t.Fatalf("Resample() = %d Hz, %d channels, want %d Hz mono", got.SampleRate, got.Channels, tt.to)
+			}
+			if len(got.Samples) != tt.to/2 {
+				t.Errorf("Resample() has %d samples, want %d", len(got.Samples), tt.to/2)
+			}
+			// Compare with the tone sampled at the new rate directly, away
+			// from the edges where the filter runs off the signal.
+			want := tone(tt.to, tt.to/2, tt.freq, tt.want)
+			var maxErr float64
+			for i := tt.to / 20; i < len(got.Samples)-tt.to/20; i++ {
+				maxErr = max(maxErr, math.Abs(float64(got.Samples[i])-float64(want.Samples[i])))
+			}
+			// 0.5% of full amplitude.
+			if maxErr > 50 {
+				t.Errorf("Resample() deviates from the expected signal by up to %g", maxErr)
+			}
+		})
+	}
+}
+
+func TestResampleStereo(t *testing.T) {
+	in := &PCM{SampleRate: 48000, Channels: 2, Samples: make([]int16, 2*4800)}
+	for i := range 4800 {
+		in.Samples[2*i], in.Samples[2*i+1] = 1000, -3000
+	}
+	got := in.Resample(16000)
+	if len(got.Samples) != 2*1600 {
+		t.Fatalf("Resample() has %d samples, want %d", len(got.Samples), 2*1600)
+	}
+	for i := 100; i < 1500; i++ {
+		if l, r := got.Samples[2*i], got.Samples[2*i+1]; l != 1000 || r != -3000 {
+			t.Fatalf("Resample() frame %d = %d, %d, want 1000, -3000", i, l, r)
+		}
+	}
+	if same := got.Resample(16000); same != got {
+		t.Error("Resample() to the same rate returned a copy")
+	}
+}
+
+func TestResampler(t *testing.T) {
+	for _, rates := range [][2]int{{8000, 16000}, {44100, 16000}, {16000, 16000}} {
+		in := tone(rates[0], rates[0]/3, 440, 10000)
+		want := in.Resample(rates[1]).Samples
+		r := NewResampler(rates[0], rates[1])
+		var got []int16
+		for i, n := 0, 1; i < len(in.Samples); i, n = i+n, n*3%997 {
+			got = append(got, r.Write(in.Samples[i:min(i+n, len(in.Samples))])...)
+		}
+		got = append(got, r.Flush()...)
+		if !slices.Equal(got, want) {
+			t.Errorf("Resampler from %d to %d Hz gave %d samples, not the %d of Resample()", rates[0], rates[1], len(got), len(want))
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package audio
+
+// silenceFrameMS is the length of the frames TrimSilence judges as silent or
+// not. Cuts fall on frame boundaries, so their times are exact.
+const silenceFrameMS = 20
+
+// TrimOptions control TrimSilence.
+type TrimOptions struct {
+	ThresholdDB float64 // Frames quieter than this, in dBFS, are silent; 0 means -40
+	PadMS       int64   // Silence kept next to speech; 0 means 200
+	MaxGapMS    int64   // Silences within the audio longer than this are cut to 2*PadMS; 0 keeps them
+}
+
+// Cut is a stretch of the original audio TrimSilence removed.
+type Cut struct {
+	StartMS int64 `json:"start_ms"`
+	EndMS   int64 `json:"end_ms"`
+}
+
+// TrimSilence returns p without its leading and trailing silence, and with
+// long silences within it shortened if opts.MaxGapMS is set, along with
+// the cuts made, in order. Audio that is silent throughout is returned as
+// is.
+func (p *PCM) TrimSilence(opts TrimOptions) (*PCM, []Cut) {
+	threshold := opts.ThresholdDB
+	if threshold == 0 {
+		threshold = -40
+	}
+	pad := opts.PadMS
+	if pad == 0 {
+		pad = 200
+	}
+	padFrames := int(pad / silenceFrameMS)
+
+	frameLen := p.SampleRate * silenceFrameMS / 1000
+	if frameLen == 0 || p.Channels == 0 {
+		return p, nil
+	}
+	mono := p.Mono()
+	n := (len(mono.Samples) + frameLen - 1) / frameLen
+	silent := make([]bool, n)
+	first, last := -1, -1
+	for i := range n {
+		silent[i] = frameDB(mono.Samples[i*frameLen:min((i+1)*frameLen, len(mono.Samples))]) < threshold
+		if !silent[i] {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return p, nil
+	}
+
+	// Cuts in frames, [start, end).
+	var cuts [][2]int
+	if start := first - padFrames; start > 0 {
+		cuts = append(cuts, [2]int{0, start})
+	}
+	if opts.MaxGapMS > 0 {
+		for i := first; i <= last; {
+			if !silent[i] {
+				i++
+				continue
+			}
+			j := i
+			for silent[j] {
+				j++
+			}
+			if int64(j-i)*silenceFrameMS > opts.MaxGapMS && j-i > 2*padFrames {
+				cuts = append(cuts, [2]int{i + padFrames, j - padFrames})
+			}
+			i = j
+		}
+	}
+	if end := last + 1 + padFrames; end < n {
+		cuts = append(cuts, [2]int{end, n})
+	}
+	if len(cuts) == 0 {
+		return p, nil
+	}
+
+	trimmed := &PCM{SampleRate: p.SampleRate, Channels: p.Channels}
+	out := make([]Cut, len(cuts))
+	kept := 0
+	for i, c := range cuts {
+		trimmed.Samples = append(trimmed.Samples, p.Samples[kept*frameLen*p.Channels:c[0]*frameLen*p.Channels]...)
+		kept = c[1]
+		out[i] = Cut{StartMS: int64(c[0]) * silenceFrameMS, EndMS: int64(c[1]) * silenceFrameMS}
+	}
+	trimmed.Samples = append(trimmed.Samples, p.Samples[min(kept*frameLen*p.Channels, len(p.Samples)):]...)
+	// The last frame may be short.
+	if tail := &out[len(out)-1]; cuts[len(cuts)-1][1] == n {
+		tail.EndMS = int64(p.Frames()) * 1000 / int64(p.SampleRate)
+	}
+	return trimmed, out
+}
+
+// OriginalMS maps a time in audio trimmed by cuts back to the original
+// audio.
+func OriginalMS(cuts []Cut, ms int64) int64 {
+	for _, c := range cuts {
+		if ms < c.StartMS {
+			break
+		}
+		ms += c.EndMS - c.StartMS
+	}
+	return ms
+}
@@ -0,0 +1,71 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTrimSilence(t *testing.T) {
+	// 16 kHz: 1 s silence, 1 s tone, 2 s silence, 0.5 s tone, 1.01 s silence.
+	const rate = 16000
+	var samples []int16
+	for _, seg := range []struct {
+		ms   int
+		loud bool
+	}{{1000, false}, {1000, true}, {2000, false}, {500, true}, {1010, false}} {
+		n := seg.ms * rate / 1000
+		if seg.loud {
+			samples = append(samples, tone(rate, n, 440, 8000).Samples...)
+			continue
+		}
+		for i := range n {
+			samples = append(samples, int16(i%3-1)) // Well below -40 dBFS
+		}
+	}
+	p := &PCM{SampleRate: rate, Channels: 1, Samples: samples}
+
+	tests := []struct {
+		name     string
+		opts     TrimOptions
+		wantCuts []Cut
+		wantMS   int64
+		mapped   [][2]int64 // Trimmed time and original time
+	}{
+		{
+			name:     "edges",
+			opts:     TrimOptions{},
+			wantCuts: []Cut{{0, 800}, {4700, 5510}},
+			wantMS:   3900,
+			mapped:   [][2]int64{{0, 800}, {1500, 2300}, {3899, 4699}},
+		},
+		{
+			name:     "gaps",
+			opts:     TrimOptions{PadMS: 100, MaxGapMS: 500},
+			wantCuts: []Cut{{0, 900}, {2100, 3900}, {4600, 5510}},
+			wantMS:   1900,
+			mapped:   [][2]int64{{0, 900}, {1199, 2099}, {1200, 3900}, {1899, 4599}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cuts := p.TrimSilence(tt.opts)
+			if diff := cmp.Diff(tt.wantCuts, cuts); diff != "" {
+				t.Errorf("cuts mismatch (-want +got):\n%s", diff)
+			}
+			if ms := int64(got.Frames()) * 1000 / rate; ms != tt.wantMS {
+				t.Errorf("trimmed to %d ms, want %d", ms, tt.wantMS)
+			}
+			for _, m := range tt.mapped {
+				if orig := OriginalMS(cuts, m[0]); orig != m[1] {
+					t.Errorf("OriginalMS(%d) = %d, want %d", m[0], orig, m[1])
+				}
+			}
+		})
+	}
+
+	quiet := &PCM{SampleRate: rate, Channels: 1, Samples: make([]int16, rate)}
+	if got, cuts := quiet.TrimSilence(TrimOptions{}); got != quiet || cuts != nil {
+		t.Errorf("TrimSilence(silence) = %d samples, cuts %v; want it as is", len(got.Samples), cuts)
+	}
+}
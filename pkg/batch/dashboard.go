@@ -0,0 +1,281 @@
+package batch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Dashboard sizes.
+const (
+	dashboardRecent = 8  // Completions listed
+	dashboardLog    = 5  // Log lines kept
+	dashboardFlight = 12 // In-flight items listed
+)
+
+// Dashboard shows the live state of a batch run in a terminal: the items
+// in flight and for how long, so stalls stand out, throughput and errors
+// by group (a provider, model or stage), and the latest completions. Its
+// methods are safe for concurrent use.
+type Dashboard struct {
+	Title string
+	Total int // Items in the run; 0 when unknown
+
+	mu       sync.Mutex
+	start    time.Time
+	inFlight map[string]flight
+	groups   map[string]*groupStats
+	classes  map[string]int // Failures by error class
+	recent   []completion   // Newest last
+	logLines []string       // Newest last
+}
+
+type flight struct {
+	group string
+	start time.Time
+}
+
+type groupStats struct {
+	done, failed, inFlight int
+	busy                   time.Duration // Summed duration of finished items
+}
+
+type completion struct {
+	group, item string
+	took        time.Duration
+	err         error
+}
+
+// NewDashboard returns a dashboard for a run of total items.
+func NewDashboard(title string, total int) *Dashboard {
+	return &Dashboard{
+		Title:    title,
+		Total:    total,
+		start:    time.Now(),
+		inFlight: make(map[string]flight),
+		groups:   make(map[string]*groupStats),
+		classes:  make(map[string]int),
+	}
+}
+
+// Start marks item of group as in flight.
+func (d *Dashboard) Start(group, item string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inFlight[group+"\x00"+item] = flight{group, time.Now()}
+	d.group(group).inFlight++
+}
+
+// Finish marks item of group as done, or failed with err. An item that
+// was not started counts with no duration.
+func (d *Dashboard) Finish(group, item string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g := d.group(group)
+	c := completion{group: group, item: item, err: err}
+	if f, ok := d.inFlight[group+"\x00"+item]; ok {
+		delete(d.inFlight, group+"\x00"+item)
+		g.inFlight--
+		c.took = time.Since(f.start)
+		g.busy += c.took
+	}
+	if err != nil {
+		g.failed++
+		d.classes[Classify(err)]++
+	} else {
+		g.done++
+	}
+	d.recent = append(d.recent, c)
+	if len(d.recent) > dashboardRecent {
+		d.recent = d.recent[1:]
+	}
+}
+
+func (d *Dashboard) group(name string) *groupStats {
+	g, ok := d.groups[name]
+	if !ok {
+		g = &groupStats{}
+		d.groups[name] = g
+	}
+	return g
+}
+
+// Write adds the lines of p to the dashboard's log pane.
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		d.logLines = append(d.logLines, line)
+	}
+	if n := len(d.logLines) - dashboardLog; n > 0 {
+		d.logLines = d.logLines[n:]
+	}
+	return len(p), nil
+}
+
+// Show redraws the dashboard on w every interval until the returned stop
+// is called, which draws it a last time and leaves it on screen.
+// Meanwhile os.Stdout and the log package write to the log pane instead
+// of the terminal.
+func (d *Dashboard) Show(w io.Writer, interval time.Duration) (stop func()) {
+	stdout, logOut := os.Stdout, log.Writer()
+	restore := func() {}
+	if r, pw, err := os.Pipe(); err == nil {
+		os.Stdout = pw
+		copied := make(chan struct{})
+		go func() {
+			defer close(copied)
+			sc := bufio.NewScanner(r)
+			for sc.Scan() {
+				d.Write(append(sc.Bytes(), '\n'))
+			}
+		}()
+		restore = func() {
+			os.Stdout = stdout
+			pw.Close()
+			<-copied
+			r.Close()
+		}
+	}
+	log.SetOutput(d)
+
+	draw := func() {
+		fmt.Fprint(w, "\x1b[H\x1b[2J")
+		d.Render(w, time.Now())
+	}
+	quit := make(chan struct{})
+	drawn := make(chan struct{})
+	go func() {
+		defer close(drawn)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				draw()
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-drawn
+		log.SetOutput(logOut)
+		restore()
+		draw()
+	}
+}
+
+// Render writes the dashboard as of now to w.
+func (d *Dashboard) Render(w io.Writer, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	elapsed := now.Sub(d.start)
+	var done, failed int
+	for _, g := range d.groups {
+		done += g.done
+		failed += g.failed
+	}
+	fmt.Fprintf(w, "%s  %s elapsed  %d done  %d failed  %d in flight", d.Title, elapsed.Round(time.Second), done, failed, len(d.inFlight))
+	if d.Total > 0 {
+		fmt.Fprintf(w, "  of %d", d.Total)
+		if finished := done + failed; finished > 0 && finished < d.Total {
+			eta := time.Duration(float64(elapsed) / float64(finished) * float64(d.Total-finished))
+			fmt.Fprintf(w, "  ETA %s", eta.Round(time.Second))
+		}
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Group\tDone\tFailed\tIn flight\tPer min\tAvg")
+	names := make([]string, 0, len(d.groups))
+	for name := range d.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g := d.groups[name]
+		avg := "-"
+		if n := g.done + g.failed; n > 0 && g.busy > 0 {
+			avg = (g.busy / time.Duration(n)).Round(100 * time.Millisecond).String()
+		}
+		rate := 0.0
+		if elapsed > 0 {
+			rate = float64(g.done+g.failed) / elapsed.Minutes()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f\t%s\n", name, g.done, g.failed, g.inFlight, rate, avg)
+	}
+	tw.Flush()
+
+	if len(d.classes) > 0 {
+		classes := make([]string, 0, len(d.classes))
+		for c, n := range d.classes {
+			classes = append(classes, fmt.Sprintf("%s %d", c, n))
+		}
+		sort.Strings(classes)
+		fmt.Fprintf(w, "\nErrors: %s\n", strings.Join(classes, ", "))
+	}
+
+	if len(d.inFlight) > 0 {
+		type row struct {
+			key string
+			f   flight
+		}
+		rows := make([]row, 0, len(d.inFlight))
+		for k, f := range d.inFlight {
+			rows = append(rows, row{k, f})
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if !rows[i].f.start.Equal(rows[j].f.start) {
+				return rows[i].f.start.Before(rows[j].f.start)
+			}
+			return rows[i].key < rows[j].key
+		})
+		fmt.Fprintln(w, "\nIn flight, oldest first")
+		for i, r := range rows {
+			if i == dashboardFlight {
+				fmt.Fprintf(w, "  (%d more)\n", len(rows)-i)
+				break
+			}
+			_, item, _ := strings.Cut(r.key, "\x00")
+			fmt.Fprintf(w, "  %s  %s  %s\n", r.f.group, item, now.Sub(r.f.start).Round(time.Second))
+		}
+	}
+
+	if len(d.recent) > 0 {
+		fmt.Fprintln(w, "\nRecent")
+		for i := len(d.recent) - 1; i >= 0; i-- {
+			c := d.recent[i]
+			if c.err != nil {
+				fmt.Fprintf(w, "  FAIL  %s  %s  %s: %s\n", c.group, c.item, Classify(c.err), firstLine(c.err.Error(), 100))
+			} else {
+				fmt.Fprintf(w, "  ok    %s  %s  %s\n", c.group, c.item, c.took.Round(100*time.Millisecond))
+			}
+		}
+	}
+
+	if len(d.logLines) > 0 {
+		fmt.Fprintln(w, "\nLog")
+		for _, l := range d.logLines {
+			fmt.Fprintf(w, "  %s\n", l)
+		}
+	}
+}
+
+// firstLine returns the first line of s, cut to n runes.
+func firstLine(s string, n int) string {
+	s, _, _ = strings.Cut(s, "\n")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "..."
+	}
+	return s
+}
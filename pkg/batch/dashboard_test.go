@@ -0,0 +1,35 @@
+package batch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDashboard(t *testing.T) {
+	d := NewDashboard("transcribe-all", 4)
+	d.Start("volc", "a")
+	d.Start("volc", "b")
+	d.Start("qwen", "a")
+	d.Finish("volc", "a", nil)
+	d.Finish("qwen", "a", errors.New("429 quota exceeded\nresponse body"))
+	d.Write([]byte("retrying\n"))
+
+	var b strings.Builder
+	d.Render(&b, d.start.Add(time.Minute))
+	out := b.String()
+	for _, want := range []string{
+		"transcribe-all  1m0s elapsed  1 done  1 failed  1 in flight  of 4  ETA 1m0s",
+		"qwen   0     1       0          1.0",
+		"volc   1     0       1          1.0",
+		"Errors: unavailable 1",
+		"In flight, oldest first\n  volc  b  1m0s",
+		"FAIL  qwen  a  unavailable: 429 quota exceeded\n",
+		"Log\n  retrying\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dashboard lacks %q:\n%s", want, out)
+		}
+	}
+}
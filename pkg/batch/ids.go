@@ -0,0 +1,33 @@
+package batch
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadIDs reads a list of case IDs, one a line, from path, or stdin when
+// path is "-". Blank lines and lines starting with # are skipped, so the
+// output of asr-eval sample can be edited by hand.
+func ReadIDs(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var ids []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	return ids, sc.Err()
+}
@@ -0,0 +1,265 @@
+// Package batch records the progress of batch runs in a manifest, so an
+// interrupted run can be resumed where it stopped.
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Item states.
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// entry is one line of a manifest. The first line names the tool and its
+// arguments; each later line sets an item's status, the last one winning.
+type entry struct {
+	Tool   string    `json:"tool,omitempty"`
+	Args   []string  `json:"args,omitempty"`
+	Item   string    `json:"item,omitempty"`
+	Status string    `json:"status,omitempty"`
+	Error  string    `json:"error,omitempty"`
+	Class  string    `json:"class,omitempty"` // Error class of a failure, see Classify
+	Time   time.Time `json:"time"`
+}
+
+// Failure is a failed item of a manifest.
+type Failure struct {
+	Item     string
+	Class    string
+	Error    string
+	Attempts int // Failures recorded for the item
+}
+
+// Manifest is the append-only JSON lines file of a batch run, recording
+// each item (a file, case, or provider and case) as pending, done or
+// failed, with the class of the error. Its methods are safe for
+// concurrent use.
+type Manifest struct {
+	Tool string
+	Args []string
+
+	mu       sync.Mutex
+	f        *os.File
+	status   map[string]string
+	failures map[string]*Failure // Failed items, and those that failed before
+}
+
+// DefaultPath names a new manifest of tool in the working directory.
+func DefaultPath(tool string) string {
+	return fmt.Sprintf("%s-%s.manifest.jsonl", tool, time.Now().Format("20060102-150405"))
+}
+
+// Create starts the manifest at path with items pending. It fails if path
+// exists, so a run never overwrites another's progress.
+func Create(path, tool string, args []string, items []string) (*Manifest, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{Tool: tool, Args: args, f: f, status: make(map[string]string), failures: make(map[string]*Failure)}
+	w := bufio.NewWriter(f)
+	lines := []entry{{Tool: tool, Args: args, Time: time.Now().UTC()}}
+	for _, it := range items {
+		m.status[it] = StatusPending
+		lines = append(lines, entry{Item: it, Status: StatusPending, Time: lines[0].Time})
+	}
+	for _, e := range lines {
+		if err := writeEntry(w, e); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Open reopens the manifest at path to resume its run; later updates are
+// appended to it.
+func Open(path string) (*Manifest, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{f: f, status: make(map[string]string), failures: make(map[string]*Failure)}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var e entry
+		if json.Unmarshal(sc.Bytes(), &e) != nil {
+			continue // A line cut short by a crash
+		}
+		if e.Tool != "" {
+			m.Tool, m.Args = e.Tool, e.Args
+			continue
+		}
+		if e.Item == "" {
+			continue
+		}
+		m.apply(e)
+	}
+	if err := sc.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if m.Tool == "" {
+		f.Close()
+		return nil, fmt.Errorf("%s: not a batch manifest", path)
+	}
+	// End a line cut short by a crash, so the next entry starts its own.
+	last := make([]byte, 1)
+	if fi, err := f.Stat(); err == nil && fi.Size() > 0 {
+		if _, err := f.ReadAt(last, fi.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// Resume opens the manifest at path, which must be tool's, and parses its
+// arguments into fs followed by args, so the resumed run has the flags of
+// the original unless args override them.
+func Resume(fs *flag.FlagSet, path, tool string, args []string) (*Manifest, error) {
+	m, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if m.Tool != tool {
+		m.Close()
+		return nil, fmt.Errorf("%s is a manifest of %s, not %s", path, m.Tool, tool)
+	}
+	if err := fs.Parse(m.Args); err != nil {
+		m.Close()
+		return nil, err
+	}
+	if err := fs.Parse(args); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// Path returns the file name of m.
+func (m *Manifest) Path() string {
+	return m.f.Name()
+}
+
+// Items returns the items in status, sorted.
+func (m *Manifest) Items(status string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for it, st := range m.status {
+		if st == status {
+			out = append(out, it)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Failure returns how item last failed, and how often it has, or the
+// zero Failure when it never did.
+func (m *Manifest) Failure(item string) Failure {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f := m.failures[item]; f != nil {
+		return *f
+	}
+	return Failure{}
+}
+
+// Failures returns the items that are failed, sorted.
+func (m *Manifest) Failures() []Failure {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Failure
+	for item, st := range m.status {
+		if st == StatusFailed {
+			out = append(out, *m.failures[item])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Item < out[j].Item })
+	return out
+}
+
+// Done records item as done.
+func (m *Manifest) Done(item string) error {
+	return m.set(item, StatusDone, nil)
+}
+
+// Fail records item as failed with err.
+func (m *Manifest) Fail(item string, err error) error {
+	return m.set(item, StatusFailed, err)
+}
+
+// Finish records item as done when err is nil and failed otherwise.
+func (m *Manifest) Finish(item string, err error) error {
+	if err != nil {
+		return m.Fail(item, err)
+	}
+	return m.Done(item)
+}
+
+func (m *Manifest) set(item, status string, err error) error {
+	e := entry{Item: item, Status: status, Time: time.Now().UTC()}
+	if err != nil {
+		e.Error, e.Class = err.Error(), Classify(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.apply(e)
+	return writeEntry(m.f, e)
+}
+
+// apply records e in memory. The caller holds mu, or owns m.
+func (m *Manifest) apply(e entry) {
+	m.status[e.Item] = e.Status
+	if e.Status != StatusFailed {
+		return
+	}
+	f := m.failures[e.Item]
+	if f == nil {
+		f = &Failure{Item: e.Item}
+		m.failures[e.Item] = f
+	}
+	f.Class, f.Error = e.Class, e.Error
+	if f.Class == "" {
+		f.Class = ClassOther
+	}
+	f.Attempts++
+}
+
+// Close closes the manifest file.
+func (m *Manifest) Close() error {
+	return m.f.Close()
+}
+
+func writeEntry(w io.Writer, e entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,71 @@
+package batch
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestManifestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.manifest.jsonl")
+	m, err := Create(path, "tool", []string{"-ext", ".x"}, []string{"c", "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Done("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Fail("b", errors.New("timeout")); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	if _, err := Create(path, "tool", nil, nil); err == nil {
+		t.Error("Create over an existing manifest succeeded, want error")
+	}
+
+	// A crash may leave a line cut short.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"item":"c","sta`)
+	f.Close()
+
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	ext := fs.String("ext", "", "")
+	concurrency := fs.Int("concurrency", 1, "")
+	fs.String("resume", "", "")
+	m, err = Resume(fs, path, "tool", []string{"-resume", path, "-concurrency", "4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if *ext != ".x" || *concurrency != 4 {
+		t.Errorf("flags = %q, %d; want .x, 4", *ext, *concurrency)
+	}
+	if diff := cmp.Diff([]string{"c"}, m.Items(StatusPending)); diff != "" {
+		t.Errorf("pending mismatch (-want +got):\n%s", diff)
+	}
+	if err := m.Done("c"); err != nil {
+		t.Fatal(err)
+	}
+	m2, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m2.Items(StatusPending); len(got) != 0 {
+		t.Errorf("pending after Done(c) = %v, want none", got)
+	}
+	m2.Close()
+	if got := m.Failure("b"); got.Error != "timeout" || got.Class != ClassTimeout || got.Attempts != 1 {
+		t.Errorf("Failure(b) = %+v, want a timeout", got)
+	}
+
+	if _, err := Resume(flag.NewFlagSet("other", flag.ContinueOnError), path, "other", nil); err == nil {
+		t.Error("Resume of another tool's manifest succeeded, want error")
+	}
+}
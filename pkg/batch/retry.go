@@ -0,0 +1,146 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"asr-eval/pkg/llmclient"
+)
+
+// Error classes of failed items, which select their retry policy.
+const (
+	ClassUnavailable = "unavailable" // Quota, rate limit or a temporarily unavailable service
+	ClassTimeout     = "timeout"
+	ClassNetwork     = "network"
+	ClassAuth        = "auth"  // Missing or rejected credentials
+	ClassInput       = "input" // The item itself is bad, e.g. no transcript came back
+	ClassOther       = "other"
+)
+
+// Classify returns the class of err, from its type where it has one and
+// its message otherwise.
+func Classify(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, llmclient.ErrUnavailable):
+		return ClassUnavailable
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ClassTimeout
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &netErr):
+		return ClassNetwork
+	}
+	msg := strings.ToLower(err.Error())
+	for _, c := range []struct {
+		class string
+		words []string
+	}{
+		{ClassUnavailable, []string{"429", "quota", "rate limit", "resource_exhausted", "resource exhausted", "503", "unavailable", "overloaded"}},
+		{ClassTimeout, []string{"timeout", "timed out", "deadline"}},
+		{ClassAuth, []string{"401", "403", "unauthorized", "forbidden", "permission", "api key", "must be set"}},
+		{ClassNetwork, []string{"connection", "eof", "broken pipe", "websocket", "no such host"}},
+		{ClassInput, []string{"empty transcript", "no transcript", "no context", "not found", "invalid"}},
+	} {
+		for _, w := range c.words {
+			if strings.Contains(msg, w) {
+				return c.class
+			}
+		}
+	}
+	return ClassOther
+}
+
+// Policy is how failed items of one class are retried: the Nth retry
+// waits Backoff * 2^(N-1), at most MaxBackoff, and an item is given up
+// after MaxAttempts failures. A zero MaxAttempts never retries.
+type Policy struct {
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	MaxAttempts int
+}
+
+// DefaultPolicies wait out quota and availability errors longest and do
+// not retry errors a retry cannot fix.
+var DefaultPolicies = map[string]Policy{
+	ClassUnavailable: {Backoff: 30 * time.Second, MaxBackoff: 10 * time.Minute, MaxAttempts: 6},
+	ClassTimeout:     {Backoff: 5 * time.Second, MaxBackoff: 2 * time.Minute, MaxAttempts: 4},
+	ClassNetwork:     {Backoff: 5 * time.Second, MaxBackoff: 2 * time.Minute, MaxAttempts: 4},
+	ClassOther:       {Backoff: 10 * time.Second, MaxBackoff: time.Minute, MaxAttempts: 3},
+	ClassAuth:        {},
+	ClassInput:       {},
+}
+
+// delay returns how long to wait before retrying an item that has failed
+// attempts times.
+func (p Policy) delay(attempts int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempts && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, max(p.MaxBackoff, p.Backoff))
+}
+
+// Retryable returns the failed items of m that policies allow another
+// attempt, sorted.
+func Retryable(m *Manifest, policies map[string]Policy) []string {
+	var out []string
+	for _, f := range m.Failures() {
+		if f.Attempts < policies[f.Class].MaxAttempts {
+			out = append(out, f.Item)
+		}
+	}
+	return out
+}
+
+// Retry runs the retryable failed items of m again, concurrency at a time,
+// each after its class's backoff, recording the outcomes in m. Items that
+// fail again are retried in later rounds until policies give up on them.
+func Retry(ctx context.Context, m *Manifest, policies map[string]Policy, concurrency int, run func(ctx context.Context, item string) error) error {
+	slots := make(chan struct{}, max(concurrency, 1))
+	var (
+		mu       sync.Mutex
+		writeErr error
+	)
+	for {
+		items := Retryable(m, policies)
+		if len(items) == 0 {
+			return nil
+		}
+		var wg sync.WaitGroup
+		for _, item := range items {
+			f := m.Failure(item)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				select {
+				case <-time.After(policies[f.Class].delay(f.Attempts)):
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				defer func() { <-slots }()
+				if err := m.Finish(item, run(ctx, item)); err != nil {
+					mu.Lock()
+					writeErr = err
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if writeErr != nil {
+			return writeErr
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/llmclient"
+)
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("generate: %w", llmclient.ErrUnavailable), ClassUnavailable},
+		{fmt.Errorf("call: %w", context.DeadlineExceeded), ClassTimeout},
+		{errors.New("Error response: Code=429, quota exceeded"), ClassUnavailable},
+		{errors.New("VOLC_APPID and VOLC_TOKEN must be set"), ClassAuth},
+		{errors.New("websocket: close 1006 (abnormal closure): unexpected EOF"), ClassNetwork},
+		{errors.New("no transcript received"), ClassInput},
+		{errors.New("boom"), ClassOther},
+	} {
+		if got := Classify(tc.err); got != tc.want {
+			t.Errorf("Classify(%q) = %s, want %s", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	m, err := Create(filepath.Join(t.TempDir(), "run.manifest.jsonl"), "tool", nil, []string{"auth", "flaky", "ok"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.Done("ok")
+	m.Fail("auth", errors.New("401 unauthorized"))
+	m.Fail("flaky", errors.New("connection reset"))
+
+	policies := map[string]Policy{ClassNetwork: {MaxAttempts: 3}}
+	runs := 0
+	err = Retry(context.Background(), m, policies, 1, func(ctx context.Context, item string) error {
+		runs++
+		if runs == 1 {
+			return errors.New("connection reset")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 {
+		t.Errorf("runs = %d, want 2", runs)
+	}
+	want := []Failure{{Item: "auth", Class: ClassAuth, Error: "401 unauthorized", Attempts: 1}}
+	if diff := cmp.Diff(want, m.Failures()); diff != "" {
+		t.Errorf("failures mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"flaky", "ok"}, m.Items(StatusDone)); diff != "" {
+		t.Errorf("done mismatch (-want +got):\n%s", diff)
+	}
+}
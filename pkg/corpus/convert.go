@@ -0,0 +1,75 @@
+package corpus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ToFLAC writes the audio of u as FLAC to w, resampled to sampleRate and
+// mixed to channels unless they are 0. FLAC sources that need no
+// conversion are copied as is; everything else goes through ffmpeg, which
+// must be on the PATH.
+func ToFLAC(ctx context.Context, u Utterance, sampleRate, channels int, w io.Writer) error {
+	if !u.Pipe && sampleRate == 0 && channels == 0 && strings.EqualFold(fileExt(u.Audio), ".flac") {
+		f, err := os.Open(u.Audio)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	}
+
+	args := []string{"-v", "error", "-nostdin", "-i", u.Audio}
+	if u.Pipe {
+		args = []string{"-v", "error", "-i", "pipe:0"}
+	}
+	if sampleRate > 0 {
+		args = append(args, "-ar", strconv.Itoa(sampleRate))
+	}
+	if channels > 0 {
+		args = append(args, "-ac", strconv.Itoa(channels))
+	}
+	args = append(args, "-c:a", "flac", "-f", "flac", "pipe:1")
+	ffmpeg := exec.CommandContext(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	ffmpeg.Stdout = w
+	ffmpeg.Stderr = &stderr
+
+	var src *exec.Cmd
+	if u.Pipe {
+		src = exec.CommandContext(ctx, "sh", "-c", u.Audio)
+		src.Stderr = &stderr
+		out, err := src.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		ffmpeg.Stdin = out
+		if err := src.Start(); err != nil {
+			return fmt.Errorf("%s: %w", u.Audio, err)
+		}
+	}
+	err := ffmpeg.Run()
+	if src != nil {
+		if werr := src.Wait(); err == nil && werr != nil {
+			err = werr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", u.Audio, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func fileExt(p string) string {
+	if i := strings.LastIndexByte(p, '.'); i >= 0 && !strings.ContainsAny(p[i:], `/\`) {
+		return p[i:]
+	}
+	return ""
+}
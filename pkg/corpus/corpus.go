@@ -0,0 +1,235 @@
+// Package corpus reads the utterance lists of public ASR corpora: Kaldi
+// data directories, LibriSpeech and Common Voice, and of plain directories
+// of audio files.
+package corpus
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Corpus formats.
+const (
+	FormatKaldi       = "kaldi"
+	FormatLibriSpeech = "librispeech"
+	FormatCommonVoice = "commonvoice"
+	FormatAudio       = "audio"
+)
+
+// audioExts are the file extensions FormatAudio takes as recordings.
+var audioExts = map[string]bool{
+	".wav": true, ".flac": true, ".mp3": true, ".m4a": true, ".aac": true,
+	".ogg": true, ".opus": true, ".webm": true, ".amr": true, ".wma": true, ".mp4": true,
+}
+
+// Utterance is one recording of a corpus with its reference transcript.
+type Utterance struct {
+	ID   string
+	Text string
+	// Audio is the path of the recording, or, when Pipe is set, a shell
+	// command writing it to stdout, as Kaldi's wav.scp allows.
+	Audio string
+	Pipe  bool
+}
+
+// Read returns the utterances of the corpus at path in format, sorted by
+// ID: a Kaldi data directory, a LibriSpeech directory (any level of it),
+// a Common Voice TSV file, or a directory of audio files.
+func Read(format, path string) ([]Utterance, error) {
+	var utts []Utterance
+	var err error
+	switch format {
+	case FormatKaldi:
+		utts, err = readKaldi(path)
+	case FormatLibriSpeech:
+		utts, err = readLibriSpeech(path)
+	case FormatCommonVoice:
+		utts, err = readCommonVoice(path)
+	case FormatAudio:
+		utts, err = readAudioDir(path)
+	default:
+		return nil, fmt.Errorf("unknown corpus format %q; want %s, %s, %s or %s", format, FormatKaldi, FormatLibriSpeech, FormatCommonVoice, FormatAudio)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(utts, func(i, j int) bool { return utts[i].ID < utts[j].ID })
+	return utts, nil
+}
+
+// readKaldi reads wav.scp and text of a Kaldi data directory. Utterances
+// without a transcript are left out. Segmented recordings (a segments
+// file) are not supported.
+func readKaldi(dir string) ([]Utterance, error) {
+	if _, err := os.Stat(filepath.Join(dir, "segments")); err == nil {
+		return nil, fmt.Errorf("%s: segmented recordings are not supported", dir)
+	}
+	text, err := readKeyedLines(filepath.Join(dir, "text"))
+	if err != nil {
+		return nil, err
+	}
+	scp, err := readKeyedLines(filepath.Join(dir, "wav.scp"))
+	if err != nil {
+		return nil, err
+	}
+	var utts []Utterance
+	for id, audio := range scp {
+		t, ok := text[id]
+		if !ok {
+			continue
+		}
+		u := Utterance{ID: id, Text: t, Audio: audio}
+		if cmd, ok := strings.CutSuffix(audio, "|"); ok {
+			u.Audio, u.Pipe = strings.TrimSpace(cmd), true
+		} else if !filepath.IsAbs(audio) {
+			// Relative paths in wav.scp are relative to the recipe
+			// directory, two levels up from data/<set>.
+			u.Audio = filepath.Join(dir, "..", "..", audio)
+		}
+		utts = append(utts, u)
+	}
+	return utts, nil
+}
+
+// readLibriSpeech reads the "<speaker>-<chapter>.trans.txt" files under
+// dir, each next to its chapter's "<id>.flac" files.
+func readLibriSpeech(dir string) ([]Utterance, error) {
+	var utts []Utterance
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".trans.txt") {
+			return err
+		}
+		lines, err := readKeyedLines(p)
+		if err != nil {
+			return err
+		}
+		for id, t := range lines {
+			utts = append(utts, Utterance{ID: id, Text: t, Audio: filepath.Join(filepath.Dir(p), id+".flac")})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(utts) == 0 {
+		return nil, fmt.Errorf("%s: no *.trans.txt files", dir)
+	}
+	return utts, nil
+}
+
+// readAudioDir takes every audio file under dir as an utterance, its ID
+// the path relative to dir without the extension and with "-" between
+// directories. A "<name>.txt" file next to "<name>.wav" is its transcript.
+func readAudioDir(dir string) ([]Utterance, error) {
+	var utts []Utterance
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !audioExts[strings.ToLower(fileExt(p))] {
+			return err
+		}
+		base := strings.TrimSuffix(p, fileExt(p))
+		rel, err := filepath.Rel(dir, base)
+		if err != nil {
+			return err
+		}
+		u := Utterance{ID: strings.ReplaceAll(filepath.ToSlash(rel), "/", "-"), Audio: p}
+		if t, err := os.ReadFile(base + ".txt"); err == nil {
+			u.Text = strings.TrimSpace(string(t))
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		utts = append(utts, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(utts) == 0 {
+		return nil, fmt.Errorf("%s: no audio files", dir)
+	}
+	return utts, nil
+}
+
+// readCommonVoice reads a Common Voice TSV file such as test.tsv, whose
+// clips are in the clips directory next to it.
+func readCommonVoice(path string) ([]Utterance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comma = '\t'
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	pathCol, textCol := -1, -1
+	for i, name := range header {
+		switch name {
+		case "path":
+			pathCol = i
+		case "sentence":
+			textCol = i
+		}
+	}
+	if pathCol < 0 || textCol < 0 {
+		return nil, fmt.Errorf("%s: need path and sentence columns", path)
+	}
+	clips := filepath.Join(filepath.Dir(path), "clips")
+	var utts []Utterance
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(rec) <= max(pathCol, textCol) {
+			continue
+		}
+		clip := rec[pathCol]
+		utts = append(utts, Utterance{
+			ID:    strings.TrimSuffix(clip, filepath.Ext(clip)),
+			Text:  rec[textCol],
+			Audio: filepath.Join(clips, clip),
+		})
+	}
+	return utts, nil
+}
+
+// readKeyedLines reads a file of "<key> <value>" lines into a map, the
+// key separated by a space or tab.
+func readKeyedLines(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		key, value := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			key, value = line[:i], strings.TrimSpace(line[i:])
+		}
+		if key != "" {
+			out[key] = value
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,74 @@
+package corpus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"s5/data/test/wav.scp": "u1 wav/u1.wav\nu2 sox u2.sph -t wav - |\nu3 wav/u3.wav\n",
+		"s5/data/test/text":    "u1 hello world\nu2\tgood  morning\n",
+
+		"LibriSpeech/test-clean/61/70968/61-70968.trans.txt": "61-70968-0000 HE BEGAN\n61-70968-0001 GIVE NOT\n",
+
+		"cv/test.tsv": "client_id\tpath\tsentence\tup_votes\nc1\tcommon_voice_en_1.mp3\tHello there.\t2\n",
+
+		"calls/a.WAV":         "",
+		"calls/a.txt":         " hi there\n",
+		"calls/day2/b.m4a":    "",
+		"calls/day2/notes.md": "",
+	})
+	libri := filepath.Join(root, "LibriSpeech", "test-clean", "61", "70968")
+
+	for _, tc := range []struct {
+		format, path string
+		want         []Utterance
+	}{
+		{FormatKaldi, "s5/data/test", []Utterance{
+			{ID: "u1", Text: "hello world", Audio: filepath.Join(root, "s5", "wav", "u1.wav")},
+			{ID: "u2", Text: "good  morning", Audio: "sox u2.sph -t wav -", Pipe: true},
+		}},
+		{FormatLibriSpeech, "LibriSpeech", []Utterance{
+			{ID: "61-70968-0000", Text: "HE BEGAN", Audio: filepath.Join(libri, "61-70968-0000.flac")},
+			{ID: "61-70968-0001", Text: "GIVE NOT", Audio: filepath.Join(libri, "61-70968-0001.flac")},
+		}},
+		{FormatCommonVoice, "cv/test.tsv", []Utterance{
+			{ID: "common_voice_en_1", Text: "Hello there.", Audio: filepath.Join(root, "cv", "clips", "common_voice_en_1.mp3")},
+		}},
+		{FormatAudio, "calls", []Utterance{
+			{ID: "a", Text: "hi there", Audio: filepath.Join(root, "calls", "a.WAV")},
+			{ID: "day2-b", Audio: filepath.Join(root, "calls", "day2", "b.m4a")},
+		}},
+	} {
+		got, err := Read(tc.format, filepath.Join(root, filepath.FromSlash(tc.path)))
+		if err != nil {
+			t.Errorf("Read(%s): %v", tc.format, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("Read(%s) mismatch (-want +got):\n%s", tc.format, diff)
+		}
+	}
+
+	if _, err := Read("timit", root); err == nil {
+		t.Error("Read of an unknown format succeeded, want error")
+	}
+}
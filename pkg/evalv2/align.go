@@ -0,0 +1,106 @@
+package evalv2
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"asr-eval/pkg/llmclient"
+)
+
+// alignment is the model's response to the align prompt.
+type alignment struct {
+	Checkpoints  []checkpointTime `json:"checkpoints"`
+	AudioReality []TimedText      `json:"audio_reality"`
+}
+
+// checkpointTime is where the model heard a checkpoint.
+type checkpointTime struct {
+	ID      string `json:"id"`
+	StartMS int    `json:"start_ms"`
+	EndMS   int    `json:"end_ms"`
+}
+
+// Align has the generation model listen to the audio file at audioPath and
+// time each checkpoint of ec, and its audio reality inference, in it. The
+// StartMS a context is generated with is a rough guess made along with
+// everything else; clips and timing metrics need the words' actual place.
+// It returns a copy of ec, in which checkpoints the model did not time
+// have no Span.
+func (e *Evaluator) Align(ctx context.Context, audioPath string, ec *EvalContext) (*EvalContext, *llmclient.Usage, error) {
+	if len(ec.Checkpoints) == 0 {
+		return nil, nil, errors.New("context has no checkpoints to align")
+	}
+	blob, err := audioBlob(audioPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := buildAlignPrompt(ec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ReportProgress(ctx, Progress{Stage: StagePromptBuilt})
+
+	req := &llmclient.Request{
+		Text:  p,
+		Blobs: []llmclient.Blob{blob},
+	}
+	var resp alignment
+	model, usage, err := e.generateJSON(ctx, e.genModel, req, &resp)
+	if err != nil {
+		return nil, usage, err
+	}
+
+	times := make(map[string]Span, len(resp.Checkpoints))
+	for _, t := range resp.Checkpoints {
+		times[t.ID] = span(t.StartMS, t.EndMS)
+	}
+	out := ec.Unaligned()
+	timed := 0
+	for i := range out.Checkpoints {
+		if sp, ok := times[out.Checkpoints[i].ID]; ok {
+			out.Checkpoints[i].Span = &sp
+			timed++
+		}
+	}
+	if timed == 0 {
+		return nil, usage, errors.New("model timed none of the checkpoints")
+	}
+	for _, t := range resp.AudioReality {
+		if strings.TrimSpace(t.Text) == "" {
+			continue
+		}
+		sp := span(t.StartMS, t.EndMS)
+		out.AudioRealitySpans = append(out.AudioRealitySpans, TimedText{StartMS: sp.StartMS, EndMS: sp.EndMS, Text: t.Text})
+	}
+	slices.SortStableFunc(out.AudioRealitySpans, func(a, b TimedText) int { return a.StartMS - b.StartMS })
+	out.Meta.AlignModel = model
+	return out, usage, nil
+}
+
+// span orders the times a model gave and keeps them within the audio's
+// start.
+func span(startMS, endMS int) Span {
+	startMS = max(startMS, 0)
+	return Span{StartMS: startMS, EndMS: max(endMS, startMS)}
+}
+
+// Aligned reports whether ec holds times from Align.
+func (ec *EvalContext) Aligned() bool {
+	return ec.Meta.AlignModel != "" || len(ec.AudioRealitySpans) > 0 ||
+		slices.ContainsFunc(ec.Checkpoints, func(cp Checkpoint) bool { return cp.Span != nil })
+}
+
+// Unaligned returns a copy of ec without what Align added.
+func (ec *EvalContext) Unaligned() *EvalContext {
+	c := *ec
+	c.AudioRealitySpans = nil
+	c.Meta.AlignModel = ""
+	c.Checkpoints = slices.Clone(ec.Checkpoints)
+	for i := range c.Checkpoints {
+		c.Checkpoints[i].Span = nil
+	}
+	return &c
+}
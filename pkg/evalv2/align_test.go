@@ -0,0 +1,108 @@
+package evalv2
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/llmclient"
+)
+
+// cannedClient answers every request with response.
+type cannedClient struct {
+	response string
+	req      *llmclient.Request
+}
+
+func (c *cannedClient) GenerateJSON(ctx context.Context, model string, req *llmclient.Request, resp any) (*llmclient.Usage, error) {
+	c.req = req
+	return &llmclient.Usage{}, json.Unmarshal([]byte(c.response), resp)
+}
+
+func (c *cannedClient) CountTokens(ctx context.Context, model string, req *llmclient.Request) (int, error) {
+	return 0, nil
+}
+
+func TestAlign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.flac")
+	if err := os.WriteFile(path, []byte("fLaC"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ec := &EvalContext{
+		Meta: ContextMeta{AudioRealityInference: "um send forty dollars to Bob", AlignModel: "old"},
+		Checkpoints: []Checkpoint{
+			{ID: "S1", StartMS: 500, TextSegment: "send forty dollars"},
+			{ID: "S2", StartMS: 2000, TextSegment: "to Bob", Span: &Span{StartMS: 1, EndMS: 2}},
+			{ID: "S3", StartMS: 3000, TextSegment: "thanks"},
+		},
+	}
+	c := &cannedClient{response: `{
+		"checkpoints": [
+			{"id": "S2", "start_ms": 2100, "end_ms": 1900},
+			{"id": "S1", "start_ms": -20, "end_ms": 1800},
+			{"id": "S9", "start_ms": 0, "end_ms": 1}
+		],
+		"audio_reality": [
+			{"start_ms": 2100, "end_ms": 2600, "text": "to Bob"},
+			{"start_ms": 100, "end_ms": 1800, "text": "um send forty dollars"},
+			{"start_ms": 2600, "end_ms": 2700, "text": " "}
+		]
+	}`}
+	e := NewEvaluator(c, "gen", "eval")
+	got, _, err := e.Align(context.Background(), path, ec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &EvalContext{
+		Meta: ContextMeta{AudioRealityInference: "um send forty dollars to Bob", AlignModel: "gen"},
+		Checkpoints: []Checkpoint{
+			{ID: "S1", StartMS: 500, TextSegment: "send forty dollars", Span: &Span{StartMS: 0, EndMS: 1800}},
+			{ID: "S2", StartMS: 2000, TextSegment: "to Bob", Span: &Span{StartMS: 2100, EndMS: 2100}},
+			{ID: "S3", StartMS: 3000, TextSegment: "thanks"},
+		},
+		AudioRealitySpans: []TimedText{
+			{StartMS: 100, EndMS: 1800, Text: "um send forty dollars"},
+			{StartMS: 2100, EndMS: 2600, Text: "to Bob"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Align() mismatch (-want +got):\n%s", diff)
+	}
+	if ec.Checkpoints[1].Span.StartMS != 1 || ec.Meta.AlignModel != "old" {
+		t.Error("Align() changed the context it was given")
+	}
+	if p := c.req.Text; !strings.Contains(p, "- S3: thanks") || !strings.Contains(p, "> um send forty dollars to Bob") {
+		t.Errorf("prompt lacks the checkpoints or the audio reality:\n%s", p)
+	}
+	if b := c.req.Blobs; len(b) != 1 || b[0].MIMEType != "audio/flac" {
+		t.Errorf("request blobs = %v, want the audio", b)
+	}
+
+	c.response = `{"checkpoints": [{"id": "S9", "start_ms": 0, "end_ms": 1}]}`
+	if _, _, err := e.Align(context.Background(), path, ec); err == nil {
+		t.Error("Align() succeeded with none of the checkpoints timed")
+	}
+}
+
+func TestUnalignedPrompt(t *testing.T) {
+	ec := &EvalContext{
+		Meta:              ContextMeta{AlignModel: "gen"},
+		Checkpoints:       []Checkpoint{{ID: "S1", Span: &Span{StartMS: 1, EndMS: 2}}},
+		AudioRealitySpans: []TimedText{{Text: "spoken"}},
+	}
+	for _, build := range []func(evaluatePromptData) (string, error){buildEvaluatePrompt, buildEvaluatePromptV2} {
+		p, err := build(evaluatePromptData{EvalContext: ec})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(p, "span") || strings.Contains(p, "align_model") {
+			t.Errorf("evaluation prompt holds the alignment:\n%s", p)
+		}
+	}
+}
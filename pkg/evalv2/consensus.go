@@ -0,0 +1,180 @@
+package evalv2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+
+	"asr-eval/pkg/llmclient"
+)
+
+// EvaluateConsensus runs the evaluation once per judge model and merges the
+// verdicts. Each checkpoint takes the majority status across judges; ties
+// resolve to the stricter status. Checkpoints the judges disagree on are
+// listed in EvalResult.Disputed for human review.
+//
+// Judges are called through the evaluator's client by name, so with a
+// llmclient.Router each may run on its own backend. A judge that fails does
+// not fail the evaluation as long as a quorum, more than half the judges,
+// succeeds; the failed judges are listed in EvalResult.FailedJudges.
+func (e *Evaluator) EvaluateConsensus(ctx context.Context, contextData *EvalContext, transcripts map[string]string, judges []string) (*EvalReport, *llmclient.Usage, error) {
+	if len(judges) < 2 {
+		return nil, nil, fmt.Errorf("consensus requires at least 2 judges, got %d", len(judges))
+	}
+
+	reports := make([]*EvalReport, len(judges))
+	usages := make([]*llmclient.Usage, len(judges))
+	errs := make([]error, len(judges))
+
+	var wg sync.WaitGroup
+	for i, judge := range judges {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i], usages[i], errs[i] = e.evaluate(ctx, judge, contextData, transcripts)
+		}()
+	}
+	wg.Wait()
+
+	usage := &llmclient.Usage{}
+	var failed []string
+	var failures []error
+	for i, err := range errs {
+		if u := usages[i]; u != nil {
+			usage.PromptTokens += u.PromptTokens
+			usage.ThoughtTokens += u.ThoughtTokens
+			usage.OutputTokens += u.OutputTokens
+			usage.TotalTokens += u.TotalTokens
+		}
+		if err != nil {
+			failed = append(failed, judges[i])
+			failures = append(failures, fmt.Errorf("judge %s failed: %w", judges[i], err))
+			reports[i] = nil
+		}
+	}
+	if quorum := len(judges)/2 + 1; len(judges)-len(failed) < quorum {
+		return nil, usage, fmt.Errorf("%d of %d judges failed, %d must succeed: %w", len(failed), len(judges), quorum, errors.Join(failures...))
+	}
+	if len(failures) > 0 {
+		slog.Warn("Consensus evaluated without failed judges", "failed_judges", failed, "error", errors.Join(failures...))
+	}
+
+	resp := &EvalReport{
+		Results: make(map[string]EvalResult),
+	}
+	for provider := range transcripts {
+		var verdicts []JudgeVerdict
+		var primary *EvalResult
+		for _, r := range reports {
+			if r == nil {
+				continue
+			}
+			res, ok := r.Results[provider]
+			if !ok {
+				continue
+			}
+			if primary == nil {
+				primary = &res
+			}
+			verdicts = append(verdicts, JudgeVerdict{
+				Model:             res.Model,
+				Metrics:           res.Metrics,
+				CheckpointResults: res.CheckpointResults,
+				Truncation:        res.Truncation,
+			})
+		}
+		if primary == nil {
+			continue
+		}
+		merged := mergeVerdicts(*primary, verdicts, contextData)
+		merged.FailedJudges = failed
+		resp.Results[provider] = merged
+	}
+
+	return resp, usage, nil
+}
+
+// mergeVerdicts builds the consensus result for one provider. Revised
+// transcript and summary are taken from the primary (first) judge. Only
+// checkpoints every judge had dropped from its prompt are left out of S.
+func mergeVerdicts(primary EvalResult, verdicts []JudgeVerdict, ctx *EvalContext) EvalResult {
+	res := primary
+	res.Model = ""
+	res.Verdicts = verdicts
+	res.CheckpointResults = make(map[string]CheckpointResult)
+	res.Disputed = nil
+	if primary.Truncation != nil {
+		tr := *primary.Truncation
+		tr.DroppedCheckpoints = nil
+		for _, id := range primary.Truncation.DroppedCheckpoints {
+			if !slices.ContainsFunc(verdicts, func(v JudgeVerdict) bool { return !v.Truncation.dropped(id) }) {
+				tr.DroppedCheckpoints = append(tr.DroppedCheckpoints, id)
+			}
+		}
+		res.Truncation = &tr
+	}
+
+	for _, cp := range ctx.Checkpoints {
+		votes := make(map[CheckpointStatus]int)
+		for _, v := range verdicts {
+			if r, ok := v.CheckpointResults[cp.ID]; ok {
+				votes[r.Status]++
+			}
+		}
+		if len(votes) == 0 {
+			continue
+		}
+		if len(votes) > 1 {
+			res.Disputed = append(res.Disputed, cp.ID)
+		}
+
+		status := majorityStatus(votes)
+		result := CheckpointResult{Status: status}
+		// Keep the detected text and reason from the first judge that agrees.
+		for _, v := range verdicts {
+			if r, ok := v.CheckpointResults[cp.ID]; ok && r.Status == status {
+				result = r
+				break
+			}
+		}
+		res.CheckpointResults[cp.ID] = result
+	}
+
+	pScore := 0.0
+	for _, v := range verdicts {
+		pScore += v.Metrics.PScore
+	}
+	res.Metrics = EvalMetrics{
+		SScore:          scoreCheckpoints(res.CheckpointResults, ctx, res.Truncation),
+		PScore:          pScore / float64(len(verdicts)),
+		PhoneticDetails: primary.Metrics.PhoneticDetails,
+	}
+	return res
+}
+
+// statusRank orders statuses from strictest to most lenient.
+var statusRank = map[CheckpointStatus]int{
+	StatusFail:    0,
+	StatusPartial: 1,
+	StatusPass:    2,
+}
+
+// majorityStatus returns the status with the most votes, preferring the
+// stricter status on ties.
+func majorityStatus(votes map[CheckpointStatus]int) CheckpointStatus {
+	statuses := make([]CheckpointStatus, 0, len(votes))
+	for s := range votes {
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if votes[statuses[i]] != votes[statuses[j]] {
+			return votes[statuses[i]] > votes[statuses[j]]
+		}
+		return statusRank[statuses[i]] < statusRank[statuses[j]]
+	})
+	return statuses[0]
+}
@@ -0,0 +1,126 @@
+package evalv2
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/llmclient"
+)
+
+func TestMergeVerdicts(t *testing.T) {
+	ctx := &EvalContext{
+		Checkpoints: []Checkpoint{
+			{ID: "S1", Tier: 1, Weight: 0.5},
+			{ID: "S2", Tier: 2, Weight: 0.3},
+			{ID: "S3", Tier: 3, Weight: 0.2},
+		},
+	}
+	verdicts := []JudgeVerdict{
+		{
+			Model:   "a",
+			Metrics: EvalMetrics{PScore: 0.8},
+			CheckpointResults: map[string]CheckpointResult{
+				"S1": {Status: StatusPass, Detected: "a1"},
+				"S2": {Status: StatusPass},
+				"S3": {Status: StatusPass},
+			},
+		},
+		{
+			Model:   "b",
+			Metrics: EvalMetrics{PScore: 0.6},
+			CheckpointResults: map[string]CheckpointResult{
+				"S1": {Status: StatusPass, Detected: "b1"},
+				"S2": {Status: StatusFail, Reason: "missed"},
+				"S3": {Status: StatusPass},
+			},
+		},
+	}
+
+	got := mergeVerdicts(EvalResult{Model: "a"}, verdicts, ctx)
+
+	wantCPs := map[string]CheckpointResult{
+		"S1": {Status: StatusPass, Detected: "a1"},
+		"S2": {Status: StatusFail, Reason: "missed"}, // tie resolves to stricter
+		"S3": {Status: StatusPass},
+	}
+	if diff := cmp.Diff(wantCPs, got.CheckpointResults); diff != "" {
+		t.Errorf("checkpoint results mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"S2"}, got.Disputed); diff != "" {
+		t.Errorf("disputed mismatch (-want +got):\n%s", diff)
+	}
+	if math.Abs(got.Metrics.SScore-0.7) > 1e-9 {
+		t.Errorf("SScore = %v, want 0.7", got.Metrics.SScore)
+	}
+	if math.Abs(got.Metrics.PScore-0.7) > 1e-9 {
+		t.Errorf("PScore = %v, want 0.7", got.Metrics.PScore)
+	}
+	if got.Model != "" {
+		t.Errorf("Model = %q, want empty for consensus result", got.Model)
+	}
+}
+
+// failingClient fails every request with err.
+type failingClient struct {
+	err error
+}
+
+func (c failingClient) GenerateJSON(ctx context.Context, model string, req *llmclient.Request, resp any) (*llmclient.Usage, error) {
+	return nil, c.err
+}
+
+func (c failingClient) CountTokens(ctx context.Context, model string, req *llmclient.Request) (int, error) {
+	return 0, c.err
+}
+
+func TestEvaluateConsensusQuorum(t *testing.T) {
+	ctx := &EvalContext{Checkpoints: []Checkpoint{{ID: "S1", Tier: 1, Weight: 1}}}
+	transcripts := map[string]string{"p": "hello"}
+	verdict := `[{"provider": "p", "checkpoint_results": [{"id": "S1", "status": "Pass"}]}]`
+	router := llmclient.NewRouter(map[string]llmclient.Client{
+		"a":   &cannedClient{response: verdict},
+		"b":   &cannedClient{response: verdict},
+		"bad": failingClient{errors.New("invalid key")},
+	}, "a")
+	e := NewEvaluator(router, "gen", "eval")
+
+	report, _, err := e.EvaluateConsensus(context.Background(), ctx, transcripts, []string{"a/judge", "b/judge", "bad/judge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := report.Results["p"]
+	if diff := cmp.Diff([]string{"bad/judge"}, r.FailedJudges); diff != "" {
+		t.Errorf("failed judges mismatch (-want +got):\n%s", diff)
+	}
+	if len(r.Verdicts) != 2 || r.Metrics.SScore != 1 {
+		t.Errorf("got %d verdicts with SScore %v, want 2 with 1", len(r.Verdicts), r.Metrics.SScore)
+	}
+
+	if _, _, err := e.EvaluateConsensus(context.Background(), ctx, transcripts, []string{"a/judge", "bad/judge"}); err == nil {
+		t.Error("EvaluateConsensus succeeded with half the judges failed")
+	}
+}
+
+func TestMajorityStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		votes map[CheckpointStatus]int
+		want  CheckpointStatus
+	}{
+		{"unanimous", map[CheckpointStatus]int{StatusPass: 3}, StatusPass},
+		{"majority", map[CheckpointStatus]int{StatusPass: 2, StatusFail: 1}, StatusPass},
+		{"tie prefers fail", map[CheckpointStatus]int{StatusPass: 1, StatusFail: 1}, StatusFail},
+		{"tie prefers partial over pass", map[CheckpointStatus]int{StatusPass: 1, StatusPartial: 1}, StatusPartial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := majorityStatus(tt.votes); got != tt.want {
+				t.Errorf("majorityStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package evalv2
+
+import "unicode/utf8"
+
+// audioTokensPerSecond is the rate at which Gemini models bill audio input.
+const audioTokensPerSecond = 32
+
+// EstimateGenerateContextTokens estimates, without calling the model, the
+// prompt tokens GenerateContext would send for audio of durationMS.
+func EstimateGenerateContextTokens(groundTruth string, transcripts map[string]string, durationMS int64) (int, error) {
+	p, err := buildGenerateContextPrompt(generateContextPromptData{GroundTruth: groundTruth, Transcripts: transcripts})
+	if err != nil {
+		return 0, err
+	}
+	return EstimateTextTokens(p) + int(durationMS*audioTokensPerSecond/1000), nil
+}
+
+// EstimateEvaluateTokens estimates, without calling the model, the prompt
+// tokens one judge's Evaluate call would send. The estimate ignores the
+// truncation a prompt token budget may apply.
+func EstimateEvaluateTokens(ec *EvalContext, transcripts map[string]string) (int, error) {
+	p, err := buildEvaluatePrompt(evaluatePromptData{EvalContext: ec, Transcripts: transcripts})
+	if err != nil {
+		return 0, err
+	}
+	return EstimateTextTokens(p), nil
+}
+
+// EstimateTextTokens roughly counts the tokens of s: four ASCII bytes or
+// one other rune (such as a CJK character) per token.
+func EstimateTextTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
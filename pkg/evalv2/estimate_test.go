@@ -0,0 +1,19 @@
+package evalv2
+
+import "testing"
+
+func TestEstimateTextTokens(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"hello world", 3},
+		{"你好世界", 4},
+		{"打开 Wi-Fi", 2 + 2},
+	} {
+		if got := EstimateTextTokens(tc.s); got != tc.want {
+			t.Errorf("EstimateTextTokens(%q) = %d, want %d", tc.s, got, tc.want)
+		}
+	}
+}
@@ -2,25 +2,33 @@ package evalv2
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"mime"
 	"path/filepath"
-	"reflect"
+	"slices"
+	"time"
 
 	"os"
 
-	"google.golang.org/genai"
+	"asr-eval/pkg/llmclient"
 )
 
+// maxModelAttempts is the number of tries a model gets on retryable errors
+// before the evaluator falls back to the next model in the chain.
+const maxModelAttempts = 3
+
 type Evaluator struct {
-	client    *genai.Client
+	client    llmclient.Client
 	genModel  string
 	evalModel string
+	fallbacks []string
+
+	maxPromptTokens int
 }
 
-func NewEvaluator(client *genai.Client, genModel, evalModel string) *Evaluator {
+func NewEvaluator(client llmclient.Client, genModel, evalModel string) *Evaluator {
 	return &Evaluator{
 		client:    client,
 		genModel:  genModel,
@@ -28,17 +36,17 @@ func NewEvaluator(client *genai.Client, genModel, evalModel string) *Evaluator {
 	}
 }
 
-func (e *Evaluator) GenerateContext(ctx context.Context, audioPath string, groundTruth string, transcripts map[string]string) (*EvalContext, *genai.GenerateContentResponseUsageMetadata, error) {
+// SetFallbackModels sets the ordered list of models to try when the primary
+// model keeps failing with quota or availability errors.
+func (e *Evaluator) SetFallbackModels(models []string) {
+	e.fallbacks = models
+}
+
+func (e *Evaluator) GenerateContext(ctx context.Context, audioPath string, groundTruth string, transcripts map[string]string) (*EvalContext, *llmclient.Usage, error) {
 	// 1. Prepare Audio Part
-	data, err := os.ReadFile(audioPath)
+	blob, err := audioBlob(audioPath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read audio file: %w", err)
-	}
-
-	ext := filepath.Ext(audioPath)
-	m := mime.TypeByExtension(ext)
-	if m == "" {
-		m = "audio/flac" // Default to flac as per dataset
+		return nil, nil, err
 	}
 
 	// 2. Prepare Text Prompt
@@ -50,24 +58,16 @@ func (e *Evaluator) GenerateContext(ctx context.Context, audioPath string, groun
 		return nil, nil, fmt.Errorf("failed to build context prompt: %w", err)
 	}
 
-	// 3. Call LLM
-	req := []*genai.Content{
-		{
-			Parts: []*genai.Part{
-				genai.NewPartFromText(p),
-				genai.NewPartFromBytes(data, m),
-			},
-		},
-	}
+	ReportProgress(ctx, Progress{Stage: StagePromptBuilt})
 
-	cfg := &genai.GenerateContentConfig{
-		ThinkingConfig: &genai.ThinkingConfig{
-			IncludeThoughts: true,
-		},
+	// 3. Call LLM
+	req := &llmclient.Request{
+		Text:  p,
+		Blobs: []llmclient.Blob{blob},
 	}
 
 	var resp EvalContext
-	usage, err := e.generateJSON(ctx, e.genModel, req, cfg, &resp)
+	_, usage, err := e.generateJSON(ctx, e.genModel, req, &resp)
 	if err != nil {
 		return nil, usage, err
 	}
@@ -88,8 +88,26 @@ func (e *Evaluator) GenerateContext(ctx context.Context, audioPath string, groun
 	return &resp, usage, nil
 }
 
-func (e *Evaluator) Evaluate(ctx context.Context, contextData *EvalContext, transcripts map[string]string) (*EvalReport, *genai.GenerateContentResponseUsageMetadata, error) {
-	p, err := buildEvaluatePrompt(evaluatePromptData{
+// audioBlob reads the audio file at path for a model to listen to.
+func audioBlob(path string) (llmclient.Blob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return llmclient.Blob{}, fmt.Errorf("failed to read audio file: %w", err)
+	}
+	m := mime.TypeByExtension(filepath.Ext(path))
+	if m == "" {
+		m = "audio/flac" // Default to flac as per dataset
+	}
+	return llmclient.Blob{MIMEType: m, Data: data}, nil
+}
+
+func (e *Evaluator) Evaluate(ctx context.Context, contextData *EvalContext, transcripts map[string]string) (*EvalReport, *llmclient.Usage, error) {
+	return e.evaluate(ctx, e.evalModel, contextData, transcripts)
+}
+
+// evaluate runs the V1 evaluation with model as the judge.
+func (e *Evaluator) evaluate(ctx context.Context, model string, contextData *EvalContext, transcripts map[string]string) (*EvalReport, *llmclient.Usage, error) {
+	p, truncation, err := e.fitPrompt(ctx, model, buildEvaluatePrompt, evaluatePromptData{
 		EvalContext: contextData,
 		Transcripts: transcripts,
 	})
@@ -97,18 +115,11 @@ func (e *Evaluator) Evaluate(ctx context.Context, contextData *EvalContext, tran
 		return nil, nil, fmt.Errorf("failed to build eval prompt: %w", err)
 	}
 
-	req := []*genai.Content{
-		{
-			Parts: []*genai.Part{
-				genai.NewPartFromText(p),
-			},
-		},
-	}
+	ReportProgress(ctx, Progress{Stage: StagePromptBuilt})
 
-	cfg := &genai.GenerateContentConfig{
-		ThinkingConfig: &genai.ThinkingConfig{
-			ThinkingLevel: genai.ThinkingLevelLow,
-		},
+	req := &llmclient.Request{
+		Text:     p,
+		Thinking: llmclient.ThinkingLow,
 	}
 
 	// llmCheckpointResult is the raw result from LLM (unexported)
@@ -132,7 +143,7 @@ func (e *Evaluator) Evaluate(ctx context.Context, contextData *EvalContext, tran
 	type llmEvalReport []llmEvalResult
 
 	var raw llmEvalReport
-	usage, err := e.generateJSON(ctx, e.evalModel, req, cfg, &raw)
+	model, usage, err := e.generateJSON(ctx, model, req, &raw)
 	if err != nil {
 		return nil, usage, err
 	}
@@ -142,7 +153,7 @@ func (e *Evaluator) Evaluate(ctx context.Context, contextData *EvalContext, tran
 		Results: make(map[string]EvalResult),
 	}
 
-	for _, item := range raw {
+	for i, item := range raw {
 		cps := make(map[string]CheckpointResult)
 		for _, cp := range item.CheckpointResults {
 			cps[cp.ID] = CheckpointResult{
@@ -158,15 +169,18 @@ func (e *Evaluator) Evaluate(ctx context.Context, contextData *EvalContext, tran
 			Metrics:           item.Metrics,
 			CheckpointResults: cps,
 			Summary:           item.Summary,
+			Model:             model,
+			Truncation:        truncation,
 		}
+		ReportProgress(ctx, Progress{Stage: StageProviderEvaluated, Model: model, Provider: item.Provider, Done: i + 1, Total: len(raw)})
 	}
 
 	return resp, usage, nil
 }
 
-func (e *Evaluator) EvaluateV2(ctx context.Context, contextData *EvalContext, transcripts map[string]string) (*EvalReport2, *genai.GenerateContentResponseUsageMetadata, error) {
+func (e *Evaluator) EvaluateV2(ctx context.Context, contextData *EvalContext, transcripts map[string]string) (*EvalReport2, *llmclient.Usage, error) {
 	// Use V2 Prompt
-	p, err := buildEvaluatePromptV2(evaluatePromptData{
+	p, truncation, err := e.fitPrompt(ctx, e.evalModel, buildEvaluatePromptV2, evaluatePromptData{
 		EvalContext: contextData,
 		Transcripts: transcripts,
 	})
@@ -174,18 +188,11 @@ func (e *Evaluator) EvaluateV2(ctx context.Context, contextData *EvalContext, tr
 		return nil, nil, fmt.Errorf("failed to build eval prompt: %w", err)
 	}
 
-	req := []*genai.Content{
-		{
-			Parts: []*genai.Part{
-				genai.NewPartFromText(p),
-			},
-		},
-	}
+	ReportProgress(ctx, Progress{Stage: StagePromptBuilt})
 
-	cfg := &genai.GenerateContentConfig{
-		ThinkingConfig: &genai.ThinkingConfig{
-			ThinkingLevel: genai.ThinkingLevelLow,
-		},
+	req := &llmclient.Request{
+		Text:     p,
+		Thinking: llmclient.ThinkingLow,
 	}
 
 	// 1. Define Intermediate Structs for LLM (must use Slices, not Maps)
@@ -207,7 +214,7 @@ func (e *Evaluator) EvaluateV2(ctx context.Context, contextData *EvalContext, tr
 	type llmEvalReportV2 []llmEvalResultV2
 
 	var raw llmEvalReportV2
-	usage, err := e.generateJSON(ctx, e.evalModel, req, cfg, &raw)
+	model, usage, err := e.generateJSON(ctx, e.evalModel, req, &raw)
 	if err != nil {
 		return nil, usage, err
 	}
@@ -217,7 +224,7 @@ func (e *Evaluator) EvaluateV2(ctx context.Context, contextData *EvalContext, tr
 		Results: make(map[string]EvalResult2),
 	}
 
-	for _, item := range raw {
+	for i, item := range raw {
 		// Convert Checkpoints Slice -> Map
 		cps := make(map[string]CheckpointResult)
 		for _, cp := range item.CheckpointResults {
@@ -236,6 +243,8 @@ func (e *Evaluator) EvaluateV2(ctx context.Context, contextData *EvalContext, tr
 			CheckpointResults: cps,
 			PhoneticAnalysis:  item.PhoneticAnalysis,
 			Summary:           item.Summary,
+			Model:             model,
+			Truncation:        truncation,
 		}
 
 		// Calculate Metrics in Go using the constructed ResultV2
@@ -243,6 +252,7 @@ func (e *Evaluator) EvaluateV2(ctx context.Context, contextData *EvalContext, tr
 		resultV2.Metrics = metrics
 
 		resp.Results[item.Provider] = resultV2
+		ReportProgress(ctx, Progress{Stage: StageProviderEvaluated, Model: model, Provider: item.Provider, Done: i + 1, Total: len(raw)})
 	}
 
 	return resp, usage, nil
@@ -250,31 +260,7 @@ func (e *Evaluator) EvaluateV2(ctx context.Context, contextData *EvalContext, tr
 
 func (e *Evaluator) calculateMetrics(item *EvalResult2, ctx *EvalContext) EvalMetrics {
 	// 1. Calculate S-Score
-	passedWeight := 0.0
-	totalWeight := 0.0
-
-	// Create a map for fast lookup of result status
-	resMap := item.CheckpointResults
-
-	for _, cp := range ctx.Checkpoints {
-		totalWeight += cp.Weight
-		if res, ok := resMap[cp.ID]; ok {
-			switch res.Status {
-			case StatusPass:
-				passedWeight += cp.Weight
-			case StatusPartial:
-				// Only for Tier 2/3 (enforced by LLM prompt usually, but good to check)
-				passedWeight += cp.Weight * 0.5
-			case StatusFail:
-				passedWeight += 0.0
-			}
-		}
-	}
-
-	sScore := 0.0
-	if totalWeight > 0 {
-		sScore = passedWeight / totalWeight
-	}
+	sScore := scoreCheckpoints(item.CheckpointResults, ctx, item.Truncation)
 
 	// 2. Calculate P-Score (PER)
 	// Reference is the "Audio Reality Inference"
@@ -291,70 +277,94 @@ func (e *Evaluator) calculateMetrics(item *EvalResult2, ctx *EvalContext) EvalMe
 	// OR just use rune count for now as a baseline for robustness.
 	// WAIT: content.Meta.TotalTokenCountEstimate is available! Use that?
 	// It says "approximated count of tokens". Let's use that as the denominator N.
-	N := float64(ctx.Meta.TotalTokenCountEstimate)
+	N := ctx.Meta.TotalTokenCountEstimate
 	if N <= 0 {
-		N = 1.0 // Prevent division by zero
+		N = 1 // Prevent division by zero
 	}
 
-	ins := len(item.PhoneticAnalysis.Insertions)
-	del := len(item.PhoneticAnalysis.Deletions)
-	sub := len(item.PhoneticAnalysis.Substitutions)
-
-	per := float64(ins+del+sub) / N
-	pScore := 1.0 - per
-	if pScore < 0 {
-		pScore = 0
+	details := PhoneticDetails{
+		Ins: len(item.PhoneticAnalysis.Insertions),
+		Del: len(item.PhoneticAnalysis.Deletions),
+		Sub: len(item.PhoneticAnalysis.Substitutions),
 	}
 
 	return EvalMetrics{
 		SScore: sScore,
-		PScore: pScore,
+		PScore: phoneticScore(details, N),
 		// QScore is calculated on the fly by the struct method
-		PhoneticDetails: PhoneticDetails{
-			Ins: ins,
-			Del: del,
-			Sub: sub,
-		},
+		PhoneticDetails: details,
 	}
 }
 
-func (e *Evaluator) generateJSON(ctx context.Context, model string, req []*genai.Content, cfg *genai.GenerateContentConfig, resp interface{}) (*genai.GenerateContentResponseUsageMetadata, error) {
-	// Automatically generate schema and set JSON response type
-	cfg.ResponseMIMEType = "application/json"
-	cfg.ResponseSchema = reflectSchema(reflect.TypeOf(resp))
+// scoreCheckpoints returns the weighted share of checkpoints in ctx that
+// passed, counting Partial as half. Checkpoints tr dropped from the prompt
+// were never judged and count neither way.
+func scoreCheckpoints(resMap map[string]CheckpointResult, ctx *EvalContext, tr *Truncation) float64 {
+	passedWeight := 0.0
+	totalWeight := 0.0
 
-	// Log the prompt for debugging
-	for i, content := range req {
-		for j, part := range content.Parts {
-			if part.Text != "" {
-				slog.Debug("LLM Prompt", "content_index", i, "part_index", j, "text", part.Text)
+	for _, cp := range ctx.Checkpoints {
+		if tr.dropped(cp.ID) {
+			continue
+		}
+		totalWeight += cp.Weight
+		if res, ok := resMap[cp.ID]; ok {
+			switch res.Status {
+			case StatusPass:
+				passedWeight += cp.Weight
+			case StatusPartial:
+				// Only for Tier 2/3 (enforced by LLM prompt usually, but good to check)
+				passedWeight += cp.Weight * 0.5
+			case StatusFail:
+				passedWeight += 0.0
 			}
 		}
 	}
 
-	r, err := e.client.Models.GenerateContent(ctx, model, req, cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
-	}
-
-	usage := r.UsageMetadata
-	if usage != nil {
-		slog.Info("LLM Usage",
-			slog.Int("prompt_tokens", int(usage.PromptTokenCount)),
-			slog.Int("thought_tokens", int(usage.ThoughtsTokenCount)),
-			slog.Int("output_tokens", int(usage.CandidatesTokenCount)),
-			slog.Int("total_tokens", int(usage.TotalTokenCount)))
+	if totalWeight == 0 {
+		return 0
 	}
+	return passedWeight / totalWeight
+}
 
-	// Log full raw response for debugging (includes thoughts, etc.)
-	if raw, err := r.MarshalJSON(); err == nil {
-		slog.Debug("LLM Raw Response", "json", string(raw))
+// generateJSON runs the request against model and, on repeated quota or
+// availability failures, against each fallback model in turn. It returns the
+// model that produced the response.
+func (e *Evaluator) generateJSON(ctx context.Context, model string, req *llmclient.Request, resp any) (string, *llmclient.Usage, error) {
+	models := []string{model}
+	for _, m := range e.fallbacks {
+		if !slices.Contains(models, m) {
+			models = append(models, m)
+		}
 	}
 
-	respStr := r.Text()
-	if err := json.Unmarshal([]byte(respStr), resp); err != nil {
-		return usage, fmt.Errorf("failed to parse JSON: %w\nResponse: %s", err, respStr)
+	var lastErr error
+	for _, m := range models {
+		for attempt := 0; attempt < maxModelAttempts; attempt++ {
+			if attempt > 0 {
+				// Exponential backoff: 1s, 2s
+				backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+				slog.Warn("LLM call failed, retrying", "model", m, "attempt", attempt, "backoff", backoff, "error", lastErr)
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					return m, nil, ctx.Err()
+				}
+			}
+			ReportProgress(ctx, Progress{Stage: StageModelCall, Model: m})
+			r := *req
+			r.OnTokens = tokenReporter(ctx, m)
+			usage, err := e.client.GenerateJSON(ctx, m, &r, resp)
+			reportUsage(ctx, m, usage)
+			if err == nil {
+				return m, usage, nil
+			}
+			lastErr = err
+			if !errors.Is(err, llmclient.ErrUnavailable) {
+				return m, usage, err
+			}
+		}
+		slog.Warn("LLM model unavailable, falling back", "model", m, "error", lastErr)
 	}
-
-	return usage, nil
+	return models[len(models)-1], nil, fmt.Errorf("all models failed: %w", lastErr)
 }
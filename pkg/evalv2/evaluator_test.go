@@ -0,0 +1,20 @@
+package evalv2
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEvaluateV2RecordsModel(t *testing.T) {
+	ec := &EvalContext{Checkpoints: []Checkpoint{{ID: "S1", Tier: 1, Weight: 1}}}
+	c := &cannedClient{response: `[{"provider": "p", "checkpoint_results": [{"id": "S1", "status": "Pass"}]}]`}
+	e := NewEvaluator(c, "gen", "eval")
+
+	report, _, err := e.EvaluateV2(context.Background(), ec, map[string]string{"p": "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Results["p"].Model; got != "eval" {
+		t.Errorf("Model = %q, want eval", got)
+	}
+}
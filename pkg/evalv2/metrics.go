@@ -0,0 +1,37 @@
+package evalv2
+
+// phoneticScore returns the P score, one minus the phonetic error rate of
+// d over n tokens, floored at 0.
+func phoneticScore(d PhoneticDetails, n int) float64 {
+	per := float64(d.Ins+d.Del+d.Sub) / float64(n)
+	return max(1-per, 0)
+}
+
+// RecomputeMetrics derives the S and P scores of r again from its
+// checkpoint results and phonetic error counts against ctx, so changes to
+// the scoring apply to stored results without asking the judges again.
+// Each judge verdict is recomputed too, and a consensus result's P is the
+// mean of its verdicts'. Scores that cannot be derived are kept: S when
+// ctx has no checkpoints, P when it has no token estimate. QScore is left
+// for the caller to recompute.
+func (r *EvalResult) RecomputeMetrics(ctx *EvalContext) {
+	recompute := func(m *EvalMetrics, results map[string]CheckpointResult, tr *Truncation) {
+		if len(ctx.Checkpoints) > 0 {
+			m.SScore = scoreCheckpoints(results, ctx, tr)
+		}
+		if n := ctx.Meta.TotalTokenCountEstimate; n > 0 {
+			m.PScore = phoneticScore(m.PhoneticDetails, n)
+		}
+	}
+	recompute(&r.Metrics, r.CheckpointResults, r.Truncation)
+	if len(r.Verdicts) == 0 {
+		return
+	}
+	pScore := 0.0
+	for i := range r.Verdicts {
+		v := &r.Verdicts[i]
+		recompute(&v.Metrics, v.CheckpointResults, v.Truncation)
+		pScore += v.Metrics.PScore
+	}
+	r.Metrics.PScore = pScore / float64(len(r.Verdicts))
+}
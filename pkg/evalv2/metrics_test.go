@@ -0,0 +1,49 @@
+package evalv2
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRecomputeMetrics(t *testing.T) {
+	ctx := &EvalContext{
+		Meta: ContextMeta{TotalTokenCountEstimate: 20},
+		Checkpoints: []Checkpoint{
+			{ID: "S1", Tier: 1, Weight: 0.5},
+			{ID: "S2", Tier: 2, Weight: 0.5},
+		},
+	}
+	r := &EvalResult{
+		CheckpointResults: map[string]CheckpointResult{
+			"S1": {Status: StatusPass},
+			"S2": {Status: StatusPartial},
+		},
+		Metrics: EvalMetrics{SScore: 0.1, PScore: 0.1, PhoneticDetails: PhoneticDetails{Sub: 1, Del: 1}},
+		Verdicts: []JudgeVerdict{
+			{Metrics: EvalMetrics{PhoneticDetails: PhoneticDetails{Sub: 1, Del: 1}}},
+			{Metrics: EvalMetrics{PhoneticDetails: PhoneticDetails{Ins: 4}}, CheckpointResults: map[string]CheckpointResult{"S1": {Status: StatusPass}}},
+		},
+	}
+	r.RecomputeMetrics(ctx)
+	for _, tc := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"S", r.Metrics.SScore, 0.75},
+		{"P", r.Metrics.PScore, 0.85}, // Mean of the verdicts' 0.9 and 0.8
+		{"verdict 0 S", r.Verdicts[0].Metrics.SScore, 0},
+		{"verdict 1 S", r.Verdicts[1].Metrics.SScore, 0.5},
+		{"verdict 1 P", r.Verdicts[1].Metrics.PScore, 0.8},
+	} {
+		if math.Abs(tc.got-tc.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+
+	// Nothing to derive from keeps the stored scores.
+	r = &EvalResult{Metrics: EvalMetrics{SScore: 0.6, PScore: 0.7}}
+	r.RecomputeMetrics(&EvalContext{})
+	if r.Metrics.SScore != 0.6 || r.Metrics.PScore != 0.7 {
+		t.Errorf("metrics = %+v, want S 0.6 and P 0.7 kept", r.Metrics)
+	}
+}
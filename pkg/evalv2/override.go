@@ -0,0 +1,44 @@
+package evalv2
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// CheckpointOverride records a reviewer's adjudication of a checkpoint.
+type CheckpointOverride struct {
+	OriginalStatus CheckpointStatus `json:"original_status"` // Status the judge gave
+	Reason         string           `json:"reason"`
+	Author         string           `json:"author,omitempty"`
+	Time           time.Time        `json:"time"`
+}
+
+// OverrideCheckpoint sets the status of checkpoint id in r and recomputes
+// the S score against ctx. The judge's original status is kept across
+// repeated overrides. QScore is left for the caller to recompute.
+func (r *EvalResult) OverrideCheckpoint(ctx *EvalContext, id string, status CheckpointStatus, o CheckpointOverride) error {
+	if !slices.ContainsFunc(ctx.Checkpoints, func(cp Checkpoint) bool { return cp.ID == id }) {
+		return fmt.Errorf("unknown checkpoint: %s", id)
+	}
+	if r.Truncation.dropped(id) {
+		return fmt.Errorf("checkpoint %s was dropped from the prompt and not judged", id)
+	}
+	if _, ok := statusRank[status]; !ok {
+		return fmt.Errorf("invalid status: %q", status)
+	}
+
+	if r.CheckpointResults == nil {
+		r.CheckpointResults = make(map[string]CheckpointResult)
+	}
+	res := r.CheckpointResults[id]
+	o.OriginalStatus = res.Status
+	if res.Override != nil {
+		o.OriginalStatus = res.Override.OriginalStatus
+	}
+	res.Status = status
+	res.Override = &o
+	r.CheckpointResults[id] = res
+	r.Metrics.SScore = scoreCheckpoints(r.CheckpointResults, ctx, r.Truncation)
+	return nil
+}
@@ -0,0 +1,46 @@
+package evalv2
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOverrideCheckpoint(t *testing.T) {
+	ctx := &EvalContext{
+		Checkpoints: []Checkpoint{
+			{ID: "S1", Tier: 1, Weight: 0.5},
+			{ID: "S2", Tier: 2, Weight: 0.5},
+		},
+	}
+	r := &EvalResult{
+		CheckpointResults: map[string]CheckpointResult{
+			"S1": {Status: StatusFail, Reason: "missed amount"},
+			"S2": {Status: StatusPass},
+		},
+		Metrics: EvalMetrics{SScore: 0.5, PScore: 0.9},
+	}
+
+	if err := r.OverrideCheckpoint(ctx, "S1", StatusPass, CheckpointOverride{Reason: "judge misheard"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Metrics.SScore; math.Abs(got-1) > 1e-9 {
+		t.Errorf("SScore = %v, want 1", got)
+	}
+	if err := r.OverrideCheckpoint(ctx, "S1", StatusPartial, CheckpointOverride{Reason: "half right"}); err != nil {
+		t.Fatal(err)
+	}
+	got := r.CheckpointResults["S1"]
+	if got.Status != StatusPartial || got.Override.OriginalStatus != StatusFail || got.Override.Reason != "half right" {
+		t.Errorf("S1 = %+v, override %+v", got, got.Override)
+	}
+	if got := r.Metrics.SScore; math.Abs(got-0.75) > 1e-9 {
+		t.Errorf("SScore = %v, want 0.75", got)
+	}
+
+	if err := r.OverrideCheckpoint(ctx, "S9", StatusPass, CheckpointOverride{}); err == nil {
+		t.Error("OverrideCheckpoint(S9) succeeded, want error")
+	}
+	if err := r.OverrideCheckpoint(ctx, "S1", "Maybe", CheckpointOverride{}); err == nil {
+		t.Error("OverrideCheckpoint(Maybe) succeeded, want error")
+	}
+}
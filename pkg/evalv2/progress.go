@@ -0,0 +1,62 @@
+package evalv2
+
+import "context"
+
+// Progress stages reported during GenerateContext and Evaluate.
+const (
+	StagePromptBuilt       = "prompt_built"
+	StageModelCall         = "model_call"
+	StageGenerating        = "generating"
+	StageProviderEvaluated = "provider_evaluated" // The judge's verdict on Provider, Done of Total, is parsed
+	StageStepStarted       = "step_started"       // A multi-step caller began Step
+)
+
+// Progress describes a stage reached by a long-running evaluator call.
+type Progress struct {
+	Stage        string `json:"stage"`
+	Step         string `json:"step,omitempty"` // Set through WithStep
+	Model        string `json:"model,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	Done         int    `json:"done,omitempty"`  // Providers evaluated so far
+	Total        int    `json:"total,omitempty"` // Providers to evaluate
+	OutputTokens int    `json:"output_tokens,omitempty"`
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context that delivers evaluator progress to fn.
+// fn may be called from multiple goroutines.
+func WithProgress(ctx context.Context, fn func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// WithStep labels the progress reported through ctx with step, for callers
+// that chain several evaluator calls.
+func WithStep(ctx context.Context, step string) context.Context {
+	fn, ok := ctx.Value(progressKey{}).(func(Progress))
+	if !ok {
+		return ctx
+	}
+	return WithProgress(ctx, func(p Progress) {
+		p.Step = step
+		fn(p)
+	})
+}
+
+// ReportProgress delivers p to the function installed by WithProgress.
+func ReportProgress(ctx context.Context, p Progress) {
+	if fn, ok := ctx.Value(progressKey{}).(func(Progress)); ok {
+		fn(p)
+	}
+}
+
+// tokenReporter returns an llmclient token callback that reports progress
+// for model, or nil when nobody is listening.
+func tokenReporter(ctx context.Context, model string) func(int) {
+	if _, ok := ctx.Value(progressKey{}).(func(Progress)); !ok {
+		return nil
+	}
+	return func(n int) {
+		ReportProgress(ctx, Progress{Stage: StageGenerating, Model: model, OutputTokens: n})
+	}
+}
@@ -2,6 +2,8 @@ package evalv2
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -151,6 +153,8 @@ type evaluatePromptData struct {
 
 // buildEvaluatePrompt constructs the prompt string for evaluation
 func buildEvaluatePrompt(d evaluatePromptData) (string, error) {
+	// Judges score text; the times Align added are no use to them.
+	d.EvalContext = d.EvalContext.Unaligned()
 	var buf bytes.Buffer
 	if err := evaluatePromptTemplate.Execute(&buf, d); err != nil {
 		return "", fmt.Errorf("failed to execute evaluate template: %w", err)
@@ -200,9 +204,52 @@ Evaluate all transcripts one by one. Output JSON matching the LLMEvalResult stru
 
 // buildEvaluatePromptV2 constructs the prompt string for evaluation V2
 func buildEvaluatePromptV2(d evaluatePromptData) (string, error) {
+	d.EvalContext = d.EvalContext.Unaligned()
 	var buf bytes.Buffer
 	if err := evaluatePromptTemplateV2.Execute(&buf, d); err != nil {
 		return "", fmt.Errorf("failed to execute evaluateV2 template: %w", err)
 	}
 	return buf.String(), nil
 }
+
+// Prompt template for Align
+var alignPromptTemplate = template.Must(template.New("align").Funcs(funcMap).Parse(`
+You are an expert phonetician who times speech in recordings to the millisecond.
+
+Listen to the provided Audio and find where each of the following is spoken.
+
+### Checkpoints
+
+{{range .Checkpoints}}- {{.ID}}: {{.TextSegment}}
+{{end}}
+### Audio Reality
+
+{{.Meta.AudioRealityInference | prefix "> "}}
+
+### Task
+
+1. Time **every** checkpoint: "start_ms" is where its first word begins and "end_ms" where its last word ends, in milliseconds from the start of the Audio.
+   - The speaker may say it differently from the text; time what is said in its place.
+   - If it is not said at all, give the time where it would have been said as both start_ms and end_ms.
+1. Split the Audio Reality into consecutive phrases of at most a few seconds each and time each phrase the same way.
+   - Keep the text of the phrases verbatim; together, in order, they must make up the whole Audio Reality.
+`))
+
+// buildAlignPrompt constructs the prompt string for alignment
+func buildAlignPrompt(ec *EvalContext) (string, error) {
+	var buf bytes.Buffer
+	if err := alignPromptTemplate.Execute(&buf, ec); err != nil {
+		return "", fmt.Errorf("failed to execute align template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// PromptVersion identifies the prompt templates in this build: a short hash
+// of their text, which changes with any prompt edit.
+var PromptVersion = func() string {
+	h := sha256.New()
+	for _, t := range []*template.Template{generateContextPromptTemplate, evaluatePromptTemplate, evaluatePromptTemplateV2} {
+		h.Write([]byte(t.Tree.Root.String()))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:6])
+}()
@@ -1,96 +1,155 @@
 package evalv2
 
 import (
-	"reflect"
-	"strings"
-	"sync"
-
-	"google.golang.org/genai"
+	"bytes"
+	"encoding/json"
+	"fmt"
 )
 
-var (
-	schemaCache   = make(map[reflect.Type]*genai.Schema)
-	schemaCacheMu sync.Mutex
-)
+// SchemaVersion is the schema version of the EvalContext and EvalReport
+// documents this package writes. Documents without a schema_version are
+// version 0.
+const SchemaVersion = 2
+
+// Migration upgrades a decoded JSON document by one schema version in
+// place. Numbers in doc are json.Number.
+type Migration func(doc map[string]any) error
+
+// contextMigrations[v] upgrades an EvalContext document from version v to
+// v+1. A migration is appended with each SchemaVersion bump, and never
+// changed once released.
+var contextMigrations = []Migration{
+	// 0 -> 1: schema_version is introduced; the layout is unchanged.
+	func(doc map[string]any) error { return nil },
+	// 1 -> 2: a context may be a reference, its hash alone, to be looked up
+	// by the reader; a full context is still valid.
+	func(doc map[string]any) error { return nil },
+}
+
+// reportMigrations[v] upgrades an EvalReport document from version v to
+// v+1. Its context snapshot is migrated by contextMigrations on its own
+// version.
+var reportMigrations = []Migration{
+	// 0 -> 1: schema_version is introduced; the layout is unchanged.
+	func(doc map[string]any) error { return nil },
+	// 1 -> 2: the context snapshot may be a reference, as in contexts.
+	func(doc map[string]any) error { return nil },
+}
 
-// reflectSchema converts a Go type to a genai.Schema using reflection, with caching.
-func reflectSchema(t reflect.Type) *genai.Schema {
-	for t.Kind() == reflect.Ptr {
-		t = t.Elem()
+func init() {
+	if len(contextMigrations) != SchemaVersion || len(reportMigrations) != SchemaVersion {
+		panic("evalv2: a migration is missing for SchemaVersion")
 	}
+}
 
-	schemaCacheMu.Lock()
-	if cached, ok := schemaCache[t]; ok {
-		schemaCacheMu.Unlock()
-		return cached
+// UnmarshalContext decodes an EvalContext document of any schema version
+// up to SchemaVersion, upgrading it first when it is older.
+func UnmarshalContext(data []byte) (*EvalContext, error) {
+	data, _, err := MigrateContext(data)
+	if err != nil {
+		return nil, err
 	}
-	schemaCacheMu.Unlock()
+	var c EvalContext
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
 
-	schema := reflectSchemaInner(t)
+// UnmarshalReport decodes an EvalReport document of any schema version up
+// to SchemaVersion, upgrading it first when it is older.
+func UnmarshalReport(data []byte) (*EvalReport, error) {
+	data, _, err := MigrateReport(data)
+	if err != nil {
+		return nil, err
+	}
+	var r EvalReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
 
-	schemaCacheMu.Lock()
-	schemaCache[t] = schema
-	schemaCacheMu.Unlock()
-	return schema
+// MigrateContext upgrades an EvalContext document to SchemaVersion. It
+// returns data unchanged, and false, when it is already current.
+func MigrateContext(data []byte) ([]byte, bool, error) {
+	return migrate(data, func(doc map[string]any) (bool, error) {
+		return upgrade(doc, contextMigrations)
+	})
 }
 
-// reflectSchemaInner contains the core reflection logic without cache awareness.
-func reflectSchemaInner(t reflect.Type) *genai.Schema {
-	switch t.Kind() {
-	case reflect.Slice, reflect.Array:
-		return &genai.Schema{
-			Type:  genai.TypeArray,
-			Items: reflectSchemaInner(t.Elem()),
-		}
-	case reflect.Struct:
-		s := &genai.Schema{
-			Type:       genai.TypeObject,
-			Properties: make(map[string]*genai.Schema),
+// MigrateReport upgrades an EvalReport document, and its context snapshot,
+// to SchemaVersion. It returns data unchanged, and false, when both are
+// already current.
+func MigrateReport(data []byte) ([]byte, bool, error) {
+	return migrate(data, func(doc map[string]any) (bool, error) {
+		changed, err := upgrade(doc, reportMigrations)
+		if err != nil {
+			return false, err
 		}
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == "" || jsonTag == "-" {
-				continue
+		if snap, ok := doc["context_snapshot"].(map[string]any); ok {
+			c, err := upgrade(snap, contextMigrations)
+			if err != nil {
+				return false, fmt.Errorf("context_snapshot: %w", err)
 			}
-			name := strings.Split(jsonTag, ",")[0]
-			ps := reflectSchemaInner(field.Type)
+			changed = changed || c
+		}
+		return changed, nil
+	})
+}
 
-			// Handle custom 'jsonscheme' tag for enums or other constraints
-			if jsTag := field.Tag.Get("jsonscheme"); jsTag != "" {
-				applyJSONScheme(ps, jsTag)
-			}
+func migrate(data []byte, up func(doc map[string]any) (bool, error)) ([]byte, bool, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false, err
+	}
+	changed, err := up(doc)
+	if err != nil || !changed {
+		return data, false, err
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
 
-			s.Properties[name] = ps
-			if !strings.Contains(jsonTag, "omitempty") {
-				s.Required = append(s.Required, name)
-			}
+// upgrade applies migrations to doc from its schema_version on, and stamps
+// the new version.
+func upgrade(doc map[string]any, migrations []Migration) (bool, error) {
+	v, err := schemaVersion(doc)
+	if err != nil {
+		return false, err
+	}
+	if v > len(migrations) {
+		return false, fmt.Errorf("schema version %d is newer than supported version %d", v, len(migrations))
+	}
+	if v == len(migrations) {
+		return false, nil
+	}
+	for ; v < len(migrations); v++ {
+		if err := migrations[v](doc); err != nil {
+			return false, fmt.Errorf("migrate schema version %d: %w", v, err)
 		}
-		return s
-	case reflect.String:
-		return &genai.Schema{Type: genai.TypeString}
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return &genai.Schema{Type: genai.TypeInteger}
-	case reflect.Float32, reflect.Float64:
-		return &genai.Schema{Type: genai.TypeNumber}
-	case reflect.Bool:
-		return &genai.Schema{Type: genai.TypeBoolean}
-	default:
-		panic("unsupported type for schema generation: " + t.String())
 	}
+	doc["schema_version"] = json.Number(fmt.Sprint(v))
+	return true, nil
 }
 
-func applyJSONScheme(s *genai.Schema, tag string) {
-	p := strings.Split(tag, ";")
-	for _, part := range p {
-		if strings.HasPrefix(part, "enum:") {
-			enumVals := strings.Split(strings.TrimPrefix(part, "enum:"), ",")
-			target := s
-			// If applied to a Slice/Array field, apply the enum to the Items
-			if s.Type == genai.TypeArray && s.Items != nil {
-				target = s.Items
-			}
-			target.Enum = enumVals
-		}
+func schemaVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["schema_version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid schema_version: %v", raw)
+	}
+	v, err := n.Int64()
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid schema_version: %v", raw)
 	}
+	return int(v), nil
 }
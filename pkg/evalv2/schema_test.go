@@ -1,132 +1,33 @@
 package evalv2
 
 import (
-	"reflect"
+	"fmt"
 	"testing"
-
-	"github.com/google/go-cmp/cmp"
-	"google.golang.org/genai"
 )
 
-func TestReflectSchema(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    interface{}
-		expected *genai.Schema
-	}{
-		{
-			name:  "basic string",
-			input: "",
-			expected: &genai.Schema{
-				Type: genai.TypeString,
-			},
-		},
-		{
-			name:  "basic int",
-			input: 0,
-			expected: &genai.Schema{
-				Type: genai.TypeInteger,
-			},
-		},
-		{
-			name: "struct with json tags",
-			input: struct {
-				Name string `json:"name"`
-				Age  int    `json:"age,omitempty"`
-			}{},
-			expected: &genai.Schema{
-				Type: genai.TypeObject,
-				Properties: map[string]*genai.Schema{
-					"name": {Type: genai.TypeString},
-					"age":  {Type: genai.TypeInteger},
-				},
-				Required: []string{"name"},
-			},
-		},
-		{
-			name: "enum tag",
-			input: struct {
-				Status string `json:"status" jsonscheme:"enum:A,B,C"`
-			}{},
-			expected: &genai.Schema{
-				Type: genai.TypeObject,
-				Properties: map[string]*genai.Schema{
-					"status": {
-						Type: genai.TypeString,
-						Enum: []string{"A", "B", "C"},
-					},
-				},
-				Required: []string{"status"},
-			},
-		},
-		{
-			name: "slice of enums",
-			input: struct {
-				Tags []string `json:"tags" jsonscheme:"enum:fast,stable"`
-			}{},
-			expected: &genai.Schema{
-				Type: genai.TypeObject,
-				Properties: map[string]*genai.Schema{
-					"tags": {
-						Type: genai.TypeArray,
-						Items: &genai.Schema{
-							Type: genai.TypeString,
-							Enum: []string{"fast", "stable"},
-						},
-					},
-				},
-				Required: []string{"tags"},
-			},
-		},
+func TestMigrateReport(t *testing.T) {
+	old := []byte(`{"evaluations":{"dg":{"metrics":{"S_score":0.9,"P_score":0.8}}},"context_snapshot":{"meta":{"total_token_count_estimate":12},"hash":"h"}}`)
+	out, changed, err := MigrateReport(old)
+	if err != nil || !changed {
+		t.Fatalf("MigrateReport(v0) = %v, %v, want upgraded", changed, err)
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			actual := reflectSchema(reflect.TypeOf(tt.input))
-			if diff := cmp.Diff(tt.expected, actual); diff != "" {
-				t.Errorf("reflectSchema() mismatch (-want +got):\n%s", diff)
-			}
-		})
+	r, err := UnmarshalReport(out)
+	if err != nil {
+		t.Fatal(err)
 	}
-}
-
-func TestSchemaCache(t *testing.T) {
-	type CacheTest struct {
-		ID int `json:"id"`
+	if r.SchemaVersion != SchemaVersion || r.ContextSnapshot.SchemaVersion != SchemaVersion {
+		t.Errorf("versions = %d, %d, want %d", r.SchemaVersion, r.ContextSnapshot.SchemaVersion, SchemaVersion)
 	}
-	typ := reflect.TypeOf(CacheTest{})
-
-	s1 := reflectSchema(typ)
-	s2 := reflectSchema(typ)
-
-	if s1 != s2 {
-		t.Error("expected cached schema instances to be the same pointer")
+	if r.Results["dg"].Metrics.SScore != 0.9 || r.ContextSnapshot.Meta.TotalTokenCountEstimate != 12 {
+		t.Errorf("content lost in migration: %+v", r)
 	}
-}
 
-func TestReflectSchema_Panic(t *testing.T) {
-	tests := []struct {
-		name  string
-		input interface{}
-	}{
-		{
-			name:  "map type",
-			input: map[string]string{},
-		},
-		{
-			name:  "unsupported complex64",
-			input: complex64(0),
-		},
+	if _, changed, err := MigrateReport(out); err != nil || changed {
+		t.Errorf("MigrateReport(current) = %v, %v, want unchanged", changed, err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			defer func() {
-				if r := recover(); r == nil {
-					t.Errorf("expected panic for %v, but did not panic", tt.name)
-				}
-			}()
-			reflectSchema(reflect.TypeOf(tt.input))
-		})
+	newer := []byte(fmt.Sprintf(`{"schema_version":%d}`, SchemaVersion+1))
+	if _, err := UnmarshalContext(newer); err == nil {
+		t.Error("UnmarshalContext succeeded on a newer schema version, want error")
 	}
 }
@@ -0,0 +1,149 @@
+package evalv2
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+
+	"asr-eval/pkg/llmclient"
+)
+
+// truncationMarker replaces the middle of a shortened transcript.
+const truncationMarker = " …[truncated]… "
+
+// maxFitRounds bounds the number of CountTokens calls spent shrinking a prompt.
+const maxFitRounds = 4
+
+// SetMaxPromptTokens sets the context window budget for evaluation prompts.
+// Zero disables the pre-count.
+func (e *Evaluator) SetMaxPromptTokens(n int) {
+	e.maxPromptTokens = n
+}
+
+// fitPrompt builds the prompt for d and, if it exceeds the token budget,
+// shrinks it deterministically: first dropping Tier-3 checkpoints from the
+// lowest weight up, then shortening every transcript around an ellipsis
+// marker. It returns nil Truncation when the prompt fits as is. Tokenizers
+// spend at most about one token per rune of text, so a prompt with no more
+// runes than the budget is sent without asking the model to count it.
+func (e *Evaluator) fitPrompt(ctx context.Context, model string, build func(evaluatePromptData) (string, error), d evaluatePromptData) (string, *Truncation, error) {
+	p, err := build(d)
+	if err != nil || e.maxPromptTokens <= 0 || len([]rune(p)) <= e.maxPromptTokens {
+		return p, nil, err
+	}
+	n, err := e.client.CountTokens(ctx, model, &llmclient.Request{Text: p})
+	if err != nil {
+		return "", nil, err
+	}
+	if n <= e.maxPromptTokens {
+		return p, nil, nil
+	}
+
+	tr := &Truncation{}
+	evalCtx := *d.EvalContext
+	transcripts := d.Transcripts
+
+	// Drop Tier-3 checkpoints, estimating tokens from the prompt length.
+	perRune := float64(n) / float64(len([]rune(p)))
+	for _, cp := range tier3ByWeight(evalCtx.Checkpoints) {
+		if estimateTokens(p, perRune) <= e.maxPromptTokens {
+			break
+		}
+		evalCtx.Checkpoints = without(evalCtx.Checkpoints, cp.ID)
+		tr.DroppedCheckpoints = append(tr.DroppedCheckpoints, cp.ID)
+		if p, err = build(evaluatePromptData{EvalContext: &evalCtx, Transcripts: transcripts}); err != nil {
+			return "", nil, err
+		}
+	}
+
+	for round := 0; round < maxFitRounds; round++ {
+		n, err = e.client.CountTokens(ctx, model, &llmclient.Request{Text: p})
+		if err != nil {
+			return "", nil, err
+		}
+		if n <= e.maxPromptTokens {
+			tr.PromptTokens = n
+			slog.Warn("Evaluation prompt truncated", "model", model, "dropped_checkpoints", tr.DroppedCheckpoints, "truncated_transcripts", len(tr.TruncatedTranscripts), "prompt_tokens", n)
+			return p, tr, nil
+		}
+
+		// Shrink all transcripts by the share of tokens they need to give up.
+		total := 0
+		for _, t := range transcripts {
+			total += len([]rune(t))
+		}
+		excess := float64(n-e.maxPromptTokens) / (float64(n) / float64(len([]rune(p))))
+		if total == 0 || excess >= float64(total) {
+			break
+		}
+		keep := 1 - excess/float64(total)
+		transcripts = shortenTranscripts(transcripts, keep, tr)
+		if p, err = build(evaluatePromptData{EvalContext: &evalCtx, Transcripts: transcripts}); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return "", nil, fmt.Errorf("prompt has %d tokens, exceeds budget of %d after truncation", n, e.maxPromptTokens)
+}
+
+// dropped reports whether checkpoint id was left out of the prompt. A nil
+// Truncation dropped nothing.
+func (t *Truncation) dropped(id string) bool {
+	return t != nil && slices.Contains(t.DroppedCheckpoints, id)
+}
+
+// tier3ByWeight returns Tier-3 checkpoints in ascending weight, ties by ID.
+func tier3ByWeight(cps []Checkpoint) []Checkpoint {
+	var out []Checkpoint
+	for _, cp := range cps {
+		if cp.Tier == 3 {
+			out = append(out, cp)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Weight != out[j].Weight {
+			return out[i].Weight < out[j].Weight
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func without(cps []Checkpoint, id string) []Checkpoint {
+	out := make([]Checkpoint, 0, len(cps))
+	for _, cp := range cps {
+		if cp.ID != id {
+			out = append(out, cp)
+		}
+	}
+	return out
+}
+
+func estimateTokens(p string, perRune float64) int {
+	return int(float64(len([]rune(p))) * perRune)
+}
+
+// shortenTranscripts keeps the head and tail of each transcript so that
+// roughly keep of its runes survive, recording original lengths in tr.
+func shortenTranscripts(transcripts map[string]string, keep float64, tr *Truncation) map[string]string {
+	if tr.TruncatedTranscripts == nil {
+		tr.TruncatedTranscripts = make(map[string]int)
+	}
+	out := make(map[string]string, len(transcripts))
+	for k, t := range transcripts {
+		r := []rune(t)
+		n := int(float64(len(r)) * keep)
+		if n >= len(r) {
+			out[k] = t
+			continue
+		}
+		if _, ok := tr.TruncatedTranscripts[k]; !ok {
+			tr.TruncatedTranscripts[k] = len(r)
+		}
+		head := n / 2
+		out[k] = string(r[:head]) + truncationMarker + string(r[len(r)-(n-head):])
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package evalv2
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/llmclient"
+)
+
+// runeCounter is a cannedClient that counts one token per rune.
+type runeCounter struct {
+	cannedClient
+}
+
+func (c *runeCounter) CountTokens(ctx context.Context, model string, req *llmclient.Request) (int, error) {
+	return len([]rune(req.Text)), nil
+}
+
+func TestTruncatedCheckpointsStayOutOfS(t *testing.T) {
+	ec := &EvalContext{
+		Meta: ContextMeta{TotalTokenCountEstimate: 10},
+		Checkpoints: []Checkpoint{
+			{ID: "S1", Tier: 1, Weight: 0.5, TextSegment: "send forty dollars"},
+			{ID: "S2", Tier: 2, Weight: 0.3, TextSegment: "to Bob"},
+			{ID: "S3", Tier: 3, Weight: 0.2, TextSegment: "thanks"},
+		},
+	}
+	transcripts := map[string]string{"p": "send forty dollars to rob"}
+
+	// Budget the prompt that leaves S3 out.
+	fit := *ec
+	fit.Checkpoints = ec.Checkpoints[:2]
+	p, err := buildEvaluatePrompt(evaluatePromptData{EvalContext: &fit, Transcripts: transcripts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &runeCounter{cannedClient{response: `[{
+		"provider": "p",
+		"metrics": {"s_score": 0.4},
+		"checkpoint_results": [
+			{"id": "S1", "status": "Pass"},
+			{"id": "S2", "status": "Fail"}
+		]
+	}]`}}
+	e := NewEvaluator(c, "gen", "eval")
+	e.SetMaxPromptTokens(len([]rune(p)))
+
+	report, _, err := e.Evaluate(context.Background(), ec, transcripts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := report.Results["p"]
+	if r.Truncation == nil {
+		t.Fatal("Truncation = nil, want S3 dropped")
+	}
+	if diff := cmp.Diff([]string{"S3"}, r.Truncation.DroppedCheckpoints); diff != "" {
+		t.Fatalf("dropped checkpoints mismatch (-want +got):\n%s", diff)
+	}
+
+	r.RecomputeMetrics(ec)
+	if got, want := r.Metrics.SScore, 0.5/0.8; math.Abs(got-want) > 1e-9 {
+		t.Errorf("recomputed SScore = %v, want %v", got, want)
+	}
+	if err := r.OverrideCheckpoint(ec, "S2", StatusPass, CheckpointOverride{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Metrics.SScore; math.Abs(got-1) > 1e-9 {
+		t.Errorf("overridden SScore = %v, want 1", got)
+	}
+	if err := r.OverrideCheckpoint(ec, "S3", StatusPass, CheckpointOverride{}); err == nil {
+		t.Error("OverrideCheckpoint(S3) succeeded on a dropped checkpoint")
+	}
+
+	// A consensus leaves out only what every judge dropped.
+	verdicts := []JudgeVerdict{
+		{CheckpointResults: map[string]CheckpointResult{"S1": {Status: StatusPass}, "S2": {Status: StatusPass}}, Truncation: r.Truncation},
+		{CheckpointResults: map[string]CheckpointResult{"S1": {Status: StatusPass}, "S2": {Status: StatusPass}, "S3": {Status: StatusFail}}},
+	}
+	merged := mergeVerdicts(r, verdicts, ec)
+	if got := merged.Truncation.DroppedCheckpoints; len(got) != 0 {
+		t.Errorf("merged dropped checkpoints = %v, want none", got)
+	}
+	if got := merged.Metrics.SScore; math.Abs(got-0.8) > 1e-9 {
+		t.Errorf("merged SScore = %v, want 0.8", got)
+	}
+	verdicts[1].Truncation = r.Truncation
+	verdicts[1].CheckpointResults = verdicts[0].CheckpointResults
+	merged = mergeVerdicts(r, verdicts, ec)
+	merged.RecomputeMetrics(ec)
+	if got := merged.Metrics.SScore; math.Abs(got-1) > 1e-9 {
+		t.Errorf("merged SScore with S3 dropped by all = %v, want 1", got)
+	}
+}
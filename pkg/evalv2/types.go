@@ -4,13 +4,19 @@ import "math"
 
 // EvalContext represents the output of Step 1 ([id].gt.v2.json)
 type EvalContext struct {
-	Meta        ContextMeta  `json:"meta"`
-	Checkpoints []Checkpoint `json:"checkpoints"`
-	Hash        string       `json:"hash,omitempty"` // Output only
+	SchemaVersion int          `json:"schema_version,omitempty"` // See SchemaVersion
+	Meta          ContextMeta  `json:"meta"`
+	Checkpoints   []Checkpoint `json:"checkpoints"`
+	Hash          string       `json:"hash,omitempty"` // Output only
+
+	// AudioRealitySpans is Meta.AudioRealityInference timed in the audio by
+	// Align, in order.
+	AudioRealitySpans []TimedText `json:"audio_reality_spans,omitempty" jsonscheme:"-"`
 }
 
 // EvalReport represents the output of Step 2 ([id].report.v2.json)
 type EvalReport struct {
+	SchemaVersion   int                   `json:"schema_version,omitempty"` // See SchemaVersion
 	Results         map[string]EvalResult `json:"evaluations"`
 	ContextSnapshot EvalContext           `json:"context_snapshot,omitempty"`
 }
@@ -30,6 +36,8 @@ type EvalResult2 struct {
 	CheckpointResults map[string]CheckpointResult `json:"checkpoint_results"`
 	PhoneticAnalysis  PhoneticAnalysis            `json:"phonetic_analysis"`
 	Summary           []string                    `json:"summary"`
+	Model             string                      `json:"model,omitempty"`      // Judge model that produced this result
+	Truncation        *Truncation                 `json:"truncation,omitempty"` // Set when the prompt was shrunk to fit
 }
 
 // ContextMeta contains metadata for the context
@@ -40,6 +48,7 @@ type ContextMeta struct {
 	GroundTruth             string `json:"ground_truth"`
 	QuestionableGT          bool   `json:"questionable_gt"`
 	QuestionableReason      string `json:"questionable_reason"`
+	AlignModel              string `json:"align_model,omitempty" jsonscheme:"-"` // Model that ran Align; empty when unaligned
 }
 
 // Checkpoint represents a hierarchical evaluation point
@@ -50,6 +59,20 @@ type Checkpoint struct {
 	Tier        int     `json:"tier"`
 	Weight      float64 `json:"weight"`
 	Rationale   string  `json:"rationale"`
+	Span        *Span   `json:"span,omitempty" jsonscheme:"-"` // Where it is said, as timed by Align; StartMS is only the generating model's estimate
+}
+
+// Span is a stretch of the audio.
+type Span struct {
+	StartMS int `json:"start_ms"`
+	EndMS   int `json:"end_ms"`
+}
+
+// TimedText is what is said in a stretch of the audio.
+type TimedText struct {
+	StartMS int    `json:"start_ms"`
+	EndMS   int    `json:"end_ms"`
+	Text    string `json:"text"`
 }
 
 // EvalResult represents the evaluation result for a single model (Map based)
@@ -59,6 +82,21 @@ type EvalResult struct {
 	Metrics           EvalMetrics                 `json:"metrics"`
 	CheckpointResults map[string]CheckpointResult `json:"checkpoint_results"`
 	Summary           []string                    `json:"summary"`
+	Model             string                      `json:"model,omitempty"`      // Judge model that produced this result
+	Truncation        *Truncation                 `json:"truncation,omitempty"` // Set when the prompt was shrunk to fit
+
+	// Consensus evaluation only
+	Verdicts     []JudgeVerdict `json:"verdicts,omitempty"`
+	Disputed     []string       `json:"disputed,omitempty"`      // Checkpoint IDs the judges disagree on
+	FailedJudges []string       `json:"failed_judges,omitempty"` // Judges left out of the consensus after failing
+}
+
+// JudgeVerdict holds one judge model's raw verdict in a consensus evaluation
+type JudgeVerdict struct {
+	Model             string                      `json:"model"`
+	Metrics           EvalMetrics                 `json:"metrics"`
+	CheckpointResults map[string]CheckpointResult `json:"checkpoint_results"`
+	Truncation        *Truncation                 `json:"truncation,omitempty"` // Set when this judge's prompt was shrunk to fit
 }
 
 // EvalMetrics holds various evaluation metrics
@@ -74,13 +112,18 @@ var SScoreWeight float64 = 0.7
 
 // CompositeScore calculates Q = S_score^SWeight * P_score^(1-SWeight) as a whole number (0-100)
 func (m EvalMetrics) CompositeScore() int {
+	return m.CompositeScoreWith(SScoreWeight)
+}
+
+// CompositeScoreWith is CompositeScore with an explicit S weight.
+func (m EvalMetrics) CompositeScoreWith(sWeight float64) int {
 	if math.IsNaN(m.SScore) || math.IsNaN(m.PScore) {
 		return 0
 	}
 	if m.SScore <= 0 || m.PScore <= 0 {
 		return 0
 	}
-	val := math.Pow(m.SScore, SScoreWeight) * math.Pow(m.PScore, 1-SScoreWeight)
+	val := math.Pow(m.SScore, sWeight) * math.Pow(m.PScore, 1-sWeight)
 	return int(math.Round(val * 100))
 }
 
@@ -105,6 +148,8 @@ type CheckpointResult struct {
 	Status   CheckpointStatus `json:"status"`
 	Detected string           `json:"detected"`         // text segment identified
 	Reason   string           `json:"reason,omitempty"` // Reason for failure
+
+	Override *CheckpointOverride `json:"override,omitempty"` // Set when a reviewer adjudicated the status
 }
 
 // PhoneticAnalysis holds detailed error chunks for PER calculation
@@ -113,3 +158,11 @@ type PhoneticAnalysis struct {
 	Deletions     []string `json:"deletions"`
 	Substitutions []string `json:"substitutions"`
 }
+
+// Truncation records how an evaluation prompt was shrunk to fit the judge's
+// context window.
+type Truncation struct {
+	PromptTokens         int            `json:"prompt_tokens"`                   // Tokens of the prompt actually sent
+	DroppedCheckpoints   []string       `json:"dropped_checkpoints,omitempty"`   // Tier-3 checkpoint IDs left out
+	TruncatedTranscripts map[string]int `json:"truncated_transcripts,omitempty"` // Provider -> original length in runes
+}
@@ -0,0 +1,24 @@
+package evalv2
+
+import (
+	"context"
+
+	"asr-eval/pkg/llmclient"
+)
+
+type usageKey struct{}
+
+// WithUsage returns a context that reports the token usage of every model
+// call made through it to fn, with the model that served the call. Calls
+// retried or falling back to another model are each reported. fn may be
+// called from multiple goroutines.
+func WithUsage(ctx context.Context, fn func(model string, u llmclient.Usage)) context.Context {
+	return context.WithValue(ctx, usageKey{}, fn)
+}
+
+// reportUsage reports u, if any, to the listener of ctx.
+func reportUsage(ctx context.Context, model string, u *llmclient.Usage) {
+	if fn, ok := ctx.Value(usageKey{}).(func(string, llmclient.Usage)); ok && u != nil {
+		fn(model, *u)
+	}
+}
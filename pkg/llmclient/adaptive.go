@@ -0,0 +1,180 @@
+package llmclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// AdaptiveOptions tune an Adaptive client. Limits apply to each model, as
+// quotas do.
+type AdaptiveOptions struct {
+	MaxConcurrency  int // Calls at once at most; below 1 means 1
+	TokensPerMinute int // Tokens a minute, as reported by calls; 0 is unlimited
+}
+
+// Adaptive is a Client that finds how many calls a model sustains: each
+// rate-limited call halves its concurrency and each success grows it by
+// about one call per round of calls, up to the maximum (AIMD). With a
+// token rate, calls also wait while the tokens used in the last minute
+// exceed it. Rate-limited calls are retried after a backoff. One Adaptive
+// shared by every worker of a run keeps the run at the speed its quota
+// allows.
+type Adaptive struct {
+	client Client
+	opts   AdaptiveOptions
+
+	// backoff is how long the nth retry of a rate-limited call waits.
+	backoff func(n int) time.Duration
+
+	mu     sync.Mutex
+	models map[string]*modelLimit
+}
+
+// maxRateLimitRetries bounds how often Adaptive retries a rate-limited
+// call before returning the error, for a quota that does not recover.
+const maxRateLimitRetries = 4
+
+// initialConcurrency is where a model's concurrency starts.
+const initialConcurrency = 4
+
+// NewAdaptive wraps client with adaptive concurrency.
+func NewAdaptive(client Client, opts AdaptiveOptions) *Adaptive {
+	opts.MaxConcurrency = max(opts.MaxConcurrency, 1)
+	return &Adaptive{
+		client: client,
+		opts:   opts,
+		backoff: func(n int) time.Duration {
+			return min(time.Second<<n, 30*time.Second)
+		},
+		models: make(map[string]*modelLimit),
+	}
+}
+
+// modelLimit is the state of one model.
+type modelLimit struct {
+	mu       sync.Mutex
+	limit    float64   // Calls at once allowed
+	inflight int       // Calls running
+	lastCut  time.Time // When limit was last halved
+	tokens   float64   // Token budget left; refills at TokensPerMinute
+	refilled time.Time
+	changed  chan struct{} // Closed and replaced when a call finishes
+}
+
+func (a *Adaptive) model(name string) *modelLimit {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	m, ok := a.models[name]
+	if !ok {
+		m = &modelLimit{
+			limit:    float64(min(initialConcurrency, a.opts.MaxConcurrency)),
+			tokens:   float64(a.opts.TokensPerMinute),
+			refilled: time.Now(),
+			changed:  make(chan struct{}),
+		}
+		a.models[name] = m
+	}
+	return m
+}
+
+// Concurrency returns how many calls to model may run at once.
+func (a *Adaptive) Concurrency(model string) int {
+	m := a.model(model)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int(m.limit)
+}
+
+func (a *Adaptive) GenerateJSON(ctx context.Context, model string, req *Request, resp any) (*Usage, error) {
+	m := a.model(model)
+	for n := 0; ; n++ {
+		start, err := a.acquire(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		usage, err := a.client.GenerateJSON(ctx, model, req, resp)
+		a.release(m, model, start, usage, err)
+		if !errors.Is(err, ErrRateLimited) || n == maxRateLimitRetries {
+			return usage, err
+		}
+		select {
+		case <-time.After(a.backoff(n)):
+		case <-ctx.Done():
+			return usage, err
+		}
+	}
+}
+
+// CountTokens is not limited; counting has a quota of its own.
+func (a *Adaptive) CountTokens(ctx context.Context, model string, req *Request) (int, error) {
+	return a.client.CountTokens(ctx, model, req)
+}
+
+// acquire waits until m has a free call and tokens left, and returns when
+// the call starts.
+func (a *Adaptive) acquire(ctx context.Context, m *modelLimit) (time.Time, error) {
+	for {
+		m.mu.Lock()
+		now := time.Now()
+		var wait time.Duration
+		if tpm := float64(a.opts.TokensPerMinute); tpm > 0 {
+			m.tokens = min(m.tokens+tpm*now.Sub(m.refilled).Minutes(), tpm)
+			m.refilled = now
+			if m.tokens <= 0 {
+				wait = time.Duration(-m.tokens/tpm*float64(time.Minute)) + time.Millisecond
+			}
+		}
+		if wait == 0 && m.inflight < int(m.limit) {
+			m.inflight++
+			m.mu.Unlock()
+			return now, nil
+		}
+		changed := m.changed
+		m.mu.Unlock()
+
+		var timer *time.Timer
+		var refill <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			refill = timer.C
+		}
+		select {
+		case <-changed:
+		case <-refill:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return time.Time{}, err
+		}
+	}
+}
+
+// release ends a call that started at start, adjusting the limit by its
+// outcome. Of the calls failing at once, only those started after the
+// last cut cut again, so a burst of failures halves the limit once.
+func (a *Adaptive) release(m *modelLimit, model string, start time.Time, usage *Usage, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inflight--
+	if usage != nil && a.opts.TokensPerMinute > 0 {
+		m.tokens -= float64(usage.TotalTokens)
+	}
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		if start.After(m.lastCut) {
+			m.limit = max(m.limit/2, 1)
+			m.lastCut = time.Now()
+			slog.Warn("LLM rate limited, reducing concurrency", "model", model, "concurrency", int(m.limit))
+		}
+	case err == nil:
+		m.limit = min(m.limit+1/m.limit, float64(a.opts.MaxConcurrency))
+	}
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
@@ -0,0 +1,91 @@
+package llmclient
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// quotaClient rate-limits calls beyond capacity at once.
+type quotaClient struct {
+	capacity int
+	tokens   int // Reported per call
+
+	mu       sync.Mutex
+	inflight int
+	starts   []time.Time
+}
+
+func (c *quotaClient) GenerateJSON(ctx context.Context, model string, req *Request, resp any) (*Usage, error) {
+	c.mu.Lock()
+	c.inflight++
+	over := c.inflight > c.capacity
+	c.starts = append(c.starts, time.Now())
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inflight--
+		c.mu.Unlock()
+	}()
+	if over {
+		return nil, ErrRateLimited
+	}
+	time.Sleep(5 * time.Millisecond)
+	return &Usage{TotalTokens: c.tokens}, nil
+}
+
+func (c *quotaClient) CountTokens(ctx context.Context, model string, req *Request) (int, error) {
+	return 0, nil
+}
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	c := &quotaClient{capacity: 2}
+	a := NewAdaptive(c, AdaptiveOptions{MaxConcurrency: 8})
+	a.backoff = func(int) time.Duration { return time.Millisecond }
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for range 40 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := a.GenerateJSON(context.Background(), "m", &Request{}, nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("call failed: %v", err)
+		}
+	}
+	if got := a.Concurrency("m"); got > 3 {
+		t.Errorf("concurrency = %d after rate limits at 3 calls, want at most 3", got)
+	}
+	if got := a.Concurrency("other"); got != initialConcurrency {
+		t.Errorf("concurrency of another model = %d, want %d", got, initialConcurrency)
+	}
+}
+
+func TestAdaptiveTokenRate(t *testing.T) {
+	// 1000 tokens a second; the first call overspends by 100, which the
+	// second waits 100ms to make up.
+	c := &quotaClient{capacity: 1, tokens: 60100}
+	a := NewAdaptive(c, AdaptiveOptions{MaxConcurrency: 1, TokensPerMinute: 60000})
+	for range 2 {
+		if _, err := a.GenerateJSON(context.Background(), "m", &Request{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if gap := c.starts[1].Sub(c.starts[0]); gap < 90*time.Millisecond {
+		t.Errorf("second call started %v after the first, want at least 100ms", gap)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := a.GenerateJSON(ctx, "m", &Request{}, nil); err != context.Canceled {
+		t.Errorf("call over the token rate with a canceled context = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,64 @@
+// Package llmclient provides a backend-neutral interface for the LLM calls
+// made by the evaluators.
+package llmclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnavailable marks failures caused by quota exhaustion or a temporarily
+// unavailable model. Callers may retry or fall back to another model.
+var ErrUnavailable = errors.New("model unavailable")
+
+// ErrRateLimited marks failures caused by exceeding a rate limit or quota,
+// which sending fewer requests avoids. It matches ErrUnavailable too.
+var ErrRateLimited = fmt.Errorf("%w: rate limited", ErrUnavailable)
+
+// Client generates a JSON response from a model.
+type Client interface {
+	// GenerateJSON sends req to model and decodes the JSON response into resp.
+	// The response schema is derived from the type of resp.
+	GenerateJSON(ctx context.Context, model string, req *Request, resp any) (*Usage, error)
+
+	// CountTokens returns the number of prompt tokens req would consume.
+	CountTokens(ctx context.Context, model string, req *Request) (int, error)
+}
+
+// Request is a single-turn prompt.
+type Request struct {
+	Text  string
+	Blobs []Blob
+
+	// Thinking controls how much the model reasons before answering.
+	Thinking ThinkingLevel
+
+	// OnTokens, if set, streams the response and is called with the number
+	// of output tokens generated so far.
+	OnTokens func(outputTokens int)
+}
+
+// Blob is an inline binary attachment such as an audio file.
+type Blob struct {
+	MIMEType string
+	Data     []byte
+}
+
+// ThinkingLevel selects the reasoning budget for a request.
+type ThinkingLevel int
+
+const (
+	// ThinkingDefault leaves the budget to the model and includes thoughts
+	// in the raw response for debugging.
+	ThinkingDefault ThinkingLevel = iota
+	ThinkingLow
+)
+
+// Usage reports token consumption for a call.
+type Usage struct {
+	PromptTokens  int `json:"prompt_tokens"`
+	ThoughtTokens int `json:"thought_tokens"`
+	OutputTokens  int `json:"output_tokens"`
+	TotalTokens   int `json:"total_tokens"`
+}
@@ -0,0 +1,150 @@
+package llmclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+
+	"google.golang.org/genai"
+)
+
+type genaiClient struct {
+	client *genai.Client
+}
+
+// NewGenAI returns a Client backed by the Google GenAI SDK.
+func NewGenAI(client *genai.Client) Client {
+	return &genaiClient{client: client}
+}
+
+func (c *genaiClient) GenerateJSON(ctx context.Context, model string, req *Request, resp any) (*Usage, error) {
+	contents := toContents(req)
+
+	cfg := &genai.GenerateContentConfig{
+		// Automatically generate schema and set JSON response type
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   reflectSchema(reflect.TypeOf(resp)),
+	}
+	switch req.Thinking {
+	case ThinkingLow:
+		cfg.ThinkingConfig = &genai.ThinkingConfig{ThinkingLevel: genai.ThinkingLevelLow}
+	default:
+		cfg.ThinkingConfig = &genai.ThinkingConfig{IncludeThoughts: true}
+	}
+
+	// Log the prompt for debugging
+	slog.Debug("LLM Prompt", "model", model, "text", req.Text)
+
+	var r *genai.GenerateContentResponse
+	var err error
+	if req.OnTokens != nil {
+		r, err = c.generateStream(ctx, model, contents, cfg, req.OnTokens)
+	} else {
+		r, err = c.client.Models.GenerateContent(ctx, model, contents, cfg)
+	}
+	if err != nil {
+		if sentinel := unavailable(err); sentinel != nil {
+			return nil, fmt.Errorf("failed to generate content: %w: %w", sentinel, err)
+		}
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	var usage *Usage
+	if m := r.UsageMetadata; m != nil {
+		usage = &Usage{
+			PromptTokens:  int(m.PromptTokenCount),
+			ThoughtTokens: int(m.ThoughtsTokenCount),
+			OutputTokens:  int(m.CandidatesTokenCount),
+			TotalTokens:   int(m.TotalTokenCount),
+		}
+		slog.Info("LLM Usage",
+			slog.String("model", model),
+			slog.Int("prompt_tokens", usage.PromptTokens),
+			slog.Int("thought_tokens", usage.ThoughtTokens),
+			slog.Int("output_tokens", usage.OutputTokens),
+			slog.Int("total_tokens", usage.TotalTokens))
+	}
+
+	// Log full raw response for debugging (includes thoughts, etc.)
+	if raw, err := r.MarshalJSON(); err == nil {
+		slog.Debug("LLM Raw Response", "json", string(raw))
+	}
+
+	respStr := r.Text()
+	if err := json.Unmarshal([]byte(respStr), resp); err != nil {
+		return usage, fmt.Errorf("failed to parse JSON: %w\nResponse: %s", err, respStr)
+	}
+	return usage, nil
+}
+
+// generateStream streams the response, reporting output tokens as chunks
+// arrive, and returns the chunks folded into a single response.
+func (c *genaiClient) generateStream(ctx context.Context, model string, contents []*genai.Content, cfg *genai.GenerateContentConfig, onTokens func(int)) (*genai.GenerateContentResponse, error) {
+	var parts []*genai.Part
+	var last *genai.GenerateContentResponse
+	for chunk, err := range c.client.Models.GenerateContentStream(ctx, model, contents, cfg) {
+		if err != nil {
+			return nil, err
+		}
+		last = chunk
+		if len(chunk.Candidates) > 0 && chunk.Candidates[0].Content != nil {
+			parts = append(parts, chunk.Candidates[0].Content.Parts...)
+		}
+		if chunk.UsageMetadata != nil {
+			onTokens(int(chunk.UsageMetadata.CandidatesTokenCount))
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("empty response stream")
+	}
+	return &genai.GenerateContentResponse{
+		Candidates:    []*genai.Candidate{{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}}},
+		UsageMetadata: last.UsageMetadata,
+		ModelVersion:  last.ModelVersion,
+	}, nil
+}
+
+func (c *genaiClient) CountTokens(ctx context.Context, model string, req *Request) (int, error) {
+	r, err := c.client.Models.CountTokens(ctx, model, toContents(req), nil)
+	if err != nil {
+		if sentinel := unavailable(err); sentinel != nil {
+			return 0, fmt.Errorf("failed to count tokens: %w: %w", sentinel, err)
+		}
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return int(r.TotalTokens), nil
+}
+
+func toContents(req *Request) []*genai.Content {
+	parts := []*genai.Part{genai.NewPartFromText(req.Text)}
+	for _, b := range req.Blobs {
+		parts = append(parts, genai.NewPartFromBytes(b.Data, b.MIMEType))
+	}
+	return []*genai.Content{{Parts: parts}}
+}
+
+// unavailable returns the sentinel of a rate limit or availability failure,
+// or nil for other errors.
+func unavailable(err error) error {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+	return unavailableStatus(apiErr.Code)
+}
+
+// unavailableStatus returns the sentinel of an HTTP status code, or nil for
+// a status other than rate limiting or unavailability.
+func unavailableStatus(code int) error {
+	switch code {
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package llmclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+type openAIClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// NewOpenAI returns a Client for an OpenAI-compatible chat completions API
+// at baseURL. It takes text only, and asks for a JSON object described by
+// the response schema in the prompt.
+func NewOpenAI(baseURL, apiKey string) Client {
+	return &openAIClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    http.DefaultClient,
+	}
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model          string          `json:"model"`
+	Messages       []openAIMessage `json:"messages"`
+	ResponseFormat struct {
+		Type string `json:"type"`
+	} `json:"response_format"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens            int `json:"prompt_tokens"`
+		CompletionTokens        int `json:"completion_tokens"`
+		TotalTokens             int `json:"total_tokens"`
+		CompletionTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"completion_tokens_details"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *openAIClient) GenerateJSON(ctx context.Context, model string, req *Request, resp any) (*Usage, error) {
+	if len(req.Blobs) > 0 {
+		return nil, errors.New("OpenAI-compatible models take text only")
+	}
+	schema, err := json.Marshal(reflectSchema(reflect.TypeOf(resp)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response schema: %w", err)
+	}
+	body := openAIRequest{
+		Model: model,
+		Messages: []openAIMessage{{
+			Role:    "user",
+			Content: fmt.Sprintf("%s\n\nRespond with JSON matching this schema:\n%s", req.Text, schema),
+		}},
+	}
+	body.ResponseFormat.Type = "json_object"
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("LLM Prompt", "model", model, "text", req.Text)
+
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	hresp, err := c.http.Do(hreq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+	defer hresp.Body.Close()
+	raw, err := io.ReadAll(hresp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	var r openAIResponse
+	if err := json.Unmarshal(raw, &r); err != nil && hresp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if hresp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(raw))
+		if r.Error != nil {
+			msg = r.Error.Message
+		}
+		err := fmt.Errorf("%s: %s", hresp.Status, msg)
+		if sentinel := unavailableStatus(hresp.StatusCode); sentinel != nil {
+			return nil, fmt.Errorf("failed to generate content: %w: %w", sentinel, err)
+		}
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	var usage *Usage
+	if u := r.Usage; u != nil {
+		usage = &Usage{
+			PromptTokens:  u.PromptTokens,
+			ThoughtTokens: u.CompletionTokensDetails.ReasoningTokens,
+			OutputTokens:  u.CompletionTokens - u.CompletionTokensDetails.ReasoningTokens,
+			TotalTokens:   u.TotalTokens,
+		}
+		slog.Info("LLM Usage",
+			slog.String("model", model),
+			slog.Int("prompt_tokens", usage.PromptTokens),
+			slog.Int("thought_tokens", usage.ThoughtTokens),
+			slog.Int("output_tokens", usage.OutputTokens),
+			slog.Int("total_tokens", usage.TotalTokens))
+		if req.OnTokens != nil {
+			req.OnTokens(usage.OutputTokens)
+		}
+	}
+	slog.Debug("LLM Raw Response", "json", string(raw))
+
+	if len(r.Choices) == 0 {
+		return usage, errors.New("empty response")
+	}
+	respStr := r.Choices[0].Message.Content
+	if err := json.Unmarshal([]byte(respStr), resp); err != nil {
+		return usage, fmt.Errorf("failed to parse JSON: %w\nResponse: %s", err, respStr)
+	}
+	return usage, nil
+}
+
+// CountTokens returns the number of runes in req, which bounds the tokens
+// of the usual tokenizers; these APIs have no way to count them.
+func (c *openAIClient) CountTokens(ctx context.Context, model string, req *Request) (int, error) {
+	return len([]rune(req.Text)), nil
+}
@@ -0,0 +1,64 @@
+package llmclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOpenAIGenerateJSON(t *testing.T) {
+	var got openAIRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{
+			"choices": [{"message": {"role": "assistant", "content": "{\"score\": 7}"}}],
+			"usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15, "completion_tokens_details": {"reasoning_tokens": 2}}
+		}`))
+	}))
+	defer srv.Close()
+
+	var resp struct {
+		Score int `json:"score"`
+	}
+	usage, err := NewOpenAI(srv.URL+"/v1/", "key").GenerateJSON(context.Background(), "qwen-max", &Request{Text: "Rate it."}, &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Score != 7 {
+		t.Errorf("Score = %d, want 7", resp.Score)
+	}
+	if diff := cmp.Diff(&Usage{PromptTokens: 10, ThoughtTokens: 2, OutputTokens: 3, TotalTokens: 15}, usage); diff != "" {
+		t.Errorf("usage mismatch (-want +got):\n%s", diff)
+	}
+	if got.Model != "qwen-max" || got.ResponseFormat.Type != "json_object" {
+		t.Errorf("request model %q, format %q", got.Model, got.ResponseFormat.Type)
+	}
+	if len(got.Messages) != 1 || !strings.HasPrefix(got.Messages[0].Content, "Rate it.") || !strings.Contains(got.Messages[0].Content, "score") {
+		t.Errorf("prompt %q lacks the text or schema", got.Messages)
+	}
+}
+
+func TestOpenAIRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"message": "slow down"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewOpenAI(srv.URL, "key").GenerateJSON(context.Background(), "m", &Request{}, &struct{}{})
+	if !errors.Is(err, ErrRateLimited) || !strings.Contains(err.Error(), "slow down") {
+		t.Errorf("err = %v, want rate limited with the API message", err)
+	}
+}
@@ -0,0 +1,79 @@
+package llmclient
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Router is a Client that sends each model to a backend chosen by its
+// name. A name with a backend prefix, such as "qwen/qwen-max", goes to that
+// backend as "qwen-max"; any other name goes to the default backend as is.
+// This lets one evaluation mix judges from different vendors.
+type Router struct {
+	backends map[string]Client
+	def      string
+}
+
+// NewRouter returns a Router over backends by prefix, sending unprefixed
+// models to the def backend. A nil backend is known but not configured, and
+// its models fail.
+func NewRouter(backends map[string]Client, def string) *Router {
+	return &Router{backends: backends, def: def}
+}
+
+// DashScopeURL is the OpenAI-compatible endpoint of Alibaba Cloud's models.
+const DashScopeURL = "https://dashscope.aliyuncs.com/compatible-mode/v1"
+
+// NewDefaultRouter routes unprefixed models to gemini, which may be nil when
+// no key is set, and "qwen/" models to DashScope with QWEN_API_KEY.
+func NewDefaultRouter(gemini Client) *Router {
+	backends := map[string]Client{"gemini": gemini, "qwen": nil}
+	if key := os.Getenv("QWEN_API_KEY"); key != "" {
+		backends["qwen"] = NewOpenAI(DashScopeURL, key)
+	}
+	return NewRouter(backends, "gemini")
+}
+
+// Backend returns the name of the backend model goes to.
+func (r *Router) Backend(model string) string {
+	backend, _ := r.split(model)
+	return backend
+}
+
+// split returns the backend for model and the name it knows the model by.
+func (r *Router) split(model string) (backend, name string) {
+	if prefix, rest, ok := strings.Cut(model, "/"); ok {
+		if _, known := r.backends[prefix]; known {
+			return prefix, rest
+		}
+	}
+	return r.def, model
+}
+
+// route returns the client for model and the name it knows the model by.
+func (r *Router) route(model string) (Client, string, error) {
+	backend, name := r.split(model)
+	c := r.backends[backend]
+	if c == nil {
+		return nil, "", fmt.Errorf("model %s: the %s backend is not configured", model, backend)
+	}
+	return c, name, nil
+}
+
+func (r *Router) GenerateJSON(ctx context.Context, model string, req *Request, resp any) (*Usage, error) {
+	c, name, err := r.route(model)
+	if err != nil {
+		return nil, err
+	}
+	return c.GenerateJSON(ctx, name, req, resp)
+}
+
+func (r *Router) CountTokens(ctx context.Context, model string, req *Request) (int, error) {
+	c, name, err := r.route(model)
+	if err != nil {
+		return 0, err
+	}
+	return c.CountTokens(ctx, name, req)
+}
@@ -0,0 +1,52 @@
+package llmclient
+
+import (
+	"context"
+	"testing"
+)
+
+// nameClient reports the model name it was called with.
+type nameClient struct {
+	backend string
+	got     string
+}
+
+func (c *nameClient) GenerateJSON(ctx context.Context, model string, req *Request, resp any) (*Usage, error) {
+	c.got = model
+	return nil, nil
+}
+
+func (c *nameClient) CountTokens(ctx context.Context, model string, req *Request) (int, error) {
+	return 0, nil
+}
+
+func TestRouter(t *testing.T) {
+	gemini := &nameClient{backend: "gemini"}
+	qwen := &nameClient{backend: "qwen"}
+	r := NewRouter(map[string]Client{"gemini": gemini, "qwen": qwen, "other": nil}, "gemini")
+
+	for _, tt := range []struct {
+		model   string
+		backend *nameClient
+		name    string
+	}{
+		{"gemini-3-flash-preview", gemini, "gemini-3-flash-preview"},
+		{"gemini/gemini-3-pro-preview", gemini, "gemini-3-pro-preview"},
+		{"qwen/qwen-max", qwen, "qwen-max"},
+		{"tunedModels/judge", gemini, "tunedModels/judge"}, // Unknown prefixes are part of the name
+	} {
+		gemini.got, qwen.got = "", ""
+		if _, err := r.GenerateJSON(context.Background(), tt.model, &Request{}, nil); err != nil {
+			t.Fatalf("GenerateJSON(%s): %v", tt.model, err)
+		}
+		if tt.backend.got != tt.name {
+			t.Errorf("GenerateJSON(%s) sent %q to %s, want %q", tt.model, tt.backend.got, tt.backend.backend, tt.name)
+		}
+	}
+	if got := r.Backend("qwen/qwen-max"); got != "qwen" {
+		t.Errorf("Backend(qwen/qwen-max) = %q, want qwen", got)
+	}
+	if _, err := r.GenerateJSON(context.Background(), "other/m", &Request{}, nil); err == nil {
+		t.Error("GenerateJSON(other/m) succeeded on an unconfigured backend")
+	}
+}
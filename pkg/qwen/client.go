@@ -1,19 +1,21 @@
 package qwen
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"os"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
+	"asr-eval/pkg/audio"
 	"asr-eval/pkg/volc/common"
 )
 
@@ -44,22 +46,39 @@ type Result struct {
 	RequestID string
 }
 
+// ProcessFile streams filePath to the service and sends results to resChan,
+// which is always closed by the time it returns.
 func (c *Client) ProcessFile(ctx context.Context, filePath string, corpusText string, resChan chan<- Result) error {
 	// 1. Prepare Audio
-	pcmData, err := c.prepareAudio(filePath)
+	pcmData, err := c.prepareAudio(ctx, filePath)
 	if err != nil {
+		close(resChan)
 		return fmt.Errorf("failed to prepare audio: %v", err)
 	}
+	return c.process(ctx, bytes.NewReader(pcmData), true, corpusText, resChan)
+}
+
+// ProcessStream streams 16-bit mono PCM at 16 kHz read from r until EOF,
+// as it arrives, to the service and sends results to resChan, which is
+// always closed by the time it returns.
+func (c *Client) ProcessStream(ctx context.Context, r io.Reader, corpusText string, resChan chan<- Result) error {
+	return c.process(ctx, r, false, corpusText, resChan)
+}
 
+// process runs a session sending the PCM read from r, paced to real time
+// if it is all at hand.
+func (c *Client) process(ctx context.Context, r io.Reader, paced bool, corpusText string, resChan chan<- Result) error {
 	// 2. Connect WebSocket
 	conn, err := c.connect(ctx)
 	if err != nil {
+		close(resChan)
 		return fmt.Errorf("failed to connect: %v", err)
 	}
 	defer conn.Close()
 
 	// 3. Send Session Update (Initial Config)
 	if err := c.sendSessionUpdate(conn, corpusText); err != nil {
+		close(resChan)
 		return fmt.Errorf("failed to send session update: %v", err)
 	}
 
@@ -81,6 +100,8 @@ func (c *Client) ProcessFile(ctx context.Context, filePath string, corpusText st
 	case <-readyChan:
 		log.Println("Session initialized (session.updated received)")
 	case <-time.After(5 * time.Second):
+		conn.Close()
+		wg.Wait()
 		return fmt.Errorf("timeout waiting for session.updated")
 	}
 
@@ -88,7 +109,7 @@ func (c *Client) ProcessFile(ctx context.Context, filePath string, corpusText st
 	time.Sleep(2 * time.Second)
 
 	// 5. Send Audio
-	err = c.sendAudio(conn, pcmData)
+	err = c.sendAudio(conn, r, paced)
 	if err != nil {
 		log.Printf("Error sending audio: %v", err)
 		// Don't return here, let the receiver finish or error out
@@ -102,21 +123,12 @@ func (c *Client) ProcessFile(ctx context.Context, filePath string, corpusText st
 	return nil
 }
 
-func (c *Client) prepareAudio(filePath string) ([]byte, error) {
-	// Use common package to read/convert file to WAV bytes
-	// Note: common.ConvertWavWithPath calls ffmpeg
-	content, err := os.ReadFile(filePath)
+func (c *Client) prepareAudio(ctx context.Context, filePath string) ([]byte, error) {
+	// FLAC and WAV decode in Go; other formats go through ffmpeg.
+	content, err := audio.ToWAV(ctx, filePath, common.DefaultSampleRate)
 	if err != nil {
 		return nil, err
 	}
-	isWav := common.JudgeWav(content)
-	if !isWav {
-		log.Printf("File is not WAV, converting: %s", filePath)
-		content, err = common.ConvertWavWithPath(filePath, common.DefaultSampleRate)
-		if err != nil {
-			return nil, err
-		}
-	}
 	log.Printf("Audio content size after preparation: %d bytes", len(content))
 
 	// Parse WAV to find 'data' chunk
@@ -205,25 +217,29 @@ func (c *Client) sendSessionUpdate(conn *websocket.Conn, corpusText string) erro
 	return conn.WriteJSON(update)
 }
 
-func (c *Client) sendAudio(conn *websocket.Conn, pcmData []byte) error {
+func (c *Client) sendAudio(conn *websocket.Conn, r io.Reader, paced bool) error {
 	// Calculate chunk size: 16k * 1 channel * 2 bytes/sample * 0.2s = 6400 bytes
 	chunkSize := 16000 * 2 * segmentDuration / 1000
 
-	log.Printf("Starting to send audio. Total data size: %d bytes", len(pcmData))
+	log.Printf("Starting to send audio (paced: %v)", paced)
 
 	ticker := time.NewTicker(time.Duration(segmentDuration) * time.Millisecond)
 	defer ticker.Stop()
 
-	for i := 0; i < len(pcmData); i += chunkSize {
-		end := i + chunkSize
-		if end > len(pcmData) {
-			end = len(pcmData)
+	chunk := make([]byte, chunkSize)
+	for {
+		// Captured audio arrives in real time; a read error ends it like EOF.
+		n, err := io.ReadFull(r, chunk)
+		if n == 0 {
+			if err != nil && err != io.EOF {
+				log.Printf("Audio read error: %v", err)
+			}
+			break
 		}
-		chunk := pcmData[i:end]
 
 		eventID := uuid.NewString()
 		// Base64 encode
-		b64Audio := base64.StdEncoding.EncodeToString(chunk)
+		b64Audio := base64.StdEncoding.EncodeToString(chunk[:n])
 
 		event := InputAudioBufferAppendEvent{
 			EventID: eventID,
@@ -235,7 +251,9 @@ func (c *Client) sendAudio(conn *websocket.Conn, pcmData []byte) error {
 			return err
 		}
 
-		<-ticker.C // Simulate real-time sending
+		if paced {
+			<-ticker.C // Simulate real-time sending
+		}
 	}
 
 	// In VAD Mode, we do NOT send input_audio_buffer.commit.
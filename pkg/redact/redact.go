@@ -0,0 +1,199 @@
+// Package redact finds personal information (phone and ID numbers, email
+// addresses and names) in transcripts and replaces it with placeholders.
+package redact
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"asr-eval/pkg/llmclient"
+)
+
+// Kinds of personal information.
+const (
+	KindPhone = "phone"
+	KindID    = "id"
+	KindEmail = "email"
+	KindName  = "name"
+)
+
+// Entity is a piece of personal information as it appears in a text.
+type Entity struct {
+	Text string `json:"text"`
+	Kind string `json:"kind" jsonscheme:"enum:name,phone,id"`
+}
+
+// Replacement records what a placeholder stands for.
+type Replacement struct {
+	Placeholder string `json:"placeholder"`
+	Kind        string `json:"kind"`
+	Value       string `json:"value"`
+}
+
+var patterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{KindEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+`)},
+	// Resident ID numbers, 18 digits with an optional X check digit, or
+	// the old 15 digits.
+	{KindID, regexp.MustCompile(`\d{17}[\dXx]|\d{15}`)},
+	// Mobile numbers, optionally grouped 3-4-4, and landlines with an
+	// area code.
+	{KindPhone, regexp.MustCompile(`1[3-9]\d(?:[- ]?\d{4}){2}|0\d{2,3}-?\d{7,8}`)},
+}
+
+// Find returns the entities the patterns match in text, in order. A match
+// running into further digits is not one, so a long number is not taken
+// for a phone number.
+func Find(text string) []Entity {
+	var spans []span
+	for _, p := range patterns {
+		for _, m := range p.re.FindAllStringIndex(text, -1) {
+			if isDigitAt(text, m[0]-1) || isDigitAt(text, m[1]) {
+				continue
+			}
+			spans = append(spans, span{m[0], m[1], p.kind})
+		}
+	}
+	var out []Entity
+	for _, s := range nonOverlapping(spans) {
+		out = append(out, Entity{Text: text[s.start:s.end], Kind: s.kind})
+	}
+	return out
+}
+
+func isDigitAt(s string, i int) bool {
+	return i >= 0 && i < len(s) && s[i] >= '0' && s[i] <= '9'
+}
+
+type span struct {
+	start, end int
+	kind       string
+}
+
+// nonOverlapping sorts spans by start, longest first, and drops those
+// overlapping an earlier one.
+func nonOverlapping(spans []span) []span {
+	slices.SortFunc(spans, func(a, b span) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return b.end - a.end
+	})
+	var out []span
+	end := 0
+	for _, s := range spans {
+		if s.start < end {
+			continue
+		}
+		out = append(out, s)
+		end = s.end
+	}
+	return out
+}
+
+// FindLLM asks model for the entities in texts that the patterns miss:
+// names, and numbers spelled out in words. The texts are sent together,
+// so the variants of a name across transcripts are found as one.
+func FindLLM(ctx context.Context, client llmclient.Client, model string, texts []string) ([]Entity, error) {
+	var b strings.Builder
+	b.WriteString(`List the personal information in the texts below, which are transcripts of the same recording: ` +
+		`names of people (kind "name"), phone numbers (kind "phone") and ID numbers (kind "id"), ` +
+		`including numbers spelled out in words. Give each entity exactly as it is written, ` +
+		`once for each distinct spelling. Do not list place, company or product names.` + "\n")
+	for i, t := range texts {
+		fmt.Fprintf(&b, "\n<text%d>\n%s\n</text%d>\n", i+1, t, i+1)
+	}
+	var resp struct {
+		Entities []Entity `json:"entities"`
+	}
+	req := &llmclient.Request{Text: b.String(), Thinking: llmclient.ThinkingLow}
+	if _, err := client.GenerateJSON(ctx, model, req, &resp); err != nil {
+		return nil, err
+	}
+	var out []Entity
+	for _, e := range resp.Entities {
+		if e.Text = strings.TrimSpace(e.Text); e.Text != "" {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Redactor replaces entities with placeholders such as [NAME_1]. A value
+// gets the same placeholder in every text a Redactor redacts, so redact
+// the texts of one recording with one Redactor to keep them comparable.
+type Redactor struct {
+	placeholders map[string]string // By kind and normalized value
+	counts       map[string]int    // Placeholders by kind
+	replacements []Replacement
+}
+
+// NewRedactor returns a Redactor with no placeholders assigned.
+func NewRedactor() *Redactor {
+	return &Redactor{placeholders: make(map[string]string), counts: make(map[string]int)}
+}
+
+// Redact replaces what Find matches in text, and every occurrence of
+// extra, such as the entities of FindLLM, with placeholders.
+func (r *Redactor) Redact(text string, extra []Entity) string {
+	var spans []span
+	for _, e := range Find(text) {
+		spans = append(spans, occurrences(text, e)...)
+	}
+	for _, e := range extra {
+		spans = append(spans, occurrences(text, e)...)
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range nonOverlapping(spans) {
+		b.WriteString(text[last:s.start])
+		b.WriteString(r.placeholder(s.kind, text[s.start:s.end]))
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func occurrences(text string, e Entity) []span {
+	var out []span
+	for i := 0; e.Text != ""; {
+		j := strings.Index(text[i:], e.Text)
+		if j < 0 {
+			break
+		}
+		out = append(out, span{i + j, i + j + len(e.Text), e.Kind})
+		i += j + len(e.Text)
+	}
+	return out
+}
+
+func (r *Redactor) placeholder(kind, value string) string {
+	key := kind + "\x00" + normalize(kind, value)
+	if p, ok := r.placeholders[key]; ok {
+		return p
+	}
+	r.counts[kind]++
+	p := fmt.Sprintf("[%s_%d]", strings.ToUpper(kind), r.counts[kind])
+	r.placeholders[key] = p
+	r.replacements = append(r.replacements, Replacement{Placeholder: p, Kind: kind, Value: value})
+	return p
+}
+
+// normalize drops the separators of numbers, so grouped and ungrouped
+// forms of a number share a placeholder.
+func normalize(kind, value string) string {
+	if kind == KindPhone || kind == KindID {
+		return strings.NewReplacer("-", "", " ", "").Replace(value)
+	}
+	return value
+}
+
+// Replacements returns the placeholders assigned so far, in order.
+func (r *Redactor) Replacements() []Replacement {
+	return slices.Clone(r.replacements)
+}
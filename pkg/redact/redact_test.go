@@ -0,0 +1,44 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFind(t *testing.T) {
+	got := Find("请拨打138-1234-5678或010-12345678，身份证11010519491231002X，邮箱a.b@example.com，订单号202412311234567890123")
+	want := []Entity{
+		{Text: "138-1234-5678", Kind: KindPhone},
+		{Text: "010-12345678", Kind: KindPhone},
+		{Text: "11010519491231002X", Kind: KindID},
+		{Text: "a.b@example.com", Kind: KindEmail},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Find mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor()
+	names := []Entity{{Text: "张三", Kind: KindName}, {Text: "张山", Kind: KindName}}
+	tests := []struct {
+		in, want string
+	}{
+		{"张三的电话是13812345678", "[NAME_1]的电话是[PHONE_1]"},
+		{"张山的电话是138 1234 5678，李四不是", "[NAME_2]的电话是[PHONE_1]，李四不是"},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.in, names); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	want := []Replacement{
+		{Placeholder: "[NAME_1]", Kind: KindName, Value: "张三"},
+		{Placeholder: "[PHONE_1]", Kind: KindPhone, Value: "13812345678"},
+		{Placeholder: "[NAME_2]", Kind: KindName, Value: "张山"},
+	}
+	if diff := cmp.Diff(want, r.Replacements()); diff != "" {
+		t.Errorf("replacements mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,257 @@
+// Package rtp receives a call's audio as RTP over UDP, as media servers fork
+// it for recording, and decodes it to PCM.
+package rtp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Codecs a Receiver decodes.
+const (
+	CodecPCMU = "pcmu" // G.711 μ-law, payload type 0, at 8 kHz
+	CodecPCMA = "pcma" // G.711 A-law, payload type 8, at 8 kHz
+	CodecL16  = "l16"  // 16-bit big-endian mono PCM under a dynamic payload type
+)
+
+// DefaultIdle is how long a Receiver waits for the next packet before it
+// takes the call to have ended.
+const DefaultIdle = 5 * time.Second
+
+// maxGap bounds the silence a Receiver fills in for missing packets, such
+// as those of a call on hold, so a clock jump does not fill the memory.
+const maxGap = 60 * time.Second
+
+// Receiver reads the audio of the first RTP stream sent to it, identified
+// by its SSRC, as 16-bit little-endian mono PCM at SampleRate. Packets
+// arriving late are dropped and lost ones filled with silence, so the
+// audio keeps the call's timing.
+type Receiver struct {
+	conn    net.PacketConn
+	codec   string
+	rate    int
+	idle    time.Duration
+	stopped atomic.Bool
+
+	started bool
+	ssrc    uint32
+	pt      byte
+	seq     uint16 // Of the last packet played
+	next    uint32 // Timestamp expected of the next packet
+	pending []byte
+	buf     []byte
+}
+
+// Listen listens for RTP on the UDP address addr, such as ":5004". rate is
+// the sample rate of L16 audio; G.711 is always at 8 kHz. The call ends
+// once no packet has arrived for idle after the first.
+func Listen(addr, codec string, rate int, idle time.Duration) (*Receiver, error) {
+	switch codec {
+	case CodecPCMU, CodecPCMA:
+		rate = 8000
+	case CodecL16:
+		if rate <= 0 {
+			return nil, errors.New("rtp: L16 needs a sample rate")
+		}
+	default:
+		return nil, fmt.Errorf("rtp: unknown codec %q", codec)
+	}
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Receiver{conn: conn, codec: codec, rate: rate, idle: idle, buf: make([]byte, 1<<16)}, nil
+}
+
+// ListenURL listens as given by a URL of the form
+// "rtp://[host]:port[?codec=pcmu|pcma|l16][&rate=<Hz>][&idle=<duration>]",
+// by default for PCMU, ending the call after DefaultIdle.
+func ListenURL(rawURL string) (*Receiver, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "rtp" {
+		return nil, fmt.Errorf("rtp: %s is not an rtp:// URL", rawURL)
+	}
+	q := u.Query()
+	codec := q.Get("codec")
+	if codec == "" {
+		codec = CodecPCMU
+	}
+	var rate int
+	if v := q.Get("rate"); v != "" {
+		if rate, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("rtp: invalid rate %q", v)
+		}
+	}
+	idle := DefaultIdle
+	if v := q.Get("idle"); v != "" {
+		if idle, err = time.ParseDuration(v); err != nil || idle <= 0 {
+			return nil, fmt.Errorf("rtp: invalid idle %q", v)
+		}
+	}
+	return Listen(u.Host, codec, rate, idle)
+}
+
+// SampleRate returns the sample rate of the audio read.
+func (r *Receiver) SampleRate() int {
+	return r.rate
+}
+
+// Addr returns the address the Receiver listens on.
+func (r *Receiver) Addr() net.Addr {
+	return r.conn.LocalAddr()
+}
+
+// Read reads the audio received. It waits for the call to start, and
+// returns io.EOF once it has ended or Stop was called.
+func (r *Receiver) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.started {
+			r.conn.SetReadDeadline(time.Now().Add(r.idle))
+		}
+		n, _, err := r.conn.ReadFrom(r.buf)
+		if err != nil {
+			var ne net.Error
+			if r.stopped.Load() || errors.As(err, &ne) && ne.Timeout() {
+				r.conn.Close()
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		r.receive(r.buf[:n])
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Stop ends the call; Read returns what was received and then io.EOF.
+func (r *Receiver) Stop() error {
+	if r.stopped.Swap(true) {
+		return nil
+	}
+	return r.conn.Close()
+}
+
+// receive decodes the audio of packet into pending, if it belongs to the
+// stream and is not late.
+func (r *Receiver) receive(packet []byte) {
+	h, payload, ok := parse(packet)
+	if !ok {
+		return
+	}
+	switch {
+	case !r.started:
+		if r.codec == CodecPCMU && h.pt != 0 || r.codec == CodecPCMA && h.pt != 8 || r.codec == CodecL16 && h.pt < 96 {
+			return
+		}
+		r.started, r.ssrc, r.pt = true, h.ssrc, h.pt
+		r.seq, r.next = h.seq-1, h.timestamp
+	case h.ssrc != r.ssrc || h.pt != r.pt:
+		// Another stream, or DTMF and comfort noise in this one.
+		return
+	case int16(h.seq-r.seq) <= 0:
+		return
+	}
+
+	var samples []int16
+	switch r.codec {
+	case CodecPCMU:
+		samples = make([]int16, len(payload))
+		for i, b := range payload {
+			samples[i] = ulaw(b)
+		}
+	case CodecPCMA:
+		samples = make([]int16, len(payload))
+		for i, b := range payload {
+			samples[i] = alaw(b)
+		}
+	case CodecL16:
+		samples = make([]int16, len(payload)/2)
+		for i := range samples {
+			samples[i] = int16(binary.BigEndian.Uint16(payload[2*i:]))
+		}
+	}
+	if gap := int32(h.timestamp - r.next); gap > 0 && int64(gap) <= int64(maxGap/time.Second)*int64(r.rate) {
+		r.pending = append(r.pending, make([]byte, 2*gap)...)
+	}
+	for _, s := range samples {
+		r.pending = binary.LittleEndian.AppendUint16(r.pending, uint16(s))
+	}
+	r.seq, r.next = h.seq, h.timestamp+uint32(len(samples))
+}
+
+// header is what a Receiver uses of an RTP header.
+type header struct {
+	pt        byte
+	seq       uint16
+	timestamp uint32
+	ssrc      uint32
+}
+
+// parse splits an RTP packet into its header and payload, reporting false
+// if it is not one.
+func parse(b []byte) (header, []byte, bool) {
+	if len(b) < 12 || b[0]>>6 != 2 {
+		return header{}, nil, false
+	}
+	h := header{
+		pt:        b[1] & 0x7f,
+		seq:       binary.BigEndian.Uint16(b[2:]),
+		timestamp: binary.BigEndian.Uint32(b[4:]),
+		ssrc:      binary.BigEndian.Uint32(b[8:]),
+	}
+	end := len(b)
+	if b[0]&0x20 != 0 { // Padding, its length in the last byte
+		end -= int(b[end-1])
+	}
+	// Skip the CSRCs and any extension, whose length is in words.
+	start := 12 + 4*int(b[0]&0x0f)
+	if b[0]&0x10 != 0 {
+		if start+4 > end {
+			return header{}, nil, false
+		}
+		start += 4 + 4*int(binary.BigEndian.Uint16(b[start+2:]))
+	}
+	if start > end {
+		return header{}, nil, false
+	}
+	return h, b[start:end], true
+}
+
+// ulaw decodes a G.711 μ-law sample.
+func ulaw(b byte) int16 {
+	u := ^b
+	t := (int(u&0x0f)<<3 + 0x84) << (u & 0x70 >> 4)
+	if u&0x80 != 0 {
+		return int16(0x84 - t)
+	}
+	return int16(t - 0x84)
+}
+
+// alaw decodes a G.711 A-law sample.
+func alaw(b byte) int16 {
+	a := b ^ 0x55
+	t := int(a&0x0f) << 4
+	switch seg := a & 0x70 >> 4; seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t = (t + 0x108) << (seg - 1)
+	}
+	if a&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
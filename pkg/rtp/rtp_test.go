@@ -0,0 +1,111 @@
+package rtp
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func packet(pt byte, seq uint16, timestamp, ssrc uint32, payload ...byte) []byte {
+	b := []byte{0x80, pt, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(b[2:], seq)
+	binary.BigEndian.PutUint32(b[4:], timestamp)
+	binary.BigEndian.PutUint32(b[8:], ssrc)
+	return append(b, payload...)
+}
+
+func TestReceiver(t *testing.T) {
+	r, err := Listen("127.0.0.1:0", CodecPCMU, 0, 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("udp", r.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	padded := packet(0, 12, 1004, 7, 0x80, 0x00, 0, 0, 3)
+	padded[0] |= 0x20
+	for _, p := range [][]byte{
+		packet(0, 10, 1000, 7, 0xff, 0x80),
+		packet(101, 11, 1002, 7, 1, 2, 3, 4), // DTMF
+		packet(0, 11, 1002, 9, 0x80, 0x80),   // Another stream
+		padded,                               // Packet 11 lost
+		packet(0, 12, 1004, 7, 0x80, 0x80),   // Repeated
+		packet(0, 13, 1006, 7, 0x00),
+		{0x80, 0},
+	} {
+		if _, err := conn.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]int16, len(b)/2)
+	for i := range got {
+		got[i] = int16(binary.LittleEndian.Uint16(b[2*i:]))
+	}
+	want := []int16{0, 32124, 0, 0, 32124, -32124, -32124}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("audio mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestG711(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(byte) int16
+		in   byte
+		want int16
+	}{
+		{"μ-law zero", ulaw, 0xff, 0},
+		{"μ-law max", ulaw, 0x80, 32124},
+		{"μ-law min", ulaw, 0x00, -32124},
+		{"μ-law smallest", ulaw, 0xfe, 8},
+		{"μ-law smallest negative", ulaw, 0x7e, -8},
+		{"A-law smallest", alaw, 0xd5, 8},
+		{"A-law smallest negative", alaw, 0x55, -8},
+		{"A-law max", alaw, 0xaa, 32256},
+		{"A-law min", alaw, 0x2a, -32256},
+	}
+	for _, tt := range tests {
+		if got := tt.f(tt.in); got != tt.want {
+			t.Errorf("%s: decode(%#x) = %d, want %d", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestListenURL(t *testing.T) {
+	for _, tt := range []struct {
+		url   string
+		codec string
+		rate  int
+	}{
+		{"rtp://127.0.0.1:0", CodecPCMU, 8000},
+		{"rtp://127.0.0.1:0?codec=pcma", CodecPCMA, 8000},
+		{"rtp://127.0.0.1:0?codec=l16&rate=16000&idle=1s", CodecL16, 16000},
+	} {
+		r, err := ListenURL(tt.url)
+		if err != nil {
+			t.Errorf("ListenURL(%q): %v", tt.url, err)
+			continue
+		}
+		r.Stop()
+		if r.codec != tt.codec || r.SampleRate() != tt.rate {
+			t.Errorf("ListenURL(%q) = %s at %d Hz, want %s at %d Hz", tt.url, r.codec, r.SampleRate(), tt.codec, tt.rate)
+		}
+	}
+	for _, bad := range []string{"udp://:5004", "rtp://127.0.0.1:0?codec=opus", "rtp://127.0.0.1:0?codec=l16", "rtp://127.0.0.1:0?idle=soon"} {
+		if r, err := ListenURL(bad); err == nil {
+			r.Stop()
+			t.Errorf("ListenURL(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package textdiff
+
+import "strings"
+
+// ANSI escapes for Inline.
+const (
+	ansiDel   = "\x1b[31;9m" // Red, struck through
+	ansiIns   = "\x1b[32m"   // Green
+	ansiReset = "\x1b[0m"
+)
+
+// Inline renders a as one line of hypothesis text with the edits marked,
+// as wdiff does: reference text as [-deleted-] and hypothesis text as
+// {+inserted+}, a replacement as both. With color, the markers are
+// replaced by ANSI colors for a terminal.
+func (a *Alignment) Inline(color bool) string {
+	del := func(s string) string {
+		if color {
+			return ansiDel + s + ansiReset
+		}
+		return "[-" + s + "-]"
+	}
+	ins := func(s string) string {
+		if color {
+			return ansiIns + s + ansiReset
+		}
+		return "{+" + s + "+}"
+	}
+	var b strings.Builder
+	for _, sp := range a.Spans {
+		// Markers go around the words; trailing spaces stay outside so
+		// the line still reads as text.
+		ref, refSpace := trimSpace(sp.Ref)
+		hyp, hypSpace := trimSpace(sp.Hyp)
+		switch sp.Op {
+		case OpEqual:
+			b.WriteString(sp.Hyp)
+		case OpDelete:
+			b.WriteString(del(ref) + refSpace)
+		case OpInsert:
+			b.WriteString(ins(hyp) + hypSpace)
+		case OpReplace:
+			b.WriteString(del(ref) + ins(hyp) + hypSpace)
+		}
+	}
+	return b.String()
+}
+
+// trimSpace splits the trailing whitespace off s.
+func trimSpace(s string) (string, string) {
+	t := strings.TrimRight(s, " \t\n")
+	return t, s[len(t):]
+}
@@ -0,0 +1,206 @@
+// Package textdiff aligns a hypothesis transcript against a reference at
+// word level, treating each CJK character as a word.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxCells bounds the alignment matrix (reference × hypothesis tokens).
+const maxCells = 64 << 20
+
+// Op is the kind of an alignment span.
+type Op string
+
+const (
+	OpEqual   Op = "equal"
+	OpInsert  Op = "insert"  // Only in the hypothesis
+	OpDelete  Op = "delete"  // Only in the reference
+	OpReplace Op = "replace" // Substituted tokens
+)
+
+// Span is a run of tokens with the same Op. Ref and Hyp are the original
+// text, including whitespace and punctuation, so concatenating all Ref (or
+// Hyp) fields reproduces the input unless it has no words at all.
+type Span struct {
+	Op  Op     `json:"op"`
+	Ref string `json:"ref,omitempty"`
+	Hyp string `json:"hyp,omitempty"`
+}
+
+// Alignment is the result of Align.
+type Alignment struct {
+	Spans     []Span  `json:"spans"`
+	RefTokens int     `json:"ref_tokens"`
+	Sub       int     `json:"sub"`
+	Del       int     `json:"del"`
+	Ins       int     `json:"ins"`
+	ErrorRate float64 `json:"error_rate"` // (Sub+Del+Ins)/RefTokens
+}
+
+// Token is a word or CJK character. Text carries the surrounding
+// punctuation and whitespace; Key is the normalized form compared during
+// alignment.
+type Token struct {
+	Text string
+	Key  string
+}
+
+// Tokenize splits s into tokens. Punctuation and whitespace are attached to
+// the preceding token, or to the first token when they lead the string; a
+// string without any word yields no tokens.
+func Tokenize(s string) []Token {
+	var toks []Token
+	var lead strings.Builder
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		w := word.String()
+		toks = append(toks, Token{Text: w, Key: strings.ToLower(w)})
+		word.Reset()
+	}
+	attach := func(r rune) {
+		if len(toks) == 0 {
+			lead.WriteRune(r)
+		} else {
+			toks[len(toks)-1].Text += string(r)
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case isCJK(r):
+			flush()
+			toks = append(toks, Token{Text: string(r), Key: string(r)})
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'':
+			word.WriteRune(r)
+		default:
+			flush()
+			attach(r)
+		}
+	}
+	flush()
+	if lead.Len() > 0 && len(toks) > 0 {
+		toks[0].Text = lead.String() + toks[0].Text
+	}
+	return toks
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Align computes a minimum edit alignment of hyp against ref.
+func Align(ref, hyp string) (*Alignment, error) {
+	rt, ht := Tokenize(ref), Tokenize(hyp)
+	n, m := len(rt), len(ht)
+	if n*m > maxCells {
+		return nil, fmt.Errorf("texts too long to align: %d × %d tokens", n, m)
+	}
+
+	// Edit distance with one backpointer byte per cell.
+	const (
+		diag = iota
+		up   // Deletion
+		left // Insertion
+	)
+	back := make([]byte, (n+1)*(m+1))
+	prev := make([]int, m+1)
+	cur := make([]int, m+1)
+	for j := 1; j <= m; j++ {
+		prev[j] = j
+		back[j] = left
+	}
+	for i := 1; i <= n; i++ {
+		cur[0] = i
+		back[i*(m+1)] = up
+		for j := 1; j <= m; j++ {
+			cost := prev[j-1]
+			if rt[i-1].Key != ht[j-1].Key {
+				cost++
+			}
+			dir := byte(diag)
+			if d := prev[j] + 1; d < cost {
+				cost, dir = d, up
+			}
+			if d := cur[j-1] + 1; d < cost {
+				cost, dir = d, left
+			}
+			cur[j] = cost
+			back[i*(m+1)+j] = dir
+		}
+		prev, cur = cur, prev
+	}
+
+	// Walk back, emitting ops in reverse.
+	type step struct {
+		op     Op
+		ri, hi int // Token indices, -1 when absent
+	}
+	var steps []step
+	for i, j := n, m; i > 0 || j > 0; {
+		switch back[i*(m+1)+j] {
+		case diag:
+			op := OpEqual
+			if rt[i-1].Key != ht[j-1].Key {
+				op = OpReplace
+			}
+			steps = append(steps, step{op, i - 1, j - 1})
+			i, j = i-1, j-1
+		case up:
+			steps = append(steps, step{OpDelete, i - 1, -1})
+			i--
+		default:
+			steps = append(steps, step{OpInsert, -1, j - 1})
+			j--
+		}
+	}
+
+	a := &Alignment{RefTokens: n}
+	for k := len(steps) - 1; k >= 0; k-- {
+		st := steps[k]
+		var r, h string
+		if st.ri >= 0 {
+			r = rt[st.ri].Text
+		}
+		if st.hi >= 0 {
+			h = ht[st.hi].Text
+		}
+		switch st.op {
+		case OpReplace:
+			a.Sub++
+		case OpDelete:
+			a.Del++
+		case OpInsert:
+			a.Ins++
+		}
+		a.appendSpan(st.op, r, h)
+	}
+	if n > 0 {
+		a.ErrorRate = float64(a.Sub+a.Del+a.Ins) / float64(n)
+	}
+	return a, nil
+}
+
+// appendSpan adds a step, merging it into the previous span when both are
+// equal or both are edits. Adjacent edits of different kinds merge into a
+// single replace span.
+func (a *Alignment) appendSpan(op Op, ref, hyp string) {
+	if k := len(a.Spans) - 1; k >= 0 {
+		last := &a.Spans[k]
+		if (last.Op == OpEqual) == (op == OpEqual) {
+			last.Ref += ref
+			last.Hyp += hyp
+			if last.Op != op {
+				last.Op = OpReplace
+			}
+			return
+		}
+	}
+	a.Spans = append(a.Spans, Span{Op: op, Ref: ref, Hyp: hyp})
+}
@@ -0,0 +1,79 @@
+package textdiff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAlign(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref, hyp string
+		want     *Alignment
+	}{
+		{
+			name: "english",
+			ref:  "Hello, big world.",
+			hyp:  "hello small world and more",
+			want: &Alignment{
+				Spans: []Span{
+					{Op: OpEqual, Ref: "Hello, ", Hyp: "hello "},
+					{Op: OpReplace, Ref: "big ", Hyp: "small "},
+					{Op: OpEqual, Ref: "world.", Hyp: "world "},
+					{Op: OpInsert, Hyp: "and more"},
+				},
+				RefTokens: 3, Sub: 1, Ins: 2, ErrorRate: 1,
+			},
+		},
+		{
+			name: "cjk",
+			ref:  "今天天气很好",
+			hyp:  "今天天很好啊",
+			want: &Alignment{
+				Spans: []Span{
+					{Op: OpEqual, Ref: "今天天", Hyp: "今天天"},
+					{Op: OpDelete, Ref: "气"},
+					{Op: OpEqual, Ref: "很好", Hyp: "很好"},
+					{Op: OpInsert, Hyp: "啊"},
+				},
+				RefTokens: 6, Del: 1, Ins: 1, ErrorRate: 2.0 / 6,
+			},
+		},
+		{
+			name: "mixed edits merge",
+			ref:  "a b c",
+			hyp:  "x",
+			want: &Alignment{
+				Spans:     []Span{{Op: OpReplace, Ref: "a b c", Hyp: "x"}},
+				RefTokens: 3, Sub: 1, Del: 2, ErrorRate: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Align(tt.ref, tt.hyp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Align() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInline(t *testing.T) {
+	a, err := Align("Hello, big world. 今天天气", "hello small world 今天天 and more")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "hello [-big-]{+small+} world 今天天 [-气-]{+and more+}"
+	if got := a.Inline(false); got != want {
+		t.Errorf("Inline(false) = %q, want %q", got, want)
+	}
+	want = "hello \x1b[31;9mbig\x1b[0m\x1b[32msmall\x1b[0m world 今天天 \x1b[31;9m气\x1b[0m\x1b[32mand more\x1b[0m"
+	if got := a.Inline(true); got != want {
+		t.Errorf("Inline(true) = %q, want %q", got, want)
+	}
+}
@@ -1,26 +1,49 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
-	"os"
+	"net"
 	"time"
 
 	"github.com/gorilla/websocket"
 
+	"asr-eval/pkg/audio"
 	"asr-eval/pkg/volc/common"
 	"asr-eval/pkg/volc/request"
 	"asr-eval/pkg/volc/response"
 )
 
+// Timeouts bound the stages of a session, so a stalled server fails the
+// session instead of hanging it. Zero fields take the defaults.
+type Timeouts struct {
+	Connect   time.Duration // Per dial attempt; default 10s
+	Handshake time.Duration // For the response to the full client request; default 10s
+	Write     time.Duration // Per message sent; default 10s
+	Response  time.Duration // Between responses, and for the last one after the audio ends; default 60s
+}
+
+var defaultTimeouts = Timeouts{
+	Connect:   10 * time.Second,
+	Handshake: 10 * time.Second,
+	Write:     10 * time.Second,
+	// The server answers at least every 15 s of audio, and audio is sent
+	// no faster than real time.
+	Response: 60 * time.Second,
+}
+
 type AsrWsClient struct {
 	seq             int
 	segmentDuration int
 	url             string
 	connect         *websocket.Conn
 	context         string
+	onSessionStart  func()
+	timeouts        Timeouts
 }
 
 func NewAsrWsClient(url string, segmentDuration int) *AsrWsClient {
@@ -28,6 +51,7 @@ func NewAsrWsClient(url string, segmentDuration int) *AsrWsClient {
 		seq:             1,
 		url:             url,
 		segmentDuration: segmentDuration,
+		timeouts:        defaultTimeouts,
 	}
 }
 
@@ -35,17 +59,33 @@ func (c *AsrWsClient) SetContext(ctx string) {
 	c.context = ctx
 }
 
-func (c *AsrWsClient) readAudioData(filePath string) ([]byte, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		log.Fatalf("failed to read file: %s", err)
+// SetOnSessionStart sets f to be called once the session is set up, after
+// which the request package's settings are no longer read.
+func (c *AsrWsClient) SetOnSessionStart(f func()) {
+	c.onSessionStart = f
+}
+
+// SetTimeouts overrides the default stage timeouts with the fields set in
+// t.
+func (c *AsrWsClient) SetTimeouts(t Timeouts) {
+	if t.Connect > 0 {
+		c.timeouts.Connect = t.Connect
 	}
-	isWav := common.JudgeWav(content)
-	if !isWav {
-		content, err = common.ConvertWavWithPath(filePath, common.DefaultSampleRate)
-		if err != nil {
-			return nil, fmt.Errorf("convert wav err: %w", err)
-		}
+	if t.Handshake > 0 {
+		c.timeouts.Handshake = t.Handshake
+	}
+	if t.Write > 0 {
+		c.timeouts.Write = t.Write
+	}
+	if t.Response > 0 {
+		c.timeouts.Response = t.Response
+	}
+}
+
+func (c *AsrWsClient) readAudioData(ctx context.Context, filePath string) ([]byte, error) {
+	content, err := audio.ToWAV(ctx, filePath, common.DefaultSampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("convert wav err: %w", err)
 	}
 	return content, nil
 }
@@ -77,8 +117,13 @@ func (c *AsrWsClient) createConnection(ctx context.Context) error {
 		}
 
 		header := request.NewAuthHeader()
-		conn, resp, err := websocket.DefaultDialer.DialContext(ctx, c.url, header)
+		dialCtx, cancel := context.WithTimeout(ctx, c.timeouts.Connect)
+		conn, resp, err := websocket.DefaultDialer.DialContext(dialCtx, c.url, header)
+		cancel()
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			lastErr = fmt.Errorf("dial websocket err: %w", err)
 			continue
 		}
@@ -97,101 +142,155 @@ func (c *AsrWsClient) sendFullClientRequest() error {
 		},
 	})
 	c.seq++
-	err := c.connect.WriteMessage(websocket.BinaryMessage, fullClientRequest)
-	if err != nil {
+	if err := c.write(websocket.BinaryMessage, fullClientRequest); err != nil {
 		return fmt.Errorf("full client message write websocket err: %w", err)
 	}
+	c.connect.SetReadDeadline(time.Now().Add(c.timeouts.Handshake))
 	_, resp, err := c.connect.ReadMessage()
 	if err != nil {
-		return fmt.Errorf("full client message read err: %w", err)
+		return fmt.Errorf("full client message read err: %w", timeoutErr(err, c.timeouts.Handshake))
 	}
 	respStruct := response.ParseResponse(resp)
 	log.Println(respStruct)
+	if respStruct.Code != 0 {
+		msg := ""
+		if respStruct.PayloadMsg != nil {
+			msg = respStruct.PayloadMsg.Error
+		}
+		return fmt.Errorf("full client request rejected: %d: %s", respStruct.Code, msg)
+	}
 	return nil
 }
 
-func (c *AsrWsClient) sendMessages(segmentSize int, content []byte, stopChan <-chan struct{}) error {
-	messageChan := make(chan []byte)
-	go func() {
-		for message := range messageChan {
-			err := c.connect.WriteMessage(websocket.TextMessage, message)
-			if err != nil {
-				log.Printf("write message err: %s", err)
-				return
-			}
-		}
-	}()
+// write sends a message within the write timeout.
+func (c *AsrWsClient) write(messageType int, data []byte) error {
+	c.connect.SetWriteDeadline(time.Now().Add(c.timeouts.Write))
+	return timeoutErr(c.connect.WriteMessage(messageType, data), c.timeouts.Write)
+}
 
-	audioSegments := splitAudio(content, segmentSize)
+// timeoutErr tells a deadline of d passing apart from other errors.
+// websocket hides the net package's error for one of its own.
+func timeoutErr(err error, d time.Duration) error {
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return fmt.Errorf("timed out after %v: %w", d, err)
+	}
+	return err
+}
 
+// sendMessages sends the audio read from r in segments, paced to real time
+// if it is all at hand; the last segment carries a negative sequence number.
+// It returns early, without error, once stopChan is closed.
+func (c *AsrWsClient) sendMessages(segmentSize int, r io.Reader, paced bool, stopChan <-chan struct{}) error {
 	ticker := time.NewTicker(time.Duration(c.segmentDuration) * time.Millisecond)
 	defer ticker.Stop()
-	defer close(messageChan)
-	log.Printf("Start sending audio segments. Total segments: %d, Segment size: %d", len(audioSegments), segmentSize)
-	for _, segment := range audioSegments {
-		select {
-		case <-ticker.C:
-			if c.seq == len(audioSegments)+1 {
-				c.seq = -c.seq
+	log.Printf("Start sending audio segments. Segment size: %d", segmentSize)
+	// Read a segment ahead to know which one is last.
+	segment, err := readSegment(r, segmentSize)
+	if err != nil {
+		return err
+	}
+	for len(segment) > 0 {
+		next, err := readSegment(r, segmentSize)
+		if err != nil {
+			return err
+		}
+		if paced {
+			select {
+			case <-ticker.C:
+			case <-stopChan:
+				log.Println("Stop signal received in sendMessages")
+				return nil
 			}
-			message := request.NewAudioOnlyRequest(c.seq, segment)
-			messageChan <- message
-			log.Printf("Sent segment seq: %d / %d", c.seq, len(audioSegments))
-			c.seq++
+		}
+		// Checked again after the tick, which may have come with the stop.
+		select {
 		case <-stopChan:
 			log.Println("Stop signal received in sendMessages")
 			return nil
+		default:
+		}
+		if len(next) == 0 {
+			c.seq = -c.seq
 		}
+		if err := c.write(websocket.TextMessage, request.NewAudioOnlyRequest(c.seq, segment)); err != nil {
+			return fmt.Errorf("write segment %d err: %w", c.seq, err)
+		}
+		log.Printf("Sent segment seq: %d", c.seq)
+		c.seq++
+		segment = next
 	}
 	log.Println("Finished sending all segments")
 	return nil
 }
 
-func (c *AsrWsClient) recvMessages(resChan chan<- *response.AsrResponse, stopChan chan<- struct{}) {
-	defer close(resChan)
+// readSegment reads up to size bytes from r, fewer only at its end.
+func readSegment(r io.Reader, size int) ([]byte, error) {
+	segment := make([]byte, size)
+	n, err := io.ReadFull(r, segment)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("read audio err: %w", err)
+	}
+	return segment[:n], nil
+}
+
+// recvMessages passes the responses on to resChan until the last one or
+// one with an error code. It fails when the connection does, or when no
+// response comes within the response timeout.
+func (c *AsrWsClient) recvMessages(resChan chan<- *response.AsrResponse) error {
 	for {
+		c.connect.SetReadDeadline(time.Now().Add(c.timeouts.Response))
 		_, message, err := c.connect.ReadMessage()
 		if err != nil {
-			log.Printf("ReadMessage error: %v", err)
-			return
+			return fmt.Errorf("read response err: %w", timeoutErr(err, c.timeouts.Response))
 		}
 		resp := response.ParseResponse(message)
+		textLen := 0
+		if resp.PayloadMsg != nil {
+			textLen = len(resp.PayloadMsg.Result.Text)
+		}
 		log.Printf("Received response: Seq=%d, Code=%d, TextLen=%d, IsLast=%v",
-			resp.PayloadSequence, resp.Code, len(resp.PayloadMsg.Result.Text), resp.IsLastPackage)
+			resp.PayloadSequence, resp.Code, textLen, resp.IsLastPackage)
 		resChan <- resp
 		if resp.IsLastPackage {
 			log.Println("Received last package")
-			return
+			return nil
 		}
 		if resp.Code != 0 {
-			close(stopChan)
-			return
+			return nil
 		}
 	}
 }
 
-func (c *AsrWsClient) startAudioStream(segmentSize int, content []byte, resChan chan<- *response.AsrResponse) error {
+// startAudioStream sends the audio while receiving the responses, and
+// returns once both are done. A failure to send closes the connection, so
+// receiving does not wait on a server that will never answer.
+func (c *AsrWsClient) startAudioStream(segmentSize int, r io.Reader, paced bool, resChan chan<- *response.AsrResponse) error {
 	stopChan := make(chan struct{})
+	sendErr := make(chan error, 1)
 	go func() {
-		err := c.sendMessages(segmentSize, content, stopChan)
+		err := c.sendMessages(segmentSize, r, paced, stopChan)
 		if err != nil {
-			log.Fatalf("failed to send audio stream: %s", err)
-			return
+			c.connect.Close()
 		}
+		sendErr <- err
 	}()
-	c.recvMessages(resChan, stopChan)
-	return nil
+	recvErr := c.recvMessages(resChan)
+	close(stopChan)
+	if err := <-sendErr; err != nil {
+		return fmt.Errorf("send audio stream err: %w", err)
+	}
+	return recvErr
 }
 
+// Excute transcribes the audio file at filePath, passing the responses to
+// resChan, which it closes when it returns. It stops when ctx is done.
 func (c *AsrWsClient) Excute(ctx context.Context, filePath string, resChan chan<- *response.AsrResponse) error {
+	defer close(resChan)
 	if filePath == "" {
 		return errors.New("file path is empty")
 	}
-	c.seq = 1
-	if c.url == "" {
-		return errors.New("url is empty")
-	}
-	content, err := c.readAudioData(filePath)
+	content, err := c.readAudioData(ctx, filePath)
 	if err != nil {
 		return fmt.Errorf("read audio data err: %w", err)
 	}
@@ -199,33 +298,53 @@ func (c *AsrWsClient) Excute(ctx context.Context, filePath string, resChan chan<
 	if err != nil {
 		return fmt.Errorf("get segment size err: %w", err)
 	}
+	return c.run(ctx, bytes.NewReader(content), segmentSize, true, resChan)
+}
 
-	err = c.createConnection(ctx)
+// ExcuteStream sends 16-bit mono PCM at common.DefaultSampleRate read from
+// r until EOF, as it arrives, behind a WAV header of unknown length. As
+// with Excute, resChan is closed when it returns. A read from r blocked
+// when ctx is done holds it up until r returns.
+func (c *AsrWsClient) ExcuteStream(ctx context.Context, r io.Reader, resChan chan<- *response.AsrResponse) error {
+	defer close(resChan)
+	header := (&audio.PCM{SampleRate: common.DefaultSampleRate, Channels: 1}).WAV()
+	segmentSize := common.DefaultSampleRate * 2 * c.segmentDuration / 1000
+	return c.run(ctx, io.MultiReader(bytes.NewReader(header), r), segmentSize, false, resChan)
+}
+
+func (c *AsrWsClient) run(ctx context.Context, r io.Reader, segmentSize int, paced bool, resChan chan<- *response.AsrResponse) error {
+	c.seq = 1
+	if c.url == "" {
+		return errors.New("url is empty")
+	}
+	err := c.createConnection(ctx)
 	if err != nil {
 		return fmt.Errorf("create connection err: %w", err)
 	}
+	defer c.connect.Close()
+	// Closing the connection unblocks whatever waits on it.
+	stop := context.AfterFunc(ctx, func() { c.connect.Close() })
+	defer stop()
+
 	err = c.sendFullClientRequest()
 	if err != nil {
-		return fmt.Errorf("send full request err: %w", err)
+		return fmt.Errorf("send full request err: %w", ctxErr(ctx, err))
 	}
-	err = c.startAudioStream(segmentSize, content, resChan)
+	if c.onSessionStart != nil {
+		c.onSessionStart()
+	}
+	err = c.startAudioStream(segmentSize, r, paced, resChan)
 	if err != nil {
-		return fmt.Errorf("start audio stream err: %w", err)
+		return fmt.Errorf("start audio stream err: %w", ctxErr(ctx, err))
 	}
 	return nil
 }
 
-func splitAudio(data []byte, segmentSize int) [][]byte {
-	if segmentSize <= 0 {
-		return nil // 返回空切片，如果 chunkSize 非法
-	}
-	var segments [][]byte
-	for i := 0; i < len(data); i += segmentSize {
-		end := i + segmentSize
-		if end > len(data) {
-			end = len(data)
-		}
-		segments = append(segments, data[i:end])
+// ctxErr returns the error of ctx in place of err once ctx is done, since
+// err then most likely comes from the connection closing for it.
+func ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
-	return segments
+	return err
 }
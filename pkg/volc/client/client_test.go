@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/volc/response"
+)
+
+// Empty full server responses, the second marked last.
+var (
+	okResponse   = []byte{0x11, 0x90, 0x00, 0x00, 0, 0, 0, 0}
+	lastResponse = []byte{0x11, 0x92, 0x00, 0x00, 0, 0, 0, 0}
+)
+
+// fakeServer answers the full client request with okResponse when
+// handshake is set, then sends replies, and then reads whatever comes
+// until the test ends without answering.
+func fakeServer(t *testing.T, handshake bool, replies ...[]byte) string {
+	done := make(chan struct{})
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		if handshake {
+			for _, m := range append([][]byte{okResponse}, replies...) {
+				if err := conn.WriteMessage(websocket.BinaryMessage, m); err != nil {
+					return
+				}
+			}
+		}
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+		<-done
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(done) })
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestExcuteTimeouts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.wav")
+	pcm := &audio.PCM{SampleRate: 16000, Channels: 1, Samples: make([]int16, 16000)}
+	if err := os.WriteFile(path, pcm.WAV(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		handshake bool
+		replies   [][]byte
+		ctx       time.Duration // Deadline of the context; 0 has none
+		wantErr   string
+	}{
+		{name: "complete", handshake: true, replies: [][]byte{lastResponse}},
+		{name: "stalled handshake", wantErr: "full client message read err: timed out"},
+		{name: "stalled responses", handshake: true, wantErr: "read response err: timed out"},
+		{name: "canceled", ctx: 100 * time.Millisecond, wantErr: context.DeadlineExceeded.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewAsrWsClient(fakeServer(t, tt.handshake, tt.replies...), 200)
+			timeout := 300 * time.Millisecond
+			if tt.ctx > 0 {
+				timeout = time.Minute
+			}
+			c.SetTimeouts(Timeouts{Handshake: timeout, Response: timeout})
+			ctx := context.Background()
+			if tt.ctx > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.ctx)
+				defer cancel()
+			}
+
+			resChan := make(chan *response.AsrResponse)
+			go func() {
+				for range resChan {
+				}
+			}()
+			start := time.Now()
+			err := c.Excute(ctx, path, resChan)
+			if took := time.Since(start); took > 5*time.Second {
+				t.Errorf("Excute() took %v", took)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Excute() = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Excute() = %v, want an error with %q", err, tt.wantErr)
+			}
+			if tt.ctx > 0 && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Excute() = %v, want the context's error", err)
+			}
+		})
+	}
+}
@@ -3,14 +3,13 @@ package common
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
-	"os/exec"
-	"strconv"
-	"time"
+
+	"asr-eval/pkg/audio"
 )
 
 const DefaultSampleRate = 16000
@@ -71,46 +70,10 @@ func JudgeWav(data []byte) bool {
 	return false
 }
 
+// ConvertWavWithPath returns the audio file at audioPath as 16-bit mono
+// WAV at sampleRate. See audio.ToWAV.
 func ConvertWavWithPath(audioPath string, sampleRate int) ([]byte, error) {
-	cmd := exec.Command("ffmpeg", "-v", "quiet", "-y", "-i", audioPath, "-acodec",
-		"pcm_s16le", "-ac", "1", "-ar", strconv.Itoa(sampleRate), "-f", "wav", "-")
-
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	err := cmd.Start()
-	if err != nil {
-		return nil, fmt.Errorf("command start error: %v", err)
-	}
-
-	done := make(chan error)
-	go func() {
-		done <- cmd.Wait()
-	}()
-
-	select {
-	case <-time.After(60 * time.Second):
-		if err := cmd.Process.Kill(); err != nil {
-			fmt.Printf("failed to kill process: %v\n", err)
-		}
-		<-done
-		return nil, fmt.Errorf("process killed as timeout reached")
-	case err := <-done:
-		if err != nil {
-			return nil, fmt.Errorf("process run error: %v", err)
-		}
-	}
-
-	if _, err := os.Stat(audioPath); err == nil {
-		// Do NOT remove the original file. The user's files are precious.
-		// The original SDK deleted the file because it assumed temporary usage?
-		// Comment out deletion.
-		// if removeErr := os.Remove(audioPath); removeErr != nil {
-		// 	fmt.Printf("failed to remove original file: %v\n", removeErr)
-		// }
-	}
-
-	return out.Bytes(), nil
+	return audio.ToWAV(context.Background(), audioPath, sampleRate)
 }
 
 type WavHeader struct {
@@ -0,0 +1,73 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+)
+
+// AlignContext times the checkpoints of a case's context, and its audio
+// reality inference, in the case audio with the generation model, and
+// saves the context with the times. Its hash stays the same, as providers
+// are scored against the same text, so its reports do not go stale.
+func (s *Service) AlignContext(ctx context.Context, req AlignContextRequest) (*evalv2.EvalContext, error) {
+	if s.LLM == nil {
+		return nil, fmt.Errorf("LLM client not initialized")
+	}
+	audioPath := filepath.Join(s.Config.DatasetDir, req.ID+extFlac)
+	if _, err := os.Stat(audioPath); err != nil {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+	ec, err := s.loadEvalContext(ctx, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("no context for case %s", req.ID)
+	}
+	release, err := s.Config.EvalSlots.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	aligned, _, err := s.newEvaluator().Align(s.withUsage(ctx, req.ID, StepAlignContext), audioPath, ec)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := audio.ReadInfo(audioPath); err == nil && info.DurationMS > 0 {
+		clampAlignment(aligned, int(info.DurationMS))
+	}
+
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	// The model takes a while; an edit made meanwhile wins.
+	if cur, err := s.loadEvalContext(ctx, req.ID); err != nil || contextHash(cur) != contextHash(ec) {
+		return nil, fmt.Errorf("context of case %s changed while aligning it", req.ID)
+	}
+	aligned.Hash = contextHash(aligned)
+	if err := s.writeEvalContext(ctx, req.ID, aligned); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditAlignContext, req.ID, map[string]string{"model": aligned.Meta.AlignModel})
+	return aligned, nil
+}
+
+// clampAlignment keeps the times of ec within audio durationMS long.
+func clampAlignment(ec *evalv2.EvalContext, durationMS int) {
+	for _, cp := range ec.Checkpoints {
+		if cp.Span != nil {
+			cp.Span.StartMS = min(cp.Span.StartMS, durationMS)
+			cp.Span.EndMS = min(cp.Span.EndMS, durationMS)
+		}
+	}
+	for i := range ec.AudioRealitySpans {
+		t := &ec.AudioRealitySpans[i]
+		t.StartMS = min(t.StartMS, durationMS)
+		t.EndMS = min(t.EndMS, durationMS)
+	}
+}
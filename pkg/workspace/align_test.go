@@ -0,0 +1,75 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/llmclient"
+)
+
+// alignLLM times S1 at 0.5-4 s and S2 at 9-20 s.
+type alignLLM struct{}
+
+func (alignLLM) GenerateJSON(ctx context.Context, model string, req *llmclient.Request, resp any) (*llmclient.Usage, error) {
+	return &llmclient.Usage{}, json.Unmarshal([]byte(`{
+		"checkpoints": [{"id": "S1", "start_ms": 500, "end_ms": 4000}, {"id": "S2", "start_ms": 9000, "end_ms": 20000}],
+		"audio_reality": [{"start_ms": 500, "end_ms": 20000, "text": "hello there"}]
+	}`), resp)
+}
+
+func (alignLLM) CountTokens(ctx context.Context, model string, req *llmclient.Request) (int, error) {
+	return 0, nil
+}
+
+func TestAlignContext(t *testing.T) {
+	dir := t.TempDir()
+	pcm := &audio.PCM{SampleRate: 8000, Channels: 1, Samples: make([]int16, 10*8000)}
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), pcm.FLAC(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir, GenModel: "gen"}, Storage: NewFSStorage(dir), LLM: alignLLM{}}
+	ctx := context.Background()
+	ec := &evalv2.EvalContext{Checkpoints: []evalv2.Checkpoint{{ID: "S1", TextSegment: "hello"}, {ID: "S2", StartMS: 3000, TextSegment: "there"}}}
+	ec.Meta.AudioRealityInference = "hello there"
+	ec.Hash = contextHash(ec)
+	if err := s.Storage.PutContext(ctx, "a", ec); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.AlignContext(ctx, AlignContextRequest{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.loadEvalContext(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Times past the 10 s of audio are cut to it.
+	var spans []*evalv2.Span
+	for _, cp := range got.Checkpoints {
+		spans = append(spans, cp.Span)
+	}
+	if diff := cmp.Diff([]*evalv2.Span{{StartMS: 500, EndMS: 4000}, {StartMS: 9000, EndMS: 10000}}, spans); diff != "" {
+		t.Errorf("checkpoint spans mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]evalv2.TimedText{{StartMS: 500, EndMS: 10000, Text: "hello there"}}, got.AudioRealitySpans); diff != "" {
+		t.Errorf("audio reality spans mismatch (-want +got):\n%s", diff)
+	}
+	if got.Hash != ec.Hash || got.Meta.AlignModel != "gen" {
+		t.Errorf("aligned context hash = %s by %q, want the unaligned %s by gen", got.Hash, got.Meta.AlignModel, ec.Hash)
+	}
+	// The unaligned context stays in the store under the same hash.
+	if c, err := resolveContext(dir, &evalv2.EvalContext{Hash: ec.Hash}); err != nil || c.Aligned() {
+		t.Errorf("stored context %s: %v, aligned %v; want the unaligned one", ec.Hash, err, c != nil && c.Aligned())
+	}
+
+	if _, err := s.AlignContext(ctx, AlignContextRequest{ID: "b"}); err == nil {
+		t.Error("AlignContext() of a missing case succeeded")
+	}
+}
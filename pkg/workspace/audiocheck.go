@@ -0,0 +1,120 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"asr-eval/pkg/audio"
+)
+
+// Audio issues, for which the manifest flags a case and provider
+// comparisons leave it out: a recording that is resampled, clipped or
+// silent drags every provider's score down for reasons none of them
+// controls.
+const (
+	AudioIssueSampleRate = "sample_rate" // Not the dataset's rate
+	AudioIssueClipped    = "clipped"
+	AudioIssueSilent     = "silent"
+)
+
+// AudioIssuesRequest selects what AudioIssues reports.
+type AudioIssuesRequest struct {
+	IDs []string // Only these cases; empty reports all
+}
+
+// AudioIssuesResponse lists the cases flagged for their audio.
+type AudioIssuesResponse struct {
+	SampleRate int             `json:"sample_rate"` // The rate expected of the dataset's audio
+	Cases      []*FlaggedAudio `json:"cases"`       // Sorted by ID
+}
+
+// FlaggedAudio is a case with audio issues.
+type FlaggedAudio struct {
+	ID         string        `json:"id"`
+	Issues     []string      `json:"issues"`
+	SampleRate int           `json:"sample_rate,omitempty"`
+	Levels     *audio.Levels `json:"levels,omitempty"`
+}
+
+// AudioIssues lists the cases the dataset manifest flags for audio issues,
+// checked when their audio is added or changed, so an import can be
+// reviewed before its recordings are evaluated.
+func (s *Service) AudioIssues(ctx context.Context, req AudioIssuesRequest) (*AudioIssuesResponse, error) {
+	m, err := s.Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf("audio checks need the dataset manifest, which %T does not keep", s.Storage)
+	}
+	resp := &AudioIssuesResponse{SampleRate: expectedSampleRate(m, s.Config.SampleRate), Cases: []*FlaggedAudio{}}
+	for _, e := range m.Cases {
+		if len(e.AudioIssues) == 0 || len(req.IDs) > 0 && !slices.Contains(req.IDs, e.ID) {
+			continue
+		}
+		resp.Cases = append(resp.Cases, &FlaggedAudio{ID: e.ID, Issues: e.AudioIssues, SampleRate: e.SampleRate, Levels: e.Levels})
+	}
+	return resp, nil
+}
+
+// flaggedAudio returns the IDs of the cases the manifest flags for audio
+// issues. Without a manifest none are.
+func (s *Service) flaggedAudio(ctx context.Context) map[string]bool {
+	m, err := s.Manifest(ctx)
+	if err != nil || m == nil {
+		return nil
+	}
+	flagged := make(map[string]bool)
+	for _, e := range m.Cases {
+		if len(e.AudioIssues) > 0 {
+			flagged[e.ID] = true
+		}
+	}
+	return flagged
+}
+
+// flagAudio sets the AudioIssues of every entry of m, expecting audio at
+// rate, or at the rate most of the dataset has when it is 0. It reports
+// whether any changed.
+func flagAudio(m *Manifest, rate int) bool {
+	rate = expectedSampleRate(m, rate)
+	changed := false
+	for _, e := range m.Cases {
+		var issues []string
+		if e.SampleRate > 0 && e.SampleRate != rate {
+			issues = append(issues, AudioIssueSampleRate)
+		}
+		if e.Levels != nil && e.Levels.Clipped() {
+			issues = append(issues, AudioIssueClipped)
+		}
+		if e.Levels != nil && e.Levels.Silent() {
+			issues = append(issues, AudioIssueSilent)
+		}
+		if !slices.Equal(issues, e.AudioIssues) {
+			e.AudioIssues = issues
+			changed = true
+		}
+	}
+	return changed
+}
+
+// expectedSampleRate returns rate, or when it is 0 the most common sample
+// rate in m, the higher one on a tie.
+func expectedSampleRate(m *Manifest, rate int) int {
+	if rate > 0 {
+		return rate
+	}
+	counts := make(map[int]int)
+	for _, e := range m.Cases {
+		if e.SampleRate > 0 {
+			counts[e.SampleRate]++
+		}
+	}
+	for r, n := range counts {
+		if n > counts[rate] || n == counts[rate] && r > rate {
+			rate = r
+		}
+	}
+	return rate
+}
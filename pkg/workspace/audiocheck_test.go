@@ -0,0 +1,87 @@
+package workspace
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/audio"
+)
+
+func TestAudioIssues(t *testing.T) {
+	dir := t.TempDir()
+	// A second of a tone at amp, at rate.
+	tone := func(rate int, amp float64) *audio.PCM {
+		p := &audio.PCM{SampleRate: rate, Channels: 1, Samples: make([]int16, rate)}
+		for i := range p.Samples {
+			v := amp * math.Sin(2*math.Pi*440*float64(i)/float64(rate))
+			p.Samples[i] = int16(max(min(v, math.MaxInt16), math.MinInt16))
+		}
+		return p
+	}
+	for id, p := range map[string]*audio.PCM{
+		"a": tone(16000, 8000),
+		"b": tone(8000, 8000),
+		"c": tone(16000, 50),
+		"d": tone(16000, 2*math.MaxInt16),
+		"e": tone(16000, 8000),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, id+".flac"), p.FLAC(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		report := `{"evaluations":{"dg":{"metrics":{"s_score":1,"p_score":1}}},"context_snapshot":{"meta":{"total_token_count_estimate":10}}}`
+		if err := os.WriteFile(filepath.Join(dir, id+".report.v2.json"), []byte(report), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+
+	issues := func(req AudioIssuesRequest) map[string][]string {
+		resp, err := s.AudioIssues(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string][]string)
+		for _, c := range resp.Cases {
+			got[c.ID] = c.Issues
+		}
+		return got
+	}
+	want := map[string][]string{
+		"b": {AudioIssueSampleRate},
+		"c": {AudioIssueSilent},
+		"d": {AudioIssueClipped},
+	}
+	if diff := cmp.Diff(want, issues(AudioIssuesRequest{})); diff != "" {
+		t.Errorf("AudioIssues() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string][]string{"c": {AudioIssueSilent}}, issues(AudioIssuesRequest{IDs: []string{"a", "c"}})); diff != "" {
+		t.Errorf("AudioIssues() of a and c mismatch (-want +got):\n%s", diff)
+	}
+
+	// The leaderboard counts only the cases not flagged.
+	lb, err := s.Leaderboard(ctx, CaseFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lb) != 1 || lb[0].Cases != 2 {
+		t.Errorf("Leaderboard() = %+v, want dg over a and e", lb)
+	}
+
+	// A configured rate takes over from the most common one.
+	s.Config.SampleRate = 8000
+	want = map[string][]string{
+		"a": {AudioIssueSampleRate},
+		"c": {AudioIssueSampleRate, AudioIssueSilent},
+		"d": {AudioIssueSampleRate, AudioIssueClipped},
+		"e": {AudioIssueSampleRate},
+	}
+	if diff := cmp.Diff(want, issues(AudioIssuesRequest{})); diff != "" {
+		t.Errorf("AudioIssues() at 8 kHz mismatch (-want +got):\n%s", diff)
+	}
+}
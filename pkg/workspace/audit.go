@@ -0,0 +1,62 @@
+package workspace
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// auditFileName is the append-only audit log in the dataset directory, one
+// JSON entry per line.
+const auditFileName = ".audit.jsonl"
+
+// Audited actions.
+const (
+	AuditCreateCase         = "create_case"
+	AuditDeleteCase         = "delete_case"
+	AuditUpdateContext      = "update_context"
+	AuditRestoreContext     = "restore_context"
+	AuditResetReport        = "reset_report"
+	AuditRollbackReport     = "rollback_report"
+	AuditResetResults       = "reset_results"
+	AuditUpdateTags         = "update_tags"
+	AuditTranscribe         = "transcribe"
+	AuditOverrideCheckpoint = "override_checkpoint"
+	AuditUpdateReview       = "update_review"
+	AuditUpdateCorrection   = "update_correction"
+	AuditUpdateChannels     = "update_channels"
+	AuditAlignContext       = "align_context"
+	AuditImportCase         = "import_case"
+	AuditRestoreBackup      = "restore_backup"
+	AuditRenameCase         = "rename_case"
+	AuditRecompute          = "recompute"
+)
+
+// AuditEntry records one change to a case.
+type AuditEntry struct {
+	Time    time.Time         `json:"time"`
+	User    string            `json:"user,omitempty"` // Empty when authentication is disabled
+	Action  string            `json:"action"`
+	CaseID  string            `json:"case_id"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// audit appends an entry for the user of ctx. Failures are logged rather
+// than returned: the change itself already happened.
+func (s *Service) audit(ctx context.Context, action, caseID string, details map[string]string) {
+	err := s.Storage.AppendAudit(ctx, &AuditEntry{
+		Time:    time.Now().UTC(),
+		User:    UserFromContext(ctx),
+		Action:  action,
+		CaseID:  caseID,
+		Details: details,
+	})
+	if err != nil {
+		slog.Error("Failed to write audit log", "dataset", s.Config.DatasetDir, "error", err)
+	}
+}
+
+// ListAudit returns audit entries matching req, newest first.
+func (s *Service) ListAudit(ctx context.Context, req ListAuditRequest) ([]*AuditEntry, error) {
+	return s.Storage.ListAudit(ctx, req)
+}
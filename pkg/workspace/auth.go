@@ -0,0 +1,186 @@
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"asr-eval/pkg/workspace/workspacepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyCookie carries the API key for requests the browser makes on its
+// own, such as <audio> sources.
+const apiKeyCookie = "asr_eval_key"
+
+// Role is what an authenticated user may do.
+type Role string
+
+const (
+	RoleViewer Role = "viewer" // Read-only: browse cases, stats and audio
+	RoleEditor Role = "editor" // Everything, including writes and evaluations
+)
+
+// readOnlyRPCs are the gRPC methods viewers may call.
+var readOnlyRPCs = map[string]bool{
+	workspacepb.Workspace_ListCases_FullMethodName:   true,
+	workspacepb.Workspace_GetCase_FullMethodName:     true,
+	workspacepb.Workspace_Leaderboard_FullMethodName: true,
+	workspacepb.Workspace_HeadToHead_FullMethodName:  true,
+}
+
+// Authenticator checks API keys on API and audio requests.
+type Authenticator struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	user string
+	key  []byte
+	role Role
+}
+
+type userKey struct{}
+
+type roleKey struct{}
+
+// LoadAuthenticator reads a users file with one "user:key" or
+// "user:key:role" entry per line; the role is viewer or editor, and
+// defaults to editor. Blank lines and lines starting with # are ignored.
+func LoadAuthenticator(path string) (*Authenticator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a := &Authenticator{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, key, ok := strings.Cut(line, ":")
+		role := RoleEditor
+		if i := strings.LastIndex(key, ":"); i >= 0 {
+			if r := Role(strings.TrimSpace(key[i+1:])); r == RoleViewer || r == RoleEditor {
+				key, role = key[:i], r
+			}
+		}
+		user, key = strings.TrimSpace(user), strings.TrimSpace(key)
+		if !ok || user == "" || key == "" {
+			return nil, fmt.Errorf("%s:%d: want user:key[:role]", path, n)
+		}
+		a.keys = append(a.keys, apiKey{user: user, key: []byte(key), role: role})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(a.keys) == 0 {
+		return nil, fmt.Errorf("%s: no API keys", path)
+	}
+	return a, nil
+}
+
+// Wrap rejects /api/ and /audio/ requests without a valid key, taken from
+// an "Authorization: Bearer" header or the asr_eval_key cookie, and
+// anything but GET and HEAD from viewers; routes for editors only, such as
+// the live ingest WebSocket, reject viewers themselves. Other paths, i.e. the UI's static
+// files, pass through. The authenticated user and role are available to
+// handlers via UserFromContext and RoleFromContext.
+func (a *Authenticator) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") && !strings.HasPrefix(r.URL.Path, "/audio/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		k, ok := a.authenticate(requestKey(r))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="asr-eval"`)
+			http.Error(w, "invalid or missing API key", http.StatusUnauthorized)
+			return
+		}
+		if k.role == RoleViewer && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "viewers cannot modify the dataset", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withUser(r.Context(), k)))
+	})
+}
+
+// UnaryInterceptor is Wrap for gRPC: it rejects calls without a valid key
+// in the "authorization: Bearer" metadata.
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		var key string
+		if v := metadata.ValueFromIncomingContext(ctx, "authorization"); len(v) > 0 {
+			if token, ok := strings.CutPrefix(v[0], "Bearer "); ok {
+				key = strings.TrimSpace(token)
+			}
+		}
+		k, ok := a.authenticate(key)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid or missing API key")
+		}
+		if k.role == RoleViewer && !readOnlyRPCs[info.FullMethod] {
+			return nil, status.Error(codes.PermissionDenied, "viewers cannot modify the dataset")
+		}
+		return next(withUser(ctx, k), req)
+	}
+}
+
+// UserFromContext returns the user authenticated for the request, or "" when
+// authentication is disabled.
+func UserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// RoleFromContext returns the role of the user authenticated for the
+// request, or "" when authentication is disabled.
+func RoleFromContext(ctx context.Context) Role {
+	role, _ := ctx.Value(roleKey{}).(Role)
+	return role
+}
+
+func withUser(ctx context.Context, k *apiKey) context.Context {
+	ctx = context.WithValue(ctx, userKey{}, k.user)
+	return context.WithValue(ctx, roleKey{}, k.role)
+}
+
+func requestKey(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if c, err := r.Cookie(apiKeyCookie); err == nil {
+		// The UI stores the key URI-encoded.
+		if v, err := url.PathUnescape(c.Value); err == nil {
+			return v
+		}
+	}
+	return ""
+}
+
+// authenticate compares key against every known key in constant time.
+func (a *Authenticator) authenticate(key string) (*apiKey, bool) {
+	if key == "" {
+		return nil, false
+	}
+	var found *apiKey
+	for i, k := range a.keys {
+		if subtle.ConstantTimeCompare(k.key, []byte(key)) == 1 {
+			found = &a.keys[i]
+		}
+	}
+	return found, found != nil
+}
@@ -0,0 +1,79 @@
+package workspace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthenticatorRoles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users")
+	users := "# comment\nalice:k1\nbob:k2:viewer\ncarol:k:3:editor\n"
+	if err := os.WriteFile(path, []byte(users), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := LoadAuthenticator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotRole Role
+	h := a.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRole = RoleFromContext(r.Context())
+	}))
+	for _, tc := range []struct {
+		method, key string
+		wantCode    int
+		wantRole    Role
+	}{
+		{"POST", "k1", http.StatusOK, RoleEditor},
+		{"GET", "k2", http.StatusOK, RoleViewer},
+		{"POST", "k2", http.StatusForbidden, ""},
+		{"POST", "k:3", http.StatusOK, RoleEditor},
+		{"GET", "k2:viewer", http.StatusUnauthorized, ""},
+	} {
+		gotRole = ""
+		r := httptest.NewRequest(tc.method, "/api/cases", nil)
+		r.Header.Set("Authorization", "Bearer "+tc.key)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != tc.wantCode || gotRole != tc.wantRole {
+			t.Errorf("%s with key %q = %d as %q, want %d as %q", tc.method, tc.key, rec.Code, gotRole, tc.wantCode, tc.wantRole)
+		}
+	}
+}
+
+func TestViewerCannotIngestLive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users")
+	if err := os.WriteFile(path, []byte("alice:k1\nbob:k2:viewer\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := LoadAuthenticator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+	h := a.Wrap(mux)
+
+	for _, tc := range []struct {
+		key, target string
+		forbidden   bool
+	}{
+		{"k2", "/api/cases:live?providers=p", true},
+		{"k1", "/api/cases:live?providers=p", false},
+		{"k2", "/api/cases", false},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+		r.Header.Set("Authorization", "Bearer "+tc.key)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if got := rec.Code == http.StatusForbidden; got != tc.forbidden {
+			t.Errorf("GET %s with key %s = %d, want forbidden %v", tc.target, tc.key, rec.Code, tc.forbidden)
+		}
+	}
+}
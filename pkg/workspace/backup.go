@@ -0,0 +1,288 @@
+package workspace
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupManifestName is the backup entry listing every other entry with
+// its hash.
+const backupManifestName = "backup.json"
+
+// BackupManifest describes the content of a backup.
+type BackupManifest struct {
+	Dataset    string            `json:"dataset"`
+	CreateTime time.Time         `json:"create_time"`
+	Files      map[string]string `json:"files"` // Slash-separated path in the dataset -> SHA-256
+}
+
+// RestoreBackupRequest configures RestoreBackup.
+type RestoreBackupRequest struct {
+	// Overwrite replaces files that exist in the dataset. Replaced contexts
+	// and reports are archived to their history first. The audit log is
+	// never replaced.
+	Overwrite bool
+}
+
+// RestoreBackupResponse counts what RestoreBackup did.
+type RestoreBackupResponse struct {
+	Restored int
+	Skipped  []string // Existing files left untouched
+}
+
+// Backup writes a zip of every dataset file but audio: transcripts,
+// contexts, reports and their histories, sidecars, runs, dataset.yaml and
+// the audit log, along with a manifest of their SHA-256 hashes.
+func (s *Service) Backup(ctx context.Context, w io.Writer) (*BackupManifest, error) {
+	names, err := s.backupFiles()
+	if err != nil {
+		return nil, err
+	}
+	m := &BackupManifest{
+		Dataset:    filepath.Base(s.Config.DatasetDir),
+		CreateTime: time.Now().UTC(),
+		Files:      make(map[string]string, len(names)),
+	}
+
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sum, err := addBackupFile(zw, filepath.Join(s.Config.DatasetDir, filepath.FromSlash(name)), name)
+		if err != nil {
+			return nil, err
+		}
+		m.Files[name] = sum
+	}
+	mw, err := zw.Create(backupManifestName)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return nil, err
+	}
+	return m, zw.Close()
+}
+
+// backupFiles lists the slash-separated paths Backup includes, sorted.
+// Audio, the manifest, locks, jobs and the sync state are left out: the
+// first is too large to snapshot and the rest are rebuilt, transient or
+// per copy.
+func (s *Service) backupFiles() ([]string, error) {
+	root := s.Config.DatasetDir
+	var names []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == locksDirName || rel == jobsDirName || rel == fingerprintsDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || strings.HasSuffix(rel, extFlac) || rel == manifestFileName || rel == changeJournalName || rel == syncStateName {
+			return nil
+		}
+		names = append(names, rel)
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+func addBackupFile(zw *zip.Writer, p, name string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	hdr, err := zip.FileInfoHeader(fi)
+	if err != nil {
+		return "", err
+	}
+	hdr.Name = name
+	hdr.Method = zip.Deflate
+	fw, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(fw, h), f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// RestoreBackup writes the files of a backup made by Backup into the
+// dataset. Every entry is checked against the manifest's hashes before
+// anything is written, so a damaged backup changes nothing.
+func (s *Service) RestoreBackup(ctx context.Context, r io.ReaderAt, size int64, req RestoreBackupRequest) (*RestoreBackupResponse, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup: %w", err)
+	}
+	m, err := readBackupManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := verifyBackup(zr, m)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	caseIDs, err := s.Storage.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range caseIDs {
+		ids[id] = true
+	}
+
+	resp := &RestoreBackupResponse{Skipped: []string{}}
+	touched := make(map[string]bool)
+	for _, f := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		dst := filepath.Join(s.Config.DatasetDir, filepath.FromSlash(f.Name))
+		if _, err := os.Stat(dst); err == nil && (!req.Overwrite || f.Name == auditFileName) {
+			resp.Skipped = append(resp.Skipped, f.Name)
+			continue
+		}
+		id, ok := "", false
+		if !strings.Contains(f.Name, "/") {
+			id, ok = owningCase(f.Name, ids)
+		}
+		if err := s.restoreBackupFile(ctx, f, dst, id, ok); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		resp.Restored++
+		if ok {
+			touched[id] = true
+		}
+	}
+
+	for _, id := range caseIDs {
+		if touched[id] {
+			s.audit(ctx, AuditRestoreBackup, id, map[string]string{"backup_time": m.CreateTime.Format(time.RFC3339)})
+		}
+	}
+	return resp, nil
+}
+
+// restoreBackupFile extracts f to dst, under the lock of case id when the
+// file belongs to one. A current context or report is archived first.
+func (s *Service) restoreBackupFile(ctx context.Context, f *zip.File, dst, id string, isCase bool) error {
+	if isCase {
+		unlock, err := s.lockCase(ctx, id)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		switch f.Name {
+		case id + extGTV2:
+			_, err = s.Storage.ArchiveContext(ctx, id)
+		case id + extReportV2:
+			_, err = s.Storage.ArchiveReport(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return extractZipFile(f, dst)
+}
+
+func readBackupManifest(zr *zip.Reader) (*BackupManifest, error) {
+	f, err := zr.Open(backupManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup: missing %s", backupManifestName)
+	}
+	defer f.Close()
+	var m BackupManifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid backup: %s: %w", backupManifestName, err)
+	}
+	return &m, nil
+}
+
+// verifyBackup checks that the entries of zr are exactly the files of m
+// with matching hashes, and returns them in manifest order.
+func verifyBackup(zr *zip.Reader, m *BackupManifest) ([]*zip.File, error) {
+	byName := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		if f.Name == backupManifestName {
+			continue
+		}
+		if _, ok := m.Files[f.Name]; !ok {
+			return nil, fmt.Errorf("invalid backup: %s is not in the manifest", f.Name)
+		}
+		byName[f.Name] = f
+	}
+
+	names := make([]string, 0, len(m.Files))
+	for name := range m.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]*zip.File, 0, len(names))
+	for _, name := range names {
+		if !filepath.IsLocal(filepath.FromSlash(name)) || strings.Contains(name, `\`) || path.Clean(name) != name {
+			return nil, fmt.Errorf("invalid backup entry: %s", name)
+		}
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid backup: %s is missing", name)
+		}
+		sum, err := hashZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup: %s: %w", name, err)
+		}
+		if sum != m.Files[name] {
+			return nil, fmt.Errorf("invalid backup: %s does not match its hash", name)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func hashZipFile(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.flac":                    "audio",
+		"a.dg":                      "hello",
+		"a.gt.v2.json":              `{"meta":{}}`,
+		".runs/r1/a.report.v2.json": `{}`,
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+
+	var buf bytes.Buffer
+	m, err := s.Backup(ctx, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := slices.Sorted(maps.Keys(m.Files))
+	if diff := cmp.Diff([]string{".runs/r1/a.report.v2.json", "a.dg", "a.gt.v2.json"}, names); diff != "" {
+		t.Errorf("backup files mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "a.gt.v2.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.dg"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.RestoreBackup(ctx, bytes.NewReader(buf.Bytes()), int64(buf.Len()), RestoreBackupRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &RestoreBackupResponse{Restored: 1, Skipped: []string{".runs/r1/a.report.v2.json", "a.dg"}}
+	if diff := cmp.Diff(want, resp); diff != "" {
+		t.Errorf("RestoreBackup mismatch (-want +got):\n%s", diff)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "a.gt.v2.json")); string(got) != `{"meta":{}}` {
+		t.Errorf("restored context = %q", got)
+	}
+
+	// A damaged backup writes nothing.
+	var damaged bytes.Buffer
+	zw := zip.NewWriter(&damaged)
+	for name, content := range map[string]string{
+		backupManifestName: `{"files":{"a.dg":"` + strings.Repeat("0", 64) + `"}}`,
+		"a.dg":             "hello",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RestoreBackup(ctx, bytes.NewReader(damaged.Bytes()), int64(damaged.Len()), RestoreBackupRequest{Overwrite: true}); err == nil {
+		t.Error("RestoreBackup succeeded on a damaged backup, want error")
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "a.dg")); string(got) != "edited" {
+		t.Errorf("a.dg = %q after a failed restore, want it untouched", got)
+	}
+}
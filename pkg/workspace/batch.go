@@ -0,0 +1,170 @@
+package workspace
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// EvaluateAll starts a batch that queues one evaluation job per matching
+// case, and returns its ID without waiting for the cases to be scanned.
+// Jobs share the JobManager worker pool, which bounds how many run at once;
+// list them with GET /api/jobs?batch_id=. Cases without an eval context
+// are skipped.
+func (s *Service) EvaluateAll(ctx context.Context, req EvaluateAllRequest) (*EvaluateAllResponse, error) {
+	if req.Run != "" {
+		if _, err := s.GetRun(ctx, req.Run); err != nil {
+			return nil, err
+		}
+	}
+	resp := &EvaluateAllResponse{BatchID: uuid.NewString()}
+	s.beginBatch(resp.BatchID)
+	go func() {
+		defer s.sealBatch(resp.BatchID)
+		if err := s.queueBatch(context.WithoutCancel(ctx), resp.BatchID, req); err != nil {
+			slog.Error("Failed to queue batch", "batch_id", resp.BatchID, "error", err)
+		}
+	}()
+	return resp, nil
+}
+
+// queueBatch submits the jobs of batch batchID for EvaluateAll.
+func (s *Service) queueBatch(ctx context.Context, batchID string, req EvaluateAllRequest) error {
+	cases, err := s.scanCasesFor(ctx, req.CaseFilter)
+	if err != nil {
+		return err
+	}
+
+	providers := req.ProviderIDs
+	if req.Recheck != "" {
+		providers = []string{req.Recheck}
+	} else if len(providers) == 0 {
+		providers = s.EnabledProviderIDs()
+	}
+
+	var queued, skipped int
+	for _, c := range cases {
+		if !req.Match(c, s.enabledProviders()) {
+			continue
+		}
+		if req.OnlyStale && !isStale(c) {
+			continue
+		}
+		if req.OnlyUnevaluated && !missingResults(c, providers) {
+			continue
+		}
+		if req.Recheck != "" && !NeedsRecheck(c, req.Recheck, req.RecheckBelow) {
+			continue
+		}
+		if c.EvalContext == nil {
+			skipped++
+			continue
+		}
+
+		evalReq := EvaluateRequest{
+			ID:          c.ID,
+			EvalContext: c.EvalContext,
+			ProviderIDs: providers,
+			JudgeModels: req.JudgeModels,
+		}
+		s.addToBatch(batchID)
+		s.Jobs.SubmitBatch(batchID, JobEvaluate, c.ID, func(ctx context.Context) (any, error) {
+			return s.Evaluate(ctx, evalReq)
+		})
+		queued++
+	}
+	slog.Info("Queued batch", "batch_id", batchID, "jobs", queued, "skipped_without_context", skipped)
+	return nil
+}
+
+// ListStale returns the matching cases whose report was produced against a
+// context other than the current one, so they can be re-evaluated with
+// EvaluateAll and OnlyStale.
+func (s *Service) ListStale(ctx context.Context, filter CaseFilter) (*ListStaleResponse, error) {
+	cases, err := s.scanCases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListStaleResponse{Cases: []StaleCase{}}
+	enabled := s.enabledProviders()
+	for _, c := range cases {
+		if !filter.Match(c, enabled) || !isStale(c) {
+			continue
+		}
+		resp.Cases = append(resp.Cases, StaleCase{
+			ID:          c.ID,
+			ReportHash:  c.ReportV2.ContextSnapshot.Hash,
+			ContextHash: c.EvalContext.Hash,
+		})
+	}
+	return resp, nil
+}
+
+// EnabledProviderIDs returns the enabled providers in sorted order.
+func (s *Service) EnabledProviderIDs() []string {
+	var ids []string
+	for p, enabled := range s.enabledProviders() {
+		if enabled {
+			ids = append(ids, p)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// isStale reports whether the case's report was produced against a context
+// other than the current one.
+func isStale(c *Case) bool {
+	if c.ReportV2 == nil || c.EvalContext == nil {
+		return false
+	}
+	return c.ReportV2.ContextSnapshot.Hash != c.EvalContext.Hash
+}
+
+// DefaultRecheckBelow is the Q score below which a result is rechecked.
+const DefaultRecheckBelow = 60
+
+// NeedsRecheck reports whether provider's result for c scored below
+// below, or DefaultRecheckBelow when it is 0, or failed a Tier-1
+// checkpoint. Q scores must be filled in, as they are in the cases the
+// service returns.
+func NeedsRecheck(c *Case, provider string, below int) bool {
+	if below <= 0 {
+		below = DefaultRecheckBelow
+	}
+	if c.ReportV2 == nil {
+		return false
+	}
+	res, ok := c.ReportV2.Results[provider]
+	if !ok {
+		return false
+	}
+	if res.Metrics.QScore < below {
+		return true
+	}
+	for _, cp := range c.ReportV2.ContextSnapshot.Checkpoints {
+		if cp.Tier == 1 && res.CheckpointResults[cp.ID].Status == evalv2.StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// missingResults reports whether any of providers lacks a result in the
+// case's report.
+func missingResults(c *Case, providers []string) bool {
+	if c.ReportV2 == nil {
+		return true
+	}
+	for _, p := range providers {
+		if _, ok := c.ReportV2.Results[p]; !ok {
+			return true
+		}
+	}
+	return false
+}
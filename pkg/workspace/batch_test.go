@@ -0,0 +1,42 @@
+package workspace
+
+import (
+	"testing"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestNeedsRecheck(t *testing.T) {
+	r := &evalv2.EvalReport{Results: map[string]evalv2.EvalResult{}}
+	r.ContextSnapshot.Checkpoints = []evalv2.Checkpoint{{ID: "C1", Tier: 1}, {ID: "C2", Tier: 2}}
+	result := func(q int, statuses map[string]evalv2.CheckpointStatus) evalv2.EvalResult {
+		var res evalv2.EvalResult
+		res.Metrics.QScore = q
+		res.CheckpointResults = make(map[string]evalv2.CheckpointResult)
+		for id, st := range statuses {
+			res.CheckpointResults[id] = evalv2.CheckpointResult{Status: st}
+		}
+		return res
+	}
+	r.Results["low"] = result(50, nil)
+	r.Results["tier1"] = result(90, map[string]evalv2.CheckpointStatus{"C1": evalv2.StatusFail})
+	r.Results["tier2"] = result(90, map[string]evalv2.CheckpointStatus{"C1": evalv2.StatusPass, "C2": evalv2.StatusFail})
+	c := &Case{ID: "a", ReportV2: r}
+
+	for _, tt := range []struct {
+		provider string
+		below    int
+		want     bool
+	}{
+		{"low", 0, true},
+		{"low", 40, false},
+		{"tier1", 0, true},
+		{"tier2", 0, false},
+		{"tier2", 95, true},
+		{"missing", 0, false},
+	} {
+		if got := NeedsRecheck(c, tt.provider, tt.below); got != tt.want {
+			t.Errorf("NeedsRecheck(%s, %d) = %v, want %v", tt.provider, tt.below, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package workspace
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/evalv2"
+)
+
+// Bias drawn from a context takes the text of its Tier 1 and 2 checkpoints,
+// the terms a transcript must get right; Tier 3 covers the rest of the
+// ground truth, which would hand the provider the answer.
+const (
+	maxBiasTier    = 2
+	maxBiasPhrases = 50
+)
+
+// contextBias returns the vocabulary of ec for the providers that take
+// one: its checkpoints up to maxBiasTier, the most critical and then the
+// heaviest first, and its business goal as the domain. It returns nil when
+// ec has neither.
+func contextBias(ec *evalv2.EvalContext) *asr.Bias {
+	cps := slices.Clone(ec.Checkpoints)
+	slices.SortStableFunc(cps, func(a, b evalv2.Checkpoint) int {
+		if c := cmp.Compare(a.Tier, b.Tier); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Weight, a.Weight)
+	})
+	b := &asr.Bias{Domain: strings.TrimSpace(ec.Meta.BusinessGoal)}
+	for _, cp := range cps {
+		p := strings.TrimSpace(cp.TextSegment)
+		if cp.Tier < 1 || cp.Tier > maxBiasTier || p == "" || slices.Contains(b.Phrases, p) {
+			continue
+		}
+		if b.Phrases = append(b.Phrases, p); len(b.Phrases) == maxBiasPhrases {
+			break
+		}
+	}
+	if len(b.Phrases) == 0 && b.Domain == "" {
+		return nil
+	}
+	return b
+}
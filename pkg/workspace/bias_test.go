@@ -0,0 +1,80 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+)
+
+// biasTranscriber answers with the options it was given.
+type biasTranscriber struct{}
+
+func (biasTranscriber) Transcribe(ctx context.Context, path string, opts asr.Options) (*asr.Result, error) {
+	out, err := json.Marshal(struct {
+		Context string
+		Bias    *asr.Bias
+	}{opts.Context, opts.Bias})
+	return &asr.Result{Text: string(out)}, err
+}
+
+func init() {
+	asr.Register("test_bias", func() (asr.Transcriber, error) { return biasTranscriber{}, nil })
+}
+
+func TestContextBias(t *testing.T) {
+	ec := &evalv2.EvalContext{
+		Meta: evalv2.ContextMeta{BusinessGoal: " Refund a duplicate charge. "},
+		Checkpoints: []evalv2.Checkpoint{
+			{ID: "S1", Tier: 3, Weight: 0.9, TextSegment: "thanks for calling"},
+			{ID: "S2", Tier: 2, Weight: 0.2, TextSegment: "order 4417"},
+			{ID: "S3", Tier: 1, Weight: 0.5, TextSegment: "forty dollars"},
+			{ID: "S4", Tier: 2, Weight: 0.8, TextSegment: "Visa ending 1234"},
+			{ID: "S5", Tier: 1, Weight: 0.5, TextSegment: " forty dollars"},
+			{ID: "S6", Tier: 1, Weight: 0.9, TextSegment: ""},
+		},
+	}
+	want := &asr.Bias{
+		Phrases: []string{"forty dollars", "Visa ending 1234", "order 4417"},
+		Domain:  "Refund a duplicate charge.",
+	}
+	if diff := cmp.Diff(want, contextBias(ec)); diff != "" {
+		t.Errorf("contextBias() mismatch (-want +got):\n%s", diff)
+	}
+	if got := contextBias(&evalv2.EvalContext{Checkpoints: ec.Checkpoints[:1]}); got != nil {
+		t.Errorf("contextBias() of Tier 3 alone = %+v, want nil", got)
+	}
+
+	// Transcribe passes it on unless the request has a context.
+	dir := t.TempDir()
+	pcm := &audio.PCM{SampleRate: 8000, Channels: 1, Samples: make([]int16, 8000)}
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), pcm.FLAC(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	if err := s.Storage.PutContext(ctx, "a", ec); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		context, want string
+	}{
+		{"", `{"Context":"","Bias":{"Phrases":["forty dollars","Visa ending 1234","order 4417"],"Domain":"Refund a duplicate charge."}}`},
+		{"given", `{"Context":"given","Bias":null}`},
+	} {
+		c, err := s.Transcribe(ctx, TranscribeRequest{ID: "a", Provider: "test_bias", Context: tt.context})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Transcripts["test_bias"]; got != tt.want {
+			t.Errorf("Transcribe() with context %q: provider got %s, want %s", tt.context, got, tt.want)
+		}
+	}
+}
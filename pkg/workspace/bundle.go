@@ -0,0 +1,256 @@
+package workspace
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/atomicfile"
+)
+
+// bundleManifestName is the bundle entry listing its cases. Every other
+// entry is a dataset file of one of them.
+const bundleManifestName = "bundle.json"
+
+// maxBundleBytes bounds the size of a POST /api/import/bundle request body.
+const maxBundleBytes = 8 << 30
+
+// BundleManifest describes the content of a bundle.
+type BundleManifest struct {
+	Cases      []string  `json:"cases"`
+	Audio      bool      `json:"audio"` // Whether audio files are included
+	CreateTime time.Time `json:"create_time"`
+}
+
+// ExportBundle writes a zip of the dataset files of the selected cases:
+// transcripts, contexts, reports and their histories, and sidecars, along
+// with the context store entries they refer to. Audio is included only
+// when req.Audio is set.
+func (s *Service) ExportBundle(ctx context.Context, w io.Writer, req ExportBundleRequest) error {
+	ids, err := s.bundleCaseIDs(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	manifest := BundleManifest{Cases: ids, Audio: req.Audio, CreateTime: time.Now().UTC()}
+	mw, err := zw.Create(bundleManifestName)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(mw).Encode(manifest); err != nil {
+		return err
+	}
+
+	refs := make(map[string]bool)
+	for _, id := range ids {
+		files, err := caseFiles(s.Config.DatasetDir, id)
+		if err != nil {
+			return err
+		}
+		for _, name := range files {
+			if name == id+extFlac && !req.Audio {
+				continue
+			}
+			path := filepath.Join(s.Config.DatasetDir, name)
+			if err := addZipFile(zw, path, name); err != nil {
+				return err
+			}
+			if refersToContexts(name) {
+				for _, hash := range fileContextRefs(path) {
+					refs[hash] = true
+				}
+			}
+		}
+	}
+	// The contexts the files refer to follow them.
+	for _, hash := range slices.Sorted(maps.Keys(refs)) {
+		name := contextsDirName + "/" + hash + extJSON
+		if err := addZipFile(zw, filepath.Join(s.Config.DatasetDir, filepath.FromSlash(name)), name); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// bundleCaseIDs returns req.IDs, or the cases matching req's filter when
+// none are given.
+func (s *Service) bundleCaseIDs(ctx context.Context, req ExportBundleRequest) ([]string, error) {
+	if len(req.IDs) > 0 {
+		for _, id := range req.IDs {
+			if strings.ContainsAny(id, `/\`) {
+				return nil, fmt.Errorf("invalid case ID: %q", id)
+			}
+			if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, id+extFlac)); err != nil {
+				return nil, fmt.Errorf("case not found: %s", id)
+			}
+		}
+		return req.IDs, nil
+	}
+	cases, err := s.scanCases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	enabled := s.enabledProviders()
+	for _, c := range cases {
+		if req.Match(c, enabled) {
+			ids = append(ids, c.ID)
+		}
+	}
+	return ids, nil
+}
+
+func addZipFile(zw *zip.Writer, path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := zip.FileInfoHeader(fi)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	hdr.Method = zip.Deflate
+	if strings.HasSuffix(name, extFlac) {
+		hdr.Method = zip.Store // Already compressed
+	}
+	fw, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
+}
+
+// ImportBundle extracts a bundle written by ExportBundle into the dataset.
+// Existing files are kept unless req.Overwrite is set.
+func (s *Service) ImportBundle(ctx context.Context, r io.ReaderAt, size int64, req ImportBundleRequest) (*ImportBundleResponse, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	manifest, err := readBundleManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Match the longest ID first so "a.b.dg" belongs to case "a.b", not "a".
+	ids := slices.Clone(manifest.Cases)
+	slices.SortFunc(ids, func(a, b string) int { return len(b) - len(a) })
+
+	resp := &ImportBundleResponse{Cases: manifest.Cases, Skipped: []string{}}
+	owners := make(map[*zip.File]string, len(zr.File))
+	for _, f := range zr.File {
+		if f.Name == bundleManifestName {
+			continue
+		}
+		// Context store entries go first, before the files referring to them.
+		if name, ok := strings.CutPrefix(f.Name, contextsDirName+"/"); ok {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			written, err := writeContextBlob(s.Config.DatasetDir, name, rc)
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("invalid bundle: %w", err)
+			}
+			if written {
+				resp.Imported++
+			}
+			continue
+		}
+		id, err := bundleEntryCase(f.Name, ids)
+		if err != nil {
+			return nil, err
+		}
+		owners[f] = id
+	}
+
+	touched := make(map[string]bool)
+	for _, f := range zr.File {
+		id, ok := owners[f]
+		if !ok {
+			continue
+		}
+		dst := filepath.Join(s.Config.DatasetDir, f.Name)
+		if _, err := os.Stat(dst); err == nil && !req.Overwrite {
+			resp.Skipped = append(resp.Skipped, f.Name)
+			continue
+		}
+		unlock, err := s.lockCase(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		err = extractZipFile(f, dst)
+		unlock()
+		if err != nil {
+			return nil, err
+		}
+		resp.Imported++
+		touched[id] = true
+	}
+
+	for _, id := range manifest.Cases {
+		if touched[id] {
+			s.audit(ctx, AuditImportCase, id, nil)
+		}
+	}
+	return resp, nil
+}
+
+func readBundleManifest(zr *zip.Reader) (*BundleManifest, error) {
+	f, err := zr.Open(bundleManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: missing %s", bundleManifestName)
+	}
+	defer f.Close()
+	var m BundleManifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %s: %w", bundleManifestName, err)
+	}
+	for _, id := range m.Cases {
+		if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+			return nil, fmt.Errorf("invalid bundle: bad case ID %q", id)
+		}
+	}
+	return &m, nil
+}
+
+// bundleEntryCase returns the case of ids, longest first, that owns the
+// entry name. Entries outside the listed cases are rejected so a bundle
+// cannot write elsewhere in the dataset.
+func bundleEntryCase(name string, ids []string) (string, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid bundle entry: %s", name)
+	}
+	for _, id := range ids {
+		if strings.HasPrefix(name, id+".") {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("bundle entry %s belongs to no listed case", name)
+}
+
+func extractZipFile(f *zip.File, dst string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return atomicfile.Copy(dst, rc)
+}
@@ -0,0 +1,81 @@
+package workspace
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	for _, name := range []string{"a.flac", "a.dg", "a.gt.v2.json", "a.b.flac", "a.b.dg", "c.flac", "c.dg"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	from := &Service{Config: ServiceConfig{DatasetDir: src}, Storage: NewFSStorage(src)}
+
+	var buf bytes.Buffer
+	if err := from.ExportBundle(ctx, &buf, ExportBundleRequest{IDs: []string{"a", "a.b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dst, "a.dg"), []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	to := &Service{Config: ServiceConfig{DatasetDir: dst}, Storage: NewFSStorage(dst)}
+	resp, err := to.ImportBundle(ctx, bytes.NewReader(buf.Bytes()), int64(buf.Len()), ImportBundleRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ImportBundleResponse{Cases: []string{"a", "a.b"}, Imported: 2, Skipped: []string{"a.dg"}}
+	if diff := cmp.Diff(want, resp); diff != "" {
+		t.Errorf("ImportBundle mismatch (-want +got):\n%s", diff)
+	}
+
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), ".") { // Audit log, locks
+			got = append(got, e.Name())
+		}
+	}
+	if diff := cmp.Diff([]string{"a.b.dg", "a.dg", "a.gt.v2.json"}, got); diff != "" {
+		t.Errorf("imported files mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportBundleRejectsForeignEntries(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		bundleManifestName: `{"cases":["a"]}`,
+		"providers.json":   `{}`,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	if _, err := s.ImportBundle(context.Background(), bytes.NewReader(buf.Bytes()), int64(buf.Len()), ImportBundleRequest{}); err == nil {
+		t.Error("ImportBundle succeeded, want error for an entry outside the listed cases")
+	}
+}
@@ -0,0 +1,508 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/evalv2"
+)
+
+// extCase is the suffix of case bundles, "[id].case.json".
+const extCase = ".case.json"
+
+// caseBundle is the content of a case bundle: every record of a case that
+// FSStorage keeps in a file of its own. Contexts are references into the
+// context store, as in those files.
+type caseBundle struct {
+	Transcripts map[string]string   `json:"transcripts,omitempty"` // By provider
+	Context     *evalv2.EvalContext `json:"context,omitempty"`
+	Report      *evalv2.EvalReport  `json:"report,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+}
+
+// CaseBundleStorage is the Storage of a dataset directory that keeps the
+// records of a case in one file, "[id].case.json", next to its audio and
+// the sidecars FSStorage documents. Records in the FSStorage layout are
+// read too and take precedence, as they are what older tools, restores
+// and rollbacks write; the next write to the case folds them into the
+// bundle and removes them.
+//
+// Realtime stream dumps stay in files of their own, as cmd/processor
+// appends to them.
+type CaseBundleStorage struct {
+	fs *FSStorage
+}
+
+// NewCaseBundleStorage returns the case bundle storage of the dataset
+// directory dir.
+func NewCaseBundleStorage(dir string) *CaseBundleStorage {
+	return &CaseBundleStorage{fs: NewFSStorage(dir)}
+}
+
+func (st *CaseBundleStorage) Close() error { return nil }
+
+func (st *CaseBundleStorage) ListIDs(ctx context.Context) ([]string, error) {
+	return st.fs.ListIDs(ctx)
+}
+
+func (st *CaseBundleStorage) ListCases(ctx context.Context) ([]*Case, error) {
+	entries, err := os.ReadDir(st.fs.dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), extFlac); ok && !e.IsDir() {
+			ids[id] = true
+		}
+	}
+	files := make(map[string][]string, len(ids))
+	for _, e := range entries {
+		if id, ok := owningCase(e.Name(), ids); ok && !e.IsDir() {
+			files[id] = append(files[id], e.Name())
+		}
+	}
+
+	results := make([]*Case, 0, len(ids))
+	for id := range ids {
+		b, err := st.read(id, files[id])
+		if err != nil {
+			slog.Warn("Failed to read case", "dataset", st.fs.dir, "case", id, "error", err)
+			b = &caseBundle{}
+		}
+		c := b.toCase(id)
+		c.Transcripts = nil
+		results = append(results, c)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+	return results, nil
+}
+
+func (st *CaseBundleStorage) GetSummary(ctx context.Context, id string) (*Case, error) {
+	c, err := st.GetCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.Transcripts = nil
+	return c, nil
+}
+
+func (st *CaseBundleStorage) GetCase(ctx context.Context, id string) (*Case, error) {
+	b, err := st.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return b.toCase(id), nil
+}
+
+func (st *CaseBundleStorage) PutCase(ctx context.Context, id string) error {
+	return st.fs.PutCase(ctx, id)
+}
+
+// DeleteCase removes every file of the case, the bundle included.
+func (st *CaseBundleStorage) DeleteCase(ctx context.Context, id string) error {
+	return st.fs.DeleteCase(ctx, id)
+}
+
+func (st *CaseBundleStorage) PutTranscript(ctx context.Context, id, provider, text string) error {
+	return st.update(id, func(b *caseBundle) {
+		if b.Transcripts == nil {
+			b.Transcripts = make(map[string]string)
+		}
+		b.Transcripts[provider] = text
+	})
+}
+
+func (st *CaseBundleStorage) GetContext(ctx context.Context, id string) (*evalv2.EvalContext, error) {
+	b, err := st.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if b.Context == nil {
+		return nil, fmt.Errorf("context not found: %s: %w", id, os.ErrNotExist)
+	}
+	return b.Context, nil
+}
+
+func (st *CaseBundleStorage) PutContext(ctx context.Context, id string, c *evalv2.EvalContext) error {
+	return st.update(id, func(b *caseBundle) { b.Context = c })
+}
+
+func (st *CaseBundleStorage) GetReport(ctx context.Context, id string) (*evalv2.EvalReport, error) {
+	b, err := st.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if b.Report == nil {
+		return nil, fmt.Errorf("report not found: %s: %w", id, os.ErrNotExist)
+	}
+	return b.Report, nil
+}
+
+func (st *CaseBundleStorage) PutReport(ctx context.Context, id string, r *evalv2.EvalReport) error {
+	return st.update(id, func(b *caseBundle) { b.Report = r })
+}
+
+func (st *CaseBundleStorage) DeleteReport(ctx context.Context, id string) error {
+	return st.update(id, func(b *caseBundle) { b.Report = nil })
+}
+
+// ArchiveContext writes the case's context to its history in the
+// FSStorage layout, which bundles share, and removes it from the bundle.
+func (st *CaseBundleStorage) ArchiveContext(ctx context.Context, id string) (int, error) {
+	b, err := st.load(id)
+	if err != nil || b.Context == nil {
+		return 0, err
+	}
+	n, err := st.fs.addVersion(id, extGTV2, func(name string) error { return st.fs.writeContext(name, b.Context) })
+	if err != nil {
+		return 0, err
+	}
+	return n, st.update(id, func(b *caseBundle) { b.Context = nil })
+}
+
+func (st *CaseBundleStorage) ListContextVersions(ctx context.Context, id string) ([]RecordVersion, error) {
+	b, err := st.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return st.versions(id, extGTV2, b.Context != nil)
+}
+
+func (st *CaseBundleStorage) GetContextVersion(ctx context.Context, id string, n int) (*evalv2.EvalContext, error) {
+	return st.fs.GetContextVersion(ctx, id, n)
+}
+
+// ArchiveReport writes the case's report to its history in the FSStorage
+// layout, which bundles share, and removes it from the bundle.
+func (st *CaseBundleStorage) ArchiveReport(ctx context.Context, id string) (int, error) {
+	b, err := st.load(id)
+	if err != nil || b.Report == nil {
+		return 0, err
+	}
+	n, err := st.fs.addVersion(id, extReportV2, func(name string) error { return st.fs.writeReport(name, b.Report) })
+	if err != nil {
+		return 0, err
+	}
+	return n, st.update(id, func(b *caseBundle) { b.Report = nil })
+}
+
+func (st *CaseBundleStorage) ListReportVersions(ctx context.Context, id string) ([]RecordVersion, error) {
+	b, err := st.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return st.versions(id, extReportV2, b.Report != nil)
+}
+
+func (st *CaseBundleStorage) GetReportVersion(ctx context.Context, id string, n int) (*evalv2.EvalReport, error) {
+	return st.fs.GetReportVersion(ctx, id, n)
+}
+
+// versions returns the archived versions of the record in "[id]<ext>",
+// followed by the current one when it is in that file or, if current, in
+// the bundle, which gives its update time.
+func (st *CaseBundleStorage) versions(id, ext string, current bool) ([]RecordVersion, error) {
+	out, err := st.fs.versions(id, ext)
+	if err != nil || !current || (len(out) > 0 && out[len(out)-1].N == 0) {
+		return out, err
+	}
+	if fi, err := os.Stat(filepath.Join(st.fs.dir, id+extCase)); err == nil {
+		out = append(out, RecordVersion{UpdateTime: fi.ModTime()})
+	}
+	return out, nil
+}
+
+func (st *CaseBundleStorage) GetTags(ctx context.Context, id string) ([]string, error) {
+	b, err := st.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return b.Tags, nil
+}
+
+func (st *CaseBundleStorage) PutTags(ctx context.Context, id string, tags []string) error {
+	return st.update(id, func(b *caseBundle) { b.Tags = tags })
+}
+
+func (st *CaseBundleStorage) AppendAudit(ctx context.Context, e *AuditEntry) error {
+	return st.fs.AppendAudit(ctx, e)
+}
+
+func (st *CaseBundleStorage) ListAudit(ctx context.Context, req ListAuditRequest) ([]*AuditEntry, error) {
+	return st.fs.ListAudit(ctx, req)
+}
+
+// Fold moves the records of case id in the FSStorage layout into its
+// bundle, reporting whether there were any.
+func (st *CaseBundleStorage) Fold(id string) (bool, error) {
+	files, err := caseFiles(st.fs.dir, id)
+	if err != nil {
+		return false, err
+	}
+	if !slices.ContainsFunc(files, func(name string) bool { return looseRecord(id, name) }) {
+		return false, nil
+	}
+	return true, st.update(id, func(*caseBundle) {})
+}
+
+// Unfold writes the records of the bundle of case id back in the FSStorage
+// layout and removes the bundle, reporting whether there was one.
+func (st *CaseBundleStorage) Unfold(ctx context.Context, id string) (bool, error) {
+	path := filepath.Join(st.fs.dir, id+extCase)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return false, nil
+	}
+	b, err := st.load(id)
+	if err != nil {
+		return false, err
+	}
+	for p, text := range b.Transcripts {
+		if err := st.fs.PutTranscript(ctx, id, p, text); err != nil {
+			return false, err
+		}
+	}
+	if b.Context != nil {
+		if err := st.fs.PutContext(ctx, id, b.Context); err != nil {
+			return false, err
+		}
+	}
+	if b.Report != nil {
+		if err := st.fs.PutReport(ctx, id, b.Report); err != nil {
+			return false, err
+		}
+	}
+	if err := st.fs.PutTags(ctx, id, b.Tags); err != nil {
+		return false, err
+	}
+	return true, os.Remove(path)
+}
+
+// load returns the records of case id, failing when the case does not
+// exist.
+func (st *CaseBundleStorage) load(id string) (*caseBundle, error) {
+	files, err := caseFiles(st.fs.dir, id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(files, id+extFlac) {
+		return nil, fmt.Errorf("case not found: %s: %w", id, os.ErrNotExist)
+	}
+	return st.read(id, files)
+}
+
+// read returns the records of case id from its bundle, overridden by those
+// among its files in the FSStorage layout, with contexts resolved.
+func (st *CaseBundleStorage) read(id string, files []string) (*caseBundle, error) {
+	b, err := readCaseBundle(filepath.Join(st.fs.dir, id+extCase))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	if b.Context != nil {
+		// A context missing from the store stays a reference, so that
+		// writing the bundle back keeps it.
+		if c, err := resolveContext(st.fs.dir, b.Context); err == nil {
+			b.Context = c
+		} else {
+			slog.Warn("Case context unavailable", "dataset", st.fs.dir, "case", id, "error", err)
+		}
+	}
+	if b.Report != nil {
+		resolveReportContext(st.fs.dir, b.Report)
+	}
+
+	for _, name := range files {
+		switch {
+		case name == id+extGTV2:
+			if c, err := st.fs.readContext(id); err == nil {
+				b.Context = c
+			}
+		case name == id+extReportV2:
+			if r, err := st.fs.readReport(id); err == nil {
+				b.Report = r
+			}
+		case name == id+extTags:
+			if tags, err := st.fs.readTags(id); err == nil {
+				b.Tags = tags
+			}
+		default:
+			if p, ok := transcriptProvider(id, name); ok {
+				content, err := os.ReadFile(filepath.Join(st.fs.dir, name))
+				if err != nil {
+					continue
+				}
+				if b.Transcripts == nil {
+					b.Transcripts = make(map[string]string)
+				}
+				b.Transcripts[p] = string(content)
+			}
+		}
+	}
+	return b, nil
+}
+
+// update applies f to the records of case id and writes them to its
+// bundle, removing the files in the FSStorage layout folded into it.
+// Callers hold the case lock.
+func (st *CaseBundleStorage) update(id string, f func(b *caseBundle)) error {
+	files, err := caseFiles(st.fs.dir, id)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(files, id+extFlac) {
+		return fmt.Errorf("case not found: %s: %w", id, os.ErrNotExist)
+	}
+	b, err := st.read(id, files)
+	if err != nil {
+		return err
+	}
+	f(b)
+
+	out := *b
+	if out.Context != nil {
+		if out.Context, err = storeContext(st.fs.dir, out.Context); err != nil {
+			return err
+		}
+	}
+	if out.Report != nil {
+		if out.Report, err = storeReportContext(st.fs.dir, out.Report); err != nil {
+			return err
+		}
+	}
+	bytes, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(filepath.Join(st.fs.dir, id+extCase), bytes); err != nil {
+		return err
+	}
+	for _, name := range files {
+		if !looseRecord(id, name) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(st.fs.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *caseBundle) toCase(id string) *Case {
+	c := &Case{
+		ID:          id,
+		Transcripts: b.Transcripts,
+		Tags:        b.Tags,
+		EvalContext: b.Context,
+		ReportV2:    b.Report,
+	}
+	if c.Transcripts == nil {
+		c.Transcripts = make(map[string]string)
+	}
+	// If no GT, use the report's snapshot
+	if c.EvalContext == nil && c.ReportV2 != nil && c.ReportV2.ContextSnapshot.Hash != "" {
+		c.EvalContext = &c.ReportV2.ContextSnapshot
+	}
+	return c
+}
+
+// looseRecord reports whether name is a record of case id in the FSStorage
+// layout, one a bundle holds.
+func looseRecord(id, name string) bool {
+	if _, ok := transcriptProvider(id, name); ok {
+		return true
+	}
+	return name == id+extGTV2 || name == id+extReportV2 || name == id+extTags
+}
+
+// readCaseBundle reads the case bundle at path, upgrading its context and
+// report to the current schema. A missing bundle is an empty one.
+func readCaseBundle(path string) (*caseBundle, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &caseBundle{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Transcripts map[string]string `json:"transcripts"`
+		Context     json.RawMessage   `json:"context"`
+		Report      json.RawMessage   `json:"report"`
+		Tags        []string          `json:"tags"`
+	}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+	b := &caseBundle{Transcripts: raw.Transcripts, Tags: raw.Tags}
+	if len(raw.Context) > 0 && string(raw.Context) != "null" {
+		if b.Context, err = evalv2.UnmarshalContext(raw.Context); err != nil {
+			return nil, fmt.Errorf("context: %w", err)
+		}
+	}
+	if len(raw.Report) > 0 && string(raw.Report) != "null" {
+		if b.Report, err = evalv2.UnmarshalReport(raw.Report); err != nil {
+			return nil, fmt.Errorf("report: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// FoldCaseBundles moves the records of every case of the dataset directory
+// into case bundles, or with unfold set back to the FSStorage layout,
+// whatever storage the service uses. With dryRun set it only counts the
+// cases to change.
+func (s *Service) FoldCaseBundles(ctx context.Context, unfold, dryRun bool) (*MigrateResponse, error) {
+	st := NewCaseBundleStorage(s.Config.DatasetDir)
+	ids, err := st.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &MigrateResponse{Failed: []string{}}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		resp.Scanned++
+		changed, err := s.foldCaseBundle(ctx, st, id, unfold, dryRun)
+		if err != nil {
+			resp.Failed = append(resp.Failed, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		if changed {
+			resp.Migrated++
+		}
+	}
+	return resp, nil
+}
+
+func (s *Service) foldCaseBundle(ctx context.Context, st *CaseBundleStorage, id string, unfold, dryRun bool) (bool, error) {
+	if dryRun {
+		files, err := caseFiles(s.Config.DatasetDir, id)
+		if err != nil {
+			return false, err
+		}
+		if unfold {
+			return slices.Contains(files, id+extCase), nil
+		}
+		return slices.ContainsFunc(files, func(name string) bool { return looseRecord(id, name) }), nil
+	}
+	unlock, err := s.lockCase(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if unfold {
+		return st.Unfold(ctx, id)
+	}
+	return st.Fold(id)
+}
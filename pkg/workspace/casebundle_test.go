@@ -0,0 +1,82 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestCaseBundleStorage(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.flac", "b.flac"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	// Records in the legacy layout are read, and folded on the next write.
+	legacy := NewFSStorage(dir)
+	if err := legacy.PutTranscript(ctx, "a", "x", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.PutTags(ctx, "a", []string{"noisy"}); err != nil {
+		t.Fatal(err)
+	}
+	st := NewCaseBundleStorage(dir)
+	if err := st.PutReport(ctx, "a", &evalv2.EvalReport{ContextSnapshot: evalv2.EvalContext{Hash: "h"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.x", "a" + extTags, "a" + extReportV2} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s left after folding: %v", name, err)
+		}
+	}
+
+	// A loose file written later takes precedence.
+	if err := legacy.PutTranscript(ctx, "a", "y", "world"); err != nil {
+		t.Fatal(err)
+	}
+	c, err := st.GetCase(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"x": "hello", "y": "world"}, c.Transcripts); diff != "" {
+		t.Errorf("transcripts mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"noisy"}, c.Tags); diff != "" {
+		t.Errorf("tags mismatch (-want +got):\n%s", diff)
+	}
+	if c.EvalContext == nil || c.EvalContext.Hash != "h" {
+		t.Errorf("context = %+v, want the report's snapshot", c.EvalContext)
+	}
+
+	cases, err := st.ListCases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 2 || cases[0].ID != "a" || cases[0].ReportV2 == nil || cases[0].Transcripts != nil {
+		t.Fatalf("ListCases = %+v, want summaries of a and b", cases)
+	}
+
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: st}
+	resp, err := s.FoldCaseBundles(ctx, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Migrated != 1 {
+		t.Errorf("unfolded %d cases, want 1", resp.Migrated)
+	}
+	got, err := legacy.GetCase(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(c, got); diff != "" {
+		t.Errorf("unfolded case mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,83 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+)
+
+// extChannels is the per-case sidecar holding the case's own channel
+// selection, in place of the dataset's.
+const extChannels = ".channels.json"
+
+// UpdateChannels sets which channel of a multichannel case providers hear
+// from now on, and which channels are the agent's and the customer's, and
+// returns the updated case. A zero selection removes the case's own, so the
+// dataset's applies again. Existing transcripts are left as they are.
+func (s *Service) UpdateChannels(ctx context.Context, req UpdateChannelsRequest) (*Case, error) {
+	if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, req.ID+extFlac)); err != nil {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+	if err := req.ChannelOptions.Validate(); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	filename := filepath.Join(s.Config.DatasetDir, req.ID+extChannels)
+	if req.ChannelOptions == (audio.ChannelOptions{}) {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	} else {
+		bytes, err := json.MarshalIndent(req.ChannelOptions, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := atomicfile.WriteFile(filename, bytes); err != nil {
+			return nil, err
+		}
+	}
+	s.audit(ctx, AuditUpdateChannels, req.ID, map[string]string{
+		"select":   req.Select,
+		"agent":    strconv.Itoa(req.Agent),
+		"customer": strconv.Itoa(req.Customer),
+	})
+	return s.GetCase(ctx, req.ID)
+}
+
+// loadChannels returns the case's own channel selection, nil when it has
+// none.
+func (s *Service) loadChannels(id string) (*audio.ChannelOptions, error) {
+	content, err := os.ReadFile(filepath.Join(s.Config.DatasetDir, id+extChannels))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var o audio.ChannelOptions
+	if err := json.Unmarshal(content, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// channelOptions returns the channel selection the audio of case id is
+// transcribed with: its own, or else the dataset's.
+func (s *Service) channelOptions(id string) (audio.ChannelOptions, error) {
+	o, err := s.loadChannels(id)
+	if err != nil || o == nil {
+		return s.Config.Channels, err
+	}
+	return *o, nil
+}
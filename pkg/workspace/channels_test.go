@@ -0,0 +1,103 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/audio"
+)
+
+// channelTranscriber reports the channels of the audio it hears and the
+// first sample of each.
+type channelTranscriber struct{}
+
+func (channelTranscriber) Transcribe(ctx context.Context, path string, opts asr.Options) (*asr.Result, error) {
+	pcm, err := audio.DecodeFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &asr.Result{Text: fmt.Sprint(pcm.Samples[:pcm.Channels])}, nil
+}
+
+func init() {
+	asr.Register("test_channels", func() (asr.Transcriber, error) { return channelTranscriber{}, nil })
+}
+
+func TestTranscribeChannels(t *testing.T) {
+	dir := t.TempDir()
+	// The agent on the left, the customer on the right.
+	pcm := &audio.PCM{SampleRate: 8000, Channels: 2, Samples: make([]int16, 2*8000)}
+	for i := 0; i < len(pcm.Samples); i += 2 {
+		pcm.Samples[i], pcm.Samples[i+1] = 1000, -3000
+	}
+	if err := os.WriteFile(filepath.Join(dir, "call.flac"), pcm.FLAC(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	transcribe := func() string {
+		t.Helper()
+		c, err := s.Transcribe(ctx, TranscribeRequest{ID: "call", Provider: "test_channels"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.Transcripts["test_channels"]
+	}
+
+	// By default the provider gets the recording as is.
+	if got, want := transcribe(), "[1000 -3000]"; got != want {
+		t.Errorf("unselected: provider heard %s, want %s", got, want)
+	}
+	s.Config.Channels = audio.ChannelOptions{Select: audio.ChannelCustomer}
+	if got, want := transcribe(), "[-3000]"; got != want {
+		t.Errorf("dataset selecting the customer: provider heard %s, want %s", got, want)
+	}
+
+	// The case's own selection overrides the dataset's until removed.
+	c, err := s.UpdateChannels(ctx, UpdateChannelsRequest{ID: "call", ChannelOptions: audio.ChannelOptions{Select: audio.ChannelAgent}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&audio.ChannelOptions{Select: audio.ChannelAgent}, c.Channels); diff != "" {
+		t.Errorf("case channels mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := transcribe(), "[1000]"; got != want {
+		t.Errorf("case selecting the agent: provider heard %s, want %s", got, want)
+	}
+	if _, err := s.UpdateChannels(ctx, UpdateChannelsRequest{ID: "call", ChannelOptions: audio.ChannelOptions{Select: audio.ChannelMix}}); err != nil {
+		t.Fatal(err)
+	}
+	// Mixing leaves the recording to the client, which averages it.
+	if got, want := transcribe(), "[1000 -3000]"; got != want {
+		t.Errorf("case mixing: provider heard %s, want %s", got, want)
+	}
+	if c, err = s.UpdateChannels(ctx, UpdateChannelsRequest{ID: "call"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Channels != nil {
+		t.Errorf("case channels after removal = %+v, want none", c.Channels)
+	}
+	if got, want := transcribe(), "[-3000]"; got != want {
+		t.Errorf("after removal: provider heard %s, want %s", got, want)
+	}
+
+	for _, req := range []UpdateChannelsRequest{
+		{ID: "call", ChannelOptions: audio.ChannelOptions{Select: "left"}},
+		{ID: "missing", ChannelOptions: audio.ChannelOptions{Select: audio.ChannelAgent}},
+	} {
+		if _, err := s.UpdateChannels(ctx, req); err == nil {
+			t.Errorf("UpdateChannels(%+v) succeeded", req)
+		}
+	}
+	// A selected channel the audio does not have fails the transcription.
+	s.Config.Channels = audio.ChannelOptions{Select: audio.ChannelCustomer, Customer: 3}
+	if _, err := s.Transcribe(ctx, TranscribeRequest{ID: "call", Provider: "test_channels"}); err == nil {
+		t.Error("Transcribe() of a missing channel succeeded")
+	}
+}
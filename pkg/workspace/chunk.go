@@ -0,0 +1,220 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+)
+
+// extChunks is the suffix of the chunk sidecar, "[id].<provider>.chunks.json",
+// of a transcript made in chunks.
+const extChunks = ".chunks.json"
+
+// TranscriptChunks records how a transcript was made from chunks of the
+// audio. Times are in the audio as sent, after any silence trimming.
+type TranscriptChunks struct {
+	ChunkMS   int64             `json:"chunk_ms"`
+	OverlapMS int64             `json:"overlap_ms"`
+	Chunks    []TranscriptChunk `json:"chunks"`
+}
+
+// TranscriptChunk is one chunk of the audio and the provider's transcript
+// of it alone, before stitching.
+type TranscriptChunk struct {
+	StartMS int64  `json:"start_ms"`
+	EndMS   int64  `json:"end_ms"`
+	Text    string `json:"text"`
+}
+
+// transcribeChunks runs t on pcm in overlapping chunks, written to dir.
+// The chunks are transcribed in parallel and their transcripts stitched,
+// dropping the text repeated in the overlaps; stream entries are shifted
+// to the chunk's place in the audio.
+func (s *Service) transcribeChunks(ctx context.Context, t asr.Transcriber, pcm *audio.PCM, dir string, opts asr.Options) (*asr.Result, *TranscriptChunks, error) {
+	cfg := s.Config.Chunking
+	chunkMS, overlapMS := int64(cfg.Seconds*1000), int64(cfg.OverlapSeconds*1000)
+	tc := &TranscriptChunks{ChunkMS: chunkMS, OverlapMS: overlapMS}
+	durationMS := int64(pcm.Frames()) * 1000 / int64(pcm.SampleRate)
+	for start := int64(0); ; start += chunkMS - overlapMS {
+		end := min(start+chunkMS, durationMS)
+		tc.Chunks = append(tc.Chunks, TranscriptChunk{StartMS: start, EndMS: end})
+		if end == durationMS {
+			break
+		}
+	}
+
+	paths := make([]string, len(tc.Chunks))
+	for i, c := range tc.Chunks {
+		from := int(c.StartMS * int64(pcm.SampleRate) / 1000)
+		to := int(c.EndMS * int64(pcm.SampleRate) / 1000)
+		chunk := &audio.PCM{SampleRate: pcm.SampleRate, Channels: 1, Samples: pcm.Samples[from:to]}
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%04d.wav", i))
+		if err := os.WriteFile(paths[i], chunk.WAV(), 0644); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, max(cfg.Concurrency, 1))
+		results = make([]*asr.Result, len(tc.Chunks))
+		errs    = make([]error, len(tc.Chunks))
+	)
+	for i := range tc.Chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+			if results[i], errs[i] = t.Transcribe(ctx, paths[i], opts); errs[i] != nil {
+				cancel() // The transcript is useless with a chunk missing.
+			}
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			c := tc.Chunks[i]
+			return nil, nil, fmt.Errorf("chunk %d at %dms-%dms: %w", i, c.StartMS, c.EndMS, err)
+		}
+	}
+
+	res := &asr.Result{}
+	texts := make([]string, len(results))
+	for i, r := range results {
+		tc.Chunks[i].Text = r.Text
+		texts[i] = r.Text
+		for _, e := range r.Stream {
+			e.Timestamp += tc.Chunks[i].StartMS
+			res.Stream = append(res.Stream, e)
+		}
+	}
+	res.Text = stitchTranscripts(texts, int(cfg.OverlapSeconds*stitchRunesPerSecond))
+	return res, tc, nil
+}
+
+// Stitching looks for the text two chunks share among the letters and
+// digits a second of overlap can hold, stitchRunesPerSecond being above
+// those of fast speech, and takes a shared run of at least
+// minStitchOverlap of them as the overlap.
+const (
+	stitchRunesPerSecond = 25
+	minStitchOverlap     = 6
+)
+
+// stitchTranscripts joins the transcripts of consecutive overlapping
+// chunks, looking for their overlap within window letters and digits of
+// the end of one and the start of the next. The text they share is kept
+// once, dropping the words either chunk heard only partly at its edge;
+// transcripts sharing none are joined as they are.
+func stitchTranscripts(texts []string, window int) string {
+	var out string
+	for _, t := range texts {
+		t = strings.TrimSpace(t)
+		switch {
+		case t == "":
+		case out == "":
+			out = t
+		default:
+			out = stitch(out, t, window)
+		}
+	}
+	return out
+}
+
+// stitch joins b after a, keeping once the text a ends and b starts with.
+func stitch(a, b string, window int) string {
+	ta := letterRunes(a)
+	ta = ta[max(0, len(ta)-window):]
+	hb := letterRunes(b)
+	hb = hb[:min(len(hb), window)]
+
+	// Longest common run of ta and hb.
+	var bestLen, bestA, bestB int
+	prev := make([]int, len(hb)+1)
+	cur := make([]int, len(hb)+1)
+	for i := range ta {
+		for j := range hb {
+			cur[j+1] = 0
+			if ta[i].r == hb[j].r {
+				cur[j+1] = prev[j] + 1
+				if cur[j+1] > bestLen {
+					bestLen, bestA, bestB = cur[j+1], i, j
+				}
+			}
+		}
+		prev, cur = cur, prev
+	}
+	if bestLen < minStitchOverlap {
+		return joinText(a, b)
+	}
+	return a[:ta[bestA].end] + b[hb[bestB].end:]
+}
+
+// indexedRune is a rune of a text and the byte offset just past it.
+type indexedRune struct {
+	r   rune
+	end int
+}
+
+// letterRunes returns the letters and digits of s, lowercased, so
+// punctuation and spacing the chunks disagree on do not break a match.
+func letterRunes(s string) []indexedRune {
+	var out []indexedRune
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			out = append(out, indexedRune{unicode.ToLower(r), i + utf8.RuneLen(r)})
+		}
+	}
+	return out
+}
+
+// joinText joins a and b with a space, unless either side of the join is
+// a space already or in a script written without spaces.
+func joinText(a, b string) string {
+	last, _ := utf8.DecodeLastRuneInString(a)
+	first, _ := utf8.DecodeRuneInString(b)
+	if unicode.IsSpace(last) || unicode.IsSpace(first) || unspaced(last) || unspaced(first) {
+		return a + b
+	}
+	return a + " " + b
+}
+
+func unspaced(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai) ||
+		r >= '\u3000' && r <= '\u303f' || // CJK punctuation
+		r >= '\uff00' && r <= '\uffef' // Fullwidth forms
+}
+
+// writeChunks writes tc as the chunk sidecar at path, or removes the file
+// when tc is nil so a transcript made whole does not keep stale chunks.
+func writeChunks(path string, tc *TranscriptChunks) error {
+	if tc == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content, err := json.MarshalIndent(tc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, content)
+}
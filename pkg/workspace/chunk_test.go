@@ -0,0 +1,112 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/audio"
+)
+
+// chunkTranscriber returns a fixed transcript for each chunk file.
+type chunkTranscriber map[string]string
+
+func (t chunkTranscriber) Transcribe(ctx context.Context, path string, opts asr.Options) (*asr.Result, error) {
+	text := t[filepath.Base(path)]
+	return &asr.Result{Text: text, Stream: []asr.StreamEntry{{Timestamp: 100, Final: true, Text: text}}}, nil
+}
+
+func init() {
+	asr.Register("test_chunks", func() (asr.Transcriber, error) {
+		return chunkTranscriber{
+			"0000.wav": "The quick brown fox jum",
+			"0001.wav": "own fox jumps over the la",
+			"0002.wav": "over the lazy dog.",
+		}, nil
+	})
+}
+
+func TestTranscribeChunked(t *testing.T) {
+	dir := t.TempDir()
+	pcm := &audio.PCM{SampleRate: 8000, Channels: 1, Samples: make([]int16, 25*8000)}
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), pcm.WAV(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{
+		Config:  ServiceConfig{DatasetDir: dir, Chunking: ChunkingConfig{Seconds: 10, OverlapSeconds: 2, Concurrency: 2}},
+		Storage: NewFSStorage(dir),
+	}
+	c, err := s.Transcribe(context.Background(), TranscribeRequest{ID: "a", Provider: "test_chunks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Transcripts["test_chunks"], "The quick brown fox jumps over the lazy dog."; got != want {
+		t.Errorf("transcript = %q, want %q", got, want)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.test_chunks"+extChunks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TranscriptChunks
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := TranscriptChunks{ChunkMS: 10000, OverlapMS: 2000, Chunks: []TranscriptChunk{
+		{StartMS: 0, EndMS: 10000, Text: "The quick brown fox jum"},
+		{StartMS: 8000, EndMS: 18000, Text: "own fox jumps over the la"},
+		{StartMS: 16000, EndMS: 25000, Text: "over the lazy dog."},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("chunks mismatch (-want +got):\n%s", diff)
+	}
+
+	stream, err := s.GetStream(context.Background(), "a", "test_chunks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var offsets []int64
+	for _, e := range stream.Events {
+		offsets = append(offsets, e.OffsetMS)
+	}
+	if diff := cmp.Diff([]int64{100, 8100, 16100}, offsets); diff != "" {
+		t.Errorf("stream offsets mismatch (-want +got):\n%s", diff)
+	}
+
+	// Audio no longer than a chunk is transcribed whole, dropping the sidecar.
+	s.Config.Chunking.Seconds = 30
+	if _, err := s.Transcribe(context.Background(), TranscribeRequest{ID: "a", Provider: "test_echo"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.test_echo"+extChunks)); !os.IsNotExist(err) {
+		t.Errorf("chunk sidecar of a whole transcription: %v", err)
+	}
+}
+
+func TestStitchTranscripts(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{"cut words", []string{"Hello there, how are yo", "how are you doing today?"}, "Hello there, how are you doing today?"},
+		{"punctuation differs", []string{"so we agreed on the plan.", "We agreed on the plan and left"}, "so we agreed on the plan and left"},
+		{"chinese", []string{"今天天气很好我们去公", "很好我们去公园散步吧"}, "今天天气很好我们去公园散步吧"},
+		{"no overlap", []string{"first part", "second part"}, "first part second part"},
+		{"no overlap chinese", []string{"第一部分", "第二部分"}, "第一部分第二部分"},
+		{"short coincidence", []string{"we saw a cat", "a cat ran off"}, "we saw a cat a cat ran off"},
+		{"empty chunk", []string{"one", "  ", "two"}, "one two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stitchTranscripts(tt.texts, 50); got != tt.want {
+				t.Errorf("stitchTranscripts(%q) = %q, want %q", tt.texts, got, tt.want)
+			}
+		})
+	}
+}
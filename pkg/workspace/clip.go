@@ -0,0 +1,90 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+)
+
+// Checkpoint clip bounds, in milliseconds.
+const (
+	defaultClipMS    = 2000 // Of an unaligned checkpoint, from its start, when the request sets none
+	defaultClipPadMS = 500
+	maxClipMS        = 30000 // Of DurationMS and of PadMS
+)
+
+// CheckpointClip returns, as a WAV file, the case audio around one of its
+// context's checkpoints: DurationMS from the checkpoint's start, or by
+// default the whole checkpoint once the context is aligned, with PadMS more
+// on either side, at the audio's own rate and channels. Reviewers
+// adjudicating a checkpoint listen to just the words it is about.
+func (s *Service) CheckpointClip(ctx context.Context, req CheckpointClipRequest) ([]byte, error) {
+	if req.DurationMS < 0 || req.DurationMS > maxClipMS || req.PadMS < 0 || req.PadMS > maxClipMS {
+		return nil, fmt.Errorf("duration_ms and pad_ms must be within [0, %d]", maxClipMS)
+	}
+	path := filepath.Join(s.Config.DatasetDir, req.ID+extFlac)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+	ec, err := s.loadEvalContext(ctx, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("no context for case %s", req.ID)
+	}
+	i := slices.IndexFunc(ec.Checkpoints, func(cp evalv2.Checkpoint) bool { return cp.ID == req.Checkpoint })
+	if i < 0 {
+		return nil, fmt.Errorf("no checkpoint %s in case %s", req.Checkpoint, req.ID)
+	}
+	cp := ec.Checkpoints[i]
+	startMS, duration := int64(cp.StartMS), int64(defaultClipMS)
+	if cp.Span != nil {
+		startMS, duration = int64(cp.Span.StartMS), min(int64(cp.Span.EndMS-cp.Span.StartMS), maxClipMS)
+	}
+	if req.DurationMS > 0 {
+		duration = req.DurationMS
+	}
+	pad := req.PadMS
+	if pad == 0 {
+		pad = defaultClipPadMS
+	}
+	pcm, err := audio.DecodeFile(path)
+	if err != nil {
+		return nil, err
+	}
+	clip := pcm.Clip(startMS-pad, startMS+duration+pad)
+	if clip.Frames() == 0 {
+		return nil, fmt.Errorf("checkpoint %s starts at %d ms, after the audio ends", req.Checkpoint, startMS)
+	}
+	return clip.WAV(), nil
+}
+
+// handleCheckpointClip handles
+// GET /api/cases/{id}/checkpoints/{checkpoint}/clip?duration_ms=&pad_ms=
+func (s *Service) handleCheckpointClip(w http.ResponseWriter, r *http.Request) {
+	req := CheckpointClipRequest{ID: r.PathValue("id"), Checkpoint: r.PathValue("checkpoint")}
+	q := r.URL.Query()
+	for name, v := range map[string]*int64{"duration_ms": &req.DurationMS, "pad_ms": &req.PadMS} {
+		if s := q.Get(name); s != "" {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || n < 0 || n > maxClipMS {
+				http.Error(w, fmt.Sprintf("invalid %s: %s", name, s), http.StatusBadRequest)
+				return
+			}
+			*v = n
+		}
+	}
+	wav, err := s.CheckpointClip(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "audio/wav")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", req.ID+"-"+req.Checkpoint+".wav"))
+	w.Write(wav)
+}
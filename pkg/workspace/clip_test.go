@@ -0,0 +1,98 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+)
+
+func TestCheckpointClip(t *testing.T) {
+	dir := t.TempDir()
+	// 10 s at 1 kHz, each sample the millisecond it is at.
+	pcm := &audio.PCM{SampleRate: 1000, Channels: 1, Samples: make([]int16, 10000)}
+	for i := range pcm.Samples {
+		pcm.Samples[i] = int16(i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), pcm.FLAC(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	ec := &evalv2.EvalContext{Checkpoints: []evalv2.Checkpoint{
+		{ID: "S1", StartMS: 200},
+		{ID: "S2", StartMS: 5000},
+		{ID: "S3", StartMS: 12000},
+		{ID: "S4", StartMS: 8000, Span: &evalv2.Span{StartMS: 8100, EndMS: 8400}},
+	}}
+	if err := s.Storage.PutContext(ctx, "a", ec); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		req        CheckpointClipRequest
+		start, end int16 // Of the clip, in ms
+	}{
+		{"default", CheckpointClipRequest{ID: "a", Checkpoint: "S2"}, 4500, 7500},
+		{"set length", CheckpointClipRequest{ID: "a", Checkpoint: "S2", DurationMS: 1000, PadMS: 100}, 4900, 6100},
+		{"near the start", CheckpointClipRequest{ID: "a", Checkpoint: "S1"}, 0, 2700},
+		{"aligned", CheckpointClipRequest{ID: "a", Checkpoint: "S4", PadMS: 100}, 8000, 8500},
+		{"aligned, set length", CheckpointClipRequest{ID: "a", Checkpoint: "S4", DurationMS: 1000}, 7600, 9600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wav, err := s.CheckpointClip(ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := audio.Decode(bytes.NewReader(wav))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &audio.PCM{SampleRate: 1000, Channels: 1, Samples: pcm.Samples[tt.start:tt.end]}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("CheckpointClip() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, req := range []CheckpointClipRequest{
+		{ID: "a", Checkpoint: "S3"},
+		{ID: "a", Checkpoint: "S9"},
+		{ID: "a", Checkpoint: "S1", PadMS: maxClipMS + 1},
+		{ID: "b", Checkpoint: "S1"},
+	} {
+		if _, err := s.CheckpointClip(ctx, req); err == nil {
+			t.Errorf("CheckpointClip(%+v) succeeded", req)
+		}
+	}
+
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+	for _, tt := range []struct {
+		url  string
+		code int
+	}{
+		{"/api/cases/a/checkpoints/S2/clip?duration_ms=1000", http.StatusOK},
+		{"/api/cases/a/checkpoints/S2/clip?pad_ms=-1", http.StatusBadRequest},
+		{"/api/cases/a/checkpoints/S9/clip", http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", tt.url, nil))
+		if rec.Code != tt.code {
+			t.Errorf("GET %s: status %d, want %d", tt.url, rec.Code, tt.code)
+		}
+		if tt.code == http.StatusOK && rec.Header().Get("Content-Type") != "audio/wav" {
+			t.Errorf("GET %s: Content-Type %q, want audio/wav", tt.url, rec.Header().Get("Content-Type"))
+		}
+	}
+}
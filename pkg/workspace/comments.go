@@ -0,0 +1,137 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"asr-eval/pkg/atomicfile"
+)
+
+// extComments is the per-case sidecar holding its comments as a JSON array,
+// oldest first.
+const extComments = ".comments.json"
+
+// errNotAuthor is returned when deleting another user's comment.
+var errNotAuthor = errors.New("only the author can delete a comment")
+
+// Comment is a reviewer note on a case, optionally anchored to a provider
+// or checkpoint.
+type Comment struct {
+	ID           string    `json:"id"`
+	Author       string    `json:"author,omitempty"`
+	CreateTime   time.Time `json:"create_time"`
+	Body         string    `json:"body"` // Markdown
+	Provider     string    `json:"provider,omitempty"`
+	CheckpointID string    `json:"checkpoint_id,omitempty"`
+}
+
+// ListComments returns the comments on a case, oldest first.
+func (s *Service) ListComments(ctx context.Context, id string) ([]*Comment, error) {
+	if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, id+extFlac)); err != nil {
+		return nil, fmt.Errorf("case not found: %s", id)
+	}
+	return s.loadComments(id)
+}
+
+// CreateComment adds a comment to a case. The author is the authenticated
+// user, falling back to req.Author when authentication is disabled.
+func (s *Service) CreateComment(ctx context.Context, req CreateCommentRequest) (*Comment, error) {
+	if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, req.ID+extFlac)); err != nil {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return nil, fmt.Errorf("body is required")
+	}
+	if req.Provider != "" && !providerIDPattern.MatchString(req.Provider) {
+		return nil, fmt.Errorf("invalid provider ID: %q", req.Provider)
+	}
+
+	c := &Comment{
+		ID:           uuid.NewString(),
+		Author:       req.Author,
+		CreateTime:   time.Now().UTC(),
+		Body:         req.Body,
+		Provider:     req.Provider,
+		CheckpointID: req.CheckpointID,
+	}
+	if user := UserFromContext(ctx); user != "" {
+		c.Author = user
+	}
+
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	comments, err := s.loadComments(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.writeComments(req.ID, append(comments, c)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DeleteComment removes a comment. With authentication enabled only its
+// author may delete it.
+func (s *Service) DeleteComment(ctx context.Context, id, commentID string) error {
+	unlock, err := s.lockCase(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	comments, err := s.loadComments(id)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(comments, func(c *Comment) bool { return c.ID == commentID })
+	if i < 0 {
+		return fmt.Errorf("comment not found: %s", commentID)
+	}
+	if user := UserFromContext(ctx); user != "" && comments[i].Author != user {
+		return errNotAuthor
+	}
+	return s.writeComments(id, slices.Delete(comments, i, i+1))
+}
+
+func (s *Service) loadComments(id string) ([]*Comment, error) {
+	content, err := os.ReadFile(filepath.Join(s.Config.DatasetDir, id+extComments))
+	if os.IsNotExist(err) {
+		return []*Comment{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var comments []*Comment
+	if err := json.Unmarshal(content, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// writeComments stores comments for id, removing the sidecar when there are
+// none.
+func (s *Service) writeComments(id string, comments []*Comment) error {
+	filename := filepath.Join(s.Config.DatasetDir, id+extComments)
+	if len(comments) == 0 {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	bytes, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, bytes)
+}
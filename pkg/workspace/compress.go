@@ -0,0 +1,106 @@
+package workspace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"asr-eval/pkg/atomicfile"
+)
+
+// Report files may be gzip-compressed under the same name. Readers tell the
+// two forms apart by the gzip magic number, which no JSON document starts
+// with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readReportFile returns the JSON content of a report file, decompressing
+// it when it is gzip.
+func readReportFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeReportFile(content)
+}
+
+// decodeReportFile returns the JSON content of a report file read as is.
+func decodeReportFile(content []byte) ([]byte, error) {
+	if !bytes.HasPrefix(content, gzipMagic) {
+		return content, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// writeReportFile replaces a report file with data, gzip-compressed when
+// compress is set.
+func writeReportFile(path string, data []byte, compress bool) error {
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	return atomicfile.WriteFile(path, data)
+}
+
+// CompressReports gzips every report file of the dataset in place, current
+// ones, their history and those of runs, or decompresses them when
+// decompress is set. Files already in the requested form are left alone.
+func (s *Service) CompressReports(ctx context.Context, decompress, dryRun bool) (*MigrateResponse, error) {
+	m := fileMigration{
+		name: "compress-reports",
+		match: func(name string) bool {
+			return schemaFilePattern.MatchString(name) && !strings.Contains(name, ".gt.v2")
+		},
+		apply: func(ctx context.Context, dir, name string, dryRun bool) (bool, error) {
+			return s.compressReportFile(ctx, dir, name, !decompress, dryRun)
+		},
+	}
+	if decompress {
+		m.name = "decompress-reports"
+	}
+	return s.migrateFiles(ctx, m, dryRun)
+}
+
+// compressReportFile rewrites the report file name in dir in the requested
+// form, holding the case lock for current reports, and reports whether it
+// had to.
+func (s *Service) compressReportFile(ctx context.Context, dir, name string, compress, dryRun bool) (bool, error) {
+	if id, ok := strings.CutSuffix(name, extReportV2); ok && dir == s.Config.DatasetDir && !dryRun {
+		unlock, err := s.lockCase(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		defer unlock()
+	}
+	path := filepath.Join(dir, name)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if bytes.HasPrefix(raw, gzipMagic) == compress {
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+	content, err := decodeReportFile(raw)
+	if err != nil {
+		return false, err
+	}
+	return true, writeReportFile(path, content, compress)
+}
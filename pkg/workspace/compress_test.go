@@ -0,0 +1,55 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestCompressReports(t *testing.T) {
+	dir := t.TempDir()
+	st := NewFSStorage(dir)
+	st.CompressReports = true
+	ctx := context.Background()
+	report := &evalv2.EvalReport{Results: map[string]evalv2.EvalResult{"dg": {Transcript: "hello"}}}
+	if err := st.PutReport(ctx, "a", report); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "a"+extReportV2)
+	isGzip := func() bool {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bytes.HasPrefix(raw, gzipMagic)
+	}
+	if !isGzip() {
+		t.Error("PutReport wrote plain JSON, want gzip")
+	}
+
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: st}
+	resp, err := s.CompressReports(ctx, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Migrated != 1 || isGzip() {
+		t.Errorf("CompressReports(decompress) changed %d files, gzip left = %v", resp.Migrated, isGzip())
+	}
+	for _, compress := range []bool{false, true} {
+		st.CompressReports = compress
+		got, err := st.GetReport(ctx, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Results["dg"].Transcript != "hello" {
+			t.Errorf("GetReport = %+v", got)
+		}
+		if err := st.PutReport(ctx, "a", got); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Where the commands look for their shared configuration: the file named
+// by ConfigEnv, or ConfigFileName in the working directory.
+const (
+	ConfigFileName = "asr-eval.yaml"
+	ConfigEnv      = "ASR_EVAL_CONFIG"
+)
+
+// SharedConfig is the content of asr-eval.yaml, shared by the server and
+// the commands. Set fields replace the built-in defaults; a dataset's
+// dataset.yaml overrides it for that dataset, and flags override both.
+type SharedConfig struct {
+	DatasetDir      string `yaml:"dataset_dir,omitempty"`
+	MaxPromptTokens *int   `yaml:"max_prompt_tokens,omitempty"`
+	JobWorkers      int    `yaml:"job_workers,omitempty"`
+	Concurrency     int    `yaml:"concurrency,omitempty"` // Concurrent LLM or ASR calls of the server and batch tools
+	// APIKeys maps the environment variables the commands read their keys
+	// from, e.g. GEMINI_API_KEY, to the variables holding them.
+	APIKeys map[string]string `yaml:"api_keys,omitempty"`
+
+	DatasetConfig `yaml:",inline"`
+}
+
+// LoadSharedConfig reads the shared configuration. It returns an empty
+// one when there is none, and an error when ConfigEnv names a missing file.
+func LoadSharedConfig() (*SharedConfig, error) {
+	path := os.Getenv(ConfigEnv)
+	explicit := path != ""
+	if !explicit {
+		path = ConfigFileName
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return &SharedConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c SharedConfig
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.DatasetConfig.validate(path); err != nil {
+		return nil, err
+	}
+	if c.MaxPromptTokens != nil && *c.MaxPromptTokens < 0 {
+		return nil, fmt.Errorf("%s: max_prompt_tokens must not be negative", path)
+	}
+	return &c, nil
+}
+
+// LoadServiceConfig returns DefaultServiceConfig with the shared
+// configuration applied, and that configuration.
+func LoadServiceConfig() (ServiceConfig, *SharedConfig, error) {
+	cfg := DefaultServiceConfig()
+	c, err := LoadSharedConfig()
+	if err != nil {
+		return cfg, nil, err
+	}
+	c.Apply(&cfg)
+	return cfg, c, nil
+}
+
+// Apply overrides cfg with the fields set in c. Call it before binding
+// flags to cfg, so that flags given explicitly win.
+func (c *SharedConfig) Apply(cfg *ServiceConfig) {
+	if c.DatasetDir != "" {
+		cfg.DatasetDir = c.DatasetDir
+	}
+	if c.MaxPromptTokens != nil {
+		cfg.MaxPromptTokens = *c.MaxPromptTokens
+	}
+	if c.JobWorkers > 0 {
+		cfg.JobWorkers = c.JobWorkers
+	}
+	c.DatasetConfig.Apply(cfg)
+	if cfg.WebhookFormat == "" { // Also the flags' default
+		cfg.WebhookFormat = WebhookSlack
+	}
+}
+
+// ConcurrencyOr returns the configured concurrency, or def when unset.
+func (c *SharedConfig) ConcurrencyOr(def int) int {
+	if c.Concurrency > 0 {
+		return c.Concurrency
+	}
+	return def
+}
+
+// ExportAPIKeys sets each unset variable of APIKeys from the variable it
+// maps to, so code reading the standard names finds the keys. Call it
+// after loading any .env file.
+func (c *SharedConfig) ExportAPIKeys() {
+	for name, from := range c.APIKeys {
+		if os.Getenv(name) != "" {
+			continue
+		}
+		if v := os.Getenv(from); v != "" {
+			os.Setenv(name, v)
+		}
+	}
+}
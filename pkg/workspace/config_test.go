@@ -0,0 +1,80 @@
+package workspace
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadServiceConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	content := `dataset_dir: /data/zh
+eval_model: judge
+max_prompt_tokens: 0
+concurrency: 3
+api_keys:
+  ASR_EVAL_TEST_KEY: ASR_EVAL_TEST_TEAM_KEY
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigEnv, path)
+
+	cfg, shared, err := LoadServiceConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := DefaultServiceConfig()
+	if cfg.DatasetDir != "/data/zh" || cfg.EvalModel != "judge" || cfg.GenModel != def.GenModel || cfg.MaxPromptTokens != 0 {
+		t.Errorf("config = %+v", cfg)
+	}
+	if cfg.WebhookFormat != WebhookSlack {
+		t.Errorf("WebhookFormat = %q, want %q", cfg.WebhookFormat, WebhookSlack)
+	}
+	if got := shared.ConcurrencyOr(10); got != 3 {
+		t.Errorf("ConcurrencyOr(10) = %d, want 3", got)
+	}
+
+	t.Setenv("ASR_EVAL_TEST_KEY", "")
+	t.Setenv("ASR_EVAL_TEST_TEAM_KEY", "secret")
+	shared.ExportAPIKeys()
+	if got := os.Getenv("ASR_EVAL_TEST_KEY"); got != "secret" {
+		t.Errorf("ASR_EVAL_TEST_KEY = %q, want secret", got)
+	}
+
+	t.Setenv(ConfigEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, _, err := LoadServiceConfig(); err == nil {
+		t.Error("LoadServiceConfig of a missing ASR_EVAL_CONFIG succeeded, want error")
+	}
+}
+
+func TestFlagOverridesWinOverDatasetConfig(t *testing.T) {
+	dir := t.TempDir()
+	content := "gen_model: dataset-gen\neval_model: dataset-eval\n"
+	if err := os.WriteFile(filepath.Join(dir, datasetConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultServiceConfig()
+	cfg.DatasetDir = dir
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&cfg.GenModel, "gen-model", cfg.GenModel, "")
+	fs.StringVar(&cfg.EvalModel, "eval-model", cfg.EvalModel, "")
+	fs.IntVar(&cfg.JobWorkers, "workers", cfg.JobWorkers, "")
+	if err := fs.Parse([]string{"-workers", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if dc := FlagOverrides(fs, &cfg); dc != nil {
+		t.Errorf("FlagOverrides without model flags = %+v, want nil", dc)
+	}
+	if err := fs.Parse([]string{"-eval-model", "flag-eval"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Overrides = FlagOverrides(fs, &cfg)
+
+	s := NewService(cfg, nil)
+	defer s.Close()
+	if s.Config.GenModel != "dataset-gen" || s.Config.EvalModel != "flag-eval" {
+		t.Errorf("models = %q, %q, want dataset-gen from dataset.yaml and flag-eval from the flag", s.Config.GenModel, s.Config.EvalModel)
+	}
+}
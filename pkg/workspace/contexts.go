@@ -0,0 +1,128 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// ListContexts returns the current eval context of a case followed by its
+// archived versions, newest first. Each entry records what changed relative
+// to the version before it.
+func (s *Service) ListContexts(ctx context.Context, id string) ([]*ContextVersion, error) {
+	versions, err := s.Storage.ListContextVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	type loaded struct {
+		RecordVersion
+		ctx *evalv2.EvalContext
+	}
+	var all []loaded // Oldest first
+	for _, v := range versions {
+		var c *evalv2.EvalContext
+		if v.N == 0 {
+			c, err = s.Storage.GetContext(ctx, id)
+		} else {
+			c, err = s.Storage.GetContextVersion(ctx, id, v.N)
+		}
+		if err != nil {
+			continue
+		}
+		all = append(all, loaded{v, c})
+	}
+
+	out := make([]*ContextVersion, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		l := all[i]
+		v := &ContextVersion{
+			Version:     l.N,
+			UpdateTime:  l.UpdateTime,
+			Hash:        l.ctx.Hash,
+			Checkpoints: len(l.ctx.Checkpoints),
+		}
+		if i > 0 {
+			v.Changes = diffContexts(all[i-1].ctx, l.ctx)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// GetContextVersion returns archived version n of a case's eval context, or
+// the current one for n == 0.
+func (s *Service) GetContextVersion(ctx context.Context, id string, n int) (*evalv2.EvalContext, error) {
+	var (
+		c   *evalv2.EvalContext
+		err error
+	)
+	if n == 0 {
+		c, err = s.loadEvalContext(ctx, id)
+	} else {
+		c, err = s.Storage.GetContextVersion(ctx, id, n)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("context version %d not found", n)
+	}
+	return c, nil
+}
+
+// RestoreContext makes an archived context version current through
+// UpdateContext, so the replaced context is archived and the report is
+// invalidated as for any other edit.
+func (s *Service) RestoreContext(ctx context.Context, req RestoreContextRequest) (*Case, error) {
+	if req.Version == 0 {
+		return nil, fmt.Errorf("version is required")
+	}
+	c, err := s.GetContextVersion(ctx, req.ID, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditRestoreContext, req.ID, map[string]string{"version": strconv.Itoa(req.Version)})
+	return s.UpdateContext(ctx, UpdateContextRequest{ID: req.ID, EvalContext: c})
+}
+
+// diffContexts summarizes the changes from a to b.
+func diffContexts(a, b *evalv2.EvalContext) *ContextChanges {
+	ch := &ContextChanges{}
+
+	old := make(map[string]evalv2.Checkpoint, len(a.Checkpoints))
+	for _, cp := range a.Checkpoints {
+		old[cp.ID] = cp
+	}
+	seen := make(map[string]bool, len(b.Checkpoints))
+	for _, cp := range b.Checkpoints {
+		seen[cp.ID] = true
+		prev, ok := old[cp.ID]
+		switch {
+		case !ok:
+			ch.AddedCheckpoints = append(ch.AddedCheckpoints, cp.ID)
+		case prev != cp:
+			ch.ChangedCheckpoints = append(ch.ChangedCheckpoints, cp.ID)
+		}
+	}
+	for _, cp := range a.Checkpoints {
+		if !seen[cp.ID] {
+			ch.RemovedCheckpoints = append(ch.RemovedCheckpoints, cp.ID)
+		}
+	}
+
+	// Compare meta field by field through its JSON form so new fields are
+	// covered without touching this code.
+	var ma, mb map[string]any
+	ja, _ := json.Marshal(a.Meta)
+	jb, _ := json.Marshal(b.Meta)
+	json.Unmarshal(ja, &ma)
+	json.Unmarshal(jb, &mb)
+	for _, k := range slices.Sorted(maps.Keys(mb)) {
+		if fmt.Sprint(ma[k]) != fmt.Sprint(mb[k]) {
+			ch.ChangedMeta = append(ch.ChangedMeta, k)
+		}
+	}
+	return ch
+}
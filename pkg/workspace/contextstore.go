@@ -0,0 +1,204 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/evalv2"
+)
+
+// contextsDirName is the content-addressed context store of a dataset
+// directory: "<hash>.json" holds the context whose contextHash is hash.
+// Context files and report snapshots refer to it by hash, so a context
+// shared by many reports and history versions is stored once. Entries are
+// written once and never changed.
+const contextsDirName = ".contexts"
+
+// contextBlobPattern matches the names of context store entries.
+var contextBlobPattern = regexp.MustCompile(`^[0-9a-f]{32}\.json$`)
+
+// isContextRef reports whether c is a reference to the context store: a
+// hash and nothing else.
+func isContextRef(c *evalv2.EvalContext) bool {
+	return c.Hash != "" && c.Meta == (evalv2.ContextMeta{}) && len(c.Checkpoints) == 0
+}
+
+// storable reports whether c can go to the context store: it is a full
+// context whose hash matches its content. An aligned context has the hash
+// of its unaligned text, whose entry it must not replace or be mistaken
+// for, so it is written inline.
+func storable(c *evalv2.EvalContext) bool {
+	return c.Hash != "" && !isContextRef(c) && contextHash(c) == c.Hash && !c.Aligned()
+}
+
+// storeContext saves c in the context store of dir and returns a reference
+// to it. A context that is not storable is returned as is, to be written
+// inline.
+func storeContext(dir string, c *evalv2.EvalContext) (*evalv2.EvalContext, error) {
+	if !storable(c) {
+		return c, nil
+	}
+	ref := &evalv2.EvalContext{SchemaVersion: evalv2.SchemaVersion, Hash: c.Hash}
+	p := filepath.Join(dir, contextsDirName, c.Hash+extJSON)
+	if _, err := os.Stat(p); err == nil {
+		return ref, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return nil, err
+	}
+	blob := *c
+	blob.SchemaVersion = evalv2.SchemaVersion
+	bytes, err := json.MarshalIndent(&blob, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := atomicfile.WriteFile(p, bytes); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// storeReportContext returns report with its context snapshot replaced by
+// a reference to the context store of dir.
+func storeReportContext(dir string, report *evalv2.EvalReport) (*evalv2.EvalReport, error) {
+	ref, err := storeContext(dir, &report.ContextSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	r := *report
+	r.ContextSnapshot = *ref
+	return &r, nil
+}
+
+// resolveContext returns the context c refers to in the context store of
+// dir, or c itself when it is not a reference.
+func resolveContext(dir string, c *evalv2.EvalContext) (*evalv2.EvalContext, error) {
+	if !isContextRef(c) {
+		return c, nil
+	}
+	content, err := os.ReadFile(filepath.Join(dir, contextsDirName, c.Hash+extJSON))
+	if err != nil {
+		return nil, fmt.Errorf("context %s: %w", c.Hash, err)
+	}
+	return evalv2.UnmarshalContext(content)
+}
+
+// resolveReportContext replaces the context snapshot of report by the
+// context it refers to. A snapshot missing from the store is left as a
+// reference: its hash is still enough to tell whether the report is stale.
+func resolveReportContext(dir string, report *evalv2.EvalReport) {
+	c, err := resolveContext(dir, &report.ContextSnapshot)
+	if err != nil {
+		slog.Warn("Report context snapshot unavailable", "dataset", dir, "error", err)
+		return
+	}
+	report.ContextSnapshot = *c
+}
+
+// storeFileContexts moves the inline context of a context or report
+// document to the context store of dir, returning the rewritten document,
+// or data and false when there is nothing to move. With dryRun set it only
+// tells whether there is.
+func storeFileContexts(dir string, data []byte, report, dryRun bool) ([]byte, bool, error) {
+	var v any
+	var c *evalv2.EvalContext
+	if report {
+		r, err := evalv2.UnmarshalReport(data)
+		if err != nil {
+			return nil, false, err
+		}
+		v, c = r, &r.ContextSnapshot
+	} else {
+		ec, err := evalv2.UnmarshalContext(data)
+		if err != nil {
+			return nil, false, err
+		}
+		v, c = ec, ec
+	}
+	if !storable(c) || dryRun {
+		return data, storable(c), nil
+	}
+	ref, err := storeContext(dir, c)
+	if err != nil {
+		return nil, false, err
+	}
+	*c = *ref
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// refersToContexts reports whether the file name may refer to the context
+// store: a context or report file, or a case bundle.
+func refersToContexts(name string) bool {
+	return schemaFilePattern.MatchString(name) || strings.HasSuffix(name, extCase)
+}
+
+// fileContextRefs returns the hashes a context or report file, or a case
+// bundle, refers to in the context store.
+func fileContextRefs(path string) []string {
+	var cs []*evalv2.EvalContext
+	if strings.HasSuffix(path, extCase) {
+		b, err := readCaseBundle(path)
+		if err != nil {
+			return nil
+		}
+		if b.Context != nil {
+			cs = append(cs, b.Context)
+		}
+		if b.Report != nil {
+			cs = append(cs, &b.Report.ContextSnapshot)
+		}
+	} else if content, err := readReportFile(path); err == nil { // Or a context file, never compressed
+		if strings.Contains(filepath.Base(path), ".gt.v2") {
+			if c, err := evalv2.UnmarshalContext(content); err == nil {
+				cs = append(cs, c)
+			}
+		} else if r, err := evalv2.UnmarshalReport(content); err == nil {
+			cs = append(cs, &r.ContextSnapshot)
+		}
+	}
+	var hashes []string
+	for _, c := range cs {
+		if isContextRef(c) {
+			hashes = append(hashes, c.Hash)
+		}
+	}
+	return hashes
+}
+
+// writeContextBlob saves the context store entry name, read from r, unless
+// it exists. Its content must hash to its name.
+func writeContextBlob(dir, name string, r io.Reader) (bool, error) {
+	if !contextBlobPattern.MatchString(name) {
+		return false, fmt.Errorf("invalid context store entry: %s", name)
+	}
+	p := filepath.Join(dir, contextsDirName, name)
+	if _, err := os.Stat(p); err == nil {
+		return false, nil
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+	c, err := evalv2.UnmarshalContext(content)
+	if err != nil {
+		return false, fmt.Errorf("context store entry %s: %w", name, err)
+	}
+	if contextHash(c)+extJSON != name {
+		return false, fmt.Errorf("context store entry %s does not match its hash", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return false, err
+	}
+	return true, atomicfile.WriteFile(p, content)
+}
@@ -0,0 +1,67 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestContextStore(t *testing.T) {
+	dir := t.TempDir()
+	st := NewFSStorage(dir)
+	ctx := context.Background()
+
+	ec := &evalv2.EvalContext{
+		Meta:        evalv2.ContextMeta{GroundTruth: strings.Repeat("hello ", 100)},
+		Checkpoints: []evalv2.Checkpoint{{ID: "c1", TextSegment: "hello"}},
+	}
+	ec.Hash = contextHash(ec)
+	for _, id := range []string{"a", "b"} {
+		if err := st.PutContext(ctx, id, ec); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.PutReport(ctx, id, &evalv2.EvalReport{ContextSnapshot: *ec}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	blobs, err := os.ReadDir(filepath.Join(dir, contextsDirName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobs) != 1 || blobs[0].Name() != ec.Hash+extJSON {
+		t.Errorf("context store = %v, want one entry for %s", blobs, ec.Hash)
+	}
+	for _, name := range []string{"a" + extGTV2, "b" + extReportV2} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(content), "hello") {
+			t.Errorf("%s holds the context inline:\n%s", name, content)
+		}
+	}
+
+	want := *ec
+	want.SchemaVersion = evalv2.SchemaVersion
+	got, err := st.GetContext(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("GetContext mismatch (-want +got):\n%s", diff)
+	}
+	r, err := st.GetReport(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, r.ContextSnapshot); diff != "" {
+		t.Errorf("report snapshot mismatch (-want +got):\n%s", diff)
+	}
+}
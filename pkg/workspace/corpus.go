@@ -0,0 +1,104 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"asr-eval/pkg/corpus"
+	"asr-eval/pkg/evalv2"
+)
+
+// ImportCorpusRequest selects how ImportCorpus names and converts cases.
+type ImportCorpusRequest struct {
+	Prefix     string // Prepended to utterance IDs to make case IDs
+	SampleRate int    // Resample the audio to this rate; 0 keeps it
+	Channels   int    // Mix the audio to this many channels; 0 keeps them
+	DryRun     bool   // Only count the cases to import
+}
+
+// ImportCorpusResponse counts what ImportCorpus did.
+type ImportCorpusResponse struct {
+	Imported int
+	IDs      []string // Of the imported cases
+	Skipped  []string // Cases that already exist
+	Failed   []string // Utterances that could not be imported, with the reason
+}
+
+// ImportCorpus adds a case per utterance: its audio as FLAC and a context
+// carrying only the reference transcript as ground truth, ready for
+// context generation. Existing cases are left untouched, so an interrupted
+// import can be run again.
+func (s *Service) ImportCorpus(ctx context.Context, utts []corpus.Utterance, req ImportCorpusRequest) (*ImportCorpusResponse, error) {
+	resp := &ImportCorpusResponse{IDs: []string{}, Skipped: []string{}, Failed: []string{}}
+	for _, u := range utts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		id := req.Prefix + u.ID
+		if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+			resp.Failed = append(resp.Failed, fmt.Sprintf("%s: invalid case ID", id))
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, id+extFlac)); err == nil {
+			resp.Skipped = append(resp.Skipped, id)
+			continue
+		}
+		if req.DryRun {
+			resp.Imported++
+			continue
+		}
+		if err := s.importUtterance(ctx, id, u, req); err != nil {
+			resp.Failed = append(resp.Failed, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		resp.Imported++
+		resp.IDs = append(resp.IDs, id)
+	}
+	return resp, nil
+}
+
+func (s *Service) importUtterance(ctx context.Context, id string, u corpus.Utterance, req ImportCorpusRequest) error {
+	unlock, err := s.lockCase(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.writeFLAC(ctx, id, u, req.SampleRate, req.Channels); err != nil {
+		return err
+	}
+	if err := s.Storage.PutCase(ctx, id); err != nil {
+		return err
+	}
+	if u.Text != "" {
+		ec := &evalv2.EvalContext{Meta: evalv2.ContextMeta{GroundTruth: u.Text}}
+		if err := s.writeEvalContext(ctx, id, ec); err != nil {
+			return err
+		}
+	}
+	s.audit(ctx, AuditImportCase, id, map[string]string{"source": u.Audio})
+	return nil
+}
+
+// writeFLAC writes the audio of u as the FLAC file of case id, converting
+// it next to its final name so a failed or interrupted conversion leaves
+// no case behind.
+func (s *Service) writeFLAC(ctx context.Context, id string, u corpus.Utterance, sampleRate, channels int) error {
+	f, err := os.CreateTemp(s.Config.DatasetDir, ".tmp-"+id+extFlac+"-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	if err := corpus.ToFLAC(ctx, u, sampleRate, channels, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.Config.DatasetDir, id+extFlac))
+}
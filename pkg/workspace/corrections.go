@@ -0,0 +1,112 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"asr-eval/pkg/atomicfile"
+)
+
+// extCorrections is the per-case sidecar holding human-corrected provider
+// transcripts, keyed by provider. The raw provider output is left as is.
+const extCorrections = ".corrections.json"
+
+// Correction is a reviewer's fix of a provider transcript.
+type Correction struct {
+	Text       string    `json:"text"`
+	UpdatedBy  string    `json:"updated_by,omitempty"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+// UpdateCorrection saves a corrected version of a provider transcript and
+// returns the updated case. An empty Text removes the correction.
+func (s *Service) UpdateCorrection(ctx context.Context, req UpdateCorrectionRequest) (*Case, error) {
+	if !providerIDPattern.MatchString(req.Provider) {
+		return nil, fmt.Errorf("invalid provider ID: %q", req.Provider)
+	}
+	if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, req.ID+extFlac)); err != nil {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+	if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, req.ID+"."+req.Provider)); err != nil {
+		return nil, fmt.Errorf("no %s transcript for case %s", req.Provider, req.ID)
+	}
+
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	corrections, err := s.loadCorrections(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Text == "" {
+		delete(corrections, req.Provider)
+	} else {
+		if corrections == nil {
+			corrections = make(map[string]*Correction)
+		}
+		corrections[req.Provider] = &Correction{
+			Text:       req.Text,
+			UpdatedBy:  UserFromContext(ctx),
+			UpdateTime: time.Now().UTC(),
+		}
+	}
+	if err := s.writeCorrections(req.ID, corrections); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditUpdateCorrection, req.ID, map[string]string{
+		"provider": req.Provider,
+		"removed":  fmt.Sprint(req.Text == ""),
+	})
+	return s.GetCase(ctx, req.ID)
+}
+
+// applyCorrections returns transcripts with the corrected text in place of
+// the raw output wherever c has a correction.
+func applyCorrections(transcripts map[string]string, c *Case) map[string]string {
+	out := make(map[string]string, len(transcripts))
+	for p, t := range transcripts {
+		if corr, ok := c.Corrections[p]; ok {
+			t = corr.Text
+		}
+		out[p] = t
+	}
+	return out
+}
+
+func (s *Service) loadCorrections(id string) (map[string]*Correction, error) {
+	content, err := os.ReadFile(filepath.Join(s.Config.DatasetDir, id+extCorrections))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var corrections map[string]*Correction
+	if err := json.Unmarshal(content, &corrections); err != nil {
+		return nil, err
+	}
+	return corrections, nil
+}
+
+// writeCorrections stores corrections for id, removing the sidecar when
+// there are none.
+func (s *Service) writeCorrections(id string, corrections map[string]*Correction) error {
+	filename := filepath.Join(s.Config.DatasetDir, id+extCorrections)
+	if len(corrections) == 0 {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	bytes, err := json.MarshalIndent(corrections, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, bytes)
+}
@@ -0,0 +1,48 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUpdateCorrection(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.flac": "", "a.x": "raw x", "a.y": "raw y"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+
+	if _, err := s.UpdateCorrection(ctx, UpdateCorrectionRequest{ID: "a", Provider: "z", Text: "fixed"}); err == nil {
+		t.Error("correcting a missing transcript succeeded, want error")
+	}
+
+	c, err := s.UpdateCorrection(ctx, UpdateCorrectionRequest{ID: "a", Provider: "x", Text: "fixed x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Transcripts["x"] != "raw x" {
+		t.Errorf("raw transcript = %q, want it untouched", c.Transcripts["x"])
+	}
+	got := applyCorrections(c.Transcripts, c)
+	if diff := cmp.Diff(map[string]string{"x": "fixed x", "y": "raw y"}, got); diff != "" {
+		t.Errorf("applyCorrections mismatch (-want +got):\n%s", diff)
+	}
+
+	c, err = s.UpdateCorrection(ctx, UpdateCorrectionRequest{ID: "a", Provider: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Corrections != nil {
+		t.Errorf("Corrections = %v after removal, want none", c.Corrections)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a"+extCorrections)); !os.IsNotExist(err) {
+		t.Errorf("sidecar still exists: %v", err)
+	}
+}
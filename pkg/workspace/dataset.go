@@ -0,0 +1,235 @@
+package workspace
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+)
+
+// datasetConfigFileName is the per-dataset configuration file.
+const datasetConfigFileName = "dataset.yaml"
+
+// DatasetConfig is the content of a dataset's dataset.yaml. Set fields
+// override the server-wide ServiceConfig for that dataset, except settings
+// given explicitly by flags.
+type DatasetConfig struct {
+	GenModel         string                     `yaml:"gen_model,omitempty"`
+	EvalModel        string                     `yaml:"eval_model,omitempty"`
+	FallbackModels   []string                   `yaml:"fallback_models,omitempty"`
+	JudgeModels      []string                   `yaml:"judge_models,omitempty"`
+	EnabledProviders map[string]bool            `yaml:"enabled_providers,omitempty"`
+	Scoring          *ScoringConfig             `yaml:"scoring,omitempty"`
+	Pricing          map[string]ProviderPricing `yaml:"pricing,omitempty"`       // By provider
+	ModelPricing     map[string]ModelPricing    `yaml:"model_pricing,omitempty"` // By LLM
+	Tags             []string                   `yaml:"tags,omitempty"`          // Allowed case tags; empty allows any
+	Webhook          *WebhookConfig             `yaml:"webhook,omitempty"`
+	CompressReports  bool                       `yaml:"compress_reports,omitempty"`
+	CaseBundles      bool                       `yaml:"case_bundles,omitempty"`
+	Chunking         *ChunkingConfig            `yaml:"chunking,omitempty"`
+	Channels         *audio.ChannelOptions      `yaml:"channels,omitempty"` // Overridden per case by its .channels.json
+	SampleRate       int                        `yaml:"sample_rate,omitempty"`
+}
+
+// ChunkingConfig splits long audio for transcription.
+type ChunkingConfig struct {
+	Seconds        float64 `yaml:"seconds"`                   // Audio longer than this is transcribed in chunks of it; 0 disables
+	OverlapSeconds float64 `yaml:"overlap_seconds,omitempty"` // Audio each chunk shares with the next
+	Concurrency    int     `yaml:"concurrency,omitempty"`     // Chunks transcribed at once; below 1 means 1
+}
+
+// WebhookConfig is where to report finished evaluations.
+type WebhookConfig struct {
+	URL    string `yaml:"url"`
+	Format string `yaml:"format,omitempty"` // slack (default) or feishu
+}
+
+// ScoringConfig parameterizes the composite Q score.
+type ScoringConfig struct {
+	SScoreWeight *float64 `yaml:"s_score_weight,omitempty"` // Q = S^w * P^(1-w)
+}
+
+// ProviderPricing is what a provider charges for transcription.
+type ProviderPricing struct {
+	PerHour  float64 `yaml:"per_hour" json:"per_hour"` // Per hour of audio
+	Currency string  `yaml:"currency,omitempty" json:"currency,omitempty"`
+}
+
+// ModelPricing is what an LLM charges per million tokens. Thought tokens
+// are billed as output.
+type ModelPricing struct {
+	InputPerMTok  float64 `yaml:"input_per_mtok" json:"input_per_mtok"`
+	OutputPerMTok float64 `yaml:"output_per_mtok" json:"output_per_mtok"`
+}
+
+// LoadDatasetConfig reads dataset.yaml from dir. It returns nil when the
+// file does not exist.
+func LoadDatasetConfig(dir string) (*DatasetConfig, error) {
+	path := filepath.Join(dir, datasetConfigFileName)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var dc DatasetConfig
+	if err := yaml.Unmarshal(content, &dc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := dc.validate(path); err != nil {
+		return nil, err
+	}
+	return &dc, nil
+}
+
+// validate checks the values of dc, read from path.
+func (dc *DatasetConfig) validate(path string) error {
+	if h := dc.Webhook; h != nil && h.Format != "" && h.Format != WebhookSlack && h.Format != WebhookFeishu {
+		return fmt.Errorf("%s: webhook.format must be %s or %s", path, WebhookSlack, WebhookFeishu)
+	}
+	if w := dc.Scoring; w != nil && w.SScoreWeight != nil && (*w.SScoreWeight < 0 || *w.SScoreWeight > 1) {
+		return fmt.Errorf("%s: scoring.s_score_weight must be within [0, 1]", path)
+	}
+	if c := dc.Chunking; c != nil && (c.Seconds < 0 || c.OverlapSeconds < 0 || c.Seconds > 0 && c.OverlapSeconds*2 >= c.Seconds) {
+		return fmt.Errorf("%s: chunking.overlap_seconds must be less than half of chunking.seconds", path)
+	}
+	if c := dc.Channels; c != nil {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("%s: channels: %w", path, err)
+		}
+	}
+	if dc.SampleRate < 0 {
+		return fmt.Errorf("%s: sample_rate must not be negative", path)
+	}
+	return nil
+}
+
+// Apply overrides cfg with the fields set in dc.
+func (dc *DatasetConfig) Apply(cfg *ServiceConfig) {
+	if dc.GenModel != "" {
+		cfg.GenModel = dc.GenModel
+	}
+	if dc.EvalModel != "" {
+		cfg.EvalModel = dc.EvalModel
+	}
+	if len(dc.FallbackModels) > 0 {
+		cfg.FallbackModels = dc.FallbackModels
+	}
+	if len(dc.JudgeModels) > 0 {
+		cfg.JudgeModels = dc.JudgeModels
+	}
+	if dc.EnabledProviders != nil {
+		cfg.EnabledProviders = dc.EnabledProviders
+	}
+	if dc.Scoring != nil && dc.Scoring.SScoreWeight != nil {
+		cfg.SScoreWeight = *dc.Scoring.SScoreWeight
+	}
+	if dc.Pricing != nil {
+		cfg.Pricing = dc.Pricing
+	}
+	if dc.ModelPricing != nil {
+		cfg.ModelPricing = dc.ModelPricing
+	}
+	if dc.Tags != nil {
+		cfg.Tags = dc.Tags
+	}
+	if dc.Webhook != nil {
+		cfg.WebhookURL = dc.Webhook.URL
+		cfg.WebhookFormat = dc.Webhook.Format
+	}
+	if dc.CompressReports {
+		cfg.CompressReports = true
+	}
+	if dc.CaseBundles {
+		cfg.CaseBundles = true
+	}
+	if dc.Chunking != nil {
+		cfg.Chunking = *dc.Chunking
+	}
+	if dc.Channels != nil {
+		cfg.Channels = *dc.Channels
+	}
+	if dc.SampleRate > 0 {
+		cfg.SampleRate = dc.SampleRate
+	}
+}
+
+// FlagOverrides returns the settings of cfg that flags set on fs gave
+// explicitly, for ServiceConfig.Overrides, or nil when there are none. The
+// flags are those the commands share: -gen-model, -eval-model,
+// -fallback-models, -judge-models, -webhook-url and -webhook-format.
+func FlagOverrides(fs *flag.FlagSet, cfg *ServiceConfig) *DatasetConfig {
+	var dc DatasetConfig
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "gen-model":
+			dc.GenModel = cfg.GenModel
+		case "eval-model":
+			dc.EvalModel = cfg.EvalModel
+		case "fallback-models":
+			dc.FallbackModels = cfg.FallbackModels
+		case "judge-models":
+			dc.JudgeModels = cfg.JudgeModels
+		case "webhook-url", "webhook-format":
+			dc.Webhook = &WebhookConfig{URL: cfg.WebhookURL, Format: cfg.WebhookFormat}
+		default:
+			return
+		}
+		set = true
+	})
+	if !set {
+		return nil
+	}
+	return &dc
+}
+
+// updateDatasetConfig sets a top-level key of dir's dataset.yaml to value,
+// keeping the rest of the file, comments included.
+func updateDatasetConfig(dir, key string, value any) error {
+	path := filepath.Join(dir, datasetConfigFileName)
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: top level is not a mapping", path)
+	}
+
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return err
+	}
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			root.Content[i+1] = &v
+			found = true
+			break
+		}
+	}
+	if !found {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &v)
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, out)
+}
@@ -0,0 +1,150 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// datasetNamePattern restricts dataset names, which appear in URLs.
+var datasetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Datasets serves several dataset directories from one server. Each dataset
+// has its own Service; requests pick one with the "dataset" query
+// parameter, so case IDs are namespaced by dataset.
+type Datasets struct {
+	names    []string // Mount order; the first is the default
+	services map[string]*Service
+}
+
+// NewDatasets returns an empty set of datasets.
+func NewDatasets() *Datasets {
+	return &Datasets{services: make(map[string]*Service)}
+}
+
+// Mount adds svc under name. The first mounted dataset is the default.
+func (d *Datasets) Mount(name string, svc *Service) error {
+	if !datasetNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid dataset name: %q", name)
+	}
+	if _, ok := d.services[name]; ok {
+		return fmt.Errorf("dataset already mounted: %s", name)
+	}
+	d.names = append(d.names, name)
+	d.services[name] = svc
+	return nil
+}
+
+// Get returns the dataset called name, or the default one when name is
+// empty.
+func (d *Datasets) Get(name string) (*Service, error) {
+	if name == "" {
+		if len(d.names) == 0 {
+			return nil, fmt.Errorf("no datasets mounted")
+		}
+		name = d.names[0]
+	}
+	svc, ok := d.services[name]
+	if !ok {
+		return nil, fmt.Errorf("dataset not found: %s", name)
+	}
+	return svc, nil
+}
+
+// Names returns the mounted dataset names in mount order.
+func (d *Datasets) Names() []string {
+	return d.names
+}
+
+// Close stops background jobs of every dataset.
+func (d *Datasets) Close() {
+	for _, svc := range d.services {
+		svc.Close()
+	}
+}
+
+// CloseEvents ends the event streams of every dataset.
+func (d *Datasets) CloseEvents() {
+	for _, svc := range d.services {
+		svc.CloseEvents()
+	}
+}
+
+// ParseDatasetRoot parses a "name=dir" mount flag. A bare dir is named
+// after its base name.
+func ParseDatasetRoot(v string) (name, dir string) {
+	if name, dir, ok := strings.Cut(v, "="); ok {
+		return name, dir
+	}
+	return filepath.Base(filepath.Clean(v)), v
+}
+
+func (d *Datasets) RegisterRoutes(mux *http.ServeMux) {
+	for _, rt := range apiRoutes {
+		h := checkPathIDs(rt.handler)
+		mux.HandleFunc(rt.pattern, func(w http.ResponseWriter, r *http.Request) {
+			svc, err := d.Get(r.URL.Query().Get("dataset"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			h(svc, w, r)
+		})
+	}
+
+	// Config
+	mux.HandleFunc("GET /api/config", d.handleGetConfig)
+
+	// Audio
+	mux.HandleFunc("GET /audio/{name}", d.handleAudio)
+
+	// Health
+	registerHealthRoutes(mux, d.ready)
+}
+
+// ready reports the first mounted dataset that cannot serve requests.
+func (d *Datasets) ready() error {
+	for _, name := range d.names {
+		if err := d.services[name].ready(); err != nil {
+			return fmt.Errorf("dataset %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// handleGetConfig handles GET /api/config?dataset=
+func (d *Datasets) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("dataset")
+	svc, err := d.Get(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if name == "" {
+		name = d.names[0]
+	}
+	cfg := svc.config()
+	cfg.Dataset = name
+	cfg.Datasets = d.names
+	cfg.Role = RoleFromContext(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cfg)
+}
+
+// handleAudio handles GET /audio/{name}?dataset=
+func (d *Datasets) handleAudio(w http.ResponseWriter, r *http.Request) {
+	svc, err := d.Get(r.URL.Query().Get("dataset"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	name := r.PathValue("name")
+	if strings.ContainsAny(name, `/\`) || name == ".." {
+		http.Error(w, "invalid file name", http.StatusBadRequest)
+		return
+	}
+	http.ServeFile(w, r, filepath.Join(svc.Config.DatasetDir, name))
+}
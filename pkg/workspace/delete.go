@@ -0,0 +1,89 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DeleteCase removes every dataset file of a case: audio, transcripts,
+// contexts, reports and stream dumps. Pending jobs for the case are
+// canceled. With ValidateOnly it only lists the files.
+func (s *Service) DeleteCase(ctx context.Context, req DeleteCaseRequest) (*DeleteCaseResponse, error) {
+	if req.ID == "" || strings.ContainsAny(req.ID, `/\`) {
+		return nil, fmt.Errorf("invalid case ID: %q", req.ID)
+	}
+	files, err := caseFiles(s.Config.DatasetDir, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+
+	resp := &DeleteCaseResponse{Files: files}
+	if req.ValidateOnly {
+		return resp, nil
+	}
+
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	for _, job := range s.Jobs.List(req.ID) {
+		if !job.Done() {
+			s.Jobs.Cancel(job.ID)
+		}
+	}
+	for _, name := range files {
+		if err := os.Remove(filepath.Join(s.Config.DatasetDir, name)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if err := s.Storage.DeleteCase(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditDeleteCase, req.ID, map[string]string{"files": strings.Join(files, ",")})
+	return resp, nil
+}
+
+// caseFiles lists the files in dir named "<id>.*", leaving out files that
+// belong to another case whose ID extends id with a dot.
+func caseFiles(dir, id string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var longer []string
+	for _, e := range entries {
+		other, ok := strings.CutSuffix(e.Name(), extFlac)
+		if ok && strings.HasPrefix(other, id+".") {
+			longer = append(longer, other+".")
+		}
+	}
+
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, id+".") {
+			continue
+		}
+		owned := true
+		for _, prefix := range longer {
+			if strings.HasPrefix(name, prefix) {
+				owned = false
+				break
+			}
+		}
+		if owned {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,31 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCaseFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"a.flac", "a.dg", "a.gt.v2.json", "a.report.v2.json", "a.dg.stream.json",
+		"a.b.flac", "a.b.dg",
+		"ab.flac",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := caseFiles(dir, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a.dg", "a.dg.stream.json", "a.flac", "a.gt.v2.json", "a.report.v2.json"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("caseFiles(a) mismatch (-want +got):\n%s", diff)
+	}
+}
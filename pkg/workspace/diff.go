@@ -0,0 +1,60 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"asr-eval/pkg/textdiff"
+)
+
+// Diff references accepted by DiffRequest.Against.
+const (
+	diffAgainstGT           = "gt"
+	diffAgainstAudioReality = "audio_reality"
+	diffAgainstRevised      = "revised"
+)
+
+// Diff aligns a provider's transcript against a reference text of the case.
+func (s *Service) Diff(ctx context.Context, req DiffRequest) (*DiffResponse, error) {
+	c, err := s.GetCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	hyp, ok := c.Transcripts[req.Provider]
+	if !ok {
+		return nil, fmt.Errorf("no transcript for provider %q", req.Provider)
+	}
+
+	against := req.Against
+	if against == "" {
+		against = diffAgainstGT
+	}
+	var ref string
+	switch against {
+	case diffAgainstGT, diffAgainstAudioReality:
+		if c.EvalContext == nil {
+			return nil, fmt.Errorf("case has no eval context")
+		}
+		ref = c.EvalContext.Meta.GroundTruth
+		if against == diffAgainstAudioReality {
+			ref = c.EvalContext.Meta.AudioRealityInference
+		}
+	case diffAgainstRevised:
+		if c.ReportV2 == nil {
+			return nil, fmt.Errorf("case has no evaluation report")
+		}
+		res, ok := c.ReportV2.Results[req.Provider]
+		if !ok {
+			return nil, fmt.Errorf("no evaluation for provider %q", req.Provider)
+		}
+		ref = res.RevisedTranscript
+	default:
+		return nil, fmt.Errorf("unknown diff reference: %s", against)
+	}
+
+	a, err := textdiff.Align(ref, hyp)
+	if err != nil {
+		return nil, err
+	}
+	return &DiffResponse{Provider: req.Provider, Against: against, Alignment: a}, nil
+}
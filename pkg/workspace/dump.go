@@ -0,0 +1,142 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// dumpRow is a line of Dump: one provider's result on one case, flat
+// enough for pandas.read_json(lines=True).
+type dumpRow struct {
+	CaseID            string             `json:"case_id"`
+	Provider          string             `json:"provider"`
+	GroundTruth       string             `json:"ground_truth"`
+	QuestionableGT    bool               `json:"questionable_gt"`
+	ContextHash       string             `json:"context_hash"`
+	Tags              []string           `json:"tags"`
+	Transcript        string             `json:"transcript"`
+	RevisedTranscript string             `json:"revised_transcript"`
+	Evaluated         bool               `json:"evaluated"` // The scores below are zero when false
+	Model             string             `json:"model"`
+	QScore            int                `json:"q_score"`
+	SScore            float64            `json:"s_score"`
+	PScore            float64            `json:"p_score"`
+	PhoneSub          int                `json:"phone_sub"`
+	PhoneDel          int                `json:"phone_del"`
+	PhoneIns          int                `json:"phone_ins"`
+	Checkpoints       []dumpCheckpoint   `json:"checkpoints"`
+	Summary           []string           `json:"summary"`
+	Truncation        *evalv2.Truncation `json:"truncation"`
+}
+
+// dumpCheckpoint is a checkpoint of the context with a provider's result.
+type dumpCheckpoint struct {
+	ID       string                  `json:"id"`
+	Tier     int                     `json:"tier"`
+	Weight   float64                 `json:"weight"`
+	Text     string                  `json:"text"`
+	Status   evalv2.CheckpointStatus `json:"status"` // Empty when not evaluated
+	Detected string                  `json:"detected"`
+	Reason   string                  `json:"reason"`
+	Override bool                    `json:"override"` // Status set by a reviewer
+}
+
+// Dump writes a JSON line per case and provider matching filter, sorted by
+// case and provider. Providers with a transcript but no result are
+// included with Evaluated false.
+func (s *Service) Dump(ctx context.Context, w io.Writer, filter CaseFilter) (int, error) {
+	cases, err := s.scanCasesFor(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	enabled := s.enabledProviders()
+
+	enc := json.NewEncoder(w)
+	n := 0
+	for _, sc := range cases {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if !filter.Match(sc, enabled) {
+			continue
+		}
+		c, err := s.GetCase(ctx, sc.ID)
+		if err != nil {
+			return n, err
+		}
+		if filter.Run != "" {
+			c.ReportV2 = sc.ReportV2
+		}
+		for _, row := range dumpRows(c) {
+			if err := enc.Encode(row); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+func dumpRows(c *Case) []*dumpRow {
+	providers := maps.Clone(c.Transcripts)
+	if providers == nil {
+		providers = make(map[string]string)
+	}
+	var results map[string]evalv2.EvalResult
+	if c.ReportV2 != nil {
+		results = c.ReportV2.Results
+	}
+	for p, r := range results {
+		if _, ok := providers[p]; !ok {
+			providers[p] = r.Transcript
+		}
+	}
+	base := dumpRow{CaseID: c.ID, Tags: c.Tags}
+	if base.Tags == nil {
+		base.Tags = []string{}
+	}
+	var checkpoints []evalv2.Checkpoint
+	if ec := c.EvalContext; ec != nil {
+		base.GroundTruth = ec.Meta.GroundTruth
+		base.QuestionableGT = ec.Meta.QuestionableGT
+		base.ContextHash = ec.Hash
+		checkpoints = ec.Checkpoints
+	}
+
+	var rows []*dumpRow
+	for _, p := range slices.Sorted(maps.Keys(providers)) {
+		row := base
+		row.Provider = p
+		row.Transcript = providers[p]
+		row.Checkpoints = []dumpCheckpoint{}
+		row.Summary = []string{}
+		r, ok := results[p]
+		if ok {
+			row.Evaluated = true
+			row.RevisedTranscript = r.RevisedTranscript
+			row.Model = r.Model
+			row.QScore, row.SScore, row.PScore = r.Metrics.QScore, r.Metrics.SScore, r.Metrics.PScore
+			d := r.Metrics.PhoneticDetails
+			row.PhoneSub, row.PhoneDel, row.PhoneIns = d.Sub, d.Del, d.Ins
+			if r.Summary != nil {
+				row.Summary = r.Summary
+			}
+			row.Truncation = r.Truncation
+		}
+		for _, cp := range checkpoints {
+			dc := dumpCheckpoint{ID: cp.ID, Tier: cp.Tier, Weight: cp.Weight, Text: cp.TextSegment}
+			if cr, ok := r.CheckpointResults[cp.ID]; ok {
+				dc.Status, dc.Detected, dc.Reason = cr.Status, cr.Detected, cr.Reason
+				dc.Override = cr.Override != nil
+			}
+			row.Checkpoints = append(row.Checkpoints, dc)
+		}
+		rows = append(rows, &row)
+	}
+	return rows
+}
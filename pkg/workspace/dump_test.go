@@ -0,0 +1,56 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestDumpRows(t *testing.T) {
+	c := &Case{
+		ID:          "a",
+		Transcripts: map[string]string{"x": "hello", "y": "hullo"},
+		EvalContext: &evalv2.EvalContext{
+			Meta:        evalv2.ContextMeta{GroundTruth: "hello"},
+			Checkpoints: []evalv2.Checkpoint{{ID: "cp1", TextSegment: "hello", Tier: 1, Weight: 1}},
+		},
+		ReportV2: &evalv2.EvalReport{Results: map[string]evalv2.EvalResult{
+			"x": {
+				Transcript:        "hello",
+				RevisedTranscript: "hello",
+				Metrics:           evalv2.EvalMetrics{QScore: 90, SScore: 0.9, PScore: 1, PhoneticDetails: evalv2.PhoneticDetails{Sub: 1}},
+				CheckpointResults: map[string]evalv2.CheckpointResult{
+					"cp1": {Status: evalv2.StatusPass, Detected: "hello", Override: &evalv2.CheckpointOverride{}},
+				},
+			},
+		}},
+	}
+	want := []*dumpRow{{
+		CaseID:            "a",
+		Provider:          "x",
+		GroundTruth:       "hello",
+		Tags:              []string{},
+		Transcript:        "hello",
+		RevisedTranscript: "hello",
+		Evaluated:         true,
+		QScore:            90,
+		SScore:            0.9,
+		PScore:            1,
+		PhoneSub:          1,
+		Checkpoints:       []dumpCheckpoint{{ID: "cp1", Tier: 1, Weight: 1, Text: "hello", Status: evalv2.StatusPass, Detected: "hello", Override: true}},
+		Summary:           []string{},
+	}, {
+		CaseID:      "a",
+		Provider:    "y",
+		GroundTruth: "hello",
+		Tags:        []string{},
+		Transcript:  "hullo",
+		Checkpoints: []dumpCheckpoint{{ID: "cp1", Tier: 1, Weight: 1, Text: "hello"}},
+		Summary:     []string{},
+	}}
+	if diff := cmp.Diff(want, dumpRows(c)); diff != "" {
+		t.Errorf("dumpRows mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,292 @@
+package workspace
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+)
+
+// fingerprintsDirName holds the audio fingerprints of the cases, one
+// "<id>.json" file each, computed as needed and recomputed when the audio
+// changes.
+const fingerprintsDirName = ".fingerprints"
+
+// Duplicate detection looks up sub-fingerprints by each of their 16-bit
+// halves, which a re-encoded recording keeps far more often than all 32
+// bits, and votes for the offset between two recordings. A pair with
+// minDuplicateVotes at one offset is compared in full there. Halves more
+// common than maxDuplicatePostings, such as those of hum and tones, carry
+// little evidence and are skipped.
+const (
+	minDuplicateVotes    = 3
+	maxDuplicatePostings = 1000
+
+	// defaultMinSimilarity is the share of fingerprint bits duplicates
+	// agree on; unrelated recordings agree on about half.
+	defaultMinSimilarity = 0.8
+
+	// minDuplicateCoverage is the share of the shorter recording's voiced
+	// audio the two must share, so a common prompt or jingle does not make
+	// calls duplicates.
+	minDuplicateCoverage = 0.8
+)
+
+// DuplicatesRequest selects what FindDuplicates reports.
+type DuplicatesRequest struct {
+	IDs           []string // Only clusters with one of these cases; empty reports all
+	MinSimilarity float64  // Share of fingerprint bits duplicates agree on; 0 means 0.8
+}
+
+// DuplicatesResponse lists the clusters of recordings found to be the same.
+type DuplicatesResponse struct {
+	Clusters []*DuplicateCluster `json:"clusters"`
+	Failed   []string            `json:"failed"` // Cases whose audio could not be fingerprinted, with the reason
+}
+
+// DuplicateCluster is a set of cases whose recordings are duplicates of
+// each other, directly or through another case of the cluster.
+type DuplicateCluster struct {
+	IDs   []string        `json:"ids"` // Sorted
+	Pairs []DuplicatePair `json:"pairs"`
+}
+
+// DuplicatePair is two cases found to be the same recording.
+type DuplicatePair struct {
+	A          string  `json:"a"`
+	B          string  `json:"b"`
+	Similarity float64 `json:"similarity"` // Share of fingerprint bits they agree on
+	OffsetMS   int64   `json:"offset_ms"`  // Where B starts in A; negative if before
+}
+
+// FindDuplicates fingerprints the audio of every case and reports the
+// clusters of recordings that are the same, even when re-encoded, at
+// another level or cut differently, so the corpus and the leaderboard are
+// not skewed by repeated clips.
+func (s *Service) FindDuplicates(ctx context.Context, req DuplicatesRequest) (*DuplicatesResponse, error) {
+	minSimilarity := req.MinSimilarity
+	if minSimilarity == 0 {
+		minSimilarity = defaultMinSimilarity
+	}
+	ids, err := s.Storage.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fps, failed := s.fingerprints(ctx, ids)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	parent := make([]int, len(ids))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	matches := matchFingerprints(fps, minSimilarity)
+	for _, m := range matches {
+		parent[find(m.b)] = find(m.a)
+	}
+
+	byRoot := make(map[int]*DuplicateCluster)
+	resp := &DuplicatesResponse{Clusters: []*DuplicateCluster{}, Failed: failed}
+	for _, m := range matches {
+		root := find(m.a)
+		c := byRoot[root]
+		if c == nil {
+			c = &DuplicateCluster{}
+			byRoot[root] = c
+			resp.Clusters = append(resp.Clusters, c)
+		}
+		c.Pairs = append(c.Pairs, DuplicatePair{
+			A:          ids[m.a],
+			B:          ids[m.b],
+			Similarity: m.similarity,
+			OffsetMS:   int64(m.offset) * audio.FingerprintHopMS,
+		})
+		for _, id := range []string{ids[m.a], ids[m.b]} {
+			if !slices.Contains(c.IDs, id) {
+				c.IDs = append(c.IDs, id)
+			}
+		}
+	}
+	resp.Clusters = slices.DeleteFunc(resp.Clusters, func(c *DuplicateCluster) bool {
+		return len(req.IDs) > 0 && !slices.ContainsFunc(c.IDs, func(id string) bool { return slices.Contains(req.IDs, id) })
+	})
+	for _, c := range resp.Clusters {
+		slices.Sort(c.IDs)
+	}
+	slices.SortFunc(resp.Clusters, func(a, b *DuplicateCluster) int { return strings.Compare(a.IDs[0], b.IDs[0]) })
+	return resp, nil
+}
+
+// fingerprintMatch is a pair of fingerprints, a before b, found to be the
+// same recording, b starting at a[offset].
+type fingerprintMatch struct {
+	a, b       int
+	offset     int
+	similarity float64
+}
+
+// matchFingerprints returns the pairs of fps that agree on at least
+// minSimilarity of their bits over most of the shorter one.
+func matchFingerprints(fps []audio.Fingerprint, minSimilarity float64) []fingerprintMatch {
+	type posting struct{ fp, pos int32 }
+	index := make([][]posting, 1<<17)
+	keys := func(v uint32) [2]uint32 { return [2]uint32{v & 0xffff, 1<<16 | v>>16} }
+
+	var out []fingerprintMatch
+	for b, fb := range fps {
+		// Votes for the offsets of b in each earlier fingerprint.
+		votes := make(map[[2]int]int)
+		for pos, v := range fb {
+			if v == 0 {
+				continue
+			}
+			for _, k := range keys(v) {
+				if len(index[k]) > maxDuplicatePostings {
+					continue
+				}
+				for _, p := range index[k] {
+					votes[[2]int{int(p.fp), int(p.pos) - pos}]++
+				}
+			}
+		}
+		best := make(map[int][2]int) // a -> offset, votes
+		for k, n := range votes {
+			a, offset := k[0], k[1]
+			// Neighboring offsets count too: cuts rarely fall on the hop.
+			n += votes[[2]int{a, offset - 1}] + votes[[2]int{a, offset + 1}]
+			if cur, ok := best[a]; n >= minDuplicateVotes && (!ok || n > cur[1] || n == cur[1] && offset < cur[0]) {
+				best[a] = [2]int{offset, n}
+			}
+		}
+		for a, ov := range best {
+			fa := fps[a]
+			m := fingerprintMatch{a: a, b: b}
+			var frames int
+			for offset := ov[0] - 1; offset <= ov[0]+1; offset++ {
+				if sim, n := audio.Compare(fa, fb, offset); sim > m.similarity {
+					m.similarity, m.offset, frames = sim, offset, n
+				}
+			}
+			if m.similarity >= minSimilarity && float64(frames) >= minDuplicateCoverage*float64(min(fa.Voiced(), fb.Voiced())) {
+				out = append(out, m)
+			}
+		}
+
+		for pos, v := range fb {
+			if v == 0 {
+				continue
+			}
+			for _, k := range keys(v) {
+				if len(index[k]) <= maxDuplicatePostings {
+					index[k] = append(index[k], posting{int32(b), int32(pos)})
+				}
+			}
+		}
+	}
+	slices.SortFunc(out, func(x, y fingerprintMatch) int {
+		if x.a != y.a {
+			return x.a - y.a
+		}
+		return x.b - y.b
+	})
+	return out
+}
+
+// fingerprints returns the fingerprints of the audio of cases ids, in
+// parallel, and the cases that failed with the reason. A failed case has
+// an empty fingerprint, which matches nothing.
+func (s *Service) fingerprints(ctx context.Context, ids []string) ([]audio.Fingerprint, []string) {
+	fps := make([]audio.Fingerprint, len(ids))
+	errs := make([]error, len(ids))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fps[i], errs[i] = s.fingerprint(ids[i])
+			}
+		}()
+	}
+	for i := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	failed := []string{}
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", ids[i], err))
+		}
+	}
+	return fps, failed
+}
+
+// fingerprintFile is the cached fingerprint of a case's audio.
+type fingerprintFile struct {
+	Audio       fileStamp `json:"audio"`
+	Fingerprint []byte    `json:"fingerprint"` // Little-endian sub-fingerprints
+}
+
+// fingerprint returns the fingerprint of case id's audio, from the cache
+// while the audio is unchanged.
+func (s *Service) fingerprint(id string) (audio.Fingerprint, error) {
+	audioPath := filepath.Join(s.Config.DatasetDir, id+extFlac)
+	fi, err := os.Stat(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	stamp := stampOf(fi)
+	cachePath := filepath.Join(s.Config.DatasetDir, fingerprintsDirName, id+extJSON)
+	var cached fingerprintFile
+	if b, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(b, &cached) == nil && cached.Audio == stamp {
+		fp := make(audio.Fingerprint, len(cached.Fingerprint)/4)
+		for i := range fp {
+			fp[i] = binary.LittleEndian.Uint32(cached.Fingerprint[4*i:])
+		}
+		return fp, nil
+	}
+
+	pcm, err := audio.DecodeFile(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	fp := pcm.Fingerprint()
+	cached = fingerprintFile{Audio: stamp, Fingerprint: make([]byte, 0, 4*len(fp))}
+	for _, v := range fp {
+		cached.Fingerprint = binary.LittleEndian.AppendUint32(cached.Fingerprint, v)
+	}
+	b, err := json.Marshal(cached)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return nil, err
+	}
+	if err := atomicfile.WriteFile(cachePath, b); err != nil {
+		return nil, err
+	}
+	return fp, nil
+}
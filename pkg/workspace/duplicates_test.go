@@ -0,0 +1,110 @@
+package workspace
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/audio"
+)
+
+// notes returns seconds of random notes, a new one every 125 ms.
+func notes(seed int64, seconds float64) []int16 {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([]int16, int(seconds*16000))
+	var freq float64
+	for i := range samples {
+		if i%2000 == 0 {
+			freq = 150 + 600*r.Float64()
+		}
+		t := float64(i) / 16000
+		samples[i] = int16(4000 * (math.Sin(2*math.Pi*freq*t) + 0.5*math.Sin(4*math.Pi*freq*t)))
+	}
+	return samples
+}
+
+func TestFindDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(id string, p *audio.PCM) {
+		if err := os.WriteFile(filepath.Join(dir, id+extFlac), p.WAV(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &audio.PCM{SampleRate: 16000, Channels: 1, Samples: notes(1, 8)}
+	write("a", a)
+	// a again, quieter at 8 kHz.
+	b := a.Resample(8000)
+	for i := range b.Samples {
+		b.Samples[i] /= 2
+	}
+	write("b", b)
+	// a from 2.5 s on.
+	write("c", &audio.PCM{SampleRate: 16000, Channels: 1, Samples: a.Samples[40000:]})
+	d := &audio.PCM{SampleRate: 16000, Channels: 1, Samples: notes(2, 8)}
+	write("d", d)
+	// The first 2 s of d, then something else: a shared prompt.
+	write("e", &audio.PCM{SampleRate: 16000, Channels: 1, Samples: append(append([]int16{}, d.Samples[:32000]...), notes(3, 6)...)})
+	if err := os.WriteFile(filepath.Join(dir, "f.flac"), []byte("not audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	resp, err := s.FindDuplicates(ctx, DuplicatesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &DuplicatesResponse{
+		Clusters: []*DuplicateCluster{{
+			IDs: []string{"a", "b", "c"},
+			Pairs: []DuplicatePair{
+				{A: "a", B: "b", OffsetMS: 0},
+				{A: "a", B: "c", OffsetMS: 2496},
+				{A: "b", B: "c", OffsetMS: 2496},
+			},
+		}},
+	}
+	for _, c := range resp.Clusters {
+		for i, p := range c.Pairs {
+			if p.Similarity < 0.8 {
+				t.Errorf("%s = %s similarity %.2f, want at least 0.8", p.A, p.B, p.Similarity)
+			}
+			c.Pairs[i].Similarity = 0
+		}
+	}
+	if len(resp.Failed) != 1 {
+		t.Errorf("Failed = %q, want f", resp.Failed)
+	}
+	resp.Failed = nil
+	if diff := cmp.Diff(want, resp); diff != "" {
+		t.Errorf("FindDuplicates() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Cached fingerprints are used, and only the clusters asked for kept.
+	if resp, err = s.FindDuplicates(ctx, DuplicatesRequest{IDs: []string{"d", "e"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Clusters) != 0 {
+		t.Errorf("FindDuplicates(d, e) = %v, want no clusters", resp.Clusters)
+	}
+	if _, err := os.Stat(filepath.Join(dir, fingerprintsDirName, "a.json")); err != nil {
+		t.Errorf("fingerprint not cached: %v", err)
+	}
+
+	// The fingerprints of deleted cases are garbage.
+	if err := os.Remove(filepath.Join(dir, "a.flac")); err != nil {
+		t.Fatal(err)
+	}
+	garbage, err := s.FindGarbage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]GCFile{{Name: ".fingerprints/a.json", Reason: GCNoAudio}}, garbage); diff != "" {
+		t.Errorf("FindGarbage() mismatch (-want +got):\n%s", diff)
+	}
+}
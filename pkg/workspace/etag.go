@@ -0,0 +1,85 @@
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// datasetVersion hashes the name, size and modification time of every
+// entry in the dataset directory, and in the directory of run when set.
+// Any write to a case, sidecar or dataset.yaml changes it, and computing it
+// stats files rather than reading them. The manifest and lock files are
+// left out: reads write them too.
+func (s *Service) datasetVersion(run string) (string, error) {
+	h := sha256.New()
+	if err := hashDir(h, s.Config.DatasetDir); err != nil {
+		return "", err
+	}
+	if run != "" {
+		if !runIDPattern.MatchString(run) {
+			return "", fmt.Errorf("invalid run ID: %q", run)
+		}
+		if err := hashDir(h, s.runDir(run)); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+func hashDir(h hash.Hash, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var buf [16]byte
+	for _, e := range entries {
+		if e.Name() == manifestFileName || e.Name() == locksDirName || e.Name() == fingerprintsDirName || e.Name() == changeJournalName {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // Removed since ReadDir
+		}
+		h.Write([]byte(e.Name()))
+		binary.LittleEndian.PutUint64(buf[:8], uint64(info.Size()))
+		binary.LittleEndian.PutUint64(buf[8:], uint64(info.ModTime().UnixNano()))
+		h.Write(buf[:])
+	}
+	return nil
+}
+
+// checkETag sets an ETag derived from the dataset version and the query,
+// and answers 304 Not Modified when the client already has it. It returns
+// true when the response has been written.
+func (s *Service) checkETag(w http.ResponseWriter, r *http.Request) bool {
+	version, err := s.datasetVersion(r.URL.Query().Get("run"))
+	if err != nil {
+		return false // Let the handler report it
+	}
+	sum := sha256.Sum256([]byte(version + "?" + r.URL.RawQuery))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// etagMatch reports whether an If-None-Match header lists etag.
+func etagMatch(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == etag || v == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package workspace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListCasesETag(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, mtime time.Time) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t0 := time.Unix(1700000000, 0)
+	write("a.flac", "", t0)
+	write("a.dg", "hello", t0)
+
+	mux := http.NewServeMux()
+	(&Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}).RegisterRoutes(mux)
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, r)
+		return rec
+	}
+
+	first := get("/api/cases", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("GET /api/cases = %d with ETag %q, want 200 with an ETag", first.Code, etag)
+	}
+	if got := get("/api/cases", etag).Code; got != http.StatusNotModified {
+		t.Errorf("unchanged = %d, want 304", got)
+	}
+	if got := get("/api/cases?sort=-id", etag).Code; got != http.StatusOK {
+		t.Errorf("other query = %d, want 200", got)
+	}
+	write("a.dg", "hello", t0.Add(time.Second))
+	if got := get("/api/cases", etag).Code; got != http.StatusOK {
+		t.Errorf("after a write = %d, want 200", got)
+	}
+}
@@ -0,0 +1,174 @@
+package workspace
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Event types pushed by GET /api/events.
+const (
+	EventCase  = "case"  // A case file was written or removed
+	EventJob   = "job"   // A job was queued, started or finished
+	EventReset = "reset" // Changes were missed; reload everything
+)
+
+// eventsPollInterval is how often the dataset watch is checked while
+// someone is listening.
+const eventsPollInterval = 500 * time.Millisecond
+
+// eventsPingInterval keeps idle event streams open through proxies.
+const eventsPingInterval = 30 * time.Second
+
+// Event is a change in the dataset. Events only say what changed; clients
+// fetch the new state.
+type Event struct {
+	Type   string `json:"type"`
+	CaseID string `json:"case_id,omitempty"`
+	File   string `json:"file,omitempty"` // For EventCase: audio, report, context, tags or review
+	Job    *Job   `json:"job,omitempty"`  // For EventJob, without its result
+}
+
+// eventFiles names the summary files in events.
+var eventFiles = map[string]string{
+	extFlac:     "audio",
+	extReportV2: "report",
+	extGTV2:     "context",
+	extTags:     "tags",
+	extReview:   "review",
+}
+
+// eventHub fans events out to subscribers. Slow subscribers miss events
+// rather than hold up the dataset. The zero value is ready to use.
+type eventHub struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool
+}
+
+// subscribe returns a channel of events, closed when the hub is, and a
+// function to stop receiving them.
+func (h *eventHub) subscribe() (<-chan Event, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan Event, 64)
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs == nil {
+		h.subs = make(map[chan Event]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *eventHub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// active reports whether anyone is subscribed.
+func (h *eventHub) active() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs) > 0
+}
+
+// close ends every subscription.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		close(ch)
+	}
+	h.subs = nil
+}
+
+// publishJob reports a job record change.
+func (s *Service) publishJob(job *Job) {
+	j := *job
+	j.Result = nil // Clients fetch it from GET /api/jobs/{id}
+	s.events.publish(Event{Type: EventJob, CaseID: j.CaseID, Job: &j})
+}
+
+// watchEvents checks the dataset watch for changes while anyone listens,
+// so writes by other processes are pushed without waiting for a read.
+func (s *Service) watchEvents(x *caseIndex, done <-chan struct{}) {
+	t := time.NewTicker(eventsPollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if !s.events.active() {
+				continue
+			}
+			if err := x.sync(); err != nil {
+				return // scanCases reports it and drops the index
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Subscribe returns the events of the dataset, as GET /api/events streams
+// them, until cancel is called. Case events need the dataset to be
+// watched; see Watched.
+func (s *Service) Subscribe() (events <-chan Event, cancel func()) {
+	return s.events.subscribe()
+}
+
+// Watched reports whether changes to the dataset are watched, and so
+// pushed as events.
+func (s *Service) Watched() bool {
+	return s.index.Load() != nil
+}
+
+// CloseEvents ends open event streams, which would otherwise keep a
+// graceful HTTP shutdown waiting.
+func (s *Service) CloseEvents() {
+	s.events.close()
+}
+
+// handleEvents handles GET /api/events
+// It streams Event values as Server-Sent Events, each named after its
+// type, until the client disconnects.
+func (s *Service) handleEvents(w http.ResponseWriter, r *http.Request) {
+	ch, cancel := s.events.subscribe()
+	defer cancel()
+	es, err := newEventStream(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ping := time.NewTicker(eventsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			es.send(e.Type, e)
+		case <-ping.C:
+			es.send("ping", struct{}{})
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
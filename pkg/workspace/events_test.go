@@ -0,0 +1,41 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEventsFromExternalWrites(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("dataset watching needs inotify")
+	}
+	dir := t.TempDir()
+	cfg := DefaultServiceConfig()
+	cfg.DatasetDir = dir
+	s := NewService(cfg, nil)
+	defer s.Close()
+
+	ch, cancel := s.events.subscribe()
+	defer cancel()
+	if err := os.WriteFile(filepath.Join(dir, "a.b.report.v2.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-ch:
+		if diff := cmp.Diff(Event{Type: EventCase, CaseID: "a.b", File: "report"}, e); diff != "" {
+			t.Errorf("event mismatch (-want +got):\n%s", diff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+
+	s.Close()
+	if _, ok := <-ch; ok {
+		t.Error("subscription still open after Close")
+	}
+}
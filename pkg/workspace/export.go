@@ -0,0 +1,126 @@
+package workspace
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// Export formats accepted by GET /api/export.
+const (
+	exportCSV  = "csv"
+	exportXLSX = "xlsx"
+	exportHTML = "html" // A zip of static pages
+	exportHF   = "hf"   // A zip loadable with Hugging Face datasets, without audio
+)
+
+// exportTiers are the checkpoint tiers broken out in exports.
+var exportTiers = []int{1, 2, 3}
+
+// exportHeader returns the column names of an export, one row per case and
+// provider.
+func exportHeader() []string {
+	h := []string{"case_id", "provider", "tags", "q_score", "s_score", "p_score", "per_sub", "per_del", "per_ins", "token_count"}
+	for _, t := range exportTiers {
+		h = append(h,
+			fmt.Sprintf("tier%d_pass", t),
+			fmt.Sprintf("tier%d_partial", t),
+			fmt.Sprintf("tier%d_fail", t),
+		)
+	}
+	return h
+}
+
+// exportRows returns the per-case per-provider metrics of the cases matching
+// filter. Cells are strings, ints or float64s.
+func (s *Service) exportRows(ctx context.Context, filter CaseFilter) ([][]any, error) {
+	cases, err := s.scanCasesFor(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]any
+	for _, c := range cases {
+		if c.ReportV2 == nil || !filter.Match(c, s.enabledProviders()) {
+			continue
+		}
+		rows = append(rows, caseExportRows(c)...)
+	}
+	return rows, nil
+}
+
+func caseExportRows(c *Case) [][]any {
+	checkpoints := c.ReportV2.ContextSnapshot.Checkpoints
+	if len(checkpoints) == 0 && c.EvalContext != nil {
+		checkpoints = c.EvalContext.Checkpoints
+	}
+	tiers := make(map[string]int, len(checkpoints))
+	for _, cp := range checkpoints {
+		tiers[cp.ID] = cp.Tier
+	}
+
+	providers := make([]string, 0, len(c.ReportV2.Results))
+	for p := range c.ReportV2.Results {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+
+	rows := make([][]any, 0, len(providers))
+	for _, p := range providers {
+		res := c.ReportV2.Results[p]
+		m := res.Metrics
+		row := []any{
+			c.ID, p, strings.Join(c.Tags, ","),
+			m.QScore, m.SScore, m.PScore,
+			m.PhoneticDetails.Sub, m.PhoneticDetails.Del, m.PhoneticDetails.Ins,
+			reportTokenCount(c),
+		}
+		for _, t := range exportTiers {
+			counts := make(map[evalv2.CheckpointStatus]int)
+			for id, r := range res.CheckpointResults {
+				if tiers[id] == t {
+					counts[r.Status]++
+				}
+			}
+			row = append(row, counts[evalv2.StatusPass], counts[evalv2.StatusPartial], counts[evalv2.StatusFail])
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func writeExportCSV(w io.Writer, header []string, rows [][]any) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(header))
+	for _, row := range rows {
+		for i, v := range row {
+			record[i] = formatCell(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
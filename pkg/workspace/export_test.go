@@ -0,0 +1,72 @@
+package workspace
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestWriteExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	rows := [][]any{
+		{"a,b", `say "hi"`, "two\nlines", 3, 0.25},
+	}
+	if err := writeExportCSV(&buf, []string{"id", "quote", "text", "n", "f"}, rows); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,quote,text,n,f\n" + `"a,b","say ""hi""","two` + "\n" + `lines",3,0.25` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a,b", `say "hi"`, "two\nlines", "3", "0.25"}, records[1]); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCaseExportRows(t *testing.T) {
+	c := &Case{
+		ID:   "a",
+		Tags: []string{"noisy", "phone"},
+		ReportV2: &evalv2.EvalReport{
+			ContextSnapshot: evalv2.EvalContext{
+				Meta: evalv2.ContextMeta{TotalTokenCountEstimate: 12},
+				Checkpoints: []evalv2.Checkpoint{
+					{ID: "S1", Tier: 1},
+					{ID: "S2", Tier: 2},
+					{ID: "S3", Tier: 2},
+				},
+			},
+			Results: map[string]evalv2.EvalResult{
+				"y": {Metrics: evalv2.EvalMetrics{QScore: 50}},
+				"x": {
+					Metrics: evalv2.EvalMetrics{QScore: 90, SScore: 0.9, PScore: 0.8, PhoneticDetails: evalv2.PhoneticDetails{Sub: 1, Del: 2, Ins: 3}},
+					CheckpointResults: map[string]evalv2.CheckpointResult{
+						"S1": {Status: evalv2.StatusPass},
+						"S2": {Status: evalv2.StatusPartial},
+						"S3": {Status: evalv2.StatusFail},
+					},
+				},
+			},
+		},
+	}
+	got := caseExportRows(c)
+	if len(got) != 2 || got[0][1] != "x" || got[1][1] != "y" {
+		t.Fatalf("rows = %v, want x then y", got)
+	}
+	want := []any{"a", "x", "noisy,phone", 90, 0.9, 0.8, 1, 2, 3, 12, 1, 0, 0, 0, 1, 1, 0, 0, 0}
+	if diff := cmp.Diff(want, got[0]); diff != "" {
+		t.Errorf("row mismatch (-want +got):\n%s", diff)
+	}
+	if len(got[0]) != len(exportHeader()) {
+		t.Errorf("row has %d cells, header %d", len(got[0]), len(exportHeader()))
+	}
+}
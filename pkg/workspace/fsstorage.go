@@ -0,0 +1,396 @@
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/evalv2"
+)
+
+// FSStorage is the Storage of a dataset directory, one file per record:
+//
+//	[id].flac              audio; a case exists when this does
+//	[id].<provider>        transcript
+//	[id].gt.v2.json        eval context, as a reference into .contexts
+//	[id].report.v2.json    report, its context snapshot a reference too
+//	[id].tags.json         tags
+//	[id].gt.v2.<n>.json    archived contexts, likewise reports
+//	.contexts/<hash>.json  contexts by hash
+//	.audit.jsonl           audit log
+type FSStorage struct {
+	dir string
+
+	// CompressReports gzips the report files written. Both forms are read.
+	CompressReports bool
+
+	auditMu sync.Mutex
+}
+
+// NewFSStorage returns the storage of the dataset directory dir.
+func NewFSStorage(dir string) *FSStorage {
+	return &FSStorage{dir: dir}
+}
+
+func (st *FSStorage) Close() error { return nil }
+
+func (st *FSStorage) ListIDs(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), extFlac); ok && !e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (st *FSStorage) ListCases(ctx context.Context) ([]*Case, error) {
+	// Optimization: Read all directory entries once
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	filesMap := make(map[string]map[string]bool) // id -> extension -> true
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if id, ext, ok := summaryFile(e.Name()); ok {
+			if filesMap[id] == nil {
+				filesMap[id] = make(map[string]bool)
+			}
+			filesMap[id][ext] = true
+		}
+	}
+
+	var results []*Case
+	for id, exts := range filesMap {
+		if c := st.summary(id, exts); c != nil {
+			results = append(results, c)
+		}
+	}
+
+	// Sort by ID to ensure stable order
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ID < results[j].ID
+	})
+	return results, nil
+}
+
+func (st *FSStorage) GetSummary(ctx context.Context, id string) (*Case, error) {
+	c := st.summary(id, statSummary(st.dir, id))
+	if c == nil {
+		return nil, fmt.Errorf("case not found: %s: %w", id, os.ErrNotExist)
+	}
+	return c, nil
+}
+
+// summary loads the summary Case for id given which of its summary files
+// exist, or returns nil when it has no audio file.
+func (st *FSStorage) summary(id string, exts map[string]bool) *Case {
+	if !exts[extFlac] {
+		// Skip if no audio file (sanity check)
+		return nil
+	}
+
+	c := &Case{ID: id}
+
+	// Try to load GT first (Precedence)
+	if exts[extGTV2] {
+		if ctx, err := st.readContext(id); err == nil {
+			c.EvalContext = ctx
+		}
+	}
+
+	// Load Report
+	if exts[extReportV2] {
+		if report, err := st.readReport(id); err == nil {
+			c.ReportV2 = report
+			// If no GT loaded yet, use snapshot
+			if c.EvalContext == nil && report.ContextSnapshot.Hash != "" {
+				c.EvalContext = &report.ContextSnapshot
+			}
+		}
+	}
+
+	if exts[extTags] {
+		c.Tags, _ = st.readTags(id)
+	}
+	return c
+}
+
+func (st *FSStorage) GetCase(ctx context.Context, id string) (*Case, error) {
+	c := &Case{
+		ID:          id,
+		Transcripts: make(map[string]string),
+	}
+
+	files, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasPrefix(name, id+".") {
+			continue
+		}
+
+		switch {
+		case name == id+extFlac:
+			found = true
+		case name == id+extTags:
+			c.Tags, _ = st.readTags(id)
+		case name == id+extGTV2:
+			if ctx, err := st.readContext(id); err == nil {
+				c.EvalContext = ctx
+			}
+		case name == id+extReportV2:
+			if report, err := st.readReport(id); err == nil {
+				c.ReportV2 = report
+			}
+		default:
+			// Transcripts; sidecars are all JSON.
+			ext := filepath.Ext(name)
+			if ext != extJSON && ext != extFlac {
+				content, _ := os.ReadFile(filepath.Join(st.dir, name))
+				provider := strings.TrimPrefix(ext, ".")
+				c.Transcripts[provider] = string(content)
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("case not found: %s: %w", id, os.ErrNotExist)
+	}
+	// If no GT, use the report's snapshot
+	if c.EvalContext == nil && c.ReportV2 != nil && c.ReportV2.ContextSnapshot.Hash != "" {
+		c.EvalContext = &c.ReportV2.ContextSnapshot
+	}
+	return c, nil
+}
+
+// PutCase checks that the case's audio is in place; the audio file is what
+// makes a case in the directory layout.
+func (st *FSStorage) PutCase(ctx context.Context, id string) error {
+	if _, err := os.Stat(filepath.Join(st.dir, id+extFlac)); err != nil {
+		return fmt.Errorf("case not found: %s: %w", id, os.ErrNotExist)
+	}
+	return nil
+}
+
+// DeleteCase removes every file of the case, audio and sidecars included.
+func (st *FSStorage) DeleteCase(ctx context.Context, id string) error {
+	files, err := caseFiles(st.dir, id)
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		if err := os.Remove(filepath.Join(st.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (st *FSStorage) PutTranscript(ctx context.Context, id, provider, text string) error {
+	return atomicfile.WriteFile(filepath.Join(st.dir, id+"."+provider), []byte(text))
+}
+
+func (st *FSStorage) GetContext(ctx context.Context, id string) (*evalv2.EvalContext, error) {
+	return st.readContext(id)
+}
+
+// PutContext saves c in the context store and writes a reference to it.
+func (st *FSStorage) PutContext(ctx context.Context, id string, c *evalv2.EvalContext) error {
+	return st.writeContext(id+extGTV2, c)
+}
+
+func (st *FSStorage) GetReport(ctx context.Context, id string) (*evalv2.EvalReport, error) {
+	return st.readReport(id)
+}
+
+// PutReport writes r with its context snapshot as a reference to the
+// context store, gzip-compressed when CompressReports is set.
+func (st *FSStorage) PutReport(ctx context.Context, id string, r *evalv2.EvalReport) error {
+	return st.writeReport(id+extReportV2, r)
+}
+
+func (st *FSStorage) DeleteReport(ctx context.Context, id string) error {
+	if err := os.Remove(filepath.Join(st.dir, id+extReportV2)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (st *FSStorage) GetTags(ctx context.Context, id string) ([]string, error) {
+	return st.readTags(id)
+}
+
+// PutTags stores tags for id, removing the sidecar when there are none.
+func (st *FSStorage) PutTags(ctx context.Context, id string, tags []string) error {
+	filename := filepath.Join(st.dir, id+extTags)
+	if len(tags) == 0 {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	bytes, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, bytes)
+}
+
+func (st *FSStorage) AppendAudit(ctx context.Context, e *AuditEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	st.auditMu.Lock()
+	defer st.auditMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(st.dir, auditFileName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+func (st *FSStorage) ListAudit(ctx context.Context, req ListAuditRequest) ([]*AuditEntry, error) {
+	f, err := os.Open(filepath.Join(st.dir, auditFileName))
+	if os.IsNotExist(err) {
+		return []*AuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []*AuditEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		if req.CaseID != "" && e.CaseID != req.CaseID {
+			continue
+		}
+		if req.User != "" && e.User != req.User {
+			continue
+		}
+		if req.Action != "" && e.Action != req.Action {
+			continue
+		}
+		out = append(out, &e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	if req.Limit > 0 && len(out) > req.Limit {
+		out = out[:req.Limit]
+	}
+	return out, nil
+}
+
+func (st *FSStorage) readContext(id string) (*evalv2.EvalContext, error) {
+	return st.loadContext(id + extGTV2)
+}
+
+// loadContext reads the context file name, current or archived.
+func (st *FSStorage) loadContext(name string) (*evalv2.EvalContext, error) {
+	content, err := os.ReadFile(filepath.Join(st.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	c, err := evalv2.UnmarshalContext(content)
+	if err != nil {
+		return nil, err
+	}
+	return resolveContext(st.dir, c)
+}
+
+func (st *FSStorage) readReport(id string) (*evalv2.EvalReport, error) {
+	return st.loadReport(id + extReportV2)
+}
+
+// loadReport reads the report file name, current or archived.
+func (st *FSStorage) loadReport(name string) (*evalv2.EvalReport, error) {
+	content, err := readReportFile(filepath.Join(st.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	r, err := evalv2.UnmarshalReport(content)
+	if err != nil {
+		return nil, err
+	}
+	resolveReportContext(st.dir, r)
+	return r, nil
+}
+
+func (st *FSStorage) readTags(id string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(st.dir, id+extTags))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	if err := json.Unmarshal(content, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// writeContext writes c to the file name as PutContext does.
+func (st *FSStorage) writeContext(name string, c *evalv2.EvalContext) error {
+	ref, err := storeContext(st.dir, c)
+	if err != nil {
+		return err
+	}
+	return st.writeJSON(name, ref)
+}
+
+// writeReport writes r to the file name as PutReport does.
+func (st *FSStorage) writeReport(name string, r *evalv2.EvalReport) error {
+	r, err := storeReportContext(st.dir, r)
+	if err != nil {
+		return err
+	}
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeReportFile(filepath.Join(st.dir, name), bytes, st.CompressReports)
+}
+
+func (st *FSStorage) writeJSON(name string, v any) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filepath.Join(st.dir, name), bytes)
+}
@@ -0,0 +1,148 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestFSStorage(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.flac", "b.flac"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var st Storage = NewFSStorage(dir)
+	ctx := context.Background()
+
+	if err := st.PutCase(ctx, "c"); err == nil {
+		t.Error("PutCase without audio succeeded, want error")
+	}
+	testStorage(t, st)
+	if _, err := os.Stat(filepath.Join(dir, "a"+extTags)); !os.IsNotExist(err) {
+		t.Errorf("tags file left after clearing tags: %v", err)
+	}
+}
+
+// testStorage checks the behaviour every Storage shares. Cases a and b must
+// exist and be empty.
+func testStorage(t *testing.T, st Storage) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := st.GetReport(ctx, "a"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("GetReport of a case without one = %v, want os.ErrNotExist", err)
+	}
+
+	if err := st.PutTranscript(ctx, "a", "x", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.PutReport(ctx, "a", &evalv2.EvalReport{ContextSnapshot: evalv2.EvalContext{Hash: "h"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.PutTags(ctx, "a", []string{"noisy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := st.GetCase(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"x": "hello"}, c.Transcripts); diff != "" {
+		t.Errorf("transcripts mismatch (-want +got):\n%s", diff)
+	}
+	if c.EvalContext == nil || c.EvalContext.Hash != "h" {
+		t.Errorf("context = %+v, want the report's snapshot", c.EvalContext)
+	}
+
+	cases, err := st.ListCases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 2 || cases[0].ID != "a" || cases[1].ID != "b" || cases[0].Transcripts != nil {
+		t.Fatalf("ListCases = %+v, want summaries of a and b", cases)
+	}
+	if diff := cmp.Diff([]string{"noisy"}, cases[0].Tags); diff != "" {
+		t.Errorf("tags mismatch (-want +got):\n%s", diff)
+	}
+
+	testHistory(t, st)
+
+	if err := st.PutTags(ctx, "a", nil); err != nil {
+		t.Fatal(err)
+	}
+	if tags, err := st.GetTags(ctx, "a"); err != nil || tags != nil {
+		t.Errorf("GetTags after clearing = %v, %v, want nil", tags, err)
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, e := range []*AuditEntry{
+		{Time: now, User: "ann", Action: AuditUpdateTags, CaseID: "a"},
+		{Time: now.Add(time.Second), User: "bob", Action: AuditUpdateTags, CaseID: "b"},
+		{Time: now.Add(2 * time.Second), User: "bob", Action: AuditUpdateTags, CaseID: "a", Details: map[string]string{"k": "v"}},
+	} {
+		if err := st.AppendAudit(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := st.ListAudit(ctx, ListAuditRequest{CaseID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*AuditEntry{
+		{Time: now.Add(2 * time.Second), User: "bob", Action: AuditUpdateTags, CaseID: "a", Details: map[string]string{"k": "v"}},
+		{Time: now, User: "ann", Action: AuditUpdateTags, CaseID: "a"},
+	}
+	if diff := cmp.Diff(want, entries); diff != "" {
+		t.Errorf("ListAudit mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// testHistory checks archiving the report of case a, which must have one
+// against context h and no history.
+func testHistory(t *testing.T, st Storage) {
+	t.Helper()
+	ctx := context.Background()
+
+	if n, err := st.ArchiveContext(ctx, "a"); err != nil || n != 0 {
+		t.Errorf("ArchiveContext without a context = %d, %v, want 0", n, err)
+	}
+	if n, err := st.ArchiveReport(ctx, "a"); err != nil || n != 1 {
+		t.Fatalf("ArchiveReport = %d, %v, want 1", n, err)
+	}
+	if _, err := st.GetReport(ctx, "a"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("GetReport after archiving = %v, want os.ErrNotExist", err)
+	}
+	if r, err := st.GetReportVersion(ctx, "a", 1); err != nil || r.ContextSnapshot.Hash != "h" {
+		t.Errorf("GetReportVersion(1) = %+v, %v, want the report against h", r, err)
+	}
+	if _, err := st.GetReportVersion(ctx, "a", 2); err == nil {
+		t.Error("GetReportVersion(2) succeeded, want error")
+	}
+
+	if err := st.PutReport(ctx, "a", &evalv2.EvalReport{ContextSnapshot: evalv2.EvalContext{Hash: "h2"}}); err != nil {
+		t.Fatal(err)
+	}
+	versions, err := st.ListReportVersions(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, v := range versions {
+		if v.UpdateTime.IsZero() {
+			t.Errorf("version %d has no update time", v.N)
+		}
+		got = append(got, v.N)
+	}
+	if diff := cmp.Diff([]int{1, 0}, got); diff != "" {
+		t.Errorf("ListReportVersions mismatch (-want +got):\n%s", diff)
+	}
+}
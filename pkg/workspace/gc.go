@@ -0,0 +1,220 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reasons a dataset file is garbage.
+const (
+	GCBackup       = "backup file"
+	GCTempFile     = "leftover temp file"
+	GCNoAudio      = "case audio deleted"
+	GCNoTranscript = "provider transcript removed"
+	GCSupersededV1 = "superseded by V2"
+	GCUnreferenced = "context referenced by no file"
+)
+
+// gcMinAge spares younger temp files and context store entries: the first
+// may still be written, and the second about to be referred to.
+const gcMinAge = time.Hour
+
+// caseSidecarPattern matches the per-case files that mean nothing without
+// the case's audio: contexts, reports and their history, case bundles,
+// sidecars, and V1
+// files "[id].gt.json" and "[id].[model].report.json".
+var caseSidecarPattern = regexp.MustCompile(`.(\.(gt|report)\.v2(\.[0-9]+)?\.json|\.case\.json|\.tags\.json|\.review\.json|\.comments\.json|\.corrections\.json|\.gt\.json|\.[a-z0-9_.-]+\.report\.json)$`)
+
+// GCFile is a dataset file GC would remove.
+type GCFile struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// FindGarbage lists the stale files of the dataset directory, sorted by
+// name: backup and leftover temp files, the records of cases whose audio
+// is gone, streams and chunk and trim sidecars of providers without a
+// transcript any more, V1 contexts and reports of cases that have V2 ones,
+// context store entries no context or report refers to, and the cached
+// fingerprints of deleted cases.
+func (s *Service) FindGarbage(ctx context.Context) ([]GCFile, error) {
+	entries, err := os.ReadDir(s.Config.DatasetDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	ids := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		names[e.Name()] = true
+		if id, ok := strings.CutSuffix(e.Name(), extFlac); ok {
+			ids[id] = true
+		}
+	}
+
+	var out []GCFile
+	add := func(name, reason string) { out = append(out, GCFile{Name: name, Reason: reason}) }
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(name, ".bak"):
+			add(name, GCBackup)
+		case strings.HasPrefix(name, ".tmp-"):
+			if fi, err := e.Info(); err == nil && time.Since(fi.ModTime()) > gcMinAge {
+				add(name, GCTempFile)
+			}
+		case strings.HasSuffix(name, extStream) || strings.HasSuffix(name, extChunks) || strings.HasSuffix(name, extTrim):
+			id, provider, ok := transcriptSidecarOwner(name, ids)
+			switch {
+			case !ok:
+				add(name, GCNoAudio)
+			case !names[id+"."+provider] && !bundledTranscript(filepath.Join(s.Config.DatasetDir, id+extCase), provider):
+				add(name, GCNoTranscript)
+			}
+		default:
+			if !caseSidecarPattern.MatchString(name) {
+				continue
+			}
+			id, ok := owningCase(name, ids)
+			switch {
+			case !ok:
+				add(name, GCNoAudio)
+			case name == id+".gt.json" && names[id+extGTV2]:
+				add(name, GCSupersededV1)
+			case strings.HasSuffix(name, ".report.json") && names[id+extReportV2]:
+				add(name, GCSupersededV1)
+			}
+		}
+	}
+	fingerprints, err := os.ReadDir(filepath.Join(s.Config.DatasetDir, fingerprintsDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range fingerprints {
+		if id, ok := strings.CutSuffix(e.Name(), extJSON); !ok || !ids[id] {
+			add(fingerprintsDirName+"/"+e.Name(), GCNoAudio)
+		}
+	}
+	garbage := make(map[string]bool, len(out))
+	for _, f := range out {
+		garbage[f.Name] = true
+	}
+	unref, err := s.unreferencedContexts(garbage)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range unref {
+		add(name, GCUnreferenced)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// unreferencedContexts lists the context store entries, as slash-separated
+// paths, that no context or report file refers to, leaving out those of the
+// files GC removes anyway.
+func (s *Service) unreferencedContexts(garbage map[string]bool) ([]string, error) {
+	blobs, err := os.ReadDir(filepath.Join(s.Config.DatasetDir, contextsDirName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := s.schemaFileDirs()
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || !refersToContexts(e.Name()) || dir == s.Config.DatasetDir && garbage[e.Name()] {
+				continue
+			}
+			for _, hash := range fileContextRefs(filepath.Join(dir, e.Name())) {
+				refs[hash] = true
+			}
+		}
+	}
+	var out []string
+	for _, e := range blobs {
+		hash, ok := strings.CutSuffix(e.Name(), extJSON)
+		if !ok || !contextBlobPattern.MatchString(e.Name()) || refs[hash] {
+			continue
+		}
+		if fi, err := e.Info(); err == nil && time.Since(fi.ModTime()) > gcMinAge {
+			out = append(out, contextsDirName+"/"+e.Name())
+		}
+	}
+	return out, nil
+}
+
+// bundledTranscript reports whether the case bundle at path holds a
+// transcript of provider.
+func bundledTranscript(path, provider string) bool {
+	b, err := readCaseBundle(path)
+	if err != nil {
+		return true // Keep the stream rather than guess
+	}
+	_, ok := b.Transcripts[provider]
+	return ok
+}
+
+// transcriptSidecarOwner splits "[id].<provider>.stream.json", or a chunk
+// or trim sidecar, for an existing case.
+func transcriptSidecarOwner(name string, ids map[string]bool) (id, provider string, ok bool) {
+	rest := name
+	for _, ext := range []string{extStream, extChunks, extTrim} {
+		rest = strings.TrimSuffix(rest, ext)
+	}
+	i := strings.LastIndexByte(rest, '.')
+	if i <= 0 || !ids[rest[:i]] {
+		return "", "", false
+	}
+	return rest[:i], rest[i+1:], true
+}
+
+// RemoveGarbage removes the files of FindGarbage, returning how many were
+// removed, and journals their removal. Files already gone are not an error.
+func (s *Service) RemoveGarbage(ctx context.Context, files []GCFile) (int, error) {
+	n := 0
+	for _, f := range files {
+		name, ok := strings.CutPrefix(f.Name, contextsDirName+"/")
+		if !ok {
+			name = strings.TrimPrefix(f.Name, fingerprintsDirName+"/")
+		}
+		if strings.ContainsAny(name, `/\`) || name == "" || name == "." || name == ".." {
+			return n, fmt.Errorf("invalid file name: %q", f.Name)
+		}
+		err := os.Remove(filepath.Join(s.Config.DatasetDir, filepath.FromSlash(f.Name)))
+		if err != nil && !os.IsNotExist(err) {
+			return n, err
+		}
+		if err == nil {
+			n++
+		}
+	}
+	removed := make(map[string]bool, len(files))
+	for _, f := range files {
+		removed[f.Name] = true
+	}
+	if err := s.journalPaths(ctx, nil, func(rel string) bool { return removed[rel] }); err != nil {
+		return n, err
+	}
+	return n, nil
+}
@@ -0,0 +1,43 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindGarbage(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"a.flac", "a.dg", "a.gt.v2.json", "a.report.v2.json", "a.dg.stream.json",
+		"a.qwen.stream.json", "a.dg.chunks.json", "a.qwen.chunks.json", "a.gt.json", "a.gemini-2.5-flash.report.json", "a.tags.json.bak",
+		"b.flac", "b.gt.json", "b.gemini-2.5-flash.report.json",
+		"c.report.v2.json", "c.report.v2.2.json", "c.dg.stream.json",
+		"dataset.yaml",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	got, err := s.FindGarbage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GCFile{
+		{"a.gemini-2.5-flash.report.json", GCSupersededV1},
+		{"a.gt.json", GCSupersededV1},
+		{"a.qwen.chunks.json", GCNoTranscript},
+		{"a.qwen.stream.json", GCNoTranscript},
+		{"a.tags.json.bak", GCBackup},
+		{"c.dg.stream.json", GCNoAudio},
+		{"c.report.v2.2.json", GCNoAudio},
+		{"c.report.v2.json", GCNoAudio},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FindGarbage mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,47 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// SetGroundTruthRequest replaces a case's ground truth.
+type SetGroundTruthRequest struct {
+	ID          string
+	GroundTruth string
+}
+
+// GroundTruth returns a case's ground truth, empty when it has no context.
+func (s *Service) GroundTruth(ctx context.Context, id string) (string, error) {
+	c, err := s.GetCase(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if c.EvalContext == nil {
+		return "", nil
+	}
+	return c.EvalContext.Meta.GroundTruth, nil
+}
+
+// SetGroundTruth replaces a case's ground truth and reports whether it
+// changed. The rest of the context was generated from the old ground
+// truth, so the new context holds the ground truth alone until it is
+// generated again; the old one stays in the context history, and the
+// report is archived as by UpdateContext. Nothing is written when the
+// ground truth is unchanged.
+func (s *Service) SetGroundTruth(ctx context.Context, req SetGroundTruthRequest) (bool, error) {
+	gt, err := s.GroundTruth(ctx, req.ID)
+	if err != nil {
+		return false, err
+	}
+	if gt == req.GroundTruth {
+		return false, nil
+	}
+	ec := &evalv2.EvalContext{Meta: evalv2.ContextMeta{GroundTruth: req.GroundTruth}}
+	if _, err := s.UpdateContext(ctx, UpdateContextRequest{ID: req.ID, EvalContext: ec}); err != nil {
+		return false, fmt.Errorf("%s: %w", req.ID, err)
+	}
+	return true, nil
+}
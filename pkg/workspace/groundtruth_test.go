@@ -0,0 +1,47 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestSetGroundTruth(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	ec := &evalv2.EvalContext{Checkpoints: []evalv2.Checkpoint{{ID: "C1", TextSegment: "old"}}}
+	ec.Meta.GroundTruth = "old"
+	if err := s.Storage.PutContext(ctx, "a", ec); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Storage.PutReport(ctx, "a", &evalv2.EvalReport{ContextSnapshot: *ec}); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := s.SetGroundTruth(ctx, SetGroundTruthRequest{ID: "a", GroundTruth: "old"}); err != nil || changed {
+		t.Fatalf("setting the same ground truth = %v, %v; want unchanged", changed, err)
+	}
+	if changed, err := s.SetGroundTruth(ctx, SetGroundTruthRequest{ID: "a", GroundTruth: "new"}); err != nil || !changed {
+		t.Fatalf("setting a new ground truth = %v, %v; want changed", changed, err)
+	}
+	c, err := s.GetCase(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.EvalContext.Meta.GroundTruth != "new" || len(c.EvalContext.Checkpoints) != 0 {
+		t.Errorf("context = %+v, want the new ground truth alone", c.EvalContext)
+	}
+	if c.ReportV2 != nil {
+		t.Error("report kept after the ground truth changed")
+	}
+	if versions, err := s.Storage.ListContextVersions(ctx, "a"); err != nil || len(versions) != 2 || versions[0].N != 1 {
+		t.Errorf("context history = %v, %v; want the old context and the current one", versions, err)
+	}
+}
@@ -0,0 +1,248 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/workspace/workspacepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=asr-eval --go-grpc_out=../.. --go-grpc_opt=module=asr-eval asreval/workspace/v1/workspace.proto
+
+// The gRPC service, defined in proto/asreval/workspace/v1/workspace.proto,
+// mirrors the JSON API. Its messages name their fields after the JSON keys
+// of the Go types, so the server and GRPCClient convert between the two
+// through protojson rather than by hand. Requests pick a dataset with the
+// "dataset" metadata key.
+const grpcDatasetMDKey = "dataset"
+
+// RegisterGRPC serves the workspace service on s for every mounted dataset.
+func (d *Datasets) RegisterGRPC(s *grpc.Server) {
+	workspacepb.RegisterWorkspaceServer(s, &grpcServer{d: d})
+}
+
+type grpcServer struct {
+	workspacepb.UnimplementedWorkspaceServer
+	d *Datasets
+}
+
+func (g *grpcServer) ListCases(ctx context.Context, in *workspacepb.ListCasesRequest) (*workspacepb.ListCasesResponse, error) {
+	return serveRPC(ctx, g.d, in, new(workspacepb.ListCasesResponse), codes.Internal, func(s *Service, req *ListCasesRequest) (any, error) {
+		return s.ListCases(ctx, *req)
+	})
+}
+
+func (g *grpcServer) GetCase(ctx context.Context, in *workspacepb.GetCaseRequest) (*workspacepb.Case, error) {
+	if err := checkRPCID(in.GetId()); err != nil {
+		return nil, err
+	}
+	return serveRPC(ctx, g.d, in, new(workspacepb.Case), codes.NotFound, func(s *Service, _ *struct{}) (any, error) {
+		return s.GetCase(ctx, in.GetId())
+	})
+}
+
+func (g *grpcServer) Evaluate(ctx context.Context, in *workspacepb.EvaluateRequest) (*workspacepb.EvalReport, error) {
+	if err := checkRPCID(in.GetId()); err != nil {
+		return nil, err
+	}
+	return serveRPC(ctx, g.d, in, new(workspacepb.EvalReport), codes.Internal, func(s *Service, req *EvaluateRequest) (any, error) {
+		return s.Evaluate(ctx, *req)
+	})
+}
+
+func (g *grpcServer) GenerateContext(ctx context.Context, in *workspacepb.GenerateContextRequest) (*workspacepb.EvalContext, error) {
+	if err := checkRPCID(in.GetId()); err != nil {
+		return nil, err
+	}
+	return serveRPC(ctx, g.d, in, new(workspacepb.EvalContext), codes.Internal, func(s *Service, req *GenerateContextRequest) (any, error) {
+		return s.GenerateContext(ctx, *req)
+	})
+}
+
+func (g *grpcServer) UpdateContext(ctx context.Context, in *workspacepb.UpdateContextRequest) (*workspacepb.Case, error) {
+	if err := checkRPCID(in.GetId()); err != nil {
+		return nil, err
+	}
+	return serveRPC(ctx, g.d, in, new(workspacepb.Case), codes.Internal, func(s *Service, req *UpdateContextRequest) (any, error) {
+		return s.UpdateContext(ctx, *req)
+	})
+}
+
+func (g *grpcServer) Leaderboard(ctx context.Context, in *workspacepb.LeaderboardRequest) (*workspacepb.LeaderboardResponse, error) {
+	return serveRPC(ctx, g.d, in, new(workspacepb.LeaderboardResponse), codes.Internal, func(s *Service, filter *CaseFilter) (any, error) {
+		stats, err := s.Leaderboard(ctx, *filter)
+		if err != nil {
+			return nil, err
+		}
+		return leaderboardResponse{Providers: stats}, nil
+	})
+}
+
+func (g *grpcServer) HeadToHead(ctx context.Context, in *workspacepb.HeadToHeadRequest) (*workspacepb.HeadToHeadResponse, error) {
+	if in.GetA() == "" || in.GetB() == "" || in.GetA() == in.GetB() {
+		return nil, status.Error(codes.InvalidArgument, "a and b must name two different providers")
+	}
+	return serveRPC(ctx, g.d, in, new(workspacepb.HeadToHeadResponse), codes.Internal, func(s *Service, req *HeadToHeadRequest) (any, error) {
+		return s.HeadToHead(ctx, *req)
+	})
+}
+
+// leaderboardResponse is the Go shape of workspacepb.LeaderboardResponse.
+type leaderboardResponse struct {
+	Providers []ProviderStats `json:"providers"`
+}
+
+// serveRPC converts in to the Go request type Req, makes call on the
+// requested dataset, and converts its result into out. Errors that are not
+// already gRPC statuses are reported with code, the counterpart of the HTTP
+// handler's status.
+func serveRPC[Req any, Resp proto.Message](ctx context.Context, d *Datasets, in proto.Message, out Resp, code codes.Code, call func(*Service, *Req) (any, error)) (Resp, error) {
+	var zero Resp
+	svc, err := d.Get(datasetFromMD(ctx))
+	if err != nil {
+		return zero, status.Error(codes.NotFound, err.Error())
+	}
+	req := new(Req)
+	if err := fromProto(in, req); err != nil {
+		return zero, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp, err := call(svc, req)
+	if err != nil {
+		return zero, rpcError(err, code)
+	}
+	if err := toProto(resp, out); err != nil {
+		return zero, status.Error(codes.Internal, err.Error())
+	}
+	return out, nil
+}
+
+// toProto converts the Go value v to m through their shared JSON form.
+// Go-only fields, such as those the JSON API computes for the UI, are
+// dropped.
+func toProto(v any, m proto.Message) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, m)
+}
+
+// fromProto is the inverse of toProto.
+func fromProto(m proto.Message, v any) error {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func rpcError(err error, code codes.Code) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
+
+// checkRPCID rejects case IDs that could escape the dataset directory.
+func checkRPCID(id string) error {
+	if !validID(id) {
+		return status.Errorf(codes.InvalidArgument, "invalid case id: %q", id)
+	}
+	return nil
+}
+
+func datasetFromMD(ctx context.Context) string {
+	if v := metadata.ValueFromIncomingContext(ctx, grpcDatasetMDKey); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// GRPCClient is a client for the workspace gRPC service that takes and
+// returns the same Go types as Service.
+type GRPCClient struct {
+	pb      workspacepb.WorkspaceClient
+	dataset string
+}
+
+// NewGRPCClient returns a client that calls cc. dataset selects the
+// dataset on a multi-dataset server; "" uses the default.
+func NewGRPCClient(cc grpc.ClientConnInterface, dataset string) *GRPCClient {
+	return &GRPCClient{pb: workspacepb.NewWorkspaceClient(cc), dataset: dataset}
+}
+
+func (c *GRPCClient) ListCases(ctx context.Context, req ListCasesRequest) (*ListCasesResponse, error) {
+	resp := new(ListCasesResponse)
+	return resp, callRPC(c.outgoing(ctx), c.pb.ListCases, req, new(workspacepb.ListCasesRequest), resp)
+}
+
+func (c *GRPCClient) GetCase(ctx context.Context, id string) (*Case, error) {
+	resp := new(Case)
+	return resp, callRPC(c.outgoing(ctx), c.pb.GetCase, nil, &workspacepb.GetCaseRequest{Id: id}, resp)
+}
+
+// Evaluate runs the evaluation inline; the call returns with the report.
+func (c *GRPCClient) Evaluate(ctx context.Context, req EvaluateRequest) (*evalv2.EvalReport, error) {
+	resp := new(evalv2.EvalReport)
+	return resp, callRPC(c.outgoing(ctx), c.pb.Evaluate, req, new(workspacepb.EvaluateRequest), resp)
+}
+
+func (c *GRPCClient) GenerateContext(ctx context.Context, req GenerateContextRequest) (*evalv2.EvalContext, error) {
+	resp := new(evalv2.EvalContext)
+	return resp, callRPC(c.outgoing(ctx), c.pb.GenerateContext, req, new(workspacepb.GenerateContextRequest), resp)
+}
+
+func (c *GRPCClient) UpdateContext(ctx context.Context, req UpdateContextRequest) (*Case, error) {
+	resp := new(Case)
+	return resp, callRPC(c.outgoing(ctx), c.pb.UpdateContext, req, new(workspacepb.UpdateContextRequest), resp)
+}
+
+func (c *GRPCClient) Leaderboard(ctx context.Context, filter CaseFilter) ([]ProviderStats, error) {
+	resp := new(leaderboardResponse)
+	if err := callRPC(c.outgoing(ctx), c.pb.Leaderboard, filter, new(workspacepb.LeaderboardRequest), resp); err != nil {
+		return nil, err
+	}
+	return resp.Providers, nil
+}
+
+func (c *GRPCClient) HeadToHead(ctx context.Context, req HeadToHeadRequest) (*HeadToHeadResponse, error) {
+	resp := new(HeadToHeadResponse)
+	return resp, callRPC(c.outgoing(ctx), c.pb.HeadToHead, req, new(workspacepb.HeadToHeadRequest), resp)
+}
+
+func (c *GRPCClient) outgoing(ctx context.Context) context.Context {
+	if c.dataset == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, grpcDatasetMDKey, c.dataset)
+}
+
+// callRPC converts req, unless nil, into in, makes call, and converts its
+// result into resp.
+func callRPC[In, Out proto.Message](ctx context.Context, call func(context.Context, In, ...grpc.CallOption) (Out, error), req any, in In, resp any) error {
+	if req != nil {
+		if err := toProto(req, in); err != nil {
+			return err
+		}
+	}
+	out, err := call(ctx, in)
+	if err != nil {
+		return err
+	}
+	return fromProto(out, resp)
+}
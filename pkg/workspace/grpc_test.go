@@ -0,0 +1,136 @@
+package workspace
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/workspace/workspacepb"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCGetCase(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.flac": "", "a.dg": "hello"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := NewDatasets()
+	if err := d.Mount("test", &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}); err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	d.RegisterGRPC(srv)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	c, err := NewGRPCClient(conn, "test").GetCase(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Transcripts["dg"]; got != "hello" {
+		t.Errorf("GetCase transcript = %q, want %q", got, "hello")
+	}
+
+	for id, want := range map[string]codes.Code{"b": codes.NotFound, "../a": codes.InvalidArgument} {
+		_, err := NewGRPCClient(conn, "").GetCase(ctx, id)
+		if got := status.Code(err); got != want {
+			t.Errorf("GetCase(%q) code = %v, want %v", id, got, want)
+		}
+	}
+	_, err = NewGRPCClient(conn, "other").GetCase(ctx, "a")
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("GetCase on unknown dataset code = %v, want NotFound", got)
+	}
+}
+
+// TestProtoRoundTrip checks that the proto messages carry every field of
+// the Go types they mirror; a field missing from the .proto would be
+// dropped on the way through.
+func TestProtoRoundTrip(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	ctx := evalv2.EvalContext{
+		SchemaVersion: 2,
+		Meta: evalv2.ContextMeta{
+			BusinessGoal:            "goal",
+			AudioRealityInference:   "inference",
+			TotalTokenCountEstimate: 12,
+			GroundTruth:             "gt",
+			QuestionableGT:          true,
+			QuestionableReason:      "reason",
+			AlignModel:              "align",
+		},
+		Checkpoints:       []evalv2.Checkpoint{{ID: "S1", StartMS: 10, TextSegment: "text", Tier: 1, Weight: 0.5, Rationale: "why", Span: &evalv2.Span{StartMS: 10, EndMS: 20}}},
+		Hash:              "hash",
+		AudioRealitySpans: []evalv2.TimedText{{StartMS: 1, EndMS: 2, Text: "t"}},
+	}
+	metrics := evalv2.EvalMetrics{SScore: 0.5, PScore: 0.25, QScore: 40, PhoneticDetails: evalv2.PhoneticDetails{Sub: 1, Del: 2, Ins: 3}}
+	results := map[string]evalv2.CheckpointResult{"S1": {
+		Status:   evalv2.StatusPass,
+		Detected: "text",
+		Reason:   "ok",
+		Override: &evalv2.CheckpointOverride{OriginalStatus: evalv2.StatusFail, Reason: "misheard", Author: "ann", Time: at},
+	}}
+	truncation := &evalv2.Truncation{PromptTokens: 100, DroppedCheckpoints: []string{"S2"}, TruncatedTranscripts: map[string]int{"dg": 300}}
+	want := &Case{
+		ID:          "a",
+		Transcripts: map[string]string{"dg": "hello"},
+		Tags:        []string{"noisy"},
+		Streams:     []string{"dg"},
+		Review:      &Review{State: ReviewDisputed, Assignee: "bob", Note: "note", UpdatedBy: "ann", UpdateTime: at},
+		Corrections: map[string]*Correction{"dg": {Text: "hello!", UpdatedBy: "ann", UpdateTime: at}},
+		Channels:    &audio.ChannelOptions{Select: "agent", Agent: 2, Customer: 1},
+		EvalContext: &ctx,
+		ReportV2: &evalv2.EvalReport{
+			SchemaVersion: 2,
+			Results: map[string]evalv2.EvalResult{"dg": {
+				Transcript:        "hello",
+				RevisedTranscript: "hello.",
+				Metrics:           metrics,
+				CheckpointResults: results,
+				Summary:           []string{"fine"},
+				Model:             "judge",
+				Truncation:        truncation,
+				Verdicts:          []evalv2.JudgeVerdict{{Model: "judge", Metrics: metrics, CheckpointResults: results, Truncation: truncation}},
+				Disputed:          []string{"S1"},
+				FailedJudges:      []string{"other"},
+			}},
+			ContextSnapshot: ctx,
+		},
+	}
+
+	m := new(workspacepb.Case)
+	if err := toProto(want, m); err != nil {
+		t.Fatal(err)
+	}
+	got := new(Case)
+	if err := fromProto(m, got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Case round trip mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,84 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime/debug"
+)
+
+// Build metadata reported by GET /version. Set them with
+//
+//	go build -ldflags "-X asr-eval/pkg/workspace.GitSHA=$(git rev-parse HEAD) -X asr-eval/pkg/workspace.BuildTime=$(date -u +%FT%TZ)"
+//
+// Without them, the VCS stamp embedded by the go command is used.
+var (
+	GitSHA    string
+	BuildTime string
+)
+
+// VersionInfo for GET /version
+type VersionInfo struct {
+	GitSHA    string `json:"git_sha,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a dirty tree
+	GoVersion string `json:"go_version,omitempty"`
+}
+
+// version returns the build metadata of the running binary.
+func version() VersionInfo {
+	v := VersionInfo{GitSHA: GitSHA, BuildTime: BuildTime}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	v.GoVersion = bi.GoVersion
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if v.GitSHA == "" {
+				v.GitSHA = s.Value
+			}
+		case "vcs.time":
+			if v.BuildTime == "" {
+				v.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			v.Modified = s.Value == "true"
+		}
+	}
+	return v
+}
+
+// ready reports why the service cannot serve requests, or nil if it can.
+func (s *Service) ready() error {
+	var errs []error
+	if _, err := os.ReadDir(s.Config.DatasetDir); err != nil {
+		errs = append(errs, fmt.Errorf("dataset dir: %w", err))
+	}
+	if s.LLM == nil {
+		errs = append(errs, errors.New("LLM client not initialized"))
+	}
+	return errors.Join(errs...)
+}
+
+// registerHealthRoutes adds the unauthenticated probes. ready checks every
+// served dataset.
+func registerHealthRoutes(mux *http.ServeMux, ready func() error) {
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /version", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(version())
+	})
+}
@@ -0,0 +1,182 @@
+package workspace
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"asr-eval/pkg/audio"
+)
+
+// hfRow is a line of the metadata.jsonl of ExportHF. Its fields keep one
+// schema across rows, as the datasets library expects: providers are a
+// list rather than keys, and absent values are null rather than left out.
+type hfRow struct {
+	FileName       string         `json:"file_name,omitempty"` // The audio next to metadata.jsonl, when exported
+	ID             string         `json:"id"`
+	AudioFile      string         `json:"audio_file"` // Relative to the dataset directory
+	DurationMS     int64          `json:"duration_ms"`
+	GroundTruth    string         `json:"ground_truth"`
+	QuestionableGT bool           `json:"questionable_gt"`
+	ContextHash    string         `json:"context_hash"`
+	Tags           []string       `json:"tags"`
+	Checkpoints    []hfCheckpoint `json:"checkpoints"`
+	Results        []hfResult     `json:"results"` // By provider, sorted
+}
+
+type hfCheckpoint struct {
+	ID      string  `json:"id"`
+	StartMS int     `json:"start_ms"`
+	Text    string  `json:"text"`
+	Tier    int     `json:"tier"`
+	Weight  float64 `json:"weight"`
+}
+
+type hfResult struct {
+	Provider   string   `json:"provider"`
+	Transcript string   `json:"transcript"`
+	QScore     *int     `json:"q_score"` // Null when not evaluated
+	SScore     *float64 `json:"s_score"`
+	PScore     *float64 `json:"p_score"`
+}
+
+// ExportHFRequest selects what ExportHF writes.
+type ExportHFRequest struct {
+	Filter CaseFilter
+	Audio  bool // Include the audio, making an audiofolder dataset
+}
+
+// hfReadme is the README.md of ExportHF, %s being the dataset name, the
+// export time and how to load it.
+const hfReadme = `# %s
+
+Exported by asr-eval on %s: a row per case in data/metadata.jsonl with
+the ground truth, evaluation checkpoints, and each provider's transcript
+and scores.
+
+` + "```python" + `
+from datasets import load_dataset
+%s
+` + "```\n"
+
+// ExportHF writes the cases matching req.Filter as a zip loadable with the
+// Hugging Face datasets library: data/metadata.jsonl, a row per case, and
+// with req.Audio the audio files next to it, which makes it an audiofolder
+// dataset. ds.to_parquet() converts it to Parquet.
+func (s *Service) ExportHF(ctx context.Context, w io.Writer, req ExportHFRequest) error {
+	cases, err := s.scanCasesFor(ctx, req.Filter)
+	if err != nil {
+		return err
+	}
+	enabled := s.enabledProviders()
+	audioInfo := s.audioLookup(ctx)
+
+	zw := zip.NewWriter(w)
+	mw, err := zw.Create("data/metadata.jsonl")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(mw)
+	var ids []string
+	for _, sc := range cases {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !req.Filter.Match(sc, enabled) {
+			continue
+		}
+		c, err := s.GetCase(ctx, sc.ID)
+		if err != nil {
+			return err
+		}
+		if req.Filter.Run != "" {
+			c.ReportV2 = sc.ReportV2
+		}
+		row := s.hfRow(c, audioInfo)
+		if req.Audio {
+			row.FileName = row.AudioFile
+		}
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+		ids = append(ids, c.ID)
+	}
+
+	if req.Audio {
+		for _, id := range ids {
+			if err := addZipFile(zw, filepath.Join(s.Config.DatasetDir, id+extFlac), "data/"+id+extFlac); err != nil {
+				return err
+			}
+		}
+	}
+	load := `ds = load_dataset("json", data_files="data/metadata.jsonl")`
+	if req.Audio {
+		load = `ds = load_dataset("audiofolder", data_dir="data")`
+	}
+	rw, err := zw.Create("README.md")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(rw, hfReadme, s.datasetName(), time.Now().UTC().Format(time.DateOnly), load); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *Service) hfRow(c *Case, audioInfo func(string) *audio.Info) *hfRow {
+	row := &hfRow{
+		ID:          c.ID,
+		AudioFile:   c.ID + extFlac,
+		Tags:        c.Tags,
+		Checkpoints: []hfCheckpoint{},
+		Results:     []hfResult{},
+	}
+	if row.Tags == nil {
+		row.Tags = []string{}
+	}
+	if info := audioInfo(c.ID); info != nil {
+		row.DurationMS = info.DurationMS
+	}
+	if ec := c.EvalContext; ec != nil {
+		row.GroundTruth = ec.Meta.GroundTruth
+		row.QuestionableGT = ec.Meta.QuestionableGT
+		row.ContextHash = ec.Hash
+		for _, cp := range ec.Checkpoints {
+			row.Checkpoints = append(row.Checkpoints, hfCheckpoint{
+				ID:      cp.ID,
+				StartMS: cp.StartMS,
+				Text:    cp.TextSegment,
+				Tier:    cp.Tier,
+				Weight:  cp.Weight,
+			})
+		}
+	}
+
+	providers := maps.Clone(c.Transcripts)
+	if providers == nil {
+		providers = make(map[string]string)
+	}
+	if c.ReportV2 != nil {
+		for p, r := range c.ReportV2.Results {
+			if _, ok := providers[p]; !ok {
+				providers[p] = r.Transcript
+			}
+		}
+	}
+	for _, p := range slices.Sorted(maps.Keys(providers)) {
+		res := hfResult{Provider: p, Transcript: providers[p]}
+		if c.ReportV2 != nil {
+			if r, ok := c.ReportV2.Results[p]; ok {
+				res.QScore, res.SScore, res.PScore = &r.Metrics.QScore, &r.Metrics.SScore, &r.Metrics.PScore
+			}
+		}
+		row.Results = append(row.Results, res)
+	}
+	return row
+}
@@ -0,0 +1,35 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestHFRow(t *testing.T) {
+	s := &Service{Config: ServiceConfig{DatasetDir: t.TempDir()}}
+	c := &Case{
+		ID:          "a",
+		Transcripts: map[string]string{"x": "hello", "y": "hullo"},
+		EvalContext: &evalv2.EvalContext{
+			Meta:        evalv2.ContextMeta{GroundTruth: "hello"},
+			Checkpoints: []evalv2.Checkpoint{{ID: "cp1", TextSegment: "hello", Tier: 1}},
+		},
+		ReportV2: &evalv2.EvalReport{Results: map[string]evalv2.EvalResult{
+			"x": {Transcript: "hello", Metrics: evalv2.EvalMetrics{QScore: 90, SScore: 0.9, PScore: 1}},
+		}},
+	}
+	b, err := json.Marshal(s.hfRow(c, s.audioLookup(context.Background())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"a","audio_file":"a.flac","duration_ms":0,"ground_truth":"hello","questionable_gt":false,"context_hash":"","tags":[],` +
+		`"checkpoints":[{"id":"cp1","start_ms":0,"text":"hello","tier":1,"weight":0}],` +
+		`"results":[{"provider":"x","transcript":"hello","q_score":90,"s_score":0.9,"p_score":1},` +
+		`{"provider":"y","transcript":"hullo","q_score":null,"s_score":null,"p_score":null}]}`
+	if string(b) != want {
+		t.Errorf("row =\n%s\nwant\n%s", b, want)
+	}
+}
@@ -0,0 +1,147 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// maxHistory bounds the archived versions kept per case record.
+const maxHistory = 20
+
+// RecordVersion is a version of a case's context or report.
+type RecordVersion struct {
+	N          int       // 0 for the current record
+	UpdateTime time.Time // When it was written
+}
+
+// In the dataset directory, archived versions of "[id]<ext>" live next to
+// it as "[id]<base>.<n>.json", e.g. "[id].report.v2.3.json", with n
+// increasing.
+
+func versionName(id, ext string, n int) string {
+	return fmt.Sprintf("%s%s.%d%s", id, strings.TrimSuffix(ext, extJSON), n, extJSON)
+}
+
+// versionInfo describes an archived file.
+type versionInfo struct {
+	n       int
+	name    string
+	modTime time.Time
+}
+
+// listVersions returns the archived versions of "[id]<ext>", oldest first.
+func (st *FSStorage) listVersions(id, ext string) ([]versionInfo, error) {
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := id + strings.TrimSuffix(ext, extJSON) + "."
+	var out []versionInfo
+	for _, e := range entries {
+		name := e.Name()
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		num, ok := strings.CutSuffix(rest, extJSON)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil || n <= 0 {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, versionInfo{n: n, name: name, modTime: fi.ModTime()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].n < out[j].n })
+	return out, nil
+}
+
+// versions returns the archived versions of "[id]<ext>", oldest first,
+// followed by the current one when it exists.
+func (st *FSStorage) versions(id, ext string) ([]RecordVersion, error) {
+	archived, err := st.listVersions(id, ext)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]RecordVersion, 0, len(archived)+1)
+	for _, v := range archived {
+		out = append(out, RecordVersion{N: v.n, UpdateTime: v.modTime})
+	}
+	if fi, err := os.Stat(filepath.Join(st.dir, id+ext)); err == nil {
+		out = append(out, RecordVersion{UpdateTime: fi.ModTime()})
+	}
+	return out, nil
+}
+
+// archive moves the current "[id]<ext>" to the next version number, which
+// it returns. It is a no-op returning 0 when the file does not exist.
+func (st *FSStorage) archive(id, ext string) (int, error) {
+	current := filepath.Join(st.dir, id+ext)
+	if _, err := os.Stat(current); os.IsNotExist(err) {
+		return 0, nil
+	}
+	return st.addVersion(id, ext, func(name string) error {
+		return os.Rename(current, filepath.Join(st.dir, name))
+	})
+}
+
+// addVersion has write create the next version of "[id]<ext>", given its
+// file name, and returns its number. The oldest versions beyond maxHistory
+// are pruned.
+func (st *FSStorage) addVersion(id, ext string, write func(name string) error) (int, error) {
+	versions, err := st.listVersions(id, ext)
+	if err != nil {
+		return 0, err
+	}
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1].n + 1
+	}
+	if err := write(versionName(id, ext, next)); err != nil {
+		return 0, err
+	}
+	for len(versions) >= maxHistory {
+		if err := os.Remove(filepath.Join(st.dir, versions[0].name)); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		versions = versions[1:]
+	}
+	return next, nil
+}
+
+func (st *FSStorage) ArchiveContext(ctx context.Context, id string) (int, error) {
+	return st.archive(id, extGTV2)
+}
+
+func (st *FSStorage) ListContextVersions(ctx context.Context, id string) ([]RecordVersion, error) {
+	return st.versions(id, extGTV2)
+}
+
+func (st *FSStorage) GetContextVersion(ctx context.Context, id string, n int) (*evalv2.EvalContext, error) {
+	return st.loadContext(versionName(id, extGTV2, n))
+}
+
+func (st *FSStorage) ArchiveReport(ctx context.Context, id string) (int, error) {
+	return st.archive(id, extReportV2)
+}
+
+func (st *FSStorage) ListReportVersions(ctx context.Context, id string) ([]RecordVersion, error) {
+	return st.versions(id, extReportV2)
+}
+
+func (st *FSStorage) GetReportVersion(ctx context.Context, id string, n int) (*evalv2.EvalReport, error) {
+	return st.loadReport(versionName(id, extReportV2, n))
+}
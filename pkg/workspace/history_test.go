@@ -0,0 +1,116 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestArchivePrunes(t *testing.T) {
+	dir := t.TempDir()
+	st := NewFSStorage(dir)
+	current := filepath.Join(dir, "a"+extReportV2)
+
+	for i := 0; i < maxHistory+3; i++ {
+		if _, err := st.archive("a", extReportV2); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(current, []byte(fmt.Sprint(i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := st.listVersions("a", extReportV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != maxHistory {
+		t.Fatalf("got %d versions, want %d", len(versions), maxHistory)
+	}
+	if first, last := versions[0].n, versions[len(versions)-1].n; first != 3 || last != maxHistory+2 {
+		t.Errorf("versions span %d..%d, want 3..%d", first, last, maxHistory+2)
+	}
+	// Version n holds the content written in iteration n-1.
+	if got, _ := os.ReadFile(filepath.Join(dir, versionName("a", extReportV2, 5))); string(got) != "4" {
+		t.Errorf("version 5 = %q, want %q", got, "4")
+	}
+}
+
+func TestRollbackReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewCaseBundleStorage(dir)}
+	ctx := context.Background()
+	for _, hash := range []string{"h1", "h2"} {
+		if err := s.writeEvalReport(ctx, "a", &evalv2.EvalReport{ContextSnapshot: evalv2.EvalContext{Hash: hash}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.RollbackReport(ctx, RollbackReportRequest{ID: "a", Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ContextSnapshot.Hash != "h1" {
+		t.Errorf("rolled back report is against %q, want h1", got.ContextSnapshot.Hash)
+	}
+	versions, err := s.ListReports(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hashes []string
+	for _, v := range versions {
+		hashes = append(hashes, fmt.Sprintf("%d:%s", v.Version, v.ContextHash))
+	}
+	if diff := cmp.Diff([]string{"0:h1", "2:h2", "1:h1"}, hashes); diff != "" {
+		t.Errorf("report history mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := s.RollbackReport(ctx, RollbackReportRequest{ID: "a", Version: 9}); err == nil {
+		t.Error("rolling back to a missing version succeeded, want error")
+	}
+}
+
+func TestResetResults(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	report := &evalv2.EvalReport{
+		Results:         map[string]evalv2.EvalResult{"x": {}, "y": {}},
+		ContextSnapshot: evalv2.EvalContext{Hash: "h"},
+	}
+	if err := s.writeEvalReport(ctx, "a", report); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ResetResults(ctx, ResetResultsRequest{ID: "a", ProviderIDs: []string{"z"}}); err == nil {
+		t.Error("resetting a missing result succeeded, want error")
+	}
+	if _, err := s.ResetResults(ctx, ResetResultsRequest{ID: "a", ProviderIDs: []string{"x"}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.loadEvalReport(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.Results["y"]; len(got.Results) != 1 || !ok || got.ContextSnapshot.Hash != "h" {
+		t.Errorf("report = %+v, want y's result against h", got)
+	}
+
+	if _, err := s.ResetResults(ctx, ResetResultsRequest{ID: "a", ProviderIDs: []string{"y"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a"+extReportV2)); !os.IsNotExist(err) {
+		t.Errorf("report still exists after removing every result: %v", err)
+	}
+	if versions, _ := s.Storage.ListReportVersions(ctx, "a"); len(versions) != 2 {
+		t.Errorf("got %d archived reports, want 2", len(versions))
+	}
+}
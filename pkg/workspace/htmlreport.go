@@ -0,0 +1,186 @@
+package workspace
+
+import (
+	"archive/zip"
+	"context"
+	_ "embed"
+	"html/template"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/textdiff"
+)
+
+//go:embed htmlreport.tmpl
+var htmlReportTemplate string
+
+var htmlReport = template.Must(template.New("").Funcs(template.FuncMap{
+	"inc":  func(i int) int { return i + 1 },
+	"pct":  func(f float64) float64 { return f * 100 },
+	"join": strings.Join,
+}).Parse(htmlReportTemplate))
+
+type htmlIndex struct {
+	Dataset     string
+	CreateTime  time.Time
+	Leaderboard []ProviderStats
+	Providers   []string
+	Cases       []htmlIndexRow
+}
+
+type htmlIndexRow struct {
+	ID     string
+	Href   string
+	Tags   []string
+	Review ReviewState
+	Scores []htmlScore // By htmlIndex.Providers
+}
+
+type htmlScore struct {
+	Q  int
+	OK bool
+}
+
+type htmlCase struct {
+	ID          string
+	Context     *evalv2.EvalContext
+	Results     []htmlResult
+	Checkpoints []htmlCheckpoint
+}
+
+type htmlResult struct {
+	Provider string
+	Result   evalv2.EvalResult
+	Diff     *textdiff.Alignment // Against the ground truth; nil without one
+}
+
+type htmlCheckpoint struct {
+	Checkpoint evalv2.Checkpoint
+	Cells      []htmlCell // By htmlCase.Results
+}
+
+type htmlCell struct {
+	Status     evalv2.CheckpointStatus
+	Class      string
+	Detected   string
+	Reason     string
+	Overridden bool
+}
+
+// ExportHTML writes a zip holding a static HTML report of the evaluated
+// cases matching filter: index.html with the leaderboard and a case table,
+// and cases/<id>.html with each case's scores, checkpoints and transcript
+// diffs. It needs no server to view.
+func (s *Service) ExportHTML(ctx context.Context, w io.Writer, filter CaseFilter) error {
+	cases, err := s.scanCasesFor(ctx, filter)
+	if err != nil {
+		return err
+	}
+	leaderboard, err := s.Leaderboard(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	enabled := s.enabledProviders()
+	index := htmlIndex{
+		Dataset:     s.datasetName(),
+		CreateTime:  time.Now(),
+		Leaderboard: leaderboard,
+	}
+	for _, p := range leaderboard {
+		index.Providers = append(index.Providers, p.Provider)
+	}
+
+	zw := zip.NewWriter(w)
+	for _, c := range cases {
+		if c.ReportV2 == nil || !filter.Match(c, enabled) {
+			continue
+		}
+		row := htmlIndexRow{
+			ID:     c.ID,
+			Href:   "cases/" + url.PathEscape(c.ID) + ".html",
+			Tags:   c.Tags,
+			Review: reviewState(c.Review),
+		}
+		for _, p := range index.Providers {
+			res, ok := c.ReportV2.Results[p]
+			row.Scores = append(row.Scores, htmlScore{Q: res.Metrics.QScore, OK: ok})
+		}
+		index.Cases = append(index.Cases, row)
+
+		f, err := zw.Create("cases/" + c.ID + ".html")
+		if err != nil {
+			return err
+		}
+		if err := htmlReport.ExecuteTemplate(f, "case", newHTMLCase(c, enabled)); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("index.html")
+	if err != nil {
+		return err
+	}
+	if err := htmlReport.ExecuteTemplate(f, "index", index); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// newHTMLCase lays out c's report for the case page. enabled limits the
+// providers shown; nil shows all.
+func newHTMLCase(c *Case, enabled map[string]bool) htmlCase {
+	hc := htmlCase{ID: c.ID, Context: c.EvalContext}
+	if hc.Context == nil {
+		hc.Context = &c.ReportV2.ContextSnapshot
+	}
+
+	providers := make([]string, 0, len(c.ReportV2.Results))
+	for p := range c.ReportV2.Results {
+		if enabled == nil || enabled[p] {
+			providers = append(providers, p)
+		}
+	}
+	sort.Strings(providers)
+
+	gt := hc.Context.Meta.GroundTruth
+	for _, p := range providers {
+		res := c.ReportV2.Results[p]
+		hr := htmlResult{Provider: p, Result: res}
+		if gt != "" {
+			if a, err := textdiff.Align(gt, res.Transcript); err == nil {
+				hr.Diff = a
+			}
+		}
+		hc.Results = append(hc.Results, hr)
+	}
+
+	// Checkpoints the report was scored against.
+	checkpoints := c.ReportV2.ContextSnapshot.Checkpoints
+	if len(checkpoints) == 0 {
+		checkpoints = hc.Context.Checkpoints
+	}
+	for _, cp := range checkpoints {
+		row := htmlCheckpoint{Checkpoint: cp}
+		for _, r := range hc.Results {
+			cr, ok := r.Result.CheckpointResults[cp.ID]
+			if !ok {
+				row.Cells = append(row.Cells, htmlCell{})
+				continue
+			}
+			row.Cells = append(row.Cells, htmlCell{
+				Status:     cr.Status,
+				Class:      strings.ToLower(string(cr.Status)),
+				Detected:   cr.Detected,
+				Reason:     cr.Reason,
+				Overridden: cr.Override != nil,
+			})
+		}
+		hc.Checkpoints = append(hc.Checkpoints, row)
+	}
+	return hc
+}
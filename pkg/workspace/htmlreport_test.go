@@ -0,0 +1,54 @@
+package workspace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestHTMLCase(t *testing.T) {
+	c := &Case{
+		ID: "a",
+		EvalContext: &evalv2.EvalContext{
+			Meta:        evalv2.ContextMeta{GroundTruth: "turn left at <main> street"},
+			Checkpoints: []evalv2.Checkpoint{{ID: "cp1", TextSegment: "main street"}},
+		},
+		ReportV2: &evalv2.EvalReport{Results: map[string]evalv2.EvalResult{
+			"x": {
+				Transcript: "turn right at <main> street",
+				CheckpointResults: map[string]evalv2.CheckpointResult{
+					"cp1": {Status: evalv2.StatusPass, Override: &evalv2.CheckpointOverride{}},
+				},
+			},
+			"y":        {Transcript: "turn left"},
+			"disabled": {},
+		}},
+	}
+	hc := newHTMLCase(c, map[string]bool{"x": true, "y": true})
+	if len(hc.Results) != 2 || hc.Results[0].Provider != "x" || hc.Results[1].Provider != "y" {
+		t.Fatalf("Results = %+v, want x and y", hc.Results)
+	}
+	if len(hc.Checkpoints) != 1 {
+		t.Fatalf("Checkpoints = %+v, want one", hc.Checkpoints)
+	}
+	cells := hc.Checkpoints[0].Cells
+	if cells[0].Class != "pass" || !cells[0].Overridden || cells[1].Status != "" {
+		t.Errorf("Cells = %+v", cells)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReport.ExecuteTemplate(&buf, "case", hc); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"<del>left", "<ins>right", "&lt;main&gt;", `class="pass"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("page is missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<main>") {
+		t.Error("page has unescaped transcript text")
+	}
+}
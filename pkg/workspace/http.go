@@ -1,30 +1,152 @@
 package workspace
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+
+	"asr-eval/pkg/asr"
 )
 
-func (s *Service) RegisterRoutes(mux *http.ServeMux) {
+// apiRoutes maps patterns to Service handlers. They are registered directly
+// by Service.RegisterRoutes and per dataset by Datasets.RegisterRoutes.
+var apiRoutes = []struct {
+	pattern string
+	handler func(*Service, http.ResponseWriter, *http.Request)
+}{
 	// Standard Methods
-	mux.HandleFunc("GET /api/cases", s.handleListCases)
-	mux.HandleFunc("GET /api/cases/{id}", s.handleGetCase)
+	{"GET /api/cases", (*Service).handleListCases},
+	{"POST /api/cases", (*Service).handleCreateCase},
+	{"GET /api/cases/{id}", (*Service).handleGetCase},
+	{"DELETE /api/cases/{id}", (*Service).handleDeleteCase},
+	{"GET /api/cases/{id}/audio-info", (*Service).handleGetAudioInfo},
+	{"GET /api/cases/{id}/checkpoints/{checkpoint}/clip", (*Service).handleCheckpointClip},
+	{"GET /api/cases/{id}/streams/{provider}", (*Service).handleGetStream},
+	{"POST /api/cases/{id}/transcripts/{provider}", (*Service).handleUpdateCorrection},
+	{"GET /api/cases/{id}/diff", (*Service).handleDiff},
+	{"GET /api/cases/{id}/reports", (*Service).handleListReports},
+	{"GET /api/cases/{id}/contexts", (*Service).handleListContexts},
+	{"GET /api/cases/{id}/contexts/{version}", (*Service).handleGetContextVersion},
+	{"GET /api/cases/{id}/comments", (*Service).handleListComments},
+	{"POST /api/cases/{id}/comments", (*Service).handleCreateComment},
+	{"DELETE /api/cases/{id}/comments/{comment}", (*Service).handleDeleteComment},
 	// Custom Methods - dispatched via POST /api/cases/{id} because {id}:suffix is not supported by ServeMux
-	mux.HandleFunc("POST /api/cases/{id}", s.handleUpdateCaseOps)
+	{"POST /api/cases/{id}", (*Service).handleUpdateCaseOps},
+	// Collection custom methods
+	{"GET /api/cases:stale", (*Service).handleListStale},
+	{"GET /api/cases:duplicates", (*Service).handleListDuplicates},
+	{"GET /api/cases:audioIssues", (*Service).handleListAudioIssues},
+	{"GET /api/cases:live", editorOnly((*Service).handleLive)}, // Creates a case and streams to providers
+	{"POST /api/cases:evaluateAll", (*Service).handleEvaluateAll},
+
+	// Runs
+	{"GET /api/runs", (*Service).handleListRuns},
+	{"POST /api/runs", (*Service).handleCreateRun},
+	{"GET /api/runs/{id}", (*Service).handleGetRun},
+	{"GET /api/runs:compare", (*Service).handleCompareRuns},
+
+	// Stats
+	{"GET /api/stats/leaderboard", (*Service).handleLeaderboard},
+	{"GET /api/stats/head-to-head", (*Service).handleHeadToHead},
+	{"GET /api/stats/coverage", (*Service).handleCoverage},
+
+	// Config
+	{"PUT /api/config/providers", (*Service).handleUpdateProviders},
+
+	// Audit
+	{"GET /api/audit", (*Service).handleListAudit},
+
+	// Export
+	{"GET /api/export", (*Service).handleExport},
+	{"GET /api/export/bundle", (*Service).handleExportBundle},
+	{"POST /api/import/bundle", (*Service).handleImportBundle},
+
+	// Jobs
+	{"GET /api/jobs", (*Service).handleListJobs},
+	{"GET /api/jobs/{id}", (*Service).handleGetJob},
+	{"POST /api/jobs/{id}", (*Service).handleJobOps},
+
+	// Live updates
+	{"GET /api/events", (*Service).handleEvents},
+}
+
+// editorOnly rejects viewers from h whatever the method, for routes such as
+// a WebSocket that change the dataset or spend provider quota on a GET,
+// which Authenticator.Wrap lets viewers through.
+func editorOnly(h func(*Service, http.ResponseWriter, *http.Request)) func(*Service, http.ResponseWriter, *http.Request) {
+	return func(s *Service, w http.ResponseWriter, r *http.Request) {
+		if RoleFromContext(r.Context()) == RoleViewer {
+			http.Error(w, "viewers cannot modify the dataset", http.StatusForbidden)
+			return
+		}
+		h(s, w, r)
+	}
+}
+
+// pathIDs are the wildcards naming a file in the dataset directory.
+var pathIDs = []string{"id", "provider", "comment"}
+
+// checkPathIDs rejects requests whose path IDs could escape the dataset
+// directory. ServeMux unescapes wildcards, so /api/cases/..%2Fx reaches h
+// with an id of "../x".
+func checkPathIDs(h func(*Service, http.ResponseWriter, *http.Request)) func(*Service, http.ResponseWriter, *http.Request) {
+	return func(s *Service, w http.ResponseWriter, r *http.Request) {
+		for _, name := range pathIDs {
+			if v := r.PathValue(name); v != "" && !validID(v) {
+				http.Error(w, fmt.Sprintf("invalid %s: %q", name, v), http.StatusBadRequest)
+				return
+			}
+		}
+		h(s, w, r)
+	}
+}
+
+// validID reports whether id can name a file in the dataset directory.
+func validID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\`) && !strings.HasPrefix(id, ".")
+}
+
+func (s *Service) RegisterRoutes(mux *http.ServeMux) {
+	for _, rt := range apiRoutes {
+		h := checkPathIDs(rt.handler)
+		mux.HandleFunc(rt.pattern, func(w http.ResponseWriter, r *http.Request) { h(s, w, r) })
+	}
 
 	// Config
 	mux.HandleFunc("GET /api/config", s.handleGetConfig)
+
+	// Health
+	registerHealthRoutes(mux, s.ready)
 }
 
+// handleListCases handles GET /api/cases
+// It supports If-None-Match, so polling clients get 304 Not Modified
+// until a file in the dataset changes.
 func (s *Service) handleListCases(w http.ResponseWriter, r *http.Request) {
-	cases, err := s.ListCases(r.Context())
+	req, err := parseListCasesRequest(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.checkETag(w, r) {
+		return
+	}
+	resp, err := s.ListCases(r.Context(), req)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(cases)
+	json.NewEncoder(w).Encode(resp)
 }
 
 // handleGetCase handles GET /api/cases/{id}
@@ -48,6 +170,236 @@ func (s *Service) handleGetCase(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(c)
 }
 
+// handleCreateCase handles POST /api/cases
+func (s *Service) handleCreateCase(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	req, err := parseCreateCaseRequest(r.MultipartForm)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c, ok := req.Audio.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	created, err := s.CreateCase(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/cases/"+created.ID)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// parseCreateCaseRequest reads a CreateCaseRequest from a multipart form.
+// Transcripts may be sent either as text fields or as files.
+func parseCreateCaseRequest(form *multipart.Form) (CreateCaseRequest, error) {
+	req := CreateCaseRequest{Transcripts: make(map[string]string)}
+	if v := form.Value["ground_truth"]; len(v) > 0 {
+		req.GroundTruth = v[0]
+	}
+	for name, v := range form.Value {
+		if p, ok := strings.CutPrefix(name, "transcript."); ok && len(v) > 0 {
+			req.Transcripts[p] = v[0]
+		}
+	}
+	for name, fhs := range form.File {
+		p, ok := strings.CutPrefix(name, "transcript.")
+		if !ok || len(fhs) == 0 {
+			continue
+		}
+		f, err := fhs[0].Open()
+		if err != nil {
+			return req, err
+		}
+		b, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return req, err
+		}
+		req.Transcripts[p] = string(b)
+	}
+
+	fhs := form.File["audio"]
+	if len(fhs) == 0 {
+		return req, fmt.Errorf("audio is required")
+	}
+	f, err := fhs[0].Open()
+	if err != nil {
+		return req, err
+	}
+	req.Audio = f
+	return req, nil
+}
+
+// handleGetAudioInfo handles GET /api/cases/{id}/audio-info
+func (s *Service) handleGetAudioInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := s.AudioInfo(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// handleGetStream handles GET /api/cases/{id}/streams/{provider}
+func (s *Service) handleGetStream(w http.ResponseWriter, r *http.Request) {
+	stream, err := s.GetStream(r.Context(), r.PathValue("id"), r.PathValue("provider"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stream)
+}
+
+// handleUpdateCorrection handles POST /api/cases/{id}/transcripts/{provider}
+func (s *Service) handleUpdateCorrection(w http.ResponseWriter, r *http.Request) {
+	var req UpdateCorrectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+	req.Provider = r.PathValue("provider")
+
+	updated, err := s.UpdateCorrection(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// handleDiff handles GET /api/cases/{id}/diff?provider=&against=
+func (s *Service) handleDiff(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	resp, err := s.Diff(r.Context(), DiffRequest{
+		ID:       r.PathValue("id"),
+		Provider: q.Get("provider"),
+		Against:  q.Get("against"),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleListReports handles GET /api/cases/{id}/reports
+func (s *Service) handleListReports(w http.ResponseWriter, r *http.Request) {
+	versions, err := s.ListReports(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// handleListContexts handles GET /api/cases/{id}/contexts
+func (s *Service) handleListContexts(w http.ResponseWriter, r *http.Request) {
+	versions, err := s.ListContexts(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// handleGetContextVersion handles GET /api/cases/{id}/contexts/{version}
+func (s *Service) handleGetContextVersion(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil {
+		http.Error(w, "invalid version", http.StatusBadRequest)
+		return
+	}
+	c, err := s.GetContextVersion(r.Context(), r.PathValue("id"), n)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// handleListComments handles GET /api/cases/{id}/comments
+func (s *Service) handleListComments(w http.ResponseWriter, r *http.Request) {
+	comments, err := s.ListComments(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+// handleCreateComment handles POST /api/cases/{id}/comments
+func (s *Service) handleCreateComment(w http.ResponseWriter, r *http.Request) {
+	var req CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	comment, err := s.CreateComment(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// handleDeleteComment handles DELETE /api/cases/{id}/comments/{comment}
+func (s *Service) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
+	err := s.DeleteComment(r.Context(), r.PathValue("id"), r.PathValue("comment"))
+	if errors.Is(err, errNotAuthor) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteCase handles DELETE /api/cases/{id}
+func (s *Service) handleDeleteCase(w http.ResponseWriter, r *http.Request) {
+	validateOnly, err := parseBoolParam(r.URL.Query(), "validate_only")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := DeleteCaseRequest{ID: r.PathValue("id")}
+	if validateOnly != nil {
+		req.ValidateOnly = *validateOnly
+	}
+
+	resp, err := s.DeleteCase(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
 // handleUpdateCaseOps dispatches custom POST methods
 func (s *Service) handleUpdateCaseOps(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
@@ -61,30 +413,112 @@ func (s *Service) handleUpdateCaseOps(w http.ResponseWriter, r *http.Request) {
 		s.handleGenerateContext(w, r)
 	case "updateContext":
 		s.handleUpdateContext(w, r)
+	case "alignContext":
+		s.handleAlignContext(w, r)
+	case "updateTags":
+		s.handleUpdateTags(w, r)
+	case "rollbackReport":
+		s.handleRollbackReport(w, r)
+	case "resetResults":
+		s.handleResetResults(w, r)
+	case "restoreContext":
+		s.handleRestoreContext(w, r)
+	case "transcribe":
+		s.handleTranscribe(w, r)
+	case "overrideCheckpoint":
+		s.handleOverrideCheckpoint(w, r)
+	case "updateReview":
+		s.handleUpdateReview(w, r)
+	case "updateChannels":
+		s.handleUpdateChannels(w, r)
+	case "runPipeline":
+		s.handleRunPipeline(w, r)
 	default:
 		http.Error(w, "Unknown method", http.StatusNotFound)
 	}
 }
 
-// handleEvaluateCase handles POST /api/cases/{id}:evaluate
-func (s *Service) handleEvaluateCase(w http.ResponseWriter, r *http.Request) {
-	var req EvaluateRequest
+// handleUpdateTags handles POST /api/cases/{id}:updateTags
+func (s *Service) handleUpdateTags(w http.ResponseWriter, r *http.Request) {
+	var req UpdateTagsRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	req.ID = r.PathValue("id")
 
-	report, err := s.Evaluate(r.Context(), req)
+	updated, err := s.UpdateTags(r.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// handleRollbackReport handles POST /api/cases/{id}:rollbackReport
+func (s *Service) handleRollbackReport(w http.ResponseWriter, r *http.Request) {
+	var req RollbackReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	report, err := s.RollbackReport(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(report)
 }
 
+// handleRestoreContext handles POST /api/cases/{id}:restoreContext
+func (s *Service) handleRestoreContext(w http.ResponseWriter, r *http.Request) {
+	var req RestoreContextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	updated, err := s.RestoreContext(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// handleEvaluateCase handles POST /api/cases/{id}:evaluate
+// It queues a job and returns it; poll GET /api/jobs/{id} for the report.
+// With "Accept: text/event-stream" it instead runs inline and streams
+// progress as Server-Sent Events.
+func (s *Service) handleEvaluateCase(w http.ResponseWriter, r *http.Request) {
+	var req EvaluateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	if wantsEventStream(r) {
+		streamResult(w, r, func(ctx context.Context) (any, error) { return s.Evaluate(ctx, req) })
+		return
+	}
+
+	job := s.Jobs.Submit(JobEvaluate, req.ID, func(ctx context.Context) (any, error) {
+		return s.Evaluate(ctx, req)
+	})
+	writeJob(w, job)
+}
+
 // handleGenerateContext handles POST /api/cases/{id}:generateContext
+// It queues a job and returns it; poll GET /api/jobs/{id} for the context.
+// With "Accept: text/event-stream" it instead runs inline and streams
+// progress as Server-Sent Events.
 func (s *Service) handleGenerateContext(w http.ResponseWriter, r *http.Request) {
 	var req GenerateContextRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -93,13 +527,147 @@ func (s *Service) handleGenerateContext(w http.ResponseWriter, r *http.Request)
 	}
 	req.ID = r.PathValue("id")
 
-	ctx, err := s.GenerateContext(r.Context(), req)
+	if wantsEventStream(r) {
+		streamResult(w, r, func(ctx context.Context) (any, error) { return s.GenerateContext(ctx, req) })
+		return
+	}
+
+	job := s.Jobs.Submit(JobGenerateContext, req.ID, func(ctx context.Context) (any, error) {
+		return s.GenerateContext(ctx, req)
+	})
+	writeJob(w, job)
+}
+
+// handleAlignContext handles POST /api/cases/{id}:alignContext
+// It queues a job and returns it, or with "Accept: text/event-stream" runs
+// inline; the result is the aligned context.
+func (s *Service) handleAlignContext(w http.ResponseWriter, r *http.Request) {
+	req := AlignContextRequest{ID: r.PathValue("id")}
+	if wantsEventStream(r) {
+		streamResult(w, r, func(ctx context.Context) (any, error) { return s.AlignContext(ctx, req) })
+		return
+	}
+
+	job := s.Jobs.Submit(JobAlignContext, req.ID, func(ctx context.Context) (any, error) {
+		return s.AlignContext(ctx, req)
+	})
+	writeJob(w, job)
+}
+
+// handleRunPipeline handles POST /api/cases/{id}:runPipeline
+// It queues one job that generates and saves a context if needed, then
+// evaluates; poll GET /api/jobs/{id} for the report. Progress carries the
+// current step. With "Accept: text/event-stream" it instead runs inline and
+// streams progress as Server-Sent Events.
+func (s *Service) handleRunPipeline(w http.ResponseWriter, r *http.Request) {
+	var req RunPipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	if wantsEventStream(r) {
+		streamResult(w, r, func(ctx context.Context) (any, error) { return s.RunPipeline(ctx, req) })
+		return
+	}
+
+	job := s.Jobs.Submit(JobRunPipeline, req.ID, func(ctx context.Context) (any, error) {
+		return s.RunPipeline(ctx, req)
+	})
+	writeJob(w, job)
+}
+
+// handleOverrideCheckpoint handles POST /api/cases/{id}:overrideCheckpoint
+func (s *Service) handleOverrideCheckpoint(w http.ResponseWriter, r *http.Request) {
+	var req OverrideCheckpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	report, err := s.OverrideCheckpoint(r.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleResetResults handles POST /api/cases/{id}:resetResults
+func (s *Service) handleResetResults(w http.ResponseWriter, r *http.Request) {
+	var req ResetResultsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	report, err := s.ResetResults(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleUpdateReview handles POST /api/cases/{id}:updateReview
+func (s *Service) handleUpdateReview(w http.ResponseWriter, r *http.Request) {
+	var req UpdateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	updated, err := s.UpdateReview(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// handleUpdateChannels handles POST /api/cases/{id}:updateChannels
+func (s *Service) handleUpdateChannels(w http.ResponseWriter, r *http.Request) {
+	var req UpdateChannelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+
+	updated, err := s.UpdateChannels(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ctx)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// handleTranscribe handles POST /api/cases/{id}:transcribe
+// It queues a job and returns it; poll GET /api/jobs/{id} for the case.
+func (s *Service) handleTranscribe(w http.ResponseWriter, r *http.Request) {
+	var req TranscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.ID = r.PathValue("id")
+	if !providerIDPattern.MatchString(req.Provider) {
+		http.Error(w, fmt.Sprintf("invalid provider ID: %q", req.Provider), http.StatusBadRequest)
+		return
+	}
+
+	job := s.Jobs.Submit(JobTranscribe, req.ID, func(ctx context.Context) (any, error) {
+		return s.Transcribe(ctx, req)
+	})
+	writeJob(w, job)
 }
 
 // handleUpdateContext handles POST /api/cases/{id}:updateContext
@@ -120,11 +688,418 @@ func (s *Service) handleUpdateContext(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(updated)
 }
 
+// handleListStale handles GET /api/cases:stale
+// It accepts the same filters as GET /api/cases.
+func (s *Service) handleListStale(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCaseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := s.ListStale(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleListDuplicates handles GET /api/cases:duplicates. Repeated id
+// parameters report only the clusters of those cases; min_similarity
+// overrides the default share of fingerprint bits.
+func (s *Service) handleListDuplicates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := DuplicatesRequest{IDs: q["id"]}
+	if v := q.Get("min_similarity"); v != "" {
+		var err error
+		if req.MinSimilarity, err = strconv.ParseFloat(v, 64); err != nil || req.MinSimilarity <= 0 || req.MinSimilarity > 1 {
+			http.Error(w, "invalid min_similarity: "+v, http.StatusBadRequest)
+			return
+		}
+	}
+	resp, err := s.FindDuplicates(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleListAudioIssues handles GET /api/cases:audioIssues. Repeated id
+// parameters report only those cases.
+func (s *Service) handleListAudioIssues(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.AudioIssues(r.Context(), AudioIssuesRequest{IDs: r.URL.Query()["id"]})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleEvaluateAll handles POST /api/cases:evaluateAll
+func (s *Service) handleEvaluateAll(w http.ResponseWriter, r *http.Request) {
+	var req EvaluateAllRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := s.EvaluateAll(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleListRuns handles GET /api/runs
+func (s *Service) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := s.ListRuns(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// handleCreateRun handles POST /api/runs
+// It queues the run's evaluations and returns their jobs.
+func (s *Service) handleCreateRun(w http.ResponseWriter, r *http.Request) {
+	var req CreateRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := s.CreateRun(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetRun handles GET /api/runs/{id}
+func (s *Service) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	run, err := s.GetRun(r.Context(), r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// handleCompareRuns handles GET /api/runs:compare?a=&b=&threshold=
+func (s *Service) handleCompareRuns(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := CompareRunsRequest{A: q.Get("a"), B: q.Get("b")}
+	if v := q.Get("threshold"); v != "" {
+		var err error
+		if req.Threshold, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "invalid threshold: "+v, http.StatusBadRequest)
+			return
+		}
+	}
+	resp, err := s.CompareRuns(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleLeaderboard handles GET /api/stats/leaderboard
+// It accepts the same filters as GET /api/cases, and If-None-Match.
+func (s *Service) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCaseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.checkETag(w, r) {
+		return
+	}
+	stats, err := s.Leaderboard(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// handleHeadToHead handles GET /api/stats/head-to-head?a=&b=
+// It accepts the same filters as GET /api/cases, plus top.
+func (s *Service) handleHeadToHead(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := parseCaseFilter(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := HeadToHeadRequest{CaseFilter: filter, A: q.Get("a"), B: q.Get("b")}
+	if v := q.Get("top"); v != "" {
+		if req.Top, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "invalid top: "+v, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.A == "" || req.B == "" || req.A == req.B {
+		http.Error(w, "a and b must name two different providers", http.StatusBadRequest)
+		return
+	}
+	resp, err := s.HeadToHead(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleCoverage handles GET /api/stats/coverage
+// It accepts the same filters as GET /api/cases, and If-None-Match.
+func (s *Service) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCaseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.checkETag(w, r) {
+		return
+	}
+	resp, err := s.Coverage(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleExport handles GET /api/export?format=csv|xlsx|html|hf
+// It accepts the same filters as GET /api/cases.
+func (s *Service) handleExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = exportCSV
+	}
+	if format != exportCSV && format != exportXLSX && format != exportHTML && format != exportHF {
+		http.Error(w, "unknown format: "+format, http.StatusBadRequest)
+		return
+	}
+	filter, err := parseCaseFilter(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == exportHTML {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="asr-eval-report.zip"`)
+		if err := s.ExportHTML(r.Context(), w, filter); err != nil {
+			slog.Error("Failed to write export", "format", format, "error", err)
+		}
+		return
+	}
+	if format == exportHF {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="asr-eval-hf.zip"`)
+		if err := s.ExportHF(r.Context(), w, ExportHFRequest{Filter: filter}); err != nil {
+			slog.Error("Failed to write export", "format", format, "error", err)
+		}
+		return
+	}
+	rows, err := s.exportRows(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := "asr-eval." + format
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	switch format {
+	case exportXLSX:
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = writeXLSX(w, "metrics", exportHeader(), rows)
+	default:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = writeExportCSV(w, exportHeader(), rows)
+	}
+	if err != nil {
+		slog.Error("Failed to write export", "format", format, "error", err)
+	}
+}
+
+// handleExportBundle handles GET /api/export/bundle?id=&audio=
+// Without id, it accepts the same filters as GET /api/cases.
+func (s *Service) handleExportBundle(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := parseCaseFilter(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	audio, err := parseBoolParam(q, "audio")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := ExportBundleRequest{CaseFilter: filter, IDs: q["id"], Audio: audio != nil && *audio}
+	if _, err := s.bundleCaseIDs(r.Context(), req); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="asr-eval-bundle.zip"`)
+	if err := s.ExportBundle(r.Context(), w, req); err != nil {
+		slog.Error("Failed to write bundle", "error", err)
+	}
+}
+
+// handleImportBundle handles POST /api/import/bundle?overwrite=
+// The body is a zip written by GET /api/export/bundle.
+func (s *Service) handleImportBundle(w http.ResponseWriter, r *http.Request) {
+	overwrite, err := parseBoolParam(r.URL.Query(), "overwrite")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// zip needs random access, so spool the body to disk first.
+	tmp, err := os.CreateTemp("", "asr-eval-bundle-*.zip")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	size, err := io.Copy(tmp, http.MaxBytesReader(w, r.Body, maxBundleBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.ImportBundle(r.Context(), tmp, size, ImportBundleRequest{Overwrite: overwrite != nil && *overwrite})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleListAudit handles GET /api/audit
+func (s *Service) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := ListAuditRequest{
+		CaseID: q.Get("case_id"),
+		User:   q.Get("user"),
+		Action: q.Get("action"),
+	}
+	limit, err := parseIntParam(q, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit != nil {
+		req.Limit = *limit
+	}
+
+	entries, err := s.ListAudit(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// handleUpdateProviders handles PUT /api/config/providers
+func (s *Service) handleUpdateProviders(w http.ResponseWriter, r *http.Request) {
+	var req UpdateProvidersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.UpdateProviders(r.Context(), req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.config())
+}
+
+// handleListJobs handles GET /api/jobs?case_id=&batch_id=
+func (s *Service) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs := s.Jobs.List(r.URL.Query().Get("case_id"))
+	if batchID := r.URL.Query().Get("batch_id"); batchID != "" {
+		jobs = slices.DeleteFunc(jobs, func(j *Job) bool { return j.BatchID != batchID })
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// handleGetJob handles GET /api/jobs/{id}
+func (s *Service) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.Jobs.Get(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleJobOps dispatches custom POST methods on jobs
+func (s *Service) handleJobOps(w http.ResponseWriter, r *http.Request) {
+	id, op, _ := strings.Cut(r.PathValue("id"), ":")
+	switch op {
+	case "cancel":
+		if err := s.Jobs.Cancel(id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		job, _ := s.Jobs.Get(id)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	default:
+		http.Error(w, "Unknown method", http.StatusNotFound)
+	}
+}
+
+// writeJob responds with 202 Accepted and the queued job.
+func writeJob(w http.ResponseWriter, job *Job) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
 func (s *Service) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config()
+	cfg.Role = RoleFromContext(r.Context())
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(Config{
+	json.NewEncoder(w).Encode(cfg)
+}
+
+func (s *Service) config() Config {
+	return Config{
 		GenModel:         s.Config.GenModel,
 		EvalModel:        s.Config.EvalModel,
-		EnabledProviders: s.Config.EnabledProviders,
-	})
+		EnabledProviders: s.enabledProviders(),
+		SScoreWeight:     s.sScoreWeight(),
+		Pricing:          s.Config.Pricing,
+		Tags:             s.Config.Tags,
+		ASRProviders:     asr.Names(),
+	}
 }
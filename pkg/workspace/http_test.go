@@ -0,0 +1,53 @@
+package workspace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPathIDsStayInDataset(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "ds")
+	for _, name := range []string{"ds/a.flac", "ds/a.p", "x.flac"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+
+	for _, tc := range []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodGet, "/api/cases/a/comments", "", http.StatusOK},
+		{http.MethodPost, "/api/cases/a/comments", `{"body":"hi"}`, http.StatusCreated},
+		{http.MethodPost, "/api/cases/a:updateTags", `{"tags":["t"]}`, http.StatusOK},
+		{http.MethodPost, "/api/cases/a:updateReview", `{"state":"in_review","assignee":"bob"}`, http.StatusOK},
+		{http.MethodPost, "/api/cases/a/transcripts/p", `{"text":"t"}`, http.StatusOK},
+		{http.MethodGet, "/api/cases/..%2Fx/comments", "", http.StatusBadRequest},
+		{http.MethodPost, "/api/cases/..%2Fx/comments", `{"body":"hi"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/cases/..%2Fx:updateTags", `{"tags":["t"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/cases/..%2Fx:updateReview", `{"state":"in_review","assignee":"bob"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/cases/..%2F..%2Fx/transcripts/p", `{"text":"t"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/cases/a/transcripts/..%2Fp", `{"text":"t"}`, http.StatusBadRequest},
+	} {
+		r := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, r)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s = %d %q, want %d", tc.method, tc.target, rec.Code, rec.Body, tc.want)
+		}
+	}
+	if entries, _ := os.ReadDir(root); len(entries) != 2 {
+		t.Errorf("files outside the dataset were written: %v", entries)
+	}
+}
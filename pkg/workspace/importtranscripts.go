@@ -0,0 +1,151 @@
+package workspace
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TranscriptRow is a provider's transcript of a case from outside the
+// dataset, such as a vendor console export.
+type TranscriptRow struct {
+	ID       string
+	Provider string
+	Text     string
+}
+
+// ReadTranscriptCSV reads rows of id, provider and text. A first row of
+// exactly "id,provider,text" is taken as a header and skipped.
+func ReadTranscriptCSV(r io.Reader) ([]TranscriptRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	var rows []TranscriptRow
+	for first := true; ; first = false {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			rec[0] = strings.TrimPrefix(rec[0], "\ufeff")
+			if strings.Join(rec, ",") == "id,provider,text" {
+				continue
+			}
+		}
+		rows = append(rows, TranscriptRow{
+			ID:       strings.TrimSpace(rec[0]),
+			Provider: strings.TrimSpace(rec[1]),
+			Text:     strings.TrimSpace(rec[2]),
+		})
+	}
+}
+
+// ImportTranscriptsRequest holds the transcripts to import.
+type ImportTranscriptsRequest struct {
+	Rows      []TranscriptRow
+	Source    string // Recorded in the audit log, e.g. the CSV file name
+	Overwrite bool   // Replace existing transcripts instead of skipping them
+	DryRun    bool   // Only validate and count the rows
+}
+
+// ImportTranscriptsResponse counts what ImportTranscripts did.
+type ImportTranscriptsResponse struct {
+	Imported int
+	Skipped  []string // "id.provider" of transcripts that already exist
+	Failed   []string // Rows that could not be imported, with the reason
+}
+
+// ImportTranscripts writes each row as the provider's transcript of the
+// case, as if the provider had transcribed it, removing any stream dump
+// and chunk or trim sidecar the provider left. The case audio must exist;
+// rows of unknown cases, invalid providers, empty text or repeating an
+// earlier row fail.
+func (s *Service) ImportTranscripts(ctx context.Context, req ImportTranscriptsRequest) (*ImportTranscriptsResponse, error) {
+	resp := &ImportTranscriptsResponse{Skipped: []string{}, Failed: []string{}}
+	existing := make(map[string]map[string]string)
+	seen := make(map[string]bool)
+	for i, row := range req.Rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name := row.ID + "." + row.Provider
+		fail := func(reason string) {
+			resp.Failed = append(resp.Failed, fmt.Sprintf("row %d: %s: %s", i+1, name, reason))
+		}
+		switch {
+		case row.ID == "" || strings.ContainsAny(row.ID, `/\`) || strings.HasPrefix(row.ID, "."):
+			fail("invalid case ID")
+			continue
+		case !providerIDPattern.MatchString(row.Provider) || "."+row.Provider == extFlac:
+			fail("invalid provider ID")
+			continue
+		case row.Text == "":
+			fail("empty transcript")
+			continue
+		case seen[name]:
+			fail("repeats an earlier row")
+			continue
+		}
+		seen[name] = true
+
+		transcripts, ok := existing[row.ID]
+		if !ok {
+			if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, row.ID+extFlac)); err != nil {
+				fail("no such case")
+				continue
+			}
+			c, err := s.Storage.GetCase(ctx, row.ID)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			if c != nil {
+				transcripts = c.Transcripts
+			}
+			existing[row.ID] = transcripts
+		}
+		if _, ok := transcripts[row.Provider]; ok && !req.Overwrite {
+			resp.Skipped = append(resp.Skipped, name)
+			continue
+		}
+		if req.DryRun {
+			resp.Imported++
+			continue
+		}
+		if err := s.importTranscript(ctx, row, req.Source); err != nil {
+			fail(err.Error())
+			continue
+		}
+		resp.Imported++
+	}
+	return resp, nil
+}
+
+func (s *Service) importTranscript(ctx context.Context, row TranscriptRow, source string) error {
+	unlock, err := s.lockCase(ctx, row.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := s.Storage.PutTranscript(ctx, row.ID, row.Provider, row.Text); err != nil {
+		return err
+	}
+	stream := filepath.Join(s.Config.DatasetDir, row.ID+"."+row.Provider+extStream)
+	if err := writeStream(stream, nil); err != nil {
+		return err
+	}
+	if err := writeChunks(filepath.Join(s.Config.DatasetDir, row.ID+"."+row.Provider+extChunks), nil); err != nil {
+		return err
+	}
+	if err := writeCuts(filepath.Join(s.Config.DatasetDir, row.ID+"."+row.Provider+extTrim), nil); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditTranscribe, row.ID, map[string]string{"provider": row.Provider, "source": source})
+	return nil
+}
@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestImportTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.flac": "", "a.x": "old x", "a.x.stream.json": "{}", "b.flac": ""} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+
+	rows, err := ReadTranscriptCSV(strings.NewReader("\ufeffid,provider,text\na,x,new x\na,y,\" y, quoted \"\nb,y,b y\nb,y,again\nc,y,no case\nb,Y,bad provider\nb,z,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 7 || rows[1].Text != "y, quoted" {
+		t.Fatalf("rows = %+v, want 7 with the quoted text trimmed", rows)
+	}
+
+	resp, err := s.ImportTranscripts(ctx, ImportTranscriptsRequest{Rows: rows})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ImportTranscriptsResponse{
+		Imported: 2,
+		Skipped:  []string{"a.x"},
+		Failed: []string{
+			"row 4: b.y: repeats an earlier row",
+			"row 5: c.y: no such case",
+			"row 6: b.Y: invalid provider ID",
+			"row 7: b.z: empty transcript",
+		},
+	}
+	if diff := cmp.Diff(want, resp); diff != "" {
+		t.Errorf("response mismatch (-want +got):\n%s", diff)
+	}
+	c, err := s.Storage.GetCase(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"x": "old x", "y": "y, quoted"}, c.Transcripts); diff != "" {
+		t.Errorf("transcripts of a mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := s.ImportTranscripts(ctx, ImportTranscriptsRequest{Rows: rows[:1], Overwrite: true}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "a.x")); string(b) != "new x" {
+		t.Errorf("a.x = %q after overwrite, want new x", b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.x.stream.json")); !os.IsNotExist(err) {
+		t.Errorf("stream dump of x still exists: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// summaryExts are the files a summary Case is built from. A case exists
+// when its extFlac file does.
+var summaryExts = []string{extFlac, extReportV2, extGTV2, extTags, extReview}
+
+// dirWatcher reports changes to the files in a directory.
+type dirWatcher interface {
+	// changes returns the names of the files changed since the last call,
+	// without blocking. all means changes were lost and everything must be
+	// reread.
+	changes() (names []string, all bool, err error)
+	close() error
+}
+
+// errWatchLost means the index can no longer tell what changed.
+var errWatchLost = errors.New("lost watch on dataset")
+
+// caseIndex keeps the summary cases of a dataset in memory, so listing
+// them does not reread every report. The watcher's events are applied on
+// each read, which also picks up writes made by this process a moment ago.
+type caseIndex struct {
+	w dirWatcher
+
+	mu    sync.Mutex
+	cases map[string]*Case // nil until the first full scan
+	dirty map[string]bool  // Case IDs to reload
+
+	onChange func(Event) // Called with x.mu held; must not block
+}
+
+// newCaseIndex watches dir, or returns an error when the platform cannot.
+func newCaseIndex(dir string) (*caseIndex, error) {
+	w, err := watchDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &caseIndex{w: w, dirty: make(map[string]bool)}, nil
+}
+
+// list returns copies of the indexed cases sorted by ID, loading what
+// changed since the last call.
+func (x *caseIndex) list(ctx context.Context, s *Service) ([]*Case, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.drain(); err != nil {
+		return nil, err
+	}
+	if x.cases == nil {
+		cases, err := s.readCases(ctx)
+		if err != nil {
+			return nil, err
+		}
+		x.cases = make(map[string]*Case, len(cases))
+		for _, c := range cases {
+			x.cases[c.ID] = c
+		}
+		clear(x.dirty)
+	}
+	for id := range x.dirty {
+		if c := s.loadSummary(ctx, id); c != nil {
+			x.cases[id] = c
+		} else {
+			delete(x.cases, id)
+		}
+		delete(x.dirty, id)
+	}
+
+	results := make([]*Case, 0, len(x.cases))
+	for _, c := range x.cases {
+		cp := *c // Callers may modify what they get
+		results = append(results, &cp)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results, nil
+}
+
+// sync applies pending changes without loading anything, so they are
+// reported to onChange promptly.
+func (x *caseIndex) sync() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.drain()
+}
+
+// drain marks the cases with pending changes dirty. Callers must hold x.mu.
+func (x *caseIndex) drain() error {
+	if x.w == nil {
+		return fmt.Errorf("%w: closed", errWatchLost)
+	}
+	names, all, err := x.w.changes()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errWatchLost, err)
+	}
+	if all {
+		x.cases = nil
+		if x.onChange != nil {
+			x.onChange(Event{Type: EventReset})
+		}
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		id, ext, ok := summaryFile(name)
+		if !ok {
+			continue
+		}
+		x.dirty[id] = true
+		if x.onChange != nil && !seen[name] {
+			seen[name] = true
+			x.onChange(Event{Type: EventCase, CaseID: id, File: eventFiles[ext]})
+		}
+	}
+	return nil
+}
+
+func (x *caseIndex) close() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.w == nil {
+		return nil
+	}
+	err := x.w.close()
+	x.w = nil
+	return err
+}
+
+// summaryFile returns the case a summary file belongs to, and its kind.
+func summaryFile(name string) (id, ext string, ok bool) {
+	for _, ext := range summaryExts {
+		if id, ok := strings.CutSuffix(name, ext); ok {
+			return id, ext, true
+		}
+	}
+	return "", "", false
+}
+
+// statSummary returns which of id's summary files exist.
+func statSummary(dir, id string) map[string]bool {
+	exts := make(map[string]bool)
+	for _, ext := range summaryExts {
+		if _, err := os.Stat(filepath.Join(dir, id+ext)); err == nil {
+			exts[ext] = true
+		}
+	}
+	return exts
+}
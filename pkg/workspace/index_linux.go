@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_ATTRIB |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// inotifyWatcher is a non-blocking inotify watch on one directory. Events
+// queue in the kernel until changes reads them.
+type inotifyWatcher struct {
+	fd  int
+	buf []byte
+}
+
+func watchDir(dir string) (dirWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	if _, err := unix.InotifyAddWatch(fd, dir, inotifyMask); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("inotify %s: %w", dir, err)
+	}
+	return &inotifyWatcher{fd: fd, buf: make([]byte, 64<<10)}, nil
+}
+
+func (w *inotifyWatcher) changes() ([]string, bool, error) {
+	var names []string
+	all := false
+	for {
+		n, err := unix.Read(w.fd, w.buf)
+		if errors.Is(err, unix.EAGAIN) {
+			return names, all, nil
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("inotify: %w", err)
+		}
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			ev := (*unix.InotifyEvent)(unsafe.Pointer(&w.buf[off]))
+			off += unix.SizeofInotifyEvent
+			name := w.buf[off : off+int(ev.Len)]
+			off += int(ev.Len)
+
+			switch {
+			case ev.Mask&(unix.IN_DELETE_SELF|unix.IN_MOVE_SELF) != 0:
+				return nil, false, fmt.Errorf("dataset directory moved or deleted")
+			case ev.Mask&unix.IN_Q_OVERFLOW != 0:
+				all = true
+			case len(name) > 0:
+				names = append(names, string(bytes.TrimRight(name, "\x00")))
+			}
+		}
+	}
+}
+
+func (w *inotifyWatcher) close() error {
+	return unix.Close(w.fd)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package workspace
+
+import "errors"
+
+func watchDir(dir string) (dirWatcher, error) {
+	return nil, errors.New("watching directories is only supported on Linux")
+}
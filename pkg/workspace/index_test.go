@@ -0,0 +1,55 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCaseIndex(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("dataset watching needs inotify")
+	}
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.flac", "")
+
+	cfg := DefaultServiceConfig()
+	cfg.DatasetDir = dir
+	s := NewService(cfg, nil)
+	defer s.Close()
+	if s.index.Load() == nil {
+		t.Fatal("dataset not watched")
+	}
+
+	ctx := context.Background()
+	check := func(want []*Case) {
+		t.Helper()
+		got, err := s.scanCases(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("scanCases mismatch (-want +got):\n%s", diff)
+		}
+	}
+	check([]*Case{{ID: "a"}})
+
+	// Written behind the service's back.
+	write("b.flac", "")
+	write("a.tags.json", `["x"]`)
+	check([]*Case{{ID: "a", Tags: []string{"x"}}, {ID: "b"}})
+
+	if err := os.Remove(filepath.Join(dir, "a.flac")); err != nil {
+		t.Fatal(err)
+	}
+	check([]*Case{{ID: "b"}})
+}
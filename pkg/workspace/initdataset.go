@@ -0,0 +1,87 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// datasetConfigTemplate is the dataset.yaml InitDataset writes: every
+// setting, commented out, so the file documents what can be overridden.
+const datasetConfigTemplate = `# Settings of this dataset. Set fields override the server-wide
+# configuration (asr-eval.yaml and flags) for this dataset only.
+
+# LLMs generating eval contexts and evaluating transcripts.
+# gen_model: gemini-3-pro-preview
+# eval_model: gemini-3-flash-preview
+# fallback_models: []
+# judge_models: []
+
+# Providers evaluated and ranked; the others are ignored.
+# enabled_providers:
+#   volc2_ctx_rt: true
+#   qwen_ctx_rt: true
+
+# Weight of S in Q = S^w * P^(1-w).
+# scoring:
+#   s_score_weight: 0.7
+
+# Allowed case tags; empty allows any.
+# tags: []
+
+# Spend per provider and per LLM, for cost reports.
+# pricing:
+#   volc2_ctx_rt: {per_hour: 1.2, currency: USD}
+# model_pricing:
+#   gemini-3-flash-preview: {input_per_mtok: 0.5, output_per_mtok: 3}
+
+# Where to report finished evaluations: slack or feishu.
+# webhook:
+#   url: https://hooks.slack.com/services/...
+#   format: slack
+
+# Transcribe audio longer than seconds in chunks, each sharing
+# overlap_seconds with the next, concurrency chunks at a time.
+# chunking:
+#   seconds: 300
+#   overlap_seconds: 5
+#   concurrency: 4
+
+# Sample rate of the dataset's audio; recordings at another rate are
+# left out of provider comparisons. Defaults to the most common rate.
+# sample_rate: 16000
+
+# Storage layout.
+# compress_reports: false
+# case_bundles: false
+`
+
+// InitDataset creates the dataset directory dir with a starter
+// dataset.yaml. Existing files are left untouched, so it can run on a
+// directory that already holds audio. It returns the paths it created.
+func InitDataset(dir string) ([]string, error) {
+	var created []string
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		created = append(created, dir)
+	}
+	for _, d := range []string{dir, filepath.Join(dir, contextsDirName)} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, err
+		}
+	}
+	path := filepath.Join(dir, datasetConfigFileName)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return created, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString(datasetConfigTemplate); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return append(created, path), nil
+}
@@ -0,0 +1,37 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitDataset(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ds")
+	created, err := InitDataset(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 {
+		t.Errorf("created = %v, want the directory and dataset.yaml", created)
+	}
+	// The template is all comments, so it overrides nothing.
+	dc, err := LoadDatasetConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc == nil || dc.GenModel != "" || dc.EnabledProviders != nil {
+		t.Errorf("dataset config = %+v, want an empty one", dc)
+	}
+
+	path := filepath.Join(dir, datasetConfigFileName)
+	if err := os.WriteFile(path, []byte("gen_model: x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if created, err := InitDataset(dir); err != nil || len(created) != 0 {
+		t.Errorf("InitDataset again = %v, %v, want nothing created", created, err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "gen_model: x\n" {
+		t.Errorf("dataset.yaml = %q, want it untouched", b)
+	}
+}
@@ -0,0 +1,389 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/evalv2"
+)
+
+const jobsDirName = ".jobs"
+
+// Finished jobs are kept for jobRetention, and at most maxFinishedJobs of
+// them, since their records carry full results.
+const (
+	jobRetention    = 7 * 24 * time.Hour
+	maxFinishedJobs = 1000
+)
+
+// JobKind identifies the operation a job runs.
+type JobKind string
+
+const (
+	JobEvaluate        JobKind = "evaluate"
+	JobGenerateContext JobKind = "generateContext"
+	JobTranscribe      JobKind = "transcribe"
+	JobRunPipeline     JobKind = "runPipeline"
+	JobAlignContext    JobKind = "alignContext"
+)
+
+// JobStatus is the lifecycle state of a job.
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// Job is an asynchronous operation on a case. Records are persisted under
+// the dataset's .jobs directory so clients can pick them up after a reload.
+type Job struct {
+	ID         string           `json:"id"`
+	Kind       JobKind          `json:"kind"`
+	CaseID     string           `json:"case_id"`
+	BatchID    string           `json:"batch_id,omitempty"` // Shared by the jobs of one POST /api/cases:evaluateAll
+	Status     JobStatus        `json:"status"`
+	Progress   *evalv2.Progress `json:"progress,omitempty"`
+	Result     json.RawMessage  `json:"result,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	StartedAt  *time.Time       `json:"started_at,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
+
+// Done reports whether the job reached a terminal state.
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// JobManager runs jobs on a bounded worker pool. Submissions never block:
+// jobs wait in an unbounded pending list until a worker is free.
+type JobManager struct {
+	dir string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	ready   *sync.Cond // Signaled when pending grows or ctx is done
+	pending []*jobTask
+	jobs    map[string]*Job
+	cancels map[string]context.CancelFunc
+
+	onUpdate func(*Job) // Called with each new or changed record, if set
+}
+
+type jobTask struct {
+	job *Job
+	run func(ctx context.Context) (any, error)
+}
+
+// NewJobManager loads persisted job records from dir and starts workers.
+// Jobs that were still pending when the previous process exited are marked
+// failed, and records past their retention are deleted.
+func NewJobManager(dir string, workers int) *JobManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &JobManager{
+		dir:     dir,
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(map[string]*Job),
+		cancels: make(map[string]context.CancelFunc),
+	}
+	m.ready = sync.NewCond(&m.mu)
+	m.load()
+	m.mu.Lock()
+	m.prune()
+	m.mu.Unlock()
+	for i := 0; i < max(workers, 1); i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+	return m
+}
+
+// Submit queues run as a new job and returns a snapshot of its record.
+func (m *JobManager) Submit(kind JobKind, caseID string, run func(ctx context.Context) (any, error)) *Job {
+	return m.SubmitBatch("", kind, caseID, run)
+}
+
+// SubmitBatch is Submit for a job that is part of batch batchID.
+func (m *JobManager) SubmitBatch(batchID string, kind JobKind, caseID string, run func(ctx context.Context) (any, error)) *Job {
+	job := &Job{
+		ID:        uuid.NewString(),
+		Kind:      kind,
+		CaseID:    caseID,
+		BatchID:   batchID,
+		Status:    JobQueued,
+		CreatedAt: time.Now(),
+	}
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	if err := m.ctx.Err(); err != nil {
+		// Shutting down; the workers are gone.
+		snapshot := m.finish(job, nil, err)
+		m.mu.Unlock()
+		m.update(&snapshot)
+		return &snapshot
+	}
+	m.pending = append(m.pending, &jobTask{job: job, run: run})
+	m.ready.Signal()
+	snapshot := *job
+	m.mu.Unlock()
+	m.update(&snapshot)
+	return &snapshot
+}
+
+// Get returns a snapshot of the job with id.
+func (m *JobManager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *job
+	return &snapshot, true
+}
+
+// List returns snapshots of all jobs, newest first. A non-empty caseID
+// restricts the list to that case.
+func (m *JobManager) List(caseID string) []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*Job
+	for _, job := range m.jobs {
+		if caseID != "" && job.CaseID != caseID {
+			continue
+		}
+		snapshot := *job
+		out = append(out, &snapshot)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	return out
+}
+
+// Cancel stops a queued or running job.
+func (m *JobManager) Cancel(id string) error {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("job not found: %s", id)
+	}
+	if job.Done() {
+		m.mu.Unlock()
+		return nil
+	}
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+		m.mu.Unlock()
+		return nil
+	}
+	// Still queued: the worker will skip it.
+	snapshot := m.finish(job, nil, context.Canceled)
+	m.mu.Unlock()
+	m.update(&snapshot)
+	return nil
+}
+
+// Close cancels running jobs and waits for the workers to exit. Jobs still
+// queued are left for the next start to mark as interrupted.
+func (m *JobManager) Close() {
+	m.cancel()
+	m.mu.Lock()
+	m.ready.Broadcast()
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+func (m *JobManager) worker() {
+	defer m.wg.Done()
+	for {
+		m.mu.Lock()
+		for len(m.pending) == 0 && m.ctx.Err() == nil {
+			m.ready.Wait()
+		}
+		if m.ctx.Err() != nil {
+			m.mu.Unlock()
+			return
+		}
+		t := m.pending[0]
+		m.pending[0] = nil
+		m.pending = m.pending[1:]
+		m.mu.Unlock()
+		m.runTask(t)
+	}
+}
+
+func (m *JobManager) runTask(t *jobTask) {
+	m.mu.Lock()
+	if t.job.Done() {
+		m.mu.Unlock()
+		return
+	}
+	if err := m.ctx.Err(); err != nil {
+		snapshot := m.finish(t.job, nil, err)
+		m.mu.Unlock()
+		m.update(&snapshot)
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+	m.cancels[t.job.ID] = cancel
+	now := time.Now()
+	t.job.Status = JobRunning
+	t.job.StartedAt = &now
+	snapshot := *t.job
+	m.mu.Unlock()
+	m.update(&snapshot)
+
+	ctx = evalv2.WithProgress(ctx, func(p evalv2.Progress) {
+		m.mu.Lock()
+		t.job.Progress = &p
+		m.mu.Unlock()
+	})
+	res, err := t.run(ctx)
+
+	m.mu.Lock()
+	delete(m.cancels, t.job.ID)
+	snapshot = m.finish(t.job, res, err)
+	m.mu.Unlock()
+	m.update(&snapshot)
+}
+
+// prune forgets finished jobs past jobRetention, then the oldest beyond
+// maxFinishedJobs, and deletes their records. Callers must hold m.mu.
+func (m *JobManager) prune() {
+	var done []*Job
+	cutoff := time.Now().Add(-jobRetention)
+	for _, job := range m.jobs {
+		if !job.Done() {
+			continue
+		}
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			m.forget(job.ID)
+			continue
+		}
+		done = append(done, job)
+	}
+	if len(done) <= maxFinishedJobs {
+		return
+	}
+	sort.Slice(done, func(i, j int) bool {
+		return finishedAt(done[i]).After(finishedAt(done[j]))
+	})
+	for _, job := range done[maxFinishedJobs:] {
+		m.forget(job.ID)
+	}
+}
+
+func finishedAt(job *Job) time.Time {
+	if job.FinishedAt == nil {
+		return time.Time{}
+	}
+	return *job.FinishedAt
+}
+
+func (m *JobManager) forget(id string) {
+	delete(m.jobs, id)
+	if m.dir == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(m.dir, id+extJSON)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Error("Failed to delete job record", "id", id, "error", err)
+	}
+}
+
+// finish records the outcome of job and returns a snapshot to persist.
+// Callers must hold m.mu.
+func (m *JobManager) finish(job *Job, res any, err error) Job {
+	now := time.Now()
+	job.FinishedAt = &now
+	defer m.prune()
+	switch {
+	case errors.Is(err, context.Canceled):
+		job.Status = JobCanceled
+		job.Error = err.Error()
+	case err != nil:
+		job.Status = JobFailed
+		job.Error = err.Error()
+	default:
+		job.Status = JobSucceeded
+		if b, err := json.Marshal(res); err == nil {
+			job.Result = b
+		}
+	}
+	return *job
+}
+
+// update persists job and reports it to onUpdate.
+func (m *JobManager) update(job *Job) {
+	m.persist(job)
+	if m.onUpdate != nil {
+		m.onUpdate(job)
+	}
+}
+
+func (m *JobManager) persist(job *Job) {
+	if m.dir == "" {
+		return
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		slog.Error("Failed to create jobs dir", "dir", m.dir, "error", err)
+		return
+	}
+	b, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := atomicfile.WriteFile(filepath.Join(m.dir, job.ID+extJSON), b); err != nil {
+		slog.Error("Failed to persist job", "id", job.ID, "error", err)
+	}
+}
+
+func (m *JobManager) load() {
+	if m.dir == "" {
+		return
+	}
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), extJSON) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(m.dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal(content, &job); err != nil {
+			continue
+		}
+		if !job.Done() {
+			snapshot := m.finish(&job, nil, fmt.Errorf("interrupted by server restart"))
+			m.persist(&snapshot)
+		}
+		m.jobs[job.ID] = &job
+	}
+}
@@ -0,0 +1,77 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJobManagerSubmitDoesNotBlock(t *testing.T) {
+	m := NewJobManager("", 1)
+	defer m.Close()
+
+	release := make(chan struct{})
+	m.Submit(JobEvaluate, "busy", func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	})
+	submitted := make(chan struct{})
+	var last *Job
+	go func() {
+		for i := 0; i < 3000; i++ {
+			last = m.Submit(JobEvaluate, "c", func(ctx context.Context) (any, error) { return nil, nil })
+		}
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Submit blocked behind a busy worker")
+	}
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, _ := m.Get(last.ID)
+		if job.Done() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("last job still %s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJobManagerPrunesFinishedJobs(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-jobRetention - time.Hour)
+	recent := time.Now().Add(-time.Hour)
+	for _, job := range []Job{
+		{ID: "old", Status: JobSucceeded, CreatedAt: old, FinishedAt: &old},
+		{ID: "recent", Status: JobSucceeded, CreatedAt: recent, FinishedAt: &recent},
+	} {
+		b, err := json.Marshal(job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, job.ID+extJSON), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewJobManager(dir, 1)
+	defer m.Close()
+	if _, ok := m.Get("old"); ok {
+		t.Error("job past retention was loaded")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old"+extJSON)); !os.IsNotExist(err) {
+		t.Errorf("record of job past retention: %v, want deleted", err)
+	}
+	if _, ok := m.Get("recent"); !ok {
+		t.Error("recent job was pruned")
+	}
+}
@@ -0,0 +1,305 @@
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// changeJournalName is the change journal of a dataset: a line per file
+// put or deleted, in order, which sync replays onto another copy of the
+// dataset. It is written under changeLockFileName in locksDirName.
+const (
+	changeJournalName  = ".changes.jsonl"
+	changeLockFileName = ".changes.lock"
+)
+
+// Change journal operations.
+const (
+	ChangePut    = "put"
+	ChangeDelete = "delete"
+)
+
+// Change is one line of the change journal.
+type Change struct {
+	Seq     int64     `json:"seq"`
+	Op      string    `json:"op"`
+	Path    string    `json:"path"`           // Slash-separated, relative to the dataset
+	Hash    string    `json:"hash,omitempty"` // SHA-256 of the content put
+	Size    int64     `json:"size,omitempty"`
+	ModTime int64     `json:"mtime,omitempty"` // Unix nanoseconds
+	Time    time.Time `json:"time"`
+}
+
+// changeState is the change journal folded: the last put of every file
+// that exists as far as the journal knows.
+type changeState struct {
+	offset int64 // Journal bytes folded
+	seq    int64
+	files  map[string]Change
+}
+
+// journaled reports whether the dataset file rel is synced to other copies
+// of the dataset. Locks, jobs, the manifest, fingerprints, journals and
+// temp files are per copy, and so are the audit log and usage ledger, which each copy
+// appends to.
+func journaled(rel string) bool {
+	base := path.Base(rel)
+	switch {
+	case strings.HasPrefix(rel, locksDirName+"/"), strings.HasPrefix(rel, jobsDirName+"/"), strings.HasPrefix(rel, fingerprintsDirName+"/"):
+		return false
+	case rel == manifestFileName, rel == changeJournalName, rel == migrationJournalName, rel == syncStateName, rel == auditFileName, rel == usageFileName:
+		return false
+	case strings.HasPrefix(base, ".tmp-"):
+		return false
+	}
+	return true
+}
+
+// journalCase records the changes to the files of case id, and to the
+// context store entries they refer to, since they were last journaled.
+func (s *Service) journalCase(ctx context.Context, id string) {
+	entries, err := os.ReadDir(s.Config.DatasetDir)
+	if err != nil {
+		return
+	}
+	ids := map[string]bool{id: true}
+	for _, e := range entries {
+		if other, ok := strings.CutSuffix(e.Name(), extFlac); ok {
+			ids[other] = true
+		}
+	}
+	owned := func(rel string) bool {
+		owner, ok := owningCase(rel, ids)
+		return ok && owner == id && !strings.Contains(rel, "/")
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && owned(e.Name()) {
+			paths = append(paths, e.Name())
+			if refersToContexts(e.Name()) {
+				for _, hash := range fileContextRefs(filepath.Join(s.Config.DatasetDir, e.Name())) {
+					paths = append(paths, contextsDirName+"/"+hash+extJSON)
+				}
+			}
+		}
+	}
+	if err := s.journalPaths(ctx, paths, owned); err != nil {
+		slog.Error("Failed to journal case changes", "dataset", s.Config.DatasetDir, "case", id, "error", err)
+	}
+}
+
+// JournalDataset records the changes to every synced file of the dataset
+// since they were last journaled, catching up with those made while no
+// service was running, by hand or by other tools.
+func (s *Service) JournalDataset(ctx context.Context) error {
+	root := s.Config.DatasetDir
+	var paths []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == locksDirName || rel == jobsDirName || rel == fingerprintsDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && journaled(rel) {
+			paths = append(paths, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.journalPaths(ctx, paths, func(string) bool { return true })
+}
+
+// journalPaths appends a put for each of paths whose content changed since
+// its last put, and a delete for each file in scope the journal knows that
+// is gone.
+func (s *Service) journalPaths(ctx context.Context, paths []string, scope func(rel string) bool) error {
+	locks := filepath.Join(s.Config.DatasetDir, locksDirName)
+	if err := os.MkdirAll(locks, 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(ctx, filepath.Join(locks, changeLockFileName))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	s.changesMu.Lock()
+	defer s.changesMu.Unlock()
+
+	if err := s.foldChanges(); err != nil {
+		return err
+	}
+	var changes []Change
+	seen := make(map[string]bool, len(paths))
+	for _, rel := range paths {
+		if seen[rel] || !journaled(rel) {
+			continue
+		}
+		seen[rel] = true
+		p := filepath.Join(s.Config.DatasetDir, filepath.FromSlash(rel))
+		fi, err := os.Stat(p)
+		if err != nil {
+			continue // Gone already; a delete below if it was known
+		}
+		last, ok := s.changes.files[rel]
+		if ok && last.Size == fi.Size() && last.ModTime == fi.ModTime().UnixNano() {
+			continue
+		}
+		hash, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		if ok && last.Hash == hash {
+			continue
+		}
+		changes = append(changes, Change{Op: ChangePut, Path: rel, Hash: hash, Size: fi.Size(), ModTime: fi.ModTime().UnixNano()})
+	}
+	for rel := range s.changes.files {
+		if !scope(rel) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, filepath.FromSlash(rel))); os.IsNotExist(err) {
+			changes = append(changes, Change{Op: ChangeDelete, Path: rel})
+		}
+	}
+	return s.appendChanges(changes)
+}
+
+// foldChanges brings s.changes up to date with the journal, which other
+// processes append to as well. Callers hold the journal lock.
+func (s *Service) foldChanges() error {
+	if s.changes == nil {
+		s.changes = &changeState{files: make(map[string]Change)}
+	}
+	f, err := os.Open(filepath.Join(s.Config.DatasetDir, changeJournalName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err != nil {
+		return err
+	} else if fi.Size() < s.changes.offset {
+		// Truncated or replaced; start over.
+		s.changes = &changeState{files: make(map[string]Change)}
+	}
+	if _, err := f.Seek(s.changes.offset, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return nil // A partial line is read again once complete
+		}
+		if err != nil {
+			return err
+		}
+		s.changes.offset += int64(len(line))
+		var c Change
+		if json.Unmarshal(line, &c) != nil {
+			continue
+		}
+		s.changes.apply(c)
+	}
+}
+
+func (st *changeState) apply(c Change) {
+	st.seq = max(st.seq, c.Seq)
+	if c.Op == ChangeDelete {
+		delete(st.files, c.Path)
+	} else {
+		st.files[c.Path] = c
+	}
+}
+
+// appendChanges numbers changes and appends them to the journal. Callers
+// hold the journal lock with s.changes up to date.
+func (s *Service) appendChanges(changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	f, err := os.OpenFile(filepath.Join(s.Config.DatasetDir, changeJournalName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var buf []byte
+	now := time.Now().UTC()
+	for _, c := range changes {
+		c.Seq = s.changes.seq + 1
+		c.Time = now
+		b, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, b...), '\n')
+		s.changes.apply(c)
+	}
+	if _, err := f.Write(buf); err != nil {
+		return err
+	}
+	s.changes.offset += int64(len(buf))
+	return nil
+}
+
+// ReadChanges returns the journal entries after seq, in order.
+func (s *Service) ReadChanges(seq int64) ([]Change, error) {
+	f, err := os.Open(filepath.Join(s.Config.DatasetDir, changeJournalName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []Change
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var c Change
+		if json.Unmarshal(sc.Bytes(), &c) != nil || c.Seq <= seq {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out, sc.Err()
+}
+
+func hashFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,215 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// bootstrapSamples is how many resamples of the cases estimate a
+// leaderboard's confidence intervals.
+const bootstrapSamples = 1000
+
+// LeaderboardReport is the leaderboard with what a written report needs:
+// confidence intervals, checkpoint results by tier, and which dataset,
+// cases and judges it covers.
+type LeaderboardReport struct {
+	Dataset      string           `json:"dataset"`
+	GenerateTime time.Time        `json:"generate_time"`
+	Filter       string           `json:"filter,omitempty"` // How the cases were selected; set by the caller
+	Cases        int              `json:"cases"`            // Evaluated cases ranked
+	JudgeModels  []string         `json:"judge_models"`     // Models recorded in the results
+	SScoreWeight float64          `json:"s_score_weight"`
+	Rows         []LeaderboardRow `json:"rows"` // Best Q first
+}
+
+// LeaderboardRow is a provider's standing.
+type LeaderboardRow struct {
+	ProviderStats
+	QLow  float64      `json:"q_low"` // 95% bootstrap interval of WeightedQ over cases
+	QHigh float64      `json:"q_high"`
+	Tiers []TierCounts `json:"tiers"` // By exportTiers
+}
+
+// TierCounts counts a provider's checkpoint results of one tier.
+type TierCounts struct {
+	Tier    int `json:"tier"`
+	Pass    int `json:"pass"`
+	Partial int `json:"partial"`
+	Fail    int `json:"fail"`
+}
+
+// PassRate returns the share of checkpoints passed, or -1 when there are
+// none.
+func (t TierCounts) PassRate() float64 {
+	n := t.Pass + t.Partial + t.Fail
+	if n == 0 {
+		return -1
+	}
+	return float64(t.Pass) / float64(n)
+}
+
+// LeaderboardReport ranks the enabled providers over the cases matching
+// filter, as Leaderboard does, and adds the details of a report.
+func (s *Service) LeaderboardReport(ctx context.Context, filter CaseFilter) (*LeaderboardReport, error) {
+	cases, err := s.scanCasesFor(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	enabled := s.enabledProviders()
+	flagged := s.flaggedAudio(ctx)
+	sWeight := s.sScoreWeight()
+	var reports []*evalv2.EvalReport
+	var weights []int
+	judges := make(map[string]bool)
+	tiers := make(map[string]map[int]*TierCounts)
+	for _, c := range cases {
+		if c.ReportV2 == nil || flagged[c.ID] || !filter.Match(c, enabled) || reportTokenCount(c) <= 0 {
+			continue
+		}
+		report := *c.ReportV2
+		report.Results = make(map[string]evalv2.EvalResult, len(c.ReportV2.Results))
+		cpTiers := make(map[string]int)
+		for _, cp := range report.ContextSnapshot.Checkpoints {
+			cpTiers[cp.ID] = cp.Tier
+		}
+		for p, r := range c.ReportV2.Results {
+			if enabled != nil && !enabled[p] {
+				continue
+			}
+			report.Results[p] = r
+			if r.Model != "" {
+				judges[r.Model] = true
+			}
+			for _, v := range r.Verdicts {
+				judges[v.Model] = true
+			}
+			if tiers[p] == nil {
+				tiers[p] = make(map[int]*TierCounts)
+				for _, t := range exportTiers {
+					tiers[p][t] = &TierCounts{Tier: t}
+				}
+			}
+			for id, cr := range r.CheckpointResults {
+				tc := tiers[p][cpTiers[id]]
+				if tc == nil {
+					continue
+				}
+				switch cr.Status {
+				case evalv2.StatusPass:
+					tc.Pass++
+				case evalv2.StatusPartial:
+					tc.Partial++
+				case evalv2.StatusFail:
+					tc.Fail++
+				}
+			}
+		}
+		reports = append(reports, &report)
+		weights = append(weights, reportTokenCount(c))
+	}
+
+	lb := Leaderboard{SScoreWeight: sWeight}
+	for i, r := range reports {
+		lb.Add(r, weights[i])
+	}
+	intervals := bootstrapQ(reports, weights, sWeight)
+	resp := &LeaderboardReport{
+		Dataset:      filepath.Base(filepath.Clean(s.Config.DatasetDir)),
+		GenerateTime: time.Now().UTC(),
+		Cases:        len(reports),
+		JudgeModels:  slices.Sorted(maps.Keys(judges)),
+		SScoreWeight: sWeight,
+	}
+	for _, st := range lb.Rankings() {
+		row := LeaderboardRow{ProviderStats: st, QLow: intervals[st.Provider][0], QHigh: intervals[st.Provider][1]}
+		for _, t := range exportTiers {
+			row.Tiers = append(row.Tiers, *tiers[st.Provider][t])
+		}
+		resp.Rows = append(resp.Rows, row)
+	}
+	return resp, nil
+}
+
+// bootstrapQ returns the 95% interval of each provider's weighted Q,
+// resampling the reports with replacement. The seed is fixed, so a report
+// of the same data has the same intervals.
+func bootstrapQ(reports []*evalv2.EvalReport, weights []int, sWeight float64) map[string][2]float64 {
+	samples := make(map[string][]float64)
+	if len(reports) == 0 {
+		return nil
+	}
+	r := rand.New(rand.NewPCG(1, 1))
+	for range bootstrapSamples {
+		lb := Leaderboard{SScoreWeight: sWeight}
+		for range reports {
+			i := r.IntN(len(reports))
+			lb.Add(reports[i], weights[i])
+		}
+		for _, st := range lb.Rankings() {
+			samples[st.Provider] = append(samples[st.Provider], st.WeightedQ)
+		}
+	}
+	out := make(map[string][2]float64, len(samples))
+	for p, qs := range samples {
+		sort.Float64s(qs)
+		out[p] = [2]float64{qs[len(qs)*25/1000], qs[(len(qs)*975-1)/1000]}
+	}
+	return out
+}
+
+// WriteMarkdown writes r as a Markdown section: the dataset details, the
+// ranking with intervals and Tier pass rates, and the checkpoint counts.
+func (r *LeaderboardReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## ASR Leaderboard: %s\n\n", r.Dataset)
+	fmt.Fprintf(&b, "- Generated: %s\n", r.GenerateTime.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&b, "- Cases: %d evaluated", r.Cases)
+	if r.Filter != "" {
+		fmt.Fprintf(&b, " (%s)", r.Filter)
+	}
+	b.WriteString("\n")
+	if len(r.JudgeModels) > 0 {
+		fmt.Fprintf(&b, "- Judges: %s\n", strings.Join(r.JudgeModels, ", "))
+	}
+	fmt.Fprintf(&b, "- Q = S^%.2g × P^%.2g, token-weighted over cases; 95%% bootstrap intervals\n\n", r.SScoreWeight, 1-r.SScoreWeight)
+
+	b.WriteString("| # | Provider | Q | 95% CI | S | P | Cases |")
+	for _, t := range exportTiers {
+		fmt.Fprintf(&b, " Tier %d pass |", t)
+	}
+	b.WriteString("\n|--:|---|--:|:-:|--:|--:|--:|")
+	for range exportTiers {
+		b.WriteString("--:|")
+	}
+	b.WriteString("\n")
+	for i, row := range r.Rows {
+		fmt.Fprintf(&b, "| %d | %s | **%.1f** | %.1f–%.1f | %.1f | %.1f | %d |", i+1, row.Provider, row.WeightedQ, row.QLow, row.QHigh, row.WeightedS, row.WeightedP, row.Cases)
+		for _, t := range row.Tiers {
+			if rate := t.PassRate(); rate < 0 {
+				b.WriteString(" – |")
+			} else {
+				fmt.Fprintf(&b, " %.0f%% |", rate*100)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n### Checkpoints by Tier\n\n| Provider | Tier | Pass | Partial | Fail |\n|---|--:|--:|--:|--:|\n")
+	for _, row := range r.Rows {
+		for _, t := range row.Tiers {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %d |\n", row.Provider, t.Tier, t.Pass, t.Partial, t.Fail)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,74 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestLeaderboardReport(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	// Equal S and P give Q = 100 S.
+	for id, q := range map[string]map[string]float64{
+		"a": {"x": .9, "y": .5},
+		"b": {"x": .7, "y": .6},
+	} {
+		if err := os.WriteFile(filepath.Join(dir, id+extFlac), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		r := &evalv2.EvalReport{Results: map[string]evalv2.EvalResult{}}
+		r.ContextSnapshot.Meta.TotalTokenCountEstimate = 10
+		r.ContextSnapshot.Checkpoints = []evalv2.Checkpoint{{ID: "C1", Tier: 1}}
+		for p, v := range q {
+			var res evalv2.EvalResult
+			res.Metrics.SScore, res.Metrics.PScore = v, v
+			res.Model = "judge"
+			status := evalv2.StatusPass
+			if p == "y" && id == "a" {
+				status = evalv2.StatusFail
+			}
+			res.CheckpointResults = map[string]evalv2.CheckpointResult{"C1": {Status: status}}
+			r.Results[p] = res
+		}
+		if err := s.Storage.PutReport(ctx, id, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := s.LeaderboardReport(ctx, CaseFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Cases != 2 || len(r.Rows) != 2 || !cmp.Equal(r.JudgeModels, []string{"judge"}) {
+		t.Fatalf("report = %+v, want 2 cases, 2 rows, judge", r)
+	}
+	x, y := r.Rows[0], r.Rows[1]
+	if x.Provider != "x" || x.WeightedQ != 80 {
+		t.Errorf("first row = %s with Q %v, want x with 80", x.Provider, x.WeightedQ)
+	}
+	// Resampling two cases gives the mean or either case's score.
+	if x.QLow != 70 || x.QHigh != 90 {
+		t.Errorf("x interval = %v-%v, want 70-90", x.QLow, x.QHigh)
+	}
+	if diff := cmp.Diff(TierCounts{Tier: 1, Pass: 1, Fail: 1}, y.Tiers[0]); diff != "" {
+		t.Errorf("y tier 1 mismatch (-want +got):\n%s", diff)
+	}
+
+	var b strings.Builder
+	if err := r.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| 1 | x | **80.0** | 70.0–90.0 | 80.0 | 80.0 | 2 | 100% | – | – |", "| y | 1 | 1 | 0 | 1 |"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("markdown lacks %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package workspace
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+)
+
+// LiveRequest is audio captured as it is spoken and the realtime providers
+// to stream it to.
+type LiveRequest struct {
+	ID         string    // Of the case to create; empty generates one
+	Audio      io.Reader // 16-bit little-endian mono PCM, read until EOF
+	SampleRate int       // Of Audio; 0 means asr.StreamSampleRate
+	Providers  []string  // Realtime providers, streamed to side by side
+	Context    string    // Biasing context for the providers that take one
+	// OnStream, if not nil, is called with each provider's stream entries
+	// as they arrive, possibly concurrently.
+	OnStream func(provider string, e asr.StreamEntry)
+}
+
+// LiveResponse is the case a live session was saved as.
+type LiveResponse struct {
+	Case   *Case    `json:"case"`
+	Failed []string `json:"failed"` // Providers that failed, with the reason
+}
+
+// Live streams req.Audio to the providers at once, with the same clients
+// as Transcribe, and saves the recording, at its own rate, as a new case
+// with each provider's transcript and stream dump, for side-by-side demos
+// and shadow evaluation that can be replayed later. The case is saved
+// unless no audio was read, even when every provider failed.
+func (s *Service) Live(ctx context.Context, req LiveRequest) (*LiveResponse, error) {
+	if len(req.Providers) == 0 {
+		return nil, errors.New("no providers to stream to")
+	}
+	id := req.ID
+	if id == "" {
+		id = uuid.NewString()
+	} else if strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("invalid case ID: %q", id)
+	}
+	audioPath := filepath.Join(s.Config.DatasetDir, id+extFlac)
+	if _, err := os.Stat(audioPath); err == nil {
+		return nil, fmt.Errorf("case already exists: %s", id)
+	}
+	rate := req.SampleRate
+	if rate == 0 {
+		rate = asr.StreamSampleRate
+	}
+	ts := make([]asr.StreamTranscriber, len(req.Providers))
+	for i, p := range req.Providers {
+		if !providerIDPattern.MatchString(p) {
+			return nil, fmt.Errorf("invalid provider ID: %q", p)
+		}
+		t, err := asr.New(p)
+		if err != nil {
+			return nil, err
+		}
+		st, ok := t.(asr.StreamTranscriber)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a realtime provider", p)
+		}
+		ts[i] = st
+	}
+
+	var (
+		wg      sync.WaitGroup
+		bufs    []*streamBuffer
+		results = make([]*asr.Result, len(ts))
+		errs    = make([]error, len(ts))
+	)
+	// The sessions start with the audio, which a call may be long in
+	// sending, so the providers do not time them out.
+	start := func() {
+		bufs = make([]*streamBuffer, len(ts))
+		for i, t := range ts {
+			bufs[i] = newStreamBuffer()
+			opts := asr.Options{Context: req.Context}
+			if req.OnStream != nil {
+				p := req.Providers[i]
+				opts.OnStream = func(e asr.StreamEntry) { req.OnStream(p, e) }
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = t.TranscribeStream(ctx, bufs[i], opts)
+			}()
+		}
+	}
+	var (
+		recorded []int16
+		chunk    = make([]byte, 2*rate/10)
+		odd      []byte // A sample split across reads
+		resample = audio.NewResampler(rate, asr.StreamSampleRate)
+		readErr  error
+	)
+	send := func(samples []int16) {
+		b := make([]byte, 0, 2*len(samples))
+		for _, v := range samples {
+			b = binary.LittleEndian.AppendUint16(b, uint16(v))
+		}
+		for _, buf := range bufs {
+			buf.Write(b)
+		}
+	}
+	for {
+		n, err := req.Audio.Read(chunk)
+		if n > 0 && bufs == nil {
+			start()
+		}
+		data := append(odd, chunk[:n]...)
+		samples := make([]int16, len(data)/2)
+		for i := range samples {
+			samples[i] = int16(binary.LittleEndian.Uint16(data[2*i:]))
+		}
+		odd = append([]byte(nil), data[2*len(samples):]...)
+		recorded = append(recorded, samples...)
+		send(resample.Write(samples))
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+	}
+	send(resample.Flush())
+	for _, b := range bufs {
+		b.Close()
+	}
+	wg.Wait()
+	if readErr != nil {
+		return nil, fmt.Errorf("read audio: %w", readErr)
+	}
+	if len(recorded) == 0 {
+		return nil, errors.New("no audio was captured")
+	}
+
+	pcm := &audio.PCM{SampleRate: rate, Channels: 1, Samples: recorded}
+	if err := atomicfile.WriteFile(audioPath, pcm.FLAC()); err != nil {
+		return nil, err
+	}
+	if err := s.Storage.PutCase(ctx, id); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditCreateCase, id, map[string]string{"source": "live"})
+
+	resp := &LiveResponse{Failed: []string{}}
+	for i, p := range req.Providers {
+		res, err := results[i], errs[i]
+		if err == nil && res.Text == "" {
+			err = errors.New("returned an empty transcript")
+		}
+		if err != nil {
+			resp.Failed = append(resp.Failed, fmt.Sprintf("%s: %v", p, err))
+			continue
+		}
+		s.recordTranscription(id, p, audioPath)
+		if err := s.Storage.PutTranscript(ctx, id, p, res.Text); err != nil {
+			return nil, err
+		}
+		if err := writeStream(filepath.Join(s.Config.DatasetDir, id+"."+p+extStream), res.Stream); err != nil {
+			return nil, err
+		}
+		s.audit(ctx, AuditTranscribe, id, map[string]string{"provider": p})
+	}
+	s.caseChanged(ctx, id)
+	c, err := s.GetCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp.Case = c
+	return resp, nil
+}
+
+// streamBuffer is an unbounded pipe, so a provider slow to connect or to
+// send holds up neither the capture nor the other providers.
+type streamBuffer struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    []byte
+	closed bool
+}
+
+func newStreamBuffer() *streamBuffer {
+	b := &streamBuffer{}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// Write appends p for Read.
+func (b *streamBuffer) Write(p []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	b.cond.Broadcast()
+}
+
+// Close makes Read return io.EOF once it has returned everything written.
+func (b *streamBuffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.cond.Broadcast()
+}
+
+// Read waits for data written and not yet read, or Close.
+func (b *streamBuffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for len(b.buf) == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if len(b.buf) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+// liveEndMessage is the text message a client of GET /api/cases:live sends
+// after the last of the audio.
+const liveEndMessage = "end"
+
+var liveUpgrader = websocket.Upgrader{}
+
+// liveEvent is a provider's stream entry as sent to a client of
+// GET /api/cases:live.
+type liveEvent struct {
+	Provider string `json:"provider"`
+	asr.StreamEntry
+}
+
+// handleLive handles GET /api/cases:live, a WebSocket on which a client,
+// such as a tap on production calls, streams audio for Live: binary
+// messages of 16-bit little-endian mono PCM at sample_rate (default 16000),
+// then the text message "end". Repeated provider parameters pick the
+// realtime providers; id names the case and context biases them. The
+// server sends each stream entry as it arrives, tagged with the provider,
+// then the LiveResponse, or {"error": ...}, and closes the socket. A client
+// that hangs up ends the audio too, but misses the result.
+func (s *Service) handleLive(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := LiveRequest{ID: q.Get("id"), Providers: q["provider"], Context: q.Get("context")}
+	if len(req.Providers) == 0 {
+		http.Error(w, "provider required", http.StatusBadRequest)
+		return
+	}
+	if v := q.Get("sample_rate"); v != "" {
+		var err error
+		if req.SampleRate, err = strconv.Atoi(v); err != nil || req.SampleRate <= 0 {
+			http.Error(w, "invalid sample_rate: "+v, http.StatusBadRequest)
+			return
+		}
+	}
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has replied.
+	}
+	defer conn.Close()
+
+	var mu sync.Mutex
+	send := func(v any) {
+		mu.Lock()
+		defer mu.Unlock()
+		conn.WriteJSON(v)
+	}
+	req.Audio = &wsAudio{conn: conn}
+	req.OnStream = func(provider string, e asr.StreamEntry) {
+		send(liveEvent{Provider: provider, StreamEntry: e})
+	}
+	resp, err := s.Live(r.Context(), req)
+	if err != nil {
+		send(map[string]string{"error": err.Error()})
+	} else {
+		send(resp)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// wsAudio reads the binary messages of a WebSocket until the end message
+// or the connection closes.
+type wsAudio struct {
+	conn *websocket.Conn
+	cur  io.Reader
+	done bool
+}
+
+func (a *wsAudio) Read(p []byte) (int, error) {
+	for !a.done {
+		if a.cur != nil {
+			n, err := a.cur.Read(p)
+			if err == io.EOF {
+				a.cur = nil
+				err = nil
+			}
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		typ, r, err := a.conn.NextReader()
+		if err != nil {
+			// A hang-up ends the audio like the end message.
+			a.done = true
+			break
+		}
+		switch typ {
+		case websocket.BinaryMessage:
+			a.cur = r
+		case websocket.TextMessage:
+			if b, _ := io.ReadAll(r); string(b) == liveEndMessage {
+				a.done = true
+			}
+		}
+	}
+	return 0, io.EOF
+}
@@ -0,0 +1,212 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/audio"
+)
+
+// liveTranscriber reports how many bytes of audio it read, or fails after
+// reading them if err is set.
+type liveTranscriber struct{ err error }
+
+func (t liveTranscriber) Transcribe(ctx context.Context, path string, opts asr.Options) (*asr.Result, error) {
+	return nil, errors.New("not used")
+}
+
+func (t liveTranscriber) TranscribeStream(ctx context.Context, r io.Reader, opts asr.Options) (*asr.Result, error) {
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return nil, err
+	}
+	if t.err != nil {
+		return nil, t.err
+	}
+	res := &asr.Result{Text: fmt.Sprintf("%d bytes", n)}
+	for _, e := range []asr.StreamEntry{{Timestamp: 100, Text: "partial"}, {Timestamp: 200, Final: true, Text: res.Text}} {
+		if opts.OnStream != nil {
+			opts.OnStream(e)
+		}
+		res.Stream = append(res.Stream, e)
+	}
+	return res, nil
+}
+
+func init() {
+	asr.Register("test_live", func() (asr.Transcriber, error) { return liveTranscriber{}, nil })
+	asr.Register("test_live_fail", func() (asr.Transcriber, error) {
+		return liveTranscriber{err: errors.New("session closed")}, nil
+	})
+}
+
+func TestLive(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+
+	samples := make([]int16, 3*asr.StreamSampleRate)
+	for i := range samples {
+		samples[i] = int16(i%200*100 - 10000)
+	}
+	var pcm bytes.Buffer
+	binary.Write(&pcm, binary.LittleEndian, samples)
+	var (
+		mu      sync.Mutex
+		streams = make(map[string][]asr.StreamEntry)
+	)
+	resp, err := s.Live(ctx, LiveRequest{
+		Audio:     &pcm,
+		Providers: []string{"test_live", "test_live_fail"},
+		OnStream: func(provider string, e asr.StreamEntry) {
+			mu.Lock()
+			defer mu.Unlock()
+			streams[provider] = append(streams[provider], e)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := resp.Case.ID
+
+	text := fmt.Sprintf("%d bytes", 2*len(samples))
+	if got := resp.Case.Transcripts; !cmp.Equal(got, map[string]string{"test_live": text}) {
+		t.Errorf("Transcripts = %v, want only test_live's %q", got, text)
+	}
+	if diff := cmp.Diff([]string{"test_live_fail: session closed"}, resp.Failed); diff != "" {
+		t.Errorf("Failed mismatch (-want +got):\n%s", diff)
+	}
+	wantStream := []asr.StreamEntry{{Timestamp: 100, Text: "partial"}, {Timestamp: 200, Final: true, Text: text}}
+	if diff := cmp.Diff(map[string][]asr.StreamEntry{"test_live": wantStream}, streams); diff != "" {
+		t.Errorf("OnStream entries mismatch (-want +got):\n%s", diff)
+	}
+	stream, err := s.GetStream(ctx, id, "test_live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(stream.Events); n != 2 || stream.Events[1].Committed != text {
+		t.Errorf("GetStream() = %+v, want the dump of test_live's stream", stream)
+	}
+	if _, err := os.Stat(filepath.Join(dir, id+".test_live_fail"+extStream)); !os.IsNotExist(err) {
+		t.Errorf("stream dump of the failed provider: %v, want none", err)
+	}
+
+	got, err := audio.DecodeFile(filepath.Join(dir, id+extFlac))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &audio.PCM{SampleRate: asr.StreamSampleRate, Channels: 1, Samples: samples}
+	if !cmp.Equal(want, got) {
+		t.Error("case audio is not the captured audio")
+	}
+
+	// Telephony audio, read a byte at a time, is kept at its rate and sent
+	// to the providers at theirs.
+	narrow := samples[:asr.StreamSampleRate]
+	pcm.Reset()
+	binary.Write(&pcm, binary.LittleEndian, narrow)
+	resp, err = s.Live(ctx, LiveRequest{ID: "call-1", Audio: iotest.OneByteReader(&pcm), SampleRate: 8000, Providers: []string{"test_live"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.Case.Transcripts["test_live"], fmt.Sprintf("%d bytes", 2*2*len(narrow)); resp.Case.ID != "call-1" || got != want {
+		t.Errorf("Live() = case %s with transcript %q, want call-1 with %q", resp.Case.ID, got, want)
+	}
+	if got, err := audio.DecodeFile(filepath.Join(dir, "call-1"+extFlac)); err != nil || got.SampleRate != 8000 || !slices.Equal(got.Samples, narrow) {
+		t.Errorf("case audio is not the 8 kHz audio read (err %v)", err)
+	}
+	if _, err := s.Live(ctx, LiveRequest{ID: "call-1", Audio: bytes.NewReader(make([]byte, 100)), Providers: []string{"test_live"}}); err == nil {
+		t.Error("Live() with the ID of an existing case succeeded")
+	}
+
+	for _, providers := range [][]string{nil, {"test_chunks"}, {"no_such_provider"}} {
+		if _, err := s.Live(ctx, LiveRequest{Audio: bytes.NewReader(make([]byte, 100)), Providers: providers}); err == nil {
+			t.Errorf("Live() with providers %v succeeded", providers)
+		}
+	}
+	if _, err := s.Live(ctx, LiveRequest{Audio: bytes.NewReader(nil), Providers: []string{"test_live"}}); err == nil {
+		t.Error("Live() without audio succeeded")
+	}
+}
+
+func TestHandleLive(t *testing.T) {
+	dir := t.TempDir()
+	mux := http.NewServeMux()
+	(&Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}).RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/cases:live?provider=test_live&sample_rate=8000&id=call-2"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for range 4 {
+		if err := conn.WriteMessage(websocket.BinaryMessage, make([]byte, 1600)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(liveEndMessage)); err != nil {
+		t.Fatal(err)
+	}
+	var events []liveEvent
+	var c *Case
+	for {
+		var msg struct {
+			liveEvent
+			Case  *Case  `json:"case"`
+			Error string `json:"error"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Error != "" {
+			t.Fatal(msg.Error)
+		}
+		if msg.Case != nil {
+			c = msg.Case
+			break
+		}
+		events = append(events, msg.liveEvent)
+	}
+
+	// 0.4 s at 8 kHz, sent at 16 kHz.
+	text := fmt.Sprintf("%d bytes", 4*1600*2)
+	want := []liveEvent{
+		{Provider: "test_live", StreamEntry: asr.StreamEntry{Timestamp: 100, Text: "partial"}},
+		{Provider: "test_live", StreamEntry: asr.StreamEntry{Timestamp: 200, Final: true, Text: text}},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+	if c.ID != "call-2" || c.Transcripts["test_live"] != text {
+		t.Errorf("case = %s with %v, want call-2 with %q", c.ID, c.Transcripts, text)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("after the result: %v, want a normal close", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/cases:live?sample_rate=8000", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("without provider: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,43 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// locksDirName holds a lock file per case. The files are never removed:
+// a process could be waiting on one.
+const locksDirName = ".locks"
+
+// lockPollInterval is how often a blocked lock is retried.
+const lockPollInterval = 50 * time.Millisecond
+
+// lockCase takes the advisory lock of case id and returns its release.
+// Every read-modify-write of a case's records holds it, so the server,
+// batch_eval and asr-eval running against one dataset do not interleave
+// their writes. The lock is not reentrant. Releasing it brings the case's
+// entry in the dataset manifest up to date and journals its changes.
+func (s *Service) lockCase(ctx context.Context, id string) (func(), error) {
+	dir := filepath.Join(s.Config.DatasetDir, locksDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	unlock, err := lockFile(ctx, filepath.Join(dir, id+".lock"))
+	if err != nil {
+		return nil, fmt.Errorf("lock case %s: %w", id, err)
+	}
+	return func() {
+		s.caseChanged(context.WithoutCancel(ctx), id)
+		unlock()
+	}, nil
+}
+
+// caseChanged updates what tracks the files of case id after they may
+// have changed: the dataset manifest and the change journal.
+func (s *Service) caseChanged(ctx context.Context, id string) {
+	s.touchManifest(ctx, id)
+	s.journalCase(ctx, id)
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+
+package workspace
+
+import (
+	"context"
+	"sync"
+)
+
+// fileLocks holds a one-slot semaphore per lock path.
+var fileLocks sync.Map
+
+// lockFile locks path within this process only; other platforms get no
+// cross-process locking.
+func lockFile(ctx context.Context, path string) (func(), error) {
+	v, _ := fileLocks.LoadOrStore(path, make(chan struct{}, 1))
+	sem := v.(chan struct{})
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
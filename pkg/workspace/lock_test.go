@@ -0,0 +1,35 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLockCase(t *testing.T) {
+	s := &Service{Config: ServiceConfig{DatasetDir: t.TempDir()}}
+	ctx := context.Background()
+
+	unlock, err := s.lockCase(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.lockCase(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other()
+
+	short, cancel := context.WithTimeout(ctx, 3*lockPollInterval)
+	defer cancel()
+	if _, err := s.lockCase(short, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("locking a held case = %v, want deadline exceeded", err)
+	}
+
+	unlock()
+	again, err := s.lockCase(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again()
+}
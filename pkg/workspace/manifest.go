@@ -0,0 +1,466 @@
+package workspace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+)
+
+// manifestFileName summarizes every case of a dataset directory in one
+// file, so listing and scoring cases reads it instead of every report.
+const (
+	manifestFileName     = "manifest.json"
+	manifestLockFileName = ".manifest.lock" // In locksDirName; case IDs never start with a dot
+)
+
+// Manifest is the content of manifest.json.
+type Manifest struct {
+	UpdateTime time.Time        `json:"update_time"`
+	Cases      []*ManifestEntry `json:"cases"` // Sorted by ID
+}
+
+// ManifestEntry summarizes one case.
+type ManifestEntry struct {
+	ID          string           `json:"id"`
+	DurationMS  int64            `json:"duration_ms,omitempty"`
+	SampleRate  int              `json:"sample_rate,omitempty"`
+	Channels    int              `json:"channels,omitempty"`
+	Levels      *audio.Levels    `json:"levels,omitempty"`
+	AudioIssues []string         `json:"audio_issues,omitempty"` // Why the case is left out of provider comparisons
+	Providers   []string         `json:"providers"`              // With a transcript
+	Context     *ManifestContext `json:"context,omitempty"`
+	Report      *ManifestReport  `json:"report,omitempty"`
+	Tags        []string         `json:"tags,omitempty"`
+	ReviewState ReviewState      `json:"review_state,omitempty"`
+	Assignee    string           `json:"assignee,omitempty"`
+
+	// Files stamps the case's files, to notice changes made without
+	// updating the manifest.
+	Files map[string]fileStamp `json:"files"`
+}
+
+// ManifestContext summarizes the case's eval context, or the snapshot in
+// its report when it has none of its own.
+type ManifestContext struct {
+	Hash           string `json:"hash,omitempty"`
+	GTHash         string `json:"gt_hash,omitempty"` // SHA-256 of the ground truth
+	QuestionableGT bool   `json:"questionable_gt,omitempty"`
+	TokenCount     int    `json:"token_count,omitempty"`
+}
+
+// ManifestReport summarizes the case's report.
+type ManifestReport struct {
+	Hash        string                   `json:"hash"`         // SHA-256 of the report JSON
+	ContextHash string                   `json:"context_hash"` // Of the context it was scored against
+	TokenCount  int                      `json:"token_count,omitempty"`
+	Scores      map[string]ManifestScore `json:"scores"` // By provider
+}
+
+// ManifestScore is a provider's result in a report.
+type ManifestScore struct {
+	Q int     `json:"q"`
+	S float64 `json:"s"`
+	P float64 `json:"p"`
+}
+
+type fileStamp struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mtime"` // Unix nanoseconds
+}
+
+// Manifest returns the dataset manifest, first bringing it up to date with
+// the directory: cases whose files changed since it was written are
+// summarized again. It returns nil when the storage is not the dataset
+// directory.
+func (s *Service) Manifest(ctx context.Context) (*Manifest, error) {
+	if _, ok := s.Storage.(*FSStorage); !ok {
+		return nil, nil
+	}
+	unlock, err := s.lockManifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	m, err := s.readManifest()
+	if err != nil {
+		slog.Warn("Rebuilding dataset manifest", "dataset", s.Config.DatasetDir, "error", err)
+		m = nil
+	}
+	files, err := s.stampCaseFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	old := make(map[string]*ManifestEntry)
+	if m != nil {
+		for _, e := range m.Cases {
+			old[e.ID] = e
+		}
+	}
+	changed := m == nil || len(old) != len(files)
+	out := &Manifest{Cases: make([]*ManifestEntry, 0, len(files))}
+	for id, stamps := range files {
+		e := old[id]
+		// Entries written before levels were measured are measured now.
+		if e == nil || !sameStamps(e.Files, stamps) || e.Levels == nil && e.SampleRate > 0 {
+			if e = s.manifestEntry(ctx, id, stamps, e); e == nil {
+				continue
+			}
+			changed = true
+		}
+		out.Cases = append(out.Cases, e)
+	}
+	sort.Slice(out.Cases, func(i, j int) bool { return out.Cases[i].ID < out.Cases[j].ID })
+	if flagAudio(out, s.Config.SampleRate) {
+		changed = true
+	}
+	if !changed {
+		return m, nil
+	}
+	out.UpdateTime = time.Now().UTC()
+	if err := s.writeManifest(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// touchManifest summarizes case id again after a change. Without a
+// manifest yet it does nothing; Manifest builds one on first use.
+func (s *Service) touchManifest(ctx context.Context, id string) {
+	if _, ok := s.Storage.(*FSStorage); !ok {
+		return
+	}
+	unlock, err := s.lockManifest(ctx)
+	if err != nil {
+		return
+	}
+	defer unlock()
+	m, err := s.readManifest()
+	if err != nil {
+		return
+	}
+
+	i := sort.Search(len(m.Cases), func(i int) bool { return m.Cases[i].ID >= id })
+	var prev, e *ManifestEntry
+	if i < len(m.Cases) && m.Cases[i].ID == id {
+		prev = m.Cases[i]
+	}
+	if stamps := s.stampFiles(id); stamps != nil {
+		e = s.manifestEntry(ctx, id, stamps, prev)
+	}
+	switch {
+	case i < len(m.Cases) && m.Cases[i].ID == id && e != nil:
+		m.Cases[i] = e
+	case i < len(m.Cases) && m.Cases[i].ID == id:
+		m.Cases = append(m.Cases[:i], m.Cases[i+1:]...)
+	case e != nil:
+		m.Cases = append(m.Cases[:i], append([]*ManifestEntry{e}, m.Cases[i:]...)...)
+	default:
+		return
+	}
+	flagAudio(m, s.Config.SampleRate)
+	m.UpdateTime = time.Now().UTC()
+	if err := s.writeManifest(m); err != nil {
+		slog.Error("Failed to update dataset manifest", "dataset", s.Config.DatasetDir, "case", id, "error", err)
+	}
+}
+
+// manifestEntry summarizes case id, or returns nil when it is gone. The
+// audio metadata of prev, the case's previous entry if any, is kept when
+// the audio has not changed since.
+func (s *Service) manifestEntry(ctx context.Context, id string, stamps map[string]fileStamp, prev *ManifestEntry) *ManifestEntry {
+	c := s.loadSummary(ctx, id)
+	if c == nil {
+		return nil
+	}
+	e := &ManifestEntry{ID: id, Providers: []string{}, Tags: c.Tags, Files: stamps}
+	path := filepath.Join(s.Config.DatasetDir, id+extFlac)
+	if prev != nil && prev.SampleRate > 0 && prev.Files[id+extFlac] == stamps[id+extFlac] {
+		e.DurationMS, e.SampleRate, e.Channels = prev.DurationMS, prev.SampleRate, prev.Channels
+	} else if info, err := audio.ReadInfo(path); err == nil {
+		e.DurationMS, e.SampleRate, e.Channels = info.DurationMS, info.SampleRate, info.Channels
+	}
+	if prev != nil && prev.Levels != nil && prev.Files[id+extFlac] == stamps[id+extFlac] {
+		e.Levels = prev.Levels
+	} else if pcm, err := audio.DecodeFile(path); err == nil {
+		l := pcm.Levels()
+		e.Levels = &l
+	}
+	for name := range stamps {
+		if p, ok := transcriptProvider(id, name); ok {
+			e.Providers = append(e.Providers, p)
+		}
+	}
+	sort.Strings(e.Providers)
+	if c.EvalContext != nil {
+		e.Context = &ManifestContext{
+			Hash:           c.EvalContext.Hash,
+			QuestionableGT: c.EvalContext.Meta.QuestionableGT,
+			TokenCount:     c.EvalContext.Meta.TotalTokenCountEstimate,
+		}
+		if gt := c.EvalContext.Meta.GroundTruth; gt != "" {
+			e.Context.GTHash = sha256Hex([]byte(gt))
+		}
+	}
+	if c.ReportV2 != nil {
+		r := &ManifestReport{
+			ContextHash: c.ReportV2.ContextSnapshot.Hash,
+			TokenCount:  c.ReportV2.ContextSnapshot.Meta.TotalTokenCountEstimate,
+			Scores:      make(map[string]ManifestScore, len(c.ReportV2.Results)),
+		}
+		if content, err := readReportFile(filepath.Join(s.Config.DatasetDir, id+extReportV2)); err == nil {
+			r.Hash = sha256Hex(content)
+		}
+		for p, res := range c.ReportV2.Results {
+			r.Scores[p] = ManifestScore{Q: res.Metrics.QScore, S: res.Metrics.SScore, P: res.Metrics.PScore}
+		}
+		e.Report = r
+	}
+	if c.Review != nil {
+		e.ReviewState, e.Assignee = c.Review.State, c.Review.Assignee
+	}
+	return e
+}
+
+// Case returns a summary Case carrying only what the manifest records:
+// enough to filter, sort and score it, not to show it.
+func (e *ManifestEntry) Case() *Case {
+	c := &Case{ID: e.ID, Tags: e.Tags}
+	if e.Context != nil {
+		c.EvalContext = &evalv2.EvalContext{
+			Hash: e.Context.Hash,
+			Meta: evalv2.ContextMeta{
+				QuestionableGT:          e.Context.QuestionableGT,
+				TotalTokenCountEstimate: e.Context.TokenCount,
+			},
+		}
+	}
+	if e.Report != nil {
+		c.ReportV2 = &evalv2.EvalReport{
+			Results: make(map[string]evalv2.EvalResult, len(e.Report.Scores)),
+			ContextSnapshot: evalv2.EvalContext{
+				Hash: e.Report.ContextHash,
+				Meta: evalv2.ContextMeta{TotalTokenCountEstimate: e.Report.TokenCount},
+			},
+		}
+		for p, sc := range e.Report.Scores {
+			c.ReportV2.Results[p] = evalv2.EvalResult{Metrics: evalv2.EvalMetrics{QScore: sc.Q, SScore: sc.S, PScore: sc.P}}
+		}
+	}
+	if e.ReviewState != "" {
+		c.Review = &Review{State: e.ReviewState, Assignee: e.Assignee}
+	}
+	return c
+}
+
+// audioLookup returns a function giving the audio metadata of a case: its
+// duration, sample rate and channels as the manifest records them, or else
+// the header of its audio, read then. The function returns nil for audio
+// it cannot read.
+func (s *Service) audioLookup(ctx context.Context) func(id string) *audio.Info {
+	cached := make(map[string]*audio.Info)
+	if m, err := s.Manifest(ctx); err == nil && m != nil {
+		for _, e := range m.Cases {
+			if e.SampleRate > 0 {
+				cached[e.ID] = &audio.Info{DurationMS: e.DurationMS, SampleRate: e.SampleRate, Channels: e.Channels}
+			}
+		}
+	}
+	return func(id string) *audio.Info {
+		if info, ok := cached[id]; ok {
+			return info
+		}
+		info, err := audio.ReadInfo(filepath.Join(s.Config.DatasetDir, id+extFlac))
+		if err != nil {
+			return nil
+		}
+		return info
+	}
+}
+
+// manifestCases returns the summary Cases of the manifest sorted by ID, or
+// nil when there is none to use.
+func (s *Service) manifestCases(ctx context.Context) []*Case {
+	m, err := s.Manifest(ctx)
+	if err != nil {
+		slog.Warn("Dataset manifest unavailable", "dataset", s.Config.DatasetDir, "error", err)
+		return nil
+	}
+	if m == nil {
+		return nil
+	}
+	cases := make([]*Case, len(m.Cases))
+	for i, e := range m.Cases {
+		cases[i] = e.Case()
+		if cases[i].ReportV2 != nil {
+			s.fillQScores(cases[i].ReportV2) // The score weight may have changed
+		}
+	}
+	return cases
+}
+
+// listCasesFor returns summary Cases to filter with filter: those of the
+// manifest when the dataset is not watched and filter selects no run, in
+// which case hydrated is false and the Cases carry only what the manifest
+// records.
+func (s *Service) listCasesFor(ctx context.Context, filter CaseFilter) (cases []*Case, hydrated bool, err error) {
+	if filter.Run == "" && s.index.Load() == nil {
+		if cases := s.manifestCases(ctx); cases != nil {
+			return cases, false, nil
+		}
+	}
+	cases, err = s.scanCasesFor(ctx, filter)
+	return cases, true, err
+}
+
+// stampCaseFiles stamps the files the manifest summarizes, by case.
+func (s *Service) stampCaseFiles() (map[string]map[string]fileStamp, error) {
+	entries, err := os.ReadDir(s.Config.DatasetDir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), extFlac); ok && !e.IsDir() {
+			ids[id] = true
+		}
+	}
+	files := make(map[string]map[string]fileStamp, len(ids))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		id, ok := owningCase(name, ids)
+		if !ok || !manifestFile(id, name) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if files[id] == nil {
+			files[id] = make(map[string]fileStamp)
+		}
+		files[id][name] = stampOf(fi)
+	}
+	return files, nil
+}
+
+// stampFiles stamps the files the manifest summarizes for case id, or
+// returns nil when the case does not exist.
+func (s *Service) stampFiles(id string) map[string]fileStamp {
+	names, err := caseFiles(s.Config.DatasetDir, id)
+	if err != nil {
+		return nil
+	}
+	stamps := make(map[string]fileStamp)
+	for _, name := range names {
+		if !manifestFile(id, name) {
+			continue
+		}
+		if fi, err := os.Stat(filepath.Join(s.Config.DatasetDir, name)); err == nil {
+			stamps[name] = stampOf(fi)
+		}
+	}
+	if _, ok := stamps[id+extFlac]; !ok {
+		return nil
+	}
+	return stamps
+}
+
+// owningCase returns the case of ids the file name belongs to, preferring
+// the longest ID, as caseFiles does.
+func owningCase(name string, ids map[string]bool) (string, bool) {
+	for i := len(name) - 1; i > 0; i-- {
+		if name[i] == '.' && ids[name[:i]] {
+			return name[:i], true
+		}
+	}
+	return "", false
+}
+
+// manifestFile reports whether the manifest summarizes the file name of
+// case id: its audio, transcripts and summary sidecars.
+func manifestFile(id, name string) bool {
+	if _, ok := transcriptProvider(id, name); ok {
+		return true
+	}
+	_, ext, ok := summaryFile(name)
+	return ok && name == id+ext
+}
+
+// transcriptProvider returns the provider of name when it is a transcript
+// of case id, "[id].<provider>".
+func transcriptProvider(id, name string) (string, bool) {
+	p, ok := strings.CutPrefix(name, id+".")
+	if !ok || !providerIDPattern.MatchString(p) || "."+p == extFlac {
+		return "", false
+	}
+	return p, true
+}
+
+func stampOf(fi os.FileInfo) fileStamp {
+	return fileStamp{Size: fi.Size(), ModTime: fi.ModTime().UnixNano()}
+}
+
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, st := range a {
+		if b[name] != st {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) lockManifest(ctx context.Context) (func(), error) {
+	dir := filepath.Join(s.Config.DatasetDir, locksDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return lockFile(ctx, filepath.Join(dir, manifestLockFileName))
+}
+
+func (s *Service) readManifest() (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(s.Config.DatasetDir, manifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+	if !sort.SliceIsSorted(m.Cases, func(i, j int) bool { return m.Cases[i].ID < m.Cases[j].ID }) {
+		return nil, errors.New("manifest cases are not sorted")
+	}
+	return &m, nil
+}
+
+func (s *Service) writeManifest(m *Manifest) error {
+	bytes, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filepath.Join(s.Config.DatasetDir, manifestFileName), bytes)
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/audio"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.flac":           "",
+		"a.dg":             "hello",
+		"a.qwen":           "hello",
+		"a.report.v2.json": `{"evaluations":{"dg":{"metrics":{"s_score":0.5,"p_score":0.5}}}}`,
+		"a.b.flac":         "",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+
+	summarize := func() map[string][]string {
+		m, err := s.Manifest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string][]string)
+		for _, e := range m.Cases {
+			got[e.ID] = append(append([]string{}, e.Providers...), e.Tags...)
+			if e.Report != nil {
+				got[e.ID] = append(got[e.ID], "report")
+			}
+		}
+		return got
+	}
+
+	want := map[string][]string{"a": {"dg", "qwen", "report"}, "a.b": {}}
+	if diff := cmp.Diff(want, summarize()); diff != "" {
+		t.Errorf("Manifest mismatch (-want +got):\n%s", diff)
+	}
+
+	// Written through the service.
+	if _, err := s.UpdateTags(ctx, UpdateTagsRequest{ID: "a.b", Add: []string{"noisy"}}); err != nil {
+		t.Fatal(err)
+	}
+	m, err := s.readManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Cases[1].Tags; !cmp.Equal(got, []string{"noisy"}) {
+		t.Errorf("tags after UpdateTags = %v, want [noisy]", got)
+	}
+
+	// Changed behind its back.
+	if err := os.Remove(filepath.Join(dir, "a.qwen")); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string][]string{"a": {"dg", "report"}, "a.b": {"noisy"}}
+	if diff := cmp.Diff(want, summarize()); diff != "" {
+		t.Errorf("Manifest after removal mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManifestAudio(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.flac")
+	pcm := &audio.PCM{SampleRate: 8000, Channels: 2, Samples: make([]int16, 2*12000)}
+	if err := os.WriteFile(path, pcm.WAV(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+
+	audioOf := func() *audio.Info {
+		m, err := s.Manifest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := m.Cases[0]
+		return &audio.Info{DurationMS: e.DurationMS, SampleRate: e.SampleRate, Channels: e.Channels}
+	}
+	want := &audio.Info{DurationMS: 1500, SampleRate: 8000, Channels: 2}
+	if diff := cmp.Diff(want, audioOf()); diff != "" {
+		t.Errorf("Manifest audio mismatch (-want +got):\n%s", diff)
+	}
+
+	// Other files of the case changing keep the audio metadata without
+	// reading the audio again; spoil it so a reread would show.
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, fi.Size()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, fi.ModTime(), fi.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.dg"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, audioOf()); diff != "" {
+		t.Errorf("Manifest audio after a transcript mismatch (-want +got):\n%s", diff)
+	}
+	if got := s.audioLookup(ctx)("a"); !cmp.Equal(got, want) {
+		t.Errorf("audioLookup() = %+v, want %+v", got, want)
+	}
+
+	// The audio changing reads it again.
+	pcm.SampleRate = 16000
+	if err := os.WriteFile(path, pcm.WAV(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, fi.ModTime().Add(time.Second), fi.ModTime().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	want = &audio.Info{DurationMS: 750, SampleRate: 16000, Channels: 2}
+	if diff := cmp.Diff(want, audioOf()); diff != "" {
+		t.Errorf("Manifest audio after a change mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,154 @@
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// migrationJournalName records, per migration, the files it rewrote or
+// found current, so a rerun, or one resumed after an interruption, skips
+// them while they are unchanged.
+const (
+	migrationJournalName  = ".migrations.jsonl"
+	migrationLockFileName = ".migrations.lock" // In locksDirName
+)
+
+// journalEntry is one line of the migration journal.
+type journalEntry struct {
+	Migration string    `json:"migration"`
+	File      string    `json:"file"` // Slash-separated, relative to the dataset
+	Size      int64     `json:"size"`
+	ModTime   int64     `json:"mtime"` // Unix nanoseconds, after the migration
+	Time      time.Time `json:"time"`
+}
+
+// MigrateResponse counts what a dataset migration did.
+type MigrateResponse struct {
+	Scanned  int
+	Migrated int      // Rewritten, or to be with a dry run
+	Skipped  int      // Unchanged since the journal recorded them
+	Failed   []string // Files that could not be migrated, with the reason
+}
+
+// fileMigration rewrites the matching context and report files of a
+// dataset one at a time.
+type fileMigration struct {
+	name  string // Journal key
+	match func(name string) bool
+	// apply rewrites the file name in dir and reports whether it had to.
+	// With dryRun set it only tells whether it would.
+	apply func(ctx context.Context, dir, name string, dryRun bool) (bool, error)
+}
+
+// migrateFiles runs m over the dataset directory and the runs, skipping
+// the files the journal records as done. Only one migration runs at a time
+// per dataset.
+func (s *Service) migrateFiles(ctx context.Context, m fileMigration, dryRun bool) (*MigrateResponse, error) {
+	locks := filepath.Join(s.Config.DatasetDir, locksDirName)
+	if err := os.MkdirAll(locks, 0755); err != nil {
+		return nil, err
+	}
+	unlock, err := lockFile(ctx, filepath.Join(locks, migrationLockFileName))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	done, err := s.readMigrationJournal(m.name)
+	if err != nil {
+		return nil, err
+	}
+	var journal *os.File
+	if !dryRun {
+		journal, err = os.OpenFile(filepath.Join(s.Config.DatasetDir, migrationJournalName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		defer journal.Close()
+	}
+
+	dirs, err := s.schemaFileDirs()
+	if err != nil {
+		return nil, err
+	}
+	resp := &MigrateResponse{Failed: []string{}}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || !m.match(e.Name()) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			resp.Scanned++
+			path := filepath.Join(dir, e.Name())
+			rel, _ := filepath.Rel(s.Config.DatasetDir, path)
+			rel = filepath.ToSlash(rel)
+			if st, ok := done[rel]; ok {
+				if fi, err := os.Stat(path); err == nil && stampOf(fi) == st {
+					resp.Skipped++
+					continue
+				}
+			}
+			changed, err := m.apply(ctx, dir, e.Name(), dryRun)
+			if err != nil {
+				resp.Failed = append(resp.Failed, fmt.Sprintf("%s: %v", rel, err))
+				continue
+			}
+			if changed {
+				resp.Migrated++
+			}
+			if journal != nil {
+				if err := appendMigrationJournal(journal, m.name, rel, path); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return resp, nil
+}
+
+// readMigrationJournal returns the stamps of the files migration has done.
+func (s *Service) readMigrationJournal(migration string) (map[string]fileStamp, error) {
+	f, err := os.Open(filepath.Join(s.Config.DatasetDir, migrationJournalName))
+	if os.IsNotExist(err) {
+		return map[string]fileStamp{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	done := make(map[string]fileStamp)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e journalEntry
+		if json.Unmarshal(sc.Bytes(), &e) != nil || e.Migration != migration {
+			continue // A line cut short by a crash, or another migration
+		}
+		done[e.File] = fileStamp{Size: e.Size, ModTime: e.ModTime}
+	}
+	return done, sc.Err()
+}
+
+func appendMigrationJournal(f *os.File, migration, rel, path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	st := stampOf(fi)
+	b, err := json.Marshal(journalEntry{Migration: migration, File: rel, Size: st.Size, ModTime: st.ModTime, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
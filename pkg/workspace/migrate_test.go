@@ -0,0 +1,49 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestMigrateJournal(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		if err := s.Storage.PutReport(ctx, id, &evalv2.EvalReport{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		dryRun bool
+		touch  string
+		want   MigrateResponse
+	}{
+		{name: "dry run", dryRun: true, want: MigrateResponse{Scanned: 2, Migrated: 2}},
+		{name: "first", want: MigrateResponse{Scanned: 2, Migrated: 2}},
+		{name: "rerun", want: MigrateResponse{Scanned: 2, Skipped: 2}},
+		{name: "rewritten", touch: "b", want: MigrateResponse{Scanned: 2, Migrated: 1, Skipped: 1}},
+	} {
+		if tc.touch != "" {
+			if err := os.WriteFile(filepath.Join(dir, tc.touch+extReportV2), []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := s.CompressReports(ctx, false, tc.dryRun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc.want.Failed = []string{}
+		if diff := cmp.Diff(&tc.want, got); diff != "" {
+			t.Errorf("%s: CompressReports mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+}
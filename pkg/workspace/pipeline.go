@@ -0,0 +1,74 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// Pipeline steps, reported in evalv2.Progress.Step.
+const (
+	StepGenerateContext = "generate_context"
+	StepSaveContext     = "save_context"
+	StepEvaluate        = "evaluate"
+	StepAlignContext    = "align_context" // Run on its own by AlignContext
+)
+
+// RunPipeline generates and saves a context when the case has none, or
+// when req.Force is set, then evaluates the providers against it.
+func (s *Service) RunPipeline(ctx context.Context, req RunPipelineRequest) (*evalv2.EvalReport, error) {
+	c, err := s.GetCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	evalCtx := c.EvalContext
+	if evalCtx == nil || req.Force {
+		gt := req.GroundTruth
+		if gt == "" && evalCtx != nil {
+			gt = evalCtx.Meta.GroundTruth
+		}
+		if strings.TrimSpace(gt) == "" {
+			return nil, fmt.Errorf("ground truth required to generate a context")
+		}
+
+		stepCtx := startStep(ctx, StepGenerateContext)
+		generated, err := s.GenerateContext(stepCtx, GenerateContextRequest{ID: req.ID, GroundTruth: gt})
+		if err != nil {
+			return nil, fmt.Errorf("generate context: %w", err)
+		}
+		generated.Meta.GroundTruth = gt
+
+		startStep(ctx, StepSaveContext)
+		updated, err := s.UpdateContext(ctx, UpdateContextRequest{ID: req.ID, EvalContext: generated})
+		if err != nil {
+			return nil, fmt.Errorf("save context: %w", err)
+		}
+		evalCtx = updated.EvalContext
+	}
+
+	providers := req.ProviderIDs
+	if len(providers) == 0 {
+		providers = s.EnabledProviderIDs()
+	}
+	report, err := s.Evaluate(startStep(ctx, StepEvaluate), EvaluateRequest{
+		ID:          req.ID,
+		EvalContext: evalCtx,
+		ProviderIDs: providers,
+		JudgeModels: req.JudgeModels,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("evaluate: %w", err)
+	}
+	return report, nil
+}
+
+// startStep reports the start of step and returns a context that labels
+// the step's progress.
+func startStep(ctx context.Context, step string) context.Context {
+	ctx = evalv2.WithStep(ctx, step)
+	evalv2.ReportProgress(ctx, evalv2.Progress{Stage: evalv2.StageStepStarted})
+	return ctx
+}
@@ -0,0 +1,30 @@
+package workspace
+
+import (
+	"context"
+	"maps"
+)
+
+// UpdateProviders replaces the enabled provider set and persists it to the
+// dataset's dataset.yaml.
+func (s *Service) UpdateProviders(ctx context.Context, req UpdateProvidersRequest) error {
+	enabled := maps.Clone(req.EnabledProviders)
+	if enabled == nil {
+		enabled = make(map[string]bool)
+	}
+
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+	if err := updateDatasetConfig(s.Config.DatasetDir, "enabled_providers", enabled); err != nil {
+		return err
+	}
+	s.Config.EnabledProviders = enabled
+	return nil
+}
+
+// enabledProviders returns a copy of the enabled provider set.
+func (s *Service) enabledProviders() map[string]bool {
+	s.providersMu.RLock()
+	defer s.providersMu.RUnlock()
+	return maps.Clone(s.Config.EnabledProviders)
+}
@@ -0,0 +1,212 @@
+package workspace
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sort keys accepted by ListCasesRequest.Sort. Prefix with "-" to sort in
+// descending order.
+const (
+	sortByID         = "id"
+	sortByQScore     = "q_score"
+	sortByTokenCount = "token_count"
+)
+
+// CaseFilter selects cases for list and stats queries. Zero values match
+// everything.
+type CaseFilter struct {
+	HasEval        *bool    `json:"has_eval,omitempty"`
+	QuestionableGT *bool    `json:"questionable_gt,omitempty"`
+	Provider       string   `json:"provider,omitempty"`  // Case must have a result for this provider
+	MinScore       *int     `json:"min_score,omitempty"` // QScore bounds, applied to Provider or the best provider
+	MaxScore       *int     `json:"max_score,omitempty"`
+	Tags           []string `json:"tags,omitempty"` // Case must have all of these tags
+	ReviewState    string   `json:"review_state,omitempty"`
+	Assignee       string   `json:"assignee,omitempty"`
+	Run            string   `json:"run,omitempty"` // Use this run's reports instead of the current ones
+}
+
+// Match reports whether c passes the filter. enabled limits the providers
+// considered for the best score when no Provider is set; nil allows all.
+func (f CaseFilter) Match(c *Case, enabled map[string]bool) bool {
+	hasEval := c.ReportV2 != nil && len(c.ReportV2.Results) > 0
+	if f.HasEval != nil && *f.HasEval != hasEval {
+		return false
+	}
+	if f.QuestionableGT != nil {
+		q := c.EvalContext != nil && c.EvalContext.Meta.QuestionableGT
+		if *f.QuestionableGT != q {
+			return false
+		}
+	}
+	if f.Provider != "" {
+		if !hasEval {
+			return false
+		}
+		if _, ok := c.ReportV2.Results[f.Provider]; !ok {
+			return false
+		}
+	}
+	for _, t := range f.Tags {
+		if !slices.Contains(c.Tags, t) {
+			return false
+		}
+	}
+	if f.ReviewState != "" && f.ReviewState != string(reviewState(c.Review)) {
+		return false
+	}
+	if f.Assignee != "" && (c.Review == nil || c.Review.Assignee != f.Assignee) {
+		return false
+	}
+	if f.MinScore != nil || f.MaxScore != nil {
+		score, ok := caseScore(c, f.Provider, enabled)
+		if !ok {
+			return false
+		}
+		if f.MinScore != nil && score < *f.MinScore {
+			return false
+		}
+		if f.MaxScore != nil && score > *f.MaxScore {
+			return false
+		}
+	}
+	return true
+}
+
+// caseScore returns the QScore of provider in c, or the best QScore across
+// the enabled providers when provider is empty.
+func caseScore(c *Case, provider string, enabled map[string]bool) (int, bool) {
+	if c.ReportV2 == nil {
+		return 0, false
+	}
+	if provider != "" {
+		r, ok := c.ReportV2.Results[provider]
+		return r.Metrics.QScore, ok
+	}
+	best, found := 0, false
+	for p, r := range c.ReportV2.Results {
+		if enabled != nil && !enabled[p] {
+			continue
+		}
+		if !found || r.Metrics.QScore > best {
+			best, found = r.Metrics.QScore, true
+		}
+	}
+	return best, found
+}
+
+func caseTokenCount(c *Case) int {
+	if c.EvalContext == nil {
+		return 0
+	}
+	return c.EvalContext.Meta.TotalTokenCountEstimate
+}
+
+// sortCases orders cases by key, using the ID as the tie breaker. provider
+// and enabled select which score q_score sorts by, as in caseScore.
+func sortCases(cases []*Case, key, provider string, enabled map[string]bool) error {
+	desc := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	var compare func(a, b *Case) int
+	switch key {
+	case "", sortByID:
+		compare = func(a, b *Case) int { return 0 }
+	case sortByQScore:
+		compare = func(a, b *Case) int {
+			sa, _ := caseScore(a, provider, enabled)
+			sb, _ := caseScore(b, provider, enabled)
+			return sa - sb
+		}
+	case sortByTokenCount:
+		compare = func(a, b *Case) int { return caseTokenCount(a) - caseTokenCount(b) }
+	default:
+		return fmt.Errorf("unknown sort key: %s", key)
+	}
+
+	sort.SliceStable(cases, func(i, j int) bool {
+		d := compare(cases[i], cases[j])
+		if d == 0 {
+			d = strings.Compare(cases[i].ID, cases[j].ID)
+		}
+		if desc {
+			return d > 0
+		}
+		return d < 0
+	})
+	return nil
+}
+
+// parseCaseFilter reads filter parameters from a query string.
+func parseCaseFilter(q url.Values) (CaseFilter, error) {
+	var f CaseFilter
+	var err error
+	if f.HasEval, err = parseBoolParam(q, "has_eval"); err != nil {
+		return f, err
+	}
+	if f.QuestionableGT, err = parseBoolParam(q, "questionable_gt"); err != nil {
+		return f, err
+	}
+	f.Provider = q.Get("provider")
+	f.Tags = q["tag"]
+	f.ReviewState = q.Get("review_state")
+	f.Assignee = q.Get("assignee")
+	f.Run = q.Get("run")
+	if f.MinScore, err = parseIntParam(q, "min_score"); err != nil {
+		return f, err
+	}
+	if f.MaxScore, err = parseIntParam(q, "max_score"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// parseListCasesRequest reads a ListCasesRequest from a query string.
+func parseListCasesRequest(q url.Values) (ListCasesRequest, error) {
+	var req ListCasesRequest
+	var err error
+	if req.CaseFilter, err = parseCaseFilter(q); err != nil {
+		return req, err
+	}
+	req.Sort = q.Get("sort")
+	if v, err := parseIntParam(q, "page"); err != nil {
+		return req, err
+	} else if v != nil {
+		req.Page = *v
+	}
+	if v, err := parseIntParam(q, "page_size"); err != nil {
+		return req, err
+	} else if v != nil {
+		req.PageSize = *v
+	}
+	return req, nil
+}
+
+func parseBoolParam(q url.Values, name string) (*bool, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q", name, v)
+	}
+	return &b, nil
+}
+
+func parseIntParam(q url.Values, name string) (*int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q", name, v)
+	}
+	return &n, nil
+}
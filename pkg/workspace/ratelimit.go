@@ -0,0 +1,177 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"asr-eval/pkg/workspace/workspacepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// expensiveOps are the API methods that spend LLM or ASR quota, by HTTP
+// method and path suffix.
+var expensiveOps = []struct{ method, suffix string }{
+	{http.MethodPost, ":evaluate"},
+	{http.MethodPost, ":generateContext"},
+	{http.MethodPost, ":runPipeline"},
+	{http.MethodPost, ":transcribe"},
+	{http.MethodPost, ":evaluateAll"},
+	{http.MethodPost, "/api/runs"},      // Evaluates every matching case
+	{http.MethodGet, "/api/cases:live"}, // Streams audio to a provider
+}
+
+// expensiveRPCs are their gRPC counterparts.
+var expensiveRPCs = map[string]bool{
+	workspacepb.Workspace_Evaluate_FullMethodName:        true,
+	workspacepb.Workspace_GenerateContext_FullMethodName: true,
+}
+
+// RateLimiter limits how often each client may call the expensive API
+// methods, with a token bucket per client. Clients are told apart by their
+// authenticated user, or their address when authentication is off.
+type RateLimiter struct {
+	rate  float64 // Tokens per second
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows each client perMinute expensive calls a minute, in
+// bursts of up to burst calls.
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(max(burst, 1)),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Wrap answers expensive requests over the client's limit with 429 Too Many
+// Requests. It must run inside Authenticator.Wrap to see the user.
+func (l *RateLimiter) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isExpensive(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := UserFromContext(r.Context())
+		if key == "" {
+			key, _, _ = net.SplitHostPort(r.RemoteAddr)
+		}
+		if wait, ok := l.allow(key); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, fmt.Sprintf("rate limit exceeded, retry in %v", wait.Round(time.Second)), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnaryInterceptor is Wrap for gRPC. It must be chained after
+// Authenticator.UnaryInterceptor to see the user.
+func (l *RateLimiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if !expensiveRPCs[info.FullMethod] {
+			return next(ctx, req)
+		}
+		key := UserFromContext(ctx)
+		if p, ok := peer.FromContext(ctx); key == "" && ok {
+			key, _, _ = net.SplitHostPort(p.Addr.String())
+		}
+		if wait, ok := l.allow(key); !ok {
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %v", wait.Round(time.Second))
+		}
+		return next(ctx, req)
+	}
+}
+
+func isExpensive(r *http.Request) bool {
+	for _, op := range expensiveOps {
+		if r.Method == op.method && strings.HasSuffix(r.URL.Path, op.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allow takes a token from key's bucket. When the bucket is empty it
+// reports how long until the next token.
+func (l *RateLimiter) allow(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		if l.rate <= 0 {
+			return time.Hour, false
+		}
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// prune drops, at most once a minute, the buckets that have refilled, as
+// they are equivalent to new ones.
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// EvalSlots caps how many evaluations and context generations run at once.
+// One value is shared by every dataset's ServiceConfig, so the cap is
+// global; a nil EvalSlots allows any number.
+type EvalSlots chan struct{}
+
+// NewEvalSlots returns a cap of n concurrent runs.
+func NewEvalSlots(n int) EvalSlots {
+	return make(EvalSlots, n)
+}
+
+// acquire waits for a free slot. The caller must call release when done.
+func (s EvalSlots) acquire(ctx context.Context) (release func(), err error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	select {
+	case s <- struct{}{}:
+		return func() { <-s }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,77 @@
+package workspace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(60, 2)
+	l.now = func() time.Time { return now }
+	h := l.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	call := func(method, path, addr string) int {
+		r := httptest.NewRequest(method, path, nil)
+		r.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec.Code
+	}
+	for i, want := range []int{200, 200, 429} {
+		if got := call("POST", "/api/cases/a:evaluate", "1.2.3.4:1000"); got != want {
+			t.Errorf("call %d = %d, want %d", i, got, want)
+		}
+	}
+	if got := call("POST", "/api/cases/a:evaluate", "1.2.3.4:2000"); got != 429 {
+		t.Errorf("same host, other port = %d, want 429", got)
+	}
+	if got := call("POST", "/api/cases/a:evaluate", "5.6.7.8:1000"); got != 200 {
+		t.Errorf("other client = %d, want 200", got)
+	}
+	if got := call("POST", "/api/cases/a:updateTags", "1.2.3.4:1000"); got != 200 {
+		t.Errorf("cheap op = %d, want 200", got)
+	}
+
+	now = now.Add(time.Second)
+	if got := call("POST", "/api/cases:evaluateAll", "1.2.3.4:1000"); got != 200 {
+		t.Errorf("after refill = %d, want 200", got)
+	}
+
+	for _, tc := range []struct{ method, path string }{
+		{"POST", "/api/runs"},
+		{"GET", "/api/cases:live"},
+	} {
+		addr := "9.9.9.9:1000"
+		for range 2 {
+			call(tc.method, tc.path, addr)
+		}
+		if got := call(tc.method, tc.path, addr); got != 429 {
+			t.Errorf("%s %s over the limit = %d, want 429", tc.method, tc.path, got)
+		}
+		now = now.Add(time.Minute)
+	}
+	if got := call("GET", "/api/runs", "9.9.9.9:1000"); got != 200 {
+		t.Errorf("listing runs = %d, want 200", got)
+	}
+}
+
+func TestEvalSlots(t *testing.T) {
+	slots := NewEvalSlots(1)
+	release, err := slots.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := slots.acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("acquire on a full cap = %v, want %v", err, context.DeadlineExceeded)
+	}
+	release()
+	if _, err := slots.acquire(context.Background()); err != nil {
+		t.Errorf("acquire after release = %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// RecomputeRequest selects the cases whose reports to score again. Without
+// IDs, the cases matching the filter are recomputed.
+type RecomputeRequest struct {
+	CaseFilter
+	IDs    []string
+	DryRun bool // Only report the changes
+}
+
+// RecomputeResponse lists the scores Recompute changed.
+type RecomputeResponse struct {
+	Cases   int           `json:"cases"`   // Cases with a report
+	Updated int           `json:"updated"` // Reports rewritten, or that would be
+	Changes []ScoreChange `json:"changes"`
+}
+
+// ScoreChange is a provider's metrics on a case before and after
+// recomputing them.
+type ScoreChange struct {
+	CaseID   string             `json:"case_id"`
+	Provider string             `json:"provider"`
+	Before   evalv2.EvalMetrics `json:"before"`
+	After    evalv2.EvalMetrics `json:"after"`
+}
+
+// Recompute derives the metrics of the selected cases' reports again from
+// their checkpoint results and phonetic error counts, with no LLM calls,
+// so a change to the scoring applies to results already stored. Changed
+// reports are rewritten, the previous version staying in the report
+// history.
+func (s *Service) Recompute(ctx context.Context, req RecomputeRequest) (*RecomputeResponse, error) {
+	ids, err := s.bundleCaseIDs(ctx, ExportBundleRequest{CaseFilter: req.CaseFilter, IDs: req.IDs})
+	if err != nil {
+		return nil, err
+	}
+	resp := &RecomputeResponse{Changes: []ScoreChange{}}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := s.recomputeCase(ctx, id, req.DryRun, resp); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	return resp, nil
+}
+
+func (s *Service) recomputeCase(ctx context.Context, id string, dryRun bool, resp *RecomputeResponse) error {
+	unlock, err := s.lockCase(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	report, err := s.loadEvalReport(ctx, id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Cases++
+
+	changed := 0
+	for _, p := range slices.Sorted(maps.Keys(report.Results)) {
+		res := report.Results[p]
+		before := res.Metrics
+		res.RecomputeMetrics(&report.ContextSnapshot)
+		res.Metrics.QScore = res.Metrics.CompositeScoreWith(s.sScoreWeight())
+		report.Results[p] = res
+		if !sameScore(before.SScore, res.Metrics.SScore) || !sameScore(before.PScore, res.Metrics.PScore) {
+			resp.Changes = append(resp.Changes, ScoreChange{CaseID: id, Provider: p, Before: before, After: res.Metrics})
+			changed++
+		}
+	}
+	if changed == 0 {
+		return nil
+	}
+	resp.Updated++
+	if dryRun {
+		return nil
+	}
+	if err := s.writeEvalReport(ctx, id, report); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditRecompute, id, map[string]string{"results": strconv.Itoa(changed)})
+	return nil
+}
+
+// sameScore reports whether two S or P scores are equal but for rounding.
+func sameScore(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
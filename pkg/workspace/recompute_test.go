@@ -0,0 +1,64 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestRecompute(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		if err := os.WriteFile(filepath.Join(dir, id+extFlac), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := &evalv2.EvalReport{Results: map[string]evalv2.EvalResult{}}
+	r.ContextSnapshot.Meta.TotalTokenCountEstimate = 10
+	r.ContextSnapshot.Checkpoints = []evalv2.Checkpoint{{ID: "C1", Tier: 1, Weight: 1}}
+	pass := map[string]evalv2.CheckpointResult{"C1": {Status: evalv2.StatusPass}}
+	r.Results["x"] = evalv2.EvalResult{
+		CheckpointResults: pass,
+		Metrics:           evalv2.EvalMetrics{SScore: 1, PScore: 0.9, PhoneticDetails: evalv2.PhoneticDetails{Sub: 1}},
+	}
+	r.Results["y"] = evalv2.EvalResult{
+		CheckpointResults: pass,
+		Metrics:           evalv2.EvalMetrics{SScore: 0.5, PScore: 0.5, PhoneticDetails: evalv2.PhoneticDetails{Del: 2}},
+	}
+	if err := s.Storage.PutReport(ctx, "a", r); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := s.Recompute(ctx, RecomputeRequest{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Cases != 1 || resp.Updated != 1 || len(resp.Changes) != 1 {
+		t.Fatalf("dry run = %+v, want one change in one of one cases", resp)
+	}
+	if c := resp.Changes[0]; c.Provider != "y" || c.After.SScore != 1 || c.After.PScore != 0.8 || c.After.QScore != 94 {
+		t.Errorf("change = %+v, want y with S 1, P 0.8 and Q 94", c)
+	}
+	if got, _ := s.loadEvalReport(ctx, "a"); got.Results["y"].Metrics.SScore != 0.5 {
+		t.Error("dry run rewrote the report")
+	}
+
+	if _, err := s.Recompute(ctx, RecomputeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.loadEvalReport(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := got.Results["y"].Metrics; m.SScore != 1 || m.PScore != 0.8 {
+		t.Errorf("y metrics = %+v, want S 1 and P 0.8", m)
+	}
+	if resp, err := s.Recompute(ctx, RecomputeRequest{}); err != nil || resp.Updated != 0 {
+		t.Errorf("second Recompute = %+v, %v, want nothing updated", resp, err)
+	}
+}
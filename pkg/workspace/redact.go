@@ -0,0 +1,142 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/redact"
+)
+
+// RedactRequest selects the cases to redact and where the copies go.
+// Without IDs, the cases matching the filter are redacted.
+type RedactRequest struct {
+	CaseFilter
+	IDs    []string
+	OutDir string // Directory of the redacted copy; must not be the dataset's
+	Audio  bool   // Also copy the audio, which is not redacted
+	LLM    bool   // Also ask the generation model for names and spelled-out numbers
+}
+
+// RedactResponse lists the placeholders of each redacted case.
+type RedactResponse struct {
+	Cases map[string][]redact.Replacement `json:"cases"` // By case ID
+}
+
+// Redact writes copies of the selected cases' transcripts, contexts and
+// tags to req.OutDir with phone and ID numbers, email addresses and, with
+// req.LLM, names replaced by placeholders. A value has the same
+// placeholder across a case's ground truth, transcripts and context, so
+// the copy can be evaluated again. Reports, reviews and comments are not
+// copied. The response maps the placeholders back and must not be shared
+// with the copy.
+func (s *Service) Redact(ctx context.Context, req RedactRequest) (*RedactResponse, error) {
+	if req.OutDir == "" {
+		return nil, errors.New("no output directory")
+	}
+	if same, err := sameDir(s.Config.DatasetDir, req.OutDir); err != nil || same {
+		return nil, fmt.Errorf("output directory must not be the dataset: %s", req.OutDir)
+	}
+	if req.LLM && s.LLM == nil {
+		return nil, errors.New("LLM client not initialized")
+	}
+	ids, err := s.bundleCaseIDs(ctx, ExportBundleRequest{CaseFilter: req.CaseFilter, IDs: req.IDs})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(req.OutDir, 0755); err != nil {
+		return nil, err
+	}
+	dst := NewFSStorage(req.OutDir)
+	defer dst.Close()
+
+	resp := &RedactResponse{Cases: make(map[string][]redact.Replacement)}
+	for _, id := range ids {
+		r, err := s.redactCase(ctx, dst, id, req)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		resp.Cases[id] = r.Replacements()
+	}
+	return resp, nil
+}
+
+func (s *Service) redactCase(ctx context.Context, dst Storage, id string, req RedactRequest) (*redact.Redactor, error) {
+	c, err := s.GetCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var texts []string
+	if ec := c.EvalContext; ec != nil {
+		texts = append(texts, ec.Meta.GroundTruth, ec.Meta.AudioRealityInference)
+	}
+	for _, t := range c.Transcripts {
+		texts = append(texts, t)
+	}
+	var extra []redact.Entity
+	if req.LLM {
+		if extra, err = redact.FindLLM(ctx, s.LLM, s.Config.GenModel, texts); err != nil {
+			return nil, fmt.Errorf("find entities: %w", err)
+		}
+	}
+
+	r := redact.NewRedactor()
+	// The ground truth goes first, so its values get the low numbers.
+	if ec := c.EvalContext; ec != nil {
+		red := *ec
+		m := &red.Meta
+		m.GroundTruth = r.Redact(m.GroundTruth, extra)
+		m.AudioRealityInference = r.Redact(m.AudioRealityInference, extra)
+		m.BusinessGoal = r.Redact(m.BusinessGoal, extra)
+		m.QuestionableReason = r.Redact(m.QuestionableReason, extra)
+		red.Checkpoints = make([]evalv2.Checkpoint, len(ec.Checkpoints))
+		for i, cp := range ec.Checkpoints {
+			cp.TextSegment = r.Redact(cp.TextSegment, extra)
+			cp.Rationale = r.Redact(cp.Rationale, extra)
+			red.Checkpoints[i] = cp
+		}
+		red.Hash = contextHash(&red)
+		if err := dst.PutContext(ctx, id, &red); err != nil {
+			return nil, err
+		}
+	}
+	for p, t := range c.Transcripts {
+		if err := dst.PutTranscript(ctx, id, p, r.Redact(t, extra)); err != nil {
+			return nil, err
+		}
+	}
+	if err := dst.PutTags(ctx, id, c.Tags); err != nil {
+		return nil, err
+	}
+	if req.Audio {
+		f, err := os.Open(filepath.Join(s.Config.DatasetDir, id+extFlac))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if err := atomicfile.Copy(filepath.Join(req.OutDir, id+extFlac), f); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// sameDir reports whether a and b are the same directory; b need not
+// exist.
+func sameDir(a, b string) (bool, error) {
+	fa, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	fb, err := os.Stat(b)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return os.SameFile(fa, fb), nil
+}
@@ -0,0 +1,58 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/redact"
+)
+
+func TestRedact(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	ec := &evalv2.EvalContext{Checkpoints: []evalv2.Checkpoint{{ID: "C1", TextSegment: "13812345678"}}}
+	ec.Meta.GroundTruth = "电话13812345678"
+	if err := s.Storage.PutContext(ctx, "a", ec); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Storage.PutTranscript(ctx, "a", "x", "电话138-1234-5678"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Redact(ctx, RedactRequest{OutDir: dir}); err == nil {
+		t.Error("redacting into the dataset succeeded, want error")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	resp, err := s.Redact(ctx, RedactRequest{OutDir: out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []redact.Replacement{{Placeholder: "[PHONE_1]", Kind: redact.KindPhone, Value: "13812345678"}}
+	if diff := cmp.Diff(want, resp.Cases["a"]); diff != "" {
+		t.Errorf("replacements mismatch (-want +got):\n%s", diff)
+	}
+
+	dst := NewFSStorage(out)
+	got, err := dst.GetContext(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Meta.GroundTruth != "电话[PHONE_1]" || got.Checkpoints[0].TextSegment != "[PHONE_1]" {
+		t.Errorf("redacted context = %q, %q", got.Meta.GroundTruth, got.Checkpoints[0].TextSegment)
+	}
+	if b, err := os.ReadFile(filepath.Join(out, "a.x")); err != nil || string(b) != "电话[PHONE_1]" {
+		t.Errorf("redacted transcript = %q, %v", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "a.flac")); !os.IsNotExist(err) {
+		t.Errorf("audio copied without Audio: %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// RenameCasesRequest maps case IDs to their new IDs.
+type RenameCasesRequest struct {
+	IDs          map[string]string // New ID by current ID
+	ValidateOnly bool              // List the files without renaming them
+}
+
+// RenameCasesResponse lists what was renamed, or would be.
+type RenameCasesResponse struct {
+	Cases []RenamedCase `json:"cases"` // Sorted by current ID
+}
+
+// RenamedCase is one case renamed.
+type RenamedCase struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Files []string `json:"files"`          // Dataset files, by their current name
+	Runs  []string `json:"runs,omitempty"` // Runs that include the case
+}
+
+// RenameCases gives cases new IDs: their dataset files, audio, transcripts,
+// stream dumps, ground truth, contexts, reports, their archived versions
+// and sidecars, are renamed, and so are their reports in runs and the
+// run records listing them. Every rename is checked before any is made: a
+// case must exist, a new ID must not be taken, and an ID cannot be both
+// renamed and a new ID. Cases with unfinished jobs are not renamed.
+func (s *Service) RenameCases(ctx context.Context, req RenameCasesRequest) (*RenameCasesResponse, error) {
+	if len(req.IDs) == 0 {
+		return nil, errors.New("no cases to rename")
+	}
+	runs, err := s.ListRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &RenameCasesResponse{}
+	seen := make(map[string]string)
+	for _, from := range slices.Sorted(maps.Keys(req.IDs)) {
+		to := req.IDs[from]
+		for _, id := range []string{from, to} {
+			if id == "" || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+				return nil, fmt.Errorf("invalid case ID: %q", id)
+			}
+		}
+		if from == to {
+			return nil, fmt.Errorf("%s is renamed to itself", from)
+		}
+		if _, ok := req.IDs[to]; ok {
+			return nil, fmt.Errorf("%s is both renamed and a new ID", to)
+		}
+		if other, ok := seen[to]; ok {
+			return nil, fmt.Errorf("%s and %s are both renamed to %s", other, from, to)
+		}
+		seen[to] = from
+
+		if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, from+extFlac)); err != nil {
+			return nil, fmt.Errorf("case not found: %s", from)
+		}
+		files, err := caseFiles(s.Config.DatasetDir, from)
+		if err != nil {
+			return nil, err
+		}
+		taken, err := caseFiles(s.Config.DatasetDir, to)
+		if err != nil {
+			return nil, err
+		}
+		if len(taken) > 0 {
+			return nil, fmt.Errorf("case %s already exists", to)
+		}
+		rc := RenamedCase{From: from, To: to, Files: files}
+		for _, run := range runs {
+			if slices.Contains(run.Cases, from) || slices.Contains(run.Skipped, from) {
+				rc.Runs = append(rc.Runs, run.ID)
+			}
+		}
+		resp.Cases = append(resp.Cases, rc)
+	}
+	if req.ValidateOnly {
+		return resp, nil
+	}
+
+	for _, rc := range resp.Cases {
+		if err := s.renameCase(ctx, rc); err != nil {
+			return nil, fmt.Errorf("rename %s to %s: %w", rc.From, rc.To, err)
+		}
+	}
+	return resp, nil
+}
+
+func (s *Service) renameCase(ctx context.Context, rc RenamedCase) error {
+	unlockFrom, err := s.lockCase(ctx, rc.From)
+	if err != nil {
+		return err
+	}
+	defer unlockFrom()
+	unlockTo, err := s.lockCase(ctx, rc.To)
+	if err != nil {
+		return err
+	}
+	defer unlockTo()
+	if s.Jobs != nil {
+		for _, job := range s.Jobs.List(rc.From) {
+			if !job.Done() {
+				return fmt.Errorf("job %s is not finished", job.ID)
+			}
+		}
+	}
+
+	// SQLite keeps the records apart from the files; read them before the
+	// files move.
+	var (
+		c  *Case
+		ec *evalv2.EvalContext
+	)
+	if _, ok := s.Storage.(*SQLiteStorage); ok {
+		if c, err = s.Storage.GetCase(ctx, rc.From); err != nil {
+			return err
+		}
+		if ec, err = s.Storage.GetContext(ctx, rc.From); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	dir := s.Config.DatasetDir
+	for i, name := range rc.Files {
+		newName := rc.To + strings.TrimPrefix(name, rc.From)
+		if err := os.Rename(filepath.Join(dir, name), filepath.Join(dir, newName)); err != nil {
+			// Put back what was moved, so the case is whole under one ID.
+			for _, moved := range rc.Files[:i] {
+				os.Rename(filepath.Join(dir, rc.To+strings.TrimPrefix(moved, rc.From)), filepath.Join(dir, moved))
+			}
+			return err
+		}
+	}
+	if c != nil {
+		if err := s.copyCaseRecords(ctx, c, ec, rc.To); err != nil {
+			return err
+		}
+		if err := s.Storage.DeleteCase(ctx, rc.From); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range rc.Runs {
+		if err := s.renameRunCase(ctx, id, rc.From, rc.To); err != nil {
+			return fmt.Errorf("run %s: %w", id, err)
+		}
+	}
+	s.audit(ctx, AuditRenameCase, rc.To, map[string]string{"from": rc.From})
+	return nil
+}
+
+// copyCaseRecords writes the storage records of c, and its own context ec
+// if any, under the case ID id.
+func (s *Service) copyCaseRecords(ctx context.Context, c *Case, ec *evalv2.EvalContext, id string) error {
+	if err := s.Storage.PutCase(ctx, id); err != nil {
+		return err
+	}
+	for p, t := range c.Transcripts {
+		if err := s.Storage.PutTranscript(ctx, id, p, t); err != nil {
+			return err
+		}
+	}
+	if ec != nil {
+		if err := s.Storage.PutContext(ctx, id, ec); err != nil {
+			return err
+		}
+	}
+	if c.ReportV2 != nil {
+		if err := s.Storage.PutReport(ctx, id, c.ReportV2); err != nil {
+			return err
+		}
+	}
+	return s.Storage.PutTags(ctx, id, c.Tags)
+}
+
+// renameRunCase renames case from to to in the record and reports of run
+// id.
+func (s *Service) renameRunCase(ctx context.Context, id, from, to string) error {
+	run, err := s.GetRun(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, ids := range [][]string{run.Cases, run.Skipped} {
+		for i, c := range ids {
+			if c == from {
+				ids[i] = to
+			}
+		}
+	}
+	sort.Strings(run.Cases)
+	sort.Strings(run.Skipped)
+	dir := s.runDir(id)
+	err = os.Rename(filepath.Join(dir, from+extReportV2), filepath.Join(dir, to+extReportV2))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return s.writeRun(run)
+}
@@ -0,0 +1,83 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRenameCases(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	for _, name := range []string{
+		"a.flac", "a.dg", "a.report.v2.json", "a.report.v2.1.json",
+		"a.b.flac", "a.b.dg",
+		"c.flac",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(s.runDir("r1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.writeRun(&Run{ID: "r1", Cases: []string{"a", "c"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.runDir("r1"), "a"+extReportV2), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ids := range []map[string]string{
+		{"a": "c"},
+		{"a": "x", "c": "x"},
+		{"a": "c", "c": "d"},
+		{"missing": "x"},
+		{"a": "../x"},
+	} {
+		if _, err := s.RenameCases(ctx, RenameCasesRequest{IDs: ids}); err == nil {
+			t.Errorf("RenameCases(%v) succeeded, want error", ids)
+		}
+	}
+
+	resp, err := s.RenameCases(ctx, RenameCasesRequest{IDs: map[string]string{"a": "z"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RenamedCase{{
+		From:  "a",
+		To:    "z",
+		Files: []string{"a.dg", "a.flac", "a.report.v2.1.json", "a.report.v2.json"},
+		Runs:  []string{"r1"},
+	}}
+	if diff := cmp.Diff(want, resp.Cases); diff != "" {
+		t.Errorf("response mismatch (-want +got):\n%s", diff)
+	}
+	for id, wantFiles := range map[string][]string{
+		"z":   {"z.dg", "z.flac", "z.report.v2.1.json", "z.report.v2.json"},
+		"a":   nil,
+		"a.b": {"a.b.dg", "a.b.flac"},
+	} {
+		got, err := caseFiles(dir, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(wantFiles, got); diff != "" {
+			t.Errorf("files of %s mismatch (-want +got):\n%s", id, diff)
+		}
+	}
+	run, err := s.GetRun(ctx, "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"c", "z"}, run.Cases); diff != "" {
+		t.Errorf("run cases mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(s.runDir("r1"), "z"+extReportV2)); err != nil {
+		t.Errorf("run report not renamed: %v", err)
+	}
+}
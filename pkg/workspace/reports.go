@@ -0,0 +1,172 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// ListReports returns the current report of a case followed by its archived
+// versions, newest first.
+func (s *Service) ListReports(ctx context.Context, id string) ([]*ReportVersion, error) {
+	versions, err := s.Storage.ListReportVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var out []*ReportVersion
+	for i := len(versions) - 1; i >= 0; i-- {
+		v, err := s.reportVersion(ctx, id, versions[i])
+		if err != nil {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (s *Service) reportVersion(ctx context.Context, id string, rv RecordVersion) (*ReportVersion, error) {
+	var (
+		report *evalv2.EvalReport
+		err    error
+	)
+	if rv.N == 0 {
+		report, err = s.Storage.GetReport(ctx, id)
+	} else {
+		report, err = s.Storage.GetReportVersion(ctx, id, rv.N)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.fillQScores(report)
+	v := &ReportVersion{
+		Version:     rv.N,
+		UpdateTime:  rv.UpdateTime,
+		ContextHash: report.ContextSnapshot.Hash,
+		QScores:     make(map[string]int, len(report.Results)),
+	}
+	models := make(map[string]bool)
+	for p, r := range report.Results {
+		v.QScores[p] = r.Metrics.QScore
+		if r.Model != "" {
+			models[r.Model] = true
+		}
+		for _, vd := range r.Verdicts {
+			models[vd.Model] = true
+		}
+	}
+	for m := range models {
+		v.Models = append(v.Models, m)
+	}
+	sort.Strings(v.Models)
+	return v, nil
+}
+
+// RollbackReport makes an archived report version current again. The
+// replaced report is archived, so a rollback can itself be undone.
+func (s *Service) RollbackReport(ctx context.Context, req RollbackReportRequest) (*evalv2.EvalReport, error) {
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	report, err := s.Storage.GetReportVersion(ctx, req.ID, req.Version)
+	if err != nil {
+		return nil, fmt.Errorf("version %d not found", req.Version)
+	}
+	if _, err := s.Storage.ArchiveReport(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	if err := s.Storage.PutReport(ctx, req.ID, report); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditRollbackReport, req.ID, map[string]string{"version": strconv.Itoa(req.Version)})
+	return s.loadEvalReport(ctx, req.ID)
+}
+
+// ResetResults removes providers' results from a case's report, keeping
+// the other results and the context snapshot, so they can be re-evaluated
+// alone. Removing the last result removes the report. The previous report
+// stays in the report history.
+func (s *Service) ResetResults(ctx context.Context, req ResetResultsRequest) (*evalv2.EvalReport, error) {
+	if len(req.ProviderIDs) == 0 {
+		return nil, fmt.Errorf("provider_ids is required")
+	}
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	report, err := s.loadEvalReport(ctx, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("report not found: %s", req.ID)
+	}
+	for _, p := range req.ProviderIDs {
+		if _, ok := report.Results[p]; !ok {
+			return nil, fmt.Errorf("no result for provider %q", p)
+		}
+		delete(report.Results, p)
+	}
+
+	if len(report.Results) == 0 {
+		if _, err = s.Storage.ArchiveReport(ctx, req.ID); err == nil {
+			err = s.Storage.DeleteReport(ctx, req.ID)
+		}
+	} else {
+		err = s.writeEvalReport(ctx, req.ID, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditResetResults, req.ID, map[string]string{"providers": strings.Join(req.ProviderIDs, ",")})
+	return report, nil
+}
+
+// OverrideCheckpoint flips a checkpoint's status for one provider on the
+// reviewer's authority, recomputing the provider's scores. The previous
+// report stays in the report history.
+func (s *Service) OverrideCheckpoint(ctx context.Context, req OverrideCheckpointRequest) (*evalv2.EvalReport, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	report, err := s.loadEvalReport(ctx, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("report not found: %s", req.ID)
+	}
+	result, ok := report.Results[req.Provider]
+	if !ok {
+		return nil, fmt.Errorf("no result for provider %q", req.Provider)
+	}
+
+	err = result.OverrideCheckpoint(&report.ContextSnapshot, req.CheckpointID, req.Status, evalv2.CheckpointOverride{
+		Reason: req.Reason,
+		Author: UserFromContext(ctx),
+		Time:   time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Metrics.QScore = result.Metrics.CompositeScoreWith(s.sScoreWeight())
+	report.Results[req.Provider] = result
+
+	if err := s.writeEvalReport(ctx, req.ID, report); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditOverrideCheckpoint, req.ID, map[string]string{
+		"provider":        req.Provider,
+		"checkpoint":      req.CheckpointID,
+		"status":          string(req.Status),
+		"original_status": string(result.CheckpointResults[req.CheckpointID].Override.OriginalStatus),
+		"reason":          req.Reason,
+	})
+	return report, nil
+}
@@ -0,0 +1,133 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"asr-eval/pkg/atomicfile"
+)
+
+// extReview is the per-case sidecar holding its Review. Cases without one
+// are unreviewed.
+const extReview = ".review.json"
+
+// ReviewState is where a case is in the adjudication workflow.
+type ReviewState string
+
+const (
+	ReviewUnreviewed ReviewState = "unreviewed"
+	ReviewInReview   ReviewState = "in_review"
+	ReviewApproved   ReviewState = "approved"
+	ReviewDisputed   ReviewState = "disputed"
+)
+
+// reviewTransitions lists the states each state may advance to.
+var reviewTransitions = map[ReviewState][]ReviewState{
+	ReviewUnreviewed: {ReviewInReview},
+	ReviewInReview:   {ReviewApproved, ReviewDisputed, ReviewUnreviewed},
+	ReviewDisputed:   {ReviewInReview, ReviewApproved},
+	ReviewApproved:   {ReviewInReview},
+}
+
+// Review is the adjudication state of a case.
+type Review struct {
+	State      ReviewState `json:"state"`
+	Assignee   string      `json:"assignee,omitempty"`
+	Note       string      `json:"note,omitempty"` // Why the state last changed, e.g. what is disputed
+	UpdatedBy  string      `json:"updated_by,omitempty"`
+	UpdateTime time.Time   `json:"update_time"`
+}
+
+// UpdateReview advances a case's review state and returns the updated case.
+// Entering in_review without an assignee assigns the current user.
+func (s *Service) UpdateReview(ctx context.Context, req UpdateReviewRequest) (*Case, error) {
+	if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, req.ID+extFlac)); err != nil {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	prev, err := s.loadReview(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	from := reviewState(prev)
+	if req.State != from && !slices.Contains(reviewTransitions[from], req.State) {
+		return nil, fmt.Errorf("cannot move review from %s to %s", from, req.State)
+	}
+
+	user := UserFromContext(ctx)
+	r := &Review{
+		State:      req.State,
+		Assignee:   req.Assignee,
+		Note:       req.Note,
+		UpdatedBy:  user,
+		UpdateTime: time.Now().UTC(),
+	}
+	if r.Assignee == "" && prev != nil {
+		r.Assignee = prev.Assignee
+	}
+	if r.State == ReviewInReview && r.Assignee == "" {
+		r.Assignee = user
+	}
+	if r.State == ReviewUnreviewed {
+		r.Assignee = ""
+	}
+	if err := s.writeReview(req.ID, r); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditUpdateReview, req.ID, map[string]string{
+		"from":     string(from),
+		"to":       string(r.State),
+		"assignee": r.Assignee,
+	})
+	return s.GetCase(ctx, req.ID)
+}
+
+// reviewState returns the state of r, treating nil as unreviewed.
+func reviewState(r *Review) ReviewState {
+	if r == nil {
+		return ReviewUnreviewed
+	}
+	return r.State
+}
+
+func (s *Service) loadReview(id string) (*Review, error) {
+	content, err := os.ReadFile(filepath.Join(s.Config.DatasetDir, id+extReview))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var r Review
+	if err := json.Unmarshal(content, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// writeReview stores r for id, removing the sidecar once the case is back
+// to unreviewed.
+func (s *Service) writeReview(id string, r *Review) error {
+	filename := filepath.Join(s.Config.DatasetDir, id+extReview)
+	if r.State == ReviewUnreviewed {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, bytes)
+}
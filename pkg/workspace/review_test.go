@@ -0,0 +1,53 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateReview(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+
+	if _, err := s.UpdateReview(ctx, UpdateReviewRequest{ID: "a", State: ReviewApproved}); err == nil {
+		t.Error("unreviewed -> approved succeeded, want error")
+	}
+
+	c, err := s.UpdateReview(ctx, UpdateReviewRequest{ID: "a", State: ReviewInReview, Assignee: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Review == nil || c.Review.State != ReviewInReview || c.Review.Assignee != "alice" {
+		t.Fatalf("Review = %+v, want in_review by alice", c.Review)
+	}
+
+	c, err = s.UpdateReview(ctx, UpdateReviewRequest{ID: "a", State: ReviewDisputed, Note: "GT misses the refund amount"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Review.State != ReviewDisputed || c.Review.Assignee != "alice" {
+		t.Errorf("Review = %+v, want disputed keeping alice", c.Review)
+	}
+
+	f := CaseFilter{ReviewState: string(ReviewDisputed), Assignee: "alice"}
+	if !f.Match(c, nil) {
+		t.Errorf("filter %+v does not match %+v", f, c.Review)
+	}
+
+	if _, err := s.UpdateReview(ctx, UpdateReviewRequest{ID: "a", State: ReviewInReview}); err != nil {
+		t.Fatal(err)
+	}
+	c, err = s.UpdateReview(ctx, UpdateReviewRequest{ID: "a", State: ReviewUnreviewed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Review != nil {
+		t.Errorf("Review = %+v, want nil once unreviewed", c.Review)
+	}
+}
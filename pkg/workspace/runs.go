@@ -0,0 +1,416 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/evalv2"
+)
+
+// runsDirName holds one directory per run: its run.json record and a copy
+// of every report the run produced, named like the case reports.
+const (
+	runsDirName = ".runs"
+	runFileName = "run.json"
+)
+
+var runIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// Run is a named evaluation of a set of cases with fixed settings. Its
+// reports are kept apart from the current case reports, so stats can be
+// computed for a run, and two runs compared, whatever was evaluated since.
+type Run struct {
+	ID            string     `json:"id"`
+	Description   string     `json:"description,omitempty"`
+	Filter        CaseFilter `json:"filter"`
+	ProviderIDs   []string   `json:"provider_ids"`
+	EvalModel     string     `json:"eval_model"`
+	JudgeModels   []string   `json:"judge_models,omitempty"`
+	PromptVersion string     `json:"prompt_version"` // evalv2.PromptVersion at creation
+	Cases         []string   `json:"cases"`
+	Skipped       []string   `json:"skipped,omitempty"` // Matching cases without an eval context
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreateTime    time.Time  `json:"create_time"`
+}
+
+// CreateRun records a run and queues an evaluation job per matching case,
+// as EvaluateAll does. Each finished evaluation is also saved to the run.
+func (s *Service) CreateRun(ctx context.Context, req CreateRunRequest) (*CreateRunResponse, error) {
+	if !runIDPattern.MatchString(req.ID) {
+		return nil, fmt.Errorf("invalid run ID: %q", req.ID)
+	}
+	dir := s.runDir(req.ID)
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Mkdir(dir, 0755); errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("run %s already exists", req.ID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	cases, err := s.scanCasesFor(ctx, req.CaseFilter)
+	if err != nil {
+		return nil, err
+	}
+	providers := req.ProviderIDs
+	if len(providers) == 0 {
+		providers = s.EnabledProviderIDs()
+	}
+	judges := req.JudgeModels
+	if len(judges) == 0 {
+		judges = s.Config.JudgeModels
+	}
+	run := &Run{
+		ID:            req.ID,
+		Description:   req.Description,
+		Filter:        req.CaseFilter,
+		ProviderIDs:   providers,
+		EvalModel:     s.Config.EvalModel,
+		JudgeModels:   judges,
+		PromptVersion: evalv2.PromptVersion,
+		Cases:         []string{},
+		CreatedBy:     UserFromContext(ctx),
+		CreateTime:    time.Now().UTC(),
+	}
+	enabled := s.enabledProviders()
+	var evals []EvaluateRequest
+	for _, c := range cases {
+		if !req.Match(c, enabled) {
+			continue
+		}
+		if c.EvalContext == nil {
+			run.Skipped = append(run.Skipped, c.ID)
+			continue
+		}
+		run.Cases = append(run.Cases, c.ID)
+		evals = append(evals, EvaluateRequest{
+			ID:          c.ID,
+			EvalContext: c.EvalContext,
+			ProviderIDs: providers,
+			JudgeModels: req.JudgeModels,
+		})
+	}
+	if err := s.writeRun(run); err != nil {
+		return nil, err
+	}
+
+	resp := &CreateRunResponse{Run: run}
+	s.beginBatch(run.ID)
+	defer s.sealBatch(run.ID)
+	for _, evalReq := range evals {
+		s.addToBatch(run.ID)
+		job := s.Jobs.SubmitBatch(run.ID, JobEvaluate, evalReq.ID, func(ctx context.Context) (any, error) {
+			report, err := s.Evaluate(ctx, evalReq)
+			if err != nil {
+				return nil, err
+			}
+			return report, s.writeRunReport(run.ID, evalReq.ID, report, evalReq.ProviderIDs)
+		})
+		resp.Jobs = append(resp.Jobs, job)
+	}
+	return resp, nil
+}
+
+// ListRuns returns the runs, newest first.
+func (s *Service) ListRuns(ctx context.Context) ([]*Run, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Config.DatasetDir, runsDirName))
+	if os.IsNotExist(err) {
+		return []*Run{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	runs := []*Run{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if run, err := s.GetRun(ctx, e.Name()); err == nil {
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].CreateTime.After(runs[j].CreateTime) })
+	return runs, nil
+}
+
+// GetRun returns the run with id.
+func (s *Service) GetRun(ctx context.Context, id string) (*Run, error) {
+	if !runIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid run ID: %q", id)
+	}
+	content, err := os.ReadFile(filepath.Join(s.runDir(id), runFileName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("run not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var run Run
+	if err := json.Unmarshal(content, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// DefaultCompareThreshold is the Q change of a case that CompareRuns
+// reports when the request sets none.
+const DefaultCompareThreshold = 10
+
+// CompareRuns ranks the providers of runs a and b over the cases both
+// evaluated, so the runs are compared like for like, and lists the cases
+// that moved and the Tier-1 checkpoints that newly fail in b. Cases
+// flagged for audio issues are left out.
+func (s *Service) CompareRuns(ctx context.Context, req CompareRunsRequest) (*CompareRunsResponse, error) {
+	if req.A == "" || req.B == "" || req.A == req.B {
+		return nil, fmt.Errorf("a and b must name two different runs")
+	}
+	cases, err := s.scanCases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reportsA, err := s.loadRunReports(req.A)
+	if err != nil {
+		return nil, err
+	}
+	reportsB, err := s.loadRunReports(req.B)
+	if err != nil {
+		return nil, err
+	}
+	flagged := s.flaggedAudio(ctx)
+	casesA := make(map[string]*Case)
+	casesB := make(map[string]*Case)
+	for _, c := range cases {
+		ra, rb := reportsA[c.ID], reportsB[c.ID]
+		if ra == nil || rb == nil || flagged[c.ID] {
+			continue
+		}
+		ca, cb := *c, *c
+		ca.ReportV2, cb.ReportV2 = ra, rb
+		casesA[c.ID], casesB[c.ID] = &ca, &cb
+	}
+	return compareCases(req, casesA, casesB, s.sScoreWeight()), nil
+}
+
+// CompareDatasets is CompareRuns over the current reports of the dataset
+// of s, as a, and that of other, as b: two copies of a dataset evaluated
+// with different settings. Cases either flags for audio issues are left
+// out.
+func (s *Service) CompareDatasets(ctx context.Context, other *Service, threshold int) (*CompareRunsResponse, error) {
+	byID := func(svc *Service) (map[string]*Case, error) {
+		cases, err := svc.scanCases(ctx)
+		if err != nil {
+			return nil, err
+		}
+		flagged := svc.flaggedAudio(ctx)
+		m := make(map[string]*Case, len(cases))
+		for _, c := range cases {
+			if c.ReportV2 != nil && !flagged[c.ID] {
+				m[c.ID] = c
+			}
+		}
+		return m, nil
+	}
+	casesA, err := byID(s)
+	if err != nil {
+		return nil, err
+	}
+	casesB, err := byID(other)
+	if err != nil {
+		return nil, err
+	}
+	for id := range casesA {
+		if casesB[id] == nil {
+			delete(casesA, id)
+		}
+	}
+	for id := range casesB {
+		if casesA[id] == nil {
+			delete(casesB, id)
+		}
+	}
+	req := CompareRunsRequest{A: s.Config.DatasetDir, B: other.Config.DatasetDir, Threshold: threshold}
+	return compareCases(req, casesA, casesB, s.sScoreWeight()), nil
+}
+
+// compareCases compares the reports of the cases in a and b, which hold
+// the same case IDs.
+func compareCases(req CompareRunsRequest, a, b map[string]*Case, sWeight float64) *CompareRunsResponse {
+	threshold := req.Threshold
+	if threshold <= 0 {
+		threshold = DefaultCompareThreshold
+	}
+	resp := &CompareRunsResponse{
+		A:           req.A,
+		B:           req.B,
+		Threshold:   threshold,
+		Providers:   []RunComparison{},
+		Moved:       []CaseScoreDelta{},
+		NewFailures: []CheckpointRegression{},
+	}
+	lbA := Leaderboard{SScoreWeight: sWeight}
+	lbB := Leaderboard{SScoreWeight: sWeight}
+	for id, ca := range a {
+		cb := b[id]
+		resp.Cases++
+		lbA.Add(ca.ReportV2, reportTokenCount(ca))
+		lbB.Add(cb.ReportV2, reportTokenCount(cb))
+		ra, rb := ca.ReportV2, cb.ReportV2
+		for p, resB := range rb.Results {
+			resA, ok := ra.Results[p]
+			if !ok {
+				continue
+			}
+			qa, qb := resA.Metrics.CompositeScoreWith(sWeight), resB.Metrics.CompositeScoreWith(sWeight)
+			if d := qb - qa; d > threshold || -d > threshold {
+				resp.Moved = append(resp.Moved, CaseScoreDelta{CaseID: id, Provider: p, A: qa, B: qb, Delta: d})
+			}
+			for _, cp := range rb.ContextSnapshot.Checkpoints {
+				if cp.Tier != 1 || resB.CheckpointResults[cp.ID].Status != evalv2.StatusFail {
+					continue
+				}
+				was, ok := resA.CheckpointResults[cp.ID]
+				if !ok || was.Status == evalv2.StatusFail {
+					continue
+				}
+				resp.NewFailures = append(resp.NewFailures, CheckpointRegression{
+					CaseID: id, Provider: p, CheckpointID: cp.ID, Text: cp.TextSegment, Was: was.Status,
+				})
+			}
+		}
+	}
+	sort.Slice(resp.Moved, func(i, j int) bool {
+		x, y := resp.Moved[i], resp.Moved[j]
+		if x.Delta != y.Delta {
+			return x.Delta < y.Delta // Largest drops first
+		}
+		if x.CaseID != y.CaseID {
+			return x.CaseID < y.CaseID
+		}
+		return x.Provider < y.Provider
+	})
+	sort.Slice(resp.NewFailures, func(i, j int) bool {
+		x, y := resp.NewFailures[i], resp.NewFailures[j]
+		if x.CaseID != y.CaseID {
+			return x.CaseID < y.CaseID
+		}
+		if x.Provider != y.Provider {
+			return x.Provider < y.Provider
+		}
+		return x.CheckpointID < y.CheckpointID
+	})
+
+	byProvider := make(map[string]*RunComparison)
+	for _, st := range lbA.Rankings() {
+		byProvider[st.Provider] = &RunComparison{Provider: st.Provider, A: &st}
+	}
+	for _, st := range lbB.Rankings() {
+		rc, ok := byProvider[st.Provider]
+		if !ok {
+			rc = &RunComparison{Provider: st.Provider}
+			byProvider[st.Provider] = rc
+		}
+		rc.B = &st
+		if rc.A != nil {
+			rc.DeltaQ = st.WeightedQ - rc.A.WeightedQ
+		}
+	}
+	for _, rc := range byProvider {
+		resp.Providers = append(resp.Providers, *rc)
+	}
+	sort.Slice(resp.Providers, func(i, j int) bool { return resp.Providers[i].Provider < resp.Providers[j].Provider })
+	return resp
+}
+
+// scanCasesFor is scanCases with the reports of filter.Run, if set, in
+// place of the current ones. Cases the run did not evaluate have none.
+func (s *Service) scanCasesFor(ctx context.Context, filter CaseFilter) ([]*Case, error) {
+	cases, err := s.scanCases(ctx)
+	if err != nil || filter.Run == "" {
+		return cases, err
+	}
+	if _, err := s.GetRun(ctx, filter.Run); err != nil {
+		return nil, err
+	}
+	reports, err := s.loadRunReports(filter.Run)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Case, len(cases))
+	for i, c := range cases {
+		rc := *c
+		rc.ReportV2 = reports[c.ID]
+		out[i] = &rc
+	}
+	return out, nil
+}
+
+func (s *Service) runDir(id string) string {
+	return filepath.Join(s.Config.DatasetDir, runsDirName, id)
+}
+
+func (s *Service) writeRun(run *Run) error {
+	bytes, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filepath.Join(s.runDir(run.ID), runFileName), bytes)
+}
+
+// writeRunReport saves the providers' results in report as the run's
+// report for case id. Evaluate returns them merged with the case's other
+// results, which the run did not produce.
+func (s *Service) writeRunReport(runID, id string, report *evalv2.EvalReport, providers []string) error {
+	r, err := storeReportContext(s.Config.DatasetDir, report)
+	if err != nil {
+		return err
+	}
+	stampReport(r)
+	r.Results = make(map[string]evalv2.EvalResult, len(providers))
+	for _, p := range providers {
+		if res, ok := report.Results[p]; ok {
+			r.Results[p] = res
+		}
+	}
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeReportFile(filepath.Join(s.runDir(runID), id+extReportV2), bytes, s.Config.CompressReports)
+}
+
+// loadRunReports returns the reports of run id by case.
+func (s *Service) loadRunReports(id string) (map[string]*evalv2.EvalReport, error) {
+	if !runIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid run ID: %q", id)
+	}
+	entries, err := os.ReadDir(s.runDir(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("run not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reports := make(map[string]*evalv2.EvalReport)
+	for _, e := range entries {
+		caseID, ok := strings.CutSuffix(e.Name(), extReportV2)
+		if !ok {
+			continue
+		}
+		report, err := s.readEvalReport(filepath.Join(runsDirName, id, e.Name()))
+		if err != nil {
+			continue
+		}
+		reports[caseID] = report
+	}
+	return reports, nil
+}
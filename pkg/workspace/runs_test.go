@@ -0,0 +1,94 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestCompareRuns(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.flac", "b.flac", "c.flac"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+
+	// Q is recomputed from S and P on load; equal S and P give Q = 100 S.
+	report := func(q map[string]float64) *evalv2.EvalReport {
+		r := &evalv2.EvalReport{Results: map[string]evalv2.EvalResult{}}
+		r.ContextSnapshot.Meta.TotalTokenCountEstimate = 10
+		for p, v := range q {
+			var res evalv2.EvalResult
+			res.Metrics.SScore, res.Metrics.PScore = v, v
+			r.Results[p] = res
+		}
+		return r
+	}
+	// A Tier-1 checkpoint y passed on case a in r1 and fails in r2.
+	checkpoint := func(r *evalv2.EvalReport, status evalv2.CheckpointStatus) *evalv2.EvalReport {
+		r.ContextSnapshot.Checkpoints = []evalv2.Checkpoint{{ID: "C1", Tier: 1, TextSegment: "hi"}}
+		y := r.Results["y"]
+		y.CheckpointResults = map[string]evalv2.CheckpointResult{"C1": {Status: status}}
+		r.Results["y"] = y
+		return r
+	}
+	for id, reports := range map[string]map[string]*evalv2.EvalReport{
+		"r1": {"a": checkpoint(report(map[string]float64{"x": .6, "y": .7}), evalv2.StatusPass), "b": report(map[string]float64{"x": .8, "y": .7})},
+		"r2": {"a": checkpoint(report(map[string]float64{"x": .9, "y": .7}), evalv2.StatusFail), "c": report(map[string]float64{"x": .1})},
+	} {
+		if err := os.MkdirAll(s.runDir(id), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.writeRun(&Run{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+		for caseID, r := range reports {
+			if err := s.writeRunReport(id, caseID, r, []string{"x", "y"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	resp, err := s.CompareRuns(ctx, CompareRunsRequest{A: "r1", B: "r2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Cases != 1 || len(resp.Providers) != 2 {
+		t.Fatalf("got %d cases, %d providers; want 1 case, 2 providers", resp.Cases, len(resp.Providers))
+	}
+	if x := resp.Providers[0]; x.Provider != "x" || x.DeltaQ != 30 {
+		t.Errorf("x = %+v, want delta 30 on case a only", x)
+	}
+	wantMoved := []CaseScoreDelta{{CaseID: "a", Provider: "x", A: 60, B: 90, Delta: 30}}
+	if diff := cmp.Diff(wantMoved, resp.Moved); diff != "" {
+		t.Errorf("moved mismatch (-want +got):\n%s", diff)
+	}
+	wantFailures := []CheckpointRegression{{CaseID: "a", Provider: "y", CheckpointID: "C1", Text: "hi", Was: evalv2.StatusPass}}
+	if diff := cmp.Diff(wantFailures, resp.NewFailures); diff != "" {
+		t.Errorf("new failures mismatch (-want +got):\n%s", diff)
+	}
+	if resp, err := s.CompareRuns(ctx, CompareRunsRequest{A: "r1", B: "r2", Threshold: 30}); err != nil || len(resp.Moved) != 0 {
+		t.Errorf("with threshold 30, moved = %v, %v; want none", resp, err)
+	}
+
+	cases, err := s.scanCasesFor(ctx, CaseFilter{Run: "r2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		if got := c.ReportV2 != nil; got != (c.ID != "b") {
+			t.Errorf("case %s has report = %v in run r2", c.ID, got)
+		}
+	}
+	if _, err := s.scanCasesFor(ctx, CaseFilter{Run: "missing"}); err == nil {
+		t.Error("listing a missing run succeeded, want error")
+	}
+}
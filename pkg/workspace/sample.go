@@ -0,0 +1,204 @@
+package workspace
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"asr-eval/pkg/audio"
+)
+
+// Sample dimensions accepted by SampleRequest.By.
+const (
+	SampleByDuration     = "duration"
+	SampleByTags         = "tags"
+	SampleByTokens       = "tokens"
+	SampleByQuestionable = "questionable"
+)
+
+// SampleDimensions are all sample dimensions, the default.
+var SampleDimensions = []string{SampleByDuration, SampleByTags, SampleByTokens, SampleByQuestionable}
+
+// SampleRequest selects a stratified sample of the cases matching the
+// filter.
+type SampleRequest struct {
+	CaseFilter
+	N    int      // Cases to pick
+	By   []string // Dimensions to stratify by; empty uses SampleDimensions
+	Seed uint64   // Same seed, same dataset, same sample
+}
+
+// SampleStratum is one combination of dimension values and how many of
+// its cases were picked.
+type SampleStratum struct {
+	Key    string `json:"key"` // Values of the dimensions, e.g. "10-30s/noisy/tokens:2/3/gt:ok"
+	Cases  int    `json:"cases"`
+	Picked int    `json:"picked"`
+}
+
+// SampleResponse is a stratified sample.
+type SampleResponse struct {
+	IDs    []string        `json:"ids"` // Sorted
+	Strata []SampleStratum `json:"strata"`
+}
+
+// Sample picks req.N cases so that every stratum is represented about in
+// proportion to its size, and every stratum at all when req.N allows:
+// each gets one case first, then the rest goes by size. With fewer picks
+// than strata, the largest strata get one each. Cases within a stratum
+// are picked at random.
+func (s *Service) Sample(ctx context.Context, req SampleRequest) (*SampleResponse, error) {
+	if req.N <= 0 {
+		return nil, fmt.Errorf("sample size must be positive")
+	}
+	by := req.By
+	if len(by) == 0 {
+		by = SampleDimensions
+	}
+	for _, d := range by {
+		if !slices.Contains(SampleDimensions, d) {
+			return nil, fmt.Errorf("unknown sample dimension %q; have %s", d, strings.Join(SampleDimensions, ", "))
+		}
+	}
+	cases, err := s.scanCasesFor(ctx, req.CaseFilter)
+	if err != nil {
+		return nil, err
+	}
+	enabled := s.enabledProviders()
+	cases = slices.DeleteFunc(cases, func(c *Case) bool { return !req.Match(c, enabled) })
+
+	tokenCuts := tertiles(cases)
+	audioInfo := s.audioLookup(ctx)
+	strata := make(map[string][]string)
+	for _, c := range cases {
+		var key []string
+		for _, d := range by {
+			key = append(key, sampleValue(c, d, tokenCuts, audioInfo))
+		}
+		k := strings.Join(key, "/")
+		strata[k] = append(strata[k], c.ID)
+	}
+
+	resp := &SampleResponse{}
+	for k, ids := range strata {
+		resp.Strata = append(resp.Strata, SampleStratum{Key: k, Cases: len(ids)})
+	}
+	// Largest first, so they win the picks when there are fewer than strata.
+	slices.SortFunc(resp.Strata, func(a, b SampleStratum) int {
+		return cmp.Or(b.Cases-a.Cases, strings.Compare(a.Key, b.Key))
+	})
+	allocate(resp.Strata, min(req.N, len(cases)))
+
+	r := rand.New(rand.NewPCG(req.Seed, req.Seed))
+	for _, st := range resp.Strata {
+		ids := strata[st.Key]
+		slices.Sort(ids)
+		r.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+		resp.IDs = append(resp.IDs, ids[:st.Picked]...)
+	}
+	slices.Sort(resp.IDs)
+	return resp, nil
+}
+
+// allocate sets Picked of strata, sorted largest first, to n picks in all.
+func allocate(strata []SampleStratum, n int) {
+	for i := range strata {
+		if n == 0 {
+			return
+		}
+		strata[i].Picked = 1
+		n--
+	}
+	// The rest is shared by the cases left, by largest remainder.
+	left := 0
+	for _, st := range strata {
+		left += st.Cases - st.Picked
+	}
+	if n == 0 || left == 0 {
+		return
+	}
+	type share struct {
+		i    int
+		frac float64
+	}
+	var shares []share
+	given := 0
+	for i, st := range strata {
+		exact := float64(n) * float64(st.Cases-st.Picked) / float64(left)
+		whole := int(exact)
+		strata[i].Picked += whole
+		given += whole
+		shares = append(shares, share{i, exact - float64(whole)})
+	}
+	slices.SortStableFunc(shares, func(a, b share) int { return cmp.Compare(b.frac, a.frac) })
+	for _, sh := range shares {
+		if given == n {
+			break
+		}
+		if st := &strata[sh.i]; st.Picked < st.Cases {
+			st.Picked++
+			given++
+		}
+	}
+}
+
+// sampleValue returns c's value of dimension d.
+func sampleValue(c *Case, d string, tokenCuts [2]int, audioInfo func(string) *audio.Info) string {
+	switch d {
+	case SampleByDuration:
+		info := audioInfo(c.ID)
+		if info == nil || info.DurationMS == 0 {
+			return "duration:unknown"
+		}
+		switch sec := info.DurationMS / 1000; {
+		case sec < 10:
+			return "<10s"
+		case sec < 30:
+			return "10-30s"
+		case sec < 120:
+			return "30-120s"
+		}
+		return ">=120s"
+	case SampleByTags:
+		if len(c.Tags) == 0 {
+			return "untagged"
+		}
+		return strings.Join(slices.Sorted(slices.Values(c.Tags)), "+")
+	case SampleByTokens:
+		n := caseTokenCount(c)
+		switch {
+		case n == 0:
+			return "tokens:unknown"
+		case n <= tokenCuts[0]:
+			return "tokens:1/3"
+		case n <= tokenCuts[1]:
+			return "tokens:2/3"
+		}
+		return "tokens:3/3"
+	case SampleByQuestionable:
+		if c.EvalContext != nil && c.EvalContext.Meta.QuestionableGT {
+			return "gt:questionable"
+		}
+		return "gt:ok"
+	}
+	return ""
+}
+
+// tertiles returns the token counts splitting the cases that have one
+// into thirds.
+func tertiles(cases []*Case) [2]int {
+	var counts []int
+	for _, c := range cases {
+		if n := caseTokenCount(c); n > 0 {
+			counts = append(counts, n)
+		}
+	}
+	if len(counts) == 0 {
+		return [2]int{}
+	}
+	slices.Sort(counts)
+	return [2]int{counts[(len(counts)-1)/3], counts[2*(len(counts)-1)/3]}
+}
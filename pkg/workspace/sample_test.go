@@ -0,0 +1,61 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSample(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+	// 12 noisy cases, 6 clean ones and one dialect case.
+	for i := range 19 {
+		id := fmt.Sprintf("c%02d", i)
+		if err := os.WriteFile(filepath.Join(dir, id+extFlac), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		tag := "noisy"
+		if i >= 12 {
+			tag = "clean"
+		}
+		if i == 18 {
+			tag = "dialect"
+		}
+		if err := s.Storage.PutTags(ctx, id, []string{tag}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := SampleRequest{N: 7, By: []string{SampleByTags}, Seed: 1}
+	resp, err := s.Sample(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SampleStratum{{Key: "noisy", Cases: 12, Picked: 4}, {Key: "clean", Cases: 6, Picked: 2}, {Key: "dialect", Cases: 1, Picked: 1}}
+	if diff := cmp.Diff(want, resp.Strata); diff != "" {
+		t.Errorf("strata mismatch (-want +got):\n%s", diff)
+	}
+	if len(resp.IDs) != 7 {
+		t.Errorf("picked %d cases, want 7", len(resp.IDs))
+	}
+	again, err := s.Sample(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(resp.IDs, again.IDs); diff != "" {
+		t.Errorf("same seed picked other cases (-first +second):\n%s", diff)
+	}
+
+	if resp, err := s.Sample(ctx, SampleRequest{N: 2, By: []string{SampleByTags}}); err != nil || len(resp.IDs) != 2 || resp.Strata[2].Picked != 0 {
+		t.Errorf("sample of 2 over 3 strata = %+v, %v; want the two largest strata", resp, err)
+	}
+	if _, err := s.Sample(ctx, SampleRequest{N: 1, By: []string{"speaker"}}); err == nil {
+		t.Error("sampling by an unknown dimension succeeded, want error")
+	}
+}
@@ -0,0 +1,87 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// schemaFilePattern matches the context and report files of a dataset,
+// current or archived: "[id].gt.v2.json", "[id].report.v2.3.json".
+var schemaFilePattern = regexp.MustCompile(`\.(gt|report)\.v2(\.[0-9]+)?\.json$`)
+
+// MigrateSchema rewrites the context and report files of the dataset that
+// predate evalv2.SchemaVersion: current ones, their history, and those of
+// runs, moving their inline contexts to the context store. Reading upgrades
+// them anyway; this saves redoing it on every read. With dryRun set it only
+// counts them. Records of a storage other than the dataset directory are
+// upgraded as they are read and written.
+func (s *Service) MigrateSchema(ctx context.Context, dryRun bool) (*MigrateResponse, error) {
+	return s.migrateFiles(ctx, fileMigration{
+		name:  "schema",
+		match: schemaFilePattern.MatchString,
+		apply: s.migrateSchemaFile,
+	}, dryRun)
+}
+
+// schemaFileDirs returns the directories holding context and report files:
+// the dataset directory and those of runs.
+func (s *Service) schemaFileDirs() ([]string, error) {
+	dirs := []string{s.Config.DatasetDir}
+	runs, err := os.ReadDir(filepath.Join(s.Config.DatasetDir, runsDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range runs {
+		if e.IsDir() {
+			dirs = append(dirs, filepath.Join(s.Config.DatasetDir, runsDirName, e.Name()))
+		}
+	}
+	return dirs, nil
+}
+
+// migrateSchemaFile upgrades the context or report file name in dir,
+// holding the case lock for the case's current files.
+func (s *Service) migrateSchemaFile(ctx context.Context, dir, name string, dryRun bool) (bool, error) {
+	isReport := !strings.Contains(name, ".gt.v2")
+	migrate := evalv2.MigrateReport
+	if !isReport {
+		migrate = evalv2.MigrateContext
+	}
+	if dir == s.Config.DatasetDir && !dryRun {
+		for _, ext := range []string{extGTV2, extReportV2} {
+			if id, ok := strings.CutSuffix(name, ext); ok {
+				unlock, err := s.lockCase(ctx, id)
+				if err != nil {
+					return false, err
+				}
+				defer unlock()
+			}
+		}
+	}
+
+	path := filepath.Join(dir, name)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	compressed := bytes.HasPrefix(raw, gzipMagic)
+	content, err := decodeReportFile(raw)
+	if err != nil {
+		return false, err
+	}
+	out, changed, err := migrate(content)
+	if err != nil {
+		return false, err
+	}
+	out, moved, err := storeFileContexts(s.Config.DatasetDir, out, isReport, dryRun)
+	if err != nil || !(changed || moved) || dryRun {
+		return changed || moved, err
+	}
+	return true, writeReportFile(path, out, compressed)
+}
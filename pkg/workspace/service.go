@@ -5,15 +5,18 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
+	"strconv"
+	"sync"
+	"sync/atomic"
 
+	"asr-eval/pkg/audio"
 	"asr-eval/pkg/evalv2"
-
-	"google.golang.org/genai"
+	"asr-eval/pkg/llmclient"
 )
 
 const (
@@ -27,7 +30,27 @@ type ServiceConfig struct {
 	DatasetDir       string
 	GenModel         string
 	EvalModel        string
+	FallbackModels   []string  // Tried in order when the primary model keeps failing
+	JudgeModels      []string  // When 2+ are set, evaluation runs as a multi-judge consensus
+	MaxPromptTokens  int       // Evaluation prompts above this are truncated; 0 disables the check
+	JobWorkers       int       // Concurrent background jobs
+	EvalSlots        EvalSlots // Shared cap on concurrent LLM runs; nil is unlimited
 	EnabledProviders map[string]bool
+	SScoreWeight     float64                    // S weight in the Q score; 0 uses evalv2.SScoreWeight
+	Pricing          map[string]ProviderPricing // By provider
+	ModelPricing     map[string]ModelPricing    // By LLM
+	Tags             []string                   // Allowed case tags; empty allows any
+	WebhookURL       string                     // Finished evaluations and batches are posted here; empty disables
+	WebhookFormat    string                     // WebhookSlack or WebhookFeishu
+	Storage          Storage                    // Case records; nil uses FSStorage over DatasetDir
+	CompressReports  bool                       // Gzip the report files FSStorage and runs write
+	CaseBundles      bool                       // With no Storage, use CaseBundleStorage over DatasetDir
+	Chunking         ChunkingConfig             // How Transcribe splits long audio; zero transcribes it whole
+	TrimSilence      *audio.TrimOptions         // Trim silence from audio before transcribing it; nil sends it as is
+	Channels         audio.ChannelOptions       // Which channel of multichannel audio providers hear; zero averages them
+	SampleRate       int                        // Of the dataset's audio; other rates are flagged. 0 expects the most common one
+	Command          string                     // Recorded in the usage ledger; defaults to the program name
+	Overrides        *DatasetConfig             // Settings given on the command line; they win over dataset.yaml
 }
 
 // DefaultServiceConfig returns the default configuration for the service.
@@ -36,6 +59,10 @@ func DefaultServiceConfig() ServiceConfig {
 		DatasetDir: "transcripts_and_audios",
 		GenModel:   "gemini-3-pro-preview",
 		EvalModel:  "gemini-3-flash-preview",
+		// Gemini 3 input context window
+		MaxPromptTokens: 1048576,
+		JobWorkers:      4,
+		SScoreWeight:    evalv2.SScoreWeight,
 		EnabledProviders: map[string]bool{
 			"volc":         false,
 			"volc_ctx":     false,
@@ -58,150 +85,199 @@ func DefaultServiceConfig() ServiceConfig {
 }
 
 type Service struct {
-	Config    ServiceConfig
-	GenClient *genai.Client
+	Config  ServiceConfig
+	LLM     llmclient.Client
+	Jobs    *JobManager
+	Storage Storage
+
+	providersMu sync.RWMutex // Guards Config.EnabledProviders
+	batchesMu   sync.Mutex
+	batches     map[string]*batchRun // Batches awaiting a webhook summary, by ID
+
+	changesMu sync.Mutex   // Guards changes
+	changes   *changeState // The change journal as last read; nil before
+
+	index     atomic.Pointer[caseIndex] // Nil when the dataset is not watched
+	events    eventHub
+	closeOnce sync.Once
+	done      chan struct{} // Closed by Close; nil for services not from NewService
 }
 
-func NewService(config ServiceConfig, client *genai.Client) *Service {
-	return &Service{
-		Config:    config,
-		GenClient: client,
+// NewService returns a service for config.DatasetDir. Settings in the
+// dataset's dataset.yaml take precedence over config, except for
+// config.Overrides.
+func NewService(config ServiceConfig, client llmclient.Client) *Service {
+	if dc, err := LoadDatasetConfig(config.DatasetDir); err != nil {
+		slog.Error("Failed to load dataset config", "dataset", config.DatasetDir, "error", err)
+	} else if dc != nil {
+		dc.Apply(&config)
+	}
+	if config.Overrides != nil {
+		config.Overrides.Apply(&config)
+	}
+	s := &Service{
+		Config:  config,
+		LLM:     client,
+		Jobs:    NewJobManager(filepath.Join(config.DatasetDir, jobsDirName), config.JobWorkers),
+		Storage: config.Storage,
+		done:    make(chan struct{}),
+	}
+	if s.Storage == nil && config.CaseBundles {
+		s.Storage = NewCaseBundleStorage(config.DatasetDir)
+	} else if s.Storage == nil {
+		st := NewFSStorage(config.DatasetDir)
+		st.CompressReports = config.CompressReports
+		s.Storage = st
+	}
+	s.Jobs.onUpdate = func(job *Job) {
+		s.publishJob(job)
+		s.notifyJob(job)
+	}
+	if _, ok := s.Storage.(*FSStorage); !ok {
+		// Only the directory layout can be watched.
+	} else if x, err := newCaseIndex(config.DatasetDir); err != nil {
+		slog.Warn("Dataset not watched, listing cases will read every file and changes are not pushed", "dataset", config.DatasetDir, "error", err)
+	} else {
+		x.onChange = s.events.publish
+		s.index.Store(x)
+		go s.watchEvents(x, s.done)
 	}
+	return s
 }
 
-// ListCases scans the directory and returns summary Case objects.
-func (s *Service) ListCases(ctx context.Context) ([]*Case, error) {
-	var results []*Case
-	dir := s.Config.DatasetDir
+// Close stops background jobs, event streams and watching the dataset, and
+// closes the storage.
+func (s *Service) Close() {
+	s.Jobs.Close()
+	s.closeOnce.Do(func() {
+		if s.done != nil {
+			close(s.done)
+		}
+	})
+	s.events.close()
+	if x := s.index.Swap(nil); x != nil {
+		x.close()
+	}
+	if s.Storage != nil {
+		s.Storage.Close()
+	}
+}
 
-	// Optimization: Read all directory entries once
-	entries, err := os.ReadDir(dir)
+// ListCases returns a filtered, sorted page of summary Case objects.
+func (s *Service) ListCases(ctx context.Context, req ListCasesRequest) (*ListCasesResponse, error) {
+	all, hydrated, err := s.listCasesFor(ctx, req.CaseFilter)
 	if err != nil {
 		return nil, err
 	}
 
-	filesMap := make(map[string]map[string]bool) // id -> extension -> true
-	for _, e := range entries {
-		if e.IsDir() {
-			continue
-		}
-		name := e.Name()
-		if strings.HasSuffix(name, extFlac) {
-			id := strings.TrimSuffix(name, extFlac)
-			if filesMap[id] == nil {
-				filesMap[id] = make(map[string]bool)
-			}
-			filesMap[id][extFlac] = true
-		} else if strings.HasSuffix(name, extReportV2) {
-			id := strings.TrimSuffix(name, extReportV2)
-			if filesMap[id] == nil {
-				filesMap[id] = make(map[string]bool)
-			}
-			filesMap[id][extReportV2] = true
-		} else if strings.HasSuffix(name, extGTV2) {
-			id := strings.TrimSuffix(name, extGTV2)
-			if filesMap[id] == nil {
-				filesMap[id] = make(map[string]bool)
-			}
-			filesMap[id][extGTV2] = true
+	cases := make([]*Case, 0, len(all))
+	enabled := s.enabledProviders()
+	for _, c := range all {
+		if req.Match(c, enabled) {
+			cases = append(cases, c)
 		}
 	}
+	if err := sortCases(cases, req.Sort, req.Provider, enabled); err != nil {
+		return nil, err
+	}
 
-	for id, exts := range filesMap {
-		if !exts[extFlac] {
-			// Skip if no audio file (sanity check)
-			continue
-		}
-
-		c := &Case{ID: id}
-
-		// Try to load GT first (Precedence)
-		if exts[extGTV2] {
-			ctx, err := s.loadEvalContext(id)
-			if err == nil {
-				c.EvalContext = ctx
+	resp := &ListCasesResponse{TotalSize: len(cases)}
+	if req.PageSize > 0 {
+		page := max(req.Page, 1)
+		start := min((page-1)*req.PageSize, len(cases))
+		end := min(start+req.PageSize, len(cases))
+		cases = cases[start:end]
+	}
+	if !hydrated {
+		page := make([]*Case, 0, len(cases))
+		for _, c := range cases {
+			if c := s.loadSummary(ctx, c.ID); c != nil {
+				page = append(page, c)
 			}
 		}
+		cases = page
+	}
+	resp.Cases = cases
+	return resp, nil
+}
 
-		// Load Report
-		if exts[extReportV2] {
-			report, err := s.loadEvalReport(id)
-			if err == nil {
-				c.ReportV2 = report
-				// If no GT loaded yet, use snapshot
-				if c.EvalContext == nil && report.ContextSnapshot.Hash != "" {
-					c.EvalContext = &report.ContextSnapshot
-				}
-			}
+// scanCases returns summary Case objects sorted by ID, from the index when
+// the dataset is watched.
+func (s *Service) scanCases(ctx context.Context) ([]*Case, error) {
+	if x := s.index.Load(); x != nil {
+		cases, err := x.list(ctx, s)
+		if !errors.Is(err, errWatchLost) {
+			return cases, err
+		}
+		slog.Warn("Dataset index disabled", "dataset", s.Config.DatasetDir, "error", err)
+		if s.index.CompareAndSwap(x, nil) {
+			x.close()
 		}
+	}
+	return s.readCases(ctx)
+}
 
-		results = append(results, c)
+// readCases returns summary Case objects sorted by ID from the storage.
+func (s *Service) readCases(ctx context.Context) ([]*Case, error) {
+	cases, err := s.Storage.ListCases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cases {
+		s.completeSummary(c)
 	}
+	return cases, nil
+}
 
-	// Sort by ID to ensure stable order
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].ID < results[j].ID
-	})
+// loadSummary returns the summary Case for id, or nil when there is no
+// such case.
+func (s *Service) loadSummary(ctx context.Context, id string) *Case {
+	c, err := s.Storage.GetSummary(ctx, id)
+	if err != nil {
+		return nil
+	}
+	s.completeSummary(c)
+	return c
+}
 
-	return results, nil
+// completeSummary adds what a summary Case needs beyond the storage's
+// records.
+func (s *Service) completeSummary(c *Case) {
+	if c.ReportV2 != nil {
+		s.fillQScores(c.ReportV2)
+	}
+	c.Review, _ = s.loadReview(c.ID)
 }
 
 // GetCase returns full details for a case
 func (s *Service) GetCase(ctx context.Context, id string) (*Case, error) {
-	c := &Case{
-		ID:          id,
-		Transcripts: make(map[string]string),
+	c, err := s.Storage.GetCase(ctx, id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("case not found: %s", id)
 	}
-
-	files, err := os.ReadDir(s.Config.DatasetDir)
 	if err != nil {
 		return nil, err
 	}
-
-	found := false
-	for _, f := range files {
-		name := f.Name()
-		if !strings.HasPrefix(name, id+".") {
-			continue
-		}
-
-		if name == id+extFlac {
-			found = true
-			continue
-		}
-
-		path := filepath.Join(s.Config.DatasetDir, name)
-
-		if strings.HasSuffix(name, extGTV2) {
-			ctx, err := s.loadEvalContext(id)
-			if err == nil {
-				c.EvalContext = ctx
-			}
-		} else if strings.HasSuffix(name, extReportV2) {
-			report, err := s.loadEvalReport(id)
-			if err == nil {
-				c.ReportV2 = report
-				// If no GT loaded yet, use snapshot
-				if c.EvalContext == nil && report.ContextSnapshot.Hash != "" {
-					c.EvalContext = &report.ContextSnapshot
-				}
-			}
-		} else {
-			// Transcripts
-			ext := filepath.Ext(name)
-			if ext != extJSON && ext != extFlac {
-				content, _ := os.ReadFile(path)
-				provider := strings.TrimPrefix(ext, ".")
-				c.Transcripts[provider] = string(content)
-			}
-		}
+	if c.ReportV2 != nil {
+		s.fillQScores(c.ReportV2)
+	}
+	c.Review, _ = s.loadReview(id)
+	c.Corrections, _ = s.loadCorrections(id)
+	c.Channels, _ = s.loadChannels(id)
+	c.Streams, err = s.listStreams(id)
+	if err != nil {
+		return nil, err
 	}
+	return c, nil
+}
 
-	if !found {
+// AudioInfo returns the header metadata of a case's audio file.
+func (s *Service) AudioInfo(ctx context.Context, id string) (*audio.Info, error) {
+	path := filepath.Join(s.Config.DatasetDir, id+extFlac)
+	if _, err := os.Stat(path); err != nil {
 		return nil, fmt.Errorf("case not found: %s", id)
 	}
-
-	return c, nil
+	return audio.ReadInfo(path)
 }
 
 // UpdateContext updates the eval context for a case.
@@ -210,25 +286,51 @@ func (s *Service) UpdateContext(ctx context.Context, req UpdateContextRequest) (
 		return nil, fmt.Errorf("EvalContext is required")
 	}
 
-	// Recalculate Hash
-	// Update Context file
-	if err := s.writeEvalContext(req.ID, req.EvalContext); err != nil {
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
 		return nil, err
 	}
+	defer unlock()
+	prev, _ := s.loadEvalContext(ctx, req.ID)
+
+	// Edited contexts get a new hash so reports against the old one read
+	// as stale.
+	req.EvalContext.Hash = contextHash(req.EvalContext)
+	if err := s.writeEvalContext(ctx, req.ID, req.EvalContext); err != nil {
+		return nil, err
+	}
+	details := map[string]string{"hash": req.EvalContext.Hash}
+	if prev != nil {
+		details["previous_hash"] = prev.Hash
+		if prev.Meta.GroundTruth != req.EvalContext.Meta.GroundTruth {
+			details["ground_truth_changed"] = "true"
+		}
+	}
+	s.audit(ctx, AuditUpdateContext, req.ID, details)
 
-	// Invalidate Report (Side effect)
-	reportPath := filepath.Join(s.Config.DatasetDir, req.ID+extReportV2)
-	_ = os.Remove(reportPath)
+	// Invalidate Report (Side effect); it stays in the report history.
+	n, err := s.Storage.ArchiveReport(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		s.audit(ctx, AuditResetReport, req.ID, map[string]string{"archived_version": strconv.Itoa(n)})
+	}
 
 	return s.GetCase(ctx, req.ID)
 }
 
 func (s *Service) GenerateContext(ctx context.Context, req GenerateContextRequest) (*evalv2.EvalContext, error) {
-	if s.GenClient == nil {
+	if s.LLM == nil {
 		return nil, fmt.Errorf("LLM client not initialized")
 	}
+	release, err := s.Config.EvalSlots.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-	evaluator := evalv2.NewEvaluator(s.GenClient, s.Config.GenModel, s.Config.EvalModel)
+	evaluator := s.newEvaluator()
 	audioPath := filepath.Join(s.Config.DatasetDir, req.ID+extFlac)
 
 	// Load transcripts from disk
@@ -238,28 +340,42 @@ func (s *Service) GenerateContext(ctx context.Context, req GenerateContextReques
 	}
 	transcripts := c.Transcripts
 
-	ctxResp, _, err := evaluator.GenerateContext(ctx, audioPath, req.GroundTruth, transcripts)
+	ctxResp, _, err := evaluator.GenerateContext(s.withUsage(ctx, req.ID, StepGenerateContext), audioPath, req.GroundTruth, transcripts)
 	if err != nil {
 		return nil, err
 	}
 
-	bytes, _ := json.Marshal(ctxResp)
-	hash := md5.Sum(bytes)
-	ctxResp.Hash = hex.EncodeToString(hash[:])
-
+	ctxResp.Hash = contextHash(ctxResp)
 	return ctxResp, nil
 }
 
+// contextHash returns the MD5 of ctx's JSON encoding without its hash,
+// schema version and alignment, so neither upgrading nor aligning a context
+// makes its reports stale.
+func contextHash(ctx *evalv2.EvalContext) string {
+	c := *ctx.Unaligned()
+	c.Hash = ""
+	c.SchemaVersion = 0
+	bytes, _ := json.Marshal(&c)
+	hash := md5.Sum(bytes)
+	return hex.EncodeToString(hash[:])
+}
+
 func (s *Service) Evaluate(ctx context.Context, req EvaluateRequest) (*evalv2.EvalReport, error) {
-	if s.GenClient == nil {
+	if s.LLM == nil {
 		return nil, fmt.Errorf("LLM client not initialized")
 	}
+	release, err := s.Config.EvalSlots.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	if req.EvalContext == nil {
 		return nil, fmt.Errorf("EvalContext is required")
 	}
 
-	evaluator := evalv2.NewEvaluator(s.GenClient, s.Config.GenModel, s.Config.EvalModel)
+	evaluator := s.newEvaluator()
 
 	// Load Transcripts
 	c, err := s.GetCase(ctx, req.ID)
@@ -278,17 +394,39 @@ func (s *Service) Evaluate(ctx context.Context, req EvaluateRequest) (*evalv2.Ev
 	} else {
 		transcripts = c.Transcripts
 	}
+	if req.UseCorrections {
+		transcripts = applyCorrections(transcripts, c)
+	}
 
-	resp, _, err := evaluator.Evaluate(ctx, req.EvalContext, transcripts)
+	judges := req.JudgeModels
+	if len(judges) == 0 {
+		judges = s.Config.JudgeModels
+	}
+	var resp *evalv2.EvalReport
+	ctx = s.withUsage(ctx, req.ID, StepEvaluate)
+	if len(judges) > 1 {
+		resp, _, err = evaluator.EvaluateConsensus(ctx, req.EvalContext, transcripts, judges)
+	} else {
+		resp, _, err = evaluator.Evaluate(ctx, req.EvalContext, transcripts)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	contextHash := req.EvalContext.Hash
 	resp.ContextSnapshot = *req.EvalContext
+	if req.UseCorrections {
+		// What-if scoring: the saved report stays with the raw output.
+		return resp, nil
+	}
 
 	// Save Report (Merge with existing)
-	existingReport, err := s.loadEvalReport(req.ID)
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	existingReport, err := s.loadEvalReport(ctx, req.ID)
 	var finalReport *evalv2.EvalReport
 
 	if err == nil && existingReport.ContextSnapshot.Hash == contextHash {
@@ -300,62 +438,90 @@ func (s *Service) Evaluate(ctx context.Context, req EvaluateRequest) (*evalv2.Ev
 		finalReport = resp
 	}
 
-	if err := s.writeEvalReport(req.ID, finalReport); err != nil {
+	if err := s.writeEvalReport(ctx, req.ID, finalReport); err != nil {
 		return nil, err
 	}
 
 	return finalReport, nil
 }
 
-func (s *Service) loadEvalReport(id string) (*evalv2.EvalReport, error) {
-	filename := filepath.Join(s.Config.DatasetDir, id+extReportV2)
-	content, err := os.ReadFile(filename)
+func (s *Service) sScoreWeight() float64 {
+	if s.Config.SScoreWeight == 0 {
+		return evalv2.SScoreWeight
+	}
+	return s.Config.SScoreWeight
+}
+
+func (s *Service) newEvaluator() *evalv2.Evaluator {
+	e := evalv2.NewEvaluator(s.LLM, s.Config.GenModel, s.Config.EvalModel)
+	e.SetFallbackModels(s.Config.FallbackModels)
+	e.SetMaxPromptTokens(s.Config.MaxPromptTokens)
+	return e
+}
+
+func (s *Service) loadEvalReport(ctx context.Context, id string) (*evalv2.EvalReport, error) {
+	report, err := s.Storage.GetReport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.fillQScores(report)
+	return report, nil
+}
+
+// readEvalReport loads a report file of the dataset, filling in QScores.
+func (s *Service) readEvalReport(name string) (*evalv2.EvalReport, error) {
+	content, err := readReportFile(filepath.Join(s.Config.DatasetDir, name))
 	if err != nil {
 		return nil, err
 	}
-	var report evalv2.EvalReport
-	if err := json.Unmarshal(content, &report); err != nil {
+	report, err := evalv2.UnmarshalReport(content)
+	if err != nil {
 		return nil, err
 	}
-	// Calculate QScores
+	resolveReportContext(s.Config.DatasetDir, report)
+	s.fillQScores(report)
+	return report, nil
+}
+
+// fillQScores calculates the QScores of report, which are not stored.
+func (s *Service) fillQScores(report *evalv2.EvalReport) {
 	for k, v := range report.Results {
 		// TODO: remove this once we switch to MER
 		if v.Metrics.PScore > 1 {
 			v.Metrics.PScore = 0
 		}
-		v.Metrics.QScore = v.Metrics.CompositeScore()
+		v.Metrics.QScore = v.Metrics.CompositeScoreWith(s.sScoreWeight())
 		report.Results[k] = v
 	}
-	return &report, nil
 }
 
-func (s *Service) loadEvalContext(id string) (*evalv2.EvalContext, error) {
-	filename := filepath.Join(s.Config.DatasetDir, id+extGTV2)
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
-	}
-	var ctx evalv2.EvalContext
-	if err := json.Unmarshal(content, &ctx); err != nil {
-		return nil, err
-	}
-	return &ctx, nil
+func (s *Service) loadEvalContext(ctx context.Context, id string) (*evalv2.EvalContext, error) {
+	return s.Storage.GetContext(ctx, id)
 }
 
-func (s *Service) writeEvalReport(id string, report *evalv2.EvalReport) error {
-	filename := filepath.Join(s.Config.DatasetDir, id+extReportV2)
-	bytes, err := json.MarshalIndent(report, "", "  ")
-	if err != nil {
+// writeEvalReport saves report as the case's report, archiving the
+// previous one.
+func (s *Service) writeEvalReport(ctx context.Context, id string, report *evalv2.EvalReport) error {
+	if _, err := s.Storage.ArchiveReport(ctx, id); err != nil {
 		return err
 	}
-	return os.WriteFile(filename, bytes, 0644)
+	stampReport(report)
+	return s.Storage.PutReport(ctx, id, report)
 }
 
-func (s *Service) writeEvalContext(id string, ctx *evalv2.EvalContext) error {
-	filename := filepath.Join(s.Config.DatasetDir, id+extGTV2)
-	bytes, err := json.MarshalIndent(ctx, "", "  ")
-	if err != nil {
+// writeEvalContext saves ec as the case's context, archiving the previous
+// one.
+func (s *Service) writeEvalContext(ctx context.Context, id string, ec *evalv2.EvalContext) error {
+	if _, err := s.Storage.ArchiveContext(ctx, id); err != nil {
 		return err
 	}
-	return os.WriteFile(filename, bytes, 0644)
+	ec.SchemaVersion = evalv2.SchemaVersion
+	return s.Storage.PutContext(ctx, id, ec)
+}
+
+// stampReport marks report, and its context snapshot, as written with the
+// current schema.
+func stampReport(report *evalv2.EvalReport) {
+	report.SchemaVersion = evalv2.SchemaVersion
+	report.ContextSnapshot.SchemaVersion = evalv2.SchemaVersion
 }
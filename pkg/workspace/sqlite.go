@@ -0,0 +1,505 @@
+package workspace
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// sqliteDriver is the database/sql driver name SQLiteStorage opens. It is
+// linked in by building with -tags sqlite.
+const sqliteDriver = "sqlite"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS cases (
+	id TEXT PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS transcripts (
+	case_id  TEXT NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+	provider TEXT NOT NULL,
+	text     TEXT NOT NULL,
+	PRIMARY KEY (case_id, provider)
+);
+CREATE TABLE IF NOT EXISTS contexts (
+	case_id     TEXT PRIMARY KEY REFERENCES cases(id) ON DELETE CASCADE,
+	hash        TEXT NOT NULL,
+	body        TEXT NOT NULL, -- evalv2.EvalContext JSON
+	update_time TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS reports (
+	case_id      TEXT PRIMARY KEY REFERENCES cases(id) ON DELETE CASCADE,
+	context_hash TEXT NOT NULL,
+	body         TEXT NOT NULL, -- evalv2.EvalReport JSON
+	update_time  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS history (
+	case_id     TEXT NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+	record      TEXT NOT NULL, -- contexts or reports, the table it was archived from
+	version     INTEGER NOT NULL,
+	body        TEXT NOT NULL,
+	update_time TEXT NOT NULL,
+	PRIMARY KEY (case_id, record, version)
+);
+CREATE TABLE IF NOT EXISTS tags (
+	case_id TEXT NOT NULL REFERENCES cases(id) ON DELETE CASCADE,
+	tag     TEXT NOT NULL,
+	PRIMARY KEY (case_id, tag)
+);
+CREATE TABLE IF NOT EXISTS audit (
+	seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+	time    TEXT NOT NULL,
+	user    TEXT NOT NULL,
+	action  TEXT NOT NULL,
+	case_id TEXT NOT NULL,
+	details TEXT -- JSON object
+);
+CREATE INDEX IF NOT EXISTS audit_case ON audit(case_id);
+`
+
+// SQLiteStorage is a Storage in a SQLite database. Writes are transactional,
+// so concurrent writers, in this process or others, cannot interleave
+// within a record, and listing cases is a single query.
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStorage opens or creates the database at path.
+func OpenSQLiteStorage(path string) (*SQLiteStorage, error) {
+	if !slices.Contains(sql.Drivers(), sqliteDriver) {
+		return nil, errors.New("SQLite support is not built in; rebuild with -tags sqlite")
+	}
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open(sqliteDriver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &SQLiteStorage{db: db}, nil
+}
+
+func (st *SQLiteStorage) Close() error {
+	return st.db.Close()
+}
+
+func (st *SQLiteStorage) ListIDs(ctx context.Context) ([]string, error) {
+	rows, err := st.db.QueryContext(ctx, `SELECT id FROM cases ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (st *SQLiteStorage) ListCases(ctx context.Context) ([]*Case, error) {
+	ids, err := st.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Case, len(ids))
+	cases := make([]*Case, len(ids))
+	for i, id := range ids {
+		cases[i] = &Case{ID: id}
+		byID[id] = cases[i]
+	}
+
+	err = st.scan(ctx, `SELECT case_id, body FROM contexts`, func(id, body string) error {
+		c := byID[id]
+		if c == nil {
+			return nil
+		}
+		ec, err := evalv2.UnmarshalContext([]byte(body))
+		c.EvalContext = ec
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = st.scan(ctx, `SELECT case_id, body FROM reports`, func(id, body string) error {
+		c := byID[id]
+		if c == nil {
+			return nil
+		}
+		r, err := evalv2.UnmarshalReport([]byte(body))
+		c.ReportV2 = r
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = st.scan(ctx, `SELECT case_id, tag FROM tags ORDER BY case_id, tag`, func(id, tag string) error {
+		if c := byID[id]; c != nil {
+			c.Tags = append(c.Tags, tag)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cases {
+		useSnapshot(c)
+	}
+	return cases, nil
+}
+
+func (st *SQLiteStorage) GetSummary(ctx context.Context, id string) (*Case, error) {
+	var exists bool
+	if err := st.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM cases WHERE id = ?)`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("case not found: %s: %w", id, os.ErrNotExist)
+	}
+	c := &Case{ID: id}
+	var err error
+	if c.EvalContext, err = st.GetContext(ctx, id); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if c.ReportV2, err = st.GetReport(ctx, id); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if c.Tags, err = st.GetTags(ctx, id); err != nil {
+		return nil, err
+	}
+	useSnapshot(c)
+	return c, nil
+}
+
+func (st *SQLiteStorage) GetCase(ctx context.Context, id string) (*Case, error) {
+	c, err := st.GetSummary(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.Transcripts = make(map[string]string)
+	err = st.scan(ctx, `SELECT provider, text FROM transcripts WHERE case_id = ?`, func(p, t string) error {
+		c.Transcripts[p] = t
+		return nil
+	}, id)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (st *SQLiteStorage) PutCase(ctx context.Context, id string) error {
+	_, err := st.db.ExecContext(ctx, `INSERT INTO cases (id) VALUES (?) ON CONFLICT DO NOTHING`, id)
+	return err
+}
+
+func (st *SQLiteStorage) PutTranscript(ctx context.Context, id, provider, text string) error {
+	_, err := st.db.ExecContext(ctx, `
+		INSERT INTO transcripts (case_id, provider, text) VALUES (?, ?, ?)
+		ON CONFLICT (case_id, provider) DO UPDATE SET text = excluded.text`,
+		id, provider, text)
+	return err
+}
+
+func (st *SQLiteStorage) PutContext(ctx context.Context, id string, c *evalv2.EvalContext) error {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = st.db.ExecContext(ctx, `
+		INSERT INTO contexts (case_id, hash, body, update_time) VALUES (?, ?, ?, ?)
+		ON CONFLICT (case_id) DO UPDATE SET hash = excluded.hash, body = excluded.body, update_time = excluded.update_time`,
+		id, c.Hash, string(body), sqliteNow())
+	return err
+}
+
+func (st *SQLiteStorage) PutReport(ctx context.Context, id string, r *evalv2.EvalReport) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = st.db.ExecContext(ctx, `
+		INSERT INTO reports (case_id, context_hash, body, update_time) VALUES (?, ?, ?, ?)
+		ON CONFLICT (case_id) DO UPDATE SET context_hash = excluded.context_hash, body = excluded.body, update_time = excluded.update_time`,
+		id, r.ContextSnapshot.Hash, string(body), sqliteNow())
+	return err
+}
+
+func (st *SQLiteStorage) DeleteCase(ctx context.Context, id string) error {
+	_, err := st.db.ExecContext(ctx, `DELETE FROM cases WHERE id = ?`, id)
+	return err
+}
+
+func (st *SQLiteStorage) GetContext(ctx context.Context, id string) (*evalv2.EvalContext, error) {
+	body, err := st.getBody(ctx, `SELECT body FROM contexts WHERE case_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	return evalv2.UnmarshalContext(body)
+}
+
+func (st *SQLiteStorage) GetReport(ctx context.Context, id string) (*evalv2.EvalReport, error) {
+	body, err := st.getBody(ctx, `SELECT body FROM reports WHERE case_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	return evalv2.UnmarshalReport(body)
+}
+
+func (st *SQLiteStorage) DeleteReport(ctx context.Context, id string) error {
+	_, err := st.db.ExecContext(ctx, `DELETE FROM reports WHERE case_id = ?`, id)
+	return err
+}
+
+func (st *SQLiteStorage) ArchiveContext(ctx context.Context, id string) (int, error) {
+	return st.archive(ctx, "contexts", id)
+}
+
+func (st *SQLiteStorage) ListContextVersions(ctx context.Context, id string) ([]RecordVersion, error) {
+	return st.versions(ctx, "contexts", id)
+}
+
+func (st *SQLiteStorage) GetContextVersion(ctx context.Context, id string, n int) (*evalv2.EvalContext, error) {
+	body, err := st.getBody(ctx, `SELECT body FROM history WHERE case_id = ? AND record = 'contexts' AND version = ?`, id, n)
+	if err != nil {
+		return nil, err
+	}
+	return evalv2.UnmarshalContext(body)
+}
+
+func (st *SQLiteStorage) ArchiveReport(ctx context.Context, id string) (int, error) {
+	return st.archive(ctx, "reports", id)
+}
+
+func (st *SQLiteStorage) ListReportVersions(ctx context.Context, id string) ([]RecordVersion, error) {
+	return st.versions(ctx, "reports", id)
+}
+
+func (st *SQLiteStorage) GetReportVersion(ctx context.Context, id string, n int) (*evalv2.EvalReport, error) {
+	body, err := st.getBody(ctx, `SELECT body FROM history WHERE case_id = ? AND record = 'reports' AND version = ?`, id, n)
+	if err != nil {
+		return nil, err
+	}
+	return evalv2.UnmarshalReport(body)
+}
+
+// archive moves the case's row of table, contexts or reports, into the
+// history as its next version, which it returns, and prunes the oldest
+// versions beyond maxHistory.
+func (st *SQLiteStorage) archive(ctx context.Context, table, id string) (int, error) {
+	tx, err := st.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var body, updated string
+	err = tx.QueryRowContext(ctx, `SELECT body, update_time FROM `+table+` WHERE case_id = ?`, id).Scan(&body, &updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(version), 0) + 1 FROM history WHERE case_id = ? AND record = ?`,
+		id, table).Scan(&n); err != nil {
+		return 0, err
+	}
+	for _, q := range []struct {
+		query string
+		args  []any
+	}{
+		{`INSERT INTO history (case_id, record, version, body, update_time) VALUES (?, ?, ?, ?, ?)`, []any{id, table, n, body, updated}},
+		{`DELETE FROM history WHERE case_id = ? AND record = ? AND version <= ?`, []any{id, table, n - maxHistory}},
+		{`DELETE FROM ` + table + ` WHERE case_id = ?`, []any{id}},
+	} {
+		if _, err := tx.ExecContext(ctx, q.query, q.args...); err != nil {
+			return 0, err
+		}
+	}
+	return n, tx.Commit()
+}
+
+// versions returns the archived versions of the case's row of table,
+// oldest first, followed by the current one.
+func (st *SQLiteStorage) versions(ctx context.Context, table, id string) ([]RecordVersion, error) {
+	var out []RecordVersion
+	err := st.scan(ctx, `
+		SELECT version, update_time FROM (
+			SELECT version, update_time FROM history WHERE case_id = ? AND record = ?
+			UNION ALL
+			SELECT 0, update_time FROM `+table+` WHERE case_id = ?
+		) ORDER BY version = 0, version`,
+		func(version, updated string) error {
+			n, err := strconv.Atoi(version)
+			if err != nil {
+				return err
+			}
+			t, err := time.Parse(time.RFC3339Nano, updated)
+			if err != nil {
+				return err
+			}
+			out = append(out, RecordVersion{N: n, UpdateTime: t})
+			return nil
+		}, id, table, id)
+	return out, err
+}
+
+func (st *SQLiteStorage) GetTags(ctx context.Context, id string) ([]string, error) {
+	rows, err := st.db.QueryContext(ctx, `SELECT tag FROM tags WHERE case_id = ? ORDER BY tag`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tags []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (st *SQLiteStorage) PutTags(ctx context.Context, id string, tags []string) error {
+	tx, err := st.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE case_id = ?`, id); err != nil {
+		return err
+	}
+	for _, t := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tags (case_id, tag) VALUES (?, ?)`, id, t); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (st *SQLiteStorage) AppendAudit(ctx context.Context, e *AuditEntry) error {
+	var details []byte
+	if len(e.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(e.Details); err != nil {
+			return err
+		}
+	}
+	_, err := st.db.ExecContext(ctx, `
+		INSERT INTO audit (time, user, action, case_id, details) VALUES (?, ?, ?, ?, ?)`,
+		e.Time.UTC().Format(time.RFC3339Nano), e.User, e.Action, e.CaseID, nullString(details))
+	return err
+}
+
+func (st *SQLiteStorage) ListAudit(ctx context.Context, req ListAuditRequest) ([]*AuditEntry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	for col, v := range map[string]string{"case_id": req.CaseID, "user": req.User, "action": req.Action} {
+		if v != "" {
+			where = append(where, col+" = ?")
+			args = append(args, v)
+		}
+	}
+	q := `SELECT time, user, action, case_id, details FROM audit`
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	q += ` ORDER BY seq DESC`
+	if req.Limit > 0 {
+		q += fmt.Sprintf(` LIMIT %d`, req.Limit)
+	}
+
+	rows, err := st.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []*AuditEntry{}
+	for rows.Next() {
+		var (
+			e       AuditEntry
+			t       string
+			details sql.NullString
+		)
+		if err := rows.Scan(&t, &e.User, &e.Action, &e.CaseID, &details); err != nil {
+			return nil, err
+		}
+		if e.Time, err = time.Parse(time.RFC3339Nano, t); err != nil {
+			return nil, err
+		}
+		if details.Valid {
+			if err := json.Unmarshal([]byte(details.String), &e.Details); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, &e)
+	}
+	return out, rows.Err()
+}
+
+// getBody returns the single JSON column selected by query of the case's
+// rows. No row is an error matching os.ErrNotExist.
+func (st *SQLiteStorage) getBody(ctx context.Context, query, id string, args ...any) ([]byte, error) {
+	var body string
+	err := st.db.QueryRowContext(ctx, query, append([]any{id}, args...)...).Scan(&body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", id, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(body), nil
+}
+
+// scan calls fn with the two text columns of each row of query.
+func (st *SQLiteStorage) scan(ctx context.Context, query string, fn func(a, b string) error, args ...any) error {
+	rows, err := st.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a, b string
+		if err := rows.Scan(&a, &b); err != nil {
+			return err
+		}
+		if err := fn(a, b); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// useSnapshot falls back to the report's context snapshot for a case
+// without a context of its own, as the directory layout does.
+func useSnapshot(c *Case) {
+	if c.EvalContext == nil && c.ReportV2 != nil && c.ReportV2.ContextSnapshot.Hash != "" {
+		c.EvalContext = &c.ReportV2.ContextSnapshot
+	}
+}
+
+// sqliteNow is the update time of a row written now.
+func sqliteNow() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func nullString(b []byte) sql.NullString {
+	return sql.NullString{String: string(b), Valid: b != nil}
+}
@@ -0,0 +1,117 @@
+//go:build sqlite
+
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	_ "modernc.org/sqlite"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestSQLiteStorage(t *testing.T) {
+	st, err := OpenSQLiteStorage(filepath.Join(t.TempDir(), "cases.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		if err := st.PutCase(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testStorage(t, st)
+
+	if err := st.DeleteCase(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := st.ListIDs(ctx); err != nil || len(ids) != 1 || ids[0] != "b" {
+		t.Errorf("ListIDs after deleting a = %v, %v, want [b]", ids, err)
+	}
+	if tags, err := st.GetTags(ctx, "a"); err != nil || tags != nil {
+		t.Errorf("tags of deleted case = %v, %v, want none", tags, err)
+	}
+}
+
+func TestServiceOnSQLite(t *testing.T) {
+	dir := t.TempDir()
+	st, err := OpenSQLiteStorage(filepath.Join(t.TempDir(), "cases.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(ServiceConfig{DatasetDir: dir, Storage: st}, nil)
+	defer s.Close()
+	ctx := context.Background()
+	if err := st.PutCase(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.PutTranscript(ctx, "a", "p", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	s.RegisterRoutes(mux)
+	call := func(method, target, body string, out any) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s = %d: %s", method, target, rec.Code, rec.Body)
+		}
+		if out != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for _, gt := range []string{"hi", "hello"} {
+		call("POST", "/api/cases/a:updateContext", `{"eval_context":{"meta":{"ground_truth":"`+gt+`"}}}`, nil)
+	}
+	for _, hash := range []string{"h1", "h2"} {
+		if err := s.writeEvalReport(ctx, "a", &evalv2.EvalReport{ContextSnapshot: evalv2.EvalContext{Hash: hash}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	call("POST", "/api/cases/a:rollbackReport", `{"version":1}`, nil)
+
+	var c Case
+	call("GET", "/api/cases/a", "", &c)
+	if c.Transcripts["p"] != "hello" || c.EvalContext == nil || c.EvalContext.Meta.GroundTruth != "hello" || c.ReportV2 == nil || c.ReportV2.ContextSnapshot.Hash != "h1" {
+		t.Errorf("case = %+v, want transcript, context and rolled back report", c)
+	}
+	var list ListCasesResponse
+	call("GET", "/api/cases", "", &list)
+	if len(list.Cases) != 1 || list.Cases[0].ID != "a" {
+		t.Errorf("cases = %+v, want a", list.Cases)
+	}
+	var contexts []*ContextVersion
+	call("GET", "/api/cases/a/contexts", "", &contexts)
+	if len(contexts) != 2 {
+		t.Errorf("got %d context versions, want 2", len(contexts))
+	}
+	var reports []*ReportVersion
+	call("GET", "/api/cases/a/reports", "", &reports)
+	var hashes []string
+	for _, v := range reports {
+		hashes = append(hashes, fmt.Sprintf("%d:%s", v.Version, v.ContextHash))
+	}
+	if diff := cmp.Diff([]string{"0:h1", "2:h2", "1:h1"}, hashes); diff != "" {
+		t.Errorf("report history mismatch (-want +got):\n%s", diff)
+	}
+
+	// The records are in the database, not the dataset directory.
+	if names, _ := filepath.Glob(filepath.Join(dir, "a.*")); len(names) > 0 {
+		t.Errorf("dataset directory has case files %v", names)
+	}
+}
@@ -0,0 +1,74 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// SSE event names sent by streaming handlers.
+const (
+	eventProgress = "progress"
+	eventResult   = "result"
+	eventError    = "error"
+)
+
+// wantsEventStream reports whether the client asked for Server-Sent Events.
+func wantsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// eventStream writes Server-Sent Events. It is safe for concurrent use.
+type eventStream struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	f  http.Flusher
+}
+
+func newEventStream(w http.ResponseWriter) (*eventStream, error) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("streaming not supported")
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &eventStream{w: w, f: f}, nil
+}
+
+func (s *eventStream) send(event string, data any) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, b)
+	s.f.Flush()
+}
+
+// streamResult runs fn while streaming evaluator progress, then sends the
+// result or the error as the final event.
+func streamResult(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context) (any, error)) {
+	es, err := newEventStream(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ctx := evalv2.WithProgress(r.Context(), func(p evalv2.Progress) {
+		es.send(eventProgress, p)
+	})
+	res, err := fn(ctx)
+	if err != nil {
+		es.send(eventError, map[string]string{"message": err.Error()})
+		return
+	}
+	es.send(eventResult, res)
+}
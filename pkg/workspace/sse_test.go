@@ -0,0 +1,94 @@
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/llmclient"
+)
+
+// verdictLLM passes every checkpoint of providers p1 and p2.
+type verdictLLM struct{}
+
+func (verdictLLM) GenerateJSON(ctx context.Context, model string, req *llmclient.Request, resp any) (*llmclient.Usage, error) {
+	return &llmclient.Usage{}, json.Unmarshal([]byte(`[
+		{"provider": "p1", "checkpoint_results": [{"id": "S1", "status": "Pass"}]},
+		{"provider": "p2", "checkpoint_results": [{"id": "S1", "status": "Pass"}]}
+	]`), resp)
+}
+
+func (verdictLLM) CountTokens(ctx context.Context, model string, req *llmclient.Request) (int, error) {
+	return 0, nil
+}
+
+func TestEvaluateEventStream(t *testing.T) {
+	dir := t.TempDir()
+	pcm := &audio.PCM{SampleRate: 8000, Channels: 1, Samples: make([]int16, 8000)}
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), pcm.FLAC(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(ServiceConfig{DatasetDir: dir, EvalModel: "eval"}, verdictLLM{})
+	defer s.Close()
+	ctx := context.Background()
+	for _, p := range []string{"p1", "p2"} {
+		if err := s.Storage.PutTranscript(ctx, "a", p, "hello"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ec := &evalv2.EvalContext{Checkpoints: []evalv2.Checkpoint{{ID: "S1", Tier: 1, Weight: 1, TextSegment: "hello"}}}
+	ec.Hash = contextHash(ec)
+	body, err := json.Marshal(EvaluateRequest{EvalContext: ec})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/cases/a:evaluate", strings.NewReader(string(body)))
+	r.Header.Set("Accept", "text/event-stream")
+	r.SetPathValue("id", "a")
+	w := httptest.NewRecorder()
+	s.handleEvaluateCase(w, r)
+
+	var (
+		progress []evalv2.Progress
+		events   []string
+	)
+	stream := w.Body.String()
+	sc := bufio.NewScanner(strings.NewReader(stream))
+	event := ""
+	for sc.Scan() {
+		line := sc.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			event = name
+			events = append(events, name)
+		} else if data, ok := strings.CutPrefix(line, "data: "); ok && event == eventProgress {
+			var p evalv2.Progress
+			if err := json.Unmarshal([]byte(data), &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Stage == evalv2.StageProviderEvaluated {
+				progress = append(progress, p)
+			}
+		}
+	}
+	if got := events[len(events)-1]; got != eventResult {
+		t.Fatalf("last event = %s, want %s; stream:\n%s", got, eventResult, stream)
+	}
+	want := []evalv2.Progress{
+		{Stage: evalv2.StageProviderEvaluated, Model: "eval", Provider: "p1", Done: 1, Total: 2},
+		{Stage: evalv2.StageProviderEvaluated, Model: "eval", Provider: "p2", Done: 2, Total: 2},
+	}
+	if diff := cmp.Diff(want, progress); diff != "" {
+		t.Errorf("provider progress mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,34 @@
+package workspace
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// StaticHandler serves the UI from fsys. Paths that don't name a file get
+// index.html, so client-side routes survive a reload; unmatched /api/ and
+// /audio/ paths stay 404s.
+func StaticHandler(fsys fs.FS) http.Handler {
+	files := http.FileServerFS(fsys)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/audio/") {
+			http.NotFound(w, r)
+			return
+		}
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if fi, err := fs.Stat(fsys, name); err == nil && !fi.IsDir() && name != "index.html" {
+			files.ServeHTTP(w, r)
+			return
+		}
+		index, err := fs.ReadFile(fsys, "index.html")
+		if err != nil {
+			http.Error(w, "UI not built: run npm run build in ui/ or pass -static-dir", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(index)
+	})
+}
@@ -0,0 +1,40 @@
+package workspace
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticHandler(t *testing.T) {
+	h := StaticHandler(fstest.MapFS{
+		"index.html":    {Data: []byte("index")},
+		"assets/app.js": {Data: []byte("app")},
+	})
+	for _, tc := range []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"/", http.StatusOK, "index"},
+		{"/assets/app.js", http.StatusOK, "app"},
+		{"/cases/a", http.StatusOK, "index"}, // Client-side route
+		{"/assets/", http.StatusOK, "index"},
+		{"/api/nope", http.StatusNotFound, "404 page not found\n"},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+		body, _ := io.ReadAll(rec.Body)
+		if rec.Code != tc.wantCode || string(body) != tc.wantBody {
+			t.Errorf("GET %s = %d %q, want %d %q", tc.path, rec.Code, body, tc.wantCode, tc.wantBody)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	StaticHandler(fstest.MapFS{}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET / without a build = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,271 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// defaultHeadToHeadTop is how many of the largest gaps HeadToHead lists by
+// default.
+const defaultHeadToHeadTop = 10
+
+// ProviderStats is a provider's token-weighted score across cases. Scores are
+// on a 0-100 scale.
+type ProviderStats struct {
+	Provider    string  `json:"provider"`
+	WeightedQ   float64 `json:"weighted_q"`
+	WeightedS   float64 `json:"weighted_s"`
+	WeightedP   float64 `json:"weighted_p"`
+	TotalTokens int     `json:"total_tokens"`
+	Cases       int     `json:"cases"`
+}
+
+// Leaderboard accumulates token-weighted provider scores from reports.
+type Leaderboard struct {
+	SScoreWeight float64 // S weight in the Q score; 0 uses evalv2.SScoreWeight
+
+	sums map[string]*ProviderStats
+}
+
+// Add accumulates every result in report, weighted by tokenCount. Reports
+// without a positive token count are ignored.
+func (l *Leaderboard) Add(report *evalv2.EvalReport, tokenCount int) {
+	if tokenCount <= 0 {
+		return
+	}
+	if l.sums == nil {
+		l.sums = make(map[string]*ProviderStats)
+	}
+	w := float64(tokenCount)
+	sWeight := l.SScoreWeight
+	if sWeight == 0 {
+		sWeight = evalv2.SScoreWeight
+	}
+	for provider, result := range report.Results {
+		s, ok := l.sums[provider]
+		if !ok {
+			s = &ProviderStats{Provider: provider}
+			l.sums[provider] = s
+		}
+		// Accumulate Q (0-100), S and P (convert 0.0-1.0 to 0-100 for consistency)
+		s.WeightedQ += float64(result.Metrics.CompositeScoreWith(sWeight)) * w
+		s.WeightedS += result.Metrics.SScore * 100 * w
+		s.WeightedP += result.Metrics.PScore * 100 * w
+		s.TotalTokens += tokenCount
+		s.Cases++
+	}
+}
+
+// Rankings returns the weighted averages per provider, best Q first.
+func (l *Leaderboard) Rankings() []ProviderStats {
+	out := make([]ProviderStats, 0, len(l.sums))
+	for _, s := range l.sums {
+		r := *s
+		if r.TotalTokens > 0 {
+			t := float64(r.TotalTokens)
+			r.WeightedQ /= t
+			r.WeightedS /= t
+			r.WeightedP /= t
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].WeightedQ != out[j].WeightedQ {
+			return out[i].WeightedQ > out[j].WeightedQ
+		}
+		return out[i].Provider < out[j].Provider
+	})
+	return out
+}
+
+// Leaderboard ranks the enabled providers over the cases matching filter,
+// leaving out those flagged for audio issues.
+func (s *Service) Leaderboard(ctx context.Context, filter CaseFilter) ([]ProviderStats, error) {
+	cases, _, err := s.listCasesFor(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	enabled := s.enabledProviders()
+	flagged := s.flaggedAudio(ctx)
+	lb := Leaderboard{SScoreWeight: s.sScoreWeight()}
+	for _, c := range cases {
+		if c.ReportV2 == nil || flagged[c.ID] || !filter.Match(c, enabled) {
+			continue
+		}
+		report := *c.ReportV2
+		report.Results = make(map[string]evalv2.EvalResult, len(c.ReportV2.Results))
+		for p, r := range c.ReportV2.Results {
+			if enabled == nil || enabled[p] {
+				report.Results[p] = r
+			}
+		}
+		lb.Add(&report, reportTokenCount(c))
+	}
+	return lb.Rankings(), nil
+}
+
+// reportTokenCount returns the token estimate the report was scored against,
+// falling back to the current context.
+func reportTokenCount(c *Case) int {
+	if n := c.ReportV2.ContextSnapshot.Meta.TotalTokenCountEstimate; n > 0 {
+		return n
+	}
+	return caseTokenCount(c)
+}
+
+// HeadToHead compares two providers on the matching cases that have a
+// result for both and no audio issues.
+func (s *Service) HeadToHead(ctx context.Context, req HeadToHeadRequest) (*HeadToHeadResponse, error) {
+	if req.A == "" || req.B == "" || req.A == req.B {
+		return nil, fmt.Errorf("two different providers are required")
+	}
+	cases, err := s.scanCasesFor(ctx, req.CaseFilter)
+	if err != nil {
+		return nil, err
+	}
+	enabled := s.enabledProviders()
+	flagged := s.flaggedAudio(ctx)
+	matched := cases[:0]
+	for _, c := range cases {
+		if !flagged[c.ID] && req.Match(c, enabled) {
+			matched = append(matched, c)
+		}
+	}
+	return headToHead(matched, req.A, req.B, req.Top), nil
+}
+
+// headToHead builds the comparison of a against b. top bounds the largest
+// gaps listed; 0 uses defaultHeadToHeadTop.
+func headToHead(cases []*Case, a, b string, top int) *HeadToHeadResponse {
+	if top <= 0 {
+		top = defaultHeadToHeadTop
+	}
+	resp := &HeadToHeadResponse{A: a, B: b, Cases: []CaseDelta{}}
+	var sum int
+	for _, c := range cases {
+		if c.ReportV2 == nil {
+			continue
+		}
+		ra, okA := c.ReportV2.Results[a]
+		rb, okB := c.ReportV2.Results[b]
+		if !okA || !okB {
+			continue
+		}
+		d := CaseDelta{ID: c.ID, A: ra.Metrics.QScore, B: rb.Metrics.QScore}
+		d.Delta = d.A - d.B
+		switch {
+		case d.Delta > 0:
+			resp.WinsA++
+		case d.Delta < 0:
+			resp.WinsB++
+		default:
+			resp.Ties++
+		}
+		sum += d.Delta
+		resp.Cases = append(resp.Cases, d)
+	}
+	if n := len(resp.Cases); n > 0 {
+		resp.MeanDelta = float64(sum) / float64(n)
+	}
+
+	resp.TopGaps = slices.Clone(resp.Cases)
+	sort.SliceStable(resp.TopGaps, func(i, j int) bool {
+		return abs(resp.TopGaps[i].Delta) > abs(resp.TopGaps[j].Delta)
+	})
+	resp.TopGaps = resp.TopGaps[:min(top, len(resp.TopGaps))]
+	return resp
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Coverage reports, for each matching case, how far it is through the
+// workflow: transcripts, context, evaluation and review.
+func (s *Service) Coverage(ctx context.Context, filter CaseFilter) (*CoverageResponse, error) {
+	cases, err := s.scanCases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(s.Config.DatasetDir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		files[e.Name()] = true
+	}
+
+	enabled := s.enabledProviders()
+	matched := cases[:0]
+	for _, c := range cases {
+		if filter.Match(c, enabled) {
+			matched = append(matched, c)
+		}
+	}
+	return coverage(matched, files, s.EnabledProviderIDs()), nil
+}
+
+// coverage builds the coverage of cases over providers. files holds the
+// names in the dataset directory.
+func coverage(cases []*Case, files map[string]bool, providers []string) *CoverageResponse {
+	resp := &CoverageResponse{
+		Providers: providers,
+		Cases:     []CaseCoverage{},
+		Totals: CoverageTotals{
+			Transcripts: make(map[string]int),
+			Reports:     make(map[string]int),
+			Review:      make(map[ReviewState]int),
+		},
+	}
+	if resp.Providers == nil {
+		resp.Providers = []string{}
+	}
+	for _, c := range cases {
+		cc := CaseCoverage{
+			ID:          c.ID,
+			Transcripts: []string{},
+			HasContext:  files[c.ID+extGTV2],
+			Report:      ReportNone,
+			ReviewState: reviewState(c.Review),
+		}
+		for _, p := range providers {
+			if !files[c.ID+"."+p] {
+				continue
+			}
+			cc.Transcripts = append(cc.Transcripts, p)
+			if c.ReportV2 == nil {
+				cc.Unevaluated = append(cc.Unevaluated, p)
+			} else if _, ok := c.ReportV2.Results[p]; !ok {
+				cc.Unevaluated = append(cc.Unevaluated, p)
+			}
+			resp.Totals.Transcripts[p]++
+		}
+		if c.ReportV2 != nil {
+			cc.Report = ReportFresh
+			if isStale(c) {
+				cc.Report = ReportStale
+			}
+		}
+
+		resp.Totals.Cases++
+		if cc.HasContext {
+			resp.Totals.WithContext++
+		}
+		resp.Totals.Reports[cc.Report]++
+		if len(cc.Unevaluated) > 0 {
+			resp.Totals.Unevaluated++
+		}
+		resp.Totals.Review[cc.ReviewState]++
+		resp.Cases = append(resp.Cases, cc)
+	}
+	return resp
+}
@@ -0,0 +1,95 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestHeadToHead(t *testing.T) {
+	report := func(scores map[string]int) *evalv2.EvalReport {
+		r := &evalv2.EvalReport{Results: make(map[string]evalv2.EvalResult)}
+		for p, q := range scores {
+			r.Results[p] = evalv2.EvalResult{Metrics: evalv2.EvalMetrics{QScore: q}}
+		}
+		return r
+	}
+	cases := []*Case{
+		{ID: "1", ReportV2: report(map[string]int{"a": 90, "b": 80})},
+		{ID: "2", ReportV2: report(map[string]int{"a": 50, "b": 85})},
+		{ID: "3", ReportV2: report(map[string]int{"a": 70, "b": 70})},
+		{ID: "4", ReportV2: report(map[string]int{"a": 60})},
+		{ID: "5"},
+	}
+
+	got := headToHead(cases, "a", "b", 2)
+	want := &HeadToHeadResponse{
+		A: "a", B: "b",
+		WinsA: 1, WinsB: 1, Ties: 1,
+		MeanDelta: -25.0 / 3,
+		Cases: []CaseDelta{
+			{ID: "1", A: 90, B: 80, Delta: 10},
+			{ID: "2", A: 50, B: 85, Delta: -35},
+			{ID: "3", A: 70, B: 70, Delta: 0},
+		},
+		TopGaps: []CaseDelta{
+			{ID: "2", A: 50, B: 85, Delta: -35},
+			{ID: "1", A: 90, B: 80, Delta: 10},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("headToHead mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	ctxA := &evalv2.EvalContext{Hash: "new"}
+	cases := []*Case{
+		{
+			ID:          "1",
+			EvalContext: ctxA,
+			ReportV2: &evalv2.EvalReport{
+				Results:         map[string]evalv2.EvalResult{"a": {}},
+				ContextSnapshot: evalv2.EvalContext{Hash: "new"},
+			},
+			Review: &Review{State: ReviewApproved},
+		},
+		{
+			ID:          "2",
+			EvalContext: ctxA,
+			ReportV2: &evalv2.EvalReport{
+				Results:         map[string]evalv2.EvalResult{"a": {}, "b": {}},
+				ContextSnapshot: evalv2.EvalContext{Hash: "old"},
+			},
+		},
+		{ID: "3"},
+	}
+	files := map[string]bool{
+		"1.flac": true, "1.a": true, "1.b": true, "1.gt.v2.json": true,
+		"2.flac": true, "2.a": true, "2.gt.v2.json": true,
+		"3.flac": true,
+	}
+
+	got := coverage(cases, files, []string{"a", "b"})
+	want := &CoverageResponse{
+		Providers: []string{"a", "b"},
+		Cases: []CaseCoverage{
+			{ID: "1", Transcripts: []string{"a", "b"}, HasContext: true, Report: ReportFresh, Unevaluated: []string{"b"}, ReviewState: ReviewApproved},
+			{ID: "2", Transcripts: []string{"a"}, HasContext: true, Report: ReportStale, ReviewState: ReviewUnreviewed},
+			{ID: "3", Transcripts: []string{}, Report: ReportNone, ReviewState: ReviewUnreviewed},
+		},
+		Totals: CoverageTotals{
+			Cases:       3,
+			Transcripts: map[string]int{"a": 2, "b": 1},
+			WithContext: 2,
+			Reports:     map[string]int{ReportFresh: 1, ReportStale: 1, ReportNone: 1},
+			Unevaluated: 1,
+			Review:      map[ReviewState]int{ReviewApproved: 1, ReviewUnreviewed: 2},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("coverage mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,130 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// Storage holds the case records of a dataset: which cases exist, their
+// transcripts, eval contexts and reports with their history, tags, and the
+// audit log. Getting a record that does not exist fails with an error
+// matching os.ErrNotExist.
+//
+// FSStorage is the dataset directory layout. Whatever the storage, audio,
+// reviews, comments, corrections, channel selections, stream dumps, runs
+// and jobs stay in the dataset directory.
+type Storage interface {
+	// ListIDs returns the IDs of all cases, sorted.
+	ListIDs(ctx context.Context) ([]string, error)
+	// ListCases returns summary cases, with context, report and tags but
+	// no transcripts, sorted by ID.
+	ListCases(ctx context.Context) ([]*Case, error)
+	// GetSummary returns the summary case for id.
+	GetSummary(ctx context.Context, id string) (*Case, error)
+	// GetCase returns a case with its transcripts, tags, context and report.
+	GetCase(ctx context.Context, id string) (*Case, error)
+	// PutCase adds a case if it does not exist. The Put methods below
+	// require the case to exist.
+	PutCase(ctx context.Context, id string) error
+	// DeleteCase removes a case and all its records.
+	DeleteCase(ctx context.Context, id string) error
+
+	PutTranscript(ctx context.Context, id, provider, text string) error
+	GetContext(ctx context.Context, id string) (*evalv2.EvalContext, error)
+	PutContext(ctx context.Context, id string, c *evalv2.EvalContext) error
+	GetReport(ctx context.Context, id string) (*evalv2.EvalReport, error)
+	PutReport(ctx context.Context, id string, r *evalv2.EvalReport) error
+	DeleteReport(ctx context.Context, id string) error
+	// ArchiveContext moves the case's context into its history as the next
+	// version, which it returns, pruning the oldest versions beyond
+	// maxHistory. It returns 0 when the case has no context of its own.
+	ArchiveContext(ctx context.Context, id string) (int, error)
+	// ListContextVersions returns the case's archived contexts, oldest
+	// first, followed by the current one, numbered 0, when there is one.
+	ListContextVersions(ctx context.Context, id string) ([]RecordVersion, error)
+	// GetContextVersion returns archived version n of the case's context.
+	GetContextVersion(ctx context.Context, id string, n int) (*evalv2.EvalContext, error)
+	// ArchiveReport, ListReportVersions and GetReportVersion are their
+	// counterparts for reports.
+	ArchiveReport(ctx context.Context, id string) (int, error)
+	ListReportVersions(ctx context.Context, id string) ([]RecordVersion, error)
+	GetReportVersion(ctx context.Context, id string, n int) (*evalv2.EvalReport, error)
+
+	// GetTags returns the case's tags, nil when it has none.
+	GetTags(ctx context.Context, id string) ([]string, error)
+	// PutTags replaces the case's tags.
+	PutTags(ctx context.Context, id string, tags []string) error
+
+	AppendAudit(ctx context.Context, e *AuditEntry) error
+	// ListAudit returns audit entries matching req, newest first.
+	ListAudit(ctx context.Context, req ListAuditRequest) ([]*AuditEntry, error)
+
+	Close() error
+}
+
+// CopyToStorageResponse counts what CopyToStorage wrote.
+type CopyToStorageResponse struct {
+	Cases       int
+	Transcripts int
+	Contexts    int
+	Reports     int
+	Audit       int
+}
+
+// CopyToStorage copies every case record of the service's storage, and
+// the audit log, into dst. Existing records in dst are overwritten;
+// running it again after the dataset changed brings dst up to date,
+// except that audit entries are appended again.
+func (s *Service) CopyToStorage(ctx context.Context, dst Storage) (*CopyToStorageResponse, error) {
+	ids, err := s.Storage.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CopyToStorageResponse{}
+	for _, id := range ids {
+		c, err := s.Storage.GetCase(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := dst.PutCase(ctx, id); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		for p, t := range c.Transcripts {
+			if err := dst.PutTranscript(ctx, id, p, t); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+			resp.Transcripts++
+		}
+		// Only a context of its own; GetCase falls back to the report's.
+		if ec, err := s.Storage.GetContext(ctx, id); err == nil {
+			if err := dst.PutContext(ctx, id, ec); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+			resp.Contexts++
+		}
+		if c.ReportV2 != nil {
+			if err := dst.PutReport(ctx, id, c.ReportV2); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+			resp.Reports++
+		}
+		if err := dst.PutTags(ctx, id, c.Tags); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		resp.Cases++
+	}
+
+	entries, err := s.Storage.ListAudit(ctx, ListAuditRequest{})
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- { // Oldest first
+		if err := dst.AppendAudit(ctx, entries[i]); err != nil {
+			return nil, err
+		}
+		resp.Audit++
+	}
+	return resp, nil
+}
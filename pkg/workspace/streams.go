@@ -0,0 +1,92 @@
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"asr-eval/pkg/audio"
+)
+
+// extStream is the suffix of realtime stream dumps, "[id].<provider>.stream.json".
+const extStream = ".stream.json"
+
+// streamEntry is one line of a stream dump as written by cmd/processor: the
+// time since the session started, whether the text is a finalized segment,
+// and the text itself.
+type streamEntry struct {
+	Timestamp int64  `json:"t"`
+	Final     bool   `json:"f,omitempty"`
+	Text      string `json:"s"`
+}
+
+// GetStream returns a provider's realtime stream for a case as a list of
+// transcript states. Each event carries the full text visible at that time,
+// finalized segments followed by the current partial, so the UI can show
+// the latest event at or before the playback position. Times are in the
+// case audio even when the provider heard it trimmed of silence.
+func (s *Service) GetStream(ctx context.Context, id, provider string) (*Stream, error) {
+	if !providerIDPattern.MatchString(provider) {
+		return nil, fmt.Errorf("invalid provider ID: %q", provider)
+	}
+	f, err := os.Open(filepath.Join(s.Config.DatasetDir, id+"."+provider+extStream))
+	if err != nil {
+		return nil, fmt.Errorf("stream not found: %s/%s", id, provider)
+	}
+	defer f.Close()
+	cuts, err := readCuts(filepath.Join(s.Config.DatasetDir, id+"."+provider+extTrim))
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &Stream{Provider: provider}
+	var final strings.Builder
+	var last int64
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var e streamEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("malformed stream entry: %w", err)
+		}
+
+		// Keep time monotonic; entries are written from a single goroutine
+		// but clocks can still step.
+		last = max(last, e.Timestamp)
+		ev := StreamEvent{OffsetMS: audio.OriginalMS(cuts, last), Final: e.Final}
+		if e.Final {
+			final.WriteString(e.Text)
+		} else {
+			ev.Partial = e.Text
+		}
+		ev.Committed = final.String()
+		stream.Events = append(stream.Events, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// listStreams returns the providers with a stream dump for case id.
+func (s *Service) listStreams(id string) ([]string, error) {
+	files, err := caseFiles(s.Config.DatasetDir, id)
+	if err != nil {
+		return nil, err
+	}
+	var providers []string
+	for _, name := range files {
+		if p, ok := strings.CutSuffix(name, extStream); ok {
+			providers = append(providers, strings.TrimPrefix(p, id+"."))
+		}
+	}
+	return providers, nil
+}
@@ -0,0 +1,170 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"asr-eval/pkg/atomicfile"
+)
+
+// syncStateName records in a dataset copy how far the change journal of
+// its source has been replayed onto it.
+const syncStateName = ".sync.json"
+
+type syncState struct {
+	Source string `json:"source"` // Absolute path of the source dataset
+	Seq    int64  `json:"seq"`    // Last change replayed
+}
+
+// SyncResponse counts what SyncDataset did.
+type SyncResponse struct {
+	Changes int   // Journal entries replayed, several per file at times
+	Copied  int   // Files copied
+	Deleted int   // Files removed
+	Seq     int64 // Last change replayed
+}
+
+// SyncDataset brings the dataset copy in dir up to date with the service's
+// dataset by replaying the change journal from where the last sync to dir
+// stopped, after journaling changes made while no service was running.
+// Only changed files are copied. Sync is one way: files changed in the
+// copy since are overwritten, and the copy's own journal is not used.
+// Files are copied holding the copy's case locks, so a server on the copy
+// can keep running. With dryRun set it only counts the changes.
+func (s *Service) SyncDataset(ctx context.Context, dir string, dryRun bool) (*SyncResponse, error) {
+	src, err := filepath.Abs(s.Config.DatasetDir)
+	if err != nil {
+		return nil, err
+	}
+	if dst, err := filepath.Abs(dir); err != nil {
+		return nil, err
+	} else if dst == src {
+		return nil, fmt.Errorf("cannot sync %s onto itself", dir)
+	}
+	if err := s.JournalDataset(ctx); err != nil {
+		return nil, err
+	}
+
+	state := syncState{Source: src}
+	if content, err := os.ReadFile(filepath.Join(dir, syncStateName)); err == nil {
+		var prev syncState
+		if err := json.Unmarshal(content, &prev); err != nil {
+			return nil, fmt.Errorf("%s: %w", syncStateName, err)
+		}
+		if prev.Source != src {
+			return nil, fmt.Errorf("%s was synced from %s, not %s", dir, prev.Source, src)
+		}
+		state = prev
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	changes, err := s.ReadChanges(state.Seq)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SyncResponse{Changes: len(changes), Seq: state.Seq}
+	if len(changes) == 0 {
+		return resp, nil
+	}
+
+	// Only the last change to a file matters.
+	last := make(map[string]Change)
+	for _, c := range changes {
+		last[c.Path] = c
+		resp.Seq = c.Seq
+	}
+	ids, err := s.Storage.ListIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	caseIDs := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		caseIDs[id] = true
+	}
+	paths := make([]string, 0, len(last))
+	for p := range last {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		c := last[p]
+		if dryRun {
+			if c.Op == ChangeDelete {
+				resp.Deleted++
+			} else {
+				resp.Copied++
+			}
+			continue
+		}
+		done, err := s.syncFile(ctx, dir, c, caseIDs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if done && c.Op == ChangeDelete {
+			resp.Deleted++
+		} else if done {
+			resp.Copied++
+		}
+	}
+	if dryRun {
+		return resp, nil
+	}
+	state.Seq = resp.Seq
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return resp, atomicfile.WriteFile(filepath.Join(dir, syncStateName), b)
+}
+
+// syncFile applies change c to the dataset copy in dir. A file put since
+// c was journaled is left for the change that journals it.
+func (s *Service) syncFile(ctx context.Context, dir string, c Change, caseIDs map[string]bool) (bool, error) {
+	if strings.HasPrefix(c.Path, "/") || strings.Contains("/"+c.Path+"/", "/../") {
+		return false, fmt.Errorf("invalid path")
+	}
+	dst := filepath.Join(dir, filepath.FromSlash(c.Path))
+	if id, ok := owningCase(c.Path, caseIDs); ok && !strings.Contains(c.Path, "/") {
+		locks := filepath.Join(dir, locksDirName)
+		if err := os.MkdirAll(locks, 0755); err != nil {
+			return false, err
+		}
+		unlock, err := lockFile(ctx, filepath.Join(locks, id+".lock"))
+		if err != nil {
+			return false, err
+		}
+		defer unlock()
+	}
+
+	if c.Op == ChangeDelete {
+		err := os.Remove(dst)
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	src := filepath.Join(s.Config.DatasetDir, filepath.FromSlash(c.Path))
+	if hash, err := hashFile(src); err != nil || hash != c.Hash {
+		return false, nil
+	}
+	if hash, err := hashFile(dst); err == nil && hash == c.Hash {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, err
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return true, atomicfile.Copy(dst, f)
+}
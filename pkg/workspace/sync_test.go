@@ -0,0 +1,71 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"asr-eval/pkg/evalv2"
+)
+
+func TestSyncDataset(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: src}, Storage: NewFSStorage(src)}
+	ctx := context.Background()
+	for _, name := range []string{"a.flac", "b.flac"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte("fLaC"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ec := &evalv2.EvalContext{Meta: evalv2.ContextMeta{GroundTruth: "hello"}}
+	ec.Hash = contextHash(ec)
+	write := func(id string) {
+		t.Helper()
+		unlock, err := s.lockCase(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unlock()
+		if err := s.writeEvalContext(ctx, id, ec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a")
+
+	resp, err := s.SyncDataset(ctx, dst, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both audio files, a's context and its context store entry.
+	if resp.Copied != 4 || resp.Deleted != 0 {
+		t.Errorf("first sync = %+v, want 4 files copied", resp)
+	}
+	for _, name := range []string{"a.flac", "b.flac", "a" + extGTV2, contextsDirName + "/" + ec.Hash + extJSON} {
+		if _, err := os.Stat(filepath.Join(dst, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s not synced: %v", name, err)
+		}
+	}
+
+	if resp, err := s.SyncDataset(ctx, dst, false); err != nil || resp.Changes != 0 {
+		t.Errorf("second sync = %+v, %v, want no changes", resp, err)
+	}
+
+	if err := os.Remove(filepath.Join(src, "b.flac")); err != nil {
+		t.Fatal(err)
+	}
+	write("a") // Archives the first context; the current one is unchanged
+	resp, err = s.SyncDataset(ctx, dst, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Copied != 1 || resp.Deleted != 1 {
+		t.Errorf("third sync = %+v, want 1 file copied and 1 removed", resp)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "b.flac")); !os.IsNotExist(err) {
+		t.Errorf("b.flac left in the copy: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, versionName("a", extGTV2, 1))); err != nil {
+		t.Errorf("archived context not synced: %v", err)
+	}
+}
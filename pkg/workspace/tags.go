@@ -0,0 +1,62 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// extTags is the per-case sidecar holding the case's tags as a JSON array.
+const extTags = ".tags.json"
+
+// UpdateTags adds and removes tags on a case and returns the updated case.
+// Tags are trimmed and lower-cased; removals apply after additions.
+func (s *Service) UpdateTags(ctx context.Context, req UpdateTagsRequest) (*Case, error) {
+	if _, err := os.Stat(filepath.Join(s.Config.DatasetDir, req.ID+extFlac)); err != nil {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	tags, err := s.Storage.GetTags(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range req.Add {
+		t, err := normalizeTag(t)
+		if err != nil {
+			return nil, err
+		}
+		if len(s.Config.Tags) > 0 && !slices.Contains(s.Config.Tags, t) {
+			return nil, fmt.Errorf("tag %q is not listed in %s", t, datasetConfigFileName)
+		}
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	for _, t := range req.Remove {
+		t, _ := normalizeTag(t)
+		tags = slices.DeleteFunc(tags, func(x string) bool { return x == t })
+	}
+	slices.Sort(tags)
+
+	if err := s.Storage.PutTags(ctx, req.ID, tags); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditUpdateTags, req.ID, map[string]string{"tags": strings.Join(tags, ",")})
+	return s.GetCase(ctx, req.ID)
+}
+
+func normalizeTag(t string) (string, error) {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t == "" || strings.ContainsAny(t, ",") {
+		return "", fmt.Errorf("invalid tag: %q", t)
+	}
+	return t, nil
+}
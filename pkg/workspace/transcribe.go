@@ -0,0 +1,157 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+)
+
+// Transcribe runs the provider's ASR client on the case audio and saves the
+// transcript, plus the stream dump for streaming providers, the chunk
+// sidecar when the audio was transcribed in chunks, and the trim sidecar
+// when silence was trimmed from it. Without a context in req, providers
+// that take one are biased with the vocabulary of the case's eval context.
+// An empty transcript is an error and leaves existing files untouched.
+func (s *Service) Transcribe(ctx context.Context, req TranscribeRequest) (*Case, error) {
+	if !providerIDPattern.MatchString(req.Provider) {
+		return nil, fmt.Errorf("invalid provider ID: %q", req.Provider)
+	}
+	audioPath := filepath.Join(s.Config.DatasetDir, req.ID+extFlac)
+	if _, err := os.Stat(audioPath); err != nil {
+		return nil, fmt.Errorf("case not found: %s", req.ID)
+	}
+	t, err := asr.New(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := s.channelOptions(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := asr.Options{Context: req.Context}
+	details := map[string]string{"provider": req.Provider}
+	if req.Context == "" {
+		if ec, err := s.loadEvalContext(ctx, req.ID); err == nil {
+			if opts.Bias = contextBias(ec); opts.Bias != nil {
+				details["bias_context"] = contextHash(ec)
+			}
+		}
+	}
+	res, err := s.transcribe(ctx, t, audioPath, channels, opts)
+	if err != nil {
+		return nil, fmt.Errorf("transcribe %s with %s: %w", req.ID, req.Provider, err)
+	}
+	if res.Text == "" {
+		return nil, fmt.Errorf("%s returned an empty transcript", req.Provider)
+	}
+	s.recordTranscription(req.ID, req.Provider, audioPath)
+
+	unlock, err := s.lockCase(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := s.Storage.PutTranscript(ctx, req.ID, req.Provider, res.Text); err != nil {
+		return nil, err
+	}
+	stream := filepath.Join(s.Config.DatasetDir, req.ID+"."+req.Provider+extStream)
+	if err := writeStream(stream, res.Stream); err != nil {
+		return nil, err
+	}
+	if err := writeChunks(filepath.Join(s.Config.DatasetDir, req.ID+"."+req.Provider+extChunks), res.chunks); err != nil {
+		return nil, err
+	}
+	if err := writeCuts(filepath.Join(s.Config.DatasetDir, req.ID+"."+req.Provider+extTrim), res.cuts); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditTranscribe, req.ID, details)
+	return s.GetCase(ctx, req.ID)
+}
+
+// transcription is a provider's result and how the audio was prepared for
+// it.
+type transcription struct {
+	*asr.Result
+	chunks *TranscriptChunks // Nil when the audio was sent whole
+	cuts   []audio.Cut       // Silence trimmed before sending
+}
+
+// transcribe runs t on the audio file at path, reduced to the channel
+// channels selects, trimmed of silence if so configured, and in chunks when
+// it is longer than the configured chunk length.
+func (s *Service) transcribe(ctx context.Context, t asr.Transcriber, path string, channels audio.ChannelOptions, opts asr.Options) (*transcription, error) {
+	chunkMS := int64(s.Config.Chunking.Seconds * 1000)
+	trim := s.Config.TrimSilence
+	whole := trim == nil && chunkMS <= 0 && channels.IsMix()
+	if !whole && trim == nil {
+		// Audio of unknown length or layout is left to the provider to
+		// accept or reject; the clients average the channels of the rest.
+		info, err := audio.ReadInfo(path)
+		whole = err != nil || (chunkMS <= 0 || info.DurationMS <= chunkMS) && (channels.IsMix() || info.Channels <= 1)
+	}
+	if whole {
+		res, err := t.Transcribe(ctx, path, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &transcription{Result: res}, nil
+	}
+
+	pcm, err := audio.DecodeFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if pcm, err = pcm.SelectChannel(channels); err != nil {
+		return nil, err
+	}
+	tr := &transcription{}
+	if trim != nil {
+		pcm, tr.cuts = pcm.TrimSilence(*trim)
+	}
+	dir, err := os.MkdirTemp("", "asr-eval-transcribe-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if chunkMS <= 0 || int64(pcm.Frames())*1000/int64(pcm.SampleRate) <= chunkMS {
+		trimmed := filepath.Join(dir, "trimmed.wav")
+		if err := os.WriteFile(trimmed, pcm.WAV(), 0644); err != nil {
+			return nil, err
+		}
+		tr.Result, err = t.Transcribe(ctx, trimmed, opts)
+	} else {
+		tr.Result, tr.chunks, err = s.transcribeChunks(ctx, t, pcm, dir, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+// writeStream writes entries as JSON lines, removing the file when there
+// are none so a stale dump never outlives its transcript.
+func writeStream(path string, entries []asr.StreamEntry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return atomicfile.WriteFile(path, buf.Bytes())
+}
@@ -0,0 +1,157 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"asr-eval/pkg/asr"
+)
+
+// TranscriptionTask is one provider's transcription of one case.
+type TranscriptionTask struct {
+	ID       string
+	Provider string
+}
+
+// String returns "<provider>/<id>", which ParseTranscriptionTask reads.
+func (t TranscriptionTask) String() string {
+	return t.Provider + "/" + t.ID
+}
+
+// ParseTranscriptionTask parses the String form of a task.
+func ParseTranscriptionTask(s string) (TranscriptionTask, error) {
+	provider, id, ok := strings.Cut(s, "/")
+	if !ok || !providerIDPattern.MatchString(provider) || id == "" {
+		return TranscriptionTask{}, fmt.Errorf("invalid transcription task %q, want <provider>/<id>", s)
+	}
+	return TranscriptionTask{ID: id, Provider: provider}, nil
+}
+
+// ProviderLimits caps how hard TranscribeAll drives one provider.
+type ProviderLimits struct {
+	Concurrency   int // Calls at once; below 1 means 1
+	RatePerMinute int // Calls started a minute; 0 is unlimited
+}
+
+// TranscriptionTasksRequest selects the transcriptions to run.
+type TranscriptionTasksRequest struct {
+	Providers []string // Empty selects every enabled provider pkg/asr can run
+	IDs       []string // Cases to transcribe; empty selects every case
+	Overwrite bool     // Also redo cases that have the provider's transcript
+}
+
+// TranscriptionTasks returns the transcriptions req selects, by provider
+// and case.
+func (s *Service) TranscriptionTasks(ctx context.Context, req TranscriptionTasksRequest) ([]TranscriptionTask, error) {
+	registered := asr.Names()
+	providers := req.Providers
+	if len(providers) == 0 {
+		for _, p := range s.EnabledProviderIDs() {
+			if slices.Contains(registered, p) {
+				providers = append(providers, p)
+			}
+		}
+		slices.Sort(providers)
+	}
+	for _, p := range providers {
+		if !slices.Contains(registered, p) {
+			return nil, fmt.Errorf("no ASR client for provider %q; have %s", p, strings.Join(registered, ", "))
+		}
+	}
+
+	ids := req.IDs
+	if len(ids) == 0 {
+		var err error
+		if ids, err = s.Storage.ListIDs(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var tasks []TranscriptionTask
+	for _, p := range providers {
+		for _, id := range ids {
+			if !req.Overwrite {
+				c, err := s.GetCase(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := c.Transcripts[p]; ok {
+					continue
+				}
+			}
+			tasks = append(tasks, TranscriptionTask{ID: id, Provider: p})
+		}
+	}
+	return tasks, nil
+}
+
+// TranscribeAll runs tasks, each provider's within its limits, or def for
+// providers without any, and the providers in parallel. started, if not
+// nil, and done are called, possibly concurrently, as each task starts and
+// finishes. It returns when all have finished or ctx is done.
+func (s *Service) TranscribeAll(ctx context.Context, tasks []TranscriptionTask, limits map[string]ProviderLimits, def ProviderLimits, started func(TranscriptionTask), done func(TranscriptionTask, error)) {
+	byProvider := make(map[string][]TranscriptionTask)
+	for _, t := range tasks {
+		byProvider[t.Provider] = append(byProvider[t.Provider], t)
+	}
+	var wg sync.WaitGroup
+	for p, tasks := range byProvider {
+		l, ok := limits[p]
+		if !ok {
+			l = def
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.transcribeProvider(ctx, tasks, l, started, done)
+		}()
+	}
+	wg.Wait()
+}
+
+// transcribeProvider runs one provider's tasks within l.
+func (s *Service) transcribeProvider(ctx context.Context, tasks []TranscriptionTask, l ProviderLimits, started func(TranscriptionTask), done func(TranscriptionTask, error)) {
+	queue := make(chan TranscriptionTask)
+	var tick <-chan time.Time
+	if l.RatePerMinute > 0 {
+		t := time.NewTicker(time.Minute / time.Duration(l.RatePerMinute))
+		defer t.Stop()
+		tick = t.C
+	}
+
+	var wg sync.WaitGroup
+	for range max(l.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range queue {
+				if started != nil {
+					started(t)
+				}
+				_, err := s.Transcribe(ctx, TranscribeRequest{ID: t.ID, Provider: t.Provider})
+				done(t, err)
+			}
+		}()
+	}
+	for i, t := range tasks {
+		// The first call goes out at once; later ones wait for the ticker.
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case queue <- t:
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,108 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/asr"
+)
+
+type echoTranscriber struct{}
+
+func (echoTranscriber) Transcribe(ctx context.Context, path string, opts asr.Options) (*asr.Result, error) {
+	return &asr.Result{Text: filepath.Base(path)}, nil
+}
+
+func init() {
+	asr.Register("test_echo", func() (asr.Transcriber, error) { return echoTranscriber{}, nil })
+}
+
+func TestTranscribeAll(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.flac", "b.flac", "b.test_echo", "c.flac"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+
+	tasks, err := s.TranscriptionTasks(ctx, TranscriptionTasksRequest{Providers: []string{"test_echo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TranscriptionTask{{ID: "a", Provider: "test_echo"}, {ID: "c", Provider: "test_echo"}}
+	if diff := cmp.Diff(want, tasks); diff != "" {
+		t.Errorf("tasks mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := s.TranscriptionTasks(ctx, TranscriptionTasksRequest{Providers: []string{"nope"}}); err == nil {
+		t.Error("TranscriptionTasks of an unregistered provider succeeded, want error")
+	}
+
+	var mu sync.Mutex
+	var done []string
+	s.TranscribeAll(ctx, tasks, nil, ProviderLimits{Concurrency: 2, RatePerMinute: 6000}, nil, func(task TranscriptionTask, err error) {
+		if err != nil {
+			t.Errorf("%s: %v", task, err)
+		}
+		mu.Lock()
+		done = append(done, task.String())
+		mu.Unlock()
+	})
+	if len(done) != 2 {
+		t.Errorf("done = %v, want 2 tasks", done)
+	}
+	c, err := s.GetCase(ctx, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Transcripts["test_echo"]; got != "c.flac" {
+		t.Errorf("c transcript = %q, want c.flac", got)
+	}
+
+	if task, err := ParseTranscriptionTask("test_echo/a"); err != nil || task != want[0] {
+		t.Errorf("ParseTranscriptionTask = %v, %v; want %v", task, err, want[0])
+	}
+}
+
+func TestWatchTranscriptions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	finished := make(chan string)
+	watched := make(chan error)
+	go func() {
+		req := TranscriptionTasksRequest{Providers: []string{"test_echo"}}
+		watched <- s.WatchTranscriptions(ctx, req, nil, ProviderLimits{Concurrency: 1}, 10*time.Millisecond, nil, func(task TranscriptionTask, err error) {
+			if err != nil {
+				t.Errorf("%s: %v", task, err)
+			}
+			finished <- task.String()
+		})
+	}()
+	if got := <-finished; got != "test_echo/a" {
+		t.Errorf("first task = %s, want test_echo/a", got)
+	}
+	// A file dropped in later is picked up by the next poll.
+	if err := os.WriteFile(filepath.Join(dir, "b.flac"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-finished; got != "test_echo/b" {
+		t.Errorf("second task = %s, want test_echo/b", got)
+	}
+	cancel()
+	if err := <-watched; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,50 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+
+	"asr-eval/pkg/atomicfile"
+	"asr-eval/pkg/audio"
+)
+
+// extTrim is the suffix of the trim sidecar, "[id].<provider>.trim.json",
+// listing the silence cut from the audio before the provider heard it.
+const extTrim = ".trim.json"
+
+// trimSidecar is the content of a trim sidecar.
+type trimSidecar struct {
+	Cuts []audio.Cut `json:"cuts"` // In the original audio, in order
+}
+
+// writeCuts writes cuts as the trim sidecar at path, or removes the file
+// when there are none.
+func writeCuts(path string, cuts []audio.Cut) error {
+	if len(cuts) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content, err := json.MarshalIndent(trimSidecar{Cuts: cuts}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, content)
+}
+
+// readCuts reads the trim sidecar at path; a missing one means no cuts.
+func readCuts(path string) ([]audio.Cut, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ts trimSidecar
+	if err := json.Unmarshal(content, &ts); err != nil {
+		return nil, err
+	}
+	return ts.Cuts, nil
+}
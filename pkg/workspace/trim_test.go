@@ -0,0 +1,81 @@
+package workspace
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"asr-eval/pkg/asr"
+	"asr-eval/pkg/audio"
+)
+
+// lengthTranscriber reports the length of the audio it hears, with stream
+// entries at its start and middle.
+type lengthTranscriber struct{}
+
+func (lengthTranscriber) Transcribe(ctx context.Context, path string, opts asr.Options) (*asr.Result, error) {
+	info, err := audio.ReadInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	return &asr.Result{
+		Text:   "heard",
+		Stream: []asr.StreamEntry{{Timestamp: 0, Text: "a"}, {Timestamp: info.DurationMS / 2, Final: true, Text: "b"}},
+	}, nil
+}
+
+func init() {
+	asr.Register("test_length", func() (asr.Transcriber, error) { return lengthTranscriber{}, nil })
+}
+
+func TestTranscribeTrimSilence(t *testing.T) {
+	dir := t.TempDir()
+	// 1 s silence, 1 s tone, 1 s silence at 8 kHz.
+	pcm := &audio.PCM{SampleRate: 8000, Channels: 1, Samples: make([]int16, 3*8000)}
+	for i := 8000; i < 16000; i++ {
+		pcm.Samples[i] = int16(8000 * math.Sin(float64(i)/3))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.flac"), pcm.WAV(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{
+		Config:  ServiceConfig{DatasetDir: dir, TrimSilence: &audio.TrimOptions{}},
+		Storage: NewFSStorage(dir),
+	}
+	ctx := context.Background()
+	if _, err := s.Transcribe(ctx, TranscribeRequest{ID: "a", Provider: "test_length"}); err != nil {
+		t.Fatal(err)
+	}
+	cuts, err := readCuts(filepath.Join(dir, "a.test_length"+extTrim))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]audio.Cut{{StartMS: 0, EndMS: 800}, {StartMS: 2200, EndMS: 3000}}, cuts); diff != "" {
+		t.Errorf("cuts mismatch (-want +got):\n%s", diff)
+	}
+	// The provider heard 1.4 s; its times map back into the case audio.
+	stream, err := s.GetStream(ctx, "a", "test_length")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var offsets []int64
+	for _, e := range stream.Events {
+		offsets = append(offsets, e.OffsetMS)
+	}
+	if diff := cmp.Diff([]int64{800, 1500}, offsets); diff != "" {
+		t.Errorf("stream offsets mismatch (-want +got):\n%s", diff)
+	}
+
+	// Without trimming, the sidecar goes.
+	s.Config.TrimSilence = nil
+	if _, err := s.Transcribe(ctx, TranscribeRequest{ID: "a", Provider: "test_length"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.test_length"+extTrim)); !os.IsNotExist(err) {
+		t.Errorf("trim sidecar of an untrimmed transcription: %v", err)
+	}
+}
@@ -1,6 +1,13 @@
 package workspace
 
-import "asr-eval/pkg/evalv2"
+import (
+	"io"
+	"time"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/textdiff"
+)
 
 // Case represents a workspace case.
 // AIP-121: Resources should be defined by their data, not by view-specific fields if possible.
@@ -14,37 +21,434 @@ type Case struct {
 	// In Get view, these should be fully populated.
 	// GroundTruth is accessed via EvalContext or ReportV2.
 	Transcripts map[string]string `json:"transcripts,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`    // Scenario labels, e.g. noisy, long, dialect
+	Streams     []string          `json:"streams,omitempty"` // Providers with a realtime stream dump; Get view only
+	Review      *Review           `json:"review,omitempty"`  // Nil when unreviewed
+
+	// Corrections are human-corrected transcripts by provider; Get view only.
+	Corrections map[string]*Correction `json:"corrections,omitempty"`
+
+	// Channels is the case's own channel selection, nil when the dataset's
+	// applies; Get view only.
+	Channels *audio.ChannelOptions `json:"channels,omitempty"`
 
 	// Complex Objects
 	EvalContext *evalv2.EvalContext `json:"eval_context,omitempty"`
 	ReportV2    *evalv2.EvalReport  `json:"report_v2,omitempty"`
 }
 
+// ListCasesRequest for GET /api/cases
+type ListCasesRequest struct {
+	CaseFilter
+	Sort     string `json:"sort,omitempty"`      // id, q_score or token_count; "-" prefix for descending
+	Page     int    `json:"page,omitempty"`      // 1-based
+	PageSize int    `json:"page_size,omitempty"` // 0 returns all matching cases
+}
+
+// ListCasesResponse for GET /api/cases
+type ListCasesResponse struct {
+	Cases     []*Case `json:"cases"`
+	TotalSize int     `json:"total_size"` // Number of matching cases across all pages
+}
+
+// CreateCaseRequest for POST /api/cases
+// Sent as multipart/form-data: an "audio" file in FLAC or a format ffmpeg
+// converts to it (WAV, Ogg Vorbis or Opus, M4A, MP3 or AMR), an optional
+// "ground_truth" field and one "transcript.<provider>" field or file per
+// provider.
+type CreateCaseRequest struct {
+	Audio       io.Reader         `json:"-"`
+	GroundTruth string            `json:"ground_truth,omitempty"`
+	Transcripts map[string]string `json:"transcripts,omitempty"`
+}
+
+// DeleteCaseRequest for DELETE /api/cases/{id}?validate_only=true
+type DeleteCaseRequest struct {
+	ID           string `json:"-"`
+	ValidateOnly bool   `json:"validate_only,omitempty"` // List the files without removing them
+}
+
+// DeleteCaseResponse for DELETE /api/cases/{id}
+type DeleteCaseResponse struct {
+	Files []string `json:"files"` // Dataset files removed, or that would be removed
+}
+
+// Stream for GET /api/cases/{id}/streams/{provider}
+type Stream struct {
+	Provider string        `json:"provider"`
+	Events   []StreamEvent `json:"events"`
+}
+
+// StreamEvent is the transcript state after one stream update.
+type StreamEvent struct {
+	OffsetMS  int64  `json:"offset_ms"`         // Since the session started, monotonic
+	Final     bool   `json:"final,omitempty"`   // The update finalized a segment
+	Committed string `json:"committed"`         // All finalized text so far
+	Partial   string `json:"partial,omitempty"` // Pending text after Committed
+}
+
+// DiffRequest for GET /api/cases/{id}/diff?provider=&against=
+type DiffRequest struct {
+	ID       string `json:"-"`
+	Provider string `json:"provider"`
+	Against  string `json:"against,omitempty"` // gt (default), audio_reality or revised
+}
+
+// DiffResponse for GET /api/cases/{id}/diff
+type DiffResponse struct {
+	Provider string `json:"provider"`
+	Against  string `json:"against"`
+	*textdiff.Alignment
+}
+
+// ReportVersion for GET /api/cases/{id}/reports
+type ReportVersion struct {
+	Version     int            `json:"version"` // 0 is the current report
+	UpdateTime  time.Time      `json:"update_time"`
+	ContextHash string         `json:"context_hash,omitempty"`
+	Models      []string       `json:"models,omitempty"` // Judge models recorded in the results
+	QScores     map[string]int `json:"q_scores"`         // By provider
+}
+
+// RollbackReportRequest for POST /api/cases/{id}:rollbackReport
+type RollbackReportRequest struct {
+	ID      string `json:"-"`
+	Version int    `json:"version"`
+}
+
+// ResetResultsRequest for POST /api/cases/{id}:resetResults
+type ResetResultsRequest struct {
+	ID          string   `json:"-"`
+	ProviderIDs []string `json:"provider_ids"`
+}
+
+// UpdateReviewRequest for POST /api/cases/{id}:updateReview
+type UpdateReviewRequest struct {
+	ID       string      `json:"-"`
+	State    ReviewState `json:"state"`
+	Assignee string      `json:"assignee,omitempty"` // Keeps the current assignee when empty
+	Note     string      `json:"note,omitempty"`
+}
+
+// UpdateChannelsRequest for POST /api/cases/{id}:updateChannels
+type UpdateChannelsRequest struct {
+	ID string `json:"-"`
+	audio.ChannelOptions
+}
+
+// CheckpointClipRequest for GET /api/cases/{id}/checkpoints/{checkpoint}/clip
+type CheckpointClipRequest struct {
+	ID         string
+	Checkpoint string // Checkpoint ID, e.g. S1
+	DurationMS int64  // Audio from the checkpoint's start; 0 means its aligned length, or else 2000
+	PadMS      int64  // Audio added before and after; 0 means 500
+}
+
+// UpdateCorrectionRequest for POST /api/cases/{id}/transcripts/{provider}
+type UpdateCorrectionRequest struct {
+	ID       string `json:"-"`
+	Provider string `json:"-"`
+	Text     string `json:"text"` // Empty removes the correction
+}
+
+// OverrideCheckpointRequest for POST /api/cases/{id}:overrideCheckpoint
+type OverrideCheckpointRequest struct {
+	ID           string                  `json:"-"`
+	Provider     string                  `json:"provider"`
+	CheckpointID string                  `json:"checkpoint_id"`
+	Status       evalv2.CheckpointStatus `json:"status"`
+	Reason       string                  `json:"reason"` // Required
+}
+
+// CreateCommentRequest for POST /api/cases/{id}/comments
+type CreateCommentRequest struct {
+	ID           string `json:"-"`
+	Author       string `json:"author,omitempty"` // Ignored when authentication is enabled
+	Body         string `json:"body"`
+	Provider     string `json:"provider,omitempty"`
+	CheckpointID string `json:"checkpoint_id,omitempty"`
+}
+
+// TranscribeRequest for POST /api/cases/{id}:transcribe
+type TranscribeRequest struct {
+	ID       string `json:"-"`
+	Provider string `json:"provider"`
+	Context  string `json:"context,omitempty"` // Biasing context for providers that take one; empty draws it from the case's eval context
+}
+
+// ContextVersion for GET /api/cases/{id}/contexts
+type ContextVersion struct {
+	Version     int             `json:"version"` // 0 is the current context
+	UpdateTime  time.Time       `json:"update_time"`
+	Hash        string          `json:"hash,omitempty"`
+	Checkpoints int             `json:"checkpoints"`
+	Changes     *ContextChanges `json:"changes,omitempty"` // Relative to the previous version
+}
+
+// ContextChanges lists what differs between two context versions.
+type ContextChanges struct {
+	AddedCheckpoints   []string `json:"added_checkpoints,omitempty"`
+	RemovedCheckpoints []string `json:"removed_checkpoints,omitempty"`
+	ChangedCheckpoints []string `json:"changed_checkpoints,omitempty"`
+	ChangedMeta        []string `json:"changed_meta,omitempty"` // JSON field names
+}
+
+// RestoreContextRequest for POST /api/cases/{id}:restoreContext
+type RestoreContextRequest struct {
+	ID      string `json:"-"`
+	Version int    `json:"version"`
+}
+
+// ListAuditRequest for GET /api/audit?case_id=&user=&action=&limit=
+type ListAuditRequest struct {
+	CaseID string `json:"case_id,omitempty"`
+	User   string `json:"user,omitempty"`
+	Action string `json:"action,omitempty"`
+	Limit  int    `json:"limit,omitempty"` // 0 returns all matching entries
+}
+
+// UpdateProvidersRequest for PUT /api/config/providers
+// Replaces the enabled set; providers not listed are disabled.
+type UpdateProvidersRequest struct {
+	EnabledProviders map[string]bool `json:"enabled_providers"`
+}
+
 // Config returns the server configuration.
 type Config struct {
-	GenModel         string          `json:"gen_model"`
-	EvalModel        string          `json:"eval_model"`
-	EnabledProviders map[string]bool `json:"enabled_providers"`
+	GenModel         string                     `json:"gen_model"`
+	EvalModel        string                     `json:"eval_model"`
+	EnabledProviders map[string]bool            `json:"enabled_providers"`
+	SScoreWeight     float64                    `json:"s_score_weight"`
+	Pricing          map[string]ProviderPricing `json:"pricing,omitempty"`
+	Tags             []string                   `json:"tags,omitempty"`          // Allowed case tags
+	ASRProviders     []string                   `json:"asr_providers,omitempty"` // Providers :transcribe can run
+	Dataset          string                     `json:"dataset,omitempty"`       // Dataset the config was read from
+	Datasets         []string                   `json:"datasets,omitempty"`      // Mounted datasets; the first is the default
+	Role             Role                       `json:"role,omitempty"`          // Caller's role; empty without authentication
 }
 
 // UpdateContextRequest for POST /api/cases/{id}:updateContext
 // Replaces generic UpdateCase.
 type UpdateContextRequest struct {
-	ID          string              `json:"-"`
+	ID          string              `json:"id,omitempty"` // Taken from the URL over HTTP
 	EvalContext *evalv2.EvalContext `json:"eval_context"`
 }
 
+// UpdateTagsRequest for POST /api/cases/{id}:updateTags
+type UpdateTagsRequest struct {
+	ID     string   `json:"-"`
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
 // GenerateContextRequest for POST /api/cases/{id}:generateContext
 // Custom method.
 type GenerateContextRequest struct {
-	ID          string `json:"-"` // Extracted from URL
+	ID          string `json:"id,omitempty"` // Taken from the URL over HTTP
 	GroundTruth string `json:"ground_truth"`
 }
 
+// AlignContextRequest for POST /api/cases/{id}:alignContext
+type AlignContextRequest struct {
+	ID string `json:"id,omitempty"` // Taken from the URL over HTTP
+}
+
 // EvaluateRequest for POST /api/cases/{id}:evaluate
 // Custom method.
 type EvaluateRequest struct {
-	ID          string              `json:"-"` // Extracted from URL
+	ID          string              `json:"id,omitempty"` // Taken from the URL over HTTP
 	EvalContext *evalv2.EvalContext `json:"eval_context"`
 	ProviderIDs []string            `json:"provider_ids"`
+	JudgeModels []string            `json:"judge_models,omitempty"` // Overrides the server's judge set
+
+	// UseCorrections scores corrected transcripts in place of the raw
+	// provider output. The report is returned but not saved.
+	UseCorrections bool `json:"use_corrections,omitempty"`
+}
+
+// RunPipelineRequest for POST /api/cases/{id}:runPipeline
+// Custom method.
+type RunPipelineRequest struct {
+	ID          string   `json:"id,omitempty"`           // Taken from the URL over HTTP
+	GroundTruth string   `json:"ground_truth,omitempty"` // Defaults to the current context's
+	Force       bool     `json:"force,omitempty"`        // Regenerate the context even if there is one
+	ProviderIDs []string `json:"provider_ids,omitempty"` // Defaults to the enabled providers
+	JudgeModels []string `json:"judge_models,omitempty"`
+}
+
+// EvaluateAllRequest for POST /api/cases:evaluateAll
+type EvaluateAllRequest struct {
+	CaseFilter
+	OnlyStale       bool     `json:"only_stale,omitempty"`       // Report context hash differs from the current context
+	OnlyUnevaluated bool     `json:"only_unevaluated,omitempty"` // Some selected provider has no result yet
+	ProviderIDs     []string `json:"provider_ids,omitempty"`     // Defaults to the enabled providers
+	JudgeModels     []string `json:"judge_models,omitempty"`
+
+	// Recheck selects the cases where this provider scored below
+	// RecheckBelow or failed a Tier-1 checkpoint, and re-evaluates that
+	// provider alone, to double-check the judge on the results that
+	// matter most.
+	Recheck      string `json:"recheck,omitempty"`
+	RecheckBelow int    `json:"recheck_below,omitempty"` // Q score; 0 uses DefaultRecheckBelow
+}
+
+// CreateRunRequest for POST /api/runs
+type CreateRunRequest struct {
+	CaseFilter
+	ID          string   `json:"id"` // Lower-case letters, digits, '.', '_' and '-'
+	Description string   `json:"description,omitempty"`
+	ProviderIDs []string `json:"provider_ids,omitempty"` // Defaults to the enabled providers
+	JudgeModels []string `json:"judge_models,omitempty"`
+}
+
+// CreateRunResponse for POST /api/runs
+type CreateRunResponse struct {
+	Run  *Run   `json:"run"`
+	Jobs []*Job `json:"jobs"` // Their batch ID is the run ID
+}
+
+// CompareRunsRequest for GET /api/runs:compare
+type CompareRunsRequest struct {
+	A         string `json:"a"`
+	B         string `json:"b"`
+	Threshold int    `json:"threshold,omitempty"` // Q change of a case to report; defaults to DefaultCompareThreshold
+}
+
+// CompareRunsResponse for GET /api/runs:compare
+type CompareRunsResponse struct {
+	A           string                 `json:"a"`
+	B           string                 `json:"b"`
+	Cases       int                    `json:"cases"` // Evaluated by both runs
+	Threshold   int                    `json:"threshold"`
+	Providers   []RunComparison        `json:"providers"`
+	Moved       []CaseScoreDelta       `json:"moved"`        // Q changed by more than the threshold, largest drop first
+	NewFailures []CheckpointRegression `json:"new_failures"` // Tier-1 checkpoints failing in B only
+}
+
+// RunComparison is one provider's standing in both runs. A or B is nil
+// when the provider was not in that run.
+type RunComparison struct {
+	Provider string         `json:"provider"`
+	A        *ProviderStats `json:"a,omitempty"`
+	B        *ProviderStats `json:"b,omitempty"`
+	DeltaQ   float64        `json:"delta_q"` // Weighted Q in B minus A
+}
+
+// CaseScoreDelta is a provider's Q on a case in both runs.
+type CaseScoreDelta struct {
+	CaseID   string `json:"case_id"`
+	Provider string `json:"provider"`
+	A        int    `json:"a"`
+	B        int    `json:"b"`
+	Delta    int    `json:"delta"` // B minus A
+}
+
+// CheckpointRegression is a Tier-1 checkpoint a provider failed in run B
+// but not in A.
+type CheckpointRegression struct {
+	CaseID       string                  `json:"case_id"`
+	Provider     string                  `json:"provider"`
+	CheckpointID string                  `json:"checkpoint_id"`
+	Text         string                  `json:"text"`
+	Was          evalv2.CheckpointStatus `json:"was"` // Status in A: Pass or Partial
+}
+
+// HeadToHeadRequest for GET /api/stats/head-to-head
+type HeadToHeadRequest struct {
+	CaseFilter
+	A   string `json:"a"`
+	B   string `json:"b"`
+	Top int    `json:"top,omitempty"` // Largest gaps to list; defaults to 10
+}
+
+// HeadToHeadResponse for GET /api/stats/head-to-head. Deltas are Q(A) - Q(B)
+// over the cases with a result for both providers.
+type HeadToHeadResponse struct {
+	A         string      `json:"a"`
+	B         string      `json:"b"`
+	WinsA     int         `json:"wins_a"`
+	WinsB     int         `json:"wins_b"`
+	Ties      int         `json:"ties"`
+	MeanDelta float64     `json:"mean_delta"`
+	Cases     []CaseDelta `json:"cases"`    // By case ID
+	TopGaps   []CaseDelta `json:"top_gaps"` // Largest |delta| first
+}
+
+// CaseDelta compares the two providers' Q scores on one case.
+type CaseDelta struct {
+	ID    string `json:"id"`
+	A     int    `json:"a"`
+	B     int    `json:"b"`
+	Delta int    `json:"delta"`
+}
+
+// Report freshness in CaseCoverage.
+const (
+	ReportNone  = "none"
+	ReportStale = "stale" // Scored against an older context
+	ReportFresh = "fresh"
+)
+
+// CoverageResponse for GET /api/stats/coverage
+type CoverageResponse struct {
+	Providers []string       `json:"providers"` // Enabled providers, which the coverage is about
+	Cases     []CaseCoverage `json:"cases"`
+	Totals    CoverageTotals `json:"totals"`
+}
+
+// CaseCoverage lists what has been done for one case.
+type CaseCoverage struct {
+	ID          string      `json:"id"`
+	Transcripts []string    `json:"transcripts"` // Providers with a transcript
+	HasContext  bool        `json:"has_context"`
+	Report      string      `json:"report"`                // none, stale or fresh
+	Unevaluated []string    `json:"unevaluated,omitempty"` // Transcripts without a result
+	ReviewState ReviewState `json:"review_state"`
+}
+
+// CoverageTotals counts CaseCoverage fields over all cases.
+type CoverageTotals struct {
+	Cases       int                 `json:"cases"`
+	Transcripts map[string]int      `json:"transcripts"` // By provider
+	WithContext int                 `json:"with_context"`
+	Reports     map[string]int      `json:"reports"`     // By freshness
+	Unevaluated int                 `json:"unevaluated"` // Cases with transcripts left to evaluate
+	Review      map[ReviewState]int `json:"review"`
+}
+
+// ExportBundleRequest for GET /api/export/bundle. Without IDs, the cases
+// matching the filter are exported.
+type ExportBundleRequest struct {
+	CaseFilter
+	IDs   []string
+	Audio bool
+}
+
+// ImportBundleRequest for POST /api/import/bundle
+type ImportBundleRequest struct {
+	Overwrite bool
+}
+
+// ImportBundleResponse for POST /api/import/bundle
+type ImportBundleResponse struct {
+	Cases    []string `json:"cases"`
+	Imported int      `json:"imported"` // Files written
+	Skipped  []string `json:"skipped"`  // Existing files left untouched
+}
+
+// ListStaleResponse for GET /api/cases:stale
+type ListStaleResponse struct {
+	Cases []StaleCase `json:"cases"`
+}
+
+// StaleCase is a case whose report predates its current eval context.
+type StaleCase struct {
+	ID          string `json:"id"`
+	ReportHash  string `json:"report_hash"`  // Context hash the report was produced against
+	ContextHash string `json:"context_hash"` // Hash of the current gt.v2.json
+}
+
+// EvaluateAllResponse for POST /api/cases:evaluateAll
+type EvaluateAllResponse struct {
+	BatchID string `json:"batch_id"` // Jobs are queued in the background; list them with GET /api/jobs?batch_id=
 }
@@ -0,0 +1,102 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/google/uuid"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/corpus"
+	"asr-eval/pkg/evalv2"
+)
+
+// maxUploadBytes bounds the size of a POST /api/cases request body.
+const maxUploadBytes = 512 << 20
+
+// providerIDPattern restricts provider IDs, which become file extensions.
+var providerIDPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// CreateCase writes the dataset files for a new case under a generated ID.
+// Audio in a format other than FLAC is converted to it with ffmpeg. A
+// ground truth without an eval context is stored as a context carrying
+// only the ground truth, ready for generateContext.
+func (s *Service) CreateCase(ctx context.Context, req CreateCaseRequest) (*Case, error) {
+	if req.Audio == nil {
+		return nil, fmt.Errorf("audio is required")
+	}
+	for p := range req.Transcripts {
+		if !providerIDPattern.MatchString(p) {
+			return nil, fmt.Errorf("invalid provider ID: %q", p)
+		}
+	}
+
+	id := uuid.NewString()
+	dir := s.Config.DatasetDir
+	upload := filepath.Join(dir, ".tmp-"+id+"-upload")
+	if err := writeFileFrom(upload, req.Audio); err != nil {
+		return nil, err
+	}
+	defer os.Remove(upload)
+	format, err := audio.DetectFile(upload)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "":
+		return nil, fmt.Errorf("audio must be FLAC, WAV, Ogg Vorbis or Opus, M4A, MP3 or AMR")
+	case audio.FormatFLAC:
+		err = os.Rename(upload, filepath.Join(dir, id+extFlac))
+	default:
+		err = s.writeFLAC(ctx, id, corpus.Utterance{Audio: upload}, 0, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Storage.PutCase(ctx, id); err != nil {
+		return nil, err
+	}
+	for p, t := range req.Transcripts {
+		if err := s.Storage.PutTranscript(ctx, id, p, t); err != nil {
+			return nil, err
+		}
+	}
+	if req.GroundTruth != "" {
+		evalCtx := &evalv2.EvalContext{Meta: evalv2.ContextMeta{GroundTruth: req.GroundTruth}}
+		if err := s.writeEvalContext(ctx, id, evalCtx); err != nil {
+			return nil, err
+		}
+	}
+
+	s.caseChanged(ctx, id)
+	s.audit(ctx, AuditCreateCase, id, nil)
+	return s.GetCase(ctx, id)
+}
+
+// writeFileFrom copies r into path, removing the partial file on failure.
+func writeFileFrom(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
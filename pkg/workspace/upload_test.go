@@ -0,0 +1,43 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateCase(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{DatasetDir: dir}, Storage: NewFSStorage(dir)}
+	ctx := context.Background()
+
+	c, err := s.CreateCase(ctx, CreateCaseRequest{
+		Audio:       strings.NewReader("fLaC\x00\x00\x00\x22"),
+		GroundTruth: "hello",
+		Transcripts: map[string]string{"dg": "hullo"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, c.ID+extFlac)); err != nil || string(got) != "fLaC\x00\x00\x00\x22" {
+		t.Errorf("audio = %q, %v; want the upload as is", got, err)
+	}
+	if c.Transcripts["dg"] != "hullo" || c.EvalContext == nil || c.EvalContext.Meta.GroundTruth != "hello" {
+		t.Errorf("CreateCase() = %+v, want the transcript and ground truth", c)
+	}
+
+	if _, err := s.CreateCase(ctx, CreateCaseRequest{Audio: strings.NewReader("not audio")}); err == nil {
+		t.Error("CreateCase() with text as audio succeeded")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".tmp-") {
+			t.Errorf("upload left behind: %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package workspace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"asr-eval/pkg/audio"
+	"asr-eval/pkg/evalv2"
+	"asr-eval/pkg/llmclient"
+)
+
+// usageFileName is the usage ledger of a dataset: a JSON line per LLM call
+// and transcription the service made, for cost reports. Like the audit
+// log, each copy of a dataset keeps its own.
+const usageFileName = ".usage.jsonl"
+
+// Usage kinds.
+const (
+	UsageLLM = "llm"
+	UsageASR = "asr"
+)
+
+// UsageEntry is one line of the usage ledger.
+type UsageEntry struct {
+	Time          time.Time `json:"time"`
+	Command       string    `json:"command"` // ServiceConfig.Command
+	Kind          string    `json:"kind"`    // UsageLLM or UsageASR
+	Model         string    `json:"model"`   // The LLM, or the ASR provider
+	CaseID        string    `json:"case_id,omitempty"`
+	Step          string    `json:"step,omitempty"` // StepGenerateContext, StepEvaluate or StepAlignContext
+	PromptTokens  int       `json:"prompt_tokens,omitempty"`
+	ThoughtTokens int       `json:"thought_tokens,omitempty"`
+	OutputTokens  int       `json:"output_tokens,omitempty"`
+	AudioMS       int64     `json:"audio_ms,omitempty"`
+}
+
+// CostLine is the usage and cost of a group of ledger entries.
+type CostLine struct {
+	Key          string
+	Calls        int
+	PromptTokens int
+	OutputTokens int // Thought tokens included
+	AudioMS      int64
+	Cost         float64
+	Unpriced     int // Calls of models or providers without pricing
+}
+
+// usageMu serializes this process's appends to usage ledgers.
+var usageMu sync.Mutex
+
+// recordUsage appends e to the usage ledger. Failures are logged rather
+// than returned: the call itself already happened.
+func (s *Service) recordUsage(e *UsageEntry) {
+	e.Time = time.Now().UTC()
+	e.Command = s.Config.Command
+	if e.Command == "" {
+		e.Command = filepath.Base(os.Args[0])
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(s.Config.DatasetDir, usageFileName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err == nil {
+		_, err = f.Write(append(b, '\n'))
+		f.Close()
+	}
+	if err != nil {
+		slog.Error("Failed to write usage ledger", "dataset", s.Config.DatasetDir, "error", err)
+	}
+}
+
+// withUsage returns a context that records the LLM calls made through it
+// for step of case id.
+func (s *Service) withUsage(ctx context.Context, id, step string) context.Context {
+	return evalv2.WithUsage(ctx, func(model string, u llmclient.Usage) {
+		s.recordUsage(&UsageEntry{
+			Kind:          UsageLLM,
+			Model:         model,
+			CaseID:        id,
+			Step:          step,
+			PromptTokens:  u.PromptTokens,
+			ThoughtTokens: u.ThoughtTokens,
+			OutputTokens:  u.OutputTokens,
+		})
+	})
+}
+
+// recordTranscription records a transcription of the audio at path by
+// provider for case id.
+func (s *Service) recordTranscription(id, provider, path string) {
+	e := &UsageEntry{Kind: UsageASR, Model: provider, CaseID: id}
+	if info, err := audio.ReadInfo(path); err == nil {
+		e.AudioMS = info.DurationMS
+	}
+	s.recordUsage(e)
+}
+
+// ReadUsage returns the usage ledger entries from since until until, in
+// the order they were recorded. A zero time leaves that end open.
+func (s *Service) ReadUsage(since, until time.Time) ([]*UsageEntry, error) {
+	f, err := os.Open(filepath.Join(s.Config.DatasetDir, usageFileName))
+	if os.IsNotExist(err) {
+		return []*UsageEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := []*UsageEntry{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var e UsageEntry
+		if json.Unmarshal(sc.Bytes(), &e) != nil {
+			continue
+		}
+		if (!since.IsZero() && e.Time.Before(since)) || (!until.IsZero() && !e.Time.Before(until)) {
+			continue
+		}
+		out = append(out, &e)
+	}
+	return out, sc.Err()
+}
+
+// Cost returns what e cost under the configured pricing, or false when
+// its model or provider has none.
+func (s *Service) Cost(e *UsageEntry) (float64, bool) {
+	switch e.Kind {
+	case UsageLLM:
+		p, ok := s.Config.ModelPricing[e.Model]
+		if !ok {
+			return 0, false
+		}
+		return (float64(e.PromptTokens)*p.InputPerMTok + float64(e.ThoughtTokens+e.OutputTokens)*p.OutputPerMTok) / 1e6, true
+	case UsageASR:
+		p, ok := s.Config.Pricing[e.Model]
+		if !ok {
+			return 0, false
+		}
+		return float64(e.AudioMS) / float64(time.Hour/time.Millisecond) * p.PerHour, true
+	}
+	return 0, false
+}
+
+// CostReport groups entries by key and sums their usage and cost, most
+// expensive first.
+func (s *Service) CostReport(entries []*UsageEntry, key func(*UsageEntry) string) []CostLine {
+	byKey := make(map[string]*CostLine)
+	for _, e := range entries {
+		k := key(e)
+		l, ok := byKey[k]
+		if !ok {
+			l = &CostLine{Key: k}
+			byKey[k] = l
+		}
+		l.Calls++
+		l.PromptTokens += e.PromptTokens
+		l.OutputTokens += e.ThoughtTokens + e.OutputTokens
+		l.AudioMS += e.AudioMS
+		if c, ok := s.Cost(e); ok {
+			l.Cost += c
+		} else {
+			l.Unpriced++
+		}
+	}
+	lines := make([]CostLine, 0, len(byKey))
+	for _, l := range byKey {
+		lines = append(lines, *l)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Cost != lines[j].Cost {
+			return lines[i].Cost > lines[j].Cost
+		}
+		return lines[i].Key < lines[j].Key
+	})
+	return lines
+}
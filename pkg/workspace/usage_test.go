@@ -0,0 +1,48 @@
+package workspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCostReport(t *testing.T) {
+	dir := t.TempDir()
+	s := &Service{Config: ServiceConfig{
+		DatasetDir:   dir,
+		Command:      "batch_eval",
+		Pricing:      map[string]ProviderPricing{"volc": {PerHour: 2}},
+		ModelPricing: map[string]ModelPricing{"flash": {InputPerMTok: 1, OutputPerMTok: 4}},
+	}}
+	start := time.Now().UTC()
+	s.recordUsage(&UsageEntry{Kind: UsageLLM, Model: "flash", CaseID: "a", PromptTokens: 1_000_000, ThoughtTokens: 100_000, OutputTokens: 150_000})
+	s.recordUsage(&UsageEntry{Kind: UsageLLM, Model: "flash", CaseID: "b", PromptTokens: 500_000})
+	s.recordUsage(&UsageEntry{Kind: UsageLLM, Model: "pro", CaseID: "a", PromptTokens: 10})
+	s.recordUsage(&UsageEntry{Kind: UsageASR, Model: "volc", CaseID: "a", AudioMS: 30 * 60 * 1000})
+
+	entries, err := s.ReadUsage(start, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || entries[0].Command != "batch_eval" {
+		t.Fatalf("got %d entries, first %+v; want 4 from batch_eval", len(entries), entries[0])
+	}
+	if later, _ := s.ReadUsage(time.Now().Add(time.Hour), time.Time{}); len(later) != 0 {
+		t.Errorf("entries after an hour from now = %d, want 0", len(later))
+	}
+
+	byModel := s.CostReport(entries, func(e *UsageEntry) string { return e.Model })
+	want := []CostLine{
+		{Key: "flash", Calls: 2, PromptTokens: 1_500_000, OutputTokens: 250_000, Cost: 2.5},
+		{Key: "volc", Calls: 1, AudioMS: 30 * 60 * 1000, Cost: 1},
+		{Key: "pro", Calls: 1, PromptTokens: 10, Unpriced: 1},
+	}
+	if diff := cmp.Diff(want, byModel); diff != "" {
+		t.Errorf("by model mismatch (-want +got):\n%s", diff)
+	}
+	byCase := s.CostReport(entries, func(e *UsageEntry) string { return e.CaseID })
+	if byCase[0].Key != "a" || byCase[0].Cost != 3 {
+		t.Errorf("most expensive case = %+v, want a at 3", byCase[0])
+	}
+}
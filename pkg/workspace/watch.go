@@ -0,0 +1,68 @@
+package workspace
+
+import (
+	"context"
+	"time"
+)
+
+// watchSettle is how long an audio file must go unchanged before
+// WatchTranscriptions picks it up, so files still being copied in are not
+// transcribed half written.
+const watchSettle = 2 * time.Second
+
+// WatchTranscriptions turns the dataset into a hot folder: it runs the
+// tasks of req, as TranscribeAll does, then again whenever audio files are
+// added, until ctx is done. A task is run once; one that failed is left to
+// a retry. It waits for events of the dataset watch, or polls every
+// interval when the dataset is not watched.
+func (s *Service) WatchTranscriptions(ctx context.Context, req TranscriptionTasksRequest, limits map[string]ProviderLimits, def ProviderLimits, interval time.Duration, started func(TranscriptionTask), done func(TranscriptionTask, error)) error {
+	events, cancel := s.Subscribe()
+	defer cancel()
+	var poll <-chan time.Time
+	if !s.Watched() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		poll = t.C
+	}
+
+	attempted := make(map[TranscriptionTask]bool)
+	var settle *time.Timer
+	var settled <-chan time.Time
+	for {
+		tasks, err := s.TranscriptionTasks(ctx, req)
+		if err != nil {
+			return err
+		}
+		var todo []TranscriptionTask
+		for _, t := range tasks {
+			if !attempted[t] {
+				attempted[t] = true
+				todo = append(todo, t)
+			}
+		}
+		s.TranscribeAll(ctx, todo, limits, def, started, done)
+
+		for wait := true; wait; {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return nil // The service closed
+				}
+				if e.Type == EventReset || (e.Type == EventCase && e.File == eventFiles[extFlac]) {
+					if settle == nil {
+						settle = time.NewTimer(watchSettle)
+						settled = settle.C
+					} else {
+						settle.Reset(watchSettle)
+					}
+				}
+			case <-settled:
+				wait = false
+			case <-poll:
+				wait = false
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"asr-eval/pkg/evalv2"
+)
+
+// Webhook payload formats.
+const (
+	WebhookSlack  = "slack"  // {"text": ...}, also accepted by most chat incoming webhooks
+	WebhookFeishu = "feishu" // Feishu/Lark custom bot text message
+)
+
+const webhookTimeout = 10 * time.Second
+
+// RunSummary reports a finished evaluation or batch run.
+type RunSummary struct {
+	Dataset string
+	Kind    string // Job kind, or "batch"
+	Cases   []CaseOutcome
+}
+
+// CaseOutcome is how one case fared in a run.
+type CaseOutcome struct {
+	CaseID string
+	Scores map[string]int // Q score by provider
+	Error  string
+}
+
+// Text renders s as a chat message: a headline, per-provider mean Q and
+// the failed cases.
+func (s *RunSummary) Text() string {
+	var b strings.Builder
+	failed := 0
+	sums := make(map[string]int)
+	counts := make(map[string]int)
+	for _, c := range s.Cases {
+		if c.Error != "" {
+			failed++
+		}
+		for p, q := range c.Scores {
+			sums[p] += q
+			counts[p]++
+		}
+	}
+
+	if len(s.Cases) == 1 {
+		c := s.Cases[0]
+		fmt.Fprintf(&b, "[%s] %s of %s ", s.Dataset, s.Kind, c.CaseID)
+		if c.Error != "" {
+			fmt.Fprintf(&b, "failed: %s", c.Error)
+			return b.String()
+		}
+		b.WriteString("finished")
+	} else {
+		fmt.Fprintf(&b, "[%s] %s of %d cases finished, %d failed", s.Dataset, s.Kind, len(s.Cases), failed)
+	}
+
+	providers := make([]string, 0, len(sums))
+	for p := range sums {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	for _, p := range providers {
+		fmt.Fprintf(&b, "\n%s: Q %.1f", p, float64(sums[p])/float64(counts[p]))
+		if counts[p] > 1 {
+			fmt.Fprintf(&b, " over %d cases", counts[p])
+		}
+	}
+	if len(s.Cases) > 1 && failed > 0 {
+		b.WriteString("\nFailed:")
+		for _, c := range s.Cases {
+			if c.Error != "" {
+				fmt.Fprintf(&b, "\n%s: %s", c.CaseID, c.Error)
+			}
+		}
+	}
+	return b.String()
+}
+
+// PostWebhook sends s to url in format, WebhookSlack when empty.
+func PostWebhook(ctx context.Context, url, format string, s *RunSummary) error {
+	var payload any
+	switch format {
+	case "", WebhookSlack:
+		payload = map[string]string{"text": s.Text()}
+	case WebhookFeishu:
+		payload = map[string]any{
+			"msg_type": "text",
+			"content":  map[string]string{"text": s.Text()},
+		}
+	default:
+		return fmt.Errorf("unknown webhook format: %q", format)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// batchRun collects the outcomes of a batch's jobs until the last one
+// finishes.
+type batchRun struct {
+	pending int
+	sealed  bool // All jobs are submitted
+	cases   []CaseOutcome
+}
+
+// notifyJob reports a finished evaluation or pipeline job to the webhook,
+// on its own or, for batch jobs, with the rest of its batch.
+func (s *Service) notifyJob(job *Job) {
+	if s.Config.WebhookURL == "" || !job.Done() || (job.Kind != JobEvaluate && job.Kind != JobRunPipeline) {
+		return
+	}
+	outcome := jobOutcome(job)
+	if job.BatchID == "" {
+		s.postWebhook(&RunSummary{Dataset: s.datasetName(), Kind: string(job.Kind), Cases: []CaseOutcome{outcome}})
+		return
+	}
+
+	s.batchesMu.Lock()
+	b, ok := s.batches[job.BatchID]
+	if !ok {
+		s.batchesMu.Unlock()
+		return
+	}
+	b.cases = append(b.cases, outcome)
+	b.pending--
+	s.batchesMu.Unlock()
+	s.finishBatch(job.BatchID)
+}
+
+// beginBatch starts collecting outcomes for batchID. Call addToBatch
+// before submitting each of its jobs and sealBatch once all are submitted.
+func (s *Service) beginBatch(batchID string) {
+	if s.Config.WebhookURL == "" {
+		return
+	}
+	s.batchesMu.Lock()
+	defer s.batchesMu.Unlock()
+	if s.batches == nil {
+		s.batches = make(map[string]*batchRun)
+	}
+	s.batches[batchID] = &batchRun{}
+}
+
+func (s *Service) addToBatch(batchID string) {
+	s.batchesMu.Lock()
+	defer s.batchesMu.Unlock()
+	if b, ok := s.batches[batchID]; ok {
+		b.pending++
+	}
+}
+
+func (s *Service) sealBatch(batchID string) {
+	s.batchesMu.Lock()
+	if b, ok := s.batches[batchID]; ok {
+		b.sealed = true
+	}
+	s.batchesMu.Unlock()
+	s.finishBatch(batchID)
+}
+
+// finishBatch posts the batch's summary once it is sealed and its last job
+// is done.
+func (s *Service) finishBatch(batchID string) {
+	s.batchesMu.Lock()
+	b, ok := s.batches[batchID]
+	if !ok || !b.sealed || b.pending > 0 {
+		s.batchesMu.Unlock()
+		return
+	}
+	delete(s.batches, batchID)
+	s.batchesMu.Unlock()
+
+	if len(b.cases) == 0 {
+		return
+	}
+	sort.Slice(b.cases, func(i, j int) bool { return b.cases[i].CaseID < b.cases[j].CaseID })
+	s.postWebhook(&RunSummary{Dataset: s.datasetName(), Kind: "batch", Cases: b.cases})
+}
+
+// postWebhook sends summary in the background, so slow webhooks do not
+// hold up the job workers.
+func (s *Service) postWebhook(summary *RunSummary) {
+	url, format := s.Config.WebhookURL, s.Config.WebhookFormat
+	go func() {
+		if err := PostWebhook(context.Background(), url, format, summary); err != nil {
+			slog.Error("Failed to post webhook", "dataset", summary.Dataset, "error", err)
+		}
+	}()
+}
+
+// jobOutcome reads the scores from a finished job's report.
+func jobOutcome(job *Job) CaseOutcome {
+	out := CaseOutcome{CaseID: job.CaseID, Error: job.Error}
+	var report evalv2.EvalReport
+	if job.Status == JobSucceeded && json.Unmarshal(job.Result, &report) == nil {
+		out.Scores = make(map[string]int, len(report.Results))
+		for p, r := range report.Results {
+			out.Scores[p] = r.Metrics.QScore
+		}
+	}
+	return out
+}
+
+// datasetName names the dataset in messages meant for people.
+func (s *Service) datasetName() string {
+	return filepath.Base(filepath.Clean(s.Config.DatasetDir))
+}
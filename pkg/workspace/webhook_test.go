@@ -0,0 +1,64 @@
+package workspace
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunSummaryText(t *testing.T) {
+	s := &RunSummary{Dataset: "zh", Kind: "batch", Cases: []CaseOutcome{
+		{CaseID: "a", Scores: map[string]int{"x": 80, "y": 60}},
+		{CaseID: "b", Scores: map[string]int{"x": 90}},
+		{CaseID: "c", Error: "quota exceeded"},
+	}}
+	want := "[zh] batch of 3 cases finished, 1 failed\nx: Q 85.0 over 2 cases\ny: Q 60.0\nFailed:\nc: quota exceeded"
+	if got := s.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+
+	s = &RunSummary{Dataset: "zh", Kind: "evaluate", Cases: []CaseOutcome{{CaseID: "a", Error: "boom"}}}
+	if got, want := s.Text(), "[zh] evaluate of a failed: boom"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+}
+
+func TestBatchWebhook(t *testing.T) {
+	posts := make(chan string, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			MsgType string `json:"msg_type"`
+			Content struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		posts <- body.MsgType + ": " + body.Content.Text
+	}))
+	defer srv.Close()
+
+	s := &Service{Config: ServiceConfig{DatasetDir: "/data/zh", WebhookURL: srv.URL, WebhookFormat: WebhookFeishu}}
+	s.beginBatch("b1")
+	s.addToBatch("b1")
+	s.addToBatch("b1")
+	s.notifyJob(&Job{Kind: JobEvaluate, CaseID: "a", BatchID: "b1", Status: JobFailed, Error: "boom"})
+	s.sealBatch("b1")
+	select {
+	case got := <-posts:
+		t.Fatalf("posted %q before the batch finished", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	s.notifyJob(&Job{Kind: JobEvaluate, CaseID: "b", BatchID: "b1", Status: JobSucceeded, Result: json.RawMessage(`{"evaluations":{"x":{"metrics":{"q_score":70}}}}`)})
+	select {
+	case got := <-posts:
+		want := "text: [zh] batch of 2 cases finished, 1 failed\nx: Q 70.0\nFailed:\na: boom"
+		if got != want {
+			t.Errorf("posted %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no post for the finished batch")
+	}
+}
@@ -0,0 +1,2606 @@
+// The workspace service: the gRPC counterpart of the JSON API under /api.
+//
+// Messages mirror the JSON API's request and response bodies, and field
+// names match their JSON keys, so a case reads the same whichever way it is
+// fetched. A request picks the dataset of a multi-dataset server with the
+// "dataset" metadata key, and authenticates with "authorization: Bearer
+// <key>" metadata when the server requires keys.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: asreval/workspace/v1/workspace.proto
+
+package workspacepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListCasesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	HasEval        *bool                  `protobuf:"varint,1,opt,name=has_eval,json=hasEval,proto3,oneof" json:"has_eval,omitempty"`
+	QuestionableGt *bool                  `protobuf:"varint,2,opt,name=questionable_gt,json=questionableGt,proto3,oneof" json:"questionable_gt,omitempty"`
+	Provider       string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`                        // Case must have a result for this provider
+	MinScore       *int32                 `protobuf:"varint,4,opt,name=min_score,json=minScore,proto3,oneof" json:"min_score,omitempty"` // Q score bounds, applied to provider or the best provider
+	MaxScore       *int32                 `protobuf:"varint,5,opt,name=max_score,json=maxScore,proto3,oneof" json:"max_score,omitempty"`
+	Tags           []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"` // Case must have all of these tags
+	ReviewState    string                 `protobuf:"bytes,7,opt,name=review_state,json=reviewState,proto3" json:"review_state,omitempty"`
+	Assignee       string                 `protobuf:"bytes,8,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Run            string                 `protobuf:"bytes,9,opt,name=run,proto3" json:"run,omitempty"`                             // Use this run's reports instead of the current ones
+	Sort           string                 `protobuf:"bytes,10,opt,name=sort,proto3" json:"sort,omitempty"`                          // id, q_score or token_count; "-" prefix for descending
+	Page           int32                  `protobuf:"varint,11,opt,name=page,proto3" json:"page,omitempty"`                         // 1-based
+	PageSize       int32                  `protobuf:"varint,12,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // 0 returns all matching cases
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListCasesRequest) Reset() {
+	*x = ListCasesRequest{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCasesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCasesRequest) ProtoMessage() {}
+
+func (x *ListCasesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCasesRequest.ProtoReflect.Descriptor instead.
+func (*ListCasesRequest) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListCasesRequest) GetHasEval() bool {
+	if x != nil && x.HasEval != nil {
+		return *x.HasEval
+	}
+	return false
+}
+
+func (x *ListCasesRequest) GetQuestionableGt() bool {
+	if x != nil && x.QuestionableGt != nil {
+		return *x.QuestionableGt
+	}
+	return false
+}
+
+func (x *ListCasesRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ListCasesRequest) GetMinScore() int32 {
+	if x != nil && x.MinScore != nil {
+		return *x.MinScore
+	}
+	return 0
+}
+
+func (x *ListCasesRequest) GetMaxScore() int32 {
+	if x != nil && x.MaxScore != nil {
+		return *x.MaxScore
+	}
+	return 0
+}
+
+func (x *ListCasesRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListCasesRequest) GetReviewState() string {
+	if x != nil {
+		return x.ReviewState
+	}
+	return ""
+}
+
+func (x *ListCasesRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *ListCasesRequest) GetRun() string {
+	if x != nil {
+		return x.Run
+	}
+	return ""
+}
+
+func (x *ListCasesRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListCasesRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListCasesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListCasesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cases         []*Case                `protobuf:"bytes,1,rep,name=cases,proto3" json:"cases,omitempty"`
+	TotalSize     int32                  `protobuf:"varint,2,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"` // Number of matching cases across all pages
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCasesResponse) Reset() {
+	*x = ListCasesResponse{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCasesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCasesResponse) ProtoMessage() {}
+
+func (x *ListCasesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCasesResponse.ProtoReflect.Descriptor instead.
+func (*ListCasesResponse) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListCasesResponse) GetCases() []*Case {
+	if x != nil {
+		return x.Cases
+	}
+	return nil
+}
+
+func (x *ListCasesResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+type GetCaseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCaseRequest) Reset() {
+	*x = GetCaseRequest{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCaseRequest) ProtoMessage() {}
+
+func (x *GetCaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCaseRequest.ProtoReflect.Descriptor instead.
+func (*GetCaseRequest) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetCaseRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type EvaluateRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	EvalContext *EvalContext           `protobuf:"bytes,2,opt,name=eval_context,json=evalContext,proto3" json:"eval_context,omitempty"`
+	ProviderIds []string               `protobuf:"bytes,3,rep,name=provider_ids,json=providerIds,proto3" json:"provider_ids,omitempty"` // Empty evaluates every provider
+	JudgeModels []string               `protobuf:"bytes,4,rep,name=judge_models,json=judgeModels,proto3" json:"judge_models,omitempty"` // Overrides the server's judge set
+	// Scores corrected transcripts in place of the raw provider output. The
+	// report is returned but not saved.
+	UseCorrections bool `protobuf:"varint,5,opt,name=use_corrections,json=useCorrections,proto3" json:"use_corrections,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EvaluateRequest) Reset() {
+	*x = EvaluateRequest{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateRequest) ProtoMessage() {}
+
+func (x *EvaluateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateRequest.ProtoReflect.Descriptor instead.
+func (*EvaluateRequest) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EvaluateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EvaluateRequest) GetEvalContext() *EvalContext {
+	if x != nil {
+		return x.EvalContext
+	}
+	return nil
+}
+
+func (x *EvaluateRequest) GetProviderIds() []string {
+	if x != nil {
+		return x.ProviderIds
+	}
+	return nil
+}
+
+func (x *EvaluateRequest) GetJudgeModels() []string {
+	if x != nil {
+		return x.JudgeModels
+	}
+	return nil
+}
+
+func (x *EvaluateRequest) GetUseCorrections() bool {
+	if x != nil {
+		return x.UseCorrections
+	}
+	return false
+}
+
+type GenerateContextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroundTruth   string                 `protobuf:"bytes,2,opt,name=ground_truth,json=groundTruth,proto3" json:"ground_truth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateContextRequest) Reset() {
+	*x = GenerateContextRequest{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateContextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateContextRequest) ProtoMessage() {}
+
+func (x *GenerateContextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateContextRequest.ProtoReflect.Descriptor instead.
+func (*GenerateContextRequest) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenerateContextRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GenerateContextRequest) GetGroundTruth() string {
+	if x != nil {
+		return x.GroundTruth
+	}
+	return ""
+}
+
+type UpdateContextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	EvalContext   *EvalContext           `protobuf:"bytes,2,opt,name=eval_context,json=evalContext,proto3" json:"eval_context,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateContextRequest) Reset() {
+	*x = UpdateContextRequest{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateContextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateContextRequest) ProtoMessage() {}
+
+func (x *UpdateContextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateContextRequest.ProtoReflect.Descriptor instead.
+func (*UpdateContextRequest) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateContextRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateContextRequest) GetEvalContext() *EvalContext {
+	if x != nil {
+		return x.EvalContext
+	}
+	return nil
+}
+
+type LeaderboardRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	HasEval        *bool                  `protobuf:"varint,1,opt,name=has_eval,json=hasEval,proto3,oneof" json:"has_eval,omitempty"`
+	QuestionableGt *bool                  `protobuf:"varint,2,opt,name=questionable_gt,json=questionableGt,proto3,oneof" json:"questionable_gt,omitempty"`
+	Provider       string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	MinScore       *int32                 `protobuf:"varint,4,opt,name=min_score,json=minScore,proto3,oneof" json:"min_score,omitempty"`
+	MaxScore       *int32                 `protobuf:"varint,5,opt,name=max_score,json=maxScore,proto3,oneof" json:"max_score,omitempty"`
+	Tags           []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	ReviewState    string                 `protobuf:"bytes,7,opt,name=review_state,json=reviewState,proto3" json:"review_state,omitempty"`
+	Assignee       string                 `protobuf:"bytes,8,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Run            string                 `protobuf:"bytes,9,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LeaderboardRequest) Reset() {
+	*x = LeaderboardRequest{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaderboardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaderboardRequest) ProtoMessage() {}
+
+func (x *LeaderboardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaderboardRequest.ProtoReflect.Descriptor instead.
+func (*LeaderboardRequest) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LeaderboardRequest) GetHasEval() bool {
+	if x != nil && x.HasEval != nil {
+		return *x.HasEval
+	}
+	return false
+}
+
+func (x *LeaderboardRequest) GetQuestionableGt() bool {
+	if x != nil && x.QuestionableGt != nil {
+		return *x.QuestionableGt
+	}
+	return false
+}
+
+func (x *LeaderboardRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *LeaderboardRequest) GetMinScore() int32 {
+	if x != nil && x.MinScore != nil {
+		return *x.MinScore
+	}
+	return 0
+}
+
+func (x *LeaderboardRequest) GetMaxScore() int32 {
+	if x != nil && x.MaxScore != nil {
+		return *x.MaxScore
+	}
+	return 0
+}
+
+func (x *LeaderboardRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *LeaderboardRequest) GetReviewState() string {
+	if x != nil {
+		return x.ReviewState
+	}
+	return ""
+}
+
+func (x *LeaderboardRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *LeaderboardRequest) GetRun() string {
+	if x != nil {
+		return x.Run
+	}
+	return ""
+}
+
+type LeaderboardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Providers     []*ProviderStats       `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaderboardResponse) Reset() {
+	*x = LeaderboardResponse{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaderboardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaderboardResponse) ProtoMessage() {}
+
+func (x *LeaderboardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaderboardResponse.ProtoReflect.Descriptor instead.
+func (*LeaderboardResponse) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LeaderboardResponse) GetProviders() []*ProviderStats {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+type ProviderStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	WeightedQ     float64                `protobuf:"fixed64,2,opt,name=weighted_q,json=weightedQ,proto3" json:"weighted_q,omitempty"`
+	WeightedS     float64                `protobuf:"fixed64,3,opt,name=weighted_s,json=weightedS,proto3" json:"weighted_s,omitempty"`
+	WeightedP     float64                `protobuf:"fixed64,4,opt,name=weighted_p,json=weightedP,proto3" json:"weighted_p,omitempty"`
+	TotalTokens   int32                  `protobuf:"varint,5,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`
+	Cases         int32                  `protobuf:"varint,6,opt,name=cases,proto3" json:"cases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProviderStats) Reset() {
+	*x = ProviderStats{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderStats) ProtoMessage() {}
+
+func (x *ProviderStats) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderStats.ProtoReflect.Descriptor instead.
+func (*ProviderStats) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ProviderStats) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ProviderStats) GetWeightedQ() float64 {
+	if x != nil {
+		return x.WeightedQ
+	}
+	return 0
+}
+
+func (x *ProviderStats) GetWeightedS() float64 {
+	if x != nil {
+		return x.WeightedS
+	}
+	return 0
+}
+
+func (x *ProviderStats) GetWeightedP() float64 {
+	if x != nil {
+		return x.WeightedP
+	}
+	return 0
+}
+
+func (x *ProviderStats) GetTotalTokens() int32 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+func (x *ProviderStats) GetCases() int32 {
+	if x != nil {
+		return x.Cases
+	}
+	return 0
+}
+
+type HeadToHeadRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	HasEval        *bool                  `protobuf:"varint,1,opt,name=has_eval,json=hasEval,proto3,oneof" json:"has_eval,omitempty"`
+	QuestionableGt *bool                  `protobuf:"varint,2,opt,name=questionable_gt,json=questionableGt,proto3,oneof" json:"questionable_gt,omitempty"`
+	Provider       string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	MinScore       *int32                 `protobuf:"varint,4,opt,name=min_score,json=minScore,proto3,oneof" json:"min_score,omitempty"`
+	MaxScore       *int32                 `protobuf:"varint,5,opt,name=max_score,json=maxScore,proto3,oneof" json:"max_score,omitempty"`
+	Tags           []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	ReviewState    string                 `protobuf:"bytes,7,opt,name=review_state,json=reviewState,proto3" json:"review_state,omitempty"`
+	Assignee       string                 `protobuf:"bytes,8,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Run            string                 `protobuf:"bytes,9,opt,name=run,proto3" json:"run,omitempty"`
+	A              string                 `protobuf:"bytes,10,opt,name=a,proto3" json:"a,omitempty"`
+	B              string                 `protobuf:"bytes,11,opt,name=b,proto3" json:"b,omitempty"`
+	Top            int32                  `protobuf:"varint,12,opt,name=top,proto3" json:"top,omitempty"` // Largest gaps to list; defaults to 10
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *HeadToHeadRequest) Reset() {
+	*x = HeadToHeadRequest{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeadToHeadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeadToHeadRequest) ProtoMessage() {}
+
+func (x *HeadToHeadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeadToHeadRequest.ProtoReflect.Descriptor instead.
+func (*HeadToHeadRequest) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *HeadToHeadRequest) GetHasEval() bool {
+	if x != nil && x.HasEval != nil {
+		return *x.HasEval
+	}
+	return false
+}
+
+func (x *HeadToHeadRequest) GetQuestionableGt() bool {
+	if x != nil && x.QuestionableGt != nil {
+		return *x.QuestionableGt
+	}
+	return false
+}
+
+func (x *HeadToHeadRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *HeadToHeadRequest) GetMinScore() int32 {
+	if x != nil && x.MinScore != nil {
+		return *x.MinScore
+	}
+	return 0
+}
+
+func (x *HeadToHeadRequest) GetMaxScore() int32 {
+	if x != nil && x.MaxScore != nil {
+		return *x.MaxScore
+	}
+	return 0
+}
+
+func (x *HeadToHeadRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *HeadToHeadRequest) GetReviewState() string {
+	if x != nil {
+		return x.ReviewState
+	}
+	return ""
+}
+
+func (x *HeadToHeadRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *HeadToHeadRequest) GetRun() string {
+	if x != nil {
+		return x.Run
+	}
+	return ""
+}
+
+func (x *HeadToHeadRequest) GetA() string {
+	if x != nil {
+		return x.A
+	}
+	return ""
+}
+
+func (x *HeadToHeadRequest) GetB() string {
+	if x != nil {
+		return x.B
+	}
+	return ""
+}
+
+func (x *HeadToHeadRequest) GetTop() int32 {
+	if x != nil {
+		return x.Top
+	}
+	return 0
+}
+
+type HeadToHeadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	A             string                 `protobuf:"bytes,1,opt,name=a,proto3" json:"a,omitempty"`
+	B             string                 `protobuf:"bytes,2,opt,name=b,proto3" json:"b,omitempty"`
+	WinsA         int32                  `protobuf:"varint,3,opt,name=wins_a,json=winsA,proto3" json:"wins_a,omitempty"`
+	WinsB         int32                  `protobuf:"varint,4,opt,name=wins_b,json=winsB,proto3" json:"wins_b,omitempty"`
+	Ties          int32                  `protobuf:"varint,5,opt,name=ties,proto3" json:"ties,omitempty"`
+	MeanDelta     float64                `protobuf:"fixed64,6,opt,name=mean_delta,json=meanDelta,proto3" json:"mean_delta,omitempty"`
+	Cases         []*CaseDelta           `protobuf:"bytes,7,rep,name=cases,proto3" json:"cases,omitempty"`                    // By case ID
+	TopGaps       []*CaseDelta           `protobuf:"bytes,8,rep,name=top_gaps,json=topGaps,proto3" json:"top_gaps,omitempty"` // Largest |delta| first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeadToHeadResponse) Reset() {
+	*x = HeadToHeadResponse{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeadToHeadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeadToHeadResponse) ProtoMessage() {}
+
+func (x *HeadToHeadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeadToHeadResponse.ProtoReflect.Descriptor instead.
+func (*HeadToHeadResponse) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HeadToHeadResponse) GetA() string {
+	if x != nil {
+		return x.A
+	}
+	return ""
+}
+
+func (x *HeadToHeadResponse) GetB() string {
+	if x != nil {
+		return x.B
+	}
+	return ""
+}
+
+func (x *HeadToHeadResponse) GetWinsA() int32 {
+	if x != nil {
+		return x.WinsA
+	}
+	return 0
+}
+
+func (x *HeadToHeadResponse) GetWinsB() int32 {
+	if x != nil {
+		return x.WinsB
+	}
+	return 0
+}
+
+func (x *HeadToHeadResponse) GetTies() int32 {
+	if x != nil {
+		return x.Ties
+	}
+	return 0
+}
+
+func (x *HeadToHeadResponse) GetMeanDelta() float64 {
+	if x != nil {
+		return x.MeanDelta
+	}
+	return 0
+}
+
+func (x *HeadToHeadResponse) GetCases() []*CaseDelta {
+	if x != nil {
+		return x.Cases
+	}
+	return nil
+}
+
+func (x *HeadToHeadResponse) GetTopGaps() []*CaseDelta {
+	if x != nil {
+		return x.TopGaps
+	}
+	return nil
+}
+
+type CaseDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	A             int32                  `protobuf:"varint,2,opt,name=a,proto3" json:"a,omitempty"`
+	B             int32                  `protobuf:"varint,3,opt,name=b,proto3" json:"b,omitempty"`
+	Delta         int32                  `protobuf:"varint,4,opt,name=delta,proto3" json:"delta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaseDelta) Reset() {
+	*x = CaseDelta{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaseDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaseDelta) ProtoMessage() {}
+
+func (x *CaseDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaseDelta.ProtoReflect.Descriptor instead.
+func (*CaseDelta) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CaseDelta) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CaseDelta) GetA() int32 {
+	if x != nil {
+		return x.A
+	}
+	return 0
+}
+
+func (x *CaseDelta) GetB() int32 {
+	if x != nil {
+		return x.B
+	}
+	return 0
+}
+
+func (x *CaseDelta) GetDelta() int32 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+type Case struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Transcripts   map[string]string      `protobuf:"bytes,2,rep,name=transcripts,proto3" json:"transcripts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // By provider
+	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Streams       []string               `protobuf:"bytes,4,rep,name=streams,proto3" json:"streams,omitempty"`                                                                                   // Providers with a realtime stream dump; GetCase only
+	Review        *Review                `protobuf:"bytes,5,opt,name=review,proto3" json:"review,omitempty"`                                                                                     // Unset when unreviewed
+	Corrections   map[string]*Correction `protobuf:"bytes,6,rep,name=corrections,proto3" json:"corrections,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // By provider; GetCase only
+	Channels      *ChannelOptions        `protobuf:"bytes,7,opt,name=channels,proto3" json:"channels,omitempty"`                                                                                 // Unset when the dataset's selection applies; GetCase only
+	EvalContext   *EvalContext           `protobuf:"bytes,8,opt,name=eval_context,json=evalContext,proto3" json:"eval_context,omitempty"`
+	ReportV2      *EvalReport            `protobuf:"bytes,9,opt,name=report_v2,json=reportV2,proto3" json:"report_v2,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Case) Reset() {
+	*x = Case{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Case) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Case) ProtoMessage() {}
+
+func (x *Case) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Case.ProtoReflect.Descriptor instead.
+func (*Case) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Case) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Case) GetTranscripts() map[string]string {
+	if x != nil {
+		return x.Transcripts
+	}
+	return nil
+}
+
+func (x *Case) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Case) GetStreams() []string {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+func (x *Case) GetReview() *Review {
+	if x != nil {
+		return x.Review
+	}
+	return nil
+}
+
+func (x *Case) GetCorrections() map[string]*Correction {
+	if x != nil {
+		return x.Corrections
+	}
+	return nil
+}
+
+func (x *Case) GetChannels() *ChannelOptions {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+func (x *Case) GetEvalContext() *EvalContext {
+	if x != nil {
+		return x.EvalContext
+	}
+	return nil
+}
+
+func (x *Case) GetReportV2() *EvalReport {
+	if x != nil {
+		return x.ReportV2
+	}
+	return nil
+}
+
+type Review struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // unreviewed, in_review, approved or disputed
+	Assignee      string                 `protobuf:"bytes,2,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	Note          string                 `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+	UpdatedBy     string                 `protobuf:"bytes,4,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	UpdateTime    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Review) Reset() {
+	*x = Review{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Review) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Review) ProtoMessage() {}
+
+func (x *Review) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Review.ProtoReflect.Descriptor instead.
+func (*Review) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Review) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Review) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *Review) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *Review) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *Review) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+type Correction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	UpdatedBy     string                 `protobuf:"bytes,2,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	UpdateTime    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Correction) Reset() {
+	*x = Correction{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Correction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Correction) ProtoMessage() {}
+
+func (x *Correction) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Correction.ProtoReflect.Descriptor instead.
+func (*Correction) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Correction) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Correction) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *Correction) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+type ChannelOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Select        string                 `protobuf:"bytes,1,opt,name=select,proto3" json:"select,omitempty"`      // mix, agent or customer
+	Agent         int32                  `protobuf:"varint,2,opt,name=agent,proto3" json:"agent,omitempty"`       // 1-based; 0 means 1
+	Customer      int32                  `protobuf:"varint,3,opt,name=customer,proto3" json:"customer,omitempty"` // 1-based; 0 means 2
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChannelOptions) Reset() {
+	*x = ChannelOptions{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChannelOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelOptions) ProtoMessage() {}
+
+func (x *ChannelOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelOptions.ProtoReflect.Descriptor instead.
+func (*ChannelOptions) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ChannelOptions) GetSelect() string {
+	if x != nil {
+		return x.Select
+	}
+	return ""
+}
+
+func (x *ChannelOptions) GetAgent() int32 {
+	if x != nil {
+		return x.Agent
+	}
+	return 0
+}
+
+func (x *ChannelOptions) GetCustomer() int32 {
+	if x != nil {
+		return x.Customer
+	}
+	return 0
+}
+
+type EvalContext struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	SchemaVersion     int32                  `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	Meta              *ContextMeta           `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	Checkpoints       []*Checkpoint          `protobuf:"bytes,3,rep,name=checkpoints,proto3" json:"checkpoints,omitempty"`
+	Hash              string                 `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"` // Output only
+	AudioRealitySpans []*TimedText           `protobuf:"bytes,5,rep,name=audio_reality_spans,json=audioRealitySpans,proto3" json:"audio_reality_spans,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *EvalContext) Reset() {
+	*x = EvalContext{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvalContext) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvalContext) ProtoMessage() {}
+
+func (x *EvalContext) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvalContext.ProtoReflect.Descriptor instead.
+func (*EvalContext) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *EvalContext) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *EvalContext) GetMeta() *ContextMeta {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *EvalContext) GetCheckpoints() []*Checkpoint {
+	if x != nil {
+		return x.Checkpoints
+	}
+	return nil
+}
+
+func (x *EvalContext) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *EvalContext) GetAudioRealitySpans() []*TimedText {
+	if x != nil {
+		return x.AudioRealitySpans
+	}
+	return nil
+}
+
+type ContextMeta struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	BusinessGoal            string                 `protobuf:"bytes,1,opt,name=business_goal,json=businessGoal,proto3" json:"business_goal,omitempty"`
+	AudioRealityInference   string                 `protobuf:"bytes,2,opt,name=audio_reality_inference,json=audioRealityInference,proto3" json:"audio_reality_inference,omitempty"`
+	TotalTokenCountEstimate int32                  `protobuf:"varint,3,opt,name=total_token_count_estimate,json=totalTokenCountEstimate,proto3" json:"total_token_count_estimate,omitempty"`
+	GroundTruth             string                 `protobuf:"bytes,4,opt,name=ground_truth,json=groundTruth,proto3" json:"ground_truth,omitempty"`
+	QuestionableGt          bool                   `protobuf:"varint,5,opt,name=questionable_gt,json=questionableGt,proto3" json:"questionable_gt,omitempty"`
+	QuestionableReason      string                 `protobuf:"bytes,6,opt,name=questionable_reason,json=questionableReason,proto3" json:"questionable_reason,omitempty"`
+	AlignModel              string                 `protobuf:"bytes,7,opt,name=align_model,json=alignModel,proto3" json:"align_model,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *ContextMeta) Reset() {
+	*x = ContextMeta{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContextMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextMeta) ProtoMessage() {}
+
+func (x *ContextMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextMeta.ProtoReflect.Descriptor instead.
+func (*ContextMeta) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ContextMeta) GetBusinessGoal() string {
+	if x != nil {
+		return x.BusinessGoal
+	}
+	return ""
+}
+
+func (x *ContextMeta) GetAudioRealityInference() string {
+	if x != nil {
+		return x.AudioRealityInference
+	}
+	return ""
+}
+
+func (x *ContextMeta) GetTotalTokenCountEstimate() int32 {
+	if x != nil {
+		return x.TotalTokenCountEstimate
+	}
+	return 0
+}
+
+func (x *ContextMeta) GetGroundTruth() string {
+	if x != nil {
+		return x.GroundTruth
+	}
+	return ""
+}
+
+func (x *ContextMeta) GetQuestionableGt() bool {
+	if x != nil {
+		return x.QuestionableGt
+	}
+	return false
+}
+
+func (x *ContextMeta) GetQuestionableReason() string {
+	if x != nil {
+		return x.QuestionableReason
+	}
+	return ""
+}
+
+func (x *ContextMeta) GetAlignModel() string {
+	if x != nil {
+		return x.AlignModel
+	}
+	return ""
+}
+
+type Checkpoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	StartMs       int32                  `protobuf:"varint,2,opt,name=start_ms,json=startMs,proto3" json:"start_ms,omitempty"`
+	TextSegment   string                 `protobuf:"bytes,3,opt,name=text_segment,json=textSegment,proto3" json:"text_segment,omitempty"`
+	Tier          int32                  `protobuf:"varint,4,opt,name=tier,proto3" json:"tier,omitempty"`
+	Weight        float64                `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
+	Rationale     string                 `protobuf:"bytes,6,opt,name=rationale,proto3" json:"rationale,omitempty"`
+	Span          *Span                  `protobuf:"bytes,7,opt,name=span,proto3" json:"span,omitempty"` // Set once aligned
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Checkpoint) Reset() {
+	*x = Checkpoint{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Checkpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Checkpoint) ProtoMessage() {}
+
+func (x *Checkpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Checkpoint.ProtoReflect.Descriptor instead.
+func (*Checkpoint) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Checkpoint) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Checkpoint) GetStartMs() int32 {
+	if x != nil {
+		return x.StartMs
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetTextSegment() string {
+	if x != nil {
+		return x.TextSegment
+	}
+	return ""
+}
+
+func (x *Checkpoint) GetTier() int32 {
+	if x != nil {
+		return x.Tier
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Checkpoint) GetRationale() string {
+	if x != nil {
+		return x.Rationale
+	}
+	return ""
+}
+
+func (x *Checkpoint) GetSpan() *Span {
+	if x != nil {
+		return x.Span
+	}
+	return nil
+}
+
+type Span struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartMs       int32                  `protobuf:"varint,1,opt,name=start_ms,json=startMs,proto3" json:"start_ms,omitempty"`
+	EndMs         int32                  `protobuf:"varint,2,opt,name=end_ms,json=endMs,proto3" json:"end_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Span) Reset() {
+	*x = Span{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Span) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Span) ProtoMessage() {}
+
+func (x *Span) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Span.ProtoReflect.Descriptor instead.
+func (*Span) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Span) GetStartMs() int32 {
+	if x != nil {
+		return x.StartMs
+	}
+	return 0
+}
+
+func (x *Span) GetEndMs() int32 {
+	if x != nil {
+		return x.EndMs
+	}
+	return 0
+}
+
+type TimedText struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartMs       int32                  `protobuf:"varint,1,opt,name=start_ms,json=startMs,proto3" json:"start_ms,omitempty"`
+	EndMs         int32                  `protobuf:"varint,2,opt,name=end_ms,json=endMs,proto3" json:"end_ms,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimedText) Reset() {
+	*x = TimedText{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimedText) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimedText) ProtoMessage() {}
+
+func (x *TimedText) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimedText.ProtoReflect.Descriptor instead.
+func (*TimedText) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *TimedText) GetStartMs() int32 {
+	if x != nil {
+		return x.StartMs
+	}
+	return 0
+}
+
+func (x *TimedText) GetEndMs() int32 {
+	if x != nil {
+		return x.EndMs
+	}
+	return 0
+}
+
+func (x *TimedText) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type EvalReport struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SchemaVersion   int32                  `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	Evaluations     map[string]*EvalResult `protobuf:"bytes,2,rep,name=evaluations,proto3" json:"evaluations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // By provider
+	ContextSnapshot *EvalContext           `protobuf:"bytes,3,opt,name=context_snapshot,json=contextSnapshot,proto3" json:"context_snapshot,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *EvalReport) Reset() {
+	*x = EvalReport{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvalReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvalReport) ProtoMessage() {}
+
+func (x *EvalReport) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvalReport.ProtoReflect.Descriptor instead.
+func (*EvalReport) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *EvalReport) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *EvalReport) GetEvaluations() map[string]*EvalResult {
+	if x != nil {
+		return x.Evaluations
+	}
+	return nil
+}
+
+func (x *EvalReport) GetContextSnapshot() *EvalContext {
+	if x != nil {
+		return x.ContextSnapshot
+	}
+	return nil
+}
+
+type EvalResult struct {
+	state             protoimpl.MessageState       `protogen:"open.v1"`
+	Transcript        string                       `protobuf:"bytes,1,opt,name=transcript,proto3" json:"transcript,omitempty"`
+	RevisedTranscript string                       `protobuf:"bytes,2,opt,name=revised_transcript,json=revisedTranscript,proto3" json:"revised_transcript,omitempty"`
+	Metrics           *EvalMetrics                 `protobuf:"bytes,3,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	CheckpointResults map[string]*CheckpointResult `protobuf:"bytes,4,rep,name=checkpoint_results,json=checkpointResults,proto3" json:"checkpoint_results,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // By checkpoint ID
+	Summary           []string                     `protobuf:"bytes,5,rep,name=summary,proto3" json:"summary,omitempty"`
+	Model             string                       `protobuf:"bytes,6,opt,name=model,proto3" json:"model,omitempty"`           // Judge model that produced this result
+	Truncation        *Truncation                  `protobuf:"bytes,7,opt,name=truncation,proto3" json:"truncation,omitempty"` // Set when the prompt was shrunk to fit
+	// Consensus evaluation only.
+	Verdicts      []*JudgeVerdict `protobuf:"bytes,8,rep,name=verdicts,proto3" json:"verdicts,omitempty"`
+	Disputed      []string        `protobuf:"bytes,9,rep,name=disputed,proto3" json:"disputed,omitempty"`                              // Checkpoint IDs the judges disagree on
+	FailedJudges  []string        `protobuf:"bytes,10,rep,name=failed_judges,json=failedJudges,proto3" json:"failed_judges,omitempty"` // Judges left out after failing
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvalResult) Reset() {
+	*x = EvalResult{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvalResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvalResult) ProtoMessage() {}
+
+func (x *EvalResult) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvalResult.ProtoReflect.Descriptor instead.
+func (*EvalResult) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *EvalResult) GetTranscript() string {
+	if x != nil {
+		return x.Transcript
+	}
+	return ""
+}
+
+func (x *EvalResult) GetRevisedTranscript() string {
+	if x != nil {
+		return x.RevisedTranscript
+	}
+	return ""
+}
+
+func (x *EvalResult) GetMetrics() *EvalMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *EvalResult) GetCheckpointResults() map[string]*CheckpointResult {
+	if x != nil {
+		return x.CheckpointResults
+	}
+	return nil
+}
+
+func (x *EvalResult) GetSummary() []string {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *EvalResult) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *EvalResult) GetTruncation() *Truncation {
+	if x != nil {
+		return x.Truncation
+	}
+	return nil
+}
+
+func (x *EvalResult) GetVerdicts() []*JudgeVerdict {
+	if x != nil {
+		return x.Verdicts
+	}
+	return nil
+}
+
+func (x *EvalResult) GetDisputed() []string {
+	if x != nil {
+		return x.Disputed
+	}
+	return nil
+}
+
+func (x *EvalResult) GetFailedJudges() []string {
+	if x != nil {
+		return x.FailedJudges
+	}
+	return nil
+}
+
+type JudgeVerdict struct {
+	state             protoimpl.MessageState       `protogen:"open.v1"`
+	Model             string                       `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	Metrics           *EvalMetrics                 `protobuf:"bytes,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	CheckpointResults map[string]*CheckpointResult `protobuf:"bytes,3,rep,name=checkpoint_results,json=checkpointResults,proto3" json:"checkpoint_results,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Truncation        *Truncation                  `protobuf:"bytes,4,opt,name=truncation,proto3" json:"truncation,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *JudgeVerdict) Reset() {
+	*x = JudgeVerdict{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JudgeVerdict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JudgeVerdict) ProtoMessage() {}
+
+func (x *JudgeVerdict) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JudgeVerdict.ProtoReflect.Descriptor instead.
+func (*JudgeVerdict) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *JudgeVerdict) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *JudgeVerdict) GetMetrics() *EvalMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *JudgeVerdict) GetCheckpointResults() map[string]*CheckpointResult {
+	if x != nil {
+		return x.CheckpointResults
+	}
+	return nil
+}
+
+func (x *JudgeVerdict) GetTruncation() *Truncation {
+	if x != nil {
+		return x.Truncation
+	}
+	return nil
+}
+
+// Field names keep the JSON API's keys.
+type EvalMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SScore        float64                `protobuf:"fixed64,1,opt,name=S_score,json=SScore,proto3" json:"S_score,omitempty"`
+	PScore        float64                `protobuf:"fixed64,2,opt,name=P_score,json=PScore,proto3" json:"P_score,omitempty"`
+	QScore        int32                  `protobuf:"varint,3,opt,name=Q_score,json=QScore,proto3" json:"Q_score,omitempty"` // 0-100
+	PERDetails    *PhoneticDetails       `protobuf:"bytes,4,opt,name=PER_details,json=PERDetails,proto3" json:"PER_details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvalMetrics) Reset() {
+	*x = EvalMetrics{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvalMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvalMetrics) ProtoMessage() {}
+
+func (x *EvalMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvalMetrics.ProtoReflect.Descriptor instead.
+func (*EvalMetrics) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *EvalMetrics) GetSScore() float64 {
+	if x != nil {
+		return x.SScore
+	}
+	return 0
+}
+
+func (x *EvalMetrics) GetPScore() float64 {
+	if x != nil {
+		return x.PScore
+	}
+	return 0
+}
+
+func (x *EvalMetrics) GetQScore() int32 {
+	if x != nil {
+		return x.QScore
+	}
+	return 0
+}
+
+func (x *EvalMetrics) GetPERDetails() *PhoneticDetails {
+	if x != nil {
+		return x.PERDetails
+	}
+	return nil
+}
+
+type PhoneticDetails struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sub           int32                  `protobuf:"varint,1,opt,name=sub,proto3" json:"sub,omitempty"`
+	Del           int32                  `protobuf:"varint,2,opt,name=del,proto3" json:"del,omitempty"`
+	Ins           int32                  `protobuf:"varint,3,opt,name=ins,proto3" json:"ins,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PhoneticDetails) Reset() {
+	*x = PhoneticDetails{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PhoneticDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PhoneticDetails) ProtoMessage() {}
+
+func (x *PhoneticDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PhoneticDetails.ProtoReflect.Descriptor instead.
+func (*PhoneticDetails) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PhoneticDetails) GetSub() int32 {
+	if x != nil {
+		return x.Sub
+	}
+	return 0
+}
+
+func (x *PhoneticDetails) GetDel() int32 {
+	if x != nil {
+		return x.Del
+	}
+	return 0
+}
+
+func (x *PhoneticDetails) GetIns() int32 {
+	if x != nil {
+		return x.Ins
+	}
+	return 0
+}
+
+type CheckpointResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // Pass, Fail or Partial
+	Detected      string                 `protobuf:"bytes,2,opt,name=detected,proto3" json:"detected,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Override      *CheckpointOverride    `protobuf:"bytes,4,opt,name=override,proto3" json:"override,omitempty"` // Set when a reviewer adjudicated the status
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckpointResult) Reset() {
+	*x = CheckpointResult{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckpointResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckpointResult) ProtoMessage() {}
+
+func (x *CheckpointResult) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckpointResult.ProtoReflect.Descriptor instead.
+func (*CheckpointResult) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CheckpointResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CheckpointResult) GetDetected() string {
+	if x != nil {
+		return x.Detected
+	}
+	return ""
+}
+
+func (x *CheckpointResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CheckpointResult) GetOverride() *CheckpointOverride {
+	if x != nil {
+		return x.Override
+	}
+	return nil
+}
+
+type CheckpointOverride struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OriginalStatus string                 `protobuf:"bytes,1,opt,name=original_status,json=originalStatus,proto3" json:"original_status,omitempty"` // Status the judge gave
+	Reason         string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Author         string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Time           *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CheckpointOverride) Reset() {
+	*x = CheckpointOverride{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckpointOverride) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckpointOverride) ProtoMessage() {}
+
+func (x *CheckpointOverride) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckpointOverride.ProtoReflect.Descriptor instead.
+func (*CheckpointOverride) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CheckpointOverride) GetOriginalStatus() string {
+	if x != nil {
+		return x.OriginalStatus
+	}
+	return ""
+}
+
+func (x *CheckpointOverride) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CheckpointOverride) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *CheckpointOverride) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type Truncation struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	PromptTokens         int32                  `protobuf:"varint,1,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	DroppedCheckpoints   []string               `protobuf:"bytes,2,rep,name=dropped_checkpoints,json=droppedCheckpoints,proto3" json:"dropped_checkpoints,omitempty"`                                                                                  // Tier-3 checkpoint IDs left out
+	TruncatedTranscripts map[string]int32       `protobuf:"bytes,3,rep,name=truncated_transcripts,json=truncatedTranscripts,proto3" json:"truncated_transcripts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // Provider to original length in runes
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Truncation) Reset() {
+	*x = Truncation{}
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Truncation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Truncation) ProtoMessage() {}
+
+func (x *Truncation) ProtoReflect() protoreflect.Message {
+	mi := &file_asreval_workspace_v1_workspace_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Truncation.ProtoReflect.Descriptor instead.
+func (*Truncation) Descriptor() ([]byte, []int) {
+	return file_asreval_workspace_v1_workspace_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Truncation) GetPromptTokens() int32 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *Truncation) GetDroppedCheckpoints() []string {
+	if x != nil {
+		return x.DroppedCheckpoints
+	}
+	return nil
+}
+
+func (x *Truncation) GetTruncatedTranscripts() map[string]int32 {
+	if x != nil {
+		return x.TruncatedTranscripts
+	}
+	return nil
+}
+
+var File_asreval_workspace_v1_workspace_proto protoreflect.FileDescriptor
+
+const file_asreval_workspace_v1_workspace_proto_rawDesc = "" +
+	"\n" +
+	"$asreval/workspace/v1/workspace.proto\x12\x14asreval.workspace.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa7\x03\n" +
+	"\x10ListCasesRequest\x12\x1e\n" +
+	"\bhas_eval\x18\x01 \x01(\bH\x00R\ahasEval\x88\x01\x01\x12,\n" +
+	"\x0fquestionable_gt\x18\x02 \x01(\bH\x01R\x0equestionableGt\x88\x01\x01\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12 \n" +
+	"\tmin_score\x18\x04 \x01(\x05H\x02R\bminScore\x88\x01\x01\x12 \n" +
+	"\tmax_score\x18\x05 \x01(\x05H\x03R\bmaxScore\x88\x01\x01\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12!\n" +
+	"\freview_state\x18\a \x01(\tR\vreviewState\x12\x1a\n" +
+	"\bassignee\x18\b \x01(\tR\bassignee\x12\x10\n" +
+	"\x03run\x18\t \x01(\tR\x03run\x12\x12\n" +
+	"\x04sort\x18\n" +
+	" \x01(\tR\x04sort\x12\x12\n" +
+	"\x04page\x18\v \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\f \x01(\x05R\bpageSizeB\v\n" +
+	"\t_has_evalB\x12\n" +
+	"\x10_questionable_gtB\f\n" +
+	"\n" +
+	"_min_scoreB\f\n" +
+	"\n" +
+	"_max_score\"d\n" +
+	"\x11ListCasesResponse\x120\n" +
+	"\x05cases\x18\x01 \x03(\v2\x1a.asreval.workspace.v1.CaseR\x05cases\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x02 \x01(\x05R\ttotalSize\" \n" +
+	"\x0eGetCaseRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xd6\x01\n" +
+	"\x0fEvaluateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12D\n" +
+	"\feval_context\x18\x02 \x01(\v2!.asreval.workspace.v1.EvalContextR\vevalContext\x12!\n" +
+	"\fprovider_ids\x18\x03 \x03(\tR\vproviderIds\x12!\n" +
+	"\fjudge_models\x18\x04 \x03(\tR\vjudgeModels\x12'\n" +
+	"\x0fuse_corrections\x18\x05 \x01(\bR\x0euseCorrections\"K\n" +
+	"\x16GenerateContextRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fground_truth\x18\x02 \x01(\tR\vgroundTruth\"l\n" +
+	"\x14UpdateContextRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12D\n" +
+	"\feval_context\x18\x02 \x01(\v2!.asreval.workspace.v1.EvalContextR\vevalContext\"\xe4\x02\n" +
+	"\x12LeaderboardRequest\x12\x1e\n" +
+	"\bhas_eval\x18\x01 \x01(\bH\x00R\ahasEval\x88\x01\x01\x12,\n" +
+	"\x0fquestionable_gt\x18\x02 \x01(\bH\x01R\x0equestionableGt\x88\x01\x01\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12 \n" +
+	"\tmin_score\x18\x04 \x01(\x05H\x02R\bminScore\x88\x01\x01\x12 \n" +
+	"\tmax_score\x18\x05 \x01(\x05H\x03R\bmaxScore\x88\x01\x01\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12!\n" +
+	"\freview_state\x18\a \x01(\tR\vreviewState\x12\x1a\n" +
+	"\bassignee\x18\b \x01(\tR\bassignee\x12\x10\n" +
+	"\x03run\x18\t \x01(\tR\x03runB\v\n" +
+	"\t_has_evalB\x12\n" +
+	"\x10_questionable_gtB\f\n" +
+	"\n" +
+	"_min_scoreB\f\n" +
+	"\n" +
+	"_max_score\"X\n" +
+	"\x13LeaderboardResponse\x12A\n" +
+	"\tproviders\x18\x01 \x03(\v2#.asreval.workspace.v1.ProviderStatsR\tproviders\"\xc1\x01\n" +
+	"\rProviderStats\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x1d\n" +
+	"\n" +
+	"weighted_q\x18\x02 \x01(\x01R\tweightedQ\x12\x1d\n" +
+	"\n" +
+	"weighted_s\x18\x03 \x01(\x01R\tweightedS\x12\x1d\n" +
+	"\n" +
+	"weighted_p\x18\x04 \x01(\x01R\tweightedP\x12!\n" +
+	"\ftotal_tokens\x18\x05 \x01(\x05R\vtotalTokens\x12\x14\n" +
+	"\x05cases\x18\x06 \x01(\x05R\x05cases\"\x91\x03\n" +
+	"\x11HeadToHeadRequest\x12\x1e\n" +
+	"\bhas_eval\x18\x01 \x01(\bH\x00R\ahasEval\x88\x01\x01\x12,\n" +
+	"\x0fquestionable_gt\x18\x02 \x01(\bH\x01R\x0equestionableGt\x88\x01\x01\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12 \n" +
+	"\tmin_score\x18\x04 \x01(\x05H\x02R\bminScore\x88\x01\x01\x12 \n" +
+	"\tmax_score\x18\x05 \x01(\x05H\x03R\bmaxScore\x88\x01\x01\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12!\n" +
+	"\freview_state\x18\a \x01(\tR\vreviewState\x12\x1a\n" +
+	"\bassignee\x18\b \x01(\tR\bassignee\x12\x10\n" +
+	"\x03run\x18\t \x01(\tR\x03run\x12\f\n" +
+	"\x01a\x18\n" +
+	" \x01(\tR\x01a\x12\f\n" +
+	"\x01b\x18\v \x01(\tR\x01b\x12\x10\n" +
+	"\x03top\x18\f \x01(\x05R\x03topB\v\n" +
+	"\t_has_evalB\x12\n" +
+	"\x10_questionable_gtB\f\n" +
+	"\n" +
+	"_min_scoreB\f\n" +
+	"\n" +
+	"_max_score\"\x84\x02\n" +
+	"\x12HeadToHeadResponse\x12\f\n" +
+	"\x01a\x18\x01 \x01(\tR\x01a\x12\f\n" +
+	"\x01b\x18\x02 \x01(\tR\x01b\x12\x15\n" +
+	"\x06wins_a\x18\x03 \x01(\x05R\x05winsA\x12\x15\n" +
+	"\x06wins_b\x18\x04 \x01(\x05R\x05winsB\x12\x12\n" +
+	"\x04ties\x18\x05 \x01(\x05R\x04ties\x12\x1d\n" +
+	"\n" +
+	"mean_delta\x18\x06 \x01(\x01R\tmeanDelta\x125\n" +
+	"\x05cases\x18\a \x03(\v2\x1f.asreval.workspace.v1.CaseDeltaR\x05cases\x12:\n" +
+	"\btop_gaps\x18\b \x03(\v2\x1f.asreval.workspace.v1.CaseDeltaR\atopGaps\"M\n" +
+	"\tCaseDelta\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\f\n" +
+	"\x01a\x18\x02 \x01(\x05R\x01a\x12\f\n" +
+	"\x01b\x18\x03 \x01(\x05R\x01b\x12\x14\n" +
+	"\x05delta\x18\x04 \x01(\x05R\x05delta\"\x81\x05\n" +
+	"\x04Case\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12M\n" +
+	"\vtranscripts\x18\x02 \x03(\v2+.asreval.workspace.v1.Case.TranscriptsEntryR\vtranscripts\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x18\n" +
+	"\astreams\x18\x04 \x03(\tR\astreams\x124\n" +
+	"\x06review\x18\x05 \x01(\v2\x1c.asreval.workspace.v1.ReviewR\x06review\x12M\n" +
+	"\vcorrections\x18\x06 \x03(\v2+.asreval.workspace.v1.Case.CorrectionsEntryR\vcorrections\x12@\n" +
+	"\bchannels\x18\a \x01(\v2$.asreval.workspace.v1.ChannelOptionsR\bchannels\x12D\n" +
+	"\feval_context\x18\b \x01(\v2!.asreval.workspace.v1.EvalContextR\vevalContext\x12=\n" +
+	"\treport_v2\x18\t \x01(\v2 .asreval.workspace.v1.EvalReportR\breportV2\x1a>\n" +
+	"\x10TranscriptsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a`\n" +
+	"\x10CorrectionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x126\n" +
+	"\x05value\x18\x02 \x01(\v2 .asreval.workspace.v1.CorrectionR\x05value:\x028\x01\"\xaa\x01\n" +
+	"\x06Review\x12\x14\n" +
+	"\x05state\x18\x01 \x01(\tR\x05state\x12\x1a\n" +
+	"\bassignee\x18\x02 \x01(\tR\bassignee\x12\x12\n" +
+	"\x04note\x18\x03 \x01(\tR\x04note\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x04 \x01(\tR\tupdatedBy\x12;\n" +
+	"\vupdate_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateTime\"|\n" +
+	"\n" +
+	"Correction\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x02 \x01(\tR\tupdatedBy\x12;\n" +
+	"\vupdate_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateTime\"Z\n" +
+	"\x0eChannelOptions\x12\x16\n" +
+	"\x06select\x18\x01 \x01(\tR\x06select\x12\x14\n" +
+	"\x05agent\x18\x02 \x01(\x05R\x05agent\x12\x1a\n" +
+	"\bcustomer\x18\x03 \x01(\x05R\bcustomer\"\x94\x02\n" +
+	"\vEvalContext\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\x05R\rschemaVersion\x125\n" +
+	"\x04meta\x18\x02 \x01(\v2!.asreval.workspace.v1.ContextMetaR\x04meta\x12B\n" +
+	"\vcheckpoints\x18\x03 \x03(\v2 .asreval.workspace.v1.CheckpointR\vcheckpoints\x12\x12\n" +
+	"\x04hash\x18\x04 \x01(\tR\x04hash\x12O\n" +
+	"\x13audio_reality_spans\x18\x05 \x03(\v2\x1f.asreval.workspace.v1.TimedTextR\x11audioRealitySpans\"\xc5\x02\n" +
+	"\vContextMeta\x12#\n" +
+	"\rbusiness_goal\x18\x01 \x01(\tR\fbusinessGoal\x126\n" +
+	"\x17audio_reality_inference\x18\x02 \x01(\tR\x15audioRealityInference\x12;\n" +
+	"\x1atotal_token_count_estimate\x18\x03 \x01(\x05R\x17totalTokenCountEstimate\x12!\n" +
+	"\fground_truth\x18\x04 \x01(\tR\vgroundTruth\x12'\n" +
+	"\x0fquestionable_gt\x18\x05 \x01(\bR\x0equestionableGt\x12/\n" +
+	"\x13questionable_reason\x18\x06 \x01(\tR\x12questionableReason\x12\x1f\n" +
+	"\valign_model\x18\a \x01(\tR\n" +
+	"alignModel\"\xd4\x01\n" +
+	"\n" +
+	"Checkpoint\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bstart_ms\x18\x02 \x01(\x05R\astartMs\x12!\n" +
+	"\ftext_segment\x18\x03 \x01(\tR\vtextSegment\x12\x12\n" +
+	"\x04tier\x18\x04 \x01(\x05R\x04tier\x12\x16\n" +
+	"\x06weight\x18\x05 \x01(\x01R\x06weight\x12\x1c\n" +
+	"\trationale\x18\x06 \x01(\tR\trationale\x12.\n" +
+	"\x04span\x18\a \x01(\v2\x1a.asreval.workspace.v1.SpanR\x04span\"8\n" +
+	"\x04Span\x12\x19\n" +
+	"\bstart_ms\x18\x01 \x01(\x05R\astartMs\x12\x15\n" +
+	"\x06end_ms\x18\x02 \x01(\x05R\x05endMs\"Q\n" +
+	"\tTimedText\x12\x19\n" +
+	"\bstart_ms\x18\x01 \x01(\x05R\astartMs\x12\x15\n" +
+	"\x06end_ms\x18\x02 \x01(\x05R\x05endMs\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\"\xb8\x02\n" +
+	"\n" +
+	"EvalReport\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\x05R\rschemaVersion\x12S\n" +
+	"\vevaluations\x18\x02 \x03(\v21.asreval.workspace.v1.EvalReport.EvaluationsEntryR\vevaluations\x12L\n" +
+	"\x10context_snapshot\x18\x03 \x01(\v2!.asreval.workspace.v1.EvalContextR\x0fcontextSnapshot\x1a`\n" +
+	"\x10EvaluationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x126\n" +
+	"\x05value\x18\x02 \x01(\v2 .asreval.workspace.v1.EvalResultR\x05value:\x028\x01\"\xe1\x04\n" +
+	"\n" +
+	"EvalResult\x12\x1e\n" +
+	"\n" +
+	"transcript\x18\x01 \x01(\tR\n" +
+	"transcript\x12-\n" +
+	"\x12revised_transcript\x18\x02 \x01(\tR\x11revisedTranscript\x12;\n" +
+	"\ametrics\x18\x03 \x01(\v2!.asreval.workspace.v1.EvalMetricsR\ametrics\x12f\n" +
+	"\x12checkpoint_results\x18\x04 \x03(\v27.asreval.workspace.v1.EvalResult.CheckpointResultsEntryR\x11checkpointResults\x12\x18\n" +
+	"\asummary\x18\x05 \x03(\tR\asummary\x12\x14\n" +
+	"\x05model\x18\x06 \x01(\tR\x05model\x12@\n" +
+	"\n" +
+	"truncation\x18\a \x01(\v2 .asreval.workspace.v1.TruncationR\n" +
+	"truncation\x12>\n" +
+	"\bverdicts\x18\b \x03(\v2\".asreval.workspace.v1.JudgeVerdictR\bverdicts\x12\x1a\n" +
+	"\bdisputed\x18\t \x03(\tR\bdisputed\x12#\n" +
+	"\rfailed_judges\x18\n" +
+	" \x03(\tR\ffailedJudges\x1al\n" +
+	"\x16CheckpointResultsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12<\n" +
+	"\x05value\x18\x02 \x01(\v2&.asreval.workspace.v1.CheckpointResultR\x05value:\x028\x01\"\xfb\x02\n" +
+	"\fJudgeVerdict\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12;\n" +
+	"\ametrics\x18\x02 \x01(\v2!.asreval.workspace.v1.EvalMetricsR\ametrics\x12h\n" +
+	"\x12checkpoint_results\x18\x03 \x03(\v29.asreval.workspace.v1.JudgeVerdict.CheckpointResultsEntryR\x11checkpointResults\x12@\n" +
+	"\n" +
+	"truncation\x18\x04 \x01(\v2 .asreval.workspace.v1.TruncationR\n" +
+	"truncation\x1al\n" +
+	"\x16CheckpointResultsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12<\n" +
+	"\x05value\x18\x02 \x01(\v2&.asreval.workspace.v1.CheckpointResultR\x05value:\x028\x01\"\xa0\x01\n" +
+	"\vEvalMetrics\x12\x17\n" +
+	"\aS_score\x18\x01 \x01(\x01R\x06SScore\x12\x17\n" +
+	"\aP_score\x18\x02 \x01(\x01R\x06PScore\x12\x17\n" +
+	"\aQ_score\x18\x03 \x01(\x05R\x06QScore\x12F\n" +
+	"\vPER_details\x18\x04 \x01(\v2%.asreval.workspace.v1.PhoneticDetailsR\n" +
+	"PERDetails\"G\n" +
+	"\x0fPhoneticDetails\x12\x10\n" +
+	"\x03sub\x18\x01 \x01(\x05R\x03sub\x12\x10\n" +
+	"\x03del\x18\x02 \x01(\x05R\x03del\x12\x10\n" +
+	"\x03ins\x18\x03 \x01(\x05R\x03ins\"\xa4\x01\n" +
+	"\x10CheckpointResult\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1a\n" +
+	"\bdetected\x18\x02 \x01(\tR\bdetected\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12D\n" +
+	"\boverride\x18\x04 \x01(\v2(.asreval.workspace.v1.CheckpointOverrideR\boverride\"\x9d\x01\n" +
+	"\x12CheckpointOverride\x12'\n" +
+	"\x0foriginal_status\x18\x01 \x01(\tR\x0eoriginalStatus\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12.\n" +
+	"\x04time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\x9c\x02\n" +
+	"\n" +
+	"Truncation\x12#\n" +
+	"\rprompt_tokens\x18\x01 \x01(\x05R\fpromptTokens\x12/\n" +
+	"\x13dropped_checkpoints\x18\x02 \x03(\tR\x12droppedCheckpoints\x12o\n" +
+	"\x15truncated_transcripts\x18\x03 \x03(\v2:.asreval.workspace.v1.Truncation.TruncatedTranscriptsEntryR\x14truncatedTranscripts\x1aG\n" +
+	"\x19TruncatedTranscriptsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x012\x8d\x05\n" +
+	"\tWorkspace\x12\\\n" +
+	"\tListCases\x12&.asreval.workspace.v1.ListCasesRequest\x1a'.asreval.workspace.v1.ListCasesResponse\x12K\n" +
+	"\aGetCase\x12$.asreval.workspace.v1.GetCaseRequest\x1a\x1a.asreval.workspace.v1.Case\x12S\n" +
+	"\bEvaluate\x12%.asreval.workspace.v1.EvaluateRequest\x1a .asreval.workspace.v1.EvalReport\x12b\n" +
+	"\x0fGenerateContext\x12,.asreval.workspace.v1.GenerateContextRequest\x1a!.asreval.workspace.v1.EvalContext\x12W\n" +
+	"\rUpdateContext\x12*.asreval.workspace.v1.UpdateContextRequest\x1a\x1a.asreval.workspace.v1.Case\x12b\n" +
+	"\vLeaderboard\x12(.asreval.workspace.v1.LeaderboardRequest\x1a).asreval.workspace.v1.LeaderboardResponse\x12_\n" +
+	"\n" +
+	"HeadToHead\x12'.asreval.workspace.v1.HeadToHeadRequest\x1a(.asreval.workspace.v1.HeadToHeadResponseB$Z\"asr-eval/pkg/workspace/workspacepbb\x06proto3"
+
+var (
+	file_asreval_workspace_v1_workspace_proto_rawDescOnce sync.Once
+	file_asreval_workspace_v1_workspace_proto_rawDescData []byte
+)
+
+func file_asreval_workspace_v1_workspace_proto_rawDescGZIP() []byte {
+	file_asreval_workspace_v1_workspace_proto_rawDescOnce.Do(func() {
+		file_asreval_workspace_v1_workspace_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_asreval_workspace_v1_workspace_proto_rawDesc), len(file_asreval_workspace_v1_workspace_proto_rawDesc)))
+	})
+	return file_asreval_workspace_v1_workspace_proto_rawDescData
+}
+
+var file_asreval_workspace_v1_workspace_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_asreval_workspace_v1_workspace_proto_goTypes = []any{
+	(*ListCasesRequest)(nil),       // 0: asreval.workspace.v1.ListCasesRequest
+	(*ListCasesResponse)(nil),      // 1: asreval.workspace.v1.ListCasesResponse
+	(*GetCaseRequest)(nil),         // 2: asreval.workspace.v1.GetCaseRequest
+	(*EvaluateRequest)(nil),        // 3: asreval.workspace.v1.EvaluateRequest
+	(*GenerateContextRequest)(nil), // 4: asreval.workspace.v1.GenerateContextRequest
+	(*UpdateContextRequest)(nil),   // 5: asreval.workspace.v1.UpdateContextRequest
+	(*LeaderboardRequest)(nil),     // 6: asreval.workspace.v1.LeaderboardRequest
+	(*LeaderboardResponse)(nil),    // 7: asreval.workspace.v1.LeaderboardResponse
+	(*ProviderStats)(nil),          // 8: asreval.workspace.v1.ProviderStats
+	(*HeadToHeadRequest)(nil),      // 9: asreval.workspace.v1.HeadToHeadRequest
+	(*HeadToHeadResponse)(nil),     // 10: asreval.workspace.v1.HeadToHeadResponse
+	(*CaseDelta)(nil),              // 11: asreval.workspace.v1.CaseDelta
+	(*Case)(nil),                   // 12: asreval.workspace.v1.Case
+	(*Review)(nil),                 // 13: asreval.workspace.v1.Review
+	(*Correction)(nil),             // 14: asreval.workspace.v1.Correction
+	(*ChannelOptions)(nil),         // 15: asreval.workspace.v1.ChannelOptions
+	(*EvalContext)(nil),            // 16: asreval.workspace.v1.EvalContext
+	(*ContextMeta)(nil),            // 17: asreval.workspace.v1.ContextMeta
+	(*Checkpoint)(nil),             // 18: asreval.workspace.v1.Checkpoint
+	(*Span)(nil),                   // 19: asreval.workspace.v1.Span
+	(*TimedText)(nil),              // 20: asreval.workspace.v1.TimedText
+	(*EvalReport)(nil),             // 21: asreval.workspace.v1.EvalReport
+	(*EvalResult)(nil),             // 22: asreval.workspace.v1.EvalResult
+	(*JudgeVerdict)(nil),           // 23: asreval.workspace.v1.JudgeVerdict
+	(*EvalMetrics)(nil),            // 24: asreval.workspace.v1.EvalMetrics
+	(*PhoneticDetails)(nil),        // 25: asreval.workspace.v1.PhoneticDetails
+	(*CheckpointResult)(nil),       // 26: asreval.workspace.v1.CheckpointResult
+	(*CheckpointOverride)(nil),     // 27: asreval.workspace.v1.CheckpointOverride
+	(*Truncation)(nil),             // 28: asreval.workspace.v1.Truncation
+	nil,                            // 29: asreval.workspace.v1.Case.TranscriptsEntry
+	nil,                            // 30: asreval.workspace.v1.Case.CorrectionsEntry
+	nil,                            // 31: asreval.workspace.v1.EvalReport.EvaluationsEntry
+	nil,                            // 32: asreval.workspace.v1.EvalResult.CheckpointResultsEntry
+	nil,                            // 33: asreval.workspace.v1.JudgeVerdict.CheckpointResultsEntry
+	nil,                            // 34: asreval.workspace.v1.Truncation.TruncatedTranscriptsEntry
+	(*timestamppb.Timestamp)(nil),  // 35: google.protobuf.Timestamp
+}
+var file_asreval_workspace_v1_workspace_proto_depIdxs = []int32{
+	12, // 0: asreval.workspace.v1.ListCasesResponse.cases:type_name -> asreval.workspace.v1.Case
+	16, // 1: asreval.workspace.v1.EvaluateRequest.eval_context:type_name -> asreval.workspace.v1.EvalContext
+	16, // 2: asreval.workspace.v1.UpdateContextRequest.eval_context:type_name -> asreval.workspace.v1.EvalContext
+	8,  // 3: asreval.workspace.v1.LeaderboardResponse.providers:type_name -> asreval.workspace.v1.ProviderStats
+	11, // 4: asreval.workspace.v1.HeadToHeadResponse.cases:type_name -> asreval.workspace.v1.CaseDelta
+	11, // 5: asreval.workspace.v1.HeadToHeadResponse.top_gaps:type_name -> asreval.workspace.v1.CaseDelta
+	29, // 6: asreval.workspace.v1.Case.transcripts:type_name -> asreval.workspace.v1.Case.TranscriptsEntry
+	13, // 7: asreval.workspace.v1.Case.review:type_name -> asreval.workspace.v1.Review
+	30, // 8: asreval.workspace.v1.Case.corrections:type_name -> asreval.workspace.v1.Case.CorrectionsEntry
+	15, // 9: asreval.workspace.v1.Case.channels:type_name -> asreval.workspace.v1.ChannelOptions
+	16, // 10: asreval.workspace.v1.Case.eval_context:type_name -> asreval.workspace.v1.EvalContext
+	21, // 11: asreval.workspace.v1.Case.report_v2:type_name -> asreval.workspace.v1.EvalReport
+	35, // 12: asreval.workspace.v1.Review.update_time:type_name -> google.protobuf.Timestamp
+	35, // 13: asreval.workspace.v1.Correction.update_time:type_name -> google.protobuf.Timestamp
+	17, // 14: asreval.workspace.v1.EvalContext.meta:type_name -> asreval.workspace.v1.ContextMeta
+	18, // 15: asreval.workspace.v1.EvalContext.checkpoints:type_name -> asreval.workspace.v1.Checkpoint
+	20, // 16: asreval.workspace.v1.EvalContext.audio_reality_spans:type_name -> asreval.workspace.v1.TimedText
+	19, // 17: asreval.workspace.v1.Checkpoint.span:type_name -> asreval.workspace.v1.Span
+	31, // 18: asreval.workspace.v1.EvalReport.evaluations:type_name -> asreval.workspace.v1.EvalReport.EvaluationsEntry
+	16, // 19: asreval.workspace.v1.EvalReport.context_snapshot:type_name -> asreval.workspace.v1.EvalContext
+	24, // 20: asreval.workspace.v1.EvalResult.metrics:type_name -> asreval.workspace.v1.EvalMetrics
+	32, // 21: asreval.workspace.v1.EvalResult.checkpoint_results:type_name -> asreval.workspace.v1.EvalResult.CheckpointResultsEntry
+	28, // 22: asreval.workspace.v1.EvalResult.truncation:type_name -> asreval.workspace.v1.Truncation
+	23, // 23: asreval.workspace.v1.EvalResult.verdicts:type_name -> asreval.workspace.v1.JudgeVerdict
+	24, // 24: asreval.workspace.v1.JudgeVerdict.metrics:type_name -> asreval.workspace.v1.EvalMetrics
+	33, // 25: asreval.workspace.v1.JudgeVerdict.checkpoint_results:type_name -> asreval.workspace.v1.JudgeVerdict.CheckpointResultsEntry
+	28, // 26: asreval.workspace.v1.JudgeVerdict.truncation:type_name -> asreval.workspace.v1.Truncation
+	25, // 27: asreval.workspace.v1.EvalMetrics.PER_details:type_name -> asreval.workspace.v1.PhoneticDetails
+	27, // 28: asreval.workspace.v1.CheckpointResult.override:type_name -> asreval.workspace.v1.CheckpointOverride
+	35, // 29: asreval.workspace.v1.CheckpointOverride.time:type_name -> google.protobuf.Timestamp
+	34, // 30: asreval.workspace.v1.Truncation.truncated_transcripts:type_name -> asreval.workspace.v1.Truncation.TruncatedTranscriptsEntry
+	14, // 31: asreval.workspace.v1.Case.CorrectionsEntry.value:type_name -> asreval.workspace.v1.Correction
+	22, // 32: asreval.workspace.v1.EvalReport.EvaluationsEntry.value:type_name -> asreval.workspace.v1.EvalResult
+	26, // 33: asreval.workspace.v1.EvalResult.CheckpointResultsEntry.value:type_name -> asreval.workspace.v1.CheckpointResult
+	26, // 34: asreval.workspace.v1.JudgeVerdict.CheckpointResultsEntry.value:type_name -> asreval.workspace.v1.CheckpointResult
+	0,  // 35: asreval.workspace.v1.Workspace.ListCases:input_type -> asreval.workspace.v1.ListCasesRequest
+	2,  // 36: asreval.workspace.v1.Workspace.GetCase:input_type -> asreval.workspace.v1.GetCaseRequest
+	3,  // 37: asreval.workspace.v1.Workspace.Evaluate:input_type -> asreval.workspace.v1.EvaluateRequest
+	4,  // 38: asreval.workspace.v1.Workspace.GenerateContext:input_type -> asreval.workspace.v1.GenerateContextRequest
+	5,  // 39: asreval.workspace.v1.Workspace.UpdateContext:input_type -> asreval.workspace.v1.UpdateContextRequest
+	6,  // 40: asreval.workspace.v1.Workspace.Leaderboard:input_type -> asreval.workspace.v1.LeaderboardRequest
+	9,  // 41: asreval.workspace.v1.Workspace.HeadToHead:input_type -> asreval.workspace.v1.HeadToHeadRequest
+	1,  // 42: asreval.workspace.v1.Workspace.ListCases:output_type -> asreval.workspace.v1.ListCasesResponse
+	12, // 43: asreval.workspace.v1.Workspace.GetCase:output_type -> asreval.workspace.v1.Case
+	21, // 44: asreval.workspace.v1.Workspace.Evaluate:output_type -> asreval.workspace.v1.EvalReport
+	16, // 45: asreval.workspace.v1.Workspace.GenerateContext:output_type -> asreval.workspace.v1.EvalContext
+	12, // 46: asreval.workspace.v1.Workspace.UpdateContext:output_type -> asreval.workspace.v1.Case
+	7,  // 47: asreval.workspace.v1.Workspace.Leaderboard:output_type -> asreval.workspace.v1.LeaderboardResponse
+	10, // 48: asreval.workspace.v1.Workspace.HeadToHead:output_type -> asreval.workspace.v1.HeadToHeadResponse
+	42, // [42:49] is the sub-list for method output_type
+	35, // [35:42] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
+}
+
+func init() { file_asreval_workspace_v1_workspace_proto_init() }
+func file_asreval_workspace_v1_workspace_proto_init() {
+	if File_asreval_workspace_v1_workspace_proto != nil {
+		return
+	}
+	file_asreval_workspace_v1_workspace_proto_msgTypes[0].OneofWrappers = []any{}
+	file_asreval_workspace_v1_workspace_proto_msgTypes[6].OneofWrappers = []any{}
+	file_asreval_workspace_v1_workspace_proto_msgTypes[9].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_asreval_workspace_v1_workspace_proto_rawDesc), len(file_asreval_workspace_v1_workspace_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   35,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_asreval_workspace_v1_workspace_proto_goTypes,
+		DependencyIndexes: file_asreval_workspace_v1_workspace_proto_depIdxs,
+		MessageInfos:      file_asreval_workspace_v1_workspace_proto_msgTypes,
+	}.Build()
+	File_asreval_workspace_v1_workspace_proto = out.File
+	file_asreval_workspace_v1_workspace_proto_goTypes = nil
+	file_asreval_workspace_v1_workspace_proto_depIdxs = nil
+}
@@ -0,0 +1,371 @@
+// The workspace service: the gRPC counterpart of the JSON API under /api.
+//
+// Messages mirror the JSON API's request and response bodies, and field
+// names match their JSON keys, so a case reads the same whichever way it is
+// fetched. A request picks the dataset of a multi-dataset server with the
+// "dataset" metadata key, and authenticates with "authorization: Bearer
+// <key>" metadata when the server requires keys.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: asreval/workspace/v1/workspace.proto
+
+package workspacepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Workspace_ListCases_FullMethodName       = "/asreval.workspace.v1.Workspace/ListCases"
+	Workspace_GetCase_FullMethodName         = "/asreval.workspace.v1.Workspace/GetCase"
+	Workspace_Evaluate_FullMethodName        = "/asreval.workspace.v1.Workspace/Evaluate"
+	Workspace_GenerateContext_FullMethodName = "/asreval.workspace.v1.Workspace/GenerateContext"
+	Workspace_UpdateContext_FullMethodName   = "/asreval.workspace.v1.Workspace/UpdateContext"
+	Workspace_Leaderboard_FullMethodName     = "/asreval.workspace.v1.Workspace/Leaderboard"
+	Workspace_HeadToHead_FullMethodName      = "/asreval.workspace.v1.Workspace/HeadToHead"
+)
+
+// WorkspaceClient is the client API for Workspace service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WorkspaceClient interface {
+	// ListCases returns the cases matching the filter, without transcripts.
+	ListCases(ctx context.Context, in *ListCasesRequest, opts ...grpc.CallOption) (*ListCasesResponse, error)
+	// GetCase returns a case with its transcripts, context and report.
+	GetCase(ctx context.Context, in *GetCaseRequest, opts ...grpc.CallOption) (*Case, error)
+	// Evaluate runs the evaluation inline and returns the report.
+	Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvalReport, error)
+	// GenerateContext generates an evaluation context from a ground truth.
+	GenerateContext(ctx context.Context, in *GenerateContextRequest, opts ...grpc.CallOption) (*EvalContext, error)
+	// UpdateContext saves an edited context and returns the updated case.
+	UpdateContext(ctx context.Context, in *UpdateContextRequest, opts ...grpc.CallOption) (*Case, error)
+	// Leaderboard returns the weighted scores of each provider.
+	Leaderboard(ctx context.Context, in *LeaderboardRequest, opts ...grpc.CallOption) (*LeaderboardResponse, error)
+	// HeadToHead compares two providers case by case.
+	HeadToHead(ctx context.Context, in *HeadToHeadRequest, opts ...grpc.CallOption) (*HeadToHeadResponse, error)
+}
+
+type workspaceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWorkspaceClient(cc grpc.ClientConnInterface) WorkspaceClient {
+	return &workspaceClient{cc}
+}
+
+func (c *workspaceClient) ListCases(ctx context.Context, in *ListCasesRequest, opts ...grpc.CallOption) (*ListCasesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCasesResponse)
+	err := c.cc.Invoke(ctx, Workspace_ListCases_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workspaceClient) GetCase(ctx context.Context, in *GetCaseRequest, opts ...grpc.CallOption) (*Case, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Case)
+	err := c.cc.Invoke(ctx, Workspace_GetCase_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workspaceClient) Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvalReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EvalReport)
+	err := c.cc.Invoke(ctx, Workspace_Evaluate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workspaceClient) GenerateContext(ctx context.Context, in *GenerateContextRequest, opts ...grpc.CallOption) (*EvalContext, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EvalContext)
+	err := c.cc.Invoke(ctx, Workspace_GenerateContext_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workspaceClient) UpdateContext(ctx context.Context, in *UpdateContextRequest, opts ...grpc.CallOption) (*Case, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Case)
+	err := c.cc.Invoke(ctx, Workspace_UpdateContext_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workspaceClient) Leaderboard(ctx context.Context, in *LeaderboardRequest, opts ...grpc.CallOption) (*LeaderboardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaderboardResponse)
+	err := c.cc.Invoke(ctx, Workspace_Leaderboard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workspaceClient) HeadToHead(ctx context.Context, in *HeadToHeadRequest, opts ...grpc.CallOption) (*HeadToHeadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeadToHeadResponse)
+	err := c.cc.Invoke(ctx, Workspace_HeadToHead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkspaceServer is the server API for Workspace service.
+// All implementations must embed UnimplementedWorkspaceServer
+// for forward compatibility.
+type WorkspaceServer interface {
+	// ListCases returns the cases matching the filter, without transcripts.
+	ListCases(context.Context, *ListCasesRequest) (*ListCasesResponse, error)
+	// GetCase returns a case with its transcripts, context and report.
+	GetCase(context.Context, *GetCaseRequest) (*Case, error)
+	// Evaluate runs the evaluation inline and returns the report.
+	Evaluate(context.Context, *EvaluateRequest) (*EvalReport, error)
+	// GenerateContext generates an evaluation context from a ground truth.
+	GenerateContext(context.Context, *GenerateContextRequest) (*EvalContext, error)
+	// UpdateContext saves an edited context and returns the updated case.
+	UpdateContext(context.Context, *UpdateContextRequest) (*Case, error)
+	// Leaderboard returns the weighted scores of each provider.
+	Leaderboard(context.Context, *LeaderboardRequest) (*LeaderboardResponse, error)
+	// HeadToHead compares two providers case by case.
+	HeadToHead(context.Context, *HeadToHeadRequest) (*HeadToHeadResponse, error)
+	mustEmbedUnimplementedWorkspaceServer()
+}
+
+// UnimplementedWorkspaceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWorkspaceServer struct{}
+
+func (UnimplementedWorkspaceServer) ListCases(context.Context, *ListCasesRequest) (*ListCasesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCases not implemented")
+}
+func (UnimplementedWorkspaceServer) GetCase(context.Context, *GetCaseRequest) (*Case, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCase not implemented")
+}
+func (UnimplementedWorkspaceServer) Evaluate(context.Context, *EvaluateRequest) (*EvalReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Evaluate not implemented")
+}
+func (UnimplementedWorkspaceServer) GenerateContext(context.Context, *GenerateContextRequest) (*EvalContext, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateContext not implemented")
+}
+func (UnimplementedWorkspaceServer) UpdateContext(context.Context, *UpdateContextRequest) (*Case, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateContext not implemented")
+}
+func (UnimplementedWorkspaceServer) Leaderboard(context.Context, *LeaderboardRequest) (*LeaderboardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Leaderboard not implemented")
+}
+func (UnimplementedWorkspaceServer) HeadToHead(context.Context, *HeadToHeadRequest) (*HeadToHeadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HeadToHead not implemented")
+}
+func (UnimplementedWorkspaceServer) mustEmbedUnimplementedWorkspaceServer() {}
+func (UnimplementedWorkspaceServer) testEmbeddedByValue()                   {}
+
+// UnsafeWorkspaceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WorkspaceServer will
+// result in compilation errors.
+type UnsafeWorkspaceServer interface {
+	mustEmbedUnimplementedWorkspaceServer()
+}
+
+func RegisterWorkspaceServer(s grpc.ServiceRegistrar, srv WorkspaceServer) {
+	// If the following call pancis, it indicates UnimplementedWorkspaceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Workspace_ServiceDesc, srv)
+}
+
+func _Workspace_ListCases_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCasesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkspaceServer).ListCases(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workspace_ListCases_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkspaceServer).ListCases(ctx, req.(*ListCasesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Workspace_GetCase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkspaceServer).GetCase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workspace_GetCase_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkspaceServer).GetCase(ctx, req.(*GetCaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Workspace_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkspaceServer).Evaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workspace_Evaluate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkspaceServer).Evaluate(ctx, req.(*EvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Workspace_GenerateContext_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateContextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkspaceServer).GenerateContext(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workspace_GenerateContext_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkspaceServer).GenerateContext(ctx, req.(*GenerateContextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Workspace_UpdateContext_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateContextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkspaceServer).UpdateContext(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workspace_UpdateContext_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkspaceServer).UpdateContext(ctx, req.(*UpdateContextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Workspace_Leaderboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaderboardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkspaceServer).Leaderboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workspace_Leaderboard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkspaceServer).Leaderboard(ctx, req.(*LeaderboardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Workspace_HeadToHead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeadToHeadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkspaceServer).HeadToHead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Workspace_HeadToHead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkspaceServer).HeadToHead(ctx, req.(*HeadToHeadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Workspace_ServiceDesc is the grpc.ServiceDesc for Workspace service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Workspace_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "asreval.workspace.v1.Workspace",
+	HandlerType: (*WorkspaceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListCases",
+			Handler:    _Workspace_ListCases_Handler,
+		},
+		{
+			MethodName: "GetCase",
+			Handler:    _Workspace_GetCase_Handler,
+		},
+		{
+			MethodName: "Evaluate",
+			Handler:    _Workspace_Evaluate_Handler,
+		},
+		{
+			MethodName: "GenerateContext",
+			Handler:    _Workspace_GenerateContext_Handler,
+		},
+		{
+			MethodName: "UpdateContext",
+			Handler:    _Workspace_UpdateContext_Handler,
+		},
+		{
+			MethodName: "Leaderboard",
+			Handler:    _Workspace_Leaderboard_Handler,
+		},
+		{
+			MethodName: "HeadToHead",
+			Handler:    _Workspace_HeadToHead_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "asreval/workspace/v1/workspace.proto",
+}
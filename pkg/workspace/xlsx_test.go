@@ -0,0 +1,93 @@
+package workspace
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	header := []string{"case_id", "q_score", "count"}
+	rows := [][]any{{"a<&>", 87.5, 3}}
+	if err := writeXLSX(&buf, "Results & more", header, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = b
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(files["xl/workbook.xml"], &wb); err != nil {
+		t.Fatal(err)
+	}
+	if len(wb.Sheets) != 1 || wb.Sheets[0].Name != "Results & more" {
+		t.Errorf("sheets = %+v, want one named %q", wb.Sheets, "Results & more")
+	}
+
+	type cell struct {
+		Ref    string `xml:"r,attr"`
+		Type   string `xml:"t,attr"`
+		Value  string `xml:"v"`
+		Inline string `xml:"is>t"`
+	}
+	var ws struct {
+		Rows []struct {
+			N     int    `xml:"r,attr"`
+			Cells []cell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(files["xl/worksheets/sheet1.xml"], &ws); err != nil {
+		t.Fatal(err)
+	}
+	want := []cell{
+		{Ref: "A2", Type: "inlineStr", Inline: "a<&>"},
+		{Ref: "B2", Value: "87.5"},
+		{Ref: "C2", Value: "3"},
+	}
+	if len(ws.Rows) != 2 || ws.Rows[0].N != 1 || ws.Rows[1].N != 2 {
+		t.Fatalf("rows = %+v, want header and one row", ws.Rows)
+	}
+	if got := ws.Rows[0].Cells[2]; got.Ref != "C1" || got.Inline != "count" {
+		t.Errorf("header cell = %+v, want C1 count", got)
+	}
+	if diff := cmp.Diff(want, ws.Rows[1].Cells); diff != "" {
+		t.Errorf("row cells mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,22 @@
+// Package ui embeds the built frontend so the server ships as one binary.
+package ui
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist holds the output of "npm run build". An unbuilt checkout only has
+// dist/.gitkeep, which the build recreates from public/.
+//
+//go:embed all:dist
+var dist embed.FS
+
+// FS returns the built frontend, rooted at its index.html.
+func FS() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err) // "dist" is a valid path
+	}
+	return sub
+}
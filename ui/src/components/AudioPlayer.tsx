@@ -1,6 +1,7 @@
 import { useState, useRef, forwardRef, useImperativeHandle } from 'react';
 import { Play, Pause } from 'lucide-react';
 import { formatTime } from '../utils/formatUtils';
+import { withDataset } from '../workspace/context';
 
 interface AudioPlayerProps {
   caseId: string;
@@ -70,7 +71,7 @@ export const AudioPlayer = forwardRef<AudioPlayerHandle, AudioPlayerProps>(({ ca
       <span className="text-[10px] font-mono text-slate-400 dark:text-slate-500 shrink-0">{formatTime(currentTime)} / {formatTime(duration)}</span>
       <audio
         ref={audioRef}
-        src={`/audio/${caseId}.flac`}
+        src={withDataset(`/audio/${caseId}.flac`)}
         onTimeUpdate={e => setCurrentTime(e.currentTarget.currentTime)}
         onLoadedMetadata={e => setDuration(e.currentTarget.duration)}
         onEnded={() => setIsPlaying(false)}
@@ -7,7 +7,7 @@ import { RichTooltip } from './RichTooltip';
 import { EvalContextDisplay } from './EvalContextDisplay';
 import { EvalReportView } from './EvalReportView';
 import { ContextManagerModal } from './ContextManagerModal';
-import { Case, Checkpoint } from '../workspace/types';
+import { Case, Checkpoint, ReviewState } from '../workspace/types';
 
 interface CaseDetailProps {
   onEvalComplete?: () => void;
@@ -30,7 +30,8 @@ export function CaseDetail({
 }: CaseDetailProps) {
   const { id } = useParams<{ id: string }>();
   const { currentCase, loading, error, refresh } = useCase(id);
-  const { evaluateCase } = useWorkspace();
+  const { evaluateCase, runPipeline, transcribe, updateReview, config } = useWorkspace();
+  const [transcribing, setTranscribing] = useState<string | null>(null);
   const [isContextModalOpen, setIsContextModalOpen] = useState(false);
   const audioPlayerRef = useRef<{ seek: (t: number) => void; pause: () => void }>(null);
   const idRef = useRef(id);
@@ -56,23 +57,33 @@ export function CaseDetail({
 
   const runEval = async () => {
     if (!currentCase || !id) return;
-    const gt = currentCase.eval_context?.meta?.ground_truth || "";
-    if (!gt.trim()) return alert("Ground Truth required (create context first)");
-
     const providersToEval = Object.keys(selectedProviders).filter(s => selectedProviders[s]);
     if (providersToEval.length === 0) return alert("Select at least one provider");
 
+    // Without a context, generate and save one first, all in one job.
+    let pipelineGT: string | undefined;
+    if (!currentCase.eval_context) {
+      pipelineGT = prompt("Ground Truth (a context is generated from it before evaluating)")?.trim();
+      if (!pipelineGT) return;
+    } else if (!currentCase.eval_context.meta?.ground_truth?.trim()) {
+      return alert("Ground Truth required (edit the context first)");
+    }
+
     // We only need provider IDs now, as transcripts are loaded/managed by backend
     // or passed via map if we want to override (but we rely on backend loading).
     // Actually, backend loads transcripts. Use provider_ids.
 
     startProcessing(id);
     try {
-      await evaluateCase({
-        id: id,
-        eval_context: currentCase.eval_context!,
-        provider_ids: providersToEval
-      });
+      if (pipelineGT) {
+        await runPipeline({ id, ground_truth: pipelineGT, provider_ids: providersToEval });
+      } else {
+        await evaluateCase({
+          id: id,
+          eval_context: currentCase.eval_context!,
+          provider_ids: providersToEval
+        });
+      }
       // Clear selection so it re-inits with new report data
       setSelectionForCase(id, undefined);
 
@@ -85,14 +96,39 @@ export function CaseDetail({
     }
   };
 
+  const runTranscribe = async (provider: string) => {
+    if (!id) return;
+    setTranscribing(provider);
+    try {
+      await transcribe({ id, provider });
+      if (idRef.current === id) await refresh();
+    } catch (e: any) {
+      alert("Transcribe Failed: " + e.message);
+    } finally {
+      setTranscribing(null);
+    }
+  };
+
+  const changeReview = async (state: ReviewState) => {
+    if (!id) return;
+    const note = state === 'disputed' ? prompt("What is disputed?") ?? undefined : undefined;
+    try {
+      await updateReview({ id, state, note });
+      if (idRef.current === id) await refresh();
+    } catch (e: any) {
+      alert("Review Update Failed: " + e.message);
+    }
+  };
+
   const handleContextSave = () => {
     if (id) setSelectionForCase(id, undefined);
     refresh();
   };
 
   const handleCheckpointClick = (checkpoint: Checkpoint) => {
-    if (audioPlayerRef.current && checkpoint.start_ms !== undefined) {
-      audioPlayerRef.current.seek(checkpoint.start_ms / 1000);
+    const startMS = checkpoint.span?.start_ms ?? checkpoint.start_ms;
+    if (audioPlayerRef.current && startMS !== undefined) {
+      audioPlayerRef.current.seek(startMS / 1000);
     }
   };
 
@@ -117,6 +153,8 @@ export function CaseDetail({
 
   const evalContext = currentCase.eval_context;
   const isProcessingThisCase = processingCases.has(currentCase.id);
+  const missingProviders = (config?.asr_providers ?? []).filter(p => !currentCase.transcripts?.[p]);
+  const readOnly = config?.role === 'viewer';
 
   return (
     <div className="flex flex-col h-full bg-slate-50 dark:bg-slate-900">
@@ -155,27 +193,57 @@ export function CaseDetail({
             </RichTooltip>
           )}
 
-          <button
-            onClick={() => {
-              audioPlayerRef.current?.pause();
-              setIsContextModalOpen(true);
-            }}
-            className="px-3 py-1.5 bg-white dark:bg-slate-800 border border-slate-200 dark:border-slate-700 hover:border-slate-300 dark:hover:border-slate-600 hover:bg-slate-50 dark:hover:bg-slate-750 text-slate-700 dark:text-slate-200 text-xs font-medium rounded-lg shadow-sm transition-all flex items-center gap-2"
-          >
-            <Settings size={14} /> {evalContext ? 'Manage Context' : 'Create Context'}
-          </button>
+          {!readOnly && (
+            <button
+              onClick={() => {
+                audioPlayerRef.current?.pause();
+                setIsContextModalOpen(true);
+              }}
+              className="px-3 py-1.5 bg-white dark:bg-slate-800 border border-slate-200 dark:border-slate-700 hover:border-slate-300 dark:hover:border-slate-600 hover:bg-slate-50 dark:hover:bg-slate-750 text-slate-700 dark:text-slate-200 text-xs font-medium rounded-lg shadow-sm transition-all flex items-center gap-2"
+            >
+              <Settings size={14} /> {evalContext ? 'Manage Context' : 'Create Context'}
+            </button>
+          )}
 
-          <button
-            onClick={runEval}
-            disabled={isProcessingThisCase || Object.values(selectedProviders).filter(Boolean).length === 0}
-            className="bg-primary hover:bg-primary-dark disabled:opacity-50 disabled:cursor-not-allowed text-white px-3 py-1.5 rounded-lg text-xs font-bold flex items-center gap-2 transition-colors shadow-sm"
+          <select
+            value={currentCase.review?.state ?? 'unreviewed'}
+            onChange={e => changeReview(e.target.value as ReviewState)}
+            disabled={readOnly}
+            className="px-2 py-1.5 bg-white dark:bg-slate-800 border border-slate-200 dark:border-slate-700 text-slate-700 dark:text-slate-200 text-xs font-medium rounded-lg shadow-sm"
+            title={currentCase.review?.assignee ? `Assigned to ${currentCase.review.assignee}` : 'Review state'}
           >
-            {isProcessingThisCase ? (
-              <><Loader2 size={14} className="animate-spin" /> Evaluating...</>
-            ) : (
-              <><Play size={14} /> Evaluate ({Object.values(selectedProviders).filter(Boolean).length})</>
-            )}
-          </button>
+            <option value="unreviewed">Unreviewed</option>
+            <option value="in_review">In Review</option>
+            <option value="approved">Approved</option>
+            <option value="disputed">Disputed</option>
+          </select>
+
+          {!readOnly && missingProviders.length > 0 && (
+            <select
+              value=""
+              onChange={e => e.target.value && runTranscribe(e.target.value)}
+              disabled={transcribing !== null}
+              className="px-2 py-1.5 bg-white dark:bg-slate-800 border border-slate-200 dark:border-slate-700 text-slate-700 dark:text-slate-200 text-xs font-medium rounded-lg shadow-sm disabled:opacity-50"
+              title="Generate a missing provider transcript"
+            >
+              <option value="">{transcribing ? `Transcribing ${transcribing}...` : 'Transcribe...'}</option>
+              {missingProviders.map(p => <option key={p} value={p}>{p}</option>)}
+            </select>
+          )}
+
+          {!readOnly && (
+            <button
+              onClick={runEval}
+              disabled={isProcessingThisCase || Object.values(selectedProviders).filter(Boolean).length === 0}
+              className="bg-primary hover:bg-primary-dark disabled:opacity-50 disabled:cursor-not-allowed text-white px-3 py-1.5 rounded-lg text-xs font-bold flex items-center gap-2 transition-colors shadow-sm"
+            >
+              {isProcessingThisCase ? (
+                <><Loader2 size={14} className="animate-spin" /> Evaluating...</>
+              ) : (
+                <><Play size={14} /> Evaluate ({Object.values(selectedProviders).filter(Boolean).length})</>
+              )}
+            </button>
+          )}
         </div>
       </div>
 
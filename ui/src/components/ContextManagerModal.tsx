@@ -141,8 +141,9 @@ export const ContextManagerModal: React.FC<ContextManagerModalProps> = ({
   };
 
   const handleCheckpointClick = (checkpoint: Checkpoint) => {
-    if (audioPlayerRef.current && checkpoint.start_ms !== undefined) {
-      audioPlayerRef.current.seek(checkpoint.start_ms / 1000);
+    const startMS = checkpoint.span?.start_ms ?? checkpoint.start_ms;
+    if (audioPlayerRef.current && startMS !== undefined) {
+      audioPlayerRef.current.seek(startMS / 1000);
     }
   };
 
@@ -339,9 +339,9 @@ export function EvalReportView({ kase, selectedProviders, onToggleProvider, onSe
                                   className="inline-block mb-0.5"
                                   trigger={
                                     <span
-                                      className={`inline-flex items-center justify-center px-1.5 py-0.5 rounded text-[9px] font-black mx-0.5 align-middle border cursor-help hover:bg-white dark:hover:bg-slate-700 transition-colors ${badgeClass}`}
+                                      className={`inline-flex items-center justify-center px-1.5 py-0.5 rounded text-[9px] font-black mx-0.5 align-middle border cursor-help hover:bg-white dark:hover:bg-slate-700 transition-colors ${badgeClass} ${result?.override ? 'ring-1 ring-primary' : ''}`}
                                     >
-                                      {part}
+                                      {part}{result?.override && '*'}
                                     </span>
                                   }
                                 >
@@ -388,6 +388,19 @@ export function EvalReportView({ kase, selectedProviders, onToggleProvider, onSe
                                         </p>
                                       </div>
                                     )}
+
+                                    {/* Reviewer adjudication */}
+                                    {result?.override && (
+                                      <div className="pt-2 space-y-1 border-t border-slate-100 dark:border-slate-800">
+                                        <span className="text-[9px] font-black text-primary uppercase tracking-widest block">
+                                          Adjudicated: {result.override.original_status || 'None'} → {result.status}
+                                        </span>
+                                        <p className="text-[11px] text-slate-600 dark:text-slate-400 leading-relaxed">
+                                          {result.override.reason}
+                                          {result.override.author && <span className="text-slate-400"> — {result.override.author}</span>}
+                                        </p>
+                                      </div>
+                                    )}
                                   </div>
                                 </RichTooltip>
                               );
@@ -1,11 +1,30 @@
-import React, { createContext, useContext, useEffect, useState, useCallback } from 'react';
+import React, { createContext, useContext, useEffect, useState, useCallback, useRef } from 'react';
 import {
-  Case, Config,
-  UpdateContextRequest, GenerateContextRequest, EvaluateRequest,
-  EvalContext, EvalReport
+  Case, Config, ListCasesResponse, Job,
+  CreateCaseRequest, UpdateContextRequest, GenerateContextRequest, EvaluateRequest, RunPipelineRequest, TranscribeRequest, UpdateReviewRequest, UpdateCorrectionRequest, ResetResultsRequest,
+  EvalContext, EvalReport, WorkspaceEvent
 } from './types';
 
+// withDataset scopes a server URL to the dataset selected by the page's
+// ?dataset= parameter; without one the server uses its default dataset.
+export function withDataset(url: string): string {
+  const dataset = new URLSearchParams(window.location.search).get('dataset');
+  if (!dataset) return url;
+  return `${url}${url.includes('?') ? '&' : '?'}dataset=${encodeURIComponent(dataset)}`;
+}
+
+// API_KEY_COOKIE must match apiKeyCookie in pkg/workspace/auth.go. A cookie
+// also covers requests the browser makes itself, such as <audio> sources.
+const API_KEY_COOKIE = 'asr_eval_key';
+
 async function handleResponse<T>(res: Response): Promise<T> {
+  if (res.status === 401) {
+    const key = window.prompt('API key');
+    if (key) {
+      document.cookie = `${API_KEY_COOKIE}=${encodeURIComponent(key)}; path=/; SameSite=Strict`;
+      window.location.reload();
+    }
+  }
   if (!res.ok) {
     const text = await res.text();
     throw new Error(text || res.statusText);
@@ -13,24 +32,70 @@ async function handleResponse<T>(res: Response): Promise<T> {
   return res.json();
 }
 
+const JOB_POLL_INTERVAL_MS = 1000;
+
+// Live updates arrive in bursts while a batch runs; refetch at most this often.
+const EVENT_REFRESH_DELAY_MS = 1000;
+
+// debounce delays fn until calls stop for ms.
+function debounce(fn: () => void, ms: number): { call: () => void; cancel: () => void } {
+  let timer: ReturnType<typeof setTimeout> | undefined;
+  return {
+    call: () => {
+      clearTimeout(timer);
+      timer = setTimeout(fn, ms);
+    },
+    cancel: () => clearTimeout(timer),
+  };
+}
+
+// waitForJob polls a queued job until it finishes and returns its result.
+async function waitForJob<T>(res: Response, signal?: AbortSignal): Promise<T> {
+  let job = await handleResponse<Job>(res);
+  while (job.status === 'queued' || job.status === 'running') {
+    if (signal?.aborted) {
+      await fetch(withDataset(`/api/jobs/${job.id}:cancel`), { method: 'POST' });
+      throw new DOMException('Aborted', 'AbortError');
+    }
+    await new Promise(resolve => setTimeout(resolve, JOB_POLL_INTERVAL_MS));
+    job = await handleResponse<Job>(await fetch(withDataset(`/api/jobs/${job.id}`)));
+  }
+  if (job.status !== 'succeeded') {
+    throw new Error(job.error || `Job ${job.status}`);
+  }
+  return job.result as T;
+}
+
 const workspaceClient = {
   fetchConfig: async (): Promise<Config> => {
-    const res = await fetch('/api/config');
+    const res = await fetch(withDataset('/api/config'));
     return handleResponse<Config>(res);
   },
 
   listCases: async (): Promise<Case[]> => {
-    const res = await fetch('/api/cases');
-    return handleResponse<Case[]>(res);
+    const res = await fetch(withDataset('/api/cases'));
+    const data = await handleResponse<ListCasesResponse>(res);
+    return data.cases;
   },
 
   getCase: async (id: string): Promise<Case> => {
-    const res = await fetch(`/api/cases/${id}`);
+    const res = await fetch(withDataset(`/api/cases/${id}`));
+    return handleResponse<Case>(res);
+  },
+
+  createCase: async (req: CreateCaseRequest): Promise<Case> => {
+    const form = new FormData();
+    form.append('audio', req.audio);
+    if (req.ground_truth) form.append('ground_truth', req.ground_truth);
+    for (const [provider, text] of Object.entries(req.transcripts ?? {})) {
+      form.append(`transcript.${provider}`, text);
+    }
+    const res = await fetch(withDataset('/api/cases'), { method: 'POST', body: form });
     return handleResponse<Case>(res);
   },
 
   updateContext: async (req: UpdateContextRequest): Promise<Case> => {
-    const res = await fetch(`/api/cases/${req.id}:updateContext`, {
+    const res = await fetch(withDataset(`/api/cases/${req.id}:updateContext`), {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
       body: JSON.stringify(req)
@@ -39,23 +104,68 @@ const workspaceClient = {
   },
 
   generateContext: async (req: GenerateContextRequest, signal?: AbortSignal): Promise<EvalContext> => {
-    const res = await fetch(`/api/cases/${req.id}:generateContext`, {
+    const res = await fetch(withDataset(`/api/cases/${req.id}:generateContext`), {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
       body: JSON.stringify(req),
       signal
     });
-    return handleResponse<EvalContext>(res);
+    return waitForJob<EvalContext>(res, signal);
   },
 
   evaluateCase: async (req: EvaluateRequest): Promise<EvalReport> => {
-    const res = await fetch(`/api/cases/${req.id}:evaluate`, {
+    const res = await fetch(withDataset(`/api/cases/${req.id}:evaluate`), {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(req)
+    });
+    return waitForJob<EvalReport>(res);
+  },
+
+  runPipeline: async (req: RunPipelineRequest): Promise<EvalReport> => {
+    const res = await fetch(withDataset(`/api/cases/${req.id}:runPipeline`), {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(req)
+    });
+    return waitForJob<EvalReport>(res);
+  },
+
+  updateReview: async (req: UpdateReviewRequest): Promise<Case> => {
+    const res = await fetch(withDataset(`/api/cases/${req.id}:updateReview`), {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(req)
+    });
+    return handleResponse<Case>(res);
+  },
+
+  resetResults: async (req: ResetResultsRequest): Promise<EvalReport> => {
+    const res = await fetch(withDataset(`/api/cases/${req.id}:resetResults`), {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
       body: JSON.stringify(req)
     });
     return handleResponse<EvalReport>(res);
   },
+
+  updateCorrection: async (req: UpdateCorrectionRequest): Promise<Case> => {
+    const res = await fetch(withDataset(`/api/cases/${req.id}/transcripts/${req.provider}`), {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ text: req.text })
+    });
+    return handleResponse<Case>(res);
+  },
+
+  transcribe: async (req: TranscribeRequest): Promise<Case> => {
+    const res = await fetch(withDataset(`/api/cases/${req.id}:transcribe`), {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(req)
+    });
+    return waitForJob<Case>(res);
+  },
 };
 
 interface WorkspaceState {
@@ -67,6 +177,11 @@ interface WorkspaceState {
   updateContext: (req: UpdateContextRequest) => Promise<Case>;
   generateContext: (req: GenerateContextRequest, signal?: AbortSignal) => Promise<EvalContext>;
   evaluateCase: (req: EvaluateRequest) => Promise<EvalReport>;
+  runPipeline: (req: RunPipelineRequest) => Promise<EvalReport>;
+  transcribe: (req: TranscribeRequest) => Promise<Case>;
+  updateReview: (req: UpdateReviewRequest) => Promise<Case>;
+  updateCorrection: (req: UpdateCorrectionRequest) => Promise<Case>;
+  subscribeEvents: (fn: (e: WorkspaceEvent) => void) => () => void;
 }
 
 const WorkspaceContext = createContext<WorkspaceState | undefined>(undefined);
@@ -101,6 +216,58 @@ export const WorkspaceProvider: React.FC<{ children: React.ReactNode }> = ({ chi
     return workspaceClient.evaluateCase(req);
   }, []);
 
+  const runPipeline = useCallback(async (req: RunPipelineRequest) => {
+    return workspaceClient.runPipeline(req);
+  }, []);
+
+  const transcribe = useCallback(async (req: TranscribeRequest) => {
+    const updatedCase = await workspaceClient.transcribe(req);
+    setCases(prev => prev.map(c => c.id === updatedCase.id ? { ...c, ...updatedCase } : c));
+    return updatedCase;
+  }, []);
+
+  const updateReview = useCallback(async (req: UpdateReviewRequest) => {
+    const updatedCase = await workspaceClient.updateReview(req);
+    setCases(prev => prev.map(c => c.id === updatedCase.id ? { ...c, ...updatedCase } : c));
+    return updatedCase;
+  }, []);
+
+  const updateCorrection = useCallback(async (req: UpdateCorrectionRequest) => {
+    return workspaceClient.updateCorrection(req);
+  }, []);
+
+  const listeners = useRef(new Set<(e: WorkspaceEvent) => void>());
+
+  const subscribeEvents = useCallback((fn: (e: WorkspaceEvent) => void) => {
+    listeners.current.add(fn);
+    return () => { listeners.current.delete(fn); };
+  }, []);
+
+  // One event stream per page, shared by every subscriber.
+  useEffect(() => {
+    const source = new EventSource(withDataset('/api/events'));
+    const dispatch = (msg: MessageEvent) => {
+      const e: WorkspaceEvent = JSON.parse(msg.data);
+      listeners.current.forEach(fn => fn(e));
+    };
+    for (const type of ['case', 'job', 'reset']) {
+      source.addEventListener(type, dispatch);
+    }
+    return () => source.close();
+  }, []);
+
+  // Keep the case list in sync with writes from batch tools and other users.
+  useEffect(() => {
+    const refresh = debounce(refreshCases, EVENT_REFRESH_DELAY_MS);
+    const unsubscribe = subscribeEvents(e => {
+      if (e.type === 'case' || e.type === 'reset') refresh.call();
+    });
+    return () => {
+      unsubscribe();
+      refresh.cancel();
+    };
+  }, [refreshCases, subscribeEvents]);
+
   useEffect(() => {
     const init = async () => {
       setLoading(true);
@@ -124,7 +291,7 @@ export const WorkspaceProvider: React.FC<{ children: React.ReactNode }> = ({ chi
   return (
     <WorkspaceContext.Provider value={{
       cases, config, loading, error, refreshCases,
-      updateContext, generateContext, evaluateCase
+      updateContext, generateContext, evaluateCase, runPipeline, transcribe, updateReview, updateCorrection, subscribeEvents
     }}>
       {children}
     </WorkspaceContext.Provider>
@@ -163,5 +330,25 @@ export const useCase = (id: string | undefined) => {
     loadCase();
   }, [loadCase]);
 
+  // Reload quietly when the case's files change elsewhere.
+  const subscribeEvents = useContext(WorkspaceContext)?.subscribeEvents;
+  useEffect(() => {
+    if (!id || !subscribeEvents) return;
+    const reload = debounce(async () => {
+      try {
+        setCurrentCase(await workspaceClient.getCase(id));
+      } catch {
+        // Keep showing what we have; the next change retries.
+      }
+    }, EVENT_REFRESH_DELAY_MS);
+    const unsubscribe = subscribeEvents(e => {
+      if (e.type === 'reset' || (e.type === 'case' && e.case_id === id)) reload.call();
+    });
+    return () => {
+      unsubscribe();
+      reload.cancel();
+    };
+  }, [id, subscribeEvents]);
+
   return { currentCase, loading, error, refresh: loadCase, setCurrentCase };
 };
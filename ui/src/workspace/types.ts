@@ -15,16 +15,124 @@ export interface Case {
 
   // Data Fields (from backend)
   transcripts?: Record<string, string>;
+  tags?: string[];
+  streams?: string[];
+  review?: Review; // Unset when unreviewed
+  corrections?: Record<string, Correction>; // Human-corrected transcripts by provider; Get view only
+  channels?: ChannelOptions; // The case's own channel selection; Get view only
 
   // Complex Objects
   eval_context?: EvalContext;
   report_v2?: EvalReport;
 }
 
+export interface Correction {
+  text: string;
+  updated_by?: string;
+  update_time: string;
+}
+
+export interface ChannelOptions {
+  select?: 'mix' | 'agent' | 'customer'; // Unset mixes
+  agent?: number; // 1-based; unset means 1
+  customer?: number; // 1-based; unset means 2
+}
+
+export interface ResetResultsRequest {
+  id: string;
+  provider_ids: string[];
+}
+
+export interface UpdateCorrectionRequest {
+  id: string;
+  provider: string;
+  text: string; // Empty removes the correction
+}
+
+export type ReviewState = 'unreviewed' | 'in_review' | 'approved' | 'disputed';
+
+export interface Review {
+  state: ReviewState;
+  assignee?: string;
+  note?: string;
+  updated_by?: string;
+  update_time: string;
+}
+
+export interface UpdateReviewRequest {
+  id: string;
+  state: ReviewState;
+  assignee?: string; // Keeps the current assignee when empty
+  note?: string;
+}
+
+export interface ListCasesResponse {
+  cases: Case[];
+  total_size: number;
+}
+
 export interface Config {
   gen_model: string;
   eval_model: string;
   enabled_providers: Record<string, boolean>;
+  s_score_weight: number;
+  pricing?: Record<string, ProviderPricing>;
+  tags?: string[]; // Allowed case tags
+  asr_providers?: string[]; // Providers :transcribe can run
+  dataset?: string;
+  datasets?: string[];
+  role?: Role; // Caller's role; unset without authentication
+}
+
+// Role limits what an authenticated user may do; viewers are read-only.
+export type Role = 'viewer' | 'editor';
+
+export interface ProviderPricing {
+  per_hour: number; // Per hour of audio
+  currency?: string;
+}
+
+export interface CreateCaseRequest {
+  audio: Blob; // FLAC
+  ground_truth?: string;
+  transcripts?: Record<string, string>;
+}
+
+export interface Comment {
+  id: string;
+  author?: string;
+  create_time: string;
+  body: string; // Markdown
+  provider?: string;
+  checkpoint_id?: string;
+}
+
+export interface OverrideCheckpointRequest {
+  id: string;
+  provider: string;
+  checkpoint_id: string;
+  status: string; // "Pass", "Fail", "Partial"
+  reason: string;
+}
+
+export interface CreateCommentRequest {
+  id: string;
+  author?: string; // Ignored when authentication is enabled
+  body: string;
+  provider?: string;
+  checkpoint_id?: string;
+}
+
+export interface TranscribeRequest {
+  id: string;
+  provider: string;
+  context?: string; // Biasing context for providers that take one
+}
+
+export interface UpdateTagsRequest {
+  id: string;
+  add?: string[];
+  remove?: string[];
 }
 
 export interface UpdateContextRequest {
@@ -41,6 +149,16 @@ export interface EvaluateRequest {
   id: string;
   eval_context: EvalContext;
   provider_ids: string[];
+  judge_models?: string[];
+  use_corrections?: boolean; // Score corrected transcripts; the report is not saved
+}
+
+export interface RunPipelineRequest {
+  id: string;
+  ground_truth?: string; // Defaults to the current context's
+  force?: boolean; // Regenerate the context even if there is one
+  provider_ids?: string[]; // Defaults to the enabled providers
+  judge_models?: string[];
 }
 
 export interface Checkpoint {
@@ -50,6 +168,16 @@ export interface Checkpoint {
   tier: number;
   weight: number;
   rationale: string;
+  span?: Span; // Where it is said, once the context is aligned
+}
+
+export interface Span {
+  start_ms: number;
+  end_ms: number;
+}
+
+export interface TimedText extends Span {
+  text: string;
 }
 
 export interface ContextMeta {
@@ -59,18 +187,29 @@ export interface ContextMeta {
   ground_truth: string;
   questionable_gt?: boolean;
   questionable_reason?: string;
+  align_model?: string; // Set once the context is aligned
 }
 
 export interface EvalContext {
+  schema_version?: number;
   meta: ContextMeta;
   checkpoints: Checkpoint[];
   hash?: string;
+  audio_reality_spans?: TimedText[]; // Set once the context is aligned
 }
 
 export interface CheckpointResult {
   status: string; // "Pass", "Fail", "Partial"
   detected: string;
   reason?: string;
+  override?: CheckpointOverride; // Set when a reviewer adjudicated the status
+}
+
+export interface CheckpointOverride {
+  original_status: string; // Status the judge gave
+  reason: string;
+  author?: string;
+  time: string;
 }
 
 export interface PhoneticDetails {
@@ -92,9 +231,310 @@ export interface EvalResult {
   metrics: EvalMetrics;
   checkpoint_results: Record<string, CheckpointResult>;
   summary: string[];
+  model?: string;
+  truncation?: Truncation;
+  verdicts?: JudgeVerdict[];
+  disputed?: string[];
+  failed_judges?: string[];
+}
+
+export interface Truncation {
+  prompt_tokens: number;
+  dropped_checkpoints?: string[];
+  truncated_transcripts?: Record<string, number>;
+}
+
+export interface JudgeVerdict {
+  model: string;
+  metrics: EvalMetrics;
+  checkpoint_results: Record<string, CheckpointResult>;
 }
 
 export interface EvalReport {
+  schema_version?: number;
   evaluations: Record<string, EvalResult | Partial<EvalResult>>;
   context_snapshot?: EvalContext;
 }
+
+export interface Progress {
+  stage: string; // "prompt_built", "model_call", "generating", "provider_evaluated", "step_started"
+  step?: string; // For runPipeline: "generate_context", "save_context" or "evaluate"
+  model?: string;
+  provider?: string;
+  done?: number;
+  total?: number;
+  output_tokens?: number;
+}
+
+export interface Job {
+  id: string;
+  kind: string; // "evaluate", "generateContext", "transcribe", "runPipeline"
+  case_id: string;
+  batch_id?: string; // Shared by the jobs of one evaluateAll
+  status: string; // "queued", "running", "succeeded", "failed", "canceled"
+  progress?: Progress;
+  result?: unknown;
+  error?: string;
+  created_at: string;
+  started_at?: string;
+  finished_at?: string;
+}
+
+// WorkspaceEvent is pushed by GET /api/events; each SSE event is named after its type.
+export interface WorkspaceEvent {
+  type: string; // "case", "job" or "reset"
+  case_id?: string;
+  file?: string; // For "case": "audio", "report", "context", "tags" or "review"
+  job?: Job; // For "job", without its result
+}
+
+export interface EvaluateAllRequest {
+  has_eval?: boolean;
+  questionable_gt?: boolean;
+  provider?: string;
+  min_score?: number;
+  max_score?: number;
+  tags?: string[];
+  review_state?: ReviewState;
+  assignee?: string;
+  run?: string;
+  only_stale?: boolean;
+  only_unevaluated?: boolean;
+  provider_ids?: string[];
+  judge_models?: string[];
+  recheck?: string; // Only cases this provider scored low on or failed a Tier-1 checkpoint of
+  recheck_below?: number; // Q score; 0 uses the server default
+}
+
+export interface ImportBundleResponse {
+  cases: string[];
+  imported: number; // Files written
+  skipped: string[]; // Existing files left untouched
+}
+
+export interface ListStaleResponse {
+  cases: StaleCase[];
+}
+
+export interface StaleCase {
+  id: string;
+  report_hash: string; // Context hash the report was produced against
+  context_hash: string; // Hash of the current gt.v2.json
+}
+
+export interface EvaluateAllResponse {
+  batch_id: string; // Jobs are queued in the background; list them with GET /api/jobs?batch_id=
+}
+
+export interface CaseFilter {
+  has_eval?: boolean;
+  questionable_gt?: boolean;
+  provider?: string;
+  min_score?: number;
+  max_score?: number;
+  tags?: string[];
+  review_state?: ReviewState;
+  assignee?: string;
+  run?: string; // Use this run's reports instead of the current ones
+}
+
+export interface Run {
+  id: string;
+  description?: string;
+  filter: CaseFilter;
+  provider_ids: string[];
+  eval_model: string;
+  judge_models?: string[];
+  prompt_version: string;
+  cases: string[];
+  skipped?: string[]; // Matching cases without an eval context
+  created_by?: string;
+  create_time: string;
+}
+
+export interface CreateRunRequest extends CaseFilter {
+  id: string;
+  description?: string;
+  provider_ids?: string[];
+  judge_models?: string[];
+}
+
+export interface CreateRunResponse {
+  run: Run;
+  jobs: Job[]; // Their batch_id is the run ID
+}
+
+export interface CompareRunsResponse {
+  a: string;
+  b: string;
+  cases: number; // Evaluated by both runs
+  threshold: number;
+  providers: RunComparison[];
+  moved: CaseScoreDelta[]; // Q changed by more than the threshold, largest drop first
+  new_failures: CheckpointRegression[]; // Tier-1 checkpoints failing in b only
+}
+
+export interface RunComparison {
+  provider: string;
+  a?: ProviderStats;
+  b?: ProviderStats;
+  delta_q: number; // Weighted Q in b minus a
+}
+
+export interface CaseScoreDelta {
+  case_id: string;
+  provider: string;
+  a: number;
+  b: number;
+  delta: number; // b minus a
+}
+
+export interface CheckpointRegression {
+  case_id: string;
+  provider: string;
+  checkpoint_id: string;
+  text: string;
+  was: string; // Status in a: "Pass" or "Partial"
+}
+
+export interface ProviderStats {
+  provider: string;
+  weighted_q: number;
+  weighted_s: number;
+  weighted_p: number;
+  total_tokens: number;
+  cases: number;
+}
+
+// Deltas are Q(a) - Q(b) over the cases with a result for both providers.
+export interface HeadToHeadResponse {
+  a: string;
+  b: string;
+  wins_a: number;
+  wins_b: number;
+  ties: number;
+  mean_delta: number;
+  cases: CaseDelta[]; // By case ID
+  top_gaps: CaseDelta[]; // Largest |delta| first
+}
+
+export interface CaseDelta {
+  id: string;
+  a: number;
+  b: number;
+  delta: number;
+}
+
+export type ReportFreshness = 'none' | 'stale' | 'fresh';
+
+export interface CoverageResponse {
+  providers: string[]; // Enabled providers, which the coverage is about
+  cases: CaseCoverage[];
+  totals: CoverageTotals;
+}
+
+export interface CaseCoverage {
+  id: string;
+  transcripts: string[]; // Providers with a transcript
+  has_context: boolean;
+  report: ReportFreshness;
+  unevaluated?: string[]; // Transcripts without a result
+  review_state: ReviewState;
+}
+
+export interface CoverageTotals {
+  cases: number;
+  transcripts: Record<string, number>; // By provider
+  with_context: number;
+  reports: Partial<Record<ReportFreshness, number>>;
+  unevaluated: number; // Cases with transcripts left to evaluate
+  review: Partial<Record<ReviewState, number>>;
+}
+
+export interface DeleteCaseResponse {
+  files: string[];
+}
+
+export interface AudioInfo {
+  format: string; // "flac", "wav"
+  duration_ms: number;
+  sample_rate: number;
+  channels: number;
+  bits_per_sample: number;
+  samples: number;
+  size: number;
+}
+
+export interface Stream {
+  provider: string;
+  events: StreamEvent[];
+}
+
+export interface StreamEvent {
+  offset_ms: number;
+  final?: boolean;
+  committed: string;
+  partial?: string;
+}
+
+export interface DiffSpan {
+  op: 'equal' | 'insert' | 'delete' | 'replace';
+  ref?: string;
+  hyp?: string;
+}
+
+export interface DiffResponse {
+  provider: string;
+  against: string; // "gt", "audio_reality", "revised"
+  spans: DiffSpan[];
+  ref_tokens: number;
+  sub: number;
+  del: number;
+  ins: number;
+  error_rate: number;
+}
+
+export interface ReportVersion {
+  version: number; // 0 is the current report
+  update_time: string;
+  context_hash?: string;
+  models?: string[];
+  q_scores: Record<string, number>;
+}
+
+export interface RollbackReportRequest {
+  id: string;
+  version: number;
+}
+
+export interface ContextVersion {
+  version: number; // 0 is the current context
+  update_time: string;
+  hash?: string;
+  checkpoints: number;
+  changes?: ContextChanges;
+}
+
+export interface ContextChanges {
+  added_checkpoints?: string[];
+  removed_checkpoints?: string[];
+  changed_checkpoints?: string[];
+  changed_meta?: string[];
+}
+
+export interface RestoreContextRequest {
+  id: string;
+  version: number;
+}
+
+export interface AuditEntry {
+  time: string;
+  user?: string;
+  action: string; // "create_case", "delete_case", "update_context", "restore_context", "reset_report", "rollback_report", "update_tags"
+  case_id: string;
+  details?: Record<string, string>;
+}
+
+export interface UpdateProvidersRequest {
+  enabled_providers: Record<string, boolean>;
+}